@@ -2,19 +2,159 @@ package payd
 
 import (
 	"context"
+
+	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
 )
 
-// Balance contains the current balance of unspent outputs.
+// Balance contains the current balance of unspent outputs, broken down by
+// whether they can be spent.
 type Balance struct {
+	// Satoshis is the total of the unspent txos of broadcast txs, the confirmed,
+	// mined, unconfirmed, reserved and frozen totals.
+	Satoshis uint64 `json:"satoshis"`
+	// UTXOs is the number of unspent txos making up Satoshis.
+	UTXOs uint64 `json:"utxos"`
+	// Spendable is the satoshis that can be used to fund a payment, the confirmed satoshis
+	// when MinConfirmations is set, otherwise the confirmed, mined and unconfirmed satoshis.
+	Spendable uint64 `json:"spendable"`
+	// MinConfirmations are the confirmations a tx needs before its txos can be spent.
+	MinConfirmations uint32 `json:"minConfirmations"`
+	// TipHeight is the height of the best chain tip the confirmations of each total are counted from.
+	TipHeight uint32 `json:"tipHeight"`
+	// Confirmed are the txos of txs that have a merkle proof and at least MinConfirmations.
+	Confirmed BalanceTotal `json:"confirmed"`
+	// Mined are the txos of txs that have a merkle proof but fewer than MinConfirmations.
+	Mined BalanceTotal `json:"mined"`
+	// Unconfirmed are the txos of broadcast txs still waiting on a merkle proof.
+	Unconfirmed BalanceTotal `json:"unconfirmed"`
+	// Reserved are the txos reserved to fund a payment that hasn't completed.
+	Reserved BalanceTotal `json:"reserved"`
+	// Frozen are the txos that have been frozen so they aren't spent.
+	Frozen BalanceTotal `json:"frozen"`
+	// Pending are the txos of txs that haven't been broadcast, these can't be spent.
+	Pending BalanceTotal `json:"pending"`
+	// Failed are the txos of txs that failed to broadcast, these can't be spent.
+	Failed BalanceTotal `json:"failed"`
+}
+
+// BalanceTotal is a total of unspent txos.
+type BalanceTotal struct {
 	Satoshis uint64 `json:"satoshis" db:"satoshis"`
+	UTXOs    uint64 `json:"utxos" db:"utxos"`
+	// MinConfirmations are the fewest confirmations of a mined tx of the txos, 0 when none have been mined.
+	MinConfirmations uint32 `json:"minConfirmations" db:"-"`
+	// MaxConfirmations are the most confirmations of a mined tx of the txos, 0 when none have been mined.
+	MaxConfirmations uint32 `json:"maxConfirmations" db:"-"`
+	// LowBlockHeight and HighBlockHeight are the heights of the lowest and highest blocks
+	// the txs of the txos were mined in, the confirmations are counted from these.
+	LowBlockHeight  null.Int `json:"-" db:"low_block_height"`
+	HighBlockHeight null.Int `json:"-" db:"high_block_height"`
+}
+
+// confirmations sets the min and max confirmations of the total from the tip.
+func (b *BalanceTotal) confirmations(tip ChainTip) {
+	if b.HighBlockHeight.Valid {
+		b.MinConfirmations = tip.Confirmations(uint32(b.HighBlockHeight.Int64))
+	}
+	if b.LowBlockHeight.Valid {
+		b.MaxConfirmations = tip.Confirmations(uint32(b.LowBlockHeight.Int64))
+	}
+}
+
+// Add will add the txos in t to the balance. The total they are added to depends on the
+// state of their tx and whether they are reserved or frozen, the block heights of t are
+// only kept when the tx has been mined.
+func (b *Balance) Add(t BalanceTotal, state TxState, reserved, frozen bool) {
+	if state != StateTxMined && state != StateTxConfirmed {
+		t.LowBlockHeight, t.HighBlockHeight = null.Int{}, null.Int{}
+	}
+	add := func(total *BalanceTotal) {
+		total.Satoshis += t.Satoshis
+		total.UTXOs += t.UTXOs
+		if t.LowBlockHeight.Valid && (!total.LowBlockHeight.Valid || t.LowBlockHeight.Int64 < total.LowBlockHeight.Int64) {
+			total.LowBlockHeight = t.LowBlockHeight
+		}
+		if t.HighBlockHeight.Valid && (!total.HighBlockHeight.Valid || t.HighBlockHeight.Int64 > total.HighBlockHeight.Int64) {
+			total.HighBlockHeight = t.HighBlockHeight
+		}
+	}
+	switch {
+	case state == StateTxPending:
+		add(&b.Pending)
+		return
+	case state == StateTxFailed:
+		add(&b.Failed)
+		return
+	case reserved:
+		add(&b.Reserved)
+	case frozen:
+		add(&b.Frozen)
+	case state == StateTxConfirmed:
+		add(&b.Confirmed)
+		b.Spendable += t.Satoshis
+	case state == StateTxMined:
+		add(&b.Mined)
+		b.Spendable += t.Satoshis
+	default:
+		add(&b.Unconfirmed)
+		b.Spendable += t.Satoshis
+	}
+	b.Satoshis += t.Satoshis
+	b.UTXOs += t.UTXOs
+}
+
+// RequireConfirmations sets the confirmations a tx needs before its txos can be spent,
+// when set only the confirmed satoshis are spendable.
+func (b *Balance) RequireConfirmations(n uint32) {
+	b.MinConfirmations = n
+	if n > 0 {
+		b.Spendable = b.Confirmed.Satoshis
+	}
+}
+
+// Confirmations sets the tip height and counts the min and max confirmations of each
+// total from it.
+func (b *Balance) Confirmations(tip ChainTip) {
+	b.TipHeight = tip.Height
+	for _, total := range []*BalanceTotal{&b.Confirmed, &b.Mined, &b.Unconfirmed, &b.Reserved, &b.Frozen, &b.Pending, &b.Failed} {
+		total.confirmations(tip)
+	}
+}
+
+// BalanceArgs are used to scope a balance to the txos paid to a user or key,
+// zero values are ignored.
+type BalanceArgs struct {
+	// UserID will only include txos paid to destinations of this user.
+	UserID null.Int `query:"userId" swaggertype:"primitive,integer"`
+	// KeyName will only include txos paid to destinations derived from this key.
+	KeyName string `query:"keyName"`
+}
+
+// Validate will check the balance args are valid.
+func (b BalanceArgs) Validate() error {
+	v := validator.New()
+	if b.UserID.Valid {
+		v = v.Validate("userId", validator.MinInt64(b.UserID.Int64, 0))
+	}
+	return v.Err()
+}
+
+// Matches returns true if a destination of the user and key is within the balance scope,
+// it is used by stores that total balances in process.
+func (b BalanceArgs) Matches(userID uint64, keyName string) bool {
+	if b.UserID.Valid && uint64(b.UserID.Int64) != userID {
+		return false
+	}
+	return b.KeyName == "" || b.KeyName == keyName
 }
 
 // BalanceService is used to enforce balance buiness rules.
 type BalanceService interface {
-	Balance(ctx context.Context) (*Balance, error)
+	Balance(ctx context.Context, args BalanceArgs) (*Balance, error)
 }
 
 // BalanceReader is used to read balance info from a datastore.
 type BalanceReader interface {
-	Balance(ctx context.Context) (*Balance, error)
+	Balance(ctx context.Context, args BalanceArgs) (*Balance, error)
 }
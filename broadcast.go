@@ -3,9 +3,14 @@ package payd
 import (
 	"context"
 
+	"github.com/libsv/go-bc"
 	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
 )
 
+// ErrTxDoubleSpent is returned when broadcasting a tx that spends txos already spent by another tx.
+var ErrTxDoubleSpent = errors.New("transaction double spends its inputs")
+
 // BroadcastArgs sends some meta identifying the invoice used when broadcasting.
 type BroadcastArgs struct {
 	InvoiceID   string
@@ -15,6 +20,34 @@ type BroadcastArgs struct {
 
 // BroadcastWriter is used to submit a transaction for public broadcast to nodes.
 type BroadcastWriter interface {
-	// Broadcast will submit a tx to a blockchain network.
+	// Broadcast will submit a tx to a blockchain network, ErrTxDoubleSpent is returned if
+	// it is rejected as a double spend.
 	Broadcast(ctx context.Context, args BroadcastArgs, tx *bt.Tx) error
 }
+
+// BroadcastStatus is a miner's view of a broadcast tx.
+type BroadcastStatus struct {
+	TxID string
+	// Known is false if the miner has no record of the tx, in its mempool or a block.
+	Known bool
+	// Description is the miner's description of the status.
+	Description string
+	// BlockHash and BlockHeight are set once the tx is mined.
+	BlockHash   string
+	BlockHeight uint32
+	// Proof is the merkle proof of the tx in BlockHash, it is nil until the tx is mined.
+	Proof *bc.MerkleProof
+}
+
+// BroadcastReader is used to query miners for the status of a broadcast tx.
+type BroadcastReader interface {
+	// BroadcastStatus returns the status of a tx.
+	BroadcastStatus(ctx context.Context, txID string) (*BroadcastStatus, error)
+}
+
+// BroadcastStatusService is used to check on broadcast txs that haven't received a merkle proof.
+type BroadcastStatusService interface {
+	// BroadcastsPoll queries the status of the broadcast txs without a proof, returning
+	// the number of txs rebroadcast, failed or given a proof.
+	BroadcastsPoll(ctx context.Context) (int, error)
+}
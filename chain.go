@@ -0,0 +1,47 @@
+package payd
+
+import (
+	"context"
+
+	"github.com/libsv/go-bc"
+)
+
+// ChainTip is the last block of the best chain.
+type ChainTip struct {
+	Hash   string `json:"hash"`
+	Height uint32 `json:"height"`
+}
+
+// Confirmations returns the confirmations of a block at height, a block at the tip has 1 confirmation.
+func (c ChainTip) Confirmations(height uint32) uint32 {
+	if height > c.Height {
+		return 0
+	}
+	return c.Height - height + 1
+}
+
+// ChainTipReader is used to read the tip of the best chain from a headers client.
+type ChainTipReader interface {
+	ChainTip(ctx context.Context) (*ChainTip, error)
+}
+
+// BlockHeightReader is used to read the height of a block from a headers client.
+type BlockHeightReader interface {
+	// BlockHeight returns the height of a block on the best chain. bc.ErrHeaderNotFound is returned if
+	// the block isn't known and bc.ErrNotOnLongestChain if it isn't on the best chain.
+	BlockHeight(ctx context.Context, blockHash string) (uint32, error)
+}
+
+// HeaderChain is a source of the block headers of the best chain, such as headers-sv or a node.
+type HeaderChain interface {
+	bc.BlockHeaderChain
+	BlockHeightReader
+	ChainTipReader
+}
+
+// HeaderSyncer keeps a local copy of the headers of the best chain up to date.
+type HeaderSyncer interface {
+	// HeadersSync will store the headers added to the best chain since the last sync,
+	// returning the number stored.
+	HeadersSync(ctx context.Context) (int, error)
+}
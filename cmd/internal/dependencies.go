@@ -7,37 +7,53 @@ import (
 
 	"github.com/libsv/payd/log"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/libsv/go-bc/spv"
 	"github.com/theflyingcodr/sockets/client"
 	"github.com/tonicpow/go-minercraft"
 
 	"github.com/libsv/payd"
 	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/data"
+	dataHeaders "github.com/libsv/payd/data/headers"
 	dataHttp "github.com/libsv/payd/data/http"
 	"github.com/libsv/payd/data/mapi"
 	dsoc "github.com/libsv/payd/data/sockets"
-	paydSQL "github.com/libsv/payd/data/sqlite"
 	"github.com/libsv/payd/service"
 )
 
 // RestDeps contains all dependencies used for the rest client.
 type RestDeps struct {
-	DestinationService    payd.DestinationsService
-	PaymentService        payd.PaymentsService
-	PaymentRequestService payd.PaymentRequestService
-	PayService            payd.PayService
-	EnvelopeService       payd.EnvelopeService
-	InvoiceService        payd.InvoiceService
-	BalanceService        payd.BalanceService
-	ProofService          payd.ProofsService
-	OwnerService          payd.OwnerService
-	UserService           payd.UserService
-	TransactionService    payd.TransactionService
+	DestinationService        payd.DestinationsService
+	PaymentService            payd.PaymentsService
+	PaymentRequestService     payd.PaymentRequestService
+	PayService                payd.PayService
+	EnvelopeService           payd.EnvelopeService
+	InvoiceService            payd.InvoiceService
+	RefundService             payd.RefundService
+	InvoiceExpiryService      payd.InvoiceExpiryService
+	BalanceService            payd.BalanceService
+	ProofService              payd.ProofsService
+	OwnerService              payd.OwnerService
+	UserService               payd.UserService
+	TransactionService        payd.TransactionService
+	WebhookService            payd.WebhookService
+	ProofCallbackService      payd.ProofCallbackService
+	UTXOReservationService    payd.UTXOReservationService
+	UTXOService               payd.UTXOService
+	UTXOMaintenanceService    payd.UTXOMaintenanceService
+	TransactionHistoryService payd.TransactionHistoryService
+	PrivateKeyService         payd.PrivateKeyService
+	WalletLockService         payd.WalletLockService
+	KeyBackupService          payd.KeyBackupService
+	ConfirmationService       payd.ConfirmationService
+	ReorgService              payd.ReorgService
+	BroadcastStatusService    payd.BroadcastStatusService
+	HeaderChain               payd.HeaderChain
+	HeaderSyncer              payd.HeaderSyncer
 }
 
 // SetupRestDeps will setup dependencies used in the rest server.
-func SetupRestDeps(cfg *config.Config, l log.Logger, db *sqlx.DB, c *client.Client) *RestDeps {
+func SetupRestDeps(cfg *config.Config, l log.Logger, store data.Store, transacter payd.Transacter, c *client.Client) *RestDeps {
 	mapiCli, err := minercraft.NewClient(nil, nil, []*minercraft.Miner{
 		{
 			Name:  cfg.Mapi.MinerName,
@@ -48,62 +64,137 @@ func SetupRestDeps(cfg *config.Config, l log.Logger, db *sqlx.DB, c *client.Clie
 	if err != nil {
 		l.Fatal(err, "failed to setup mapi client")
 	}
-	sqlLiteStore := paydSQL.NewSQLiteStore(db)
-	proofSvc := service.NewProofsService(sqlLiteStore, l)
+	webhookSvc := service.NewWebhooks(l, cfg.Webhooks, store,
+		dataHttp.NewWebhooks(&http.Client{Timeout: cfg.Webhooks.Timeout}), service.NewTimestampService())
+	headers, headerSyncer := setupHeaders(cfg.HeadersClient)
+	spvv, err := spv.NewPaymentVerifier(headers)
+	if err != nil {
+		l.Fatal(err, "failed to create spv client")
+	}
+	proofSvc := service.NewProofsService(store, store, store, webhookSvc, transacter, service.NewTimestampService(), headers, spvv, cfg.Wallet, l)
+	proofCallbackSvc := service.NewProofCallbacks(l, cfg.ProofCallbacks, store,
+		dataHttp.NewProofCallbacks(&http.Client{Timeout: cfg.ProofCallbacks.Timeout}), service.NewTimestampService())
 
-	pcSvc := service.NewPeerChannelsSvc(sqlLiteStore, cfg.PeerChannels, &paydSQL.Transacter{})
+	pcSvc := service.NewPeerChannelsSvc(store, cfg.PeerChannels, transacter)
 	pcNotifSvc := service.NewPeerChannelsNotifyService(cfg.PeerChannels, pcSvc)
 	pcNotifSvc.RegisterHandler(payd.PeerChannelHandlerTypeProof, proofSvc)
 
 	mapiStore := mapi.NewMapi(cfg.Mapi, mapiCli, l)
-	spvv, err := spv.NewPaymentVerifier(dataHttp.NewHeaderSVConnection(&http.Client{Timeout: time.Duration(cfg.HeadersClient.Timeout) * time.Second}, cfg.HeadersClient.Address))
-	if err != nil {
-		l.Fatal(err, "failed to create spv client")
-	}
 
-	spvc, err := spv.NewEnvelopeCreator(sqlLiteStore, sqlLiteStore)
+	spvc, err := spv.NewEnvelopeCreator(store, store)
 	if err != nil {
 		l.Fatal(err, "failed to create spv verifier")
 	}
 
 	seedSvc := service.NewSeedService()
-	privKeySvc := service.NewPrivateKeys(sqlLiteStore, cfg.Wallet.Network == "mainnet")
-	destSvc := service.NewDestinationsService(cfg.Wallet, privKeySvc, sqlLiteStore, sqlLiteStore, sqlLiteStore, seedSvc)
-	paymentSvc := service.NewPayments(l, spvv, sqlLiteStore, sqlLiteStore, sqlLiteStore, &paydSQL.Transacter{}, mapiStore, sqlLiteStore, sqlLiteStore, pcSvc, pcNotifSvc, cfg.PeerChannels)
-	envSvc := service.NewEnvelopes(privKeySvc, sqlLiteStore, sqlLiteStore, sqlLiteStore, seedSvc, spvc)
+	privKeySvc := service.NewPrivateKeys(store, cfg.Wallet)
+	destSvc := service.NewDestinationsService(cfg.Wallet, privKeySvc, store, store, store, seedSvc)
+	paymentSvc := service.NewPayments(l, spvv, store, store, store, transacter, mapiStore, store, store, pcSvc, pcNotifSvc, webhookSvc, cfg.PeerChannels)
+	envSvc := service.NewEnvelopes(privKeySvc, store, store, store, seedSvc, spvc, cfg.Wallet)
 	paySvc := service.NewPayStrategy().Register(
-		service.NewPayService(&paydSQL.Transacter{}, dataHttp.NewDPP(&http.Client{Timeout: time.Duration(cfg.DPP.Timeout) * time.Second}), envSvc, cfg.Server, pcNotifSvc, sqlLiteStore, sqlLiteStore, cfg.Wallet),
+		service.NewPayService(transacter, dataHttp.NewDPP(&http.Client{Timeout: time.Duration(cfg.DPP.Timeout) * time.Second}), envSvc, cfg.Server, pcNotifSvc, store, store, store, cfg.Wallet),
 		"http", "https",
 	).Register(
 		service.NewPayChannel(dsoc.NewPaymentChannel(*cfg.Socket, c)), "ws", "wss",
 	)
-	paymentReqSvc := service.NewPaymentRequest(cfg.Wallet, destSvc, mapiStore, sqlLiteStore, sqlLiteStore, l)
-	invoiceSvc := service.NewInvoice(cfg.Server, cfg.Wallet, sqlLiteStore, destSvc, &paydSQL.Transacter{}, service.NewTimestampService())
-	balanceSvc := service.NewBalance(sqlLiteStore)
+	paymentReqSvc := service.NewPaymentRequest(cfg.Wallet, destSvc, mapiStore, store, store, l)
+	invoiceSvc := service.NewInvoice(cfg.Server, cfg.Wallet, store, destSvc, transacter, service.NewTimestampService(), webhookSvc, headers)
+	refundSvc := service.NewRefunds(l, store, envSvc, store, mapiStore, mapiStore,
+		dataHttp.NewPaymail(&http.Client{Timeout: time.Duration(cfg.DPP.Timeout) * time.Second}), transacter)
+	invoiceExpirySvc := service.NewInvoiceExpiry(l, store, dsoc.NewInvoiceExpiry(*cfg.Socket, c), webhookSvc, service.NewTimestampService())
+	balanceSvc := service.NewBalance(store, headers, cfg.Wallet)
 	connectService := service.NewConnect(dsoc.NewConnect(cfg.DPP, c), invoiceSvc, cfg.DPP)
 	invoiceSvc.SetConnectionService(connectService)
-	ownerSvc := service.NewOwnerService(sqlLiteStore)
-	userSvc := service.NewUsersService(sqlLiteStore, privKeySvc)
+	ownerSvc := service.NewOwnerService(store)
+	userSvc := service.NewUsersService(store, privKeySvc, cfg.Wallet)
 
-	transactionService := service.NewTransactions(&paydSQL.Transacter{}, sqlLiteStore, sqlLiteStore, sqlLiteStore)
+	transactionService := service.NewTransactions(transacter, store, store, store)
+	reservationSvc := service.NewUTXOReservations(l, store, service.NewTimestampService())
+	utxoSvc := service.NewUTXOs(store)
+	utxoMaintenanceSvc := service.NewUTXOMaintenance(l, cfg.Wallet, privKeySvc, store, store, store, seedSvc, mapiStore, mapiStore, transacter)
+	txHistorySvc := service.NewTransactionHistory(store)
+	keyBackupSvc := service.NewKeyBackup(privKeySvc, store, store, cfg.Wallet)
+	confirmationSvc := service.NewConfirmations(cfg.Wallet, headers, store)
+	reorgSvc := service.NewReorg(l, cfg.Wallet, headers, headers, store, store, store, mapiStore, proofSvc,
+		webhookSvc, transacter, service.NewTimestampService())
+	broadcastStatusSvc := service.NewBroadcastStatus(l, cfg.Wallet, store, store, mapiStore, mapiStore, proofSvc,
+		webhookSvc, transacter, service.NewTimestampService())
 
 	// create master private key if it doesn't exist
 	if err = privKeySvc.Create(context.Background(), "masterkey", 1); err != nil {
 		l.Fatal(err, "failed to create master key")
 	}
+	if cfg.Wallet.Passphrase != "" {
+		unlockWallet(context.Background(), l, cfg.Wallet, privKeySvc)
+	}
 
 	return &RestDeps{
-		DestinationService:    destSvc,
-		PaymentService:        paymentSvc,
-		PaymentRequestService: paymentReqSvc,
-		PayService:            paySvc,
-		EnvelopeService:       envSvc,
-		InvoiceService:        invoiceSvc,
-		BalanceService:        balanceSvc,
-		ProofService:          proofSvc,
-		OwnerService:          ownerSvc,
-		UserService:           userSvc,
-		TransactionService:    transactionService,
+		DestinationService:        destSvc,
+		PaymentService:            paymentSvc,
+		PaymentRequestService:     paymentReqSvc,
+		PayService:                paySvc,
+		EnvelopeService:           envSvc,
+		InvoiceService:            invoiceSvc,
+		RefundService:             refundSvc,
+		InvoiceExpiryService:      invoiceExpirySvc,
+		BalanceService:            balanceSvc,
+		ProofService:              proofSvc,
+		OwnerService:              ownerSvc,
+		UserService:               userSvc,
+		TransactionService:        transactionService,
+		WebhookService:            webhookSvc,
+		ProofCallbackService:      proofCallbackSvc,
+		UTXOReservationService:    reservationSvc,
+		UTXOService:               utxoSvc,
+		UTXOMaintenanceService:    utxoMaintenanceSvc,
+		TransactionHistoryService: txHistorySvc,
+		PrivateKeyService:         privKeySvc,
+		WalletLockService:         privKeySvc,
+		KeyBackupService:          keyBackupSvc,
+		ConfirmationService:       confirmationSvc,
+		ReorgService:              reorgSvc,
+		BroadcastStatusService:    broadcastStatusSvc,
+		HeaderChain:               headers,
+		HeaderSyncer:              headerSyncer,
+	}
+}
+
+// setupHeaders returns the block header source selected in cfg, wrapped in a local header
+// cache unless it is disabled, in which case the returned syncer is nil.
+func setupHeaders(cfg *config.HeadersClient) (payd.HeaderChain, payd.HeaderSyncer) {
+	client := &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}
+	var source payd.HeaderChain
+	switch cfg.Source {
+	case config.HeadersSourceRPC:
+		source = dataHttp.NewBitcoindConnection(client, cfg.Address, cfg.RPCUser, cfg.RPCPassword)
+	default:
+		source = dataHttp.NewHeaderSVConnection(client, cfg.Address)
+	}
+	if cfg.CacheBlocks == 0 {
+		return source, nil
+	}
+	cache := dataHeaders.NewCache(source, cfg.CacheBlocks, cfg.CacheOlder)
+	return cache, cache
+}
+
+// unlockWallet unlocks the wallet with the configured passphrase, encrypting it first
+// if it isn't already encrypted.
+func unlockWallet(ctx context.Context, l log.Logger, cfg *config.Wallet, svc payd.WalletLockService) {
+	status, err := svc.WalletLockStatus(ctx)
+	if err != nil {
+		l.Fatal(err, "failed to get wallet lock status")
+	}
+	if !status.Encrypted {
+		if err := svc.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: cfg.Passphrase}); err != nil {
+			l.Fatal(err, "failed to encrypt wallet")
+		}
+		l.Info("wallet private keys encrypted")
+	}
+	if err := svc.WalletUnlock(ctx, payd.WalletUnlock{
+		Passphrase: cfg.Passphrase,
+		Timeout:    int64(cfg.UnlockTimeout / time.Second),
+	}); err != nil {
+		l.Fatal(err, "failed to unlock wallet")
 	}
 }
 
@@ -122,10 +213,14 @@ type SocketDeps struct {
 	TransactionService        payd.TransactionService
 	PeerChannelsService       payd.PeerChannelsService
 	PeerChannelsNotifyService payd.PeerChannelsNotifyService
+	Transacter                payd.Transacter
 }
 
-// SetupSocketDeps will setup dependencies used in the socket server.
-func SetupSocketDeps(cfg *config.Config, l log.Logger, db *sqlx.DB, c *client.Client) *SocketDeps {
+// SetupSocketDeps will setup dependencies used in the socket server, privKeySvc
+// is shared with the rest server so both see the same wallet lock, and headers so
+// both read the same header cache.
+func SetupSocketDeps(cfg *config.Config, l log.Logger, store data.Store, transacter payd.Transacter, c *client.Client,
+	privKeySvc payd.PrivateKeyService, headers payd.HeaderChain) *SocketDeps {
 	mapiCli, err := minercraft.NewClient(nil, nil, []*minercraft.Miner{
 		{
 			Name:  cfg.Mapi.MinerName,
@@ -136,43 +231,38 @@ func SetupSocketDeps(cfg *config.Config, l log.Logger, db *sqlx.DB, c *client.Cl
 	if err != nil {
 		l.Fatal(err, "failed to setup mapi client")
 	}
-	sqlLiteStore := paydSQL.NewSQLiteStore(db)
-	proofSvc := service.NewProofsService(sqlLiteStore, l)
-	pcSvc := service.NewPeerChannelsSvc(sqlLiteStore, cfg.PeerChannels, &paydSQL.Transacter{})
-	pcNotifSvc := service.NewPeerChannelsNotifyService(cfg.PeerChannels, pcSvc)
-	pcNotifSvc.RegisterHandler(payd.PeerChannelHandlerTypeProof, proofSvc)
-	mapiStore := mapi.NewMapi(cfg.Mapi, mapiCli, l)
-	spvv, err := spv.NewPaymentVerifier(dataHttp.NewHeaderSVConnection(&http.Client{Timeout: time.Duration(cfg.HeadersClient.Timeout) * time.Second}, cfg.HeadersClient.Address))
+	webhookSvc := service.NewWebhooks(l, cfg.Webhooks, store,
+		dataHttp.NewWebhooks(&http.Client{Timeout: cfg.Webhooks.Timeout}), service.NewTimestampService())
+	spvv, err := spv.NewPaymentVerifier(headers)
 	if err != nil {
 		l.Fatal(err, "failed to create spv client")
 	}
+	proofSvc := service.NewProofsService(store, store, store, webhookSvc, transacter, service.NewTimestampService(), headers, spvv, cfg.Wallet, l)
+	pcSvc := service.NewPeerChannelsSvc(store, cfg.PeerChannels, transacter)
+	pcNotifSvc := service.NewPeerChannelsNotifyService(cfg.PeerChannels, pcSvc)
+	pcNotifSvc.RegisterHandler(payd.PeerChannelHandlerTypeProof, proofSvc)
+	mapiStore := mapi.NewMapi(cfg.Mapi, mapiCli, l)
 
-	spvc, err := spv.NewEnvelopeCreator(sqlLiteStore, sqlLiteStore)
+	spvc, err := spv.NewEnvelopeCreator(store, store)
 	if err != nil {
 		l.Fatal(err, "failed to create spv verifier")
 	}
 
 	seedSvc := service.NewSeedService()
-	privKeySvc := service.NewPrivateKeys(sqlLiteStore, cfg.Wallet.Network == "mainnet")
-	destSvc := service.NewDestinationsService(cfg.Wallet, privKeySvc, sqlLiteStore, sqlLiteStore, sqlLiteStore, seedSvc)
-	paymentSvc := service.NewPayments(l, spvv, sqlLiteStore, sqlLiteStore, sqlLiteStore, &paydSQL.Transacter{}, mapiStore, sqlLiteStore, sqlLiteStore, pcSvc, pcNotifSvc, cfg.PeerChannels)
-	envSvc := service.NewEnvelopes(privKeySvc, sqlLiteStore, sqlLiteStore, sqlLiteStore, seedSvc, spvc)
+	destSvc := service.NewDestinationsService(cfg.Wallet, privKeySvc, store, store, store, seedSvc)
+	paymentSvc := service.NewPayments(l, spvv, store, store, store, transacter, mapiStore, store, store, pcSvc, pcNotifSvc, webhookSvc, cfg.PeerChannels)
+	envSvc := service.NewEnvelopes(privKeySvc, store, store, store, seedSvc, spvc, cfg.Wallet)
 	paySvc := service.NewPayStrategy().Register(
-		service.NewPayService(&paydSQL.Transacter{}, dataHttp.NewDPP(&http.Client{Timeout: time.Duration(cfg.DPP.Timeout) * time.Second}), envSvc, cfg.Server, pcNotifSvc, sqlLiteStore, sqlLiteStore, cfg.Wallet),
+		service.NewPayService(transacter, dataHttp.NewDPP(&http.Client{Timeout: time.Duration(cfg.DPP.Timeout) * time.Second}), envSvc, cfg.Server, pcNotifSvc, store, store, store, cfg.Wallet),
 		"http", "https",
 	).Register(service.NewPayChannel(dsoc.NewPaymentChannel(*cfg.Socket, c)), "ws", "wss")
-	invoiceSvc := service.NewInvoice(cfg.Server, cfg.Wallet, sqlLiteStore, destSvc, &paydSQL.Transacter{}, service.NewTimestampService())
-	balanceSvc := service.NewBalance(sqlLiteStore)
-	ownerSvc := service.NewOwnerService(sqlLiteStore)
-	paymentReqSvc := service.NewPaymentRequest(cfg.Wallet, destSvc, mapiStore, sqlLiteStore, sqlLiteStore, l)
+	invoiceSvc := service.NewInvoice(cfg.Server, cfg.Wallet, store, destSvc, transacter, service.NewTimestampService(), webhookSvc, headers)
+	balanceSvc := service.NewBalance(store, headers, cfg.Wallet)
+	ownerSvc := service.NewOwnerService(store)
+	paymentReqSvc := service.NewPaymentRequest(cfg.Wallet, destSvc, mapiStore, store, store, l)
 	connectService := service.NewConnect(dsoc.NewConnect(cfg.DPP, c), invoiceSvc, cfg.DPP)
 	invoiceSvc.SetConnectionService(connectService)
-	transactionService := service.NewTransactions(&paydSQL.Transacter{}, sqlLiteStore, sqlLiteStore, sqlLiteStore)
-
-	// create master private key if it doesn't exist
-	if err = privKeySvc.Create(context.Background(), "masterkey", 1); err != nil {
-		l.Fatal(err, "failed to create master key")
-	}
+	transactionService := service.NewTransactions(transacter, store, store, store)
 
 	return &SocketDeps{
 		DestinationService:        destSvc,
@@ -188,5 +278,6 @@ func SetupSocketDeps(cfg *config.Config, l log.Logger, db *sqlx.DB, c *client.Cl
 		TransactionService:        transactionService,
 		PeerChannelsService:       pcSvc,
 		PeerChannelsNotifyService: pcNotifSvc,
+		Transacter:                transacter,
 	}
 }
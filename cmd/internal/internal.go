@@ -12,7 +12,6 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/libsv/payd"
 	"github.com/libsv/payd/config"
-	paydSQL "github.com/libsv/payd/data/sqlite"
 	"github.com/libsv/payd/docs"
 	"github.com/libsv/payd/dpp"
 	"github.com/libsv/payd/log"
@@ -66,6 +65,7 @@ func SetupHTTPEndpoints(cfg config.Config, services *RestDeps, g *echo.Group) {
 	// handlers
 	thttp.NewInvoice(services.InvoiceService).
 		RegisterRoutes(g)
+	thttp.NewRefunds(services.RefundService).RegisterRoutes(g)
 	thttp.NewBalance(services.BalanceService).RegisterRoutes(g)
 	thttp.NewProofs(services.ProofService).RegisterRoutes(g)
 	thttp.NewPayments(services.PaymentService).RegisterRoutes(g)
@@ -73,6 +73,13 @@ func SetupHTTPEndpoints(cfg config.Config, services *RestDeps, g *echo.Group) {
 	thttp.NewOwnersHandler(services.OwnerService).RegisterRoutes(g)
 	thttp.NewUsersHandler(services.UserService).RegisterRoutes(g)
 	thttp.NewPayHandler(services.PayService).RegisterRoutes(g)
+	thttp.NewWebhooks(services.WebhookService).RegisterRoutes(g)
+	thttp.NewUTXOReservations(services.UTXOReservationService).RegisterRoutes(g)
+	thttp.NewUTXOs(services.UTXOService).RegisterRoutes(g)
+	thttp.NewUTXOMaintenance(services.UTXOMaintenanceService).RegisterRoutes(g)
+	thttp.NewTransactionHistory(services.TransactionHistoryService).RegisterRoutes(g)
+	thttp.NewWalletLock(services.WalletLockService).RegisterRoutes(g)
+	thttp.NewKeyBackup(services.KeyBackupService).RegisterRoutes(g)
 	if cfg.Deployment.Environment == "local" {
 		// ugly endpoint for regtest topup - local only!
 		thttp.NewTransactions(services.TransactionService).RegisterRoutes(g)
@@ -95,7 +102,7 @@ func SetupSocketClient(cfg config.Config, deps *SocketDeps, c *client.Client) {
 		WithServerErrorHandler(socMiddleware.ErrorMsgHandler)
 
 	// client handlers
-	tsoc.NewPaymentRequest(&paydSQL.Transacter{}, deps.PaymentRequestService, deps.EnvelopeService, cfg.DPP).
+	tsoc.NewPaymentRequest(deps.Transacter, deps.PaymentRequestService, deps.EnvelopeService, cfg.DPP).
 		RegisterListeners(c)
 	tsoc.NewPayments(deps.PaymentService).
 		RegisterListeners(c)
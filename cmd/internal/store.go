@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/config/databases"
+	"github.com/libsv/payd/data"
+	paydBadger "github.com/libsv/payd/data/badger"
+	paydMemory "github.com/libsv/payd/data/memory"
+	paydMySQL "github.com/libsv/payd/data/mysql"
+	paydPostgres "github.com/libsv/payd/data/postgres"
+	paydSQL "github.com/libsv/payd/data/sqlite"
+	"github.com/libsv/payd/log"
+)
+
+// SetupStore will connect to the configured db returning the data store and transacter
+// for it, the returned closer should be closed on shutdown.
+func SetupStore(l log.Logger, cfg *config.Db) (data.Store, payd.Transacter, io.Closer, error) {
+	switch cfg.Type {
+	case config.DBMemory:
+		l.Info("using in-memory data store, data will be lost on shutdown")
+		return paydMemory.NewMemoryStore(), &paydMemory.Transacter{}, nopCloser{}, nil
+	case config.DBBadger:
+		db, err := databases.SetupBadgerDB(l, cfg)
+		if err != nil {
+			return nil, nil, nil, errors.WithStack(err)
+		}
+		return paydBadger.NewBadgerStore(db), &paydBadger.Transacter{}, db, nil
+	}
+	db, err := databases.NewDbSetup().SetupDb(l, cfg)
+	if err != nil {
+		return nil, nil, nil, errors.WithStack(err)
+	}
+	switch cfg.Type {
+	case config.DBMySQL:
+		return paydMySQL.NewMySQLStore(db), &paydMySQL.Transacter{}, db, nil
+	case config.DBPostgres:
+		return paydPostgres.NewPostgresStore(db), &paydPostgres.Transacter{}, db, nil
+	default:
+		return paydSQL.NewSQLiteStore(db), &paydSQL.Transacter{}, db, nil
+	}
+}
+
+// nopCloser is returned for stores with nothing to close.
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/libsv/payd/transports/http/middleware"
 	"github.com/theflyingcodr/sockets/client"
 
+	"github.com/libsv/payd"
 	"github.com/libsv/payd/config"
-	"github.com/libsv/payd/config/databases"
 	_ "github.com/libsv/payd/docs"
 	"github.com/libsv/payd/log"
 )
@@ -61,6 +61,8 @@ func main() {
 		WithSocket().
 		WithTransports().
 		WithPeerChannels().
+		WithWebhooks().
+		WithProofCallbacks().
 		Load()
 	log := log.NewZero(cfg.Logging)
 	// validate the config, fail if it fails.
@@ -68,7 +70,7 @@ func main() {
 		log.Fatal(err, "validation errors")
 	}
 	log.Infof("------Environment: %v -----", cfg.Server)
-	db, err := databases.NewDbSetup().SetupDb(log, cfg.Db)
+	store, transacter, db, err := internal.SetupStore(log, cfg.Db)
 	if err != nil {
 		log.Fatal(err, "failed to setup database")
 	}
@@ -84,7 +86,7 @@ func main() {
 	c := client.New(client.WithMaxMessageSize(10000), client.WithPongTimeout(360*time.Second))
 	defer c.Close()
 
-	rDeps := internal.SetupRestDeps(cfg, log, db, c)
+	rDeps := internal.SetupRestDeps(cfg, log, store, transacter, c)
 	e.Use(middleware.AuthUser(log, rDeps.UserService))
 
 	g := e.Group("/")
@@ -92,7 +94,7 @@ func main() {
 	internal.SetupHTTPEndpoints(*cfg, rDeps, g)
 
 	// setup sockets
-	deps := internal.SetupSocketDeps(cfg, log, db, c)
+	deps := internal.SetupSocketDeps(cfg, log, store, transacter, c, rDeps.PrivateKeyService, rDeps.HeaderChain)
 	internal.SetupSocketClient(*cfg, deps, c)
 	// setup socket endpoints
 	internal.SetupSocketHTTPEndpoints(*cfg.Deployment, deps, g)
@@ -110,6 +112,106 @@ func main() {
 			time.Sleep(30 * time.Minute)
 		}
 	}()
+	if cfg.Wallet.ExpiryInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.InvoiceExpiryService.InvoicesExpire(context.Background()); err != nil {
+					log.Error(err, "failed to expire invoices")
+				}
+				time.Sleep(cfg.Wallet.ExpiryInterval)
+			}
+		}()
+	}
+	if cfg.Webhooks.Interval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.WebhookService.WebhooksDeliver(context.Background()); err != nil {
+					log.Error(err, "failed to deliver webhooks")
+				}
+				time.Sleep(cfg.Webhooks.Interval)
+			}
+		}()
+	}
+	if cfg.ProofCallbacks.Interval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.ProofCallbackService.ProofCallbacksSend(context.Background()); err != nil {
+					log.Error(err, "failed to send proof callbacks")
+				}
+				time.Sleep(cfg.ProofCallbacks.Interval)
+			}
+		}()
+	}
+	if cfg.Wallet.ReservationInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.UTXOReservationService.UTXOReservationsExpire(context.Background()); err != nil {
+					log.Error(err, "failed to release expired utxo reservations")
+				}
+				time.Sleep(cfg.Wallet.ReservationInterval)
+			}
+		}()
+	}
+	if cfg.Wallet.ConsolidateInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.UTXOMaintenanceService.UTXOsConsolidate(context.Background(), payd.UTXOsConsolidate{}); err != nil {
+					log.Error(err, "failed to consolidate utxos")
+				}
+				time.Sleep(cfg.Wallet.ConsolidateInterval)
+			}
+		}()
+	}
+	if cfg.Wallet.FanOutInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.UTXOMaintenanceService.UTXOsFanOut(context.Background(), payd.UTXOsFanOut{}); err != nil {
+					log.Error(err, "failed to fan out utxos")
+				}
+				time.Sleep(cfg.Wallet.FanOutInterval)
+			}
+		}()
+	}
+	if cfg.HeadersClient.CacheBlocks > 0 && cfg.HeadersClient.SyncInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.HeaderSyncer.HeadersSync(context.Background()); err != nil {
+					log.Error(err, "failed to sync block headers")
+				}
+				time.Sleep(cfg.HeadersClient.SyncInterval)
+			}
+		}()
+	}
+	if cfg.Wallet.ConfirmInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.ConfirmationService.TransactionsConfirm(context.Background()); err != nil {
+					log.Error(err, "failed to confirm transactions")
+				}
+				time.Sleep(cfg.Wallet.ConfirmInterval)
+			}
+		}()
+	}
+	if cfg.Wallet.ReorgInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.ReorgService.ProofsReorg(context.Background()); err != nil {
+					log.Error(err, "failed to check proofs for reorgs")
+				}
+				time.Sleep(cfg.Wallet.ReorgInterval)
+			}
+		}()
+	}
+	if cfg.Wallet.PollInterval > 0 {
+		go func() {
+			for {
+				if _, err := rDeps.BroadcastStatusService.BroadcastsPoll(context.Background()); err != nil {
+					log.Error(err, "failed to poll broadcast transactions")
+				}
+				time.Sleep(cfg.Wallet.PollInterval)
+			}
+		}()
+	}
 	if err := internal.ResumeSocketConnections(deps, cfg.DPP); err != nil {
 		log.Error(err, "failed to reconnect invoices with dpp")
 	}
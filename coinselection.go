@@ -0,0 +1,42 @@
+package payd
+
+// CoinSelection is the strategy used to choose which utxos fund a transaction.
+type CoinSelection string
+
+// Supported coin selection strategies.
+const (
+	// CoinSelectionLargestFirst spends the largest utxos first, using the fewest inputs.
+	CoinSelectionLargestFirst CoinSelection = "largest"
+	// CoinSelectionSmallestFirst spends the smallest utxos first, consolidating dust.
+	CoinSelectionSmallestFirst CoinSelection = "smallest"
+	// CoinSelectionBranchAndBound looks for utxos matching the amount closely enough that
+	// no change is needed, falling back to largest first when there is no match.
+	CoinSelectionBranchAndBound CoinSelection = "bnb"
+	// CoinSelectionOldestFirst spends the oldest utxos first.
+	CoinSelectionOldestFirst CoinSelection = "oldest"
+	// CoinSelectionPrivacy avoids spending utxos from different derivation branches together.
+	CoinSelectionPrivacy CoinSelection = "privacy"
+)
+
+func (c CoinSelection) String() string {
+	return string(c)
+}
+
+// CoinSelectArgs are used to choose the utxos funding a transaction.
+type CoinSelectArgs struct {
+	// Satoshis is the amount the selected utxos must exceed once the fees for
+	// spending them are taken off.
+	Satoshis uint64
+	// InputFee is the fee paid to spend each utxo.
+	InputFee uint64
+	// ChangeFee is the fee paid to add a change output, a selection exceeding
+	// Satoshis by less than this won't have change.
+	ChangeFee uint64
+}
+
+// CoinSelector chooses the utxos to spend to fund a transaction.
+type CoinSelector interface {
+	// SelectCoins returns the utxos to spend, chosen from utxos. If there aren't
+	// enough funds no utxos are returned.
+	SelectCoins(args CoinSelectArgs, utxos []UTXO) []UTXO
+}
@@ -10,41 +10,80 @@ import (
 
 // Environment variable constants.
 const (
-	EnvServerPort               = "server.port"
-	EnvServerHost               = "server.host"
-	EnvServerSwaggerEnabled     = "server.swagger.enabled"
-	EnvServerSwaggerHost        = "server.swagger.host"
-	EnvEnvironment              = "env.environment"
-	EnvRegion                   = "env.region"
-	EnvVersion                  = "env.version"
-	EnvCommit                   = "env.commit"
-	EnvBuildDate                = "env.builddate"
-	EnvBitcoinNetwork           = "env.bitcoin.network"
-	EnvLogLevel                 = "log.level"
-	EnvDb                       = "db.type"
-	EnvDbSchema                 = "db.schema.path"
-	EnvDbDsn                    = "db.dsn"
-	EnvDbMigrate                = "db.migrate"
-	EnvHeadersClientAddress     = "headersclient.address"
-	EnvHeadersClientTimeout     = "headersclient.timeout"
-	EnvNetwork                  = "wallet.network"
-	EnvWalletSpvRequired        = "wallet.spvrequired"
-	EnvPaymentExpiry            = "wallet.paymentexpiry"
-	EnvWalletPayoutLimitSats    = "wallet.payoutlimit.sats" // max allowed to be paid
-	EnvWalletPayoutLimitEnabled = "wallet.payoutlimit.enabled"
-	EnvDPPTimeout               = "dpp.timeout"
-	EnvDPPHost                  = "dpp.host"
-	EnvMAPIMinerName            = "mapi.minername"
-	EnvMAPIURL                  = "mapi.minerurl"
-	EnvMAPIToken                = "mapi.token"
-	EnvMAPICallbackHost         = "mapi.callback.host"
-	EnvSocketMaxMessageBytes    = "socket.maxmessage.bytes"
-	EnvTransportHTTPEnabled     = "transport.http.enabled"
-	EnvTransportSocketsEnabled  = "transport.sockets.enabled"
-	EnvPeerChannelsHost         = "peerchannels.host"
-	EnvPeerChannelsPath         = "peerchannels.path"
-	EnvPeerChannelsTLS          = "peerchannels.tls"
-	EnvPeerChannelsTTL          = "peerchannels.ttl.minutes"
+	EnvServerPort                = "server.port"
+	EnvServerHost                = "server.host"
+	EnvServerSwaggerEnabled      = "server.swagger.enabled"
+	EnvServerSwaggerHost         = "server.swagger.host"
+	EnvEnvironment               = "env.environment"
+	EnvRegion                    = "env.region"
+	EnvVersion                   = "env.version"
+	EnvCommit                    = "env.commit"
+	EnvBuildDate                 = "env.builddate"
+	EnvBitcoinNetwork            = "env.bitcoin.network"
+	EnvLogLevel                  = "log.level"
+	EnvDb                        = "db.type"
+	EnvDbSchema                  = "db.schema.path"
+	EnvDbDsn                     = "db.dsn"
+	EnvDbMigrate                 = "db.migrate"
+	EnvHeadersClientAddress      = "headersclient.address"
+	EnvHeadersClientTimeout      = "headersclient.timeout"
+	EnvHeadersClientSource       = "headersclient.source"
+	EnvHeadersClientRPCUser      = "headersclient.rpc.user"
+	EnvHeadersClientRPCPassword  = "headersclient.rpc.password"
+	EnvHeadersClientCacheBlocks  = "headersclient.cache.blocks"
+	EnvHeadersClientCacheOlder   = "headersclient.cache.older"
+	EnvHeadersClientSyncInterval = "headersclient.sync.interval.seconds"
+	EnvNetwork                   = "wallet.network"
+	EnvWalletSpvRequired         = "wallet.spvrequired"
+	EnvPaymentExpiry             = "wallet.paymentexpiry"
+	EnvWalletPayoutLimitSats     = "wallet.payoutlimit.sats" // max allowed to be paid
+	EnvWalletPayoutLimitEnabled  = "wallet.payoutlimit.enabled"
+	EnvWalletExpiryInterval      = "wallet.expiry.interval.seconds"
+	EnvWalletCoinSelection       = "wallet.coinselection"
+	EnvWalletReservationTTL      = "wallet.reservation.ttl.seconds"
+	EnvWalletReservationInterval = "wallet.reservation.interval.seconds"
+	EnvWalletConsolidateInterval = "wallet.consolidate.interval.seconds"
+	EnvWalletConsolidateSatoshis = "wallet.consolidate.satoshis"
+	EnvWalletConsolidateMinUTXOs = "wallet.consolidate.minutxos"
+	EnvWalletConsolidateMaxUTXOs = "wallet.consolidate.maxutxos"
+	EnvWalletFanOutInterval      = "wallet.fanout.interval.seconds"
+	EnvWalletFanOutSatoshis      = "wallet.fanout.satoshis"
+	EnvWalletFanOutOutputs       = "wallet.fanout.outputs"
+	EnvWalletOutputSplit         = "wallet.outputs.split"
+	EnvWalletOutputDenomination  = "wallet.outputs.denomination"
+	EnvWalletOutputMax           = "wallet.outputs.max"
+	EnvWalletPassphrase          = "wallet.passphrase"
+	EnvWalletUnlockTimeout       = "wallet.unlock.timeout.seconds"
+	EnvWalletMnemonicPassphrase  = "wallet.mnemonic.passphrase"
+	EnvWalletConfirmationsMin    = "wallet.confirmations.min"
+	EnvWalletConfirmInterval     = "wallet.confirmations.interval.seconds"
+	EnvWalletReorgInterval       = "wallet.reorg.interval.seconds"
+	EnvWalletReorgDepth          = "wallet.reorg.depth"
+	EnvWalletPollInterval        = "wallet.poll.interval.seconds"
+	EnvWalletPollGrace           = "wallet.poll.grace.seconds"
+	EnvDPPTimeout                = "dpp.timeout"
+	EnvDPPHost                   = "dpp.host"
+	EnvMAPIMinerName             = "mapi.minername"
+	EnvMAPIURL                   = "mapi.minerurl"
+	EnvMAPIToken                 = "mapi.token"
+	EnvMAPICallbackHost          = "mapi.callback.host"
+	EnvSocketMaxMessageBytes     = "socket.maxmessage.bytes"
+	EnvTransportHTTPEnabled      = "transport.http.enabled"
+	EnvTransportSocketsEnabled   = "transport.sockets.enabled"
+	EnvPeerChannelsHost          = "peerchannels.host"
+	EnvPeerChannelsPath          = "peerchannels.path"
+	EnvPeerChannelsTLS           = "peerchannels.tls"
+	EnvPeerChannelsTTL           = "peerchannels.ttl.minutes"
+	EnvWebhooksInterval          = "webhooks.interval.seconds"
+	EnvWebhooksTimeout           = "webhooks.timeout.seconds"
+	EnvWebhooksMaxAttempts       = "webhooks.maxattempts"
+	EnvWebhooksBackoff           = "webhooks.backoff.seconds"
+	EnvWebhooksBackoffMax        = "webhooks.backoff.max.seconds"
+	EnvProofCallbacksInterval    = "proofcallbacks.interval.seconds"
+	EnvProofCallbacksTimeout     = "proofcallbacks.timeout.seconds"
+	EnvProofCallbacksMaxAttempts = "proofcallbacks.maxattempts"
+	EnvProofCallbacksBackoff     = "proofcallbacks.backoff.seconds"
+	EnvProofCallbacksBackoffMax  = "proofcallbacks.backoff.max.seconds"
 
 	LogDebug = "debug"
 	LogInfo  = "info"
@@ -67,7 +106,7 @@ func (n NetworkType) String() string {
 	return string(n)
 }
 
-var reDbType = regexp.MustCompile(`sqlite|mysql|postgres`)
+var reDbType = regexp.MustCompile(`sqlite|mysql|postgres|badger|memory`)
 
 // DbType is used to restrict the dbs we can support.
 type DbType string
@@ -77,23 +116,42 @@ const (
 	DBSqlite   DbType = "sqlite"
 	DBMySQL    DbType = "mysql"
 	DBPostgres DbType = "postgres"
+	DBBadger   DbType = "badger"
+	DBMemory   DbType = "memory"
+)
+
+var reHeadersSource = regexp.MustCompile(`^(headersv|rpc)$`)
+
+// HeadersSource is used to restrict the block header sources we can support.
+type HeadersSource string
+
+// Supported block header sources.
+const (
+	HeadersSourceHeaderSV HeadersSource = "headersv"
+	HeadersSourceRPC      HeadersSource = "rpc"
 )
 
 var reNetworks = regexp.MustCompile(`^(regtest|stn|testnet|mainnet)$`)
 
+var reCoinSelection = regexp.MustCompile(`^(largest|smallest|bnb|oldest|privacy)?$`)
+
+var reOutputSplit = regexp.MustCompile(`^(none|denomination|even|random)?$`)
+
 // Config returns strongly typed config values.
 type Config struct {
-	Logging       *Logging
-	Server        *Server
-	Deployment    *Deployment
-	Db            *Db
-	HeadersClient *HeadersClient
-	Wallet        *Wallet
-	PeerChannels  *PeerChannels
-	DPP           *DPP
-	Mapi          *MApi
-	Socket        *Socket
-	Transports    *Transports
+	Logging        *Logging
+	Server         *Server
+	Deployment     *Deployment
+	Db             *Db
+	HeadersClient  *HeadersClient
+	Wallet         *Wallet
+	PeerChannels   *PeerChannels
+	DPP            *DPP
+	Mapi           *MApi
+	Socket         *Socket
+	Transports     *Transports
+	Webhooks       *Webhooks
+	ProofCallbacks *ProofCallbacks
 }
 
 // Validate will ensure the config matches certain parameters.
@@ -102,8 +160,13 @@ func (c *Config) Validate() error {
 	if c.Db != nil {
 		vl = vl.Validate("db.type", validator.MatchString(string(c.Db.Type), reDbType))
 	}
+	if c.HeadersClient != nil {
+		vl = vl.Validate("headersclient.source", validator.MatchString(string(c.HeadersClient.Source), reHeadersSource))
+	}
 	if c.Wallet != nil {
-		vl = vl.Validate("wallet.network", validator.MatchString(string(c.Wallet.Network), reNetworks))
+		vl = vl.Validate("wallet.network", validator.MatchString(string(c.Wallet.Network), reNetworks)).
+			Validate("wallet.coinselection", validator.MatchString(c.Wallet.CoinSelection, reCoinSelection)).
+			Validate("wallet.outputs.split", validator.MatchString(c.Wallet.OutputSplit, reOutputSplit))
 	}
 	return vl.Err()
 }
@@ -153,8 +216,22 @@ type Db struct {
 
 // HeadersClient contains HeadersClient information.
 type HeadersClient struct {
+	// Address is the url of headers-sv, or of the node when Source is rpc.
 	Address string
 	Timeout int
+	// Source is where block headers are read from, headers-sv or a bitcoind compatible node.
+	Source HeadersSource
+	// RPCUser and RPCPassword authenticate json-rpc requests to the node.
+	RPCUser     string
+	RPCPassword string
+	// CacheBlocks is how many headers of the best chain, back from the tip, are kept
+	// locally, zero disables the cache and every header is read from the source.
+	CacheBlocks uint32
+	// CacheOlder is how many headers of blocks deeper than CacheBlocks are kept once read from
+	// the source, the least recently used are dropped first.
+	CacheOlder int
+	// SyncInterval is how often the cached headers are synced with the source.
+	SyncInterval time.Duration
 }
 
 // Wallet contains information relating to a payd installation.
@@ -164,6 +241,61 @@ type Wallet struct {
 	PaymentExpiryHours  int64
 	PayoutLimitEnabled  bool
 	PayoutLimitSatoshis uint64
+	// ExpiryInterval is how often invoices are checked and moved to expired
+	// once past their expiry date, zero disables the check.
+	ExpiryInterval time.Duration
+	// CoinSelection is the default strategy used to choose the utxos funding payments.
+	CoinSelection string
+	// ReservationTTL is how long utxos reserved for a payment are held before they
+	// are released to be spent again, zero means reservations don't expire.
+	ReservationTTL time.Duration
+	// ReservationInterval is how often expired reservations are released, zero disables this.
+	ReservationInterval time.Duration
+	// ConsolidateInterval is how often small utxos are consolidated, zero disables this.
+	ConsolidateInterval time.Duration
+	// ConsolidateSatoshis is the value at or below which utxos are consolidated.
+	ConsolidateSatoshis uint64
+	// ConsolidateMinUTXOs is the fewest small utxos worth consolidating.
+	ConsolidateMinUTXOs int
+	// ConsolidateMaxUTXOs is the most utxos consolidated by a single tx.
+	ConsolidateMaxUTXOs int
+	// FanOutInterval is how often large utxos are split, zero disables this.
+	FanOutInterval time.Duration
+	// FanOutSatoshis is the value at or above which utxos are split.
+	FanOutSatoshis uint64
+	// FanOutOutputs is the number of utxos each large utxo is split into.
+	FanOutOutputs int
+	// OutputSplit is the default strategy used to split invoice satoshis across outputs.
+	OutputSplit string
+	// OutputDenomination is the value of each output when splitting by denomination.
+	OutputDenomination uint64
+	// OutputMax is the most outputs invoice satoshis are split into.
+	OutputMax int
+	// Passphrase, if set, encrypts the private keys at rest and unlocks them on startup.
+	Passphrase string
+	// UnlockTimeout is how long the wallet stays unlocked after being unlocked on startup,
+	// zero keeps it unlocked until it is locked.
+	UnlockTimeout time.Duration
+	// MnemonicPassphrase is the optional BIP39 passphrase combined with the mnemonic
+	// when creating master keys, it is needed along with the mnemonic to restore them.
+	MnemonicPassphrase string
+	// MinConfirmations are the confirmations a tx needs before its txos can be spent,
+	// zero means txos can be spent as soon as their tx is broadcast.
+	MinConfirmations uint32
+	// ConfirmInterval is how often mined txs are checked for the confirmations
+	// they need to be confirmed, zero disables this.
+	ConfirmInterval time.Duration
+	// ReorgInterval is how often the chain tip is checked for reorgs that orphan the
+	// blocks of stored merkle proofs, zero disables this.
+	ReorgInterval time.Duration
+	// ReorgDepth is how many blocks below the chain tip are checked for orphaned proofs.
+	ReorgDepth uint32
+	// PollInterval is how often mapi is queried for the status of broadcast txs
+	// without a merkle proof, zero disables this.
+	PollInterval time.Duration
+	// PollGrace is how long after a tx is created before its status is queried,
+	// giving mapi time to send its proof to the callback.
+	PollGrace time.Duration
 }
 
 // PeerChannels information relating to peer channel interactions.
@@ -204,6 +336,34 @@ type Transports struct {
 	SocketsEnabled bool
 }
 
+// Webhooks contains settings for delivering events to merchant webhooks.
+type Webhooks struct {
+	// Interval is how often due deliveries are sent, zero disables delivery.
+	Interval time.Duration
+	// Timeout is how long to wait for a webhook endpoint to respond.
+	Timeout time.Duration
+	// MaxAttempts is the number of times a delivery is tried before it is dead lettered.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, it doubles with each failed attempt.
+	Backoff time.Duration
+	// BackoffMax is the longest wait between retries.
+	BackoffMax time.Duration
+}
+
+// ProofCallbacks contains settings for sending merkle proofs to payer proof callbacks.
+type ProofCallbacks struct {
+	// Interval is how often due proofs are sent, zero disables sending.
+	Interval time.Duration
+	// Timeout is how long to wait for a callback to respond.
+	Timeout time.Duration
+	// MaxAttempts is the number of times a proof is sent before the callback is failed.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, it doubles with each failed attempt.
+	Backoff time.Duration
+	// BackoffMax is the longest wait between retries.
+	BackoffMax time.Duration
+}
+
 // ConfigurationLoader will load configuration items
 // into a struct that contains a configuration.
 type ConfigurationLoader interface {
@@ -218,5 +378,7 @@ type ConfigurationLoader interface {
 	WithTransports() ConfigurationLoader
 	WithMapi() ConfigurationLoader
 	WithPeerChannels() ConfigurationLoader
+	WithWebhooks() ConfigurationLoader
+	WithProofCallbacks() ConfigurationLoader
 	Load() *Config
 }
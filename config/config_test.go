@@ -34,6 +34,20 @@ func Test_ConfigValidateDB(t *testing.T) {
 				},
 			},
 			err: nil,
+		}, "valid db config (badger) should return no errors": {
+			cfg: &Config{
+				Db: &Db{
+					Type: "badger",
+				},
+			},
+			err: nil,
+		}, "valid db config (memory) should return no errors": {
+			cfg: &Config{
+				Db: &Db{
+					Type: "memory",
+				},
+			},
+			err: nil,
 		}, "invalid db config should return no errors": {
 			cfg: &Config{
 				Db: &Db{
@@ -118,3 +132,108 @@ func Test_ConfigValidateNetwork(t *testing.T) {
 		})
 	}
 }
+
+func Test_ConfigValidateCoinSelection(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		cfg *Config
+		err error
+	}{
+		"valid coin selection should return no errors": {
+			cfg: &Config{
+				Wallet: &Wallet{
+					Network:       NetworkRegtest,
+					CoinSelection: "privacy",
+				},
+			},
+			err: nil,
+		}, "empty coin selection should return no errors": {
+			cfg: &Config{
+				Wallet: &Wallet{
+					Network: NetworkRegtest,
+				},
+			},
+			err: nil,
+		}, "invalid coin selection should error": {
+			cfg: &Config{
+				Wallet: &Wallet{
+					Network:       NetworkRegtest,
+					CoinSelection: "random",
+				},
+			},
+			err: errors.New("[wallet.coinselection: value random failed to meet requirements]"),
+		}, "valid output split should return no errors": {
+			cfg: &Config{
+				Wallet: &Wallet{
+					Network:     NetworkRegtest,
+					OutputSplit: "random",
+				},
+			},
+			err: nil,
+		}, "invalid output split should error": {
+			cfg: &Config{
+				Wallet: &Wallet{
+					Network:     NetworkRegtest,
+					OutputSplit: "largest",
+				},
+			},
+			err: errors.New("[wallet.outputs.split: value largest failed to meet requirements]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.cfg.Validate()
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.err.Error())
+		})
+	}
+}
+
+func Test_ConfigValidateHeadersSource(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		cfg *Config
+		err error
+	}{
+		"headersv source should return no errors": {
+			cfg: &Config{
+				HeadersClient: &HeadersClient{
+					Source: HeadersSourceHeaderSV,
+				},
+			},
+			err: nil,
+		}, "rpc source should return no errors": {
+			cfg: &Config{
+				HeadersClient: &HeadersClient{
+					Source: HeadersSourceRPC,
+				},
+			},
+			err: nil,
+		}, "empty source should error": {
+			cfg: &Config{
+				HeadersClient: &HeadersClient{},
+			},
+			err: errors.New("[headersclient.source: value  failed to meet requirements]"),
+		}, "invalid source should error": {
+			cfg: &Config{
+				HeadersClient: &HeadersClient{
+					Source: "electrum",
+				},
+			},
+			err: errors.New("[headersclient.source: value electrum failed to meet requirements]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.cfg.Validate()
+			if test.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.err.Error())
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package databases
+
+import (
+	"fmt"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd/config"
+	paydBadger "github.com/libsv/payd/data/badger"
+	"github.com/libsv/payd/log"
+)
+
+// SetupBadgerDB will open the badger db found in the directory set as the dsn, creating
+// it if it doesn't exist and migrating it if required.
+func SetupBadgerDB(l log.Logger, c *config.Db) (*badgerdb.DB, error) {
+	db, err := badgerdb.Open(badgerdb.DefaultOptions(c.Dsn).WithLogger(badgerLogger{l: l}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup database")
+	}
+	if !c.MigrateDb {
+		l.Info("migrate database set to false, skipping migration")
+		return db, nil
+	}
+	l.Info("migrating database")
+	if err := paydBadger.Migrate(db); err != nil {
+		l.Fatal(err, "failed to exec migrations")
+	}
+	l.Info("migrating database completed")
+	return db, nil
+}
+
+// badgerLogger writes badger logs to our logger, badger info logs
+// are noisy so are written at debug level.
+type badgerLogger struct {
+	l log.Logger
+}
+
+func (b badgerLogger) Errorf(format string, args ...interface{}) {
+	b.l.Error(fmt.Errorf(format, args...), "badger error")
+}
+
+func (b badgerLogger) Warningf(format string, args ...interface{}) {
+	b.l.Warnf(format, args...)
+}
+
+func (b badgerLogger) Infof(format string, args ...interface{}) {
+	b.l.Debugf(format, args...)
+}
+
+func (b badgerLogger) Debugf(format string, args ...interface{}) {
+	b.l.Debugf(format, args...)
+}
@@ -17,8 +17,8 @@ type dbSetups map[config.DbType]dbSetupFunc
 func NewDbSetup() dbSetups {
 	s := make(map[config.DbType]dbSetupFunc, 3)
 	s[config.DBSqlite] = setupSqliteDB
-	//s[config.DBMySQL] = setupMySqlDB
-	//s[config.DBPostgres] = setupPostgresDB
+	s[config.DBMySQL] = setupMySqlDB
+	s[config.DBPostgres] = setupPostgresDB
 	return s
 }
 
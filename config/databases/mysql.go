@@ -0,0 +1,58 @@
+package databases
+
+import (
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
+	migrateMysql "github.com/golang-migrate/migrate/v4/database/mysql"
+
+	// this is needed for loading file based migrations.
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+)
+
+func setupMySqlDB(l log.Logger, c *config.Db) (*sqlx.DB, error) {
+	dsn, err := mysql.ParseDSN(c.Dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse mysql dsn")
+	}
+	// timestamps are scanned into time.Time and migration files contain multiple statements,
+	// force these on rather than relying on the dsn.
+	dsn.ParseTime = true
+	dsn.MultiStatements = true
+	db, err := sqlx.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup database")
+	}
+	if err := db.Ping(); err != nil {
+		return nil, errors.Wrap(err, "failed to connect to mysql database")
+	}
+	if !c.MigrateDb {
+		l.Info("migrate database set to false, skipping migration")
+		return db, nil
+	}
+	l.Info("migrating database")
+	driver, err := migrateMysql.WithInstance(db.DB, &migrateMysql.Config{})
+	if err != nil {
+		l.Fatalf(err, "creating mysql db driver failed")
+	}
+	m, err := migrate.NewWithDatabaseInstance(
+		fmt.Sprintf("file://%s", c.SchemaPath), "mysql",
+		driver)
+	if err != nil {
+		l.Fatal(err, "failed to migrate file instance")
+	}
+
+	if err := m.Up(); err != nil {
+		if !errors.Is(err, migrate.ErrNoChange) {
+			l.Fatal(err, "failed to exec migrations")
+		}
+	}
+	l.Info("migrating database completed")
+	return db, nil
+}
@@ -0,0 +1,52 @@
+package databases
+
+import (
+	"fmt"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+
+	// this is needed for loading file based migrations.
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jmoiron/sqlx"
+
+	// used to import the postgres drivers.
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+)
+
+func setupPostgresDB(l log.Logger, c *config.Db) (*sqlx.DB, error) {
+	db, err := sqlx.Open("postgres", c.Dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup database")
+	}
+	if err := db.Ping(); err != nil {
+		return nil, errors.Wrap(err, "failed to connect to postgres database")
+	}
+	if !c.MigrateDb {
+		l.Info("migrate database set to false, skipping migration")
+		return db, nil
+	}
+	l.Info("migrating database")
+	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	if err != nil {
+		l.Fatalf(err, "creating postgres db driver failed")
+	}
+	m, err := migrate.NewWithDatabaseInstance(
+		fmt.Sprintf("file://%s", c.SchemaPath), "postgres",
+		driver)
+	if err != nil {
+		l.Fatal(err, "failed to migrate file instance")
+	}
+
+	if err := m.Up(); err != nil {
+		if !errors.Is(err, migrate.ErrNoChange) {
+			l.Fatal(err, "failed to exec migrations")
+		}
+	}
+	l.Info("migrating database completed")
+	return db, nil
+}
@@ -34,6 +34,10 @@ func SetupDefaults() {
 	// headers client
 	viper.SetDefault(EnvHeadersClientAddress, "http://headersv:8080")
 	viper.SetDefault(EnvHeadersClientTimeout, 30)
+	viper.SetDefault(EnvHeadersClientSource, "headersv")
+	viper.SetDefault(EnvHeadersClientCacheBlocks, 100)
+	viper.SetDefault(EnvHeadersClientCacheOlder, 1000)
+	viper.SetDefault(EnvHeadersClientSyncInterval, 30)
 
 	// dpp
 	viper.SetDefault(EnvDPPTimeout, 30)
@@ -45,6 +49,27 @@ func SetupDefaults() {
 	viper.SetDefault(EnvPaymentExpiry, 24)
 	viper.SetDefault(EnvWalletPayoutLimitEnabled, false)
 	viper.SetDefault(EnvWalletPayoutLimitSats, 0)
+	viper.SetDefault(EnvWalletExpiryInterval, 60)
+	viper.SetDefault(EnvWalletCoinSelection, "bnb")
+	viper.SetDefault(EnvWalletReservationTTL, 300)
+	viper.SetDefault(EnvWalletReservationInterval, 60)
+	viper.SetDefault(EnvWalletConsolidateInterval, 0)
+	viper.SetDefault(EnvWalletConsolidateSatoshis, 1000)
+	viper.SetDefault(EnvWalletConsolidateMinUTXOs, 10)
+	viper.SetDefault(EnvWalletConsolidateMaxUTXOs, 100)
+	viper.SetDefault(EnvWalletFanOutInterval, 0)
+	viper.SetDefault(EnvWalletFanOutSatoshis, 1000000)
+	viper.SetDefault(EnvWalletFanOutOutputs, 10)
+	viper.SetDefault(EnvWalletOutputSplit, "none")
+	viper.SetDefault(EnvWalletOutputDenomination, 10000)
+	viper.SetDefault(EnvWalletOutputMax, 10)
+	viper.SetDefault(EnvWalletUnlockTimeout, 0)
+	viper.SetDefault(EnvWalletConfirmationsMin, 0)
+	viper.SetDefault(EnvWalletConfirmInterval, 60)
+	viper.SetDefault(EnvWalletReorgInterval, 60)
+	viper.SetDefault(EnvWalletReorgDepth, 100)
+	viper.SetDefault(EnvWalletPollInterval, 300)
+	viper.SetDefault(EnvWalletPollGrace, 3600)
 
 	// mapi
 	viper.SetDefault(EnvMAPIMinerName, "local-mapi")
@@ -61,4 +86,18 @@ func SetupDefaults() {
 	// Peer channels
 	viper.SetDefault(EnvPeerChannelsTTL, 120)
 	viper.SetDefault(EnvPeerChannelsPath, "")
+
+	// Webhooks
+	viper.SetDefault(EnvWebhooksInterval, 5)
+	viper.SetDefault(EnvWebhooksTimeout, 10)
+	viper.SetDefault(EnvWebhooksMaxAttempts, 10)
+	viper.SetDefault(EnvWebhooksBackoff, 30)
+	viper.SetDefault(EnvWebhooksBackoffMax, 3600)
+
+	// Proof callbacks
+	viper.SetDefault(EnvProofCallbacksInterval, 10)
+	viper.SetDefault(EnvProofCallbacksTimeout, 10)
+	viper.SetDefault(EnvProofCallbacksMaxAttempts, 10)
+	viper.SetDefault(EnvProofCallbacksBackoff, 60)
+	viper.SetDefault(EnvProofCallbacksBackoffMax, 3600)
 }
@@ -66,8 +66,14 @@ func (v *ViperConfig) WithDb() ConfigurationLoader {
 // WithHeadersClient sets up and returns headers client configuration.
 func (v *ViperConfig) WithHeadersClient() ConfigurationLoader {
 	v.HeadersClient = &HeadersClient{
-		Address: viper.GetString(EnvHeadersClientAddress),
-		Timeout: viper.GetInt(EnvHeadersClientTimeout),
+		Address:      viper.GetString(EnvHeadersClientAddress),
+		Timeout:      viper.GetInt(EnvHeadersClientTimeout),
+		Source:       HeadersSource(viper.GetString(EnvHeadersClientSource)),
+		RPCUser:      viper.GetString(EnvHeadersClientRPCUser),
+		RPCPassword:  viper.GetString(EnvHeadersClientRPCPassword),
+		CacheBlocks:  viper.GetUint32(EnvHeadersClientCacheBlocks),
+		CacheOlder:   viper.GetInt(EnvHeadersClientCacheOlder),
+		SyncInterval: time.Duration(viper.GetInt64(EnvHeadersClientSyncInterval)) * time.Second,
 	}
 	return v
 }
@@ -80,6 +86,29 @@ func (v *ViperConfig) WithWallet() ConfigurationLoader {
 		PaymentExpiryHours:  viper.GetInt64(EnvPaymentExpiry),
 		PayoutLimitEnabled:  viper.GetBool(EnvWalletPayoutLimitEnabled),
 		PayoutLimitSatoshis: viper.GetUint64(EnvWalletPayoutLimitSats),
+		ExpiryInterval:      time.Duration(viper.GetInt64(EnvWalletExpiryInterval)) * time.Second,
+		CoinSelection:       viper.GetString(EnvWalletCoinSelection),
+		ReservationTTL:      time.Duration(viper.GetInt64(EnvWalletReservationTTL)) * time.Second,
+		ReservationInterval: time.Duration(viper.GetInt64(EnvWalletReservationInterval)) * time.Second,
+		ConsolidateInterval: time.Duration(viper.GetInt64(EnvWalletConsolidateInterval)) * time.Second,
+		ConsolidateSatoshis: viper.GetUint64(EnvWalletConsolidateSatoshis),
+		ConsolidateMinUTXOs: viper.GetInt(EnvWalletConsolidateMinUTXOs),
+		ConsolidateMaxUTXOs: viper.GetInt(EnvWalletConsolidateMaxUTXOs),
+		FanOutInterval:      time.Duration(viper.GetInt64(EnvWalletFanOutInterval)) * time.Second,
+		FanOutSatoshis:      viper.GetUint64(EnvWalletFanOutSatoshis),
+		FanOutOutputs:       viper.GetInt(EnvWalletFanOutOutputs),
+		OutputSplit:         viper.GetString(EnvWalletOutputSplit),
+		OutputDenomination:  viper.GetUint64(EnvWalletOutputDenomination),
+		OutputMax:           viper.GetInt(EnvWalletOutputMax),
+		Passphrase:          viper.GetString(EnvWalletPassphrase),
+		UnlockTimeout:       time.Duration(viper.GetInt64(EnvWalletUnlockTimeout)) * time.Second,
+		MnemonicPassphrase:  viper.GetString(EnvWalletMnemonicPassphrase),
+		MinConfirmations:    viper.GetUint32(EnvWalletConfirmationsMin),
+		ConfirmInterval:     time.Duration(viper.GetInt64(EnvWalletConfirmInterval)) * time.Second,
+		ReorgInterval:       time.Duration(viper.GetInt64(EnvWalletReorgInterval)) * time.Second,
+		ReorgDepth:          viper.GetUint32(EnvWalletReorgDepth),
+		PollInterval:        time.Duration(viper.GetInt64(EnvWalletPollInterval)) * time.Second,
+		PollGrace:           time.Duration(viper.GetInt64(EnvWalletPollGrace)) * time.Second,
 	}
 	return v
 }
@@ -130,6 +159,30 @@ func (v *ViperConfig) WithPeerChannels() ConfigurationLoader {
 	return v
 }
 
+// WithWebhooks reads webhook delivery config.
+func (v *ViperConfig) WithWebhooks() ConfigurationLoader {
+	v.Webhooks = &Webhooks{
+		Interval:    time.Duration(viper.GetInt64(EnvWebhooksInterval)) * time.Second,
+		Timeout:     time.Duration(viper.GetInt64(EnvWebhooksTimeout)) * time.Second,
+		MaxAttempts: viper.GetInt(EnvWebhooksMaxAttempts),
+		Backoff:     time.Duration(viper.GetInt64(EnvWebhooksBackoff)) * time.Second,
+		BackoffMax:  time.Duration(viper.GetInt64(EnvWebhooksBackoffMax)) * time.Second,
+	}
+	return v
+}
+
+// WithProofCallbacks reads proof callback config.
+func (v *ViperConfig) WithProofCallbacks() ConfigurationLoader {
+	v.ProofCallbacks = &ProofCallbacks{
+		Interval:    time.Duration(viper.GetInt64(EnvProofCallbacksInterval)) * time.Second,
+		Timeout:     time.Duration(viper.GetInt64(EnvProofCallbacksTimeout)) * time.Second,
+		MaxAttempts: viper.GetInt(EnvProofCallbacksMaxAttempts),
+		Backoff:     time.Duration(viper.GetInt64(EnvProofCallbacksBackoff)) * time.Second,
+		BackoffMax:  time.Duration(viper.GetInt64(EnvProofCallbacksBackoffMax)) * time.Second,
+	}
+	return v
+}
+
 // Load will return the underlying config setup.
 func (v *ViperConfig) Load() *Config {
 	return v.Config
@@ -0,0 +1,280 @@
+package badger
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+)
+
+// keySep separates the parts of a key, the final part of an index key
+// is the id of the record it points to.
+const keySep = "/"
+
+// Key prefixes for records and their secondary indexes.
+const (
+	prefixSeq                = "seq"
+	prefixMeta               = "meta"
+	prefixUser               = "user"
+	prefixKey                = "key"
+	prefixPeerChannelAccount = "peerchannelaccount"
+	prefixInvoice            = "invoice"
+	prefixPayment            = "payment"
+	prefixRefund             = "refund"
+	prefixFeeQuote           = "feequote"
+	prefixDestination        = "destination"
+	prefixTx                 = "tx"
+	prefixTxo                = "txo"
+	prefixProof              = "proof"
+	prefixProofStale         = "proofstale"
+	prefixProofCallback      = "proofcallback"
+	prefixPeerChannel        = "peerchannel"
+	prefixPeerChannelTok     = "peerchanneltok"
+	prefixWebhook            = "webhook"
+	prefixWebhookDelivery    = "webhookdelivery"
+
+	idxInvoiceState       = "idx/invoice/state"
+	idxInvoiceCreated     = "idx/invoice/created"
+	idxInvoiceDestination = "idx/invoice/destination"
+	idxInvoiceTx          = "idx/invoice/tx"
+	idxDestinationScript  = "idx/destination/script"
+	idxDestinationPath    = "idx/destination/path"
+	idxTxoStatus          = "idx/txo/status"
+	idxTxoReservation     = "idx/txo/reservation"
+	idxPeerChannelOpen    = "idx/peerchannel/open"
+	idxWebhookDeliveryDue = "idx/webhookdelivery/due"
+	idxProofCallbackDue   = "idx/proofcallback/due"
+)
+
+// txo statuses used in the txo status index.
+const (
+	txoUnspent  = "unspent"
+	txoReserved = "reserved"
+	txoSpent    = "spent"
+)
+
+type badgerStore struct {
+	db *badgerdb.DB
+}
+
+// NewBadgerStore will setup and return a badger backed data store.
+func NewBadgerStore(db *badgerdb.DB) *badgerStore {
+	return &badgerStore{db: db}
+}
+
+func (s *badgerStore) newTx(ctx context.Context) *badgerdb.Txn {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil {
+		if ctxx.Txn == nil {
+			ctxx.Txn = s.db.NewTransaction(true)
+		}
+		return ctxx.Txn
+	}
+	return s.db.NewTransaction(true)
+}
+
+// view runs fn in the context based tx if one has been started, otherwise
+// a new read only tx is used.
+func (s *badgerStore) view(ctx context.Context, fn func(txn *badgerdb.Txn) error) error {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil && ctxx.Txn != nil {
+		return fn(ctxx.Txn)
+	}
+	return s.db.View(fn)
+}
+
+// commit a transaction, if there is a context based tx
+// this will not commit - we wait on the context to close it.
+func commit(ctx context.Context, txn *badgerdb.Txn) error {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil {
+		if ctxx.Txn != nil {
+			return nil
+		}
+	}
+	return txn.Commit()
+}
+
+// rollback a transaction, if there is a context based tx
+// this will not rollback - we wait on the context to close it.
+func rollback(ctx context.Context, txn *badgerdb.Txn) {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil {
+		if ctxx.Txn != nil {
+			return
+		}
+	}
+	txn.Discard()
+}
+
+// key joins parts into a single key.
+func key(parts ...string) []byte {
+	return []byte(strings.Join(parts, keySep))
+}
+
+// prefix returns a key prefix matching all keys nested under parts.
+func prefix(parts ...string) []byte {
+	return []byte(strings.Join(parts, keySep) + keySep)
+}
+
+// fmtID formats a numeric id so keys sort in numeric order.
+func fmtID(i uint64) string {
+	return fmt.Sprintf("%020d", i)
+}
+
+// get will read the value at k into v, badgerdb.ErrKeyNotFound is returned
+// if the key doesn't exist.
+func get(txn *badgerdb.Txn, k []byte, v interface{}) error {
+	item, err := txn.Get(k)
+	if err != nil {
+		return err
+	}
+	return item.Value(func(val []byte) error {
+		return errors.Wrapf(json.Unmarshal(val, v), "failed to decode value for key %s", k)
+	})
+}
+
+// set will store v at k.
+func set(txn *badgerdb.Txn, k []byte, v interface{}) error {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode value for key %s", k)
+	}
+	return txn.Set(k, bb)
+}
+
+// exists returns true if k is in the store.
+func exists(txn *badgerdb.Txn, k []byte) (bool, error) {
+	if _, err := txn.Get(k); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// index stores an empty index entry at k.
+func index(txn *badgerdb.Txn, k []byte) error {
+	return txn.Set(k, []byte{})
+}
+
+// ids returns the final key part of every key under p, for an index
+// this is the id of the record being pointed to.
+func ids(txn *badgerdb.Txn, p []byte) []string {
+	opts := badgerdb.DefaultIteratorOptions
+	opts.PrefetchValues = false
+	opts.Prefix = p
+	it := txn.NewIterator(opts)
+	defer it.Close()
+	var ii []string
+	for it.Seek(p); it.ValidForPrefix(p); it.Next() {
+		ii = append(ii, strings.TrimPrefix(string(it.Item().Key()), string(p)))
+	}
+	return ii
+}
+
+// each decodes every value under p, calling fn with the decoded value.
+// newV must return a pointer for the value to be decoded into.
+func each(txn *badgerdb.Txn, p []byte, newV func() interface{}, fn func(v interface{}) error) error {
+	it := txn.NewIterator(badgerdb.IteratorOptions{Prefix: p, PrefetchValues: true, PrefetchSize: 100})
+	defer it.Close()
+	for it.Seek(p); it.ValidForPrefix(p); it.Next() {
+		v := newV()
+		if err := it.Item().Value(func(val []byte) error {
+			return json.Unmarshal(val, v)
+		}); err != nil {
+			return errors.Wrapf(err, "failed to decode value for key %s", it.Item().Key())
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextID increments and returns the named sequence.
+func nextID(txn *badgerdb.Txn, name string) (uint64, error) {
+	k := key(prefixSeq, name)
+	var seq uint64
+	item, err := txn.Get(k)
+	switch {
+	case err == nil:
+		if err := item.Value(func(val []byte) error {
+			seq = binary.BigEndian.Uint64(val)
+			return nil
+		}); err != nil {
+			return 0, errors.Wrapf(err, "failed to read sequence %s", name)
+		}
+	case !errors.Is(err, badgerdb.ErrKeyNotFound):
+		return 0, errors.Wrapf(err, "failed to read sequence %s", name)
+	}
+	seq++
+	return seq, setSeq(txn, name, seq)
+}
+
+// setSeq sets the named sequence to seq.
+func setSeq(txn *badgerdb.Txn, name string, seq uint64) error {
+	bb := make([]byte, 8)
+	binary.BigEndian.PutUint64(bb, seq)
+	return errors.Wrapf(txn.Set(key(prefixSeq, name), bb), "failed to store sequence %s", name)
+}
+
+type execKey int
+
+// nolint:gochecknoglobals // this variable is fine as it's used for context & is private
+var exec execKey
+
+// Tx wraps the transaction used in context.
+type Tx struct {
+	*badgerdb.Txn
+}
+
+// WithTxContext will add a new empty transaction to the provided context.
+func WithTxContext(ctx context.Context) context.Context {
+	tx := TxFromContext(ctx)
+	if tx != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, exec, &Tx{})
+}
+
+// TxFromContext will return a context based transaction if found.
+func TxFromContext(ctx context.Context) *Tx {
+	if tx, ok := ctx.Value(exec).(*Tx); ok {
+		return tx
+	}
+	return nil
+}
+
+// Transacter is used to implement the DBTransacter interface for
+// managing db transactions in other layers.
+type Transacter struct {
+}
+
+// WithTx will add a TX to the provided context.
+func (t *Transacter) WithTx(ctx context.Context) context.Context {
+	return WithTxContext(ctx)
+}
+
+// Commit will atomically write all changes made in the context transaction.
+func (t *Transacter) Commit(ctx context.Context) error {
+	tx := TxFromContext(ctx)
+	if tx != nil && tx.Txn != nil {
+		return tx.Txn.Commit()
+	}
+	return nil
+}
+
+// Rollback will discard the tx and any changes made within it.
+func (t *Transacter) Rollback(ctx context.Context) error {
+	tx := TxFromContext(ctx)
+	if tx != nil && tx.Txn != nil {
+		tx.Txn.Discard()
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package badger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/config/databases"
+	"github.com/libsv/payd/data"
+	paydBadger "github.com/libsv/payd/data/badger"
+	"github.com/libsv/payd/data/storetest"
+	"github.com/libsv/payd/log"
+)
+
+func TestBadgerStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (data.Store, payd.Transacter) {
+		db, err := databases.SetupBadgerDB(log.Noop{}, &config.Db{
+			Type:      config.DBBadger,
+			Dsn:       t.TempDir(),
+			MigrateDb: true,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return paydBadger.NewBadgerStore(db), &paydBadger.Transacter{}
+	})
+}
@@ -0,0 +1,49 @@
+package badger
+
+import (
+	"context"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos"
+
+	"github.com/libsv/payd"
+)
+
+// Balance will return the current account balance, totalling the unspent txos by the
+// state of their tx and whether they are reserved or frozen along with the heights of
+// the blocks their txs were mined in.
+func (s *badgerStore) Balance(ctx context.Context, args payd.BalanceArgs) (*payd.Balance, error) {
+	var resp payd.Balance
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, status := range []string{txoUnspent, txoReserved} {
+			for _, outpoint := range ids(txn, prefix(idxTxoStatus, status)) {
+				var t txo
+				if err := txnTxo(txn, outpoint, &t); err != nil {
+					return err
+				}
+				var d destination
+				if err := txnDestination(txn, t.DestinationID, &d); err != nil {
+					// as with the sql stores, txos not paid to one of our destinations aren't counted.
+					if lathos.IsNotFound(err) {
+						continue
+					}
+					return err
+				}
+				if !args.Matches(d.UserID, d.KeyName) {
+					continue
+				}
+				var tx transaction
+				if err := get(txn, key(prefixTx, t.TxID), &tx); err != nil {
+					return errors.Wrapf(err, "failed to get tx %s for txo", t.TxID)
+				}
+				resp.Add(payd.BalanceTotal{Satoshis: t.Satoshis, UTXOs: 1, LowBlockHeight: tx.BlockHeight, HighBlockHeight: tx.BlockHeight},
+					tx.State, status == txoReserved, t.Frozen)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get balance")
+	}
+	return &resp, nil
+}
@@ -0,0 +1,43 @@
+package badger
+
+import (
+	"context"
+	"sort"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+// DerivationPathExists will return true / false if the supplied derivation path exists or not.
+func (s *badgerStore) DerivationPathExists(ctx context.Context, args payd.DerivationExistsArgs) (bool, error) {
+	var ok bool
+	if err := s.view(ctx, func(txn *badgerdb.Txn) (err error) {
+		ok, err = exists(txn, key(idxDestinationPath, fmtID(args.UserID), args.KeyName, args.Path))
+		return err
+	}); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return ok, nil
+}
+
+// DerivationPaths returns every path used to derive a destination from the key.
+func (s *badgerStore) DerivationPaths(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+	resp := []payd.DerivationPath{}
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixDestination), func() interface{} { return &destination{} }, func(v interface{}) error {
+			d := v.(*destination)
+			if d.UserID == args.UserID && d.KeyName == args.Name {
+				resp = append(resp, payd.DerivationPath{Path: d.DerivationPath, LockingScript: d.LockingScript})
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get derivation paths of key %s", args.Name)
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Path < resp[j].Path
+	})
+	return resp, nil
+}
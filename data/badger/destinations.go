@@ -0,0 +1,151 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// destination is the stored representation of a payment destination.
+type destination struct {
+	ID             uint64    `json:"id"`
+	UserID         uint64    `json:"userId"`
+	KeyName        string    `json:"keyName"`
+	LockingScript  string    `json:"lockingScript"`
+	DerivationPath string    `json:"derivationPath"`
+	Satoshis       uint64    `json:"satoshis"`
+	State          string    `json:"state"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (d destination) toOutput() (payd.Output, error) {
+	s, err := bscript.NewFromHexString(d.LockingScript)
+	if err != nil {
+		return payd.Output{}, errors.Wrapf(err, "failed to parse locking script for destination %d", d.ID)
+	}
+	return payd.Output{
+		ID:             d.ID,
+		LockingScript:  s,
+		Satoshis:       d.Satoshis,
+		DerivationPath: d.DerivationPath,
+		State:          d.State,
+	}, nil
+}
+
+// DestinationsCreate will store the destinations, linking them to the invoice if provided.
+func (s *badgerStore) DestinationsCreate(ctx context.Context, args payd.DestinationsCreateArgs, req []payd.DestinationCreate) ([]payd.Output, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	invoiceID := args.InvoiceID.ValueOrZero()
+	if !args.InvoiceID.IsZero() {
+		var inv invoice
+		if err := txnInvoice(txn, invoiceID, &inv); err != nil {
+			return nil, errors.WithMessagef(err, "failed to add destinations for invoiceID '%s'", invoiceID)
+		}
+	}
+	now := time.Now().UTC()
+	dd := make([]payd.Output, 0, len(req))
+	for _, r := range req {
+		ok, err := exists(txn, key(idxDestinationScript, r.Script))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check for destination with script %s", r.Script)
+		}
+		if ok {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("destination with script %s already exists", r.Script))
+		}
+		destID, err := nextID(txn, prefixDestination)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get next destination id")
+		}
+		d := destination{
+			ID:             destID,
+			UserID:         r.UserID,
+			KeyName:        r.KeyName,
+			LockingScript:  r.Script,
+			DerivationPath: r.DerivationPath,
+			Satoshis:       r.Satoshis,
+			State:          "pending",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := set(txn, key(prefixDestination, fmtID(destID)), d); err != nil {
+			return nil, errors.Wrapf(err, "failed to insert payment destinations for invoiceID '%s'", invoiceID)
+		}
+		for _, k := range [][]byte{
+			key(idxDestinationScript, r.Script),
+			key(idxDestinationPath, fmtID(r.UserID), r.KeyName, r.DerivationPath),
+		} {
+			if err := index(txn, k); err != nil {
+				return nil, errors.Wrap(err, "failed to add destination index")
+			}
+		}
+		if invoiceID != "" {
+			if err := index(txn, key(idxInvoiceDestination, invoiceID, fmtID(destID))); err != nil {
+				return nil, errors.Wrapf(err, "failed to link destination to invoiceID '%s'", invoiceID)
+			}
+		}
+		o, err := d.toOutput()
+		if err != nil {
+			return nil, err
+		}
+		dd = append(dd, o)
+	}
+	return dd, errors.Wrapf(commit(ctx, txn), "failed to commit transaction when creating payment destinations")
+}
+
+// Destinations will return a set of destination outputs for a specific invoiceID.
+func (s *badgerStore) Destinations(ctx context.Context, args payd.DestinationsArgs) ([]payd.Output, error) {
+	var outs []payd.Output
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		// destinations are only paid by the txs of their invoice.
+		received := map[uint64]uint64{}
+		for _, txID := range ids(txn, prefix(idxInvoiceTx, args.InvoiceID)) {
+			if err := each(txn, key(prefixTxo, txID), func() interface{} { return &txo{} }, func(v interface{}) error {
+				t := v.(*txo)
+				received[t.DestinationID] += t.Satoshis
+				return nil
+			}); err != nil {
+				return errors.Wrapf(err, "failed to get txos of tx %s", txID)
+			}
+		}
+		for _, destID := range ids(txn, prefix(idxInvoiceDestination, args.InvoiceID)) {
+			var d destination
+			if err := get(txn, key(prefixDestination, destID), &d); err != nil {
+				return errors.Wrapf(err, "failed to get destination %s", destID)
+			}
+			o, err := d.toOutput()
+			if err != nil {
+				return err
+			}
+			o.SatoshisReceived = received[d.ID]
+			outs = append(outs, o)
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get destinations with invoiceID %s", args.InvoiceID)
+	}
+	if len(outs) == 0 {
+		return nil, lathos.NewErrNotFound(errcodes.ErrDestinationsNotFound, fmt.Sprintf("destinations with invoiceID %s not found", args.InvoiceID))
+	}
+	return outs, nil
+}
+
+// txnDestination reads the destination with destID into d.
+func txnDestination(txn *badgerdb.Txn, destID uint64, d *destination) error {
+	if err := get(txn, key(prefixDestination, fmtID(destID)), d); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrDestinationsNotFound, fmt.Sprintf("destination %d not found", destID))
+		}
+		return errors.Wrapf(err, "failed to get destination %d", destID)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package badger
+
+import (
+	"context"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// feeQuote is the stored representation of the fees offered for an invoice.
+type feeQuote struct {
+	FeeQuote  *bt.FeeQuote `json:"feeQuote"`
+	ExpiresAt time.Time    `json:"expiresAt"`
+}
+
+// FeeQuoteCreate will store the fee quote for an invoice, replacing any existing quote.
+func (s *badgerStore) FeeQuoteCreate(ctx context.Context, args *payd.FeeQuoteCreateArgs) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	if err := set(txn, key(prefixFeeQuote, args.InvoiceID), feeQuote{
+		FeeQuote:  args.FeeQuote,
+		ExpiresAt: args.FeeQuote.Expiry(),
+	}); err != nil {
+		return errors.Wrap(err, "failed to insert fees into db")
+	}
+	return errors.Wrapf(commit(ctx, txn), "failed to commit transaction when inserting fee rate for invoice '%s'", args.InvoiceID)
+}
+
+// FeeQuote will return the fee quote stored for an invoice.
+func (s *badgerStore) FeeQuote(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+	var fq feeQuote
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return get(txn, key(prefixFeeQuote, invoiceID), &fq)
+	}); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return nil, lathos.NewErrNotFoundf("N0002", "cannot find fee quote for invoiceID %s", invoiceID)
+		}
+		return nil, errors.Wrapf(err, "failed to get fee quote for invoiceID %s", invoiceID)
+	}
+	fq.FeeQuote.UpdateExpiry(fq.ExpiresAt)
+	return fq.FeeQuote, nil
+}
@@ -0,0 +1,457 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// invoice is the stored representation of an invoice.
+type invoice struct {
+	ID                string            `json:"id"`
+	Reference         null.String       `json:"reference"`
+	Description       null.String       `json:"description"`
+	Satoshis          uint64            `json:"satoshis"`
+	SatoshisReceived  uint64            `json:"satoshisReceived"`
+	SatoshisRefunded  uint64            `json:"satoshisRefunded"`
+	ExpiresAt         null.Time         `json:"expiresAt"`
+	PaymentReceivedAt null.Time         `json:"paymentReceivedAt"`
+	RefundTo          null.String       `json:"refundTo"`
+	RefundedAt        null.Time         `json:"refundedAt"`
+	State             payd.InvoiceState `json:"state"`
+	SPVRequired       bool              `json:"spvRequired"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+	DeletedAt         null.Time         `json:"deletedAt"`
+}
+
+// payment is the stored representation of a payment made against an invoice.
+type payment struct {
+	InvoiceID string      `json:"invoiceId"`
+	TxID      string      `json:"txId"`
+	Satoshis  uint64      `json:"satoshis"`
+	RefundTo  null.String `json:"refundTo"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// refund is the stored representation of a refund paid against an invoice.
+type refund struct {
+	InvoiceID string    `json:"invoiceId"`
+	TxID      string    `json:"txId"`
+	Satoshis  uint64    `json:"satoshis"`
+	RefundTo  string    `json:"refundTo"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (i invoice) toInvoice() payd.Invoice {
+	return payd.Invoice{
+		ID:                i.ID,
+		Reference:         i.Reference,
+		Description:       i.Description,
+		Satoshis:          i.Satoshis,
+		SatoshisReceived:  i.SatoshisReceived,
+		SatoshisRefunded:  i.SatoshisRefunded,
+		ExpiresAt:         i.ExpiresAt,
+		PaymentReceivedAt: i.PaymentReceivedAt,
+		RefundTo:          i.RefundTo,
+		RefundedAt:        i.RefundedAt,
+		State:             i.State,
+		SPVRequired:       i.SPVRequired,
+		MetaData: payd.MetaData{
+			CreatedAt: i.CreatedAt,
+			UpdatedAt: i.UpdatedAt,
+			DeletedAt: i.DeletedAt,
+		},
+	}
+}
+
+// Invoice will return an invoice that matches the provided args.
+func (s *badgerStore) Invoice(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+	var resp payd.Invoice
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		var inv invoice
+		if err := txnInvoice(txn, args.InvoiceID, &inv); err != nil {
+			return err
+		}
+		resp = inv.toInvoice()
+		resp.BlockHeight = txnInvoiceBlockHeight(txn, args.InvoiceID)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Invoices will return the invoices matching args, deleted invoices are never returned.
+// Invoices are read in creation order using the created index and filtered as they are read.
+func (s *badgerStore) Invoices(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
+	var resp []payd.Invoice
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		entries := ids(txn, prefix(idxInvoiceCreated))
+		for n := range entries {
+			entry := entries[n]
+			if args.Descending() {
+				entry = entries[len(entries)-1-n]
+			}
+			invoiceID := entry[strings.LastIndex(entry, keySep)+1:]
+			var inv invoice
+			if err := get(txn, key(prefixInvoice, invoiceID), &inv); err != nil {
+				return errors.Wrapf(err, "failed to get invoice with invoiceID %s", invoiceID)
+			}
+			if inv.State == payd.StateInvoiceDeleted || !args.Matches(inv.toInvoice()) {
+				continue
+			}
+			i := inv.toInvoice()
+			i.BlockHeight = txnInvoiceBlockHeight(txn, invoiceID)
+			resp = append(resp, i)
+			if args.Limit > 0 && len(resp) == args.Limit {
+				return nil
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get invoices")
+	}
+	return resp, nil
+}
+
+// InvoicesPending will return any invoices that are pending or partially paid.
+func (s *badgerStore) InvoicesPending(ctx context.Context) ([]payd.Invoice, error) {
+	var resp []payd.Invoice
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, state := range []payd.InvoiceState{payd.StateInvoicePending, payd.StateInvoicePartiallyPaid} {
+			for _, invoiceID := range ids(txn, prefix(idxInvoiceState, string(state))) {
+				var inv invoice
+				if err := get(txn, key(prefixInvoice, invoiceID), &inv); err != nil {
+					return errors.Wrapf(err, "failed to get invoice with invoiceID %s", invoiceID)
+				}
+				resp = append(resp, inv.toInvoice())
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get invoices")
+	}
+	return resp, nil
+}
+
+// InvoicePayments will return the payments made against an invoice, oldest first.
+func (s *badgerStore) InvoicePayments(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoicePayment, error) {
+	var resp []payd.InvoicePayment
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixPayment, args.InvoiceID), func() interface{} { return &payment{} }, func(v interface{}) error {
+			p := v.(*payment)
+			resp = append(resp, payd.InvoicePayment{
+				InvoiceID: p.InvoiceID,
+				TxID:      p.TxID,
+				Satoshis:  p.Satoshis,
+				RefundTo:  p.RefundTo,
+				CreatedAt: p.CreatedAt,
+			})
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get payments for invoiceID %s", args.InvoiceID)
+	}
+	sort.SliceStable(resp, func(i, j int) bool {
+		return resp[i].CreatedAt.Before(resp[j].CreatedAt)
+	})
+	return resp, nil
+}
+
+// InvoiceRefunds will return the refunds paid against an invoice, oldest first.
+func (s *badgerStore) InvoiceRefunds(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoiceRefund, error) {
+	var resp []payd.InvoiceRefund
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixRefund, args.InvoiceID), func() interface{} { return &refund{} }, func(v interface{}) error {
+			r := v.(*refund)
+			resp = append(resp, payd.InvoiceRefund{
+				InvoiceID: r.InvoiceID,
+				TxID:      r.TxID,
+				Satoshis:  r.Satoshis,
+				RefundTo:  r.RefundTo,
+				CreatedAt: r.CreatedAt,
+			})
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get refunds for invoiceID %s", args.InvoiceID)
+	}
+	sort.SliceStable(resp, func(i, j int) bool {
+		return resp[i].CreatedAt.Before(resp[j].CreatedAt)
+	})
+	return resp, nil
+}
+
+// InvoiceCreate will persist a new Invoice in the data store.
+func (s *badgerStore) InvoiceCreate(ctx context.Context, req payd.InvoiceCreate) (*payd.Invoice, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	ok, err := exists(txn, key(prefixInvoice, req.InvoiceID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check for invoice with invoiceID %s", req.InvoiceID)
+	}
+	if ok {
+		return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("invoice with invoiceID %s already exists", req.InvoiceID))
+	}
+	inv := invoice{
+		ID:          req.InvoiceID,
+		Reference:   req.Reference,
+		Description: req.Description,
+		Satoshis:    req.Satoshis,
+		ExpiresAt:   req.ExpiresAt,
+		State:       payd.StateInvoicePending,
+		SPVRequired: req.SPVRequired,
+		CreatedAt:   req.CreatedAt,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	if err := txnInvoiceSave(txn, &inv, ""); err != nil {
+		return nil, errors.Wrapf(err, "failed to insert invoice with invoiceID %s", req.InvoiceID)
+	}
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating invoice with invoiceID %s", req.InvoiceID)
+	}
+	resp := inv.toInvoice()
+	return &resp, nil
+}
+
+// InvoiceUpdate will record a payment against an invoice, marking it paid once the
+// satoshis received cover it or partially paid if not, and return the result.
+// Only pending or partially paid invoices are updated, any other invoice is returned unchanged.
+func (s *badgerStore) InvoiceUpdate(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var inv invoice
+	if err := txnInvoice(txn, args.InvoiceID, &inv); err != nil {
+		return nil, errors.WithMessage(err, "failed to update invoice")
+	}
+	if inv.State == payd.StateInvoicePending || inv.State == payd.StateInvoicePartiallyPaid {
+		ok, err := exists(txn, key(prefixPayment, inv.ID, req.TxID))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check for payment with tx %s", req.TxID)
+		}
+		if ok {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("payment for tx %s has already been recorded against invoiceID %s", req.TxID, args.InvoiceID))
+		}
+		now := time.Now().UTC()
+		refundTo := null.NewString(req.RefundTo, req.RefundTo != "")
+		if err := set(txn, key(prefixPayment, inv.ID, req.TxID), payment{
+			InvoiceID: inv.ID,
+			TxID:      req.TxID,
+			Satoshis:  req.Satoshis,
+			RefundTo:  refundTo,
+			CreatedAt: now,
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to insert payment for invoiceID %s", args.InvoiceID)
+		}
+		prev := inv.State
+		inv.SatoshisReceived += req.Satoshis
+		inv.State = payd.StateInvoicePartiallyPaid
+		if inv.SatoshisReceived >= inv.Satoshis {
+			inv.State = payd.StateInvoicePaid
+			inv.PaymentReceivedAt = null.TimeFrom(now)
+		}
+		if refundTo.Valid {
+			inv.RefundTo = refundTo
+		}
+		inv.UpdatedAt = now
+		if err := txnInvoiceSave(txn, &inv, prev); err != nil {
+			return nil, errors.Wrapf(err, "failed to update invoice for invoiceID %s", args.InvoiceID)
+		}
+	}
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when updating invoice with invoiceID %s", args.InvoiceID)
+	}
+	resp := inv.toInvoice()
+	return &resp, nil
+}
+
+// InvoiceRefund will record a refund against an invoice, marking it refunded once the
+// refunds cover the satoshis received, and return the result.
+// Only paid, partially paid or expired invoices are updated, and only when the refunds don't exceed the
+// satoshis received, otherwise the invoice is returned unchanged.
+func (s *badgerStore) InvoiceRefund(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var inv invoice
+	if err := txnInvoice(txn, args.InvoiceID, &inv); err != nil {
+		return nil, errors.WithMessage(err, "failed to refund invoice")
+	}
+	refundable := inv.State == payd.StateInvoicePaid || inv.State == payd.StateInvoicePartiallyPaid || inv.State == payd.StateInvoiceExpired
+	if refundable && inv.SatoshisRefunded+req.Satoshis <= inv.SatoshisReceived {
+		ok, err := exists(txn, key(prefixRefund, inv.ID, req.TxID))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check for refund with tx %s", req.TxID)
+		}
+		if ok {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("refund for tx %s has already been recorded against invoiceID %s", req.TxID, args.InvoiceID))
+		}
+		if !req.RefundedAt.Valid {
+			req.RefundedAt = null.TimeFrom(time.Now().UTC())
+		}
+		if err := set(txn, key(prefixRefund, inv.ID, req.TxID), refund{
+			InvoiceID: inv.ID,
+			TxID:      req.TxID,
+			Satoshis:  req.Satoshis,
+			RefundTo:  req.RefundTo.ValueOrZero(),
+			CreatedAt: req.RefundedAt.Time,
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to insert refund for invoiceID %s", args.InvoiceID)
+		}
+		prev := inv.State
+		inv.SatoshisRefunded += req.Satoshis
+		if inv.SatoshisRefunded >= inv.SatoshisReceived {
+			inv.State = payd.StateInvoiceRefunded
+		}
+		inv.RefundedAt = req.RefundedAt
+		inv.UpdatedAt = req.RefundedAt.Time
+		if err := txnInvoiceSave(txn, &inv, prev); err != nil {
+			return nil, errors.Wrapf(err, "failed to refund invoice for invoiceID %s", args.InvoiceID)
+		}
+	}
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when refunding invoice with invoiceID %s", args.InvoiceID)
+	}
+	resp := inv.toInvoice()
+	return &resp, nil
+}
+
+// InvoicesExpire will move the overdue pending or partially paid invoices to the expired
+// state, deleting their destinations still waiting to be paid, and return them.
+func (s *badgerStore) InvoicesExpire(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	now := time.Now().UTC()
+	var resp []payd.Invoice
+	for _, state := range []payd.InvoiceState{payd.StateInvoicePending, payd.StateInvoicePartiallyPaid} {
+		for _, invoiceID := range ids(txn, prefix(idxInvoiceState, string(state))) {
+			var inv invoice
+			if err := get(txn, key(prefixInvoice, invoiceID), &inv); err != nil {
+				return nil, errors.Wrapf(err, "failed to get invoice with invoiceID %s", invoiceID)
+			}
+			if !inv.ExpiresAt.Valid || inv.ExpiresAt.Time.After(args.ExpiresBefore) {
+				continue
+			}
+			for _, destID := range ids(txn, prefix(idxInvoiceDestination, inv.ID)) {
+				var d destination
+				if err := get(txn, key(prefixDestination, destID), &d); err != nil {
+					return nil, errors.Wrapf(err, "failed to get destination %s", destID)
+				}
+				if d.State != "pending" {
+					continue
+				}
+				d.State = "deleted"
+				d.UpdatedAt = now
+				if err := set(txn, key(prefixDestination, destID), d); err != nil {
+					return nil, errors.Wrapf(err, "failed to delete destination %s", destID)
+				}
+			}
+			inv.State = payd.StateInvoiceExpired
+			inv.UpdatedAt = now
+			if err := txnInvoiceSave(txn, &inv, state); err != nil {
+				return nil, errors.Wrapf(err, "failed to expire invoice with invoiceID %s", inv.ID)
+			}
+			resp = append(resp, inv.toInvoice())
+		}
+	}
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction when expiring invoices")
+	}
+	sort.SliceStable(resp, func(i, j int) bool {
+		if resp[i].CreatedAt.Equal(resp[j].CreatedAt) {
+			return resp[i].ID < resp[j].ID
+		}
+		return resp[i].CreatedAt.Before(resp[j].CreatedAt)
+	})
+	return resp, nil
+}
+
+// InvoiceDelete will mark an invoice as deleted, it will then no longer be returned.
+func (s *badgerStore) InvoiceDelete(ctx context.Context, args payd.InvoiceArgs) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var inv invoice
+	if err := txnInvoice(txn, args.InvoiceID, &inv); err != nil {
+		return errors.WithMessagef(err, "failed to find invoice with id %s to delete", args.InvoiceID)
+	}
+	prev := inv.State
+	now := time.Now().UTC()
+	inv.DeletedAt = null.TimeFrom(now)
+	inv.UpdatedAt = now
+	inv.State = payd.StateInvoiceDeleted
+	if err := txnInvoiceSave(txn, &inv, prev); err != nil {
+		return errors.Wrapf(err, "failed to delete invoice for invoiceID %s", args.InvoiceID)
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when deleting invoice with invoiceID %s", args.InvoiceID)
+	}
+	return nil
+}
+
+// txnInvoice reads a non deleted invoice into inv, returning a not found error
+// if it doesn't exist.
+func txnInvoice(txn *badgerdb.Txn, invoiceID string, inv *invoice) error {
+	if err := get(txn, key(prefixInvoice, invoiceID), inv); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrInvoiceNotFound, fmt.Sprintf("invoice with invoiceID %s not found", invoiceID))
+		}
+		return errors.Wrapf(err, "failed to get invoice with invoiceID %s", invoiceID)
+	}
+	if inv.State == payd.StateInvoiceDeleted {
+		return lathos.NewErrNotFound(errcodes.ErrInvoiceNotFound, fmt.Sprintf("invoice with invoiceID %s not found", invoiceID))
+	}
+	return nil
+}
+
+// txnInvoiceBlockHeight returns the height of the highest block an invoice payment was mined in,
+// as with the sql stores it is only set once every payment has been mined.
+func txnInvoiceBlockHeight(txn *badgerdb.Txn, invoiceID string) null.Int {
+	var height null.Int
+	for _, txID := range ids(txn, prefix(prefixPayment, invoiceID)) {
+		var t transaction
+		if err := get(txn, key(prefixTx, txID), &t); err != nil || !t.BlockHeight.Valid {
+			return null.Int{}
+		}
+		if !height.Valid || t.BlockHeight.Int64 > height.Int64 {
+			height = t.BlockHeight
+		}
+	}
+	return height
+}
+
+// invoiceCreatedKey returns the created index key for inv, keys sort by creation date then id.
+func invoiceCreatedKey(inv *invoice) []byte {
+	return key(idxInvoiceCreated, fmtID(uint64(inv.CreatedAt.UnixNano())), inv.ID)
+}
+
+// txnInvoiceSave writes inv, moving it from the prev state index to its current
+// state index. prev is empty for new invoices.
+func txnInvoiceSave(txn *badgerdb.Txn, inv *invoice, prev payd.InvoiceState) error {
+	if err := set(txn, key(prefixInvoice, inv.ID), inv); err != nil {
+		return err
+	}
+	if prev == "" {
+		if err := index(txn, invoiceCreatedKey(inv)); err != nil {
+			return errors.Wrap(err, "failed to add invoice created index")
+		}
+	}
+	if prev == inv.State {
+		return nil
+	}
+	if prev != "" {
+		if err := txn.Delete(key(idxInvoiceState, string(prev), inv.ID)); err != nil {
+			return errors.Wrap(err, "failed to remove invoice state index")
+		}
+	}
+	return errors.Wrap(index(txn, key(idxInvoiceState, string(inv.State), inv.ID)), "failed to add invoice state index")
+}
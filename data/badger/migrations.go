@@ -0,0 +1,241 @@
+package badger
+
+import (
+	"encoding/binary"
+	"strings"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+)
+
+// migration applies a single versioned change to the store.
+type migration func(txn *badgerdb.Txn) error
+
+// migrations are applied in order, each one once, the index of the last applied
+// migration is kept so new migrations must only ever be appended.
+// nolint:gochecknoglobals // this is fine as it is private and never modified.
+var migrations = []migration{
+	migrateInitial,
+	migrateInvoiceCreatedIndex,
+	migratePayments,
+	migrateRefunds,
+	migrateProofCallbacks,
+	migrateUTXOReservations,
+	migrateConfirmations,
+}
+
+// Migrate will apply any migrations not yet applied to the db.
+func Migrate(db *badgerdb.DB) error {
+	for {
+		done, err := migrateNext(db)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// migrateNext will apply the next migration, returning true once there are no more to apply.
+func migrateNext(db *badgerdb.DB) (bool, error) {
+	var done bool
+	err := db.Update(func(txn *badgerdb.Txn) error {
+		var version uint64
+		item, err := txn.Get(key(prefixMeta, "version"))
+		switch {
+		case err == nil:
+			if err := item.Value(func(val []byte) error {
+				version = binary.BigEndian.Uint64(val)
+				return nil
+			}); err != nil {
+				return errors.Wrap(err, "failed to read db version")
+			}
+		case !errors.Is(err, badgerdb.ErrKeyNotFound):
+			return errors.Wrap(err, "failed to read db version")
+		}
+		if version >= uint64(len(migrations)) {
+			done = true
+			return nil
+		}
+		if err := migrations[version](txn); err != nil {
+			return errors.Wrapf(err, "failed to apply migration %d", version+1)
+		}
+		bb := make([]byte, 8)
+		binary.BigEndian.PutUint64(bb, version+1)
+		return errors.Wrap(txn.Set(key(prefixMeta, "version"), bb), "failed to store db version")
+	})
+	return done, err
+}
+
+// migrateInitial seeds the same users and accounts as the sql stores.
+func migrateInitial(txn *badgerdb.Txn) error {
+	for _, u := range []user{{
+		ID:          0,
+		Name:        "Userless",
+		Email:       "user@example.com",
+		Address:     "123 Street Fake",
+		PhoneNumber: "123456789",
+	}, {
+		ID:          1,
+		Name:        "BitcoinSV LiteClient",
+		IsOwner:     true,
+		Avatar:      "https://bitcoinassociation.net/wp-content/uploads/2019/09/2-bsv-logo-engtag-full.png",
+		Email:       "https://discord.gg/bsv",
+		Address:     "1 BSV Avenue",
+		PhoneNumber: "0800-123-456",
+		ExtendedData: map[string]string{
+			"example key 1": "example value 1",
+			"example key 2": "example value 2",
+		},
+	}} {
+		if err := set(txn, key(prefixUser, fmtID(u.ID)), u); err != nil {
+			return errors.Wrapf(err, "failed to create user %d", u.ID)
+		}
+	}
+	if err := setSeq(txn, prefixUser, 1); err != nil {
+		return err
+	}
+	// account 0 is used by the userless user, for receiving change proofs.
+	for userID, acc := range []peerChannelAccount{{
+		ID: 0,
+	}, {
+		ID:       1,
+		UserName: "username",
+		Password: "password",
+	}} {
+		if err := set(txn, key(prefixPeerChannelAccount, fmtID(uint64(userID))), acc); err != nil {
+			return errors.Wrapf(err, "failed to create peer channel account for user %d", userID)
+		}
+	}
+	return nil
+}
+
+// migrateInvoiceCreatedIndex adds the created index, used to page through invoices, to existing invoices.
+func migrateInvoiceCreatedIndex(txn *badgerdb.Txn) error {
+	return each(txn, prefix(prefixInvoice), func() interface{} { return &invoice{} }, func(v interface{}) error {
+		inv := v.(*invoice)
+		return errors.Wrapf(index(txn, invoiceCreatedKey(inv)), "failed to index invoice %s", inv.ID)
+	})
+}
+
+// migratePayments stores the value of existing txos, which was previously read from their
+// destination, and records a payment for invoices that were paid in full by a single tx.
+func migratePayments(txn *badgerdb.Txn) error {
+	if err := each(txn, prefix(prefixTxo), func() interface{} { return &txo{} }, func(v interface{}) error {
+		t := v.(*txo)
+		var d destination
+		if err := txnDestination(txn, t.DestinationID, &d); err != nil {
+			return err
+		}
+		t.Satoshis = d.Satoshis
+		return errors.Wrapf(set(txn, key(prefixTxo, t.Outpoint), t), "failed to update txo %s", t.Outpoint)
+	}); err != nil {
+		return err
+	}
+	return each(txn, prefix(prefixInvoice), func() interface{} { return &invoice{} }, func(v interface{}) error {
+		inv := v.(*invoice)
+		if inv.State != payd.StateInvoicePaid && inv.State != payd.StateInvoiceRefunded {
+			return nil
+		}
+		for _, txID := range ids(txn, prefix(idxInvoiceTx, inv.ID)) {
+			var tx transaction
+			if err := get(txn, key(prefixTx, txID), &tx); err != nil {
+				return errors.Wrapf(err, "failed to get tx %s", txID)
+			}
+			if tx.State != payd.StateTxBroadcast {
+				continue
+			}
+			if err := set(txn, key(prefixPayment, inv.ID, txID), payment{
+				InvoiceID: inv.ID,
+				TxID:      txID,
+				Satoshis:  inv.Satoshis,
+				RefundTo:  inv.RefundTo,
+				CreatedAt: inv.PaymentReceivedAt.ValueOrZero(),
+			}); err != nil {
+				return errors.Wrapf(err, "failed to create payment for invoice %s", inv.ID)
+			}
+		}
+		inv.SatoshisReceived = inv.Satoshis
+		return errors.Wrapf(set(txn, key(prefixInvoice, inv.ID), inv), "failed to update invoice %s", inv.ID)
+	})
+}
+
+// migrateRefunds sets the satoshis refunded for invoices that were refunded in full.
+func migrateRefunds(txn *badgerdb.Txn) error {
+	return each(txn, prefix(prefixInvoice), func() interface{} { return &invoice{} }, func(v interface{}) error {
+		inv := v.(*invoice)
+		if inv.State != payd.StateInvoiceRefunded {
+			return nil
+		}
+		inv.SatoshisRefunded = inv.SatoshisReceived
+		return errors.Wrapf(set(txn, key(prefixInvoice, inv.ID), inv), "failed to update invoice %s", inv.ID)
+	})
+}
+
+// migrateProofCallbacks rekeys proof callbacks by the tx paying their invoice, callbacks were
+// previously keyed by invoice and invoices were paid by a single tx.
+func migrateProofCallbacks(txn *badgerdb.Txn) error {
+	var cc []proofCallback
+	if err := each(txn, prefix(prefixProofCallback), func() interface{} { return &proofCallback{} }, func(v interface{}) error {
+		cc = append(cc, *v.(*proofCallback))
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, c := range cc {
+		if err := txn.Delete(key(prefixProofCallback, c.InvoiceID, c.URL)); err != nil {
+			return errors.Wrapf(err, "failed to remove proof callback %s for invoice %s", c.URL, c.InvoiceID)
+		}
+		for _, txID := range ids(txn, prefix(prefixPayment, c.InvoiceID)) {
+			c.TxID = txID
+			if err := set(txn, key(prefixProofCallback, txID, c.URL), c); err != nil {
+				return errors.Wrapf(err, "failed to update proof callback %s for invoice %s", c.URL, c.InvoiceID)
+			}
+		}
+	}
+	return nil
+}
+
+// migrateUTXOReservations expires existing reservations, they were reserved before
+// reservations had an expiry so are released by the next run of the reservation job.
+func migrateUTXOReservations(txn *badgerdb.Txn) error {
+	for _, outpoint := range ids(txn, prefix(idxTxoStatus, txoReserved)) {
+		var t txo
+		if err := txnTxo(txn, outpoint, &t); err != nil {
+			return err
+		}
+		t.ReservedUntil = null.TimeFrom(t.UpdatedAt)
+		if err := txnTxoSave(txn, &t, txoReserved); err != nil {
+			return errors.Wrapf(err, "failed to update reservation of txo %s", outpoint)
+		}
+	}
+	return nil
+}
+
+// migrateConfirmations confirms broadcast txs with a proof, they were treated as confirmed
+// before the mined state was added.
+func migrateConfirmations(txn *badgerdb.Txn) error {
+	// proof keys are txID/blockHash.
+	for _, id := range ids(txn, prefix(prefixProof)) {
+		txID := strings.SplitN(id, keySep, 2)[0]
+		var t transaction
+		if err := get(txn, key(prefixTx, txID), &t); err != nil {
+			if errors.Is(err, badgerdb.ErrKeyNotFound) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get tx %s", txID)
+		}
+		if t.State != payd.StateTxBroadcast {
+			continue
+		}
+		t.State = payd.StateTxConfirmed
+		if err := set(txn, key(prefixTx, txID), t); err != nil {
+			return errors.Wrapf(err, "failed to confirm tx %s", txID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package badger
+
+import (
+	"context"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// Owner will return the owner of the wallet.
+func (s *badgerStore) Owner(ctx context.Context) (*payd.User, error) {
+	var owner *payd.User
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixUser), func() interface{} { return &user{} }, func(v interface{}) error {
+			if u := v.(*user); owner == nil && u.IsOwner {
+				owner = u.toUser()
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet owner")
+	}
+	if owner == nil {
+		return nil, lathos.NewErrNotFound("N004", "failed to get wallet owner")
+	}
+	return owner, nil
+}
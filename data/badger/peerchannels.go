@@ -0,0 +1,173 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// peerChannelAccount is the stored representation of a users peer channel account.
+type peerChannelAccount struct {
+	ID       int64  `json:"id"`
+	UserName string `json:"userName"`
+	Password string `json:"password"`
+}
+
+// peerChannel is the stored representation of a peer channel.
+type peerChannel struct {
+	AccountID int64                       `json:"accountId"`
+	ChannelID string                      `json:"channelId"`
+	Host      string                      `json:"host"`
+	Path      string                      `json:"path"`
+	Type      payd.PeerChannelHandlerType `json:"type"`
+	CreatedAt time.Time                   `json:"createdAt"`
+	Closed    bool                        `json:"closed"`
+}
+
+// peerChannelToken is the stored representation of a peer channel api token.
+type peerChannelToken struct {
+	ChannelID string `json:"channelId"`
+	Token     string `json:"token"`
+	Role      string `json:"role"`
+	CanRead   bool   `json:"canRead"`
+	CanWrite  bool   `json:"canWrite"`
+}
+
+// PeerChannelAccount will return the peer channel account for a user.
+func (s *badgerStore) PeerChannelAccount(ctx context.Context, args *payd.PeerChannelIDArgs) (*payd.PeerChannelAccount, error) {
+	var acc peerChannelAccount
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return get(txn, key(prefixPeerChannelAccount, fmtID(uint64(args.UserID))), &acc)
+	}); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return nil, lathos.NewErrNotFound(errcodes.ErrPeerChannelNotFound, fmt.Sprintf("peer channel account for user id %d not found", args.UserID))
+		}
+		return nil, errors.Wrapf(err, "failed to get peer channel for user id %d", args.UserID)
+	}
+	return &payd.PeerChannelAccount{
+		ID:       acc.ID,
+		Username: acc.UserName,
+		Password: acc.Password,
+	}, nil
+}
+
+// PeerChannelCreate will store a new open peer channel.
+func (s *badgerStore) PeerChannelCreate(ctx context.Context, args *payd.PeerChannelCreateArgs) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	if args.CreatedAt.IsZero() {
+		args.CreatedAt = time.Now()
+	}
+	k := key(prefixPeerChannel, args.ChannelID)
+	ok, err := exists(txn, k)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for channel %s", args.ChannelID)
+	}
+	if ok {
+		return lathos.NewErrDuplicate("D001", fmt.Sprintf("channel %s already exists", args.ChannelID))
+	}
+	if err := set(txn, k, peerChannel{
+		AccountID: args.PeerChannelAccountID,
+		ChannelID: args.ChannelID,
+		Host:      args.ChannelHost,
+		Path:      args.ChannelPath,
+		Type:      args.ChannelType,
+		CreatedAt: args.CreatedAt,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to insert channel %s", args.ChannelID)
+	}
+	if err := index(txn, key(idxPeerChannelOpen, string(args.ChannelType), args.ChannelID)); err != nil {
+		return errors.Wrapf(err, "failed to index channel %s", args.ChannelID)
+	}
+	return errors.Wrapf(commit(ctx, txn), "failed to commit creating channel %s", args.ChannelID)
+}
+
+// PeerChannelAPITokenCreate will store an api token for a channel.
+func (s *badgerStore) PeerChannelAPITokenCreate(ctx context.Context, args *payd.PeerChannelAPITokenStoreArgs) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	ok, err := exists(txn, key(prefixPeerChannel, args.PeerChannelsChannelID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for channel %s", args.PeerChannelsChannelID)
+	}
+	if !ok {
+		return lathos.NewErrNotFound(errcodes.ErrPeerChannelNotFound, fmt.Sprintf("channel %s not found", args.PeerChannelsChannelID))
+	}
+	if err := set(txn, key(prefixPeerChannelTok, args.PeerChannelsChannelID, args.Token), peerChannelToken{
+		ChannelID: args.PeerChannelsChannelID,
+		Token:     args.Token,
+		Role:      args.Role,
+		CanRead:   args.CanRead,
+		CanWrite:  args.CanWrite,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to insert api token %s for channel %s", args.Token, args.PeerChannelsChannelID)
+	}
+	return errors.Wrapf(commit(ctx, txn), "failed to commit creating token %s for channel %s", args.Token, args.PeerChannelsChannelID)
+}
+
+// PeerChannelAPITokensCreate will store multiple api tokens.
+func (s *badgerStore) PeerChannelAPITokensCreate(ctx context.Context, entries ...*payd.PeerChannelAPITokenStoreArgs) error {
+	for _, entry := range entries {
+		if err := s.PeerChannelAPITokenCreate(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PeerChannelsOpened returns the open channels of channelType, a channel is returned once per api token.
+func (s *badgerStore) PeerChannelsOpened(ctx context.Context, channelType payd.PeerChannelHandlerType) ([]payd.PeerChannel, error) {
+	var resp []payd.PeerChannel
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, channelID := range ids(txn, prefix(idxPeerChannelOpen, string(channelType))) {
+			var pc peerChannel
+			if err := get(txn, key(prefixPeerChannel, channelID), &pc); err != nil {
+				return errors.Wrapf(err, "failed to get channel %s", channelID)
+			}
+			for _, tok := range ids(txn, prefix(prefixPeerChannelTok, channelID)) {
+				resp = append(resp, payd.PeerChannel{
+					ID:        pc.ChannelID,
+					Token:     tok,
+					Host:      pc.Host,
+					Path:      pc.Path,
+					CreatedAt: pc.CreatedAt,
+					Type:      pc.Type,
+				})
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to query for opened peer channels")
+	}
+	return resp, nil
+}
+
+// PeerChannelCloseChannel will mark a channel as closed.
+func (s *badgerStore) PeerChannelCloseChannel(ctx context.Context, channelID string) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var pc peerChannel
+	if err := get(txn, key(prefixPeerChannel, channelID), &pc); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrPeerChannelNotFound, fmt.Sprintf("channel %s not found", channelID))
+		}
+		return errors.Wrapf(err, "failed to close channel %s", channelID)
+	}
+	pc.Closed = true
+	if err := set(txn, key(prefixPeerChannel, channelID), pc); err != nil {
+		return errors.Wrapf(err, "failed to close channel %s", channelID)
+	}
+	if err := txn.Delete(key(idxPeerChannelOpen, string(pc.Type), channelID)); err != nil {
+		return errors.Wrapf(err, "failed to close channel %s", channelID)
+	}
+
+	return errors.Wrap(commit(ctx, txn), "failed to commit transaction for peerChannelClose")
+}
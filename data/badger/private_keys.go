@@ -0,0 +1,114 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// privateKey is the stored representation of a private key.
+type privateKey struct {
+	UserID    uint64      `json:"userId"`
+	Name      string      `json:"name"`
+	Xprv      string      `json:"xprv"`
+	Mnemonic  null.String `json:"mnemonic"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// PrivateKey will return a key by name from the datastore.
+// If not found nil is returned.
+func (s *badgerStore) PrivateKey(ctx context.Context, args payd.KeyArgs) (*payd.PrivateKey, error) {
+	var k privateKey
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return get(txn, key(prefixKey, fmtID(args.UserID), args.Name), &k)
+	}); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get key named %s from datastore", args.Name)
+	}
+	return &payd.PrivateKey{
+		UserID:    k.UserID,
+		Name:      k.Name,
+		Xprv:      k.Xprv,
+		Mnemonic:  k.Mnemonic,
+		CreatedAt: k.CreatedAt,
+	}, nil
+}
+
+// PrivateKeyCreate will create and return a new key in the database.
+func (s *badgerStore) PrivateKeyCreate(ctx context.Context, req payd.PrivateKey) (*payd.PrivateKey, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	k := key(prefixKey, fmtID(req.UserID), req.Name)
+	ok, err := exists(txn, k)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check for key named '%s'", req.Name)
+	}
+	if ok {
+		return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("key named '%s' already exists", req.Name))
+	}
+	req.CreatedAt = time.Now().UTC()
+	if err := set(txn, k, privateKey{
+		UserID:    req.UserID,
+		Name:      req.Name,
+		Xprv:      req.Xprv,
+		Mnemonic:  req.Mnemonic,
+		CreatedAt: req.CreatedAt,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to add key named '%s'", req.Name)
+	}
+	return &req, errors.Wrap(commit(ctx, txn), "failed to commit create key tx")
+}
+
+// PrivateKeys will return every key in the datastore.
+func (s *badgerStore) PrivateKeys(ctx context.Context) ([]payd.PrivateKey, error) {
+	resp := []payd.PrivateKey{}
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixKey), func() interface{} { return &privateKey{} }, func(v interface{}) error {
+			k := v.(*privateKey)
+			resp = append(resp, payd.PrivateKey{
+				UserID:    k.UserID,
+				Name:      k.Name,
+				Xprv:      k.Xprv,
+				Mnemonic:  k.Mnemonic,
+				CreatedAt: k.CreatedAt,
+			})
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get keys from datastore")
+	}
+	return resp, nil
+}
+
+// PrivateKeysUpdate will replace the xprv of each key, if any aren't found none are updated.
+func (s *badgerStore) PrivateKeysUpdate(ctx context.Context, req []payd.PrivateKey) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	for _, r := range req {
+		var k privateKey
+		kk := key(prefixKey, fmtID(r.UserID), r.Name)
+		if err := get(txn, kk, &k); err != nil {
+			if errors.Is(err, badgerdb.ErrKeyNotFound) {
+				return lathos.NewErrNotFound(errcodes.ErrPrivateKeyNotFound,
+					fmt.Sprintf("key named '%s' for user %d not found", r.Name, r.UserID))
+			}
+			return errors.Wrapf(err, "failed to get key named '%s'", r.Name)
+		}
+		k.Xprv = r.Xprv
+		k.Mnemonic = r.Mnemonic
+		if err := set(txn, kk, k); err != nil {
+			return errors.Wrapf(err, "failed to update key named '%s'", r.Name)
+		}
+	}
+	return errors.Wrap(commit(ctx, txn), "failed to commit update keys tx")
+}
@@ -0,0 +1,238 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// proofCallback is the stored representation of a merkle proof callback.
+type proofCallback struct {
+	InvoiceID     string                  `json:"invoiceId"`
+	TxID          string                  `json:"txId"`
+	URL           string                  `json:"url"`
+	Token         string                  `json:"token"`
+	State         payd.ProofCallbackState `json:"state"`
+	Attempts      int                     `json:"attempts"`
+	Proof         []byte                  `json:"proof"`
+	NextAttemptAt null.Time               `json:"nextAttemptAt"`
+	LastError     null.String             `json:"lastError"`
+	CreatedAt     time.Time               `json:"createdAt"`
+	UpdatedAt     time.Time               `json:"updatedAt"`
+}
+
+func (c proofCallback) toProofCallback() payd.ProofCallback {
+	return payd.ProofCallback{
+		InvoiceID:     c.InvoiceID,
+		TxID:          c.TxID,
+		URL:           c.URL,
+		Token:         c.Token,
+		State:         c.State,
+		Attempts:      c.Attempts,
+		Proof:         c.Proof,
+		NextAttemptAt: c.NextAttemptAt,
+		LastError:     c.LastError,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+}
+
+// ProofCallbacks returns the callbacks for an invoice, optionally only those for a single transaction.
+func (s *badgerStore) ProofCallbacks(ctx context.Context, args payd.ProofCallbackArgs) ([]payd.ProofCallback, error) {
+	var resp []payd.ProofCallback
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, txID := range ids(txn, prefix(idxInvoiceTx, args.InvoiceID)) {
+			if args.TxID != "" && txID != args.TxID {
+				continue
+			}
+			if err := each(txn, prefix(prefixProofCallback, txID), func() interface{} { return &proofCallback{} }, func(v interface{}) error {
+				resp = append(resp, v.(*proofCallback).toProofCallback())
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get proof callbacks for invoiceID %s", args.InvoiceID)
+	}
+	return resp, nil
+}
+
+// ProofCallbacksDue returns the pending callbacks with a proof that are due to be sent, oldest first.
+func (s *badgerStore) ProofCallbacksDue(ctx context.Context, args payd.ProofCallbacksDueArgs) ([]payd.ProofCallback, error) {
+	var resp []payd.ProofCallback
+	due := fmtID(uint64(args.DueBefore.UnixNano()))
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, entry := range ids(txn, prefix(idxProofCallbackDue)) {
+			if args.Limit > 0 && len(resp) == args.Limit {
+				return nil
+			}
+			// entries are ordered by next attempt then txID and url, the url can contain the
+			// key separator so is always the remainder of the key.
+			parts := strings.SplitN(entry, keySep, 3)
+			if parts[0] > due {
+				return nil
+			}
+			var c proofCallback
+			if err := txnProofCallback(txn, parts[1], parts[2], &c); err != nil {
+				return err
+			}
+			resp = append(resp, c.toProofCallback())
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get due proof callbacks")
+	}
+	return resp, nil
+}
+
+// ProofCallBacksCreate will store merkle proof callback urls for a payment.
+func (s *badgerStore) ProofCallBacksCreate(ctx context.Context, args payd.ProofCallbackArgs, req map[string]dpp.ProofCallback) error {
+	if len(req) == 0 {
+		// nothing to store
+		return nil
+	}
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var inv invoice
+	if err := txnInvoice(txn, args.InvoiceID, &inv); err != nil {
+		return errors.WithMessagef(err, "failed to insert callback urls for invoiceID %s", args.InvoiceID)
+	}
+	ok, err := exists(txn, key(prefixTx, args.TxID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for tx %s", args.TxID)
+	}
+	if !ok {
+		return lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("transaction with txID %s not found", args.TxID))
+	}
+	now := time.Now().UTC()
+	for url, val := range req {
+		k := key(prefixProofCallback, args.TxID, url)
+		ok, err := exists(txn, k)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check for callback url %s", url)
+		}
+		if ok {
+			return lathos.NewErrDuplicate("D001", fmt.Sprintf("callback url %s already exists for txID %s", url, args.TxID))
+		}
+		if err := set(txn, k, proofCallback{
+			InvoiceID: args.InvoiceID,
+			TxID:      args.TxID,
+			URL:       url,
+			Token:     val.Token,
+			State:     payd.StateProofCallbackPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to insert callback urls for invoiceID %s", args.InvoiceID)
+		}
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when creating callback urls with invoiceID %s", args.InvoiceID)
+	}
+	return nil
+}
+
+// ProofCallbacksQueue will store the proof for each callback of a transaction and queue them to be sent.
+func (s *badgerStore) ProofCallbacksQueue(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var cc []proofCallback
+	if err := each(txn, prefix(prefixProofCallback, args.TxID), func() interface{} { return &proofCallback{} }, func(v interface{}) error {
+		cc = append(cc, *v.(*proofCallback))
+		return nil
+	}); err != nil {
+		return errors.Wrapf(err, "failed to get proof callbacks for txID %s", args.TxID)
+	}
+	for _, prev := range cc {
+		c := prev
+		c.Proof = req.Proof
+		c.State = payd.StateProofCallbackPending
+		c.Attempts = 0
+		c.NextAttemptAt = null.TimeFrom(req.NextAttemptAt.UTC())
+		c.LastError = null.String{}
+		c.UpdatedAt = req.UpdatedAt.UTC()
+		if err := txnProofCallbackSave(txn, &c, &prev); err != nil {
+			return errors.Wrapf(err, "failed to queue proof callback %s for txID %s", c.URL, args.TxID)
+		}
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when queueing proof callbacks for txID %s", args.TxID)
+	}
+	return nil
+}
+
+// ProofCallbackUpdate will record the outcome of sending a proof callback.
+func (s *badgerStore) ProofCallbackUpdate(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var prev proofCallback
+	if err := txnProofCallback(txn, args.TxID, args.URL, &prev); err != nil {
+		return err
+	}
+	c := prev
+	c.State = req.State
+	c.Attempts = req.Attempts
+	c.NextAttemptAt = req.NextAttemptAt
+	if c.NextAttemptAt.Valid {
+		c.NextAttemptAt.Time = c.NextAttemptAt.Time.UTC()
+	}
+	c.LastError = req.LastError
+	c.UpdatedAt = req.UpdatedAt.UTC()
+	if err := txnProofCallbackSave(txn, &c, &prev); err != nil {
+		return errors.Wrapf(err, "failed to update proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when updating proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	return nil
+}
+
+// txnProofCallback reads a proof callback, returning a not found error if it doesn't exist.
+func txnProofCallback(txn *badgerdb.Txn, txID, url string, c *proofCallback) error {
+	if err := get(txn, key(prefixProofCallback, txID, url), c); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrProofCallbackNotFound, fmt.Sprintf("proof callback %s for txID %s not found", url, txID))
+		}
+		return errors.Wrapf(err, "failed to get proof callback %s for txID %s", url, txID)
+	}
+	return nil
+}
+
+// proofCallbackDueKey returns the due index key for c, keys sort by next attempt then txID and url.
+func proofCallbackDueKey(c *proofCallback) []byte {
+	return key(idxProofCallbackDue, fmtID(uint64(c.NextAttemptAt.Time.UnixNano())), c.TxID, c.URL)
+}
+
+// proofCallbackDue returns true if c should be in the due index.
+func proofCallbackDue(c *proofCallback) bool {
+	return c.State == payd.StateProofCallbackPending && c.NextAttemptAt.Valid
+}
+
+// txnProofCallbackSave writes c, only pending callbacks with a proof are kept in the due index.
+// prev is nil for new callbacks.
+func txnProofCallbackSave(txn *badgerdb.Txn, c, prev *proofCallback) error {
+	if err := set(txn, key(prefixProofCallback, c.TxID, c.URL), c); err != nil {
+		return err
+	}
+	if prev != nil && proofCallbackDue(prev) {
+		if err := txn.Delete(proofCallbackDueKey(prev)); err != nil {
+			return errors.Wrap(err, "failed to remove proof callback due index")
+		}
+	}
+	if !proofCallbackDue(c) {
+		return nil
+	}
+	return errors.Wrap(index(txn, proofCallbackDueKey(c)), "failed to add proof callback due index")
+}
@@ -0,0 +1,143 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// staleProof is a proof whose block was orphaned, it is moved out of the proofs so it is
+// no longer read as a merkle proof of its tx.
+type staleProof struct {
+	Proof   *bc.MerkleProof `json:"proof"`
+	StaleAt time.Time       `json:"staleAt"`
+}
+
+// ProofCreate will store a proof against its tx and block.
+func (s *badgerStore) ProofCreate(ctx context.Context, req dpp.ProofWrapper) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	k := key(prefixProof, req.CallbackTxID, req.BlockHash)
+	ok, err := exists(txn, k)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if ok {
+		return lathos.NewErrDuplicate("D001", fmt.Sprintf("proof for txid %s and blockhash '%s' already exists", req.CallbackTxID, req.BlockHash))
+	}
+	// a tx can only be in one block of the best chain so proofs in any other block are stale.
+	now := time.Now().UTC()
+	for _, hash := range ids(txn, prefix(prefixProof, req.CallbackTxID)) {
+		if err := proofStale(txn, req.CallbackTxID, hash, now); err != nil {
+			return errors.Wrapf(err, "failed to mark proof for txid %s in block %s stale", req.CallbackTxID, hash)
+		}
+	}
+	// a block that was orphaned then rejoined the best chain replaces its stale proof.
+	if err := txn.Delete(key(prefixProofStale, req.CallbackTxID, req.BlockHash)); err != nil {
+		return errors.Wrapf(err, "failed to remove stale proof for txid %s and blockhash '%s'", req.CallbackTxID, req.BlockHash)
+	}
+	if err := set(txn, k, req.CallbackPayload); err != nil {
+		return errors.Wrapf(err, "failed to proof for txid %s and blockhash '%s'", req.CallbackTxID, req.BlockHash)
+	}
+	return errors.WithStack(commit(ctx, txn))
+}
+
+// MerkleProof will retrieve a proof.
+func (s *badgerStore) MerkleProof(ctx context.Context, txID string) (*bc.MerkleProof, error) {
+	var proof *bc.MerkleProof
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		hashes := ids(txn, prefix(prefixProof, txID))
+		if len(hashes) == 0 {
+			return nil
+		}
+		proof = &bc.MerkleProof{}
+		return get(txn, key(prefixProof, txID, hashes[0]), proof)
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve proof")
+	}
+	return proof, nil
+}
+
+// ProofBlocks will return the blocks of the proofs of mined and confirmed transactions, highest first.
+func (s *badgerStore) ProofBlocks(ctx context.Context, args payd.ProofBlocksArgs) ([]payd.ProofBlock, error) {
+	blocks := []payd.ProofBlock{}
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		seen := map[payd.ProofBlock]bool{}
+		// proof keys are txID/blockHash.
+		for _, id := range ids(txn, prefix(prefixProof)) {
+			parts := strings.SplitN(id, keySep, 2)
+			var t transaction
+			if err := get(txn, key(prefixTx, parts[0]), &t); err != nil {
+				if errors.Is(err, badgerdb.ErrKeyNotFound) {
+					continue
+				}
+				return errors.Wrapf(err, "failed to get tx %s", parts[0])
+			}
+			if t.State != payd.StateTxMined && t.State != payd.StateTxConfirmed {
+				continue
+			}
+			if !t.BlockHeight.Valid || t.BlockHeight.Int64 < int64(args.MinBlockHeight) {
+				continue
+			}
+			b := payd.ProofBlock{BlockHash: parts[1], BlockHeight: uint32(t.BlockHeight.Int64)}
+			if !seen[b] {
+				seen[b] = true
+				blocks = append(blocks, b)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to get proof blocks at or above height %d", args.MinBlockHeight)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].BlockHeight != blocks[j].BlockHeight {
+			return blocks[i].BlockHeight > blocks[j].BlockHeight
+		}
+		return blocks[i].BlockHash < blocks[j].BlockHash
+	})
+	return blocks, nil
+}
+
+// ProofsStale will mark the proofs of a block stale, returning the txIDs of the proofs.
+func (s *badgerStore) ProofsStale(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	txIDs := []string{}
+	for _, id := range ids(txn, prefix(prefixProof)) {
+		parts := strings.SplitN(id, keySep, 2)
+		if parts[1] != args.BlockHash {
+			continue
+		}
+		if err := proofStale(txn, parts[0], parts[1], args.StaleAt); err != nil {
+			return nil, errors.Wrapf(err, "failed to mark proof for txid %s in block %s stale", parts[0], args.BlockHash)
+		}
+		txIDs = append(txIDs, parts[0])
+	}
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when marking proofs of block %s stale", args.BlockHash)
+	}
+	return txIDs, nil
+}
+
+// proofStale moves a proof to the stale proofs.
+func proofStale(txn *badgerdb.Txn, txID, blockHash string, staleAt time.Time) error {
+	k := key(prefixProof, txID, blockHash)
+	proof := staleProof{Proof: &bc.MerkleProof{}, StaleAt: staleAt}
+	if err := get(txn, k, proof.Proof); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := set(txn, key(prefixProofStale, txID, blockHash), proof); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(txn.Delete(k))
+}
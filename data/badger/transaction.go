@@ -0,0 +1,316 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// transaction is the stored representation of a transaction.
+type transaction struct {
+	TxID        string       `json:"txId"`
+	TxHex       string       `json:"txHex"`
+	State       payd.TxState `json:"state"`
+	FailReason  null.String  `json:"failReason"`
+	BlockHeight null.Int     `json:"blockHeight"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// txo is the stored representation of a transaction output paying one of our destinations.
+type txo struct {
+	Outpoint      string      `json:"outpoint"`
+	DestinationID uint64      `json:"destinationId"`
+	TxID          string      `json:"txId"`
+	Vout          uint64      `json:"vout"`
+	Satoshis      uint64      `json:"satoshis"`
+	ReservedFor   null.String `json:"reservedFor"`
+	ReservedUntil null.Time   `json:"reservedUntil"`
+	Frozen        bool        `json:"frozen"`
+	SpentAt       null.Time   `json:"spentAt"`
+	SpendingTxID  null.String `json:"spendingTxId"`
+	CreatedAt     time.Time   `json:"createdAt"`
+	UpdatedAt     time.Time   `json:"updatedAt"`
+}
+
+// status returns the txo status used to index it.
+func (t txo) status() string {
+	switch {
+	case t.SpentAt.Valid || t.SpendingTxID.Valid:
+		return txoSpent
+	case t.ReservedFor.Valid:
+		return txoReserved
+	default:
+		return txoUnspent
+	}
+}
+
+// TransactionCreate will store a transaction and its txos in the data base.
+func (s *badgerStore) TransactionCreate(ctx context.Context, req payd.TransactionCreate) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	ok, err := exists(txn, key(prefixTx, req.TxID))
+	if err != nil {
+		return errors.Wrapf(err, "failed to check for transaction %s", req.TxID)
+	}
+	if ok {
+		return lathos.NewErrDuplicate("D001", "transaction has already been stored")
+	}
+	timestamp := time.Now().UTC()
+	if err := set(txn, key(prefixTx, req.TxID), transaction{
+		TxID:      req.TxID,
+		TxHex:     req.TxHex,
+		State:     payd.StateTxPending,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	}); err != nil {
+		return errors.Wrap(err, "failed to insert new transaction")
+	}
+
+	if err := s.insertOutputs(txn, timestamp, req); err != nil {
+		return err
+	}
+
+	// If no invoice id was provided, end here as this is a change tx.
+	if req.InvoiceID == "" {
+		return errors.Wrapf(commit(ctx, txn),
+			"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
+	}
+
+	var inv invoice
+	if err := txnInvoice(txn, req.InvoiceID, &inv); err != nil {
+		return errors.WithMessagef(err, "failed to create invoice mapping for tx %s", req.TxID)
+	}
+	if err := index(txn, key(idxInvoiceTx, req.InvoiceID, req.TxID)); err != nil {
+		return errors.Wrapf(err, "failed to create invoice mapping for tx %s invoice %s", req.TxID, req.InvoiceID)
+	}
+
+	return errors.Wrapf(commit(ctx, txn),
+		"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
+}
+
+// insertOutputs stores the txos of a tx and marks the destinations they pay as received.
+func (s *badgerStore) insertOutputs(txn *badgerdb.Txn, timestamp time.Time, req payd.TransactionCreate) error {
+	for _, o := range req.Outputs {
+		ok, err := exists(txn, key(prefixTxo, o.Outpoint))
+		if err != nil {
+			return errors.Wrapf(err, "failed to check for txo %s", o.Outpoint)
+		}
+		if ok {
+			return lathos.NewErrDuplicate("D001", fmt.Sprintf("txo %s has already been stored", o.Outpoint))
+		}
+		var d destination
+		if err := txnDestination(txn, o.DestinationID, &d); err != nil {
+			return errors.WithMessage(err, "failed to update destinations state to received")
+		}
+		d.State = "received"
+		d.UpdatedAt = timestamp
+		if err := set(txn, key(prefixDestination, fmtID(d.ID)), d); err != nil {
+			return errors.Wrap(err, "failed to update destinations state to received")
+		}
+		t := txo{
+			Outpoint:      o.Outpoint,
+			DestinationID: o.DestinationID,
+			TxID:          o.TxID,
+			Vout:          o.Vout,
+			Satoshis:      o.Satoshis,
+			CreatedAt:     timestamp,
+			UpdatedAt:     timestamp,
+		}
+		if err := txnTxoSave(txn, &t, ""); err != nil {
+			return errors.Wrap(err, "failed to insert transaction outputs")
+		}
+	}
+	return nil
+}
+
+// TransactionUpdateState will update a transactions internal state.
+func (s *badgerStore) TransactionUpdateState(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var t transaction
+	if err := get(txn, key(prefixTx, args.TxID), &t); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("tx '%s' not in store", args.TxID))
+		}
+		return errors.Wrapf(err, "failed to update transactionId '%s' state to '%s'", args.TxID, req.State)
+	}
+	mined := req.State == payd.StateTxMined || req.State == payd.StateTxConfirmed
+	// a tx with a proof on the best chain only goes back to broadcast once its proofs are stale.
+	if !mined && len(ids(txn, prefix(prefixProof, args.TxID))) > 0 {
+		return lathos.NewErrUnprocessable(errcodes.ErrTxStateMined,
+			fmt.Sprintf("tx '%s' is mined and can't be set %s", args.TxID, req.State))
+	}
+	t.State = req.State
+	t.FailReason = req.FailReason
+	switch {
+	case !mined:
+		t.BlockHeight = null.Int{}
+	case req.BlockHeight.Valid:
+		t.BlockHeight = req.BlockHeight
+	}
+	t.UpdatedAt = time.Now().UTC()
+	if err := set(txn, key(prefixTx, args.TxID), t); err != nil {
+		return errors.Wrapf(err, "failed to update transactionId '%s' state to '%s'", args.TxID, req.State)
+	}
+	return errors.Wrapf(commit(ctx, txn),
+		"failed to commit transaction when updating transactionId '%s' state to '%s'", args.TxID, req.State)
+}
+
+// TransactionsConfirm will set mined transactions at or below the max block height to confirmed.
+func (s *badgerStore) TransactionsConfirm(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var confirm []*transaction
+	if err := each(txn, prefix(prefixTx), func() interface{} { return &transaction{} }, func(v interface{}) error {
+		t := v.(*transaction)
+		if t.State == payd.StateTxMined && t.BlockHeight.Int64 <= int64(args.MaxBlockHeight) {
+			confirm = append(confirm, t)
+		}
+		return nil
+	}); err != nil {
+		return 0, errors.Wrapf(err, "failed to get transactions mined at or below height %d", args.MaxBlockHeight)
+	}
+	now := time.Now().UTC()
+	for _, t := range confirm {
+		t.State = payd.StateTxConfirmed
+		t.UpdatedAt = now
+		if err := set(txn, key(prefixTx, t.TxID), t); err != nil {
+			return 0, errors.Wrapf(err, "failed to confirm transactionId '%s'", t.TxID)
+		}
+	}
+	return len(confirm), errors.Wrapf(commit(ctx, txn),
+		"failed to commit transaction when confirming transactions mined at or below height %d", args.MaxBlockHeight)
+}
+
+// Tx returns a tx from the internal store.
+func (s *badgerStore) Tx(ctx context.Context, txID string) (*bt.Tx, error) {
+	var t transaction
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return get(txn, key(prefixTx, txID), &t)
+	}); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return nil, lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("tx '%s' not in store", txID))
+		}
+		return nil, errors.Wrapf(err, "failed to retrieve transaction for id %s", txID)
+	}
+	return bt.NewTxFromString(t.TxHex)
+}
+
+// txnTxo reads the txo at outpoint into t.
+func txnTxo(txn *badgerdb.Txn, outpoint string, t *txo) error {
+	return errors.Wrapf(get(txn, key(prefixTxo, outpoint), t), "failed to get txo %s", outpoint)
+}
+
+// txnTxoSave writes t, moving it from the prev status index to its current
+// status index. prev is empty for new txos.
+func txnTxoSave(txn *badgerdb.Txn, t *txo, prev string) error {
+	if err := set(txn, key(prefixTxo, t.Outpoint), t); err != nil {
+		return err
+	}
+	status := t.status()
+	if prev == status {
+		return nil
+	}
+	if prev != "" {
+		if err := txn.Delete(key(idxTxoStatus, prev, t.Outpoint)); err != nil {
+			return errors.Wrap(err, "failed to remove txo status index")
+		}
+	}
+	return errors.Wrap(index(txn, key(idxTxoStatus, status, t.Outpoint)), "failed to add txo status index")
+}
+
+// Transactions returns the txs matching args, ordered by creation date then id, with
+// the wallet txos they received and spent totalled.
+func (s *badgerStore) Transactions(ctx context.Context, args payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+	txs := map[string]*payd.TransactionHistory{}
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		if err := each(txn, prefix(prefixTx), func() interface{} { return &transaction{} }, func(v interface{}) error {
+			tx := v.(*transaction)
+			txs[tx.TxID] = &payd.TransactionHistory{
+				TxID:       tx.TxID,
+				TxHex:      tx.TxHex,
+				State:      tx.State,
+				FailReason: tx.FailReason,
+				CreatedAt:  tx.CreatedAt,
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := each(txn, prefix(prefixTxo), func() interface{} { return &txo{} }, func(v interface{}) error {
+			t := v.(*txo)
+			if h, ok := txs[t.TxID]; ok {
+				h.Received += t.Satoshis
+			}
+			if !t.SpendingTxID.Valid {
+				return nil
+			}
+			h, ok := txs[t.SpendingTxID.String]
+			if !ok {
+				return nil
+			}
+			h.Spent += t.Satoshis
+			if t.ReservedFor.Valid && (!h.PaidTo.Valid || t.ReservedFor.String < h.PaidTo.String) {
+				h.PaidTo = t.ReservedFor
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		// invoice tx index keys are invoiceID/txID.
+		for _, id := range ids(txn, prefix(idxInvoiceTx)) {
+			parts := strings.SplitN(id, keySep, 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if h, ok := txs[parts[1]]; ok {
+				h.InvoiceID = null.StringFrom(parts[0])
+			}
+		}
+		if err := each(txn, prefix(prefixRefund), func() interface{} { return &refund{} }, func(v interface{}) error {
+			r := v.(*refund)
+			if h, ok := txs[r.TxID]; ok && !h.InvoiceID.Valid {
+				h.InvoiceID = null.StringFrom(r.InvoiceID)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, h := range txs {
+			h.Confirmed = len(ids(txn, prefix(prefixProof, h.TxID))) > 0
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get transactions")
+	}
+	resp := []payd.TransactionHistory{}
+	for _, h := range txs {
+		if args.Matches(*h) {
+			resp = append(resp, *h)
+		}
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		less := resp[i].CreatedAt.Before(resp[j].CreatedAt) ||
+			resp[i].CreatedAt.Equal(resp[j].CreatedAt) && resp[i].TxID < resp[j].TxID
+		if args.Descending() {
+			return !less
+		}
+		return less
+	})
+	if args.Limit > 0 && len(resp) > args.Limit {
+		resp = resp[:args.Limit]
+	}
+	return resp, nil
+}
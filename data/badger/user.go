@@ -0,0 +1,125 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	lerrs "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// user is the stored representation of a user, ExtendedData holds the user meta data.
+type user struct {
+	ID           uint64            `json:"id"`
+	Name         string            `json:"name"`
+	IsOwner      bool              `json:"isOwner"`
+	Avatar       string            `json:"avatar"`
+	Email        string            `json:"email"`
+	Address      string            `json:"address"`
+	PhoneNumber  string            `json:"phoneNumber"`
+	ExtendedData map[string]string `json:"extendedData"`
+}
+
+func (u user) toUser() *payd.User {
+	resp := &payd.User{
+		ID:           u.ID,
+		Name:         u.Name,
+		Email:        u.Email,
+		Avatar:       u.Avatar,
+		Address:      u.Address,
+		PhoneNumber:  u.PhoneNumber,
+		ExtendedData: make(map[string]interface{}, len(u.ExtendedData)),
+	}
+	for k, v := range u.ExtendedData {
+		resp.ExtendedData[k] = v
+	}
+	return resp
+}
+
+// CreateUser creates a new user in the system.
+func (s *badgerStore) CreateUser(ctx context.Context, req payd.CreateUserArgs, pks payd.PrivateKeyService) (*payd.CreateUserResponse, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+
+	userID, err := nextID(txn, prefixUser)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new user: %s", req.Name)
+	}
+	u := user{
+		ID:           userID,
+		Name:         req.Name,
+		Avatar:       req.Avatar,
+		Email:        req.Email,
+		Address:      req.Address,
+		PhoneNumber:  req.PhoneNumber,
+		ExtendedData: make(map[string]string, len(req.ExtendedData)),
+	}
+	// meta data is stored as text, as it is in the sql stores.
+	for k, v := range req.ExtendedData {
+		u.ExtendedData[k] = fmt.Sprintf("%v", v)
+	}
+	if err := set(txn, key(prefixUser, fmtID(userID)), u); err != nil {
+		return nil, errors.Wrapf(err, "failed to create new user: %s", req.Name)
+	}
+
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating new user: %s", req.Name)
+	}
+
+	// Create a new xpriv for this new user
+	if err = pks.Create(ctx, "masterkey", userID); err != nil {
+		return nil, errors.Wrap(err, "failed to create new xkey")
+	}
+	return &payd.CreateUserResponse{ID: userID}, nil
+}
+
+// ReadUser will return a user, they must have a master key.
+func (s *badgerStore) ReadUser(ctx context.Context, userID uint64) (*payd.User, error) {
+	var (
+		u user
+		k privateKey
+	)
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		if err := get(txn, key(prefixUser, fmtID(userID)), &u); err != nil {
+			return err
+		}
+		return get(txn, key(prefixKey, fmtID(userID), "masterkey"), &k)
+	}); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return nil, lerrs.NewErrNotFound("N004", fmt.Sprintf("failed to get wallet owner: user %d not found", userID))
+		}
+		return nil, errors.Wrapf(err, "failed to get user %d", userID)
+	}
+	return u.toUser(), nil
+}
+
+// UpdateUser is not currently supported.
+func (s *badgerStore) UpdateUser(ctx context.Context, ID uint64, d payd.User) (*payd.User, error) {
+	return nil, nil
+}
+
+// DeleteUser will remove a user and their keys.
+func (s *badgerStore) DeleteUser(ctx context.Context, userID uint64) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	ok, err := exists(txn, key(prefixUser, fmtID(userID)))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get user %d", userID)
+	}
+	if !ok {
+		return lerrs.NewErrNotFound("N004", fmt.Sprintf("failed to delete wallet owner: user %d not found", userID))
+	}
+	kk := [][]byte{key(prefixUser, fmtID(userID))}
+	for _, name := range ids(txn, prefix(prefixKey, fmtID(userID))) {
+		kk = append(kk, key(prefixKey, fmtID(userID), name))
+	}
+	for _, k := range kk {
+		if err := txn.Delete(k); err != nil {
+			return errors.Wrapf(err, "failed to delete user %d", userID)
+		}
+	}
+	return errors.Wrapf(commit(ctx, txn), "failed to commit transaction when deleting user %d", userID)
+}
@@ -0,0 +1,292 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos"
+	"github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// UTXOReserve marks the utxos chosen by the selector, from the unspent txos of broadcast txs,
+// as reserved and returns them. If there aren't enough funds nothing is reserved.
+func (s *badgerStore) UTXOReserve(ctx context.Context, req payd.UTXOReserve) ([]payd.UTXO, error) {
+	if req.Selector == nil {
+		return nil, errors.New("a coin selector is required to reserve utxos")
+	}
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var spendable []payd.UTXO
+	for _, outpoint := range ids(txn, prefix(idxTxoStatus, txoUnspent)) {
+		var t txo
+		if err := txnTxo(txn, outpoint, &t); err != nil {
+			return nil, err
+		}
+		var tx transaction
+		if err := get(txn, key(prefixTx, t.TxID), &tx); err != nil {
+			return nil, errors.Wrapf(err, "failed to get tx %s for utxo", t.TxID)
+		}
+		if !req.Spendable(tx.State) || t.Frozen {
+			continue
+		}
+		var d destination
+		if err := txnDestination(txn, t.DestinationID, &d); err != nil {
+			return nil, err
+		}
+		if !req.MatchesKey(d.UserID, d.KeyName) {
+			continue
+		}
+		spendable = append(spendable, payd.UTXO{
+			Outpoint:       t.Outpoint,
+			TxID:           t.TxID,
+			Vout:           uint32(t.Vout),
+			Satoshis:       t.Satoshis,
+			LockingScript:  d.LockingScript,
+			DerivationPath: d.DerivationPath,
+			CreatedAt:      t.CreatedAt,
+		})
+	}
+	sort.SliceStable(spendable, func(i, j int) bool {
+		return spendable[i].CreatedAt.Before(spendable[j].CreatedAt)
+	})
+	utxos := req.Selector.SelectCoins(payd.CoinSelectArgs{
+		Satoshis:  req.Satoshis,
+		InputFee:  req.InputFee,
+		ChangeFee: req.ChangeFee,
+	}, spendable)
+	if len(utxos) == 0 {
+		return []payd.UTXO{}, nil
+	}
+	timestamp := time.Now().UTC()
+	for _, utxo := range utxos {
+		var t txo
+		if err := txnTxo(txn, utxo.Outpoint, &t); err != nil {
+			return nil, err
+		}
+		t.ReservedFor = null.StringFrom(req.ReservedFor)
+		t.ReservedUntil = null.NewTime(req.ReservedUntil, !req.ReservedUntil.IsZero())
+		t.UpdatedAt = timestamp
+		if err := txnTxoSave(txn, &t, txoUnspent); err != nil {
+			return nil, errors.Wrap(err, "failed to reserve utxo")
+		}
+		if err := index(txn, key(idxTxoReservation, req.ReservedFor, t.Outpoint)); err != nil {
+			return nil, errors.Wrap(err, "failed to reserve utxo")
+		}
+	}
+
+	return utxos, errors.Wrap(commit(ctx, txn), "error committing utxo reservation")
+}
+
+// UTXOUnreserve unmarks the reservation from matching reservations that haven't been spent, or were
+// spent by a tx that was never broadcast, which are unspent again.
+func (s *badgerStore) UTXOUnreserve(ctx context.Context, req payd.UTXOUnreserve) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	timestamp := time.Now().UTC()
+	for _, outpoint := range ids(txn, prefix(idxTxoReservation, req.ReservedFor)) {
+		var t txo
+		if err := txnTxo(txn, outpoint, &t); err != nil {
+			return err
+		}
+		ok, err := txnTxoReleasable(txn, &t)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		prev := t.status()
+		t.ReservedFor = null.String{}
+		t.ReservedUntil = null.Time{}
+		t.SpentAt = null.Time{}
+		t.SpendingTxID = null.String{}
+		t.UpdatedAt = timestamp
+		if err := txnTxoSave(txn, &t, prev); err != nil {
+			return errors.Wrap(err, "failed to unreserve utxos")
+		}
+		if err := txn.Delete(key(idxTxoReservation, req.ReservedFor, outpoint)); err != nil {
+			return errors.Wrap(err, "failed to unreserve utxos")
+		}
+	}
+
+	return errors.Wrap(commit(ctx, txn), "failed to commit transaction to unreserve utxos")
+}
+
+// UTXOReservations returns the reserved utxos that haven't been spent, or were spent by a tx
+// that was never broadcast, grouped by reservation.
+func (s *badgerStore) UTXOReservations(ctx context.Context) ([]payd.UTXOReservation, error) {
+	reservations := map[string]*payd.UTXOReservation{}
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		outpoints := append(ids(txn, prefix(idxTxoStatus, txoReserved)), ids(txn, prefix(idxTxoStatus, txoSpent))...)
+		for _, outpoint := range outpoints {
+			var t txo
+			if err := txnTxo(txn, outpoint, &t); err != nil {
+				return err
+			}
+			if !t.ReservedFor.Valid {
+				continue
+			}
+			ok, err := txnTxoReleasable(txn, &t)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			r, ok := reservations[t.ReservedFor.String]
+			if !ok {
+				r = &payd.UTXOReservation{ReservedFor: t.ReservedFor.String}
+				reservations[t.ReservedFor.String] = r
+			}
+			r.UTXOs++
+			r.Satoshis += t.Satoshis
+			if t.ReservedUntil.Valid && (!r.ReservedUntil.Valid || t.ReservedUntil.Time.After(r.ReservedUntil.Time)) {
+				r.ReservedUntil = t.ReservedUntil
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get reserved utxos")
+	}
+	rr := make([]payd.UTXOReservation, 0, len(reservations))
+	for _, r := range reservations {
+		rr = append(rr, *r)
+	}
+	sort.Slice(rr, func(i, j int) bool {
+		return rr[i].ReservedFor < rr[j].ReservedFor
+	})
+	return rr, nil
+}
+
+// txnTxoReleasable returns true if the reservation of t can still be released, that is t is unspent or
+// was spent by a tx that was never broadcast, such as one sent over a socket that was never acked.
+func txnTxoReleasable(txn *badgerdb.Txn, t *txo) (bool, error) {
+	if t.status() != txoSpent {
+		return true, nil
+	}
+	var spending transaction
+	if err := get(txn, key(prefixTx, t.SpendingTxID.String), &spending); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get spending tx of txo %s", t.Outpoint)
+	}
+	return !spending.State.Broadcast(), nil
+}
+
+// UTXOs returns the txos matching args, ordered by creation date then outpoint.
+func (s *badgerStore) UTXOs(ctx context.Context, args payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+	resp := []payd.UTXODetail{}
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, status := range []string{txoUnspent, txoReserved, txoSpent} {
+			for _, outpoint := range ids(txn, prefix(idxTxoStatus, status)) {
+				var t txo
+				if err := txnTxo(txn, outpoint, &t); err != nil {
+					return err
+				}
+				var d destination
+				if err := txnDestination(txn, t.DestinationID, &d); err != nil {
+					if lathos.IsNotFound(err) {
+						continue
+					}
+					return err
+				}
+				var tx transaction
+				if err := get(txn, key(prefixTx, t.TxID), &tx); err != nil {
+					return errors.Wrapf(err, "failed to get tx %s for txo", t.TxID)
+				}
+				u := payd.UTXODetail{
+					Outpoint:       t.Outpoint,
+					TxID:           t.TxID,
+					Vout:           uint32(t.Vout),
+					Satoshis:       t.Satoshis,
+					LockingScript:  d.LockingScript,
+					DerivationPath: d.DerivationPath,
+					KeyName:        d.KeyName,
+					TxState:        tx.State,
+					Confirmed:      len(ids(txn, prefix(prefixProof, t.TxID))) > 0,
+					ReservedFor:    t.ReservedFor,
+					ReservedUntil:  t.ReservedUntil,
+					Frozen:         t.Frozen,
+					SpentAt:        t.SpentAt,
+					SpendingTxID:   t.SpendingTxID,
+					CreatedAt:      t.CreatedAt,
+				}
+				if args.Matches(u) {
+					resp = append(resp, u)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get utxos")
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		less := resp[i].CreatedAt.Before(resp[j].CreatedAt) ||
+			resp[i].CreatedAt.Equal(resp[j].CreatedAt) && resp[i].Outpoint < resp[j].Outpoint
+		if args.Descending() {
+			return !less
+		}
+		return less
+	})
+	if args.Limit > 0 && len(resp) > args.Limit {
+		resp = resp[:args.Limit]
+	}
+	return resp, nil
+}
+
+// UTXOFrozenUpdate will freeze or unfreeze unspent txos, if any aren't found none are updated.
+func (s *badgerStore) UTXOFrozenUpdate(ctx context.Context, req payd.UTXOFrozenUpdate) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	timestamp := time.Now().UTC()
+	for _, outpoint := range req.Outpoints {
+		var t txo
+		if err := txnTxo(txn, outpoint, &t); err != nil {
+			if errors.Is(err, badgerdb.ErrKeyNotFound) {
+				return errs.NewErrNotFound(errcodes.ErrUTXONotFound, fmt.Sprintf("unspent txo %s not found", outpoint))
+			}
+			return err
+		}
+		status := t.status()
+		if status == txoSpent {
+			return errs.NewErrNotFound(errcodes.ErrUTXONotFound, fmt.Sprintf("unspent txo %s not found", outpoint))
+		}
+		t.Frozen = req.Frozen
+		t.UpdatedAt = timestamp
+		if err := txnTxoSave(txn, &t, status); err != nil {
+			return errors.Wrap(err, "failed to freeze utxos")
+		}
+	}
+	return errors.Wrap(commit(ctx, txn), "failed to commit transaction to freeze utxos")
+}
+
+// UTXOSpend spends txs matching the provided reservation.
+func (s *badgerStore) UTXOSpend(ctx context.Context, req payd.UTXOSpend) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	req.Timestamp = time.Now().UTC()
+	for _, outpoint := range ids(txn, prefix(idxTxoReservation, req.Reservation)) {
+		var t txo
+		if err := txnTxo(txn, outpoint, &t); err != nil {
+			return err
+		}
+		prev := t.status()
+		t.SpentAt = null.TimeFrom(req.Timestamp)
+		t.SpendingTxID = null.StringFrom(req.SpendingTxID)
+		t.UpdatedAt = req.Timestamp
+		if err := txnTxoSave(txn, &t, prev); err != nil {
+			return errors.Wrap(err, "failed to mark utxos as spent")
+		}
+	}
+
+	return errors.Wrap(commit(ctx, txn), "failed to commit transaction for spending utxo")
+}
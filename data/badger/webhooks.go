@@ -0,0 +1,342 @@
+package badger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	badgerdb "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// webhook is the stored representation of a webhook.
+type webhook struct {
+	ID        string              `json:"id"`
+	URL       string              `json:"url"`
+	Secret    string              `json:"secret"`
+	Events    []payd.WebhookEvent `json:"events"`
+	CreatedAt time.Time           `json:"createdAt"`
+	DeletedAt null.Time           `json:"deletedAt"`
+}
+
+func (w webhook) toWebhook() payd.Webhook {
+	return payd.Webhook{
+		ID:        w.ID,
+		URL:       w.URL,
+		Secret:    w.Secret,
+		Events:    w.Events,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+// webhookDelivery is the stored representation of a webhook delivery.
+type webhookDelivery struct {
+	ID            string                    `json:"id"`
+	WebhookID     string                    `json:"webhookId"`
+	Event         payd.WebhookEvent         `json:"event"`
+	Payload       json.RawMessage           `json:"payload"`
+	State         payd.WebhookDeliveryState `json:"state"`
+	Attempts      int                       `json:"attempts"`
+	NextAttemptAt time.Time                 `json:"nextAttemptAt"`
+	LastError     null.String               `json:"lastError"`
+	DeliveredAt   null.Time                 `json:"deliveredAt"`
+	CreatedAt     time.Time                 `json:"createdAt"`
+	UpdatedAt     time.Time                 `json:"updatedAt"`
+}
+
+func (d webhookDelivery) toWebhookDelivery() payd.WebhookDelivery {
+	return payd.WebhookDelivery{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		State:         d.State,
+		Attempts:      d.Attempts,
+		NextAttemptAt: d.NextAttemptAt,
+		LastError:     d.LastError,
+		DeliveredAt:   d.DeliveredAt,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+// Webhook will return a webhook matching args, deleted webhooks are not returned.
+func (s *badgerStore) Webhook(ctx context.Context, args payd.WebhookArgs) (*payd.Webhook, error) {
+	var wh webhook
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return txnWebhook(txn, args.WebhookID, &wh)
+	}); err != nil {
+		return nil, err
+	}
+	resp := wh.toWebhook()
+	return &resp, nil
+}
+
+// Webhooks will return all webhooks that haven't been deleted, oldest first.
+func (s *badgerStore) Webhooks(ctx context.Context) ([]payd.Webhook, error) {
+	var resp []payd.Webhook
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixWebhook), func() interface{} { return &webhook{} }, func(v interface{}) error {
+			if wh := v.(*webhook); !wh.DeletedAt.Valid {
+				resp = append(resp, wh.toWebhook())
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get webhooks")
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].CreatedAt.Equal(resp[j].CreatedAt) {
+			return resp[i].ID < resp[j].ID
+		}
+		return resp[i].CreatedAt.Before(resp[j].CreatedAt)
+	})
+	return resp, nil
+}
+
+// WebhookCreate will persist a new webhook along with the events it subscribes to.
+func (s *badgerStore) WebhookCreate(ctx context.Context, req payd.WebhookCreate) (*payd.Webhook, error) {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	k := key(prefixWebhook, req.WebhookID)
+	ok, err := exists(txn, k)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to check for webhook with webhookID %s", req.WebhookID)
+	}
+	if ok {
+		return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("webhook with webhookID %s already exists", req.WebhookID))
+	}
+	events := append([]payd.WebhookEvent{}, req.Events...)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i] < events[j]
+	})
+	for i := 1; i < len(events); i++ {
+		if events[i] == events[i-1] {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("webhook with webhookID %s has duplicate events", req.WebhookID))
+		}
+	}
+	wh := webhook{
+		ID:        req.WebhookID,
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Events:    events,
+		CreatedAt: req.CreatedAt,
+	}
+	if err := set(txn, k, wh); err != nil {
+		return nil, errors.Wrapf(err, "failed to insert webhook with webhookID %s", req.WebhookID)
+	}
+	if err := commit(ctx, txn); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating webhook with webhookID %s", req.WebhookID)
+	}
+	resp := wh.toWebhook()
+	return &resp, nil
+}
+
+// WebhookDelete will soft delete a webhook, its deliveries are kept.
+func (s *badgerStore) WebhookDelete(ctx context.Context, args payd.WebhookArgs) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var wh webhook
+	if err := txnWebhook(txn, args.WebhookID, &wh); err != nil {
+		return err
+	}
+	wh.DeletedAt = null.TimeFrom(time.Now().UTC())
+	if err := set(txn, key(prefixWebhook, wh.ID), wh); err != nil {
+		return errors.Wrapf(err, "failed to delete webhook with webhookID %s", args.WebhookID)
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when deleting webhook with webhookID %s", args.WebhookID)
+	}
+	return nil
+}
+
+// WebhookDelivery will return a delivery matching args.
+func (s *badgerStore) WebhookDelivery(ctx context.Context, args payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error) {
+	var d webhookDelivery
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return txnWebhookDelivery(txn, args.DeliveryID, &d)
+	}); err != nil {
+		return nil, err
+	}
+	resp := d.toWebhookDelivery()
+	return &resp, nil
+}
+
+// WebhookDeliveries returns the deliveries matching args, newest first.
+func (s *badgerStore) WebhookDeliveries(ctx context.Context, args payd.WebhookDeliveriesArgs) ([]payd.WebhookDelivery, error) {
+	var resp []payd.WebhookDelivery
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		return each(txn, prefix(prefixWebhookDelivery), func() interface{} { return &webhookDelivery{} }, func(v interface{}) error {
+			d := v.(*webhookDelivery)
+			if (args.WebhookID == "" || d.WebhookID == args.WebhookID) && (args.State == "" || d.State == args.State) {
+				resp = append(resp, d.toWebhookDelivery())
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook deliveries")
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].CreatedAt.Equal(resp[j].CreatedAt) {
+			return resp[i].ID > resp[j].ID
+		}
+		return resp[i].CreatedAt.After(resp[j].CreatedAt)
+	})
+	if args.Limit > 0 && len(resp) > args.Limit {
+		resp = resp[:args.Limit]
+	}
+	return resp, nil
+}
+
+// WebhookDeliveriesDue returns the pending deliveries due to be attempted, ordered by their next attempt.
+func (s *badgerStore) WebhookDeliveriesDue(ctx context.Context, args payd.WebhookDeliveriesDueArgs) ([]payd.WebhookDelivery, error) {
+	var resp []payd.WebhookDelivery
+	due := fmtID(uint64(args.DueBefore.UnixNano()))
+	if err := s.view(ctx, func(txn *badgerdb.Txn) error {
+		for _, entry := range ids(txn, prefix(idxWebhookDeliveryDue)) {
+			if args.Limit > 0 && len(resp) == args.Limit {
+				return nil
+			}
+			// entries are ordered by next attempt, everything after this one is due later.
+			if entry[:strings.Index(entry, keySep)] > due {
+				return nil
+			}
+			var d webhookDelivery
+			if err := txnWebhookDelivery(txn, entry[strings.LastIndex(entry, keySep)+1:], &d); err != nil {
+				return err
+			}
+			var wh webhook
+			if err := get(txn, key(prefixWebhook, d.WebhookID), &wh); err != nil {
+				return errors.Wrapf(err, "failed to get webhook with webhookID %s", d.WebhookID)
+			}
+			if wh.DeletedAt.Valid {
+				continue
+			}
+			resp = append(resp, d.toWebhookDelivery())
+		}
+		return nil
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to get due webhook deliveries")
+	}
+	return resp, nil
+}
+
+// WebhookDeliveriesCreate will add deliveries to the outbox in the pending state.
+func (s *badgerStore) WebhookDeliveriesCreate(ctx context.Context, req []payd.WebhookDeliveryCreate) error {
+	if len(req) == 0 {
+		// nothing to store
+		return nil
+	}
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	for _, r := range req {
+		ok, err := exists(txn, key(prefixWebhookDelivery, r.DeliveryID))
+		if err != nil {
+			return errors.Wrapf(err, "failed to check for webhook delivery %s", r.DeliveryID)
+		}
+		if ok {
+			return lathos.NewErrDuplicate("D001", "webhook delivery already exists")
+		}
+		if ok, err = exists(txn, key(prefixWebhook, r.WebhookID)); err != nil {
+			return errors.Wrapf(err, "failed to check for webhook with webhookID %s", r.WebhookID)
+		}
+		if !ok {
+			return lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", r.WebhookID))
+		}
+		if err := txnWebhookDeliverySave(txn, &webhookDelivery{
+			ID:            r.DeliveryID,
+			WebhookID:     r.WebhookID,
+			Event:         r.Event,
+			Payload:       r.Payload,
+			State:         payd.StateWebhookDeliveryPending,
+			NextAttemptAt: r.NextAttemptAt.UTC(),
+			CreatedAt:     r.CreatedAt.UTC(),
+			UpdatedAt:     r.CreatedAt.UTC(),
+		}, nil); err != nil {
+			return errors.Wrap(err, "failed to insert webhook deliveries")
+		}
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrap(err, "failed to commit transaction when creating webhook deliveries")
+	}
+	return nil
+}
+
+// WebhookDeliveryUpdate will update a delivery with the outcome of an attempt.
+func (s *badgerStore) WebhookDeliveryUpdate(ctx context.Context, args payd.WebhookDeliveryArgs, req payd.WebhookDeliveryUpdate) error {
+	txn := s.newTx(ctx)
+	defer rollback(ctx, txn)
+	var d webhookDelivery
+	if err := txnWebhookDelivery(txn, args.DeliveryID, &d); err != nil {
+		return err
+	}
+	prev := d
+	d.State = req.State
+	d.Attempts = req.Attempts
+	d.NextAttemptAt = req.NextAttemptAt.UTC()
+	d.LastError = req.LastError
+	d.DeliveredAt = req.DeliveredAt
+	d.UpdatedAt = req.UpdatedAt.UTC()
+	if err := txnWebhookDeliverySave(txn, &d, &prev); err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	if err := commit(ctx, txn); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when updating webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	return nil
+}
+
+// txnWebhook reads a webhook that hasn't been deleted into wh.
+func txnWebhook(txn *badgerdb.Txn, webhookID string, wh *webhook) error {
+	if err := get(txn, key(prefixWebhook, webhookID), wh); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", webhookID))
+		}
+		return errors.Wrapf(err, "failed to get webhook with webhookID %s", webhookID)
+	}
+	if wh.DeletedAt.Valid {
+		return lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", webhookID))
+	}
+	return nil
+}
+
+// txnWebhookDelivery reads a delivery into d.
+func txnWebhookDelivery(txn *badgerdb.Txn, deliveryID string, d *webhookDelivery) error {
+	if err := get(txn, key(prefixWebhookDelivery, deliveryID), d); err != nil {
+		if errors.Is(err, badgerdb.ErrKeyNotFound) {
+			return lathos.NewErrNotFound(errcodes.ErrWebhookDeliveryNotFound, fmt.Sprintf("webhook delivery with deliveryID %s not found", deliveryID))
+		}
+		return errors.Wrapf(err, "failed to get webhook delivery with deliveryID %s", deliveryID)
+	}
+	return nil
+}
+
+// webhookDeliveryDueKey returns the due index key for d, keys sort by next attempt then id.
+func webhookDeliveryDueKey(d *webhookDelivery) []byte {
+	return key(idxWebhookDeliveryDue, fmtID(uint64(d.NextAttemptAt.UnixNano())), d.ID)
+}
+
+// txnWebhookDeliverySave writes d, only pending deliveries are kept in the due index.
+// prev is nil for new deliveries.
+func txnWebhookDeliverySave(txn *badgerdb.Txn, d, prev *webhookDelivery) error {
+	if err := set(txn, key(prefixWebhookDelivery, d.ID), d); err != nil {
+		return err
+	}
+	if prev != nil && prev.State == payd.StateWebhookDeliveryPending {
+		if err := txn.Delete(webhookDeliveryDueKey(prev)); err != nil {
+			return errors.Wrap(err, "failed to remove webhook delivery due index")
+		}
+	}
+	if d.State != payd.StateWebhookDeliveryPending {
+		return nil
+	}
+	return errors.Wrap(index(txn, webhookDeliveryDueKey(d)), "failed to add webhook delivery due index")
+}
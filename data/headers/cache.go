@@ -0,0 +1,217 @@
+package headers
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/libsv/go-bc"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type cache struct {
+	source    payd.HeaderChain
+	depth     uint32
+	olderSize int
+
+	mu      sync.RWMutex
+	tip     *payd.ChainTip
+	headers map[string]*bc.BlockHeader
+	heights map[string]uint32
+	hashes  map[uint32]string
+	// older holds the blocks deeper than depth read from source, least recently used last.
+	older      *list.List
+	olderItems map[string]*list.Element
+}
+
+// olderBlock is a block deeper than depth, it is no longer expected to leave the best chain
+// so is kept until it is the least recently used. header is nil until it is read.
+type olderBlock struct {
+	hash   string
+	height uint32
+	header *bc.BlockHeader
+}
+
+// NewCache returns a payd.HeaderChain that keeps the headers of the last depth blocks of
+// the best chain locally, synced from source. Headers of older blocks are read from source
+// then kept, up to olderSize of them. Blocks added since the last sync are read from source.
+func NewCache(source payd.HeaderChain, depth uint32, olderSize int) *cache {
+	return &cache{
+		source:     source,
+		depth:      depth,
+		olderSize:  olderSize,
+		headers:    map[string]*bc.BlockHeader{},
+		heights:    map[string]uint32{},
+		hashes:     map[uint32]string{},
+		older:      list.New(),
+		olderItems: map[string]*list.Element{},
+	}
+}
+
+// BlockHeader returns the header for the provided blockhash, from the local headers if
+// it is one of them.
+func (c *cache) BlockHeader(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+	c.mu.RLock()
+	h, ok := c.headers[blockHash]
+	c.mu.RUnlock()
+	if !ok {
+		if h = c.olderLookup(blockHash).header; h == nil {
+			return c.headerFromSource(ctx, blockHash)
+		}
+	}
+	header := *h
+	return &header, nil
+}
+
+// BlockHeight returns the height of the block with the provided blockhash, from the local headers
+// if it is one of them.
+func (c *cache) BlockHeight(ctx context.Context, blockHash string) (uint32, error) {
+	c.mu.RLock()
+	height, ok := c.heights[blockHash]
+	c.mu.RUnlock()
+	if ok {
+		return height, nil
+	}
+	if b := c.olderLookup(blockHash); b.hash != "" {
+		return b.height, nil
+	}
+	height, err := c.source.BlockHeight(ctx, blockHash)
+	if err != nil {
+		return 0, err
+	}
+	c.keepOlder(olderBlock{hash: blockHash, height: height})
+	return height, nil
+}
+
+// headerFromSource reads a header from source, keeping it if the block is deeper than depth.
+func (c *cache) headerFromSource(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+	h, err := c.source.BlockHeader(ctx, blockHash)
+	if err != nil || c.olderSize == 0 {
+		return h, err
+	}
+	// the height tells if the block is deeper than depth, without it the header isn't kept.
+	if height, err := c.BlockHeight(ctx, blockHash); err == nil {
+		header := *h
+		c.keepOlder(olderBlock{hash: blockHash, height: height, header: &header})
+	}
+	return h, nil
+}
+
+// olderLookup returns the kept older block with the provided blockhash, it has an empty hash
+// if it isn't kept.
+func (c *cache) olderLookup(blockHash string) olderBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.olderItems[blockHash]
+	if !ok {
+		return olderBlock{}
+	}
+	c.older.MoveToFront(e)
+	return *e.Value.(*olderBlock)
+}
+
+// keepOlder keeps a block if it is deeper than depth as of the last sync, the least recently
+// used block is dropped once more than olderSize are kept.
+func (c *cache) keepOlder(b olderBlock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.olderSize == 0 || c.tip == nil || b.height+c.depth > c.tip.Height {
+		return
+	}
+	if e, ok := c.olderItems[b.hash]; ok {
+		if b.header == nil {
+			b.header = e.Value.(*olderBlock).header
+		}
+		e.Value = &b
+		c.older.MoveToFront(e)
+		return
+	}
+	c.olderItems[b.hash] = c.older.PushFront(&b)
+	if c.older.Len() > c.olderSize {
+		last := c.older.Remove(c.older.Back()).(*olderBlock)
+		delete(c.olderItems, last.hash)
+	}
+}
+
+// ChainTip returns the tip of the best chain as of the last sync, it is read from source
+// until the first sync.
+func (c *cache) ChainTip(ctx context.Context) (*payd.ChainTip, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.tip == nil {
+		return c.source.ChainTip(ctx)
+	}
+	tip := *c.tip
+	return &tip, nil
+}
+
+// HeadersSync reads the chain tip from source and stores the headers from the tip back to the
+// first header already stored at its height. After a reorg the headers of the orphaned blocks
+// are replaced, and headers deeper than depth are dropped.
+func (c *cache) HeadersSync(ctx context.Context) (int, error) {
+	tip, err := c.source.ChainTip(ctx)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get chain tip")
+	}
+	c.mu.RLock()
+	synced := c.tip != nil && c.tip.Hash == tip.Hash
+	c.mu.RUnlock()
+	if synced {
+		return 0, nil
+	}
+	var minHeight uint32
+	if tip.Height >= c.depth {
+		minHeight = tip.Height - c.depth + 1
+	}
+	headers := map[uint32]*bc.BlockHeader{}
+	hashes := map[uint32]string{}
+	hash := tip.Hash
+	for height := tip.Height; height >= minHeight; height-- {
+		c.mu.RLock()
+		stored := c.hashes[height] == hash
+		c.mu.RUnlock()
+		if stored {
+			break
+		}
+		// the tip may be orphaned while syncing, the next sync starts again from the new tip.
+		h, err := c.source.BlockHeader(ctx, hash)
+		if err != nil {
+			return 0, errors.WithMessagef(err, "failed to get header %s at height %d", hash, height)
+		}
+		headers[height] = h
+		hashes[height] = hash
+		hash = h.HashPrevBlockStr()
+		if height == 0 {
+			break
+		}
+	}
+	c.mu.Lock()
+	for height, hash := range hashes {
+		delete(c.headers, c.hashes[height])
+		delete(c.heights, c.hashes[height])
+		c.hashes[height] = hash
+		c.headers[hash] = headers[height]
+		c.heights[hash] = height
+	}
+	// a reorg can leave the best chain shorter than it was.
+	var deeper []olderBlock
+	for height, hash := range c.hashes {
+		if height > tip.Height || height < minHeight {
+			if height < minHeight {
+				deeper = append(deeper, olderBlock{hash: hash, height: height, header: c.headers[hash]})
+			}
+			delete(c.hashes, height)
+			delete(c.headers, hash)
+			delete(c.heights, hash)
+		}
+	}
+	c.tip = tip
+	c.mu.Unlock()
+	// headers now deeper than depth are kept with the older blocks.
+	for _, b := range deeper {
+		c.keepOlder(b)
+	}
+	return len(hashes), nil
+}
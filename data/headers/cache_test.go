@@ -0,0 +1,206 @@
+package headers_test
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data/headers"
+	"github.com/libsv/payd/mocks"
+)
+
+// chain is a fake best chain, blocks are identified by the fork they're on and their height.
+type chain struct {
+	headers map[string]*bc.BlockHeader
+	heights map[string]uint32
+	best    map[string]bool
+	tip     payd.ChainTip
+}
+
+func blockHash(fork, height int) string {
+	return fmt.Sprintf("%032x%032x", fork, height)
+}
+
+// extend adds the blocks of a fork from height to tip, it becomes the best chain.
+func (c *chain) extend(t *testing.T, fork, from, tip int) {
+	c.best = map[string]bool{}
+	prev := make([]byte, 32)
+	for height := 0; height <= tip; height++ {
+		f := fork
+		if height < from {
+			f = 0
+		}
+		hash := blockHash(f, height)
+		c.headers[hash] = &bc.BlockHeader{Version: 1, Time: uint32(height), HashPrevBlock: prev, HashMerkleRoot: make([]byte, 32)}
+		c.heights[hash] = uint32(height)
+		c.best[hash] = true
+		var err error
+		prev, err = hex.DecodeString(hash)
+		require.NoError(t, err)
+	}
+	c.tip = payd.ChainTip{Hash: blockHash(fork, tip), Height: uint32(tip)}
+}
+
+func (c *chain) source() *mocks.HeaderChainMock {
+	return &mocks.HeaderChainMock{
+		BlockHeaderFunc: func(ctx context.Context, hash string) (*bc.BlockHeader, error) {
+			h, ok := c.headers[hash]
+			if !ok {
+				return nil, bc.ErrHeaderNotFound
+			}
+			if !c.best[hash] {
+				return nil, bc.ErrNotOnLongestChain
+			}
+			header := *h
+			return &header, nil
+		},
+		BlockHeightFunc: func(ctx context.Context, hash string) (uint32, error) {
+			h, ok := c.heights[hash]
+			if !ok {
+				return 0, bc.ErrHeaderNotFound
+			}
+			if !c.best[hash] {
+				return 0, bc.ErrNotOnLongestChain
+			}
+			return h, nil
+		},
+		ChainTipFunc: func(context.Context) (*payd.ChainTip, error) {
+			tip := c.tip
+			return &tip, nil
+		},
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	c := &chain{headers: map[string]*bc.BlockHeader{}, heights: map[string]uint32{}}
+	c.extend(t, 0, 0, 10)
+	src := c.source()
+	cache := headers.NewCache(src, 5, 2)
+	// lookups counts the headers read from source by f.
+	lookups := func(f func()) int {
+		n := len(src.BlockHeaderCalls())
+		f()
+		return len(src.BlockHeaderCalls()) - n
+	}
+	header := func(fork, height int) error {
+		h, err := cache.BlockHeader(ctx, blockHash(fork, height))
+		if err == nil {
+			assert.Equal(t, c.headers[blockHash(fork, height)], h)
+		}
+		return err
+	}
+	sync := func(exp int) {
+		n, err := cache.HeadersSync(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, exp, n)
+	}
+
+	// headers and the tip are read from source until the first sync.
+	assert.Equal(t, 1, lookups(func() { assert.NoError(t, header(0, 8)) }))
+	tip, err := cache.ChainTip(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &c.tip, tip)
+	assert.Len(t, src.ChainTipCalls(), 1)
+
+	// the last 5 headers are synced and read locally, older headers are read from source once.
+	assert.Equal(t, 5, lookups(func() { sync(5) }))
+	assert.Zero(t, lookups(func() {
+		for height := 6; height <= 10; height++ {
+			assert.NoError(t, header(0, height))
+		}
+	}))
+	assert.Equal(t, 1, lookups(func() { assert.NoError(t, header(0, 5)) }))
+	assert.Zero(t, lookups(func() { assert.NoError(t, header(0, 5)) }))
+	assert.Zero(t, lookups(func() { sync(0) }))
+
+	// new blocks are synced back to the stored headers, those now deeper than 5 are kept with the
+	// older headers, dropping the least recently used once there are more than 2.
+	c.extend(t, 0, 0, 12)
+	assert.Equal(t, 2, lookups(func() { sync(2) }))
+	tip, err = cache.ChainTip(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &payd.ChainTip{Hash: blockHash(0, 12), Height: 12}, tip)
+	assert.Zero(t, lookups(func() {
+		assert.NoError(t, header(0, 8))
+		assert.NoError(t, header(0, 7))
+		assert.NoError(t, header(0, 6))
+	}))
+	assert.Equal(t, 1, lookups(func() { assert.NoError(t, header(0, 5)) }))
+
+	// a reorg replaces the orphaned headers, the orphaned blocks are then read from source.
+	c.extend(t, 1, 11, 13)
+	assert.Equal(t, 3, lookups(func() { sync(3) }))
+	assert.Zero(t, lookups(func() {
+		assert.NoError(t, header(0, 10))
+		assert.NoError(t, header(1, 11))
+		assert.NoError(t, header(1, 13))
+	}))
+	assert.Equal(t, 2, lookups(func() {
+		assert.ErrorIs(t, header(0, 11), bc.ErrNotOnLongestChain)
+		assert.ErrorIs(t, header(0, 12), bc.ErrNotOnLongestChain)
+	}))
+
+	// heights of the synced and older headers are read locally, others from source.
+	heights := len(src.BlockHeightCalls())
+	height, err := cache.BlockHeight(ctx, blockHash(1, 13))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(13), height)
+	height, err = cache.BlockHeight(ctx, blockHash(0, 5))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(5), height)
+	assert.Len(t, src.BlockHeightCalls(), heights)
+	_, err = cache.BlockHeight(ctx, blockHash(0, 12))
+	assert.ErrorIs(t, err, bc.ErrNotOnLongestChain)
+	assert.Len(t, src.BlockHeightCalls(), heights+1)
+
+	// a reorg to a shorter chain drops the headers above the new tip.
+	c.extend(t, 2, 11, 11)
+	assert.Equal(t, 1, lookups(func() { sync(1) }))
+	assert.Equal(t, 2, lookups(func() {
+		assert.ErrorIs(t, header(1, 12), bc.ErrNotOnLongestChain)
+		assert.ErrorIs(t, header(1, 13), bc.ErrNotOnLongestChain)
+	}))
+}
+
+func TestCache_HeadersSync(t *testing.T) {
+	tests := map[string]struct {
+		tipErr    error
+		headerErr error
+		expErr    error
+	}{
+		"chain tip error is reported": {
+			tipErr: errors.New("headers-sv is down"),
+			expErr: errors.New("failed to get chain tip: headers-sv is down"),
+		},
+		"header error is reported": {
+			headerErr: bc.ErrNotOnLongestChain,
+			expErr:    fmt.Errorf("failed to get header %s at height 10: %s", blockHash(0, 10), bc.ErrNotOnLongestChain),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache := headers.NewCache(&mocks.HeaderChainMock{
+				BlockHeaderFunc: func(context.Context, string) (*bc.BlockHeader, error) {
+					return nil, test.headerErr
+				},
+				ChainTipFunc: func(context.Context) (*payd.ChainTip, error) {
+					if test.tipErr != nil {
+						return nil, test.tipErr
+					}
+					return &payd.ChainTip{Hash: blockHash(0, 10), Height: 10}, nil
+				},
+			}, 5, 2)
+			n, err := cache.HeadersSync(context.Background())
+			assert.EqualError(t, err, test.expErr.Error())
+			assert.Zero(t, n)
+		})
+	}
+}
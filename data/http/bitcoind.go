@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data"
+	"github.com/pkg/errors"
+)
+
+// rpcErrInvalidAddressOrKey is the json-rpc error code a node returns for an unknown block.
+const rpcErrInvalidAddressOrKey = -5
+
+type bitcoindConnection struct {
+	client   data.Client
+	host     string
+	user     string
+	password string
+}
+
+// NewBitcoindConnection returns a payd.HeaderChain reading block headers over json-rpc from a
+// bitcoind compatible node.
+func NewBitcoindConnection(client data.Client, host, user, password string) *bitcoindConnection {
+	return &bitcoindConnection{
+		client:   client,
+		host:     host,
+		user:     user,
+		password: password,
+	}
+}
+
+// blockHeaderVerbose is the json description of a block header returned by getblockheader.
+type blockHeaderVerbose struct {
+	// Confirmations is -1 for a block that isn't on the best chain.
+	Confirmations int64  `json:"confirmations"`
+	Height        uint32 `json:"height"`
+}
+
+// rpcError is the error a node returns when a json-rpc call fails.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// BlockHeader returns the header for the provided blockhash. bc.ErrHeaderNotFound is returned if
+// the node doesn't know the block and bc.ErrNotOnLongestChain if it isn't on the best chain.
+func (b *bitcoindConnection) BlockHeader(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+	if _, err := b.blockHeaderVerbose(ctx, blockHash); err != nil {
+		return nil, err
+	}
+	var header string
+	if err := b.call(ctx, "getblockheader", &header, blockHash, false); err != nil {
+		return nil, err
+	}
+	bh, err := bc.NewBlockHeaderFromStr(header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse header %s", blockHash)
+	}
+	return bh, nil
+}
+
+// BlockHeight returns the height of the block with the provided blockhash. bc.ErrHeaderNotFound is returned
+// if the node doesn't know the block and bc.ErrNotOnLongestChain if it isn't on the best chain.
+func (b *bitcoindConnection) BlockHeight(ctx context.Context, blockHash string) (uint32, error) {
+	verbose, err := b.blockHeaderVerbose(ctx, blockHash)
+	if err != nil {
+		return 0, err
+	}
+	return verbose.Height, nil
+}
+
+// blockHeaderVerbose returns the node's json description of a block header, checking the block
+// is on the best chain.
+func (b *bitcoindConnection) blockHeaderVerbose(ctx context.Context, blockHash string) (*blockHeaderVerbose, error) {
+	var verbose blockHeaderVerbose
+	if err := b.call(ctx, "getblockheader", &verbose, blockHash, true); err != nil {
+		var rErr *rpcError
+		if errors.As(err, &rErr) && rErr.Code == rpcErrInvalidAddressOrKey {
+			return nil, bc.ErrHeaderNotFound
+		}
+		return nil, err
+	}
+	if verbose.Confirmations < 0 {
+		return nil, bc.ErrNotOnLongestChain
+	}
+	return &verbose, nil
+}
+
+// ChainTip returns the tip of the best chain known to the node.
+func (b *bitcoindConnection) ChainTip(ctx context.Context) (*payd.ChainTip, error) {
+	var info struct {
+		Blocks        uint32 `json:"blocks"`
+		BestBlockHash string `json:"bestblockhash"`
+	}
+	if err := b.call(ctx, "getblockchaininfo", &info); err != nil {
+		return nil, err
+	}
+	return &payd.ChainTip{Hash: info.BestBlockHash, Height: info.Blocks}, nil
+}
+
+// call makes a json-rpc call to the node, decoding the result into v.
+func (b *bitcoindConnection) call(ctx context.Context, method string, v interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"id":      "payd",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode %s request", method)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.host, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "error creating request for %s", method)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.SetBasicAuth(b.user, b.password)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s response body", method)
+	}
+	// the node replies to failed calls with an error status and the error in the body.
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("%s request: unexpected status code %d\nresponse body:\n%s", method, resp.StatusCode, respBody)
+	}
+	if rpcResp.Error != nil {
+		return errors.Wrapf(rpcResp.Error, "%s request failed", method)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request: unexpected status code %d\nresponse body:\n%s", method, resp.StatusCode, respBody)
+	}
+	return errors.Wrapf(json.Unmarshal(rpcResp.Result, v), "failed to decode %s result", method)
+}
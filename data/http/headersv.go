@@ -2,11 +2,13 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/libsv/go-bc"
+	"github.com/libsv/payd"
 	"github.com/libsv/payd/data"
 	"github.com/pkg/errors"
 )
@@ -16,16 +18,23 @@ type hsvConnection struct {
 	host   string
 }
 
-// NewHeaderSVConnection returns a bc.BlockHeaderChain using a header client.
-func NewHeaderSVConnection(client data.Client, host string) bc.BlockHeaderChain {
+// headerStateLongestChain is the headers-sv state of a header on the best chain.
+const headerStateLongestChain = "LONGEST_CHAIN"
+
+// NewHeaderSVConnection returns a payd.HeaderChain reading block headers from headers-sv.
+func NewHeaderSVConnection(client data.Client, host string) *hsvConnection {
 	return &hsvConnection{
 		client: client,
 		host:   host,
 	}
 }
 
-// BlockHeader returns the header for the provided blockhash.
+// BlockHeader returns the header for the provided blockhash. bc.ErrHeaderNotFound is returned if
+// headers-sv doesn't know the block and bc.ErrNotOnLongestChain if it isn't on the best chain.
 func (h *hsvConnection) BlockHeader(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+	if _, err := h.headerState(ctx, blockHash); err != nil {
+		return nil, err
+	}
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodGet,
@@ -44,6 +53,9 @@ func (h *hsvConnection) BlockHeader(ctx context.Context, blockHash string) (*bc.
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, bc.ErrHeaderNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
@@ -63,3 +75,96 @@ func (h *hsvConnection) BlockHeader(ctx context.Context, blockHash string) (*bc.
 	}
 	return bh, nil
 }
+
+// BlockHeight returns the height of the block with the provided blockhash. bc.ErrHeaderNotFound is
+// returned if headers-sv doesn't know the block and bc.ErrNotOnLongestChain if it isn't on the best chain.
+func (h *hsvConnection) BlockHeight(ctx context.Context, blockHash string) (uint32, error) {
+	return h.headerState(ctx, blockHash)
+}
+
+// headerState checks the block is on the best chain, returning its height.
+func (h *hsvConnection) headerState(ctx context.Context, blockHash string) (uint32, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/api/v1/chain/header/state/%s", h.host, blockHash),
+		nil,
+	)
+	if err != nil {
+		return 0, errors.Wrapf(err, "error creating request for chain/header/state/%s", blockHash)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, bc.ErrHeaderNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse error message body")
+		}
+
+		return 0, fmt.Errorf("block header state request: unexpected status code %d\nresponse body:\n%s", resp.StatusCode, body)
+	}
+
+	var state struct {
+		State  string `json:"state"`
+		Height uint32 `json:"height"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return 0, errors.Wrapf(err, "failed to decode state of header %s", blockHash)
+	}
+	if state.State != headerStateLongestChain {
+		return 0, bc.ErrNotOnLongestChain
+	}
+	return state.Height, nil
+}
+
+// ChainTip returns the tip of the best chain known to headers-sv.
+func (h *hsvConnection) ChainTip(ctx context.Context) (*payd.ChainTip, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/v1/chain/tips", h.host), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating request for chain/tips")
+	}
+	req.Header.Add("Content-Type", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse error message body")
+		}
+
+		return nil, fmt.Errorf("chain tips request: unexpected status code %d\nresponse body:\n%s", resp.StatusCode, body)
+	}
+
+	var tips []struct {
+		State  string `json:"state"`
+		Height uint32 `json:"height"`
+		Header struct {
+			Hash string `json:"hash"`
+		} `json:"header"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tips); err != nil {
+		return nil, errors.Wrap(err, "failed to decode chain tips")
+	}
+	for _, t := range tips {
+		if t.State == headerStateLongestChain {
+			return &payd.ChainTip{Hash: t.Header.Hash, Height: t.Height}, nil
+		}
+	}
+	return nil, errors.New("no chain tip is on the longest chain")
+}
@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data"
+	"github.com/libsv/payd/errcodes"
+)
+
+// BrfcP2PPaymentDestination is the brfc id of the paymail capability used to
+// request outputs to pay a paymail.
+// https://docs.moneybutton.com/docs/paymail/paymail-07-p2p-payment-destination.html
+const BrfcP2PPaymentDestination = "2a40af698840"
+
+// BrfcP2PReceiveTransaction is the brfc id of the paymail capability used to send
+// a transaction paying a p2p payment destination to the paymail.
+// https://docs.moneybutton.com/docs/paymail/paymail-06-p2p-transactions.html
+const BrfcP2PReceiveTransaction = "5f1323cddf31"
+
+type paymail struct {
+	client data.Client
+}
+
+// NewPaymail returns a paymail client, used to find where a paymail wants to be paid.
+func NewPaymail(client data.Client) payd.PaymailReaderWriter {
+	return &paymail{client: client}
+}
+
+// Capability returns the endpoint the paymail host serves for a capability, read
+// from the host's bsvalias capability discovery document.
+// https://bsvalias.org/02-02-capability-discovery.html
+func (p *paymail) Capability(ctx context.Context, args payd.P2PCapabilityArgs) (string, error) {
+	var resp struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	if err := p.do(ctx, http.MethodGet, "https://"+args.Domain+"/.well-known/bsvalias", nil, &resp); err != nil {
+		return "", errors.WithMessagef(err, "failed to discover capabilities for paymail host %s", args.Domain)
+	}
+	// capabilities that aren't endpoints are flags, so only strings are returned.
+	endpoint, ok := resp.Capabilities[args.BrfcID].(string)
+	if !ok {
+		return "", lathos.NewErrNotFound(errcodes.ErrPaymailCapabilityNotFound,
+			fmt.Sprintf("paymail host %s does not support capability %s", args.Domain, args.BrfcID))
+	}
+	return endpoint, nil
+}
+
+// OutputsCreate requests the outputs to use when paying the paymail alias@domain, along with the
+// reference to send with the tx paying them.
+func (p *paymail) OutputsCreate(ctx context.Context, args payd.P2POutputCreateArgs, req payd.P2PPayment) (*payd.P2PPaymentDestination, error) {
+	endpoint, err := p.endpoint(ctx, args.Alias, args.Domain, BrfcP2PPaymentDestination)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Outputs []struct {
+			Script   string `json:"script"`
+			Satoshis uint64 `json:"satoshis"`
+		} `json:"outputs"`
+		Reference string `json:"reference"`
+	}
+	if err := p.do(ctx, http.MethodPost, endpoint, req, &resp); err != nil {
+		return nil, errors.WithMessagef(err, "failed to request outputs for paymail %s@%s", args.Alias, args.Domain)
+	}
+	oo := make([]*bt.Output, 0, len(resp.Outputs))
+	for _, o := range resp.Outputs {
+		s, err := bscript.NewFromHexString(o.Script)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid output script %s returned for paymail %s@%s", o.Script, args.Alias, args.Domain)
+		}
+		oo = append(oo, &bt.Output{LockingScript: s, Satoshis: o.Satoshis})
+	}
+	return &payd.P2PPaymentDestination{Outputs: oo, Reference: resp.Reference}, nil
+}
+
+// TransactionSubmit sends a tx paying the outputs returned by OutputsCreate to the paymail
+// alias@domain, args.PaymentID is the reference returned with the outputs.
+func (p *paymail) TransactionSubmit(ctx context.Context, args payd.P2PTransactionArgs, req payd.P2PTransaction) error {
+	endpoint, err := p.endpoint(ctx, args.Alias, args.Domain, BrfcP2PReceiveTransaction)
+	if err != nil {
+		return err
+	}
+	body := struct {
+		Hex      string `json:"hex"`
+		Metadata struct {
+			Sender    string `json:"sender,omitempty"`
+			PubKey    string `json:"pubkey,omitempty"`
+			Signature string `json:"signature,omitempty"`
+			Note      string `json:"note,omitempty"`
+		} `json:"metadata"`
+		Reference string `json:"reference"`
+	}{Hex: req.TxHex, Reference: args.PaymentID}
+	body.Metadata.Sender = req.Metadata.Sender
+	body.Metadata.PubKey = req.Metadata.PubKey
+	body.Metadata.Signature = req.Metadata.Signature
+	body.Metadata.Note = req.Metadata.Note
+	var resp struct {
+		TxID string `json:"txid"`
+	}
+	return errors.WithMessagef(p.do(ctx, http.MethodPost, endpoint, body, &resp),
+		"failed to send tx to paymail %s@%s", args.Alias, args.Domain)
+}
+
+// endpoint returns the endpoint of a capability for the paymail alias@domain.
+func (p *paymail) endpoint(ctx context.Context, alias, domain, brfcID string) (string, error) {
+	endpoint, err := p.Capability(ctx, payd.P2PCapabilityArgs{
+		Domain: domain,
+		BrfcID: brfcID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer("{alias}", alias, "{domain.tld}", domain).Replace(endpoint), nil
+}
+
+// do sends body, if set, as json and decodes the json response into out.
+func (p *paymail) do(ctx context.Context, method, url string, body, out interface{}) error {
+	var bb []byte
+	if body != nil {
+		var err error
+		if bb, err = json.Marshal(body); err != nil {
+			return errors.Wrap(err, "failed to encode request body")
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(bb))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", url)
+	}
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send request to %s", url)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		msg, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse error message body")
+		}
+		return fmt.Errorf("unexpected status code %d from %s\nresponse body:\n%s", resp.StatusCode, url, msg)
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(out), "failed to decode response from %s", url)
+}
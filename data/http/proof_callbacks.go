@@ -0,0 +1,53 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data"
+)
+
+type proofCallbacks struct {
+	client data.Client
+}
+
+// NewProofCallbacks returns a client used to send merkle proofs to payer proof callbacks.
+func NewProofCallbacks(client data.Client) payd.ProofCallbackSender {
+	return &proofCallbacks{client: client}
+}
+
+// ProofCallbackSend posts the merkle proof envelope, as received from mAPI, to the callback
+// url. If the payer supplied a token it is sent as a bearer token in the Authorization header.
+func (p *proofCallbacks) ProofCallbackSend(ctx context.Context, callback payd.ProofCallback) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callback.URL, bytes.NewReader(callback.Proof))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", callback.URL)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if callback.Token != "" {
+		req.Header.Add("Authorization", "Bearer "+callback.Token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send request to %s", callback.URL)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		// only the start of the body is kept, it is stored as the callback error.
+		msg, err := ioutil.ReadAll(io.LimitReader(resp.Body, 256))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse error message body")
+		}
+		return fmt.Errorf("unexpected status code %d from %s, response body: %s", resp.StatusCode, callback.URL, msg)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data"
+)
+
+// Headers sent with each webhook delivery.
+const (
+	HeaderWebhookEvent     = "X-Payd-Event"
+	HeaderWebhookDelivery  = "X-Payd-Delivery"
+	HeaderWebhookSignature = "X-Payd-Signature"
+)
+
+type webhooks struct {
+	client data.Client
+}
+
+// NewWebhooks returns a client used to post events to merchant webhooks.
+func NewWebhooks(client data.Client) payd.WebhookSender {
+	return &webhooks{client: client}
+}
+
+// WebhookSend posts msg as json to the webhook url. The body is signed with a HMAC-SHA256
+// keyed with the webhook secret, sent hex encoded as "sha256=<signature>" in the
+// X-Payd-Signature header, so receivers can check the message came from us.
+func (w *webhooks) WebhookSend(ctx context.Context, args payd.WebhookSendArgs, msg payd.WebhookMessage) error {
+	bb, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode webhook message")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, args.URL, bytes.NewReader(bb))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create request for %s", args.URL)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(HeaderWebhookEvent, msg.Event.String())
+	req.Header.Add(HeaderWebhookDelivery, msg.ID)
+	req.Header.Add(HeaderWebhookSignature, "sha256="+WebhookSignature(args.Secret, bb))
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send request to %s", args.URL)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		// only the start of the body is kept, it is stored as the delivery error.
+		msg, err := ioutil.ReadAll(io.LimitReader(resp.Body, 256))
+		if err != nil {
+			return errors.Wrap(err, "failed to parse error message body")
+		}
+		return fmt.Errorf("unexpected status code %d from %s, response body: %s", resp.StatusCode, args.URL, msg)
+	}
+	return nil
+}
+
+// WebhookSignature returns the hex encoded HMAC-SHA256 of body keyed with secret.
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
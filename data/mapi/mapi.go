@@ -2,8 +2,11 @@ package mapi
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"time"
 
+	"github.com/libsv/go-bc"
 	"github.com/libsv/go-bt/v2"
 	"github.com/pkg/errors"
 	"github.com/tonicpow/go-minercraft"
@@ -13,6 +16,13 @@ import (
 	"github.com/libsv/payd/log"
 )
 
+// mapi result descriptions, these are the messages of the node errors.
+const (
+	resultTxUnknown       = "No such mempool or blockchain transaction"
+	resultMempoolConflict = "txn-mempool-conflict"
+	resultDoubleSpend     = "txn-double-spend-detected"
+)
+
 type minercraftMapi struct {
 	client *minercraft.Client
 	cfg    *config.MApi
@@ -55,9 +65,44 @@ func (m *minercraftMapi) Broadcast(ctx context.Context, args payd.BroadcastArgs,
 		return nil
 	}
 	m.l.Debugf("failed to submit transaction with hex: %s", tx.String())
+	if len(resp.Results.ConflictedWith) > 0 ||
+		strings.Contains(resp.Results.ResultDescription, resultMempoolConflict) ||
+		strings.Contains(resp.Results.ResultDescription, resultDoubleSpend) {
+		return errors.Wrapf(payd.ErrTxDoubleSpent, "failed to submit transaction %s", resp.Results.ResultDescription)
+	}
 	return errors.Errorf("failed to submit transaction %s", resp.Results.ResultDescription)
 }
 
+// BroadcastStatus will query mapi for the status of a transaction, the merkle proof of the
+// transaction is returned once it is mined.
+func (m *minercraftMapi) BroadcastStatus(ctx context.Context, txID string) (*payd.BroadcastStatus, error) {
+	// minercraft doesn't send the merkle proof query params or parse the proof, so the params
+	// are added to the txid and the proof is read from the raw payload.
+	resp, err := m.client.QueryTransaction(ctx,
+		m.client.MinerByName(m.cfg.MinerName),
+		txID+"?merkleProof=true&merkleFormat="+minercraft.MerkleFormatTSC)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query status of transaction %s", txID)
+	}
+	var payload struct {
+		ResultDescription string          `json:"resultDescription"`
+		BlockHash         string          `json:"blockHash"`
+		BlockHeight       uint32          `json:"blockHeight"`
+		MerkleProof       *bc.MerkleProof `json:"merkleProof"`
+	}
+	if err := json.Unmarshal([]byte(resp.Payload), &payload); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse status of transaction %s", txID)
+	}
+	return &payd.BroadcastStatus{
+		TxID:        txID,
+		Known:       !strings.HasPrefix(payload.ResultDescription, resultTxUnknown),
+		Description: payload.ResultDescription,
+		BlockHash:   payload.BlockHash,
+		BlockHeight: payload.BlockHeight,
+		Proof:       payload.MerkleProof,
+	}, nil
+}
+
 // FeeQuote will return the current fees for the configured miner. If the fee has not
 // expired we will return the current memoized fee quote.
 func (m *minercraftMapi) FeeQuote(ctx context.Context) (*bt.FeeQuote, error) {
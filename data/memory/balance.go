@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/libsv/payd"
+)
+
+// Balance will return the current account balance, totalling the unspent txos by the
+// state of their tx and whether they are reserved or frozen along with the heights of
+// the blocks their txs were mined in.
+func (s *memoryStore) Balance(ctx context.Context, args payd.BalanceArgs) (*payd.Balance, error) {
+	st := s.view(ctx)
+	var resp payd.Balance
+	for _, t := range st.txos {
+		if t.spent() {
+			continue
+		}
+		// as with the sql stores, txos not paid to one of our destinations aren't counted.
+		d, ok := st.destinations[t.DestinationID]
+		if !ok || !args.Matches(d.UserID, d.KeyName) {
+			continue
+		}
+		tx := st.transactions[t.TxID]
+		resp.Add(payd.BalanceTotal{Satoshis: t.Satoshis, UTXOs: 1, LowBlockHeight: tx.BlockHeight, HighBlockHeight: tx.BlockHeight},
+			tx.State, t.ReservedFor.Valid, t.Frozen)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/libsv/payd"
+)
+
+// DerivationPathExists will return true / false if the supplied derivation path exists or not.
+func (s *memoryStore) DerivationPathExists(ctx context.Context, args payd.DerivationExistsArgs) (bool, error) {
+	for _, d := range s.view(ctx).destinations {
+		if d.UserID == args.UserID && d.KeyName == args.KeyName && d.DerivationPath == args.Path {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DerivationPaths returns every path used to derive a destination from the key.
+func (s *memoryStore) DerivationPaths(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+	resp := []payd.DerivationPath{}
+	for _, d := range s.view(ctx).destinations {
+		if d.UserID == args.UserID && d.KeyName == args.Name {
+			resp = append(resp, payd.DerivationPath{Path: d.DerivationPath, LockingScript: d.LockingScript})
+		}
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].Path < resp[j].Path
+	})
+	return resp, nil
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// destination is the stored representation of a payment destination.
+type destination struct {
+	ID             uint64
+	UserID         uint64
+	KeyName        string
+	LockingScript  string
+	DerivationPath string
+	Satoshis       uint64
+	State          string
+	InvoiceID      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (d destination) toOutput() (payd.Output, error) {
+	s, err := bscript.NewFromHexString(d.LockingScript)
+	if err != nil {
+		return payd.Output{}, errors.Wrapf(err, "failed to parse locking script for destination %d", d.ID)
+	}
+	return payd.Output{
+		ID:             d.ID,
+		LockingScript:  s,
+		Satoshis:       d.Satoshis,
+		DerivationPath: d.DerivationPath,
+		State:          d.State,
+	}, nil
+}
+
+// DestinationsCreate will store the destinations, linking them to the invoice if provided.
+func (s *memoryStore) DestinationsCreate(ctx context.Context, args payd.DestinationsCreateArgs, req []payd.DestinationCreate) ([]payd.Output, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create payment destinations")
+	}
+	defer rollback(ctx, tx)
+	invoiceID := args.InvoiceID.ValueOrZero()
+	if !args.InvoiceID.IsZero() {
+		if _, err := tx.st.invoice(invoiceID); err != nil {
+			return nil, errors.WithMessagef(err, "failed to add destinations for invoiceID '%s'", invoiceID)
+		}
+	}
+	now := time.Now().UTC()
+	dd := make([]payd.Output, 0, len(req))
+	for _, r := range req {
+		for _, d := range tx.st.destinations {
+			if d.LockingScript == r.Script {
+				return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("destination with script %s already exists", r.Script))
+			}
+		}
+		tx.st.destinationSeq++
+		d := destination{
+			ID:             tx.st.destinationSeq,
+			UserID:         r.UserID,
+			KeyName:        r.KeyName,
+			LockingScript:  r.Script,
+			DerivationPath: r.DerivationPath,
+			Satoshis:       r.Satoshis,
+			State:          "pending",
+			InvoiceID:      invoiceID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		o, err := d.toOutput()
+		if err != nil {
+			return nil, err
+		}
+		tx.st.destinations[d.ID] = d
+		dd = append(dd, o)
+	}
+	return dd, errors.Wrapf(commit(ctx, tx), "failed to commit transaction when creating payment destinations")
+}
+
+// Destinations will return a set of destination outputs for a specific invoiceID.
+func (s *memoryStore) Destinations(ctx context.Context, args payd.DestinationsArgs) ([]payd.Output, error) {
+	st := s.view(ctx)
+	received := map[uint64]uint64{}
+	for _, t := range st.txos {
+		received[t.DestinationID] += t.Satoshis
+	}
+	var outs []payd.Output
+	for _, d := range st.destinations {
+		if d.InvoiceID == "" || d.InvoiceID != args.InvoiceID {
+			continue
+		}
+		o, err := d.toOutput()
+		if err != nil {
+			return nil, err
+		}
+		o.SatoshisReceived = received[d.ID]
+		outs = append(outs, o)
+	}
+	if len(outs) == 0 {
+		return nil, lathos.NewErrNotFound(errcodes.ErrDestinationsNotFound, fmt.Sprintf("destinations with invoiceID %s not found", args.InvoiceID))
+	}
+	sort.Slice(outs, func(i, j int) bool { return outs[i].ID < outs[j].ID })
+	return outs, nil
+}
+
+// destination returns the destination with destID, or a not found error if it doesn't exist.
+func (s *state) destination(destID uint64) (destination, error) {
+	d, ok := s.destinations[destID]
+	if !ok {
+		return destination{}, lathos.NewErrNotFound(errcodes.ErrDestinationsNotFound, fmt.Sprintf("destination %d not found", destID))
+	}
+	return d, nil
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// feeQuote is the stored representation of the fees offered for an invoice, the
+// quote is stored encoded so callers can't modify it after it is stored.
+type feeQuote struct {
+	FeeQuote  []byte
+	ExpiresAt time.Time
+}
+
+// FeeQuoteCreate will store the fee quote for an invoice, replacing any existing quote.
+func (s *memoryStore) FeeQuoteCreate(ctx context.Context, args *payd.FeeQuoteCreateArgs) error {
+	bb, err := json.Marshal(args.FeeQuote)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode fee quote")
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert fees")
+	}
+	defer rollback(ctx, tx)
+	tx.st.feeQuotes[args.InvoiceID] = feeQuote{
+		FeeQuote:  bb,
+		ExpiresAt: args.FeeQuote.Expiry(),
+	}
+	return errors.Wrapf(commit(ctx, tx), "failed to commit transaction when inserting fee rate for invoice '%s'", args.InvoiceID)
+}
+
+// FeeQuote will return the fee quote stored for an invoice.
+func (s *memoryStore) FeeQuote(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+	fq, ok := s.view(ctx).feeQuotes[invoiceID]
+	if !ok {
+		return nil, lathos.NewErrNotFoundf("N0002", "cannot find fee quote for invoiceID %s", invoiceID)
+	}
+	resp := bt.NewFeeQuote()
+	if err := json.Unmarshal(fq.FeeQuote, resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode fee quote for invoiceID %s", invoiceID)
+	}
+	resp.UpdateExpiry(fq.ExpiresAt)
+	return resp, nil
+}
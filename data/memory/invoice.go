@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// Invoice will return an invoice that matches the provided args.
+func (s *memoryStore) Invoice(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+	inv, err := s.view(ctx).invoice(args.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Invoices will return the invoices matching args, deleted invoices are never returned.
+func (s *memoryStore) Invoices(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
+	ii := s.view(ctx).invoicesWhere(func(inv payd.Invoice) bool {
+		return inv.State != payd.StateInvoiceDeleted && args.Matches(inv)
+	})
+	if args.Descending() {
+		for l, r := 0, len(ii)-1; l < r; l, r = l+1, r-1 {
+			ii[l], ii[r] = ii[r], ii[l]
+		}
+	}
+	if args.Limit > 0 && len(ii) > args.Limit {
+		ii = ii[:args.Limit]
+	}
+	return ii, nil
+}
+
+// InvoicesPending will return any invoices that are pending or partially paid.
+func (s *memoryStore) InvoicesPending(ctx context.Context) ([]payd.Invoice, error) {
+	return s.view(ctx).invoicesWhere(func(inv payd.Invoice) bool {
+		return inv.State == payd.StateInvoicePending || inv.State == payd.StateInvoicePartiallyPaid
+	}), nil
+}
+
+// InvoicePayments will return the payments made against an invoice, oldest first.
+func (s *memoryStore) InvoicePayments(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoicePayment, error) {
+	pp := s.view(ctx).payments[args.InvoiceID]
+	if len(pp) == 0 {
+		return nil, nil
+	}
+	return append([]payd.InvoicePayment{}, pp...), nil
+}
+
+// InvoiceRefunds will return the refunds paid against an invoice, oldest first.
+func (s *memoryStore) InvoiceRefunds(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoiceRefund, error) {
+	rr := s.view(ctx).refunds[args.InvoiceID]
+	if len(rr) == 0 {
+		return nil, nil
+	}
+	return append([]payd.InvoiceRefund{}, rr...), nil
+}
+
+// InvoiceCreate will persist a new Invoice in the data store.
+func (s *memoryStore) InvoiceCreate(ctx context.Context, req payd.InvoiceCreate) (*payd.Invoice, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new invoice with invoiceID %s", req.InvoiceID)
+	}
+	defer rollback(ctx, tx)
+	if _, ok := tx.st.invoices[req.InvoiceID]; ok {
+		return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("invoice with invoiceID %s already exists", req.InvoiceID))
+	}
+	inv := payd.Invoice{
+		ID:          req.InvoiceID,
+		Reference:   req.Reference,
+		Description: req.Description,
+		Satoshis:    req.Satoshis,
+		ExpiresAt:   req.ExpiresAt,
+		State:       payd.StateInvoicePending,
+		SPVRequired: req.SPVRequired,
+		MetaData: payd.MetaData{
+			CreatedAt: req.CreatedAt,
+			UpdatedAt: time.Now().UTC(),
+		},
+	}
+	tx.st.invoices[inv.ID] = inv
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating invoice with invoiceID %s", req.InvoiceID)
+	}
+	return &inv, nil
+}
+
+// InvoiceUpdate will record a payment against an invoice, marking it paid once the
+// satoshis received cover it or partially paid if not, and return the result.
+// Only pending or partially paid invoices are updated, any other invoice is returned unchanged.
+func (s *memoryStore) InvoiceUpdate(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update invoice with invoiceID %s", args.InvoiceID)
+	}
+	defer rollback(ctx, tx)
+	inv, err := tx.st.invoice(args.InvoiceID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to update invoice")
+	}
+	if inv.State == payd.StateInvoicePending || inv.State == payd.StateInvoicePartiallyPaid {
+		pp := tx.st.payments[inv.ID]
+		for _, p := range pp {
+			if p.TxID == req.TxID {
+				return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("payment for tx %s has already been recorded against invoiceID %s", req.TxID, args.InvoiceID))
+			}
+		}
+		now := time.Now().UTC()
+		refundTo := null.NewString(req.RefundTo, req.RefundTo != "")
+		// copy the payments so the committed state isn't modified.
+		tx.st.payments[inv.ID] = append(append(make([]payd.InvoicePayment, 0, len(pp)+1), pp...), payd.InvoicePayment{
+			InvoiceID: inv.ID,
+			TxID:      req.TxID,
+			Satoshis:  req.Satoshis,
+			RefundTo:  refundTo,
+			CreatedAt: now,
+		})
+		inv.SatoshisReceived += req.Satoshis
+		inv.State = payd.StateInvoicePartiallyPaid
+		if inv.SatoshisReceived >= inv.Satoshis {
+			inv.State = payd.StateInvoicePaid
+			inv.PaymentReceivedAt = null.TimeFrom(now)
+		}
+		if refundTo.Valid {
+			inv.RefundTo = refundTo
+		}
+		inv.UpdatedAt = now
+		tx.st.invoices[inv.ID] = inv
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when updating invoice with invoiceID %s", args.InvoiceID)
+	}
+	return &inv, nil
+}
+
+// InvoiceRefund will record a refund against an invoice, marking it refunded once the
+// refunds cover the satoshis received, and return the result.
+// Only paid, partially paid or expired invoices are updated, and only when the refunds don't exceed the
+// satoshis received, otherwise the invoice is returned unchanged.
+func (s *memoryStore) InvoiceRefund(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to refund invoice with invoiceID %s", args.InvoiceID)
+	}
+	defer rollback(ctx, tx)
+	inv, err := tx.st.invoice(args.InvoiceID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to refund invoice")
+	}
+	refundable := inv.State == payd.StateInvoicePaid || inv.State == payd.StateInvoicePartiallyPaid || inv.State == payd.StateInvoiceExpired
+	if refundable && inv.SatoshisRefunded+req.Satoshis <= inv.SatoshisReceived {
+		rr := tx.st.refunds[inv.ID]
+		for _, r := range rr {
+			if r.TxID == req.TxID {
+				return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("refund for tx %s has already been recorded against invoiceID %s", req.TxID, args.InvoiceID))
+			}
+		}
+		if !req.RefundedAt.Valid {
+			req.RefundedAt = null.TimeFrom(time.Now().UTC())
+		}
+		// copy the refunds so the committed state isn't modified.
+		tx.st.refunds[inv.ID] = append(append(make([]payd.InvoiceRefund, 0, len(rr)+1), rr...), payd.InvoiceRefund{
+			InvoiceID: inv.ID,
+			TxID:      req.TxID,
+			Satoshis:  req.Satoshis,
+			RefundTo:  req.RefundTo.ValueOrZero(),
+			CreatedAt: req.RefundedAt.Time,
+		})
+		inv.SatoshisRefunded += req.Satoshis
+		if inv.SatoshisRefunded >= inv.SatoshisReceived {
+			inv.State = payd.StateInvoiceRefunded
+		}
+		inv.RefundedAt = req.RefundedAt
+		inv.UpdatedAt = req.RefundedAt.Time
+		tx.st.invoices[inv.ID] = inv
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when refunding invoice with invoiceID %s", args.InvoiceID)
+	}
+	return &inv, nil
+}
+
+// InvoicesExpire will move the overdue pending or partially paid invoices to the expired
+// state, deleting their destinations still waiting to be paid, and return them.
+func (s *memoryStore) InvoicesExpire(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to expire invoices")
+	}
+	defer rollback(ctx, tx)
+	ii := tx.st.invoicesWhere(func(inv payd.Invoice) bool {
+		return (inv.State == payd.StateInvoicePending || inv.State == payd.StateInvoicePartiallyPaid) &&
+			inv.ExpiresAt.Valid && !inv.ExpiresAt.Time.After(args.ExpiresBefore)
+	})
+	now := time.Now().UTC()
+	for i := range ii {
+		ii[i].State = payd.StateInvoiceExpired
+		ii[i].UpdatedAt = now
+		tx.st.invoices[ii[i].ID] = ii[i]
+		for id, d := range tx.st.destinations {
+			if d.InvoiceID == ii[i].ID && d.State == "pending" {
+				d.State = "deleted"
+				d.UpdatedAt = now
+				tx.st.destinations[id] = d
+			}
+		}
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction when expiring invoices")
+	}
+	return ii, nil
+}
+
+// InvoiceDelete will mark an invoice as deleted, it will then no longer be returned.
+func (s *memoryStore) InvoiceDelete(ctx context.Context, args payd.InvoiceArgs) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete invoice with invoiceID %s", args.InvoiceID)
+	}
+	defer rollback(ctx, tx)
+	inv, err := tx.st.invoice(args.InvoiceID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to find invoice with id %s to delete", args.InvoiceID)
+	}
+	now := time.Now().UTC()
+	inv.DeletedAt = null.TimeFrom(now)
+	inv.UpdatedAt = now
+	inv.State = payd.StateInvoiceDeleted
+	tx.st.invoices[inv.ID] = inv
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when deleting invoice with invoiceID %s", args.InvoiceID)
+	}
+	return nil
+}
+
+// invoice returns a non deleted invoice, or a not found error if it doesn't exist.
+func (s *state) invoice(invoiceID string) (payd.Invoice, error) {
+	inv, ok := s.invoices[invoiceID]
+	if !ok || inv.State == payd.StateInvoiceDeleted {
+		return payd.Invoice{}, lathos.NewErrNotFound(errcodes.ErrInvoiceNotFound, fmt.Sprintf("invoice with invoiceID %s not found", invoiceID))
+	}
+	inv.BlockHeight = s.invoiceBlockHeight(invoiceID)
+	return inv, nil
+}
+
+// invoicesWhere returns the invoices matching fn, ordered by creation date.
+func (s *state) invoicesWhere(fn func(inv payd.Invoice) bool) []payd.Invoice {
+	var ii []payd.Invoice
+	for _, inv := range s.invoices {
+		inv.BlockHeight = s.invoiceBlockHeight(inv.ID)
+		if fn(inv) {
+			ii = append(ii, inv)
+		}
+	}
+	sort.Slice(ii, func(i, j int) bool {
+		if ii[i].CreatedAt.Equal(ii[j].CreatedAt) {
+			return ii[i].ID < ii[j].ID
+		}
+		return ii[i].CreatedAt.Before(ii[j].CreatedAt)
+	})
+	return ii
+}
+
+// invoiceBlockHeight returns the height of the highest block an invoice payment was mined in,
+// as with the sql stores it is only set once every payment has been mined.
+func (s *state) invoiceBlockHeight(invoiceID string) null.Int {
+	var height null.Int
+	for _, p := range s.payments[invoiceID] {
+		h := s.transactions[p.TxID].BlockHeight
+		if !h.Valid {
+			return null.Int{}
+		}
+		if !height.Valid || h.Int64 > height.Int64 {
+			height = h
+		}
+	}
+	return height
+}
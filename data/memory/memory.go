@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+// state holds all data in the store.
+//
+// A state is never modified once committed, writes are made to a copy which replaces
+// the committed state on commit. Records are stored by value and any maps or slices
+// they hold are replaced, never modified, so copying the top level maps is enough.
+type state struct {
+	userSeq        uint64
+	destinationSeq uint64
+
+	users               map[uint64]user
+	keys                map[keyID]payd.PrivateKey
+	peerChannelAccounts map[uint64]payd.PeerChannelAccount
+	invoices            map[string]payd.Invoice
+	payments            map[string][]payd.InvoicePayment
+	refunds             map[string][]payd.InvoiceRefund
+	feeQuotes           map[string]feeQuote
+	destinations        map[uint64]destination
+	transactions        map[string]transaction
+	txos                map[string]txo
+	proofs              map[proofID]proof
+	proofCallbacks      map[proofCallbackID]payd.ProofCallback
+	peerChannels        map[string]peerChannel
+	peerChannelTokens   map[peerChannelTokenID]payd.PeerChannelAPITokenStoreArgs
+	webhooks            map[string]webhook
+	webhookDeliveries   map[string]payd.WebhookDelivery
+}
+
+func (s *state) clone() *state {
+	c := *s
+	c.users = make(map[uint64]user, len(s.users))
+	for k, v := range s.users {
+		c.users[k] = v
+	}
+	c.keys = make(map[keyID]payd.PrivateKey, len(s.keys))
+	for k, v := range s.keys {
+		c.keys[k] = v
+	}
+	c.peerChannelAccounts = make(map[uint64]payd.PeerChannelAccount, len(s.peerChannelAccounts))
+	for k, v := range s.peerChannelAccounts {
+		c.peerChannelAccounts[k] = v
+	}
+	c.invoices = make(map[string]payd.Invoice, len(s.invoices))
+	for k, v := range s.invoices {
+		c.invoices[k] = v
+	}
+	c.payments = make(map[string][]payd.InvoicePayment, len(s.payments))
+	for k, v := range s.payments {
+		c.payments[k] = v
+	}
+	c.refunds = make(map[string][]payd.InvoiceRefund, len(s.refunds))
+	for k, v := range s.refunds {
+		c.refunds[k] = v
+	}
+	c.feeQuotes = make(map[string]feeQuote, len(s.feeQuotes))
+	for k, v := range s.feeQuotes {
+		c.feeQuotes[k] = v
+	}
+	c.destinations = make(map[uint64]destination, len(s.destinations))
+	for k, v := range s.destinations {
+		c.destinations[k] = v
+	}
+	c.transactions = make(map[string]transaction, len(s.transactions))
+	for k, v := range s.transactions {
+		c.transactions[k] = v
+	}
+	c.txos = make(map[string]txo, len(s.txos))
+	for k, v := range s.txos {
+		c.txos[k] = v
+	}
+	c.proofs = make(map[proofID]proof, len(s.proofs))
+	for k, v := range s.proofs {
+		c.proofs[k] = v
+	}
+	c.proofCallbacks = make(map[proofCallbackID]payd.ProofCallback, len(s.proofCallbacks))
+	for k, v := range s.proofCallbacks {
+		c.proofCallbacks[k] = v
+	}
+	c.peerChannels = make(map[string]peerChannel, len(s.peerChannels))
+	for k, v := range s.peerChannels {
+		c.peerChannels[k] = v
+	}
+	c.peerChannelTokens = make(map[peerChannelTokenID]payd.PeerChannelAPITokenStoreArgs, len(s.peerChannelTokens))
+	for k, v := range s.peerChannelTokens {
+		c.peerChannelTokens[k] = v
+	}
+	c.webhooks = make(map[string]webhook, len(s.webhooks))
+	for k, v := range s.webhooks {
+		c.webhooks[k] = v
+	}
+	c.webhookDeliveries = make(map[string]payd.WebhookDelivery, len(s.webhookDeliveries))
+	for k, v := range s.webhookDeliveries {
+		c.webhookDeliveries[k] = v
+	}
+	return &c
+}
+
+type memoryStore struct {
+	// writer is held for the lifetime of a write transaction, only one
+	// write transaction can be open at a time.
+	writer sync.Mutex
+	// mu guards swapping the committed state.
+	mu sync.RWMutex
+	st *state
+}
+
+// NewMemoryStore will setup and return an in-memory data store, it is seeded with
+// the same users as the sql stores. Nothing is persisted so all data is lost when
+// the store is discarded.
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{st: &state{
+		userSeq: 1,
+		users: map[uint64]user{
+			0: {
+				User: payd.User{
+					ID:          0,
+					Name:        "Userless",
+					Email:       "user@example.com",
+					Address:     "123 Street Fake",
+					PhoneNumber: "123456789",
+				},
+			},
+			1: {
+				User: payd.User{
+					ID:          1,
+					Name:        "BitcoinSV LiteClient",
+					Avatar:      "https://bitcoinassociation.net/wp-content/uploads/2019/09/2-bsv-logo-engtag-full.png",
+					Email:       "https://discord.gg/bsv",
+					Address:     "1 BSV Avenue",
+					PhoneNumber: "0800-123-456",
+				},
+				IsOwner: true,
+				Meta: map[string]string{
+					"example key 1": "example value 1",
+					"example key 2": "example value 2",
+				},
+			},
+		},
+		keys: map[keyID]payd.PrivateKey{},
+		// account 0 is used by the userless user, for receiving change proofs.
+		peerChannelAccounts: map[uint64]payd.PeerChannelAccount{
+			0: {ID: 0},
+			1: {ID: 1, Username: "username", Password: "password"},
+		},
+		invoices:          map[string]payd.Invoice{},
+		payments:          map[string][]payd.InvoicePayment{},
+		refunds:           map[string][]payd.InvoiceRefund{},
+		feeQuotes:         map[string]feeQuote{},
+		destinations:      map[uint64]destination{},
+		transactions:      map[string]transaction{},
+		txos:              map[string]txo{},
+		proofs:            map[proofID]proof{},
+		proofCallbacks:    map[proofCallbackID]payd.ProofCallback{},
+		peerChannels:      map[string]peerChannel{},
+		peerChannelTokens: map[peerChannelTokenID]payd.PeerChannelAPITokenStoreArgs{},
+		webhooks:          map[string]webhook{},
+		webhookDeliveries: map[string]payd.WebhookDelivery{},
+	}}
+}
+
+// newTx returns the transaction to write to, if there is a context based tx it
+// is used, starting it if this is the first write.
+func (s *memoryStore) newTx(ctx context.Context) (*Tx, error) {
+	ctxx := TxFromContext(ctx)
+	if ctxx == nil {
+		ctxx = &Tx{}
+	}
+	if ctxx.done {
+		return nil, errors.New("transaction has already been committed or rolled back")
+	}
+	if ctxx.st == nil {
+		s.writer.Lock()
+		s.mu.RLock()
+		ctxx.s = s
+		ctxx.st = s.st.clone()
+		s.mu.RUnlock()
+	}
+	return ctxx, nil
+}
+
+// view returns the state to read from, this is the context based tx state
+// if one has been started, otherwise the last committed state.
+func (s *memoryStore) view(ctx context.Context) *state {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil && ctxx.st != nil && !ctxx.done {
+		return ctxx.st
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.st
+}
+
+// commit a transaction, if there is a context based tx
+// this will not commit - we wait on the context to close it.
+func commit(ctx context.Context, tx *Tx) error {
+	if TxFromContext(ctx) != nil {
+		return nil
+	}
+	return tx.commit()
+}
+
+// rollback a transaction, if there is a context based tx
+// this will not rollback - we wait on the context to close it.
+func rollback(ctx context.Context, tx *Tx) {
+	if TxFromContext(ctx) != nil {
+		return
+	}
+	tx.rollback()
+}
+
+type execKey int
+
+// nolint:gochecknoglobals // this variable is fine as it's used for context & is private
+var exec execKey
+
+// Tx is a write transaction, changes are made to a copy of the store state
+// which replaces the store state when committed.
+type Tx struct {
+	s    *memoryStore
+	st   *state
+	done bool
+}
+
+func (t *Tx) commit() error {
+	if t.st == nil || t.done {
+		return nil
+	}
+	t.s.mu.Lock()
+	t.s.st = t.st
+	t.s.mu.Unlock()
+	t.done = true
+	t.s.writer.Unlock()
+	return nil
+}
+
+func (t *Tx) rollback() {
+	if t.st == nil || t.done {
+		return
+	}
+	t.done = true
+	t.s.writer.Unlock()
+}
+
+// WithTxContext will add a new empty transaction to the provided context.
+func WithTxContext(ctx context.Context) context.Context {
+	tx := TxFromContext(ctx)
+	if tx != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, exec, &Tx{})
+}
+
+// TxFromContext will return a context based transaction if found.
+func TxFromContext(ctx context.Context) *Tx {
+	if tx, ok := ctx.Value(exec).(*Tx); ok {
+		return tx
+	}
+	return nil
+}
+
+// Transacter is used to implement the DBTransacter interface for
+// managing db transactions in other layers.
+type Transacter struct {
+}
+
+// WithTx will add a TX to the provided context.
+func (t *Transacter) WithTx(ctx context.Context) context.Context {
+	return WithTxContext(ctx)
+}
+
+// Commit will make all changes in the context transaction visible.
+func (t *Transacter) Commit(ctx context.Context) error {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx.commit()
+	}
+	return nil
+}
+
+// Rollback will discard all changes in the context transaction.
+func (t *Transacter) Rollback(ctx context.Context) error {
+	if tx := TxFromContext(ctx); tx != nil {
+		tx.rollback()
+	}
+	return nil
+}
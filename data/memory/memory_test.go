@@ -0,0 +1,16 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data"
+	"github.com/libsv/payd/data/memory"
+	"github.com/libsv/payd/data/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (data.Store, payd.Transacter) {
+		return memory.NewMemoryStore(), &memory.Transacter{}
+	})
+}
@@ -0,0 +1,24 @@
+package memory
+
+import (
+	"context"
+
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// Owner will return the owner of the wallet.
+func (s *memoryStore) Owner(ctx context.Context) (*payd.User, error) {
+	var owner *user
+	for _, u := range s.view(ctx).users {
+		u := u
+		if u.IsOwner && (owner == nil || u.ID < owner.ID) {
+			owner = &u
+		}
+	}
+	if owner == nil {
+		return nil, lathos.NewErrNotFound("N004", "failed to get wallet owner")
+	}
+	return owner.toUser(), nil
+}
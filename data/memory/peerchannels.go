@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// peerChannel is the stored representation of a peer channel.
+type peerChannel struct {
+	AccountID int64
+	ChannelID string
+	Host      string
+	Path      string
+	Type      payd.PeerChannelHandlerType
+	CreatedAt time.Time
+	Closed    bool
+}
+
+// peerChannelTokenID uniquely identifies an api token for a channel.
+type peerChannelTokenID struct {
+	channelID string
+	token     string
+}
+
+// PeerChannelAccount will return the peer channel account for a user.
+func (s *memoryStore) PeerChannelAccount(ctx context.Context, args *payd.PeerChannelIDArgs) (*payd.PeerChannelAccount, error) {
+	acc, ok := s.view(ctx).peerChannelAccounts[uint64(args.UserID)]
+	if !ok {
+		return nil, lathos.NewErrNotFound(errcodes.ErrPeerChannelNotFound, fmt.Sprintf("peer channel account for user id %d not found", args.UserID))
+	}
+	return &acc, nil
+}
+
+// PeerChannelCreate will store a new open peer channel.
+func (s *memoryStore) PeerChannelCreate(ctx context.Context, args *payd.PeerChannelCreateArgs) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert channel %s", args.ChannelID)
+	}
+	defer rollback(ctx, tx)
+	if args.CreatedAt.IsZero() {
+		args.CreatedAt = time.Now()
+	}
+	if _, ok := tx.st.peerChannels[args.ChannelID]; ok {
+		return lathos.NewErrDuplicate("D001", fmt.Sprintf("channel %s already exists", args.ChannelID))
+	}
+	tx.st.peerChannels[args.ChannelID] = peerChannel{
+		AccountID: args.PeerChannelAccountID,
+		ChannelID: args.ChannelID,
+		Host:      args.ChannelHost,
+		Path:      args.ChannelPath,
+		Type:      args.ChannelType,
+		CreatedAt: args.CreatedAt,
+	}
+	return errors.Wrapf(commit(ctx, tx), "failed to commit creating channel %s", args.ChannelID)
+}
+
+// PeerChannelAPITokenCreate will store an api token for a channel.
+func (s *memoryStore) PeerChannelAPITokenCreate(ctx context.Context, args *payd.PeerChannelAPITokenStoreArgs) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert api token %s for channel %s", args.Token, args.PeerChannelsChannelID)
+	}
+	defer rollback(ctx, tx)
+	if _, ok := tx.st.peerChannels[args.PeerChannelsChannelID]; !ok {
+		return lathos.NewErrNotFound(errcodes.ErrPeerChannelNotFound, fmt.Sprintf("channel %s not found", args.PeerChannelsChannelID))
+	}
+	tx.st.peerChannelTokens[peerChannelTokenID{channelID: args.PeerChannelsChannelID, token: args.Token}] = *args
+	return errors.Wrapf(commit(ctx, tx), "failed to commit creating token %s for channel %s", args.Token, args.PeerChannelsChannelID)
+}
+
+// PeerChannelAPITokensCreate will store multiple api tokens.
+func (s *memoryStore) PeerChannelAPITokensCreate(ctx context.Context, entries ...*payd.PeerChannelAPITokenStoreArgs) error {
+	for _, entry := range entries {
+		if err := s.PeerChannelAPITokenCreate(ctx, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PeerChannelsOpened returns the open channels of channelType, a channel is returned once per api token.
+func (s *memoryStore) PeerChannelsOpened(ctx context.Context, channelType payd.PeerChannelHandlerType) ([]payd.PeerChannel, error) {
+	st := s.view(ctx)
+	var resp []payd.PeerChannel
+	for id := range st.peerChannelTokens {
+		pc := st.peerChannels[id.channelID]
+		if pc.Closed || pc.Type != channelType {
+			continue
+		}
+		resp = append(resp, payd.PeerChannel{
+			ID:        pc.ChannelID,
+			Token:     id.token,
+			Host:      pc.Host,
+			Path:      pc.Path,
+			CreatedAt: pc.CreatedAt,
+			Type:      pc.Type,
+		})
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].ID == resp[j].ID {
+			return resp[i].Token < resp[j].Token
+		}
+		return resp[i].ID < resp[j].ID
+	})
+	return resp, nil
+}
+
+// PeerChannelCloseChannel will mark a channel as closed.
+func (s *memoryStore) PeerChannelCloseChannel(ctx context.Context, channelID string) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to close channel %s", channelID)
+	}
+	defer rollback(ctx, tx)
+	pc, ok := tx.st.peerChannels[channelID]
+	if !ok {
+		return lathos.NewErrNotFound(errcodes.ErrPeerChannelNotFound, fmt.Sprintf("channel %s not found", channelID))
+	}
+	pc.Closed = true
+	tx.st.peerChannels[channelID] = pc
+
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction for peerChannelClose")
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// keyID uniquely identifies a users private key.
+type keyID struct {
+	userID uint64
+	name   string
+}
+
+// PrivateKey will return a key by name from the datastore.
+// If not found nil is returned.
+func (s *memoryStore) PrivateKey(ctx context.Context, args payd.KeyArgs) (*payd.PrivateKey, error) {
+	k, ok := s.view(ctx).keys[keyID{userID: args.UserID, name: args.Name}]
+	if !ok {
+		return nil, nil
+	}
+	return &k, nil
+}
+
+// PrivateKeyCreate will create and return a new key in the datastore.
+func (s *memoryStore) PrivateKeyCreate(ctx context.Context, req payd.PrivateKey) (*payd.PrivateKey, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to add key named '%s'", req.Name)
+	}
+	defer rollback(ctx, tx)
+	id := keyID{userID: req.UserID, name: req.Name}
+	if _, ok := tx.st.keys[id]; ok {
+		return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("key named '%s' already exists", req.Name))
+	}
+	req.CreatedAt = time.Now().UTC()
+	tx.st.keys[id] = req
+	return &req, errors.Wrap(commit(ctx, tx), "failed to commit create key tx")
+}
+
+// PrivateKeys will return every key in the datastore.
+func (s *memoryStore) PrivateKeys(ctx context.Context) ([]payd.PrivateKey, error) {
+	st := s.view(ctx)
+	resp := make([]payd.PrivateKey, 0, len(st.keys))
+	for _, k := range st.keys {
+		resp = append(resp, k)
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		if resp[i].UserID != resp[j].UserID {
+			return resp[i].UserID < resp[j].UserID
+		}
+		return resp[i].Name < resp[j].Name
+	})
+	return resp, nil
+}
+
+// PrivateKeysUpdate will replace the xprv of each key, if any aren't found none are updated.
+func (s *memoryStore) PrivateKeysUpdate(ctx context.Context, req []payd.PrivateKey) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to update keys")
+	}
+	defer rollback(ctx, tx)
+	for _, k := range req {
+		id := keyID{userID: k.UserID, name: k.Name}
+		key, ok := tx.st.keys[id]
+		if !ok {
+			return lathos.NewErrNotFound(errcodes.ErrPrivateKeyNotFound,
+				fmt.Sprintf("key named '%s' for user %d not found", k.Name, k.UserID))
+		}
+		key.Xprv = k.Xprv
+		key.Mnemonic = k.Mnemonic
+		tx.st.keys[id] = key
+	}
+	return errors.Wrap(commit(ctx, tx), "failed to commit update keys tx")
+}
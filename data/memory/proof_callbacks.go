@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// proofCallbackID uniquely identifies a callback url for a payment.
+type proofCallbackID struct {
+	txID string
+	url  string
+}
+
+// ProofCallbacks returns the callbacks for an invoice, optionally only those for a single transaction.
+func (s *memoryStore) ProofCallbacks(ctx context.Context, args payd.ProofCallbackArgs) ([]payd.ProofCallback, error) {
+	cc := s.view(ctx).proofCallbacksWhere(func(c payd.ProofCallback) bool {
+		return c.InvoiceID == args.InvoiceID && (args.TxID == "" || c.TxID == args.TxID)
+	}, func(a, b payd.ProofCallback) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if a.TxID != b.TxID {
+			return a.TxID < b.TxID
+		}
+		return a.URL < b.URL
+	})
+	return cc, nil
+}
+
+// ProofCallbacksDue returns the pending callbacks with a proof that are due to be sent, oldest first.
+func (s *memoryStore) ProofCallbacksDue(ctx context.Context, args payd.ProofCallbacksDueArgs) ([]payd.ProofCallback, error) {
+	cc := s.view(ctx).proofCallbacksWhere(func(c payd.ProofCallback) bool {
+		return c.State == payd.StateProofCallbackPending && c.NextAttemptAt.Valid && !c.NextAttemptAt.Time.After(args.DueBefore)
+	}, func(a, b payd.ProofCallback) bool {
+		if !a.NextAttemptAt.Time.Equal(b.NextAttemptAt.Time) {
+			return a.NextAttemptAt.Time.Before(b.NextAttemptAt.Time)
+		}
+		if a.TxID != b.TxID {
+			return a.TxID < b.TxID
+		}
+		return a.URL < b.URL
+	})
+	if args.Limit > 0 && len(cc) > args.Limit {
+		cc = cc[:args.Limit]
+	}
+	return cc, nil
+}
+
+// ProofCallBacksCreate will store merkle proof callback urls for a payment.
+func (s *memoryStore) ProofCallBacksCreate(ctx context.Context, args payd.ProofCallbackArgs, req map[string]dpp.ProofCallback) error {
+	if len(req) == 0 {
+		// nothing to store
+		return nil
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert callback urls for invoiceID %s", args.InvoiceID)
+	}
+	defer rollback(ctx, tx)
+	if _, err := tx.st.invoice(args.InvoiceID); err != nil {
+		return errors.WithMessagef(err, "failed to insert callback urls for invoiceID %s", args.InvoiceID)
+	}
+	if _, ok := tx.st.transactions[args.TxID]; !ok {
+		return lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("transaction with txID %s not found", args.TxID))
+	}
+	now := time.Now().UTC()
+	for url, val := range req {
+		id := proofCallbackID{txID: args.TxID, url: url}
+		if _, ok := tx.st.proofCallbacks[id]; ok {
+			return lathos.NewErrDuplicate("D001", fmt.Sprintf("callback url %s already exists for txID %s", url, args.TxID))
+		}
+		tx.st.proofCallbacks[id] = payd.ProofCallback{
+			InvoiceID: args.InvoiceID,
+			TxID:      args.TxID,
+			URL:       url,
+			Token:     val.Token,
+			State:     payd.StateProofCallbackPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when creating callback urls with invoiceID %s", args.InvoiceID)
+	}
+	return nil
+}
+
+// ProofCallbacksQueue will store the proof for each callback of a transaction and queue them to be sent.
+func (s *memoryStore) ProofCallbacksQueue(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to queue proof callbacks for txID %s", args.TxID)
+	}
+	defer rollback(ctx, tx)
+	for id, c := range tx.st.proofCallbacks {
+		if c.TxID != args.TxID {
+			continue
+		}
+		c.Proof = append([]byte{}, req.Proof...)
+		c.State = payd.StateProofCallbackPending
+		c.Attempts = 0
+		c.NextAttemptAt.SetValid(req.NextAttemptAt.UTC())
+		c.LastError.Valid = false
+		c.LastError.String = ""
+		c.UpdatedAt = req.UpdatedAt.UTC()
+		tx.st.proofCallbacks[id] = c
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when queueing proof callbacks for txID %s", args.TxID)
+	}
+	return nil
+}
+
+// ProofCallbackUpdate will record the outcome of sending a proof callback.
+func (s *memoryStore) ProofCallbackUpdate(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	defer rollback(ctx, tx)
+	id := proofCallbackID{txID: args.TxID, url: args.URL}
+	c, ok := tx.st.proofCallbacks[id]
+	if !ok {
+		return lathos.NewErrNotFound(errcodes.ErrProofCallbackNotFound, fmt.Sprintf("proof callback %s for txID %s not found", args.URL, args.TxID))
+	}
+	c.State = req.State
+	c.Attempts = req.Attempts
+	c.NextAttemptAt = req.NextAttemptAt
+	if c.NextAttemptAt.Valid {
+		c.NextAttemptAt.Time = c.NextAttemptAt.Time.UTC()
+	}
+	c.LastError = req.LastError
+	c.UpdatedAt = req.UpdatedAt.UTC()
+	tx.st.proofCallbacks[id] = c
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when updating proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	return nil
+}
+
+// proofCallbacksWhere returns the proof callbacks matching fn, sorted by less.
+func (s *state) proofCallbacksWhere(fn func(c payd.ProofCallback) bool, less func(a, b payd.ProofCallback) bool) []payd.ProofCallback {
+	var cc []payd.ProofCallback
+	for _, c := range s.proofCallbacks {
+		if fn(c) {
+			cc = append(cc, c)
+		}
+	}
+	sort.Slice(cc, func(i, j int) bool {
+		return less(cc[i], cc[j])
+	})
+	return cc
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+)
+
+// proofID uniquely identifies a proof of a tx in a block.
+type proofID struct {
+	txID      string
+	blockHash string
+}
+
+// proof is the stored representation of a merkle proof, it is stale once its block is orphaned.
+type proof struct {
+	bc.MerkleProof
+	StaleAt null.Time
+}
+
+// ProofCreate will store a proof against its tx and block.
+func (s *memoryStore) ProofCreate(ctx context.Context, req dpp.ProofWrapper) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store proof for txid %s", req.CallbackTxID)
+	}
+	defer rollback(ctx, tx)
+	id := proofID{txID: req.CallbackTxID, blockHash: req.BlockHash}
+	if p, ok := tx.st.proofs[id]; ok && !p.StaleAt.Valid {
+		return lathos.NewErrDuplicate("D001", fmt.Sprintf("proof for txid %s and blockhash '%s' already exists", req.CallbackTxID, req.BlockHash))
+	}
+	// a tx can only be in one block of the best chain so proofs in any other block are stale.
+	now := null.TimeFrom(time.Now().UTC())
+	for pid, p := range tx.st.proofs {
+		if pid.txID == id.txID && !p.StaleAt.Valid {
+			p.StaleAt = now
+			tx.st.proofs[pid] = p
+		}
+	}
+	var mp bc.MerkleProof
+	if req.CallbackPayload != nil {
+		mp = *req.CallbackPayload
+		mp.Nodes = append([]string(nil), mp.Nodes...)
+	}
+	tx.st.proofs[id] = proof{MerkleProof: mp}
+	return errors.WithStack(commit(ctx, tx))
+}
+
+// MerkleProof will retrieve a proof.
+func (s *memoryStore) MerkleProof(ctx context.Context, txID string) (*bc.MerkleProof, error) {
+	var ids []proofID
+	st := s.view(ctx)
+	for id, p := range st.proofs {
+		if id.txID == txID && !p.StaleAt.Valid {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].blockHash < ids[j].blockHash })
+	mp := st.proofs[ids[0]].MerkleProof
+	mp.Nodes = append([]string(nil), mp.Nodes...)
+	return &mp, nil
+}
+
+// ProofBlocks will return the blocks of the proofs of mined and confirmed transactions, highest first.
+func (s *memoryStore) ProofBlocks(ctx context.Context, args payd.ProofBlocksArgs) ([]payd.ProofBlock, error) {
+	st := s.view(ctx)
+	seen := map[payd.ProofBlock]bool{}
+	blocks := []payd.ProofBlock{}
+	for id, p := range st.proofs {
+		if p.StaleAt.Valid {
+			continue
+		}
+		t, ok := st.transactions[id.txID]
+		if !ok || !t.BlockHeight.Valid || t.BlockHeight.Int64 < int64(args.MinBlockHeight) {
+			continue
+		}
+		if t.State != payd.StateTxMined && t.State != payd.StateTxConfirmed {
+			continue
+		}
+		b := payd.ProofBlock{BlockHash: id.blockHash, BlockHeight: uint32(t.BlockHeight.Int64)}
+		if !seen[b] {
+			seen[b] = true
+			blocks = append(blocks, b)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].BlockHeight != blocks[j].BlockHeight {
+			return blocks[i].BlockHeight > blocks[j].BlockHeight
+		}
+		return blocks[i].BlockHash < blocks[j].BlockHash
+	})
+	return blocks, nil
+}
+
+// ProofsStale will mark the proofs of a block stale, returning the txIDs of the proofs.
+func (s *memoryStore) ProofsStale(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to mark proofs of block %s stale", args.BlockHash)
+	}
+	defer rollback(ctx, tx)
+	txIDs := []string{}
+	for id, p := range tx.st.proofs {
+		if id.blockHash != args.BlockHash || p.StaleAt.Valid {
+			continue
+		}
+		p.StaleAt = null.TimeFrom(args.StaleAt)
+		tx.st.proofs[id] = p
+		txIDs = append(txIDs, id.txID)
+	}
+	sort.Strings(txIDs)
+	return txIDs, errors.Wrapf(commit(ctx, tx),
+		"failed to commit transaction when marking proofs of block %s stale", args.BlockHash)
+}
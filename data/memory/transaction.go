@@ -0,0 +1,231 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// transaction is the stored representation of a transaction.
+type transaction struct {
+	TxID        string
+	TxHex       string
+	State       payd.TxState
+	FailReason  null.String
+	BlockHeight null.Int
+	InvoiceID   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// txo is the stored representation of a transaction output paying one of our destinations.
+type txo struct {
+	Outpoint      string
+	DestinationID uint64
+	TxID          string
+	Vout          uint64
+	Satoshis      uint64
+	ReservedFor   null.String
+	ReservedUntil null.Time
+	Frozen        bool
+	SpentAt       null.Time
+	SpendingTxID  null.String
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// spent returns true if the txo has been spent.
+func (t txo) spent() bool {
+	return t.SpentAt.Valid || t.SpendingTxID.Valid
+}
+
+// TransactionCreate will store a transaction and its txos.
+func (s *memoryStore) TransactionCreate(ctx context.Context, req payd.TransactionCreate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to store tx '%s'", req.TxID)
+	}
+	defer rollback(ctx, tx)
+	if _, ok := tx.st.transactions[req.TxID]; ok {
+		return lathos.NewErrDuplicate("D001", "transaction has already been stored")
+	}
+	// If an invoice id was provided, the tx pays it, otherwise this is a change tx.
+	if req.InvoiceID != "" {
+		if _, err := tx.st.invoice(req.InvoiceID); err != nil {
+			return errors.WithMessagef(err, "failed to create invoice mapping for tx %s", req.TxID)
+		}
+	}
+	timestamp := time.Now().UTC()
+	tx.st.transactions[req.TxID] = transaction{
+		TxID:      req.TxID,
+		TxHex:     req.TxHex,
+		State:     payd.StateTxPending,
+		InvoiceID: req.InvoiceID,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	}
+	for _, o := range req.Outputs {
+		if _, ok := tx.st.txos[o.Outpoint]; ok {
+			return lathos.NewErrDuplicate("D001", fmt.Sprintf("txo %s has already been stored", o.Outpoint))
+		}
+		d, err := tx.st.destination(o.DestinationID)
+		if err != nil {
+			return errors.WithMessage(err, "failed to update destinations state to received")
+		}
+		d.State = "received"
+		d.UpdatedAt = timestamp
+		tx.st.destinations[d.ID] = d
+		tx.st.txos[o.Outpoint] = txo{
+			Outpoint:      o.Outpoint,
+			DestinationID: o.DestinationID,
+			TxID:          o.TxID,
+			Vout:          o.Vout,
+			Satoshis:      o.Satoshis,
+			CreatedAt:     timestamp,
+			UpdatedAt:     timestamp,
+		}
+	}
+	return errors.Wrapf(commit(ctx, tx),
+		"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
+}
+
+// TransactionUpdateState will update a transactions internal state.
+func (s *memoryStore) TransactionUpdateState(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update transactionId '%s' state to '%s'", args.TxID, req.State)
+	}
+	defer rollback(ctx, tx)
+	t, ok := tx.st.transactions[args.TxID]
+	if !ok {
+		return lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("tx '%s' not in store", args.TxID))
+	}
+	mined := req.State == payd.StateTxMined || req.State == payd.StateTxConfirmed
+	// a tx with a proof on the best chain only goes back to broadcast once its proofs are stale.
+	if !mined {
+		for id, p := range tx.st.proofs {
+			if id.txID == args.TxID && !p.StaleAt.Valid {
+				return lathos.NewErrUnprocessable(errcodes.ErrTxStateMined,
+					fmt.Sprintf("tx '%s' is mined and can't be set %s", args.TxID, req.State))
+			}
+		}
+	}
+	t.State = req.State
+	t.FailReason = req.FailReason
+	switch {
+	case !mined:
+		t.BlockHeight = null.Int{}
+	case req.BlockHeight.Valid:
+		t.BlockHeight = req.BlockHeight
+	}
+	t.UpdatedAt = time.Now().UTC()
+	tx.st.transactions[args.TxID] = t
+	return errors.Wrapf(commit(ctx, tx),
+		"failed to commit transaction when updating transactionId '%s' state to '%s'", args.TxID, req.State)
+}
+
+// TransactionsConfirm will set mined transactions at or below the max block height to confirmed.
+func (s *memoryStore) TransactionsConfirm(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to confirm transactions mined at or below height %d", args.MaxBlockHeight)
+	}
+	defer rollback(ctx, tx)
+	var n int
+	now := time.Now().UTC()
+	for id, t := range tx.st.transactions {
+		if t.State != payd.StateTxMined || t.BlockHeight.Int64 > int64(args.MaxBlockHeight) {
+			continue
+		}
+		t.State = payd.StateTxConfirmed
+		t.UpdatedAt = now
+		tx.st.transactions[id] = t
+		n++
+	}
+	return n, errors.Wrapf(commit(ctx, tx),
+		"failed to commit transaction when confirming transactions mined at or below height %d", args.MaxBlockHeight)
+}
+
+// Tx returns a tx from the internal store.
+func (s *memoryStore) Tx(ctx context.Context, txID string) (*bt.Tx, error) {
+	t, ok := s.view(ctx).transactions[txID]
+	if !ok {
+		return nil, lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("tx '%s' not in store", txID))
+	}
+	return bt.NewTxFromString(t.TxHex)
+}
+
+// Transactions returns the txs matching args, ordered by creation date then id, with
+// the wallet txos they received and spent totalled.
+func (s *memoryStore) Transactions(ctx context.Context, args payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+	st := s.view(ctx)
+	txs := make(map[string]*payd.TransactionHistory, len(st.transactions))
+	for _, tx := range st.transactions {
+		h := &payd.TransactionHistory{
+			TxID:       tx.TxID,
+			TxHex:      tx.TxHex,
+			State:      tx.State,
+			FailReason: tx.FailReason,
+			CreatedAt:  tx.CreatedAt,
+		}
+		if tx.InvoiceID != "" {
+			h.InvoiceID = null.StringFrom(tx.InvoiceID)
+		}
+		txs[tx.TxID] = h
+	}
+	for _, t := range st.txos {
+		if h, ok := txs[t.TxID]; ok {
+			h.Received += t.Satoshis
+		}
+		if !t.SpendingTxID.Valid {
+			continue
+		}
+		h, ok := txs[t.SpendingTxID.String]
+		if !ok {
+			continue
+		}
+		h.Spent += t.Satoshis
+		if t.ReservedFor.Valid && (!h.PaidTo.Valid || t.ReservedFor.String < h.PaidTo.String) {
+			h.PaidTo = t.ReservedFor
+		}
+	}
+	for _, rr := range st.refunds {
+		for _, r := range rr {
+			if h, ok := txs[r.TxID]; ok && !h.InvoiceID.Valid {
+				h.InvoiceID = null.StringFrom(r.InvoiceID)
+			}
+		}
+	}
+	for id, p := range st.proofs {
+		if h, ok := txs[id.txID]; ok && !p.StaleAt.Valid {
+			h.Confirmed = true
+		}
+	}
+	resp := []payd.TransactionHistory{}
+	for _, h := range txs {
+		if args.Matches(*h) {
+			resp = append(resp, *h)
+		}
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		less := resp[i].CreatedAt.Before(resp[j].CreatedAt) ||
+			resp[i].CreatedAt.Equal(resp[j].CreatedAt) && resp[i].TxID < resp[j].TxID
+		if args.Descending() {
+			return !less
+		}
+		return less
+	})
+	if args.Limit > 0 && len(resp) > args.Limit {
+		resp = resp[:args.Limit]
+	}
+	return resp, nil
+}
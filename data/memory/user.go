@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	lerrs "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+// user is the stored representation of a user, Meta holds the user meta data
+// which replaces the User ExtendedData.
+type user struct {
+	payd.User
+	IsOwner bool
+	Meta    map[string]string
+}
+
+func (u user) toUser() *payd.User {
+	resp := u.User
+	resp.ExtendedData = make(map[string]interface{}, len(u.Meta))
+	for k, v := range u.Meta {
+		resp.ExtendedData[k] = v
+	}
+	return &resp
+}
+
+// CreateUser creates a new user in the system.
+func (s *memoryStore) CreateUser(ctx context.Context, req payd.CreateUserArgs, pks payd.PrivateKeyService) (*payd.CreateUserResponse, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new user: %s", req.Name)
+	}
+	defer rollback(ctx, tx)
+
+	tx.st.userSeq++
+	u := user{
+		User: payd.User{
+			ID:          tx.st.userSeq,
+			Name:        req.Name,
+			Avatar:      req.Avatar,
+			Email:       req.Email,
+			Address:     req.Address,
+			PhoneNumber: req.PhoneNumber,
+		},
+		Meta: make(map[string]string, len(req.ExtendedData)),
+	}
+	// meta data is stored as text, as it is in the sql stores.
+	for k, v := range req.ExtendedData {
+		u.Meta[k] = fmt.Sprintf("%v", v)
+	}
+	tx.st.users[u.ID] = u
+
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating new user: %s", req.Name)
+	}
+
+	// Create a new xpriv for this new user
+	if err = pks.Create(ctx, "masterkey", u.ID); err != nil {
+		return nil, errors.Wrap(err, "failed to create new xkey")
+	}
+	return &payd.CreateUserResponse{ID: u.ID}, nil
+}
+
+// ReadUser will return a user, they must have a master key.
+func (s *memoryStore) ReadUser(ctx context.Context, userID uint64) (*payd.User, error) {
+	st := s.view(ctx)
+	u, ok := st.users[userID]
+	_, hasKey := st.keys[keyID{userID: userID, name: "masterkey"}]
+	if !ok || !hasKey {
+		return nil, lerrs.NewErrNotFound("N004", fmt.Sprintf("failed to get wallet owner: user %d not found", userID))
+	}
+	return u.toUser(), nil
+}
+
+// UpdateUser is not currently supported.
+func (s *memoryStore) UpdateUser(ctx context.Context, ID uint64, d payd.User) (*payd.User, error) {
+	return nil, nil
+}
+
+// DeleteUser will remove a user and their keys.
+func (s *memoryStore) DeleteUser(ctx context.Context, userID uint64) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete user %d", userID)
+	}
+	defer rollback(ctx, tx)
+	if _, ok := tx.st.users[userID]; !ok {
+		return lerrs.NewErrNotFound("N004", fmt.Sprintf("failed to delete wallet owner: user %d not found", userID))
+	}
+	delete(tx.st.users, userID)
+	for id := range tx.st.keys {
+		if id.userID == userID {
+			delete(tx.st.keys, id)
+		}
+	}
+	return errors.Wrapf(commit(ctx, tx), "failed to commit transaction when deleting user %d", userID)
+}
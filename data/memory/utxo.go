@@ -0,0 +1,237 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// UTXOReserve marks the utxos chosen by the selector, from the unspent txos of broadcast txs,
+// as reserved and returns them. If there aren't enough funds nothing is reserved.
+func (s *memoryStore) UTXOReserve(ctx context.Context, req payd.UTXOReserve) ([]payd.UTXO, error) {
+	if req.Selector == nil {
+		return nil, errors.New("a coin selector is required to reserve utxos")
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reserve utxos")
+	}
+	defer rollback(ctx, tx)
+	var tt []txo
+	for _, t := range tx.st.txos {
+		if t.spent() || t.ReservedFor.Valid || t.Frozen || !req.Spendable(tx.st.transactions[t.TxID].State) {
+			continue
+		}
+		tt = append(tt, t)
+	}
+	sort.Slice(tt, func(i, j int) bool {
+		if tt[i].CreatedAt.Equal(tt[j].CreatedAt) {
+			return tt[i].Outpoint < tt[j].Outpoint
+		}
+		return tt[i].CreatedAt.Before(tt[j].CreatedAt)
+	})
+	spendable := make([]payd.UTXO, 0, len(tt))
+	for _, t := range tt {
+		d, err := tx.st.destination(t.DestinationID)
+		if err != nil {
+			return nil, err
+		}
+		if !req.MatchesKey(d.UserID, d.KeyName) {
+			continue
+		}
+		spendable = append(spendable, payd.UTXO{
+			Outpoint:       t.Outpoint,
+			TxID:           t.TxID,
+			Vout:           uint32(t.Vout),
+			Satoshis:       t.Satoshis,
+			LockingScript:  d.LockingScript,
+			DerivationPath: d.DerivationPath,
+			CreatedAt:      t.CreatedAt,
+		})
+	}
+	utxos := req.Selector.SelectCoins(payd.CoinSelectArgs{
+		Satoshis:  req.Satoshis,
+		InputFee:  req.InputFee,
+		ChangeFee: req.ChangeFee,
+	}, spendable)
+	if len(utxos) == 0 {
+		return []payd.UTXO{}, nil
+	}
+	timestamp := time.Now().UTC()
+	for _, utxo := range utxos {
+		t := tx.st.txos[utxo.Outpoint]
+		t.ReservedFor = null.StringFrom(req.ReservedFor)
+		t.ReservedUntil = null.NewTime(req.ReservedUntil, !req.ReservedUntil.IsZero())
+		t.UpdatedAt = timestamp
+		tx.st.txos[t.Outpoint] = t
+	}
+
+	return utxos, errors.Wrap(commit(ctx, tx), "error committing utxo reservation")
+}
+
+// UTXOUnreserve unmarks the reservation from matching reservations that haven't been spent, or were
+// spent by a tx that was never broadcast, which are unspent again.
+func (s *memoryStore) UTXOUnreserve(ctx context.Context, req payd.UTXOUnreserve) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to unreserve utxos")
+	}
+	defer rollback(ctx, tx)
+	timestamp := time.Now().UTC()
+	for _, t := range tx.st.txos {
+		if !t.ReservedFor.Valid || t.ReservedFor.String != req.ReservedFor || !tx.st.releasable(t) {
+			continue
+		}
+		t.ReservedFor = null.String{}
+		t.ReservedUntil = null.Time{}
+		t.SpentAt = null.Time{}
+		t.SpendingTxID = null.String{}
+		t.UpdatedAt = timestamp
+		tx.st.txos[t.Outpoint] = t
+	}
+
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction to unreserve utxos")
+}
+
+// UTXOReservations returns the reserved utxos that haven't been spent, or were spent by a tx
+// that was never broadcast, grouped by reservation.
+func (s *memoryStore) UTXOReservations(ctx context.Context) ([]payd.UTXOReservation, error) {
+	st := s.view(ctx)
+	reservations := map[string]*payd.UTXOReservation{}
+	for _, t := range st.txos {
+		if !t.ReservedFor.Valid || !st.releasable(t) {
+			continue
+		}
+		r, ok := reservations[t.ReservedFor.String]
+		if !ok {
+			r = &payd.UTXOReservation{ReservedFor: t.ReservedFor.String}
+			reservations[t.ReservedFor.String] = r
+		}
+		r.UTXOs++
+		r.Satoshis += t.Satoshis
+		if t.ReservedUntil.Valid && (!r.ReservedUntil.Valid || t.ReservedUntil.Time.After(r.ReservedUntil.Time)) {
+			r.ReservedUntil = t.ReservedUntil
+		}
+	}
+	rr := make([]payd.UTXOReservation, 0, len(reservations))
+	for _, r := range reservations {
+		rr = append(rr, *r)
+	}
+	sort.Slice(rr, func(i, j int) bool {
+		return rr[i].ReservedFor < rr[j].ReservedFor
+	})
+	return rr, nil
+}
+
+// releasable returns true if the reservation of t can still be released, that is t is unspent or
+// was spent by a tx that was never broadcast, such as one sent over a socket that was never acked.
+func (st *state) releasable(t txo) bool {
+	if !t.spent() {
+		return true
+	}
+	spending, ok := st.transactions[t.SpendingTxID.String]
+	return ok && !spending.State.Broadcast()
+}
+
+// UTXOs returns the txos matching args, ordered by creation date then outpoint.
+func (s *memoryStore) UTXOs(ctx context.Context, args payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+	st := s.view(ctx)
+	confirmed := map[string]bool{}
+	for id, p := range st.proofs {
+		if !p.StaleAt.Valid {
+			confirmed[id.txID] = true
+		}
+	}
+	resp := []payd.UTXODetail{}
+	for _, t := range st.txos {
+		d, ok := st.destinations[t.DestinationID]
+		if !ok {
+			continue
+		}
+		tx, ok := st.transactions[t.TxID]
+		if !ok {
+			continue
+		}
+		u := payd.UTXODetail{
+			Outpoint:       t.Outpoint,
+			TxID:           t.TxID,
+			Vout:           uint32(t.Vout),
+			Satoshis:       t.Satoshis,
+			LockingScript:  d.LockingScript,
+			DerivationPath: d.DerivationPath,
+			KeyName:        d.KeyName,
+			TxState:        tx.State,
+			Confirmed:      confirmed[t.TxID],
+			ReservedFor:    t.ReservedFor,
+			ReservedUntil:  t.ReservedUntil,
+			Frozen:         t.Frozen,
+			SpentAt:        t.SpentAt,
+			SpendingTxID:   t.SpendingTxID,
+			CreatedAt:      t.CreatedAt,
+		}
+		if args.Matches(u) {
+			resp = append(resp, u)
+		}
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		less := resp[i].CreatedAt.Before(resp[j].CreatedAt) ||
+			resp[i].CreatedAt.Equal(resp[j].CreatedAt) && resp[i].Outpoint < resp[j].Outpoint
+		if args.Descending() {
+			return !less
+		}
+		return less
+	})
+	if args.Limit > 0 && len(resp) > args.Limit {
+		resp = resp[:args.Limit]
+	}
+	return resp, nil
+}
+
+// UTXOFrozenUpdate will freeze or unfreeze unspent txos, if any aren't found none are updated.
+func (s *memoryStore) UTXOFrozenUpdate(ctx context.Context, req payd.UTXOFrozenUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to freeze utxos")
+	}
+	defer rollback(ctx, tx)
+	timestamp := time.Now().UTC()
+	for _, outpoint := range req.Outpoints {
+		t, ok := tx.st.txos[outpoint]
+		if !ok || t.spent() {
+			return lathos.NewErrNotFound(errcodes.ErrUTXONotFound, fmt.Sprintf("unspent txo %s not found", outpoint))
+		}
+		t.Frozen = req.Frozen
+		t.UpdatedAt = timestamp
+		tx.st.txos[outpoint] = t
+	}
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction to freeze utxos")
+}
+
+// UTXOSpend spends txs matching the provided reservation.
+func (s *memoryStore) UTXOSpend(ctx context.Context, req payd.UTXOSpend) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to mark utxos as spent")
+	}
+	defer rollback(ctx, tx)
+	req.Timestamp = time.Now().UTC()
+	for _, t := range tx.st.txos {
+		if !t.ReservedFor.Valid || t.ReservedFor.String != req.Reservation {
+			continue
+		}
+		t.SpentAt = null.TimeFrom(req.Timestamp)
+		t.SpendingTxID = null.StringFrom(req.SpendingTxID)
+		t.UpdatedAt = req.Timestamp
+		tx.st.txos[t.Outpoint] = t
+	}
+
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction for spending utxo")
+}
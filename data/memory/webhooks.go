@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+// webhook is the stored representation of a webhook, deleted webhooks are kept
+// so their deliveries can still be listed.
+type webhook struct {
+	payd.Webhook
+	DeletedAt null.Time
+}
+
+// Webhook will return a webhook matching args, deleted webhooks are not returned.
+func (s *memoryStore) Webhook(ctx context.Context, args payd.WebhookArgs) (*payd.Webhook, error) {
+	wh, err := s.view(ctx).webhook(args.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	return &wh.Webhook, nil
+}
+
+// Webhooks will return all webhooks that haven't been deleted, oldest first.
+func (s *memoryStore) Webhooks(ctx context.Context) ([]payd.Webhook, error) {
+	var ww []payd.Webhook
+	for _, wh := range s.view(ctx).webhooks {
+		if !wh.DeletedAt.Valid {
+			ww = append(ww, wh.Webhook)
+		}
+	}
+	sort.Slice(ww, func(i, j int) bool {
+		if ww[i].CreatedAt.Equal(ww[j].CreatedAt) {
+			return ww[i].ID < ww[j].ID
+		}
+		return ww[i].CreatedAt.Before(ww[j].CreatedAt)
+	})
+	return ww, nil
+}
+
+// WebhookCreate will persist a new webhook along with the events it subscribes to.
+func (s *memoryStore) WebhookCreate(ctx context.Context, req payd.WebhookCreate) (*payd.Webhook, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new webhook with webhookID %s", req.WebhookID)
+	}
+	defer rollback(ctx, tx)
+	if _, ok := tx.st.webhooks[req.WebhookID]; ok {
+		return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("webhook with webhookID %s already exists", req.WebhookID))
+	}
+	events := append([]payd.WebhookEvent{}, req.Events...)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i] < events[j]
+	})
+	for i := 1; i < len(events); i++ {
+		if events[i] == events[i-1] {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("webhook with webhookID %s has duplicate events", req.WebhookID))
+		}
+	}
+	wh := payd.Webhook{
+		ID:        req.WebhookID,
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Events:    events,
+		CreatedAt: req.CreatedAt,
+	}
+	tx.st.webhooks[wh.ID] = webhook{Webhook: wh}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating webhook with webhookID %s", req.WebhookID)
+	}
+	return &wh, nil
+}
+
+// WebhookDelete will soft delete a webhook, its deliveries are kept.
+func (s *memoryStore) WebhookDelete(ctx context.Context, args payd.WebhookArgs) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook with webhookID %s", args.WebhookID)
+	}
+	defer rollback(ctx, tx)
+	wh, err := tx.st.webhook(args.WebhookID)
+	if err != nil {
+		return err
+	}
+	wh.DeletedAt = null.TimeFrom(time.Now().UTC())
+	tx.st.webhooks[wh.ID] = wh
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when deleting webhook with webhookID %s", args.WebhookID)
+	}
+	return nil
+}
+
+// WebhookDelivery will return a delivery matching args.
+func (s *memoryStore) WebhookDelivery(ctx context.Context, args payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error) {
+	d, ok := s.view(ctx).webhookDeliveries[args.DeliveryID]
+	if !ok {
+		return nil, lathos.NewErrNotFound(errcodes.ErrWebhookDeliveryNotFound, fmt.Sprintf("webhook delivery with deliveryID %s not found", args.DeliveryID))
+	}
+	return &d, nil
+}
+
+// WebhookDeliveries returns the deliveries matching args, newest first.
+func (s *memoryStore) WebhookDeliveries(ctx context.Context, args payd.WebhookDeliveriesArgs) ([]payd.WebhookDelivery, error) {
+	dd := s.view(ctx).webhookDeliveriesWhere(func(d payd.WebhookDelivery) bool {
+		return (args.WebhookID == "" || d.WebhookID == args.WebhookID) &&
+			(args.State == "" || d.State == args.State)
+	}, func(a, b payd.WebhookDelivery) bool {
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID > b.ID
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+	if args.Limit > 0 && len(dd) > args.Limit {
+		dd = dd[:args.Limit]
+	}
+	return dd, nil
+}
+
+// WebhookDeliveriesDue returns the pending deliveries due to be attempted, ordered by their next attempt.
+func (s *memoryStore) WebhookDeliveriesDue(ctx context.Context, args payd.WebhookDeliveriesDueArgs) ([]payd.WebhookDelivery, error) {
+	st := s.view(ctx)
+	dd := st.webhookDeliveriesWhere(func(d payd.WebhookDelivery) bool {
+		if d.State != payd.StateWebhookDeliveryPending || d.NextAttemptAt.After(args.DueBefore) {
+			return false
+		}
+		_, err := st.webhook(d.WebhookID)
+		return err == nil
+	}, func(a, b payd.WebhookDelivery) bool {
+		if a.NextAttemptAt.Equal(b.NextAttemptAt) {
+			return a.ID < b.ID
+		}
+		return a.NextAttemptAt.Before(b.NextAttemptAt)
+	})
+	if args.Limit > 0 && len(dd) > args.Limit {
+		dd = dd[:args.Limit]
+	}
+	return dd, nil
+}
+
+// WebhookDeliveriesCreate will add deliveries to the outbox in the pending state.
+func (s *memoryStore) WebhookDeliveriesCreate(ctx context.Context, req []payd.WebhookDeliveryCreate) error {
+	if len(req) == 0 {
+		// nothing to store
+		return nil
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook deliveries")
+	}
+	defer rollback(ctx, tx)
+	for _, d := range req {
+		if _, ok := tx.st.webhookDeliveries[d.DeliveryID]; ok {
+			return lathos.NewErrDuplicate("D001", "webhook delivery already exists")
+		}
+		if _, ok := tx.st.webhooks[d.WebhookID]; !ok {
+			return lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", d.WebhookID))
+		}
+		tx.st.webhookDeliveries[d.DeliveryID] = payd.WebhookDelivery{
+			ID:            d.DeliveryID,
+			WebhookID:     d.WebhookID,
+			Event:         d.Event,
+			Payload:       append([]byte{}, d.Payload...),
+			State:         payd.StateWebhookDeliveryPending,
+			NextAttemptAt: d.NextAttemptAt.UTC(),
+			CreatedAt:     d.CreatedAt.UTC(),
+			UpdatedAt:     d.CreatedAt.UTC(),
+		}
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrap(err, "failed to commit transaction when creating webhook deliveries")
+	}
+	return nil
+}
+
+// WebhookDeliveryUpdate will update a delivery with the outcome of an attempt.
+func (s *memoryStore) WebhookDeliveryUpdate(ctx context.Context, args payd.WebhookDeliveryArgs, req payd.WebhookDeliveryUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	defer rollback(ctx, tx)
+	d, ok := tx.st.webhookDeliveries[args.DeliveryID]
+	if !ok {
+		return lathos.NewErrNotFound(errcodes.ErrWebhookDeliveryNotFound, fmt.Sprintf("webhook delivery with deliveryID %s not found", args.DeliveryID))
+	}
+	d.State = req.State
+	d.Attempts = req.Attempts
+	d.NextAttemptAt = req.NextAttemptAt.UTC()
+	d.LastError = req.LastError
+	d.DeliveredAt = req.DeliveredAt
+	d.UpdatedAt = req.UpdatedAt.UTC()
+	tx.st.webhookDeliveries[d.ID] = d
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when updating webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	return nil
+}
+
+// webhook returns a webhook that hasn't been deleted, or a not found error if it doesn't exist.
+func (s *state) webhook(webhookID string) (webhook, error) {
+	wh, ok := s.webhooks[webhookID]
+	if !ok || wh.DeletedAt.Valid {
+		return webhook{}, lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", webhookID))
+	}
+	return wh, nil
+}
+
+// webhookDeliveriesWhere returns the deliveries matching fn, sorted by less.
+func (s *state) webhookDeliveriesWhere(fn func(d payd.WebhookDelivery) bool, less func(a, b payd.WebhookDelivery) bool) []payd.WebhookDelivery {
+	var dd []payd.WebhookDelivery
+	for _, d := range s.webhookDeliveries {
+		if fn(d) {
+			dd = append(dd, d)
+		}
+	}
+	sort.Slice(dd, func(i, j int) bool {
+		return less(dd[i], dd[j])
+	})
+	return dd
+}
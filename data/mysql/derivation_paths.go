@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+const (
+	sqlDerivationPathExists = `
+	SELECT EXISTS(
+	    SELECT derivation_path FROM destinations WHERE derivation_path = ? AND key_name = ? AND user_id = ?
+	    )
+	`
+
+	sqlDerivationPaths = `
+	SELECT derivation_path, locking_script
+	FROM destinations
+	WHERE key_name = ? AND user_id = ?
+	ORDER BY derivation_path
+	`
+)
+
+// DerivationPathExists will return true / false if the supplied derivation path exists or not.
+func (s *mysqlStore) DerivationPathExists(ctx context.Context, args payd.DerivationExistsArgs) (bool, error) {
+	var exists bool
+	if err := s.db.GetContext(ctx, &exists, sqlDerivationPathExists, args.Path, args.KeyName, args.UserID); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return exists, nil
+}
+
+// DerivationPaths returns every path used to derive a destination from the key.
+func (s *mysqlStore) DerivationPaths(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+	resp := []payd.DerivationPath{}
+	if err := s.db.SelectContext(ctx, &resp, sqlDerivationPaths, args.Name, args.UserID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get derivation paths of key %s", args.Name)
+	}
+	return resp, nil
+}
@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+const (
+	sqlDestinationCreateFromMasterKey = `
+	INSERT INTO destinations (key_name, user_id, locking_script, derivation_path, satoshis, state)
+	VALUES(:key_name, :user_id, :locking_script, :derivation_path, :satoshis, 'pending')
+	`
+
+	sqlDestinationInvoiceCreate = `
+	INSERT INTO destination_invoice(destination_id, invoice_id)
+	VALUES(:destination_id, :invoice_id)
+	`
+
+	sqlDestinationsByScripts = `
+	SELECT destination_id, locking_script, derivation_path, satoshis, state
+	FROM destinations
+	WHERE locking_script IN(?)
+	`
+
+	sqlDestinationsByInvoiceID = `
+	SELECT d.destination_id, d.locking_script, d.derivation_path, d.satoshis, d.state,
+		COALESCE((SELECT SUM(t.satoshis) FROM txos t WHERE t.destination_id = d.destination_id), 0) AS satoshis_received
+	FROM destinations as d INNER JOIN destination_invoice as di ON d.destination_id = di.destination_id
+	WHERE di.invoice_id = ?
+	`
+)
+
+type dbOutput struct {
+	ID uint64 `db:"destination_id"`
+	// LockingScript is the P2PKH locking script used.
+	LockingScript string `db:"locking_script"`
+	Satoshis      uint64 `db:"satoshis"`
+	// DerivationPath is the deterministic path for this destination.
+	DerivationPath string `db:"derivation_path"`
+	// State will indicate if this destination is still waiting on a tx to fulfil it (pending)
+	// has been paid to in a tx (received) or has been deleted.
+	State string `db:"state"`
+	// SatoshisReceived is the total already paid to this destination.
+	SatoshisReceived uint64 `db:"satoshis_received"`
+}
+
+func (o dbOutput) toOutput() payd.Output {
+	s, _ := bscript.NewFromHexString(o.LockingScript)
+	return payd.Output{
+		ID:               o.ID,
+		LockingScript:    s,
+		Satoshis:         o.Satoshis,
+		DerivationPath:   o.DerivationPath,
+		State:            o.State,
+		SatoshisReceived: o.SatoshisReceived,
+	}
+}
+
+// DestinationsCreate will store a set of destinations, linking them to an invoice if one is supplied.
+func (s *mysqlStore) DestinationsCreate(ctx context.Context, args payd.DestinationsCreateArgs, req []payd.DestinationCreate) ([]payd.Output, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to setup sql transaction when adding destinations for invoice %s", args.InvoiceID.ValueOrZero())
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if err := handleNamedExec(tx, sqlDestinationCreateFromMasterKey, req); err != nil {
+		return nil, errors.Wrapf(err, "failed to insert payment destinations for invoiceID '%s' sqlDestinationCreateFromMasterKey", args.InvoiceID.ValueOrZero())
+	}
+	ll := make([]string, 0, len(req))
+	for _, d := range req {
+		ll = append(ll, d.Script)
+	}
+	query, sqlArgs, err := sqlx.In(sqlDestinationsByScripts, ll)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sql for getting destinations after creation")
+	}
+	var oo []dbOutput
+	if err := tx.SelectContext(ctx, &oo, tx.Rebind(query), sqlArgs...); err != nil {
+		return nil, errors.Wrap(err, "failed to read destinations after creation")
+	}
+	if len(oo) == 0 {
+		return nil, lathos.NewErrNotFound(errcodes.ErrDestinationsFailedCreate, "destinations not found, did the create fail?")
+	}
+	dd := make([]payd.Output, 0, len(oo))
+	for _, o := range oo {
+		dd = append(dd, o.toOutput())
+	}
+
+	// no invoice just return
+	if args.InvoiceID.IsZero() {
+		return dd, errors.Wrapf(commit(ctx, tx), "failed to commit transaction when creating payment destinations")
+	}
+
+	// add destinations and invoice reference
+	destInv := make([]struct {
+		DestinationID uint64 `db:"destination_id"`
+		InvoiceID     string `db:"invoice_id"`
+	}, 0, len(dd))
+	for _, d := range dd {
+		destInv = append(destInv, struct {
+			DestinationID uint64 `db:"destination_id"`
+			InvoiceID     string `db:"invoice_id"`
+		}{
+			DestinationID: d.ID,
+			InvoiceID:     args.InvoiceID.ValueOrZero(),
+		})
+	}
+	if err := handleNamedExec(tx, sqlDestinationInvoiceCreate, destInv); err != nil {
+		return nil, errors.Wrapf(err, "failed to create invoice for invoiceID '%s' sqlDestinationInvoiceCreate", args.InvoiceID.ValueOrZero())
+	}
+	return dd, errors.Wrapf(commit(ctx, tx), "failed to commit transaction when creating payment destinations")
+}
+
+// Destinations will return a set of destination outputs for a specific invoiceID.
+func (s *mysqlStore) Destinations(ctx context.Context, args payd.DestinationsArgs) ([]payd.Output, error) {
+	var oo []dbOutput
+	if err := s.db.SelectContext(ctx, &oo, sqlDestinationsByInvoiceID, args.InvoiceID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get destinations with invoiceID %s", args.InvoiceID)
+	}
+	if len(oo) == 0 {
+		return nil, lathos.NewErrNotFound(errcodes.ErrDestinationsNotFound, fmt.Sprintf("destinations with invoiceID %s not found", args.InvoiceID))
+	}
+	outs := make([]payd.Output, len(oo))
+	for i := 0; i < len(oo); i++ {
+		outs[i] = oo[i].toOutput()
+	}
+	return outs, nil
+}
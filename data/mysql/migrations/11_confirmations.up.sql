@@ -0,0 +1,5 @@
+-- the height of the block a transaction was mined in, set when its merkle proof is stored.
+ALTER TABLE transactions ADD COLUMN block_height BIGINT;
+-- transactions with a proof were treated as confirmed before the mined state was added.
+UPDATE transactions SET state = 'confirmed'
+WHERE state = 'broadcast' AND tx_id IN (SELECT tx_id FROM proofs);
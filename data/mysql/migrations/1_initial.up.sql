@@ -0,0 +1,186 @@
+-- required tables:
+-- keys            - to store all our extended private keys created
+-- paymentOutputs  - to store the outputs generated in PaymentRequests
+-- txos            - to store our outputs and note when they have been spent
+
+-- the userless user is seeded with an id of 0, stop mysql treating this as a request for the next id.
+SET SESSION sql_mode = CONCAT_WS(',', NULLIF(@@SESSION.sql_mode, ''), 'NO_AUTO_VALUE_ON_ZERO');
+
+CREATE TABLE users(
+    user_id         BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY
+    ,is_owner       BOOLEAN NOT NULL DEFAULT FALSE
+    ,name           VARCHAR(255) NOT NULL
+    ,avatar_url     VARCHAR(1024)
+    ,email          VARCHAR(255) NOT NULL
+    ,address        VARCHAR(1024)
+    ,phone_number   VARCHAR(64)
+) ENGINE=InnoDB;
+
+CREATE TABLE `keys` (
+    name        VARCHAR(255) NOT NULL
+    ,user_id    BIGINT UNSIGNED NOT NULL
+    ,xprv       VARCHAR(255) NOT NULL
+    ,created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT keys_user_name UNIQUE(user_id, name)
+) ENGINE=InnoDB;
+
+CREATE TABLE users_meta(
+    user_id         BIGINT UNSIGNED NOT NULL
+    ,`key`          VARCHAR(255) NOT NULL
+    ,value          VARCHAR(1024) NOT NULL
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT users_key UNIQUE(user_id, `key`)
+) ENGINE=InnoDB;
+
+CREATE TABLE users_peerchannels(
+    id                          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY
+    ,user_id                    BIGINT UNSIGNED
+    ,account_id                 BIGINT NOT NULL
+    ,user_name                  VARCHAR(255) NOT NULL
+    ,password                   VARCHAR(255) NOT NULL
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT user_id_key UNIQUE(user_id)
+    ,CONSTRAINT account_id_key UNIQUE(account_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE peerchannels(
+    id                          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY
+    ,peerchannels_account_id    BIGINT NOT NULL
+    ,channel_id                 VARCHAR(255) NOT NULL
+    ,channel_host               VARCHAR(255) NOT NULL
+    ,channel_path               VARCHAR(255) NOT NULL
+    ,channel_type               VARCHAR(32) NOT NULL
+    ,created_at                 DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,closed                     BOOLEAN NOT NULL DEFAULT FALSE
+    ,FOREIGN KEY (peerchannels_account_id) REFERENCES users_peerchannels(account_id)
+    ,CONSTRAINT channel_id_host_key UNIQUE(channel_id, channel_host)
+) ENGINE=InnoDB;
+
+CREATE TABLE peerchannels_toks(
+    id                          BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY
+    ,peerchannels_channel_id    VARCHAR(255) NOT NULL
+    ,tok                        VARCHAR(255) NOT NULL
+    ,role                       VARCHAR(32) NOT NULL
+    ,can_read                   BOOLEAN NOT NULL
+    ,can_write                  BOOLEAN NOT NULL
+    ,CONSTRAINT tok_key UNIQUE(tok)
+) ENGINE=InnoDB;
+
+CREATE TABLE invoices (
+    invoice_id              VARCHAR(64) PRIMARY KEY
+    ,satoshis               BIGINT UNSIGNED NOT NULL
+    ,payment_reference      VARCHAR(32)
+    ,description            VARCHAR(1024)
+    ,spv_required           BOOLEAN NOT NULL DEFAULT FALSE
+    ,expires_at             DATETIME(6)
+    ,payment_received_at    DATETIME(6)
+    ,refund_to              VARCHAR(1024)
+    ,refunded_at            DATETIME(6)
+    ,state                  VARCHAR(10)
+    ,created_at             DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at             DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,deleted_at             DATETIME(6)
+    ,INDEX idx_invoices_payment_reference (payment_reference)
+    ,INDEX idx_invoices_state (state)
+) ENGINE=InnoDB;
+
+CREATE TABLE fee_rates (
+    fee_rate_id     BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY
+    ,invoice_id     VARCHAR(64)
+    ,fee_json       TEXT
+    ,expires_at     DATETIME(6)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,CONSTRAINT fee_key UNIQUE(invoice_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE transactions (
+    tx_id               CHAR(64) NOT NULL PRIMARY KEY
+    ,tx_hex             LONGTEXT NOT NULL
+    ,state              VARCHAR(10) NOT NULL DEFAULT 'pending'
+    ,fail_reason        TEXT
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,deleted_at         DATETIME(6)
+) ENGINE=InnoDB;
+
+CREATE TABLE transaction_invoice (
+    tx_id               CHAR(64) NOT NULL
+    ,invoice_id         VARCHAR(64) NOT NULL
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE destinations(
+    destination_id   BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY
+    ,locking_script  VARCHAR(50) NOT NULL
+    ,satoshis        BIGINT UNSIGNED NOT NULL
+    ,derivation_path VARCHAR(255) NOT NULL
+    ,key_name        VARCHAR(255) NOT NULL DEFAULT 'masterkey'
+    ,user_id         BIGINT UNSIGNED NOT NULL
+    ,state           VARCHAR(10) NOT NULL
+    ,created_at      DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at      DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,deleted_at      DATETIME(6)
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT destinations_locking_script UNIQUE(locking_script)
+    ,INDEX idx_destinations_derivation_path (derivation_path)
+) ENGINE=InnoDB;
+
+CREATE TABLE destination_invoice(
+    destination_id  BIGINT UNSIGNED NOT NULL
+    ,invoice_id     VARCHAR(64) NOT NULL
+    ,FOREIGN KEY (destination_id) REFERENCES destinations(destination_id)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+) ENGINE=InnoDB;
+
+-- store unspent transactions
+CREATE TABLE txos (
+    outpoint        VARCHAR(80) PRIMARY KEY
+    ,destination_id BIGINT UNSIGNED
+    ,tx_id          CHAR(64)
+    ,vout           BIGINT UNSIGNED
+    ,spent_at       DATETIME(6) -- this is the date when YOU use the funds
+    ,spending_txid  CHAR(64) -- the txid where you'd spent this output
+    ,reserved_for   VARCHAR(255) -- the paymentId of this txo is being spent against
+    ,created_at     DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at     DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+    ,FOREIGN KEY (spending_txid) REFERENCES transactions(tx_id)
+    ,FOREIGN KEY (destination_id) REFERENCES destinations(destination_id)
+    ,INDEX idx_txos_reserved_for (reserved_for)
+) ENGINE=InnoDB;
+
+CREATE TABLE proofs(
+    blockhash           VARCHAR(255) NOT NULL
+    ,tx_id              CHAR(64) NOT NULL
+    ,data               LONGTEXT NOT NULL
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,PRIMARY KEY(blockhash, tx_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+) ENGINE=InnoDB;
+
+CREATE TABLE proof_callbacks(
+    invoice_id          VARCHAR(64) NOT NULL
+    ,url                VARCHAR(255) NOT NULL
+    ,token              VARCHAR(1024)
+    ,state              VARCHAR(32) NOT NULL
+    ,attempts           INT NOT NULL DEFAULT 0
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,PRIMARY KEY(invoice_id, url)
+) ENGINE=InnoDB;
+
+INSERT INTO users(user_id, name, is_owner, avatar_url, email, address, phone_number)
+VALUES(0, 'Userless', FALSE, '', 'user@example.com', '123 Street Fake', '123456789'),
+      (1, 'BitcoinSV LiteClient', TRUE, 'https://bitcoinassociation.net/wp-content/uploads/2019/09/2-bsv-logo-engtag-full.png', 'https://discord.gg/bsv', '1 BSV Avenue', '0800-123-456');
+
+INSERT INTO users_meta(user_id, `key`, value)
+VALUES(1, 'example key 1', 'example value 1'),
+      (1, 'example key 2', 'example value 2');
+
+INSERT INTO users_peerchannels(user_id, account_id, user_name, password)
+VALUES(0, 0, '', ''), -- userless, for receiving change proofs
+      (1, 1, 'username', 'password');
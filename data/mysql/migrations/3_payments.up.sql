@@ -0,0 +1,36 @@
+-- an invoice can be paid by many transactions, each one is recorded as a payment
+-- and added to the satoshis received until the invoice is paid in full.
+CREATE TABLE payments (
+    invoice_id          VARCHAR(64) NOT NULL
+    ,tx_id              CHAR(64) NOT NULL
+    ,satoshis           BIGINT UNSIGNED NOT NULL
+    ,refund_to          VARCHAR(1024)
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,PRIMARY KEY(invoice_id, tx_id)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+) ENGINE=InnoDB;
+
+ALTER TABLE invoices
+    ADD COLUMN satoshis_received BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    MODIFY COLUMN state VARCHAR(16);
+
+-- txos store their own value as instalments don't pay the full destination amount.
+ALTER TABLE txos ADD COLUMN satoshis BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+UPDATE txos t
+    INNER JOIN destinations d ON d.destination_id = t.destination_id
+SET t.satoshis = d.satoshis;
+
+-- invoices paid before now were paid in full by a single transaction.
+INSERT INTO payments(invoice_id, tx_id, satoshis, refund_to, created_at)
+SELECT i.invoice_id, ti.tx_id, i.satoshis, i.refund_to, COALESCE(i.payment_received_at, i.updated_at)
+FROM invoices i
+    INNER JOIN transaction_invoice ti ON ti.invoice_id = i.invoice_id
+    INNER JOIN transactions t ON t.tx_id = ti.tx_id
+WHERE i.state IN ('paid', 'refunded')
+  AND t.state = 'broadcast';
+
+UPDATE invoices
+SET satoshis_received = satoshis
+WHERE state IN ('paid', 'refunded');
@@ -0,0 +1,19 @@
+-- an invoice can be refunded in part many times, each refund transaction is recorded
+-- and added to the satoshis refunded until everything received has been paid back.
+CREATE TABLE refunds (
+    invoice_id          VARCHAR(64) NOT NULL
+    ,tx_id              CHAR(64) NOT NULL
+    ,satoshis           BIGINT UNSIGNED NOT NULL
+    ,refund_to          VARCHAR(1024) NOT NULL
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,PRIMARY KEY(invoice_id, tx_id)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+) ENGINE=InnoDB;
+
+ALTER TABLE invoices ADD COLUMN satoshis_refunded BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+-- invoices refunded before now were refunded in full.
+UPDATE invoices
+SET satoshis_refunded = satoshis_received
+WHERE state = 'refunded';
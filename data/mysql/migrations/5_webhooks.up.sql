@@ -0,0 +1,35 @@
+-- webhooks are merchant endpoints that are posted invoice and transaction lifecycle events.
+CREATE TABLE webhooks (
+    webhook_id          VARCHAR(36) NOT NULL PRIMARY KEY
+    ,url                VARCHAR(1024) NOT NULL
+    ,secret             VARCHAR(256) NOT NULL
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,deleted_at         DATETIME(6)
+) ENGINE=InnoDB;
+
+-- the events each webhook is subscribed to.
+CREATE TABLE webhook_events (
+    webhook_id          VARCHAR(36) NOT NULL
+    ,event              VARCHAR(32) NOT NULL
+    ,PRIMARY KEY(webhook_id, event)
+    ,FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id)
+) ENGINE=InnoDB;
+
+-- webhook_deliveries is an outbox, a delivery is written in the same transaction as the
+-- change raising the event and posted by a background worker until it succeeds or is dead lettered.
+CREATE TABLE webhook_deliveries (
+    delivery_id         VARCHAR(36) NOT NULL PRIMARY KEY
+    ,webhook_id         VARCHAR(36) NOT NULL
+    ,event              VARCHAR(32) NOT NULL
+    ,payload            MEDIUMBLOB NOT NULL
+    ,state              VARCHAR(32) NOT NULL DEFAULT 'pending'
+    ,attempts           INT NOT NULL DEFAULT 0
+    ,next_attempt_at    DATETIME(6) NOT NULL
+    ,last_error         VARCHAR(1024)
+    ,delivered_at       DATETIME(6)
+    ,created_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,updated_at         DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+    ,FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id)
+    ,INDEX idx_webhook_deliveries_due (state, next_attempt_at)
+    ,INDEX idx_webhook_deliveries_webhook (webhook_id, created_at)
+) ENGINE=InnoDB;
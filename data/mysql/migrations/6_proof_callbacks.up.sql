@@ -0,0 +1,24 @@
+-- proof callbacks are supplied with each payment so they are keyed by the transaction
+-- paying the invoice, once its proof is received the envelope is stored and posted to
+-- the callback by a background worker until it is sent or runs out of attempts.
+ALTER TABLE proof_callbacks
+    ADD COLUMN tx_id CHAR(64),
+    ADD COLUMN proof MEDIUMBLOB,
+    ADD COLUMN next_attempt_at DATETIME(6),
+    ADD COLUMN last_error VARCHAR(1024);
+
+-- callbacks stored before now belong to the single payment made against their invoice.
+UPDATE proof_callbacks pc
+    INNER JOIN payments p ON p.invoice_id = pc.invoice_id
+SET pc.tx_id = p.tx_id;
+
+DELETE FROM proof_callbacks WHERE tx_id IS NULL;
+
+ALTER TABLE proof_callbacks
+    MODIFY COLUMN tx_id CHAR(64) NOT NULL,
+    ADD INDEX idx_proof_callbacks_invoice (invoice_id),
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY(tx_id, url),
+    ADD FOREIGN KEY (tx_id) REFERENCES transactions(tx_id);
+
+CREATE INDEX idx_proof_callbacks_due ON proof_callbacks(state, next_attempt_at);
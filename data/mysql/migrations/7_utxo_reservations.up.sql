@@ -0,0 +1,5 @@
+-- reserved_until is when a reservation expires, expired reservations are released by a background job.
+ALTER TABLE txos ADD COLUMN reserved_until DATETIME(6);
+
+-- existing reservations never expired, they expire straight away so they are released.
+UPDATE txos SET reserved_until = updated_at WHERE reserved_for IS NOT NULL;
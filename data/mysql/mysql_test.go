@@ -0,0 +1,51 @@
+package mysql_test
+
+import (
+	"testing"
+
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/config/databases"
+	"github.com/libsv/payd/data"
+	"github.com/libsv/payd/data/mysql"
+	"github.com/libsv/payd/data/storetest"
+	"github.com/libsv/payd/log"
+)
+
+// envTestDsn is the connection string of a mysql database used to run the store
+// conformance tests, the tests are skipped when not set unless storetest.EnvRequired is.
+// The database is dropped and recreated before each test, never point this at a database in use.
+const envTestDsn = "PAYD_TEST_MYSQL_DSN"
+
+func TestMySQLStore(t *testing.T) {
+	dsn := storetest.DSN(t, envTestDsn)
+	cfg, err := driver.ParseDSN(dsn)
+	require.NoError(t, err)
+	storetest.Run(t, func(t *testing.T) (data.Store, payd.Transacter) {
+		wipeCfg := *cfg
+		wipeCfg.DBName = ""
+		wipe, err := sqlx.Open("mysql", wipeCfg.FormatDSN())
+		require.NoError(t, err)
+		_, err = wipe.Exec("DROP DATABASE IF EXISTS " + cfg.DBName)
+		require.NoError(t, err)
+		_, err = wipe.Exec("CREATE DATABASE " + cfg.DBName)
+		require.NoError(t, err)
+		require.NoError(t, wipe.Close())
+
+		db, err := databases.NewDbSetup().SetupDb(log.Noop{}, &config.Db{
+			Type:       config.DBMySQL,
+			Dsn:        dsn,
+			SchemaPath: "migrations",
+			MigrateDb:  true,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return mysql.NewMySQLStore(db), &mysql.Transacter{}
+	})
+}
@@ -0,0 +1,177 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+const (
+	sqlCallbackURLInsert = `
+	INSERT INTO proof_callbacks(invoice_id, tx_id, url, token, state)
+	VALUES(:invoice_id,:tx_id,:url,:token,'pending')
+	`
+
+	sqlProofCallbacks = `
+	SELECT invoice_id, tx_id, url, token, state, attempts, proof, next_attempt_at, last_error, created_at, updated_at
+	FROM proof_callbacks
+	WHERE invoice_id = ?
+	`
+
+	sqlProofCallbacksDue = `
+	SELECT invoice_id, tx_id, url, token, state, attempts, proof, next_attempt_at, last_error, created_at, updated_at
+	FROM proof_callbacks
+	WHERE state = 'pending' AND next_attempt_at <= ?
+	ORDER BY next_attempt_at, tx_id, url
+	`
+
+	sqlProofCallbacksQueue = `
+	UPDATE proof_callbacks
+	SET proof = :proof, state = 'pending', attempts = 0, next_attempt_at = :next_attempt_at, last_error = NULL,
+		updated_at = :updated_at
+	WHERE tx_id = :tx_id
+	`
+
+	sqlProofCallbackUpdate = `
+	UPDATE proof_callbacks
+	SET state = :state, attempts = :attempts, next_attempt_at = :next_attempt_at, last_error = :last_error,
+		updated_at = :updated_at
+	WHERE tx_id = :tx_id AND url = :url
+	`
+)
+
+type proofCallbackDTO struct {
+	InvoiceID string `db:"invoice_id"`
+	TxID      string `db:"tx_id"`
+	URL       string `db:"url"`
+	Token     string `db:"token"`
+}
+
+// ProofCallbacks returns the callbacks for an invoice, optionally only those for a single transaction.
+func (s *mysqlStore) ProofCallbacks(ctx context.Context, args payd.ProofCallbackArgs) ([]payd.ProofCallback, error) {
+	query := sqlProofCallbacks
+	params := []interface{}{args.InvoiceID}
+	if args.TxID != "" {
+		query += " AND tx_id = ?"
+		params = append(params, args.TxID)
+	}
+	query += " ORDER BY created_at, tx_id, url"
+	var resp []payd.ProofCallback
+	if err := s.db.SelectContext(ctx, &resp, query, params...); err != nil {
+		return nil, errors.Wrapf(err, "failed to get proof callbacks for invoiceID %s", args.InvoiceID)
+	}
+	return resp, nil
+}
+
+// ProofCallbacksDue returns the pending callbacks with a proof that are due to be sent, oldest first.
+func (s *mysqlStore) ProofCallbacksDue(ctx context.Context, args payd.ProofCallbacksDueArgs) ([]payd.ProofCallback, error) {
+	query := sqlProofCallbacksDue
+	params := []interface{}{args.DueBefore.UTC()}
+	if args.Limit > 0 {
+		query += " LIMIT ?"
+		params = append(params, args.Limit)
+	}
+	var resp []payd.ProofCallback
+	if err := s.db.SelectContext(ctx, &resp, query, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to get due proof callbacks")
+	}
+	return resp, nil
+}
+
+// ProofCallBacksCreate can be implemented to store merkle proof callback urls for a payment.
+func (s *mysqlStore) ProofCallBacksCreate(ctx context.Context, args payd.ProofCallbackArgs, req map[string]dpp.ProofCallback) error {
+	if len(req) == 0 {
+		// nothing to store
+		return nil
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create new callback urls for invoiceID %s", args.InvoiceID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+
+	cc := make([]proofCallbackDTO, 0, len(req))
+	for url, val := range req {
+		cc = append(cc, proofCallbackDTO{
+			InvoiceID: args.InvoiceID,
+			TxID:      args.TxID,
+			URL:       url,
+			Token:     val.Token,
+		})
+	}
+	if err := handleNamedExec(tx, sqlCallbackURLInsert, cc); err != nil {
+		return errors.Wrapf(err, "failed to insert callback urls for invoiceID %s", args.InvoiceID)
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when creating callback urls with invoiceID %s", args.InvoiceID)
+	}
+	return nil
+}
+
+// ProofCallbacksQueue will store the proof for each callback of a transaction and queue them to be sent.
+func (s *mysqlStore) ProofCallbacksQueue(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to queue proof callbacks for txID %s", args.TxID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if _, err := tx.NamedExec(sqlProofCallbacksQueue, map[string]interface{}{
+		"proof":           []byte(req.Proof),
+		"next_attempt_at": req.NextAttemptAt.UTC(),
+		"updated_at":      req.UpdatedAt.UTC(),
+		"tx_id":           args.TxID,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to queue proof callbacks for txID %s", args.TxID)
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when queueing proof callbacks for txID %s", args.TxID)
+	}
+	return nil
+}
+
+// ProofCallbackUpdate will record the outcome of sending a proof callback.
+func (s *mysqlStore) ProofCallbackUpdate(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if req.NextAttemptAt.Valid {
+		req.NextAttemptAt.Time = req.NextAttemptAt.Time.UTC()
+	}
+	res, err := tx.NamedExec(sqlProofCallbackUpdate, map[string]interface{}{
+		"state":           req.State,
+		"attempts":        req.Attempts,
+		"next_attempt_at": req.NextAttemptAt,
+		"last_error":      req.LastError,
+		"updated_at":      req.UpdatedAt.UTC(),
+		"tx_id":           args.TxID,
+		"url":             args.URL,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	if rows == 0 {
+		return lathos.NewErrNotFound(errcodes.ErrProofCallbackNotFound, fmt.Sprintf("proof callback %s for txID %s not found", args.URL, args.TxID))
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when updating proof callback %s for txID %s", args.URL, args.TxID)
+	}
+	return nil
+}
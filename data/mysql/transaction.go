@@ -0,0 +1,245 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+const (
+	sqlTransactionCreate = `
+		INSERT INTO transactions(tx_id, tx_hex)
+		VALUES(:tx_id, :tx_hex)
+	`
+
+	sqlTransactionInvoiceCreate = `
+		INSERT INTO transaction_invoice(tx_id, invoice_id)
+		VALUES(:tx_id, :invoice_id)
+	`
+
+	sqlTxoCreate = `
+		INSERT INTO txos(outpoint, destination_id, tx_id, vout, satoshis)
+		VALUES(:outpoint, :destination_id, :tx_id, :vout, :satoshis)
+	`
+
+	sqlDestinationSetReceived = `
+		UPDATE destinations
+		SET state = 'received', updated_at = ?
+		WHERE destination_id IN(?)
+	`
+
+	// block_height is kept unless the update sets it and is cleared when the tx isn't mined.
+	sqlTransactionUpdateState = `
+		UPDATE transactions
+		SET state = ?, fail_reason = ?,
+			block_height = CASE WHEN ? IN ('mined', 'confirmed') THEN COALESCE(?, block_height) END,
+			updated_at = ?
+		WHERE tx_id = ?
+	`
+
+	sqlTransactionProofExists = `
+		SELECT EXISTS(SELECT 1 FROM proofs WHERE tx_id = ? AND stale_at IS NULL)
+	`
+
+	sqlTransactionsConfirm = `
+		UPDATE transactions
+		SET state = 'confirmed', updated_at = ?
+		WHERE state = 'mined' AND block_height <= ?
+	`
+
+	sqlTransactionGet = `
+	SELECT tx_hex
+	FROM transactions
+	WHERE tx_id = ?
+	`
+
+	sqlTransactions = `
+	SELECT tx.tx_id, tx.tx_hex, tx.state, tx.fail_reason, tx.created_at,
+		COALESCE((SELECT SUM(t.satoshis) FROM txos t WHERE t.tx_id = tx.tx_id), 0) AS received,
+		COALESCE((SELECT SUM(t.satoshis) FROM txos t WHERE t.spending_txid = tx.tx_id), 0) AS spent,
+		(SELECT MIN(t.reserved_for) FROM txos t WHERE t.spending_txid = tx.tx_id) AS paid_to,
+		COALESCE((SELECT MIN(ti.invoice_id) FROM transaction_invoice ti WHERE ti.tx_id = tx.tx_id),
+			(SELECT MIN(r.invoice_id) FROM refunds r WHERE r.tx_id = tx.tx_id)) AS invoice_id,
+		EXISTS(SELECT 1 FROM proofs p WHERE p.tx_id = tx.tx_id AND p.stale_at IS NULL) AS confirmed
+	FROM transactions tx
+	WHERE tx.deleted_at IS NULL
+	`
+)
+
+// TransactionCreate will store a transaction and its txos in the data base.
+func (s *mysqlStore) TransactionCreate(ctx context.Context, req payd.TransactionCreate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to start transaction when inserting transaction to db")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	timestamp := time.Now().UTC()
+	// insert tx and utxos
+	if err := handleNamedExec(tx, sqlTransactionCreate, req); err != nil {
+		if isUniqueViolation(err) {
+			return lathos.NewErrDuplicate("D001", "transaction has already been stored")
+		}
+		return errors.Wrap(err, "failed to insert new transaction")
+	}
+
+	if err = s.insertOutputs(ctx, tx, timestamp, req); err != nil {
+		return err
+	}
+
+	// If no invoice id was provided, end here as this is a change tx.
+	// The payment itself is recorded against the invoice by InvoiceUpdate once the tx is broadcast.
+	if req.InvoiceID == "" {
+		return errors.Wrapf(commit(ctx, tx),
+			"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
+	}
+
+	if err = handleNamedExec(tx, sqlTransactionInvoiceCreate, req); err != nil {
+		return errors.Wrapf(err, "failed to create invoice mapping for tx %s invoice %s", req.TxID, req.InvoiceID)
+	}
+
+	return errors.Wrapf(commit(ctx, tx),
+		"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
+}
+
+func (s *mysqlStore) insertOutputs(ctx context.Context, tx *sqlx.Tx, timestamp time.Time, req payd.TransactionCreate) error {
+	// Only write outputs if they exist. This can happen in the case of a tx being funded an
+	// exact amount, causing no change output to be created.
+	if len(req.Outputs) == 0 {
+		return nil
+	}
+
+	if err := handleNamedExec(tx, sqlTxoCreate, req.Outputs); err != nil {
+		return errors.Wrap(err, "failed to insert transaction outputs")
+	}
+
+	ll := make([]uint64, 0, len(req.Outputs))
+	for _, d := range req.Outputs {
+		ll = append(ll, d.DestinationID)
+	}
+	query, sqlArgs, err := sqlx.In(sqlDestinationSetReceived, timestamp, ll)
+	if err != nil {
+		return errors.Wrap(err, "failed to create sql for updating destination state")
+	}
+	result, err := tx.ExecContext(ctx, tx.Rebind(query), sqlArgs...)
+	if err != nil {
+		return errors.Wrap(err, "failed to update destinations state to received")
+	}
+	return errors.Wrap(handleExecRows(result), "failed to update destinations state to received")
+}
+
+// TransactionUpdateState will update a transactions internal state.
+func (s *mysqlStore) TransactionUpdateState(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to start transaction when updating transaction state")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	// a tx with a proof on the best chain only goes back to broadcast once its proofs are stale.
+	if req.State != payd.StateTxMined && req.State != payd.StateTxConfirmed {
+		var mined bool
+		if err := tx.GetContext(ctx, &mined, sqlTransactionProofExists, args.TxID); err != nil {
+			return errors.Wrapf(err, "failed to check proofs of transactionId '%s'", args.TxID)
+		}
+		if mined {
+			return lathos.NewErrUnprocessable(errcodes.ErrTxStateMined,
+				fmt.Sprintf("tx '%s' is mined and can't be set %s", args.TxID, req.State))
+		}
+	}
+	result, err := tx.ExecContext(ctx, sqlTransactionUpdateState, req.State, req.FailReason, req.State, req.BlockHeight, time.Now().UTC(), args.TxID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update transactionId '%s' state to '%s'", args.TxID, req.State)
+	}
+	if err := handleExecRows(result); err != nil {
+		return errors.Wrapf(err, "failed to update transactionId '%s' state to '%s'", args.TxID, req.State)
+	}
+	return errors.Wrapf(commit(ctx, tx),
+		"failed to commit transaction when updating transactionId '%s' state to '%s'", args.TxID, req.State)
+}
+
+// TransactionsConfirm will set mined transactions at or below the max block height to confirmed.
+func (s *mysqlStore) TransactionsConfirm(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error) {
+	result, err := s.db.ExecContext(ctx, sqlTransactionsConfirm, time.Now().UTC(), args.MaxBlockHeight)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to confirm transactions mined at or below height %d", args.MaxBlockHeight)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read confirmed transactions count")
+	}
+	return int(rows), nil
+}
+
+// Tx returns a tx from the internal store.
+func (s *mysqlStore) Tx(ctx context.Context, txID string) (*bt.Tx, error) {
+	var txhex struct {
+		TxHex string `db:"tx_hex"`
+	}
+	if err := s.db.GetContext(ctx, &txhex, sqlTransactionGet, txID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lathos.NewErrNotFound(errcodes.ErrTxNotFound, fmt.Sprintf("tx '%s' not in store", txID))
+		}
+		return nil, errors.Wrapf(err, "failed to retrieve transaction for id %s", txID)
+	}
+
+	return bt.NewTxFromString(txhex.TxHex)
+}
+
+// Transactions returns the txs matching args, ordered by creation date then id, with
+// the wallet txos they received and spent totalled.
+func (s *mysqlStore) Transactions(ctx context.Context, args payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+	query, params := transactionSearch(args)
+	resp := []payd.TransactionHistory{}
+	if err := s.db.SelectContext(ctx, &resp, query, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to get transactions")
+	}
+	return resp, nil
+}
+
+// transactionSearch builds the query and params to find the txs matching args.
+func transactionSearch(args payd.TransactionSearchArgs) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString(sqlTransactions)
+	var params []interface{}
+	where := func(clause string, vv ...interface{}) {
+		sb.WriteString(" AND " + clause)
+		params = append(params, vv...)
+	}
+	if args.State != "" {
+		where("tx.state = ?", string(args.State))
+	}
+	if args.CreatedFrom.Valid {
+		where("tx.created_at >= ?", args.CreatedFrom.Time.UTC())
+	}
+	if args.CreatedTo.Valid {
+		where("tx.created_at < ?", args.CreatedTo.Time.UTC())
+	}
+	order, cmp := "ASC", ">"
+	if args.Descending() {
+		order, cmp = "DESC", "<"
+	}
+	if !args.Cursor.IsZero() {
+		createdAt := args.Cursor.CreatedAt.UTC()
+		where(fmt.Sprintf("(tx.created_at %s ? OR (tx.created_at = ? AND tx.tx_id %s ?))", cmp, cmp),
+			createdAt, createdAt, args.Cursor.TxID)
+	}
+	fmt.Fprintf(&sb, " ORDER BY tx.created_at %s, tx.tx_id %s", order, order)
+	if args.Limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		params = append(params, args.Limit)
+	}
+	return sb.String(), params
+}
@@ -0,0 +1,152 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	lerrs "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+)
+
+const (
+	sqlCreateUser = `
+		INSERT INTO users(name, is_owner, avatar_url, email, address, phone_number)
+		VALUES(?, FALSE, ?, ?, ?, ?)
+	`
+
+	sqlGetUserByID = `
+		SELECT u.user_id, u.name, u.avatar_url, u.email, u.phone_number, u.address
+		FROM users u
+		JOIN ` + "`keys`" + ` k ON u.user_id = k.user_id
+		WHERE k.user_id = ?
+	`
+
+	sqlDeleteUserByID = `
+		DELETE FROM users
+		WHERE user_id = :user_id
+	`
+
+	sqlGetUserMetaByID = `
+		SELECT ` + "`key`" + `, value FROM users_meta where user_id = ?
+	`
+
+	sqlCreateUserMeta = `INSERT INTO users_meta(user_id, ` + "`key`" + `, value) VALUES (:user_id, :key, :value)`
+)
+
+// userMeta is the struct for meta data table.
+type userMeta struct {
+	UserID uint64 `db:"user_id"`
+	Key    string `db:"key"`
+	Value  string `db:"value"`
+}
+
+// CreateUser creates a new user in the system.
+func (s *mysqlStore) CreateUser(ctx context.Context, req payd.CreateUserArgs, pks payd.PrivateKeyService) (*payd.CreateUserResponse, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new user for: %s", req.Name)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+
+	res, err := tx.ExecContext(ctx, sqlCreateUser, req.Name, req.Avatar, req.Email, req.Address, req.PhoneNumber)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new user: %s", req.Name)
+	}
+	// mysql has no RETURNING clause, read the id generated by the insert instead.
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read id of new user: %s", req.Name)
+	}
+	resp := payd.CreateUserResponse{ID: uint64(id)}
+
+	if len(req.ExtendedData) > 0 {
+		meta := make([]userMeta, 0, len(req.ExtendedData))
+		for k, v := range req.ExtendedData {
+			meta = append(meta, userMeta{
+				UserID: resp.ID,
+				Key:    k,
+				Value:  fmt.Sprintf("%v", v),
+			})
+		}
+		if _, err = tx.NamedExec(sqlCreateUserMeta, meta); err != nil {
+			return nil, errors.Wrapf(err, "failed to create new user meta data: %s", req.Name)
+		}
+	}
+
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating new user: %s", req.Name)
+	}
+
+	// Create a new xpriv for this new user
+	if err = pks.Create(ctx, "masterkey", resp.ID); err != nil {
+		return nil, errors.Wrap(err, "failed to create new xkey")
+	}
+	return &resp, nil
+}
+
+// ReadUser will return a user, they must have a master key.
+func (s *mysqlStore) ReadUser(ctx context.Context, userID uint64) (*payd.User, error) {
+	var data struct {
+		ID          uint64         `db:"user_id"`
+		Name        string         `db:"name"`
+		Email       string         `db:"email"`
+		Avatar      sql.NullString `db:"avatar_url"`
+		Address     sql.NullString `db:"address"`
+		PhoneNumber sql.NullString `db:"phone_number"`
+	}
+
+	if err := s.db.GetContext(ctx, &data, sqlGetUserByID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lerrs.NewErrNotFound("N004", "failed to get wallet owner: "+err.Error())
+		}
+		return nil, errors.Wrapf(err, "failed to get user %d", userID)
+	}
+
+	meta := make([]struct {
+		Key   string `db:"key"`
+		Value string `db:"value"`
+	}, 0)
+
+	if err := s.db.SelectContext(ctx, &meta, sqlGetUserMetaByID, userID); err != nil {
+		return nil, errors.Wrap(err, "failed to get wallet owner extended info")
+	}
+
+	user := payd.User{
+		ID:           data.ID,
+		Name:         data.Name,
+		Email:        data.Email,
+		Avatar:       data.Avatar.String,
+		Address:      data.Address.String,
+		PhoneNumber:  data.PhoneNumber.String,
+		ExtendedData: make(map[string]interface{}),
+	}
+
+	for _, v := range meta {
+		user.ExtendedData[v.Key] = v.Value
+	}
+
+	return &user, nil
+}
+
+// UpdateUser is not currently supported.
+func (s *mysqlStore) UpdateUser(ctx context.Context, ID uint64, d payd.User) (*payd.User, error) {
+	return nil, nil
+}
+
+// DeleteUser will remove a user from the store.
+func (s *mysqlStore) DeleteUser(ctx context.Context, userID uint64) error {
+	data := struct {
+		ID uint64 `db:"user_id"`
+	}{
+		ID: userID,
+	}
+	if _, err := s.db.NamedExecContext(ctx, sqlDeleteUserByID, data); err != nil {
+		return errors.Wrapf(err, "failed to delete user %d", userID)
+	}
+	return nil
+}
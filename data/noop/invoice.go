@@ -25,7 +25,7 @@ func (i *invoice) Invoice(ctx context.Context, args payd.InvoiceArgs) (*payd.Inv
 }
 
 // Invoice will return an invoice that matches the provided args.
-func (i *invoice) Invoices(ctx context.Context) ([]payd.Invoice, error) {
+func (i *invoice) Invoices(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
 	return []payd.Invoice{{
 		ID:       "noop-abc123",
 		Satoshis: 10000,
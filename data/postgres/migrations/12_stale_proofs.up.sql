@@ -0,0 +1,2 @@
+-- set when the block of a proof is orphaned by a reorg, a stale proof no longer proves its tx is mined.
+ALTER TABLE proofs ADD COLUMN stale_at TIMESTAMPTZ;
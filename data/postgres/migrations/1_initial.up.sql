@@ -0,0 +1,189 @@
+-- required tables:
+-- keys            - to store all our extended private keys created
+-- paymentOutputs  - to store the outputs generated in PaymentRequests
+-- txos            - to store our outputs and note when they have been spent
+
+CREATE TABLE users(
+    user_id         BIGSERIAL PRIMARY KEY
+    ,is_owner       BOOLEAN NOT NULL DEFAULT FALSE
+    ,name           VARCHAR NOT NULL
+    ,avatar_url     VARCHAR
+    ,email          VARCHAR NOT NULL
+    ,address        VARCHAR
+    ,phone_number   VARCHAR
+);
+
+CREATE TABLE keys (
+    name        VARCHAR NOT NULL
+    ,user_id    BIGINT NOT NULL
+    ,xprv       VARCHAR NOT NULL
+    ,created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT keys_user_name UNIQUE(user_id, name)
+);
+
+CREATE TABLE users_meta(
+    user_id         BIGINT NOT NULL
+    ,key            VARCHAR NOT NULL
+    ,value          VARCHAR NOT NULL
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT users_key UNIQUE(user_id, key)
+);
+
+CREATE TABLE users_peerchannels(
+    id                          BIGSERIAL PRIMARY KEY
+    ,user_id                    BIGINT
+    ,account_id                 BIGINT NOT NULL
+    ,user_name                  VARCHAR NOT NULL
+    ,password                   VARCHAR NOT NULL
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT user_id_key UNIQUE(user_id)
+    ,CONSTRAINT account_id_key UNIQUE(account_id)
+);
+
+CREATE TABLE peerchannels(
+    id                          BIGSERIAL PRIMARY KEY
+    ,peerchannels_account_id    BIGINT NOT NULL
+    ,channel_id                 VARCHAR NOT NULL
+    ,channel_host               VARCHAR NOT NULL
+    ,channel_path               VARCHAR NOT NULL
+    ,channel_type               VARCHAR NOT NULL
+    ,created_at                 TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,closed                     BOOLEAN NOT NULL DEFAULT FALSE
+    ,FOREIGN KEY (peerchannels_account_id) REFERENCES users_peerchannels(account_id)
+    ,CONSTRAINT channel_id_host_key UNIQUE(channel_id, channel_host)
+);
+
+CREATE TABLE peerchannels_toks(
+    id                          BIGSERIAL PRIMARY KEY
+    ,peerchannels_channel_id    VARCHAR NOT NULL
+    ,tok                        VARCHAR NOT NULL
+    ,role                       VARCHAR NOT NULL
+    ,can_read                   BOOLEAN NOT NULL
+    ,can_write                  BOOLEAN NOT NULL
+    ,CONSTRAINT tok_key UNIQUE(tok)
+);
+
+CREATE TABLE invoices (
+    invoice_id              VARCHAR PRIMARY KEY
+    ,satoshis               BIGINT NOT NULL
+    ,payment_reference      VARCHAR(32)
+    ,description            VARCHAR(1024)
+    ,spv_required           BOOLEAN NOT NULL DEFAULT FALSE
+    ,expires_at             TIMESTAMPTZ
+    ,payment_received_at    TIMESTAMPTZ
+    ,refund_to              VARCHAR
+    ,refunded_at            TIMESTAMPTZ
+    ,state                  VARCHAR(10)
+    ,created_at             TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at             TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,deleted_at             TIMESTAMPTZ
+);
+
+CREATE INDEX idx_invoices_payment_reference ON invoices (payment_reference);
+CREATE INDEX idx_invoices_state ON invoices (state);
+
+CREATE TABLE fee_rates (
+    fee_rate_id     BIGSERIAL PRIMARY KEY
+    ,invoice_id     VARCHAR
+    ,fee_json       TEXT
+    ,expires_at     TIMESTAMPTZ
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,CONSTRAINT fee_key UNIQUE(invoice_id)
+);
+
+CREATE TABLE transactions (
+    tx_id               CHAR(64) NOT NULL PRIMARY KEY
+    ,tx_hex             TEXT NOT NULL
+    ,state              VARCHAR(10) NOT NULL DEFAULT 'pending'
+    ,fail_reason        TEXT
+    ,created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,deleted_at         TIMESTAMPTZ
+);
+
+CREATE TABLE transaction_invoice (
+    tx_id               CHAR(64) NOT NULL
+    ,invoice_id         VARCHAR NOT NULL
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+);
+
+CREATE TABLE destinations(
+    destination_id   BIGSERIAL PRIMARY KEY
+    ,locking_script  VARCHAR(50) NOT NULL
+    ,satoshis        BIGINT NOT NULL
+    ,derivation_path TEXT NOT NULL
+    ,key_name        VARCHAR NOT NULL DEFAULT 'masterkey'
+    ,user_id         BIGINT NOT NULL
+    ,state           VARCHAR(10) NOT NULL
+    ,created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,deleted_at      TIMESTAMPTZ
+    ,FOREIGN KEY (user_id) REFERENCES users(user_id)
+    ,CONSTRAINT destinations_locking_script UNIQUE(locking_script)
+);
+
+CREATE INDEX idx_destinations_derivation_path ON destinations (derivation_path);
+
+CREATE TABLE destination_invoice(
+    destination_id  BIGINT NOT NULL
+    ,invoice_id     VARCHAR NOT NULL
+    ,FOREIGN KEY (destination_id) REFERENCES destinations(destination_id)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+);
+
+-- store unspent transactions
+CREATE TABLE txos (
+    outpoint        VARCHAR PRIMARY KEY
+    ,destination_id BIGINT
+    ,tx_id          CHAR(64)
+    ,vout           BIGINT
+    ,spent_at       TIMESTAMPTZ -- this is the date when YOU use the funds
+    ,spending_txid  CHAR(64) -- the txid where you'd spent this output
+    ,reserved_for   VARCHAR -- the paymentId of this txo is being spent against
+    ,created_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+    ,FOREIGN KEY (spending_txid) REFERENCES transactions(tx_id)
+    ,FOREIGN KEY (destination_id) REFERENCES destinations(destination_id)
+);
+
+CREATE INDEX idx_txos_reserved_for ON txos (reserved_for);
+
+CREATE TABLE proofs(
+    blockhash           VARCHAR(255) NOT NULL
+    ,tx_id              VARCHAR(64) NOT NULL
+    ,data               TEXT NOT NULL
+    ,created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY(blockhash, tx_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+);
+
+CREATE TABLE proof_callbacks(
+    invoice_id          VARCHAR NOT NULL
+    ,url                VARCHAR NOT NULL
+    ,token              VARCHAR
+    ,state              VARCHAR NOT NULL
+    ,attempts           INTEGER NOT NULL DEFAULT 0
+    ,created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,PRIMARY KEY(invoice_id,url)
+);
+
+INSERT INTO users(user_id, name, is_owner, avatar_url, email, address, phone_number)
+VALUES(0, 'Userless', FALSE, '', 'user@example.com', '123 Street Fake', '123456789'),
+      (1, 'BitcoinSV LiteClient', TRUE, 'https://bitcoinassociation.net/wp-content/uploads/2019/09/2-bsv-logo-engtag-full.png', 'https://discord.gg/bsv', '1 BSV Avenue', '0800-123-456');
+
+-- user ids were inserted explicitly, move the sequence on so new users don't clash.
+SELECT setval('users_user_id_seq', (SELECT MAX(user_id) FROM users));
+
+INSERT INTO users_meta(user_id, key, value)
+VALUES(1, 'example key 1', 'example value 1'),
+      (1, 'example key 2', 'example value 2');
+
+INSERT INTO users_peerchannels(user_id, account_id, user_name, password)
+VALUES(0, 0, '', ''), -- userless, for receiving change proofs
+      (1, 1, 'username', 'password');
@@ -0,0 +1,19 @@
+-- an invoice can be refunded in part many times, each refund transaction is recorded
+-- and added to the satoshis refunded until everything received has been paid back.
+CREATE TABLE refunds (
+    invoice_id          VARCHAR NOT NULL
+    ,tx_id              CHAR(64) NOT NULL
+    ,satoshis           BIGINT NOT NULL
+    ,refund_to          VARCHAR NOT NULL
+    ,created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY(invoice_id, tx_id)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+);
+
+ALTER TABLE invoices ADD COLUMN satoshis_refunded BIGINT NOT NULL DEFAULT 0;
+
+-- invoices refunded before now were refunded in full.
+UPDATE invoices
+SET satoshis_refunded = satoshis_received
+WHERE state = 'refunded';
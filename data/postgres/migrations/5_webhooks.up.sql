@@ -0,0 +1,36 @@
+-- webhooks are merchant endpoints that are posted invoice and transaction lifecycle events.
+CREATE TABLE webhooks (
+    webhook_id          VARCHAR NOT NULL PRIMARY KEY
+    ,url                VARCHAR NOT NULL
+    ,secret             VARCHAR NOT NULL
+    ,created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,deleted_at         TIMESTAMPTZ
+);
+
+-- the events each webhook is subscribed to.
+CREATE TABLE webhook_events (
+    webhook_id          VARCHAR NOT NULL
+    ,event              VARCHAR NOT NULL
+    ,PRIMARY KEY(webhook_id, event)
+    ,FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id)
+);
+
+-- webhook_deliveries is an outbox, a delivery is written in the same transaction as the
+-- change raising the event and posted by a background worker until it succeeds or is dead lettered.
+CREATE TABLE webhook_deliveries (
+    delivery_id         VARCHAR NOT NULL PRIMARY KEY
+    ,webhook_id         VARCHAR NOT NULL
+    ,event              VARCHAR NOT NULL
+    ,payload            BYTEA NOT NULL
+    ,state              VARCHAR NOT NULL DEFAULT 'pending'
+    ,attempts           INTEGER NOT NULL DEFAULT 0
+    ,next_attempt_at    TIMESTAMPTZ NOT NULL
+    ,last_error         VARCHAR
+    ,delivered_at       TIMESTAMPTZ
+    ,created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+    ,FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(state, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
@@ -0,0 +1,24 @@
+-- proof callbacks are supplied with each payment so they are keyed by the transaction
+-- paying the invoice, once its proof is received the envelope is stored and posted to
+-- the callback by a background worker until it is sent or runs out of attempts.
+ALTER TABLE proof_callbacks
+    ADD COLUMN tx_id CHAR(64),
+    ADD COLUMN proof BYTEA,
+    ADD COLUMN next_attempt_at TIMESTAMPTZ,
+    ADD COLUMN last_error VARCHAR;
+
+-- callbacks stored before now belong to the single payment made against their invoice.
+UPDATE proof_callbacks
+SET tx_id = p.tx_id
+FROM payments p
+WHERE p.invoice_id = proof_callbacks.invoice_id;
+
+DELETE FROM proof_callbacks WHERE tx_id IS NULL;
+
+ALTER TABLE proof_callbacks
+    ALTER COLUMN tx_id SET NOT NULL,
+    DROP CONSTRAINT proof_callbacks_pkey,
+    ADD PRIMARY KEY(tx_id, url),
+    ADD FOREIGN KEY (tx_id) REFERENCES transactions(tx_id);
+
+CREATE INDEX idx_proof_callbacks_due ON proof_callbacks(state, next_attempt_at);
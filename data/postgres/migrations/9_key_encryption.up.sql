@@ -0,0 +1,2 @@
+-- encrypted keys hold the xpub and the sealed xprv so need more room than a plain xprv.
+ALTER TABLE keys ALTER COLUMN xprv TYPE VARCHAR;
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// pqUniqueViolation is the postgres error code returned when a unique constraint is violated.
+const pqUniqueViolation = "23505"
+
+type postgresStore struct {
+	db *sqlx.DB
+}
+
+// NewPostgresStore will setup and return a postgres data store.
+func NewPostgresStore(db *sqlx.DB) *postgresStore {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) newTx(ctx context.Context) (*sqlx.Tx, error) {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil {
+		if ctxx.Tx == nil {
+			t, err := s.db.BeginTxx(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			ctxx.Tx = t
+		}
+		return ctxx.Tx, nil
+	}
+	return s.db.BeginTxx(ctx, nil)
+}
+
+// commit a transaction, if there is a context based tx
+// this will not commit - we wait on the context to close it.
+func commit(ctx context.Context, tx *sqlx.Tx) error {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil {
+		if ctxx.Tx != nil {
+			return nil
+		}
+	}
+	return tx.Commit()
+}
+
+// rollback a transaction, if there is a context based tx
+// this will not rollback - we wait on the context to close it.
+func rollback(ctx context.Context, tx *sqlx.Tx) error {
+	ctxx := TxFromContext(ctx)
+	if ctxx != nil {
+		if ctxx.Tx != nil {
+			return nil
+		}
+	}
+	return tx.Rollback()
+}
+
+func handleNamedExec(tx db, sql string, args interface{}) error {
+	res, err := tx.NamedExec(sql, args)
+	if err != nil {
+		return errors.Wrap(err, "failed to run exec")
+	}
+	return handleExecRows(res)
+}
+
+func handleExecRows(res sql.Result) error {
+	ra, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to read rows affected")
+	}
+	if ra <= 0 {
+		return errors.Wrap(err, "exec did not affect rows")
+	}
+	return nil
+}
+
+// isUniqueViolation returns true if the error was caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqUniqueViolation
+	}
+	return false
+}
+
+type db interface {
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	Get(dest interface{}, query string, args ...interface{}) error
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+}
+
+type execKey int
+
+// nolint:gochecknoglobals // this variable is fine as it's used for context & is private
+var exec execKey
+
+// Tx wraps the transaction used in context.
+type Tx struct {
+	*sqlx.Tx
+}
+
+// WithTxContext will add a new empty transaction to the provided context.
+func WithTxContext(ctx context.Context) context.Context {
+	tx := TxFromContext(ctx)
+	if tx != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, exec, &Tx{})
+}
+
+// TxFromContext will return a context based transaction if found.
+func TxFromContext(ctx context.Context) *Tx {
+	if tx, ok := ctx.Value(exec).(*Tx); ok {
+		return tx
+	}
+	return nil
+}
+
+// Transacter is used to implement the DBTransacter interface for
+// managing db transactions in other layers.
+type Transacter struct {
+}
+
+// WithTx will add a TX to the provided context.
+func (t *Transacter) WithTx(ctx context.Context) context.Context {
+	return WithTxContext(ctx)
+}
+
+// Commit will commit a distributed transaction.
+func (t *Transacter) Commit(ctx context.Context) error {
+	tx := TxFromContext(ctx)
+	if tx != nil && tx.Tx != nil {
+		return tx.Tx.Commit()
+	}
+	return nil
+}
+
+// Rollback will revert the tx and rollback any changes.
+func (t *Transacter) Rollback(ctx context.Context) error {
+	tx := TxFromContext(ctx)
+	if tx != nil && tx.Tx != nil {
+		return tx.Tx.Rollback()
+	}
+	return nil
+}
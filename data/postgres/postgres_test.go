@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/config/databases"
+	"github.com/libsv/payd/data"
+	"github.com/libsv/payd/data/postgres"
+	"github.com/libsv/payd/data/storetest"
+	"github.com/libsv/payd/log"
+)
+
+// envTestDsn is the connection string of a postgres database used to run the store
+// conformance tests, the tests are skipped when not set unless storetest.EnvRequired is.
+// The database is wiped before each test, never point this at a database in use.
+const envTestDsn = "PAYD_TEST_POSTGRES_DSN"
+
+func TestPostgresStore(t *testing.T) {
+	dsn := storetest.DSN(t, envTestDsn)
+	storetest.Run(t, func(t *testing.T) (data.Store, payd.Transacter) {
+		wipe, err := sqlx.Open("postgres", dsn)
+		require.NoError(t, err)
+		_, err = wipe.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public;")
+		require.NoError(t, err)
+		require.NoError(t, wipe.Close())
+
+		db, err := databases.NewDbSetup().SetupDb(log.Noop{}, &config.Db{
+			Type:       config.DBPostgres,
+			Dsn:        dsn,
+			SchemaPath: "migrations",
+			MigrateDb:  true,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return postgres.NewPostgresStore(db), &postgres.Transacter{}
+	})
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+const (
+	sqlKeyByUserIDName = `
+	SELECT user_id, name, xprv, mnemonic, created_at AS "createdAt"
+	FROM keys
+	WHERE user_id = $1 AND name = $2
+	`
+
+	sqlKeys = `
+	SELECT user_id, name, xprv, mnemonic, created_at AS "createdAt"
+	FROM keys
+	ORDER BY user_id, name
+	`
+
+	sqlCreateKey = `
+	INSERT INTO keys(user_id, name, xprv, mnemonic)
+	VALUES(:user_id, :name, :xprv, :mnemonic)
+	`
+
+	sqlUpdateKey = `
+	UPDATE keys
+	SET xprv = :xprv, mnemonic = :mnemonic
+	WHERE user_id = :user_id AND name = :name
+	`
+)
+
+// PrivateKey will return a key by name from the datastore.
+// If not found nil is returned.
+func (s *postgresStore) PrivateKey(ctx context.Context, args payd.KeyArgs) (*payd.PrivateKey, error) {
+	var resp payd.PrivateKey
+	if err := s.db.GetContext(ctx, &resp, sqlKeyByUserIDName, args.UserID, args.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get key named %s from datastore", args.Name)
+	}
+	return &resp, nil
+}
+
+// PrivateKeyCreate will create and return a new key in the database.
+func (s *postgresStore) PrivateKeyCreate(ctx context.Context, req payd.PrivateKey) (*payd.PrivateKey, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin tx when creating key")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if err := handleNamedExec(tx, sqlCreateKey, req); err != nil {
+		return nil, errors.Wrapf(err, "failed to add key named '%s'", req.Name)
+	}
+	var resp payd.PrivateKey
+	if err := tx.GetContext(ctx, &resp, sqlKeyByUserIDName, req.UserID, req.Name); err != nil {
+		return nil, errors.Wrapf(err, "failed to get key named %s from datastore", req.Name)
+	}
+	return &resp, errors.Wrap(commit(ctx, tx), "failed to commit create key tx")
+}
+
+// PrivateKeys will return every key in the datastore.
+func (s *postgresStore) PrivateKeys(ctx context.Context) ([]payd.PrivateKey, error) {
+	resp := []payd.PrivateKey{}
+	if err := s.db.SelectContext(ctx, &resp, sqlKeys); err != nil {
+		return nil, errors.Wrap(err, "failed to get keys from datastore")
+	}
+	return resp, nil
+}
+
+// PrivateKeysUpdate will replace the xprv of each key, if any aren't found none are updated.
+func (s *postgresStore) PrivateKeysUpdate(ctx context.Context, req []payd.PrivateKey) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin tx when updating keys")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	for _, k := range req {
+		res, err := tx.NamedExecContext(ctx, sqlUpdateKey, k)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update key named '%s'", k.Name)
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrapf(err, "failed to update key named '%s'", k.Name)
+		}
+		if rows == 0 {
+			return lathos.NewErrNotFound(errcodes.ErrPrivateKeyNotFound,
+				fmt.Sprintf("key named '%s' for user %d not found", k.Name, k.UserID))
+		}
+	}
+	return errors.Wrap(commit(ctx, tx), "failed to commit update keys tx")
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-dpp"
+
+	"github.com/libsv/payd"
+)
+
+const (
+	sqlProofInsert = `
+	INSERT INTO proofs(blockhash, tx_id, data)
+	VALUES(:blockhash, :tx_id, :data)
+	`
+
+	sqlProofGet = `
+	SELECT data
+	FROM proofs
+	WHERE tx_id = $1 AND stale_at IS NULL
+	`
+
+	sqlProofDeleteStale = `
+	DELETE FROM proofs
+	WHERE blockhash = :blockhash AND tx_id = :tx_id AND stale_at IS NOT NULL
+	`
+
+	sqlProofsStaleOtherBlocks = `
+	UPDATE proofs
+	SET stale_at = :stale_at, updated_at = :stale_at
+	WHERE tx_id = :tx_id AND blockhash <> :blockhash AND stale_at IS NULL
+	`
+
+	sqlProofBlocks = `
+	SELECT DISTINCT p.blockhash, t.block_height
+	FROM proofs p
+	JOIN transactions t ON t.tx_id = p.tx_id
+	WHERE p.stale_at IS NULL AND t.state IN ('mined', 'confirmed') AND t.block_height >= $1
+	ORDER BY t.block_height DESC
+	`
+
+	sqlProofsByBlock = `
+	SELECT tx_id
+	FROM proofs
+	WHERE blockhash = $1 AND stale_at IS NULL
+	ORDER BY tx_id
+	`
+
+	sqlProofsStale = `
+	UPDATE proofs
+	SET stale_at = $1, updated_at = $2
+	WHERE blockhash = $3 AND stale_at IS NULL
+	`
+)
+
+// ProofCreate will insert a proof to the database.
+func (s *postgresStore) ProofCreate(ctx context.Context, req dpp.ProofWrapper) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	bb, err := json.Marshal(req.CallbackPayload)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dbProof := struct {
+		Blockhash string    `db:"blockhash"`
+		TxID      string    `db:"tx_id"`
+		Data      string    `db:"data"`
+		StaleAt   time.Time `db:"stale_at"`
+	}{
+		Blockhash: req.BlockHash,
+		TxID:      req.CallbackTxID,
+		Data:      string(bb),
+		StaleAt:   time.Now().UTC(),
+	}
+	// a proof for a block that was orphaned then rejoined the best chain replaces the stale proof,
+	// and a tx can only be in one block of the best chain so proofs in any other block are stale.
+	if _, err := tx.NamedExecContext(ctx, sqlProofDeleteStale, dbProof); err != nil {
+		return errors.Wrapf(err, "failed to remove stale proof for txid %s and blockhash '%s'", req.CallbackTxID, req.BlockHash)
+	}
+	if _, err := tx.NamedExecContext(ctx, sqlProofsStaleOtherBlocks, dbProof); err != nil {
+		return errors.Wrapf(err, "failed to mark proofs for txid %s in other blocks stale", req.CallbackTxID)
+	}
+	if err := handleNamedExec(tx, sqlProofInsert, dbProof); err != nil {
+		return errors.Wrapf(err, "failed to proof for txid %s and blockhash '%s'", req.CallbackTxID, req.BlockHash)
+	}
+	return errors.WithStack(commit(ctx, tx))
+}
+
+// MerkleProof will retrieve a proof.
+func (s *postgresStore) MerkleProof(ctx context.Context, txID string) (*bc.MerkleProof, error) {
+	var data string
+	if err := s.db.GetContext(ctx, &data, sqlProofGet, txID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to retrieve proof")
+	}
+	var proof bc.MerkleProof
+	if err := json.Unmarshal([]byte(data), &proof); err != nil {
+		return nil, errors.Wrap(err, "failed to process raw proof")
+	}
+	return &proof, nil
+}
+
+// ProofBlocks will return the blocks of the proofs of mined and confirmed transactions, highest first.
+func (s *postgresStore) ProofBlocks(ctx context.Context, args payd.ProofBlocksArgs) ([]payd.ProofBlock, error) {
+	var blocks []payd.ProofBlock
+	if err := s.db.SelectContext(ctx, &blocks, sqlProofBlocks, args.MinBlockHeight); err != nil {
+		return nil, errors.Wrapf(err, "failed to get proof blocks at or above height %d", args.MinBlockHeight)
+	}
+	return blocks, nil
+}
+
+// ProofsStale will mark the proofs of a block stale, returning the txIDs of the proofs.
+func (s *postgresStore) ProofsStale(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	var txIDs []string
+	if err := tx.SelectContext(ctx, &txIDs, sqlProofsByBlock, args.BlockHash); err != nil {
+		return nil, errors.Wrapf(err, "failed to get proofs of block %s", args.BlockHash)
+	}
+	if _, err := tx.ExecContext(ctx, sqlProofsStale, args.StaleAt, args.StaleAt, args.BlockHash); err != nil {
+		return nil, errors.Wrapf(err, "failed to mark proofs of block %s stale", args.BlockHash)
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when marking proofs of block %s stale", args.BlockHash)
+	}
+	return txIDs, nil
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+const (
+	// FOR UPDATE SKIP LOCKED ensures concurrent reservations never choose from the same utxos.
+	sqlUTXOsSpendable = `
+	SELECT t.outpoint, t.tx_id, t.vout, d.locking_script, t.satoshis, d.derivation_path, t.created_at
+	FROM txos t
+	    INNER JOIN destinations d ON t.destination_id = d.destination_id
+		INNER JOIN transactions tx on t.tx_id = tx.tx_id
+	WHERE t.reserved_for IS NULL
+	  AND t.spent_at IS NULL
+	  AND t.spending_txid IS NULL
+	  AND tx.state IN ('broadcast', 'mined', 'confirmed')
+	  AND NOT t.frozen
+	  AND ($1 = '' OR (d.key_name = $1 AND d.user_id = $2))
+	  AND (NOT $3 OR tx.state = 'confirmed')
+	ORDER BY t.created_at, t.outpoint
+	FOR UPDATE OF t SKIP LOCKED
+	`
+
+	sqlUTXOReserve = `
+	UPDATE txos
+	SET reserved_for = $1, reserved_until = $2, updated_at = $3
+	WHERE outpoint = $4
+	`
+
+	sqlUTXOUnreserve = `
+	UPDATE txos
+	SET reserved_for = NULL, reserved_until = NULL, spent_at = NULL, spending_txid = NULL, updated_at = $1
+	WHERE reserved_for = $2 AND (spent_at IS NULL AND spending_txid IS NULL
+		OR spending_txid IN (SELECT tx_id FROM transactions WHERE state NOT IN ('broadcast', 'mined', 'confirmed')))
+	`
+
+	sqlUTXOsReserved = `
+	SELECT t.reserved_for, t.satoshis, t.reserved_until
+	FROM txos t
+		LEFT JOIN transactions s ON t.spending_txid = s.tx_id
+	WHERE t.reserved_for IS NOT NULL AND (t.spent_at IS NULL AND t.spending_txid IS NULL
+		OR s.state NOT IN ('broadcast', 'mined', 'confirmed'))
+	ORDER BY t.reserved_for
+	`
+
+	sqlUTXOs = `
+	SELECT t.outpoint, t.tx_id, t.vout, t.satoshis, d.locking_script, d.derivation_path, d.key_name,
+		tx.state AS tx_state, t.reserved_for, t.reserved_until, t.frozen, t.spent_at, t.spending_txid, t.created_at,
+		EXISTS(SELECT 1 FROM proofs p WHERE p.tx_id = t.tx_id AND p.stale_at IS NULL) AS confirmed
+	FROM txos t
+		INNER JOIN destinations d ON t.destination_id = d.destination_id
+		INNER JOIN transactions tx ON t.tx_id = tx.tx_id
+	WHERE 1 = 1
+	`
+
+	sqlUTXOFrozenUpdate = `
+	UPDATE txos
+	SET frozen = ?, updated_at = ?
+	WHERE outpoint = ? AND spent_at IS NULL AND spending_txid IS NULL
+	`
+
+	sqlUTXOSpend = `
+	UPDATE txos
+	SET spent_at = :timestamp, spending_txid = :spending_txid, updated_at = :timestamp
+	WHERE reserved_for = :reserved_for
+	`
+)
+
+// UTXOReserve marks the utxos chosen by the selector, from those that are spendable, as reserved
+// and returns them. If there aren't enough funds nothing is reserved.
+func (s *postgresStore) UTXOReserve(ctx context.Context, req payd.UTXOReserve) ([]payd.UTXO, error) {
+	if req.Selector == nil {
+		return nil, errors.New("a coin selector is required to reserve utxos")
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creation transaction to get utxos")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	var spendable []payd.UTXO
+	if err := tx.SelectContext(ctx, &spendable, sqlUTXOsSpendable, req.KeyName, req.UserID, req.Confirmed); err != nil {
+		return nil, errors.Wrap(err, "failed to get spendable utxos")
+	}
+	utxos := req.Selector.SelectCoins(payd.CoinSelectArgs{
+		Satoshis:  req.Satoshis,
+		InputFee:  req.InputFee,
+		ChangeFee: req.ChangeFee,
+	}, spendable)
+	if len(utxos) == 0 {
+		return []payd.UTXO{}, nil
+	}
+	timestamp := time.Now().UTC()
+	reservedUntil := null.NewTime(req.ReservedUntil, !req.ReservedUntil.IsZero())
+	for _, utxo := range utxos {
+		result, err := tx.ExecContext(ctx, sqlUTXOReserve, req.ReservedFor, reservedUntil, timestamp, utxo.Outpoint)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to reserve utxo")
+		}
+		if err := handleExecRows(result); err != nil {
+			return nil, errors.Wrap(err, "failed to handle update for reserving utxo")
+		}
+	}
+
+	return utxos, errors.Wrap(commit(ctx, tx), "error committing utxo reservation")
+}
+
+// UTXOUnreserve unmarks the reservation from matching reservations that haven't been spent, or were
+// spent by a tx that was never broadcast, which are unspent again.
+func (s *postgresStore) UTXOUnreserve(ctx context.Context, req payd.UTXOUnreserve) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error creation transaction to get utxos")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if _, err = tx.ExecContext(ctx, sqlUTXOUnreserve, time.Now().UTC(), req.ReservedFor); err != nil {
+		return errors.Wrap(err, "failed to unreserve utxos")
+	}
+
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction to unreserve utxos")
+}
+
+// UTXOReservations returns the reserved utxos that haven't been spent, or were spent by a tx
+// that was never broadcast, grouped by reservation.
+func (s *postgresStore) UTXOReservations(ctx context.Context) ([]payd.UTXOReservation, error) {
+	var rows []struct {
+		ReservedFor   string    `db:"reserved_for"`
+		Satoshis      uint64    `db:"satoshis"`
+		ReservedUntil null.Time `db:"reserved_until"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, sqlUTXOsReserved); err != nil {
+		return nil, errors.Wrap(err, "failed to get reserved utxos")
+	}
+	rr := []payd.UTXOReservation{}
+	for _, row := range rows {
+		if len(rr) == 0 || rr[len(rr)-1].ReservedFor != row.ReservedFor {
+			rr = append(rr, payd.UTXOReservation{ReservedFor: row.ReservedFor})
+		}
+		r := &rr[len(rr)-1]
+		r.UTXOs++
+		r.Satoshis += row.Satoshis
+		if row.ReservedUntil.Valid && (!r.ReservedUntil.Valid || row.ReservedUntil.Time.After(r.ReservedUntil.Time)) {
+			r.ReservedUntil = row.ReservedUntil
+		}
+	}
+	return rr, nil
+}
+
+// UTXOs returns the txos matching args, ordered by creation date then outpoint.
+func (s *postgresStore) UTXOs(ctx context.Context, args payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+	query, params := utxoSearch(args)
+	resp := []payd.UTXODetail{}
+	if err := s.db.SelectContext(ctx, &resp, s.db.Rebind(query), params...); err != nil {
+		return nil, errors.Wrap(err, "failed to get utxos")
+	}
+	return resp, nil
+}
+
+// UTXOFrozenUpdate will freeze or unfreeze unspent txos, if any aren't found none are updated.
+func (s *postgresStore) UTXOFrozenUpdate(ctx context.Context, req payd.UTXOFrozenUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to create transaction to freeze utxos")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	timestamp := time.Now().UTC()
+	for _, outpoint := range req.Outpoints {
+		res, err := tx.ExecContext(ctx, tx.Rebind(sqlUTXOFrozenUpdate), req.Frozen, timestamp, outpoint)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update frozen state of utxo %s", outpoint)
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrapf(err, "failed to update frozen state of utxo %s", outpoint)
+		}
+		if rows == 0 {
+			return lathos.NewErrNotFound(errcodes.ErrUTXONotFound, fmt.Sprintf("unspent txo %s not found", outpoint))
+		}
+	}
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction to freeze utxos")
+}
+
+// UTXOSpend spends txs matching the provided reservation.
+func (s *postgresStore) UTXOSpend(ctx context.Context, req payd.UTXOSpend) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to create transaction to spend utxos")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	req.Timestamp = time.Now().UTC()
+	if err := handleNamedExec(tx, sqlUTXOSpend, req); err != nil {
+		return errors.Wrap(err, "failed to mark utxos as spent")
+	}
+
+	return errors.Wrap(commit(ctx, tx), "failed to commit transaction for spending utxo")
+}
+
+// utxoSearch builds the query and params to find the txos matching args.
+func utxoSearch(args payd.UTXOSearchArgs) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString(sqlUTXOs)
+	var params []interface{}
+	where := func(clause string, vv ...interface{}) {
+		sb.WriteString(" AND " + clause)
+		params = append(params, vv...)
+	}
+	const spent = "(t.spent_at IS NOT NULL OR t.spending_txid IS NOT NULL)"
+	if args.Spent.Valid {
+		if args.Spent.Bool {
+			where(spent)
+		} else {
+			where("NOT " + spent)
+		}
+	}
+	if args.Reserved.Valid {
+		if args.Reserved.Bool {
+			where("t.reserved_for IS NOT NULL AND NOT " + spent)
+		} else {
+			where("(t.reserved_for IS NULL OR " + spent + ")")
+		}
+	}
+	if args.Confirmed.Valid {
+		if args.Confirmed.Bool {
+			where("EXISTS(SELECT 1 FROM proofs p WHERE p.tx_id = t.tx_id AND p.stale_at IS NULL)")
+		} else {
+			where("NOT EXISTS(SELECT 1 FROM proofs p WHERE p.tx_id = t.tx_id AND p.stale_at IS NULL)")
+		}
+	}
+	if args.Frozen.Valid {
+		if args.Frozen.Bool {
+			where("t.frozen")
+		} else {
+			where("NOT t.frozen")
+		}
+	}
+	if args.SatoshisMin > 0 {
+		where("t.satoshis >= ?", args.SatoshisMin)
+	}
+	if args.SatoshisMax > 0 {
+		where("t.satoshis <= ?", args.SatoshisMax)
+	}
+	if args.DerivationPath != "" {
+		where("(d.derivation_path = ? OR d.derivation_path LIKE ?)", args.DerivationPath, args.DerivationPath+"/%")
+	}
+	order, cmp := "ASC", ">"
+	if args.Descending() {
+		order, cmp = "DESC", "<"
+	}
+	if !args.Cursor.IsZero() {
+		createdAt := args.Cursor.CreatedAt.UTC()
+		where(fmt.Sprintf("(t.created_at %s ? OR (t.created_at = ? AND t.outpoint %s ?))", cmp, cmp),
+			createdAt, createdAt, args.Cursor.Outpoint)
+	}
+	fmt.Fprintf(&sb, " ORDER BY t.created_at %s, t.outpoint %s", order, order)
+	if args.Limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		params = append(params, args.Limit)
+	}
+	return sb.String(), params
+}
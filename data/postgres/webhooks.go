@@ -0,0 +1,316 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+)
+
+const (
+	sqlWebhookCreate = `
+	INSERT INTO webhooks(webhook_id, url, secret, created_at)
+	VALUES(:webhook_id, :url, :secret, :created_at)
+	`
+
+	sqlWebhookEventCreate = `
+	INSERT INTO webhook_events(webhook_id, event)
+	VALUES(:webhook_id, :event)
+	`
+
+	sqlWebhookByID = `
+	SELECT webhook_id, url, secret, created_at
+	FROM webhooks
+	WHERE webhook_id = $1 AND deleted_at IS NULL
+	`
+
+	sqlWebhooks = `
+	SELECT webhook_id, url, secret, created_at
+	FROM webhooks
+	WHERE deleted_at IS NULL
+	ORDER BY created_at, webhook_id
+	`
+
+	sqlWebhookEventsByID = `
+	SELECT event
+	FROM webhook_events
+	WHERE webhook_id = $1
+	ORDER BY event
+	`
+
+	sqlWebhookEvents = `
+	SELECT e.webhook_id, e.event
+	FROM webhook_events e
+	JOIN webhooks w ON w.webhook_id = e.webhook_id
+	WHERE w.deleted_at IS NULL
+	ORDER BY e.webhook_id, e.event
+	`
+
+	sqlWebhookDelete = `
+	UPDATE webhooks
+	SET deleted_at = :deleted_at
+	WHERE webhook_id = :webhook_id AND deleted_at IS NULL
+	`
+
+	sqlWebhookDeliveryCreate = `
+	INSERT INTO webhook_deliveries(delivery_id, webhook_id, event, payload, state, next_attempt_at, created_at, updated_at)
+	VALUES(:delivery_id, :webhook_id, :event, :payload, 'pending', :next_attempt_at, :created_at, :created_at)
+	`
+
+	sqlWebhookDeliveries = `
+	SELECT delivery_id, webhook_id, event, payload, state, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+	FROM webhook_deliveries
+	WHERE 1 = 1
+	`
+
+	sqlWebhookDeliveryByID = `
+	SELECT delivery_id, webhook_id, event, payload, state, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+	FROM webhook_deliveries
+	WHERE delivery_id = $1
+	`
+
+	sqlWebhookDeliveriesDue = `
+	SELECT d.delivery_id, d.webhook_id, d.event, d.payload, d.state, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, d.created_at, d.updated_at
+	FROM webhook_deliveries d
+	JOIN webhooks w ON w.webhook_id = d.webhook_id
+	WHERE d.state = 'pending' AND d.next_attempt_at <= ? AND w.deleted_at IS NULL
+	ORDER BY d.next_attempt_at, d.delivery_id
+	`
+
+	sqlWebhookDeliveryUpdate = `
+	UPDATE webhook_deliveries
+	SET state = :state, attempts = :attempts, next_attempt_at = :next_attempt_at, last_error = :last_error,
+		delivered_at = :delivered_at, updated_at = :updated_at
+	WHERE delivery_id = :delivery_id
+	`
+)
+
+// webhookEvent is a single event a webhook is subscribed to.
+type webhookEvent struct {
+	WebhookID string            `db:"webhook_id"`
+	Event     payd.WebhookEvent `db:"event"`
+}
+
+// Webhook will return a webhook matching args, deleted webhooks are not returned.
+func (s *postgresStore) Webhook(ctx context.Context, args payd.WebhookArgs) (*payd.Webhook, error) {
+	var resp payd.Webhook
+	if err := s.db.GetContext(ctx, &resp, sqlWebhookByID, args.WebhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", args.WebhookID))
+		}
+		return nil, errors.Wrapf(err, "failed to get webhook with webhookID %s", args.WebhookID)
+	}
+	if err := s.db.SelectContext(ctx, &resp.Events, sqlWebhookEventsByID, args.WebhookID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get events for webhookID %s", args.WebhookID)
+	}
+	return &resp, nil
+}
+
+// Webhooks will return all webhooks that haven't been deleted, oldest first.
+func (s *postgresStore) Webhooks(ctx context.Context) ([]payd.Webhook, error) {
+	var resp []payd.Webhook
+	if err := s.db.SelectContext(ctx, &resp, sqlWebhooks); err != nil {
+		return nil, errors.Wrap(err, "failed to get webhooks")
+	}
+	var ee []webhookEvent
+	if err := s.db.SelectContext(ctx, &ee, sqlWebhookEvents); err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook events")
+	}
+	events := map[string][]payd.WebhookEvent{}
+	for _, e := range ee {
+		events[e.WebhookID] = append(events[e.WebhookID], e.Event)
+	}
+	for i := range resp {
+		resp[i].Events = events[resp[i].ID]
+	}
+	return resp, nil
+}
+
+// WebhookCreate will persist a new webhook along with the events it subscribes to.
+func (s *postgresStore) WebhookCreate(ctx context.Context, req payd.WebhookCreate) (*payd.Webhook, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create new webhook with webhookID %s", req.WebhookID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if err := handleNamedExec(tx, sqlWebhookCreate, req); err != nil {
+		if isUniqueViolation(err) {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("webhook with webhookID %s already exists", req.WebhookID))
+		}
+		return nil, errors.Wrapf(err, "failed to insert webhook with webhookID %s", req.WebhookID)
+	}
+	ee := make([]webhookEvent, 0, len(req.Events))
+	for _, e := range req.Events {
+		ee = append(ee, webhookEvent{WebhookID: req.WebhookID, Event: e})
+	}
+	if err := handleNamedExec(tx, sqlWebhookEventCreate, ee); err != nil {
+		if isUniqueViolation(err) {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("webhook with webhookID %s has duplicate events", req.WebhookID))
+		}
+		return nil, errors.Wrapf(err, "failed to insert events for webhookID %s", req.WebhookID)
+	}
+	var resp payd.Webhook
+	if err := tx.Get(&resp, sqlWebhookByID, req.WebhookID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get new webhook with webhookID %s after creation", req.WebhookID)
+	}
+	if err := tx.Select(&resp.Events, sqlWebhookEventsByID, req.WebhookID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get events for new webhookID %s", req.WebhookID)
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when creating webhook with webhookID %s", req.WebhookID)
+	}
+	return &resp, nil
+}
+
+// WebhookDelete will soft delete a webhook, its deliveries are kept.
+func (s *postgresStore) WebhookDelete(ctx context.Context, args payd.WebhookArgs) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook with webhookID %s", args.WebhookID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	res, err := tx.NamedExec(sqlWebhookDelete, map[string]interface{}{
+		"deleted_at": time.Now().UTC(),
+		"webhook_id": args.WebhookID,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook with webhookID %s", args.WebhookID)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook with webhookID %s", args.WebhookID)
+	}
+	if rows == 0 {
+		return lathos.NewErrNotFound(errcodes.ErrWebhookNotFound, fmt.Sprintf("webhook with webhookID %s not found", args.WebhookID))
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when deleting webhook with webhookID %s", args.WebhookID)
+	}
+	return nil
+}
+
+// WebhookDelivery will return a delivery matching args.
+func (s *postgresStore) WebhookDelivery(ctx context.Context, args payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error) {
+	var resp payd.WebhookDelivery
+	if err := s.db.GetContext(ctx, &resp, sqlWebhookDeliveryByID, args.DeliveryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, lathos.NewErrNotFound(errcodes.ErrWebhookDeliveryNotFound, fmt.Sprintf("webhook delivery with deliveryID %s not found", args.DeliveryID))
+		}
+		return nil, errors.Wrapf(err, "failed to get webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	return &resp, nil
+}
+
+// WebhookDeliveries returns the deliveries matching args, newest first.
+func (s *postgresStore) WebhookDeliveries(ctx context.Context, args payd.WebhookDeliveriesArgs) ([]payd.WebhookDelivery, error) {
+	var sb strings.Builder
+	sb.WriteString(sqlWebhookDeliveries)
+	var params []interface{}
+	if args.WebhookID != "" {
+		sb.WriteString(" AND webhook_id = ?")
+		params = append(params, args.WebhookID)
+	}
+	if args.State != "" {
+		sb.WriteString(" AND state = ?")
+		params = append(params, string(args.State))
+	}
+	sb.WriteString(" ORDER BY created_at DESC, delivery_id DESC")
+	if args.Limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		params = append(params, args.Limit)
+	}
+	var resp []payd.WebhookDelivery
+	if err := s.db.SelectContext(ctx, &resp, s.db.Rebind(sb.String()), params...); err != nil {
+		return nil, errors.Wrap(err, "failed to get webhook deliveries")
+	}
+	return resp, nil
+}
+
+// WebhookDeliveriesDue returns the pending deliveries due to be attempted, ordered by their next attempt.
+func (s *postgresStore) WebhookDeliveriesDue(ctx context.Context, args payd.WebhookDeliveriesDueArgs) ([]payd.WebhookDelivery, error) {
+	query := sqlWebhookDeliveriesDue
+	params := []interface{}{args.DueBefore.UTC()}
+	if args.Limit > 0 {
+		query += " LIMIT ?"
+		params = append(params, args.Limit)
+	}
+	var resp []payd.WebhookDelivery
+	if err := s.db.SelectContext(ctx, &resp, s.db.Rebind(query), params...); err != nil {
+		return nil, errors.Wrap(err, "failed to get due webhook deliveries")
+	}
+	return resp, nil
+}
+
+// WebhookDeliveriesCreate will add deliveries to the outbox in the pending state.
+func (s *postgresStore) WebhookDeliveriesCreate(ctx context.Context, req []payd.WebhookDeliveryCreate) error {
+	if len(req) == 0 {
+		// nothing to store
+		return nil
+	}
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook deliveries")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	for i := range req {
+		req[i].NextAttemptAt = req[i].NextAttemptAt.UTC()
+		req[i].CreatedAt = req[i].CreatedAt.UTC()
+	}
+	if err := handleNamedExec(tx, sqlWebhookDeliveryCreate, req); err != nil {
+		if isUniqueViolation(err) {
+			return lathos.NewErrDuplicate("D001", "webhook delivery already exists")
+		}
+		return errors.Wrap(err, "failed to insert webhook deliveries")
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrap(err, "failed to commit transaction when creating webhook deliveries")
+	}
+	return nil
+}
+
+// WebhookDeliveryUpdate will update a delivery with the outcome of an attempt.
+func (s *postgresStore) WebhookDeliveryUpdate(ctx context.Context, args payd.WebhookDeliveryArgs, req payd.WebhookDeliveryUpdate) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	res, err := tx.NamedExec(sqlWebhookDeliveryUpdate, map[string]interface{}{
+		"state":           req.State,
+		"attempts":        req.Attempts,
+		"next_attempt_at": req.NextAttemptAt.UTC(),
+		"last_error":      req.LastError,
+		"delivered_at":    req.DeliveredAt,
+		"updated_at":      req.UpdatedAt.UTC(),
+		"delivery_id":     args.DeliveryID,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	if rows == 0 {
+		return lathos.NewErrNotFound(errcodes.ErrWebhookDeliveryNotFound, fmt.Sprintf("webhook delivery with deliveryID %s not found", args.DeliveryID))
+	}
+	if err := commit(ctx, tx); err != nil {
+		return errors.Wrapf(err, "failed to commit transaction when updating webhook delivery with deliveryID %s", args.DeliveryID)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package sockets
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/sockets"
+	"github.com/theflyingcodr/sockets/client"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+)
+
+type invoiceExpiry struct {
+	cli *client.Client
+	cfg config.Socket
+}
+
+// NewInvoiceExpiry will setup and return a notifier that closes the socket channel
+// joined to take payment for an invoice once it expires.
+func NewInvoiceExpiry(cfg config.Socket, cli *client.Client) *invoiceExpiry {
+	return &invoiceExpiry{
+		cli: cli,
+		cfg: cfg,
+	}
+}
+
+// InvoiceExpired will publish an invoice.expired message, letting the payer know the
+// invoice can no longer be paid, then leave the invoice channel.
+// Invoices that aren't connected to a socket channel are ignored.
+func (i *invoiceExpiry) InvoiceExpired(ctx context.Context, inv payd.Invoice) error {
+	if !i.cli.HasChannel(inv.ID) {
+		return nil
+	}
+	defer i.cli.LeaveChannel(inv.ID, nil)
+	h := http.Header{}
+	h.Add("X-Origin-ID", i.cfg.ClientIdentifier)
+	return errors.Wrapf(i.cli.Publish(sockets.Request{
+		ChannelID:  inv.ID,
+		MessageKey: "invoice.expired",
+		Body:       inv,
+		Headers:    h,
+	}), "failed to publish invoice expired socket message for invoice %s", inv.ID)
+}
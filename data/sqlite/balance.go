@@ -2,7 +2,7 @@ package sqlite
 
 import (
 	"context"
-	"database/sql"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -11,20 +11,51 @@ import (
 
 const (
 	sqlBalance = `
-	SELECT IFNULL(SUM(d.satoshis), 0) as satoshis
-	FROM txos as t INNER JOIN destinations as d on t.destination_id = d.destination_id
-	WHERE t.spent_at IS NULL 
+	SELECT b.state, b.reserved, b.frozen, COUNT(*) AS utxos, COALESCE(SUM(b.satoshis), 0) AS satoshis,
+		MIN(b.block_height) AS low_block_height, MAX(b.block_height) AS high_block_height
+	FROM (
+		SELECT tx.state, tx.block_height, t.satoshis, t.reserved_for IS NOT NULL AS reserved, t.frozen
+		FROM txos t
+			INNER JOIN destinations d ON t.destination_id = d.destination_id
+			INNER JOIN transactions tx ON t.tx_id = tx.tx_id
+		WHERE t.spent_at IS NULL AND t.spending_txid IS NULL
+	`
+
+	sqlBalanceGroup = `
+	) b
+	GROUP BY b.state, b.reserved, b.frozen
 	`
 )
 
-// Balance will return the current account balance.
-func (s *sqliteStore) Balance(ctx context.Context) (*payd.Balance, error) {
-	var resp payd.Balance
-	if err := s.db.GetContext(ctx, &resp, sqlBalance); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return &payd.Balance{Satoshis: 0}, nil
-		}
+// Balance will return the current account balance, totalling the unspent txos by the
+// state of their tx and whether they are reserved or frozen along with the heights of
+// the blocks their txs were mined in.
+func (s *sqliteStore) Balance(ctx context.Context, args payd.BalanceArgs) (*payd.Balance, error) {
+	var sb strings.Builder
+	sb.WriteString(sqlBalance)
+	var params []interface{}
+	if args.UserID.Valid {
+		sb.WriteString(" AND d.user_id = ?")
+		params = append(params, args.UserID.Int64)
+	}
+	if args.KeyName != "" {
+		sb.WriteString(" AND d.key_name = ?")
+		params = append(params, args.KeyName)
+	}
+	sb.WriteString(sqlBalanceGroup)
+	query := sb.String()
+	var rows []struct {
+		payd.BalanceTotal
+		State    payd.TxState `db:"state"`
+		Reserved bool         `db:"reserved"`
+		Frozen   bool         `db:"frozen"`
+	}
+	if err := s.db.SelectContext(ctx, &rows, query, params...); err != nil {
 		return nil, errors.Wrap(err, "failed to get balance")
 	}
+	var resp payd.Balance
+	for _, row := range rows {
+		resp.Add(row.BalanceTotal, row.State, row.Reserved, row.Frozen)
+	}
 	return &resp, nil
 }
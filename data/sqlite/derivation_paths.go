@@ -14,6 +14,13 @@ const (
 	    SELECT derivation_path FROM destinations WHERE derivation_path = $1 AND key_name = $2 AND user_id = $3 
 	    )
 	`
+
+	sqlDerivationPaths = `
+	SELECT derivation_path, locking_script
+	FROM destinations
+	WHERE key_name = $1 AND user_id = $2
+	ORDER BY derivation_path
+	`
 )
 
 // DerivationPathExists will return true / false if the supplied derivation path exists or not.
@@ -24,3 +31,12 @@ func (s *sqliteStore) DerivationPathExists(ctx context.Context, args payd.Deriva
 	}
 	return exists == 1, nil
 }
+
+// DerivationPaths returns every path used to derive a destination from the key.
+func (s *sqliteStore) DerivationPaths(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+	resp := []payd.DerivationPath{}
+	if err := s.db.SelectContext(ctx, &resp, sqlDerivationPaths, args.Name, args.UserID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get derivation paths of key %s", args.Name)
+	}
+	return resp, nil
+}
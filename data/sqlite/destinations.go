@@ -33,7 +33,8 @@ const (
 	`
 
 	sqlDestinationsByInvoiceID = `
-	SELECT d.destination_id, d.locking_script, d.derivation_path, d.satoshis, d.state
+	SELECT d.destination_id, d.locking_script, d.derivation_path, d.satoshis, d.state,
+		COALESCE((SELECT SUM(t.satoshis) FROM txos t WHERE t.destination_id = d.destination_id), 0) AS satoshis_received
 	FROM destinations as d INNER JOIN destination_invoice as di ON d.destination_id = di.destination_id
 	WHERE di.invoice_id = :invoice_id 
 	`
@@ -127,6 +128,8 @@ type dbOutput struct {
 	// State will indicate if this destination is still waiting on a tx to fulfil it (pending)
 	// has been paid to in a tx (received) or has been deleted.
 	State string `db:"state"  enums:"pending,received,deleted"`
+	// SatoshisReceived is the total already paid to this destination.
+	SatoshisReceived uint64 `db:"satoshis_received"`
 }
 
 // Destinations will return a set of destination outputs for a specific invoiceID.
@@ -145,11 +148,12 @@ func (s *sqliteStore) Destinations(ctx context.Context, args payd.DestinationsAr
 	for i := 0; i < len(oo); i++ {
 		s, _ := bscript.NewFromHexString(oo[i].LockingScript)
 		outs[i] = payd.Output{
-			ID:             oo[i].ID,
-			LockingScript:  s,
-			Satoshis:       oo[i].Satoshis,
-			DerivationPath: oo[i].DerivationPath,
-			State:          oo[i].State,
+			ID:               oo[i].ID,
+			LockingScript:    s,
+			Satoshis:         oo[i].Satoshis,
+			DerivationPath:   oo[i].DerivationPath,
+			State:            oo[i].State,
+			SatoshisReceived: oo[i].SatoshisReceived,
 		}
 	}
 	return outs, nil
@@ -0,0 +1,15 @@
+//go:build cgo
+// +build cgo
+
+package sqlite
+
+import (
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// isConstraintErr returns true if err is caused by a sqlite constraint violation.
+func isConstraintErr(err error) bool {
+	var sqlErr sqlite3.Error
+	return errors.As(err, &sqlErr) && sqlErr.Code == sqlite3.ErrConstraint
+}
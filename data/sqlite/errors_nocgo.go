@@ -0,0 +1,10 @@
+//go:build !cgo
+// +build !cgo
+
+package sqlite
+
+// isConstraintErr always returns false, the sqlite driver isn't available
+// without cgo so sqlite errors can't occur.
+func isConstraintErr(err error) bool {
+	return false
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/libsv/payd"
 	"github.com/libsv/payd/errcodes"
 	"github.com/pkg/errors"
 	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
 )
 
 const (
@@ -18,30 +21,75 @@ const (
 	VALUES(:invoice_id, :satoshis, :description, :spv_required, :payment_reference, :created_at, :expires_at, 'pending')
 	`
 
+	// block_height is the height of the highest block an invoice payment was mined in, it is only
+	// set once every payment has been mined.
 	sqlInvoiceByID = `
-	SELECT invoice_id, satoshis, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at
+	SELECT invoice_id, satoshis, satoshis_received, satoshis_refunded, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at,
+		(SELECT CASE WHEN COUNT(*) = COUNT(tx.block_height) THEN MAX(tx.block_height) END
+		FROM payments p INNER JOIN transactions tx ON tx.tx_id = p.tx_id
+		WHERE p.invoice_id = invoices.invoice_id) AS block_height
 	FROM invoices
 	WHERE invoice_id = :invoice_id
 	AND state != 'deleted'
 	`
 
 	sqlInvoices = `
-	SELECT invoice_id, satoshis, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at
+	SELECT invoice_id, satoshis, satoshis_received, satoshis_refunded, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at,
+		(SELECT CASE WHEN COUNT(*) = COUNT(tx.block_height) THEN MAX(tx.block_height) END
+		FROM payments p INNER JOIN transactions tx ON tx.tx_id = p.tx_id
+		WHERE p.invoice_id = invoices.invoice_id) AS block_height
 	FROM invoices
 	WHERE state != 'deleted'
 	`
 
 	sqlPendingInvoices = `
-	SELECT invoice_id, satoshis, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at
+	SELECT invoice_id, satoshis, satoshis_received, satoshis_refunded, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at
 	FROM invoices
-	WHERE state == 'pending'
+	WHERE state IN ('pending', 'partially_paid')
 	`
 
-	// TODO - sort updates when working on rest of Invoice API.
 	sqlInvoiceUpdate = `
-		UPDATE invoices 
-		SET payment_received_at = :paymentReceivedAt, refund_to = :refundTo, state = 'paid'
-		WHERE invoice_id = :invoice_id AND state = 'pending'
+	UPDATE invoices
+	SET state = CASE WHEN satoshis_received + :satoshis >= satoshis THEN 'paid' ELSE 'partially_paid' END,
+		payment_received_at = CASE WHEN satoshis_received + :satoshis >= satoshis THEN :paymentReceivedAt ELSE payment_received_at END,
+		refund_to = COALESCE(:refundTo, refund_to),
+		updated_at = :paymentReceivedAt,
+		satoshis_received = satoshis_received + :satoshis
+	WHERE invoice_id = :invoice_id AND state IN ('pending', 'partially_paid')
+	`
+
+	sqlInvoicePaymentCreate = `
+	INSERT INTO payments(invoice_id, tx_id, satoshis, refund_to, created_at)
+	VALUES(:invoice_id, :tx_id, :satoshis, :refund_to, :created_at)
+	`
+
+	sqlInvoicePayments = `
+	SELECT invoice_id, tx_id, satoshis, refund_to, created_at
+	FROM payments
+	WHERE invoice_id = ?
+	ORDER BY created_at, tx_id
+	`
+
+	sqlInvoiceRefund = `
+	UPDATE invoices
+	SET state = CASE WHEN satoshis_refunded + :satoshis >= satoshis_received THEN 'refunded' ELSE state END,
+		refunded_at = :refundedAt,
+		updated_at = :refundedAt,
+		satoshis_refunded = satoshis_refunded + :satoshis
+	WHERE invoice_id = :invoice_id AND state IN ('paid', 'partially_paid', 'expired')
+		AND satoshis_refunded + :satoshis <= satoshis_received
+	`
+
+	sqlInvoiceRefundCreate = `
+	INSERT INTO refunds(invoice_id, tx_id, satoshis, refund_to, created_at)
+	VALUES(:invoice_id, :tx_id, :satoshis, :refund_to, :created_at)
+	`
+
+	sqlInvoiceRefunds = `
+	SELECT invoice_id, tx_id, satoshis, refund_to, created_at
+	FROM refunds
+	WHERE invoice_id = ?
+	ORDER BY created_at, tx_id
 	`
 
 	sqlInvoiceDelete = `
@@ -49,6 +97,34 @@ const (
 	SET deleted_at = :deleted_at, state = 'deleted'
 	WHERE invoice_id = :invoice_id
 	`
+
+	sqlInvoicesOverdue = `
+	SELECT invoice_id
+	FROM invoices
+	WHERE state IN ('pending', 'partially_paid') AND expires_at <= ?
+	ORDER BY created_at, invoice_id
+	LIMIT ?
+	`
+
+	sqlInvoicesExpireDestinations = `
+	UPDATE destinations
+	SET state = 'deleted', updated_at = ?, deleted_at = ?
+	WHERE state = 'pending'
+	AND destination_id IN (SELECT destination_id FROM destination_invoice WHERE invoice_id IN (?))
+	`
+
+	sqlInvoicesExpire = `
+	UPDATE invoices
+	SET state = 'expired', updated_at = ?
+	WHERE state IN ('pending', 'partially_paid') AND invoice_id IN (?)
+	`
+
+	sqlInvoicesExpired = `
+	SELECT invoice_id, satoshis, satoshis_received, satoshis_refunded, description, spv_required, payment_reference, payment_received_at, expires_at, state, refund_to, refunded_at, created_at, updated_at, deleted_at
+	FROM invoices
+	WHERE state = 'expired' AND invoice_id IN (?)
+	ORDER BY created_at, invoice_id
+	`
 )
 
 // Invoice will return an invoice that matches the provided args.
@@ -63,19 +139,17 @@ func (s *sqliteStore) Invoice(ctx context.Context, args payd.InvoiceArgs) (*payd
 	return &resp, nil
 }
 
-// Invoice will return an invoice that matches the provided args.
-func (s *sqliteStore) Invoices(ctx context.Context) ([]payd.Invoice, error) {
+// Invoices will return the invoices matching args, deleted invoices are never returned.
+func (s *sqliteStore) Invoices(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
+	query, params := invoiceSearch(args)
 	var resp []payd.Invoice
-	if err := s.db.SelectContext(ctx, &resp, sqlInvoices); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, lathos.NewErrNotFound(errcodes.ErrInvoicesNotFound, "no invoices found")
-		}
+	if err := s.db.SelectContext(ctx, &resp, query, params...); err != nil {
 		return nil, errors.Wrapf(err, "failed to get invoices")
 	}
 	return resp, nil
 }
 
-// InvoicesPending will return any invoices that have the status 'pending`.
+// InvoicesPending will return any invoices that are pending or partially paid.
 func (s *sqliteStore) InvoicesPending(ctx context.Context) ([]payd.Invoice, error) {
 	var resp []payd.Invoice
 	if err := s.db.SelectContext(ctx, &resp, sqlPendingInvoices); err != nil {
@@ -87,6 +161,24 @@ func (s *sqliteStore) InvoicesPending(ctx context.Context) ([]payd.Invoice, erro
 	return resp, nil
 }
 
+// InvoicePayments will return the payments made against an invoice, oldest first.
+func (s *sqliteStore) InvoicePayments(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoicePayment, error) {
+	var resp []payd.InvoicePayment
+	if err := s.db.SelectContext(ctx, &resp, sqlInvoicePayments, args.InvoiceID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get payments for invoiceID %s", args.InvoiceID)
+	}
+	return resp, nil
+}
+
+// InvoiceRefunds will return the refunds paid against an invoice, oldest first.
+func (s *sqliteStore) InvoiceRefunds(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoiceRefund, error) {
+	var resp []payd.InvoiceRefund
+	if err := s.db.SelectContext(ctx, &resp, sqlInvoiceRefunds, args.InvoiceID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get refunds for invoiceID %s", args.InvoiceID)
+	}
+	return resp, nil
+}
+
 // Create will persist a new Invoice in the data store.
 func (s *sqliteStore) InvoiceCreate(ctx context.Context, req payd.InvoiceCreate) (*payd.Invoice, error) {
 	tx, err := s.newTx(ctx)
@@ -97,7 +189,10 @@ func (s *sqliteStore) InvoiceCreate(ctx context.Context, req payd.InvoiceCreate)
 		_ = rollback(ctx, tx)
 	}()
 	if err := handleNamedExec(tx, sqlCreateInvoice, req); err != nil {
-		return nil, errors.Wrap(err, "failed to insert invoice for ")
+		if isConstraintErr(err) {
+			return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("invoice with invoiceID %s already exists", req.InvoiceID))
+		}
+		return nil, errors.Wrapf(err, "failed to insert invoice with invoiceID %s", req.InvoiceID)
 	}
 	var resp payd.Invoice
 	if err := tx.Get(&resp, sqlInvoiceByID, req.InvoiceID); err != nil {
@@ -109,7 +204,8 @@ func (s *sqliteStore) InvoiceCreate(ctx context.Context, req payd.InvoiceCreate)
 	return &resp, nil
 }
 
-// Update will update an invoice to mark it paid and return the result.
+// InvoiceUpdate will record a payment against an invoice, marking it paid once the
+// satoshis received cover it or partially paid if not, and return the result.
 func (s *sqliteStore) InvoiceUpdate(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
 	tx, err := s.newTx(ctx)
 	if err != nil {
@@ -129,6 +225,120 @@ func (s *sqliteStore) InvoiceUpdate(ctx context.Context, args payd.InvoiceUpdate
 	return resp, nil
 }
 
+// InvoiceRefund will record a refund against an invoice, marking it refunded once the
+// refunds cover the satoshis received, and return the result.
+// Only paid, partially paid or expired invoices are updated, and only when the refunds don't exceed the
+// satoshis received, otherwise the invoice is returned unchanged.
+func (s *sqliteStore) InvoiceRefund(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to refund invoice with invoiceID %s", args.InvoiceID)
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	if !req.RefundedAt.Valid {
+		req.RefundedAt = null.TimeFrom(time.Now().UTC())
+	}
+	res, err := tx.NamedExec(sqlInvoiceRefund, map[string]interface{}{
+		"satoshis":   req.Satoshis,
+		"refundedAt": req.RefundedAt,
+		"invoice_id": args.InvoiceID,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to refund invoice for invoiceID %s", args.InvoiceID)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to refund invoice for invoiceID %s", args.InvoiceID)
+	}
+	if rows > 0 {
+		if err := handleNamedExec(tx, sqlInvoiceRefundCreate, payd.InvoiceRefund{
+			InvoiceID: args.InvoiceID,
+			TxID:      req.TxID,
+			Satoshis:  req.Satoshis,
+			RefundTo:  req.RefundTo.ValueOrZero(),
+			CreatedAt: req.RefundedAt.Time,
+		}); err != nil {
+			if isConstraintErr(err) {
+				return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("refund for tx %s has already been recorded against invoiceID %s", req.TxID, args.InvoiceID))
+			}
+			return nil, errors.Wrapf(err, "failed to insert refund for invoiceID %s", args.InvoiceID)
+		}
+	}
+	var resp payd.Invoice
+	if err := tx.Get(&resp, sqlInvoiceByID, args.InvoiceID); err != nil {
+		return nil, errors.Wrapf(err, "failed to get invoice with invoiceID %s after refund", args.InvoiceID)
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit transaction when refunding invoice with invoiceID %s", args.InvoiceID)
+	}
+	return &resp, nil
+}
+
+// InvoicesExpire will move the overdue pending or partially paid invoices to the expired
+// state, deleting their destinations still waiting to be paid, and return them.
+func (s *sqliteStore) InvoicesExpire(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup sql transaction when expiring invoices")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	// overdue invoices are expired in batches to bound the ids in each statement, an expired
+	// invoice is no longer overdue so each batch reads the next.
+	now := time.Now().UTC()
+	resp := []payd.Invoice{}
+	for {
+		var ids []string
+		if err := tx.Select(&ids, sqlInvoicesOverdue, args.ExpiresBefore.UTC(), args.Batch()); err != nil {
+			return nil, errors.Wrap(err, "failed to get overdue invoices")
+		}
+		if len(ids) == 0 {
+			break
+		}
+		ii, err := invoicesExpire(tx, ids, now)
+		if err != nil {
+			return nil, err
+		}
+		resp = append(resp, ii...)
+		if len(ids) < args.Batch() {
+			break
+		}
+	}
+	if err := commit(ctx, tx); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction when expiring invoices")
+	}
+	return resp, nil
+}
+
+// invoicesExpire expires the invoices with ids, deleting their destinations still waiting
+// to be paid, and returns them.
+func invoicesExpire(tx *sqlx.Tx, ids []string, now time.Time) ([]payd.Invoice, error) {
+	query, sqlArgs, err := sqlx.In(sqlInvoicesExpireDestinations, now, now, ids)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create sql for deleting expired invoice destinations")
+	}
+	if _, err := tx.Exec(tx.Rebind(query), sqlArgs...); err != nil {
+		return nil, errors.Wrap(err, "failed to delete expired invoice destinations")
+	}
+	if query, sqlArgs, err = sqlx.In(sqlInvoicesExpire, now, ids); err != nil {
+		return nil, errors.Wrap(err, "failed to create sql for expiring invoices")
+	}
+	if _, err := tx.Exec(tx.Rebind(query), sqlArgs...); err != nil {
+		return nil, errors.Wrap(err, "failed to expire invoices")
+	}
+	if query, sqlArgs, err = sqlx.In(sqlInvoicesExpired, ids); err != nil {
+		return nil, errors.Wrap(err, "failed to create sql for getting expired invoices")
+	}
+	var resp []payd.Invoice
+	if err := tx.Select(&resp, tx.Rebind(query), sqlArgs...); err != nil {
+		return nil, errors.Wrap(err, "failed to get invoices after expiry")
+	}
+	return resp, nil
+}
+
 func (s *sqliteStore) InvoiceDelete(ctx context.Context, args payd.InvoiceArgs) error {
 	tx, err := s.newTx(ctx)
 	if err != nil {
@@ -160,22 +370,94 @@ func (s *sqliteStore) InvoiceDelete(ctx context.Context, args payd.InvoiceArgs)
 	return nil
 }
 
-// txUpdateInvoicePaid takes a db object / transaction and adds a transaction to the data store
-// along with utxos, returning the updated invoice.
+// txUpdateInvoicePaid takes a db object / transaction and records a payment against the invoice,
+// marking it paid or partially paid and returning the updated invoice. Invoices that aren't
+// waiting on payment are returned unchanged.
 // This method can be used with other methods in the store allowing
 // multiple methods to be ran in the same db transaction.
 func (s *sqliteStore) txUpdateInvoicePaid(tx db, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
 	req.PaymentReceivedAt = time.Now().UTC()
-	if err := handleNamedExec(tx, sqlInvoiceUpdate, map[string]interface{}{
+	refundTo := null.NewString(req.RefundTo, req.RefundTo != "")
+	res, err := tx.NamedExec(sqlInvoiceUpdate, map[string]interface{}{
+		"satoshis":          req.Satoshis,
 		"paymentReceivedAt": req.PaymentReceivedAt,
-		"refundTo":          req.RefundTo,
+		"refundTo":          refundTo,
 		"invoice_id":        args.InvoiceID,
-	}); err != nil {
+	})
+	if err != nil {
 		return nil, errors.Wrapf(err, "failed to update invoice for invoiceID %s", args.InvoiceID)
 	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update invoice for invoiceID %s", args.InvoiceID)
+	}
+	if rows > 0 {
+		if err := handleNamedExec(tx, sqlInvoicePaymentCreate, payd.InvoicePayment{
+			InvoiceID: args.InvoiceID,
+			TxID:      req.TxID,
+			Satoshis:  req.Satoshis,
+			RefundTo:  refundTo,
+			CreatedAt: req.PaymentReceivedAt,
+		}); err != nil {
+			if isConstraintErr(err) {
+				return nil, lathos.NewErrDuplicate("D001", fmt.Sprintf("payment for tx %s has already been recorded against invoiceID %s", req.TxID, args.InvoiceID))
+			}
+			return nil, errors.Wrapf(err, "failed to insert payment for invoiceID %s", args.InvoiceID)
+		}
+	}
 	var resp payd.Invoice
 	if err := tx.Get(&resp, sqlInvoiceByID, args.InvoiceID); err != nil {
 		return nil, errors.Wrapf(err, "failed to get invoice with invoiceID %s after update", args.InvoiceID)
 	}
 	return &resp, nil
 }
+
+// invoiceSearch builds the query and params used to search invoices.
+func invoiceSearch(args payd.InvoiceSearchArgs) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString(sqlInvoices)
+	var params []interface{}
+	where := func(clause string, vv ...interface{}) {
+		sb.WriteString(" AND " + clause)
+		params = append(params, vv...)
+	}
+	if args.State != "" {
+		where("state = ?", string(args.State))
+	}
+	if args.Reference != "" {
+		where("payment_reference = ?", args.Reference)
+	}
+	if args.CreatedFrom.Valid {
+		where("created_at >= ?", args.CreatedFrom.Time.UTC())
+	}
+	if args.CreatedTo.Valid {
+		where("created_at < ?", args.CreatedTo.Time.UTC())
+	}
+	if args.ExpiresFrom.Valid {
+		where("expires_at >= ?", args.ExpiresFrom.Time.UTC())
+	}
+	if args.ExpiresTo.Valid {
+		where("expires_at < ?", args.ExpiresTo.Time.UTC())
+	}
+	if args.SatoshisMin > 0 {
+		where("satoshis >= ?", args.SatoshisMin)
+	}
+	if args.SatoshisMax > 0 {
+		where("satoshis <= ?", args.SatoshisMax)
+	}
+	order, cmp := "ASC", ">"
+	if args.Descending() {
+		order, cmp = "DESC", "<"
+	}
+	if !args.Cursor.IsZero() {
+		createdAt := args.Cursor.CreatedAt.UTC()
+		where(fmt.Sprintf("(created_at %s ? OR (created_at = ? AND invoice_id %s ?))", cmp, cmp),
+			createdAt, createdAt, args.Cursor.InvoiceID)
+	}
+	fmt.Fprintf(&sb, " ORDER BY created_at %s, invoice_id %s", order, order)
+	if args.Limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		params = append(params, args.Limit)
+	}
+	return sb.String(), params
+}
@@ -0,0 +1,2 @@
+-- the mnemonic of a key is kept until it is revealed for backup.
+ALTER TABLE keys ADD COLUMN mnemonic VARCHAR;
@@ -0,0 +1,2 @@
+-- invoices are searched and paged in creation order.
+CREATE INDEX idx_invoices_created_at ON invoices (created_at, invoice_id);
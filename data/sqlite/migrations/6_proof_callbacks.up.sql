@@ -0,0 +1,30 @@
+-- proof callbacks are supplied with each payment so they are keyed by the transaction
+-- paying the invoice, once its proof is received the envelope is stored and posted to
+-- the callback by a background worker until it is sent or runs out of attempts.
+CREATE TABLE proof_callbacks_new(
+    invoice_id          VARCHAR NOT NULL
+    ,tx_id              CHAR(64) NOT NULL
+    ,url                VARCHAR NOT NULL
+    ,token              VARCHAR
+    ,state              VARCHAR NOT NULL
+    ,attempts           INTEGER NOT NULL DEFAULT 0
+    ,proof              BLOB
+    ,next_attempt_at    TIMESTAMP
+    ,last_error         VARCHAR
+    ,created_at         TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,updated_at         TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    ,PRIMARY KEY(tx_id, url)
+    ,FOREIGN KEY (invoice_id) REFERENCES invoices(invoice_id)
+    ,FOREIGN KEY (tx_id) REFERENCES transactions(tx_id)
+);
+
+-- callbacks stored before now belong to the single payment made against their invoice.
+INSERT INTO proof_callbacks_new(invoice_id, tx_id, url, token, state, attempts, created_at, updated_at)
+SELECT pc.invoice_id, p.tx_id, pc.url, pc.token, pc.state, pc.attempts, pc.created_at, pc.updated_at
+FROM proof_callbacks pc
+    INNER JOIN payments p ON p.invoice_id = pc.invoice_id;
+
+DROP TABLE proof_callbacks;
+ALTER TABLE proof_callbacks_new RENAME TO proof_callbacks;
+
+CREATE INDEX idx_proof_callbacks_due ON proof_callbacks(state, next_attempt_at);
@@ -0,0 +1,2 @@
+-- frozen txos are never reserved to fund a payment.
+ALTER TABLE txos ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- encrypted keys hold the xpub and the sealed xprv so need more room than a plain xprv,
+-- sqlite doesn't enforce the length of a VARCHAR so the xprv column is left as it is.
+SELECT 1;
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+	lathos "github.com/theflyingcodr/lathos/errs"
 
 	// test here.
 	_ "github.com/mattn/go-sqlite3"
@@ -13,15 +15,27 @@ import (
 )
 
 const (
-	keyByUserID = `
-	SELECT user_id, name, xprv, createdAt
+	keyByUserIDName = `
+	SELECT user_id, name, xprv, mnemonic, createdAt
 	FROM keys
-	WHERE user_id = :user_id
+	WHERE user_id = :user_id AND name = :name
+	`
+
+	keys = `
+	SELECT user_id, name, xprv, mnemonic, createdAt
+	FROM keys
+	ORDER BY user_id, name
 	`
 
 	createKey = `
-	INSERT INTO keys(user_id, name, xprv)
-	VALUES(:user_id, :name, :xprv)
+	INSERT INTO keys(user_id, name, xprv, mnemonic)
+	VALUES(:user_id, :name, :xprv, :mnemonic)
+	`
+
+	updateKey = `
+	UPDATE keys
+	SET xprv = :xprv, mnemonic = :mnemonic
+	WHERE user_id = :user_id AND name = :name
 	`
 )
 
@@ -29,7 +43,7 @@ const (
 // If not found an error will be returned.
 func (s *sqliteStore) PrivateKey(ctx context.Context, args payd.KeyArgs) (*payd.PrivateKey, error) {
 	var resp payd.PrivateKey
-	if err := s.db.Get(&resp, keyByUserID, args.UserID); err != nil {
+	if err := s.db.Get(&resp, keyByUserIDName, args.UserID, args.Name); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -49,7 +63,6 @@ func (s *sqliteStore) PrivateKeyCreate(ctx context.Context, req payd.PrivateKey)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to add key named '%s'", req.Name)
 	}
-	fmt.Printf("%+v", res)
 	rows, err := res.RowsAffected()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get rows affected when creating private key")
@@ -58,8 +71,43 @@ func (s *sqliteStore) PrivateKeyCreate(ctx context.Context, req payd.PrivateKey)
 		return nil, errors.Wrap(err, "no rows affected when creating private key")
 	}
 	var resp payd.PrivateKey
-	if err := tx.Get(&resp, keyByUserID, req.UserID); err != nil {
+	if err := tx.Get(&resp, keyByUserIDName, req.UserID, req.Name); err != nil {
 		return nil, errors.Wrapf(err, "failed to get key named %s from datastore", req.Name)
 	}
 	return &resp, errors.Wrap(tx.Commit(), "failed to commit create key tx")
 }
+
+// PrivateKeys will return every key in the datastore.
+func (s *sqliteStore) PrivateKeys(ctx context.Context) ([]payd.PrivateKey, error) {
+	resp := []payd.PrivateKey{}
+	if err := s.db.SelectContext(ctx, &resp, keys); err != nil {
+		return nil, errors.Wrap(err, "failed to get keys from datastore")
+	}
+	return resp, nil
+}
+
+// PrivateKeysUpdate will replace the xprv of each key, if any aren't found none are updated.
+func (s *sqliteStore) PrivateKeysUpdate(ctx context.Context, req []payd.PrivateKey) error {
+	tx, err := s.newTx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin tx when updating keys")
+	}
+	defer func() {
+		_ = rollback(ctx, tx)
+	}()
+	for _, k := range req {
+		res, err := tx.NamedExecContext(ctx, updateKey, k)
+		if err != nil {
+			return errors.Wrapf(err, "failed to update key named '%s'", k.Name)
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrapf(err, "failed to update key named '%s'", k.Name)
+		}
+		if rows == 0 {
+			return lathos.NewErrNotFound(errcodes.ErrPrivateKeyNotFound,
+				fmt.Sprintf("key named '%s' for user %d not found", k.Name, k.UserID))
+		}
+	}
+	return errors.Wrap(commit(ctx, tx), "failed to commit update keys tx")
+}
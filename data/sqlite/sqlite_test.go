@@ -0,0 +1,33 @@
+package sqlite_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/config/databases"
+	"github.com/libsv/payd/data"
+	"github.com/libsv/payd/data/sqlite"
+	"github.com/libsv/payd/data/storetest"
+	"github.com/libsv/payd/log"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (data.Store, payd.Transacter) {
+		db, err := databases.NewDbSetup().SetupDb(log.Noop{}, &config.Db{
+			Type:       config.DBSqlite,
+			Dsn:        fmt.Sprintf("file:%s?_foreign_keys=true&pooled=true", filepath.Join(t.TempDir(), "wallet.db")),
+			SchemaPath: "migrations",
+			MigrateDb:  true,
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return sqlite.NewSQLiteStore(db), &sqlite.Transacter{}
+	})
+}
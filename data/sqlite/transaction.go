@@ -4,16 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/payd"
 	"github.com/libsv/payd/errcodes"
-	"github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 	lathos "github.com/theflyingcodr/lathos/errs"
-	"gopkg.in/guregu/null.v3"
 )
 
 const (
@@ -28,8 +27,8 @@ const (
 	`
 
 	sqlTxoCreate = `
-		INSERT INTO txos(outpoint, destination_id, tx_id, vout)
-		VALUES(:outpoint,:destination_id, :tx_id, :vout)
+		INSERT INTO txos(outpoint, destination_id, tx_id, vout, satoshis)
+		VALUES(:outpoint, :destination_id, :tx_id, :vout, :satoshis)
 	`
 
 	sqlDestinationSetReceived = `
@@ -38,16 +37,23 @@ const (
 		WHERE destination_id IN(?)
 	`
 
+	// block_height is kept unless the update sets it and is cleared when the tx isn't mined.
 	sqlTransactionUpdateState = `
 		UPDATE transactions
-		SET state = ?, fail_reason = ?, updated_at = ?
+		SET state = ?, fail_reason = ?,
+			block_height = CASE WHEN ? IN ('mined', 'confirmed') THEN COALESCE(?, block_height) END,
+			updated_at = ?
 		WHERE tx_id = ?
 	`
 
-	sqlInvoiceSetPaid = `
-	UPDATE invoices 
-	SET payment_received_at = :timestamp, refund_to=:refundto state = 'paid', updated_at = :timestamp
-	WHERE invoice_id = :invoice_id
+	sqlTransactionProofExists = `
+		SELECT EXISTS(SELECT 1 FROM proofs WHERE tx_id = ? AND stale_at IS NULL)
+	`
+
+	sqlTransactionsConfirm = `
+		UPDATE transactions
+		SET state = 'confirmed', updated_at = ?
+		WHERE state = 'mined' AND block_height <= ?
 	`
 
 	sqlTransactionGet = `
@@ -55,6 +61,18 @@ const (
 	FROM transactions
 	WHERE tx_id=$1
 	`
+
+	sqlTransactions = `
+	SELECT tx.tx_id, tx.tx_hex, tx.state, tx.fail_reason, tx.created_at,
+		COALESCE((SELECT SUM(t.satoshis) FROM txos t WHERE t.tx_id = tx.tx_id), 0) AS received,
+		COALESCE((SELECT SUM(t.satoshis) FROM txos t WHERE t.spending_txid = tx.tx_id), 0) AS spent,
+		(SELECT MIN(t.reserved_for) FROM txos t WHERE t.spending_txid = tx.tx_id) AS paid_to,
+		COALESCE((SELECT MIN(ti.invoice_id) FROM transaction_invoice ti WHERE ti.tx_id = tx.tx_id),
+			(SELECT MIN(r.invoice_id) FROM refunds r WHERE r.tx_id = tx.tx_id)) AS invoice_id,
+		EXISTS(SELECT 1 FROM proofs p WHERE p.tx_id = tx.tx_id AND p.stale_at IS NULL) AS confirmed
+	FROM transactions tx
+	WHERE tx.deleted_at IS NULL
+	`
 )
 
 // TransactionCreate will store a transaction and its txos in the data base.
@@ -69,11 +87,8 @@ func (s *sqliteStore) TransactionCreate(ctx context.Context, req payd.Transactio
 	timestamp := time.Now().UTC()
 	// insert tx and utxos
 	if err := handleNamedExec(tx, sqlTransactionCreate, req); err != nil {
-		var sqlErr sqlite3.Error
-		if ok := errors.As(err, &sqlErr); ok {
-			if sqlErr.Code == sqlite3.ErrConstraint {
-				return lathos.NewErrDuplicate("D001", "transaction has already been stored")
-			}
+		if isConstraintErr(err) {
+			return lathos.NewErrDuplicate("D001", "transaction has already been stored")
 		}
 		return errors.Wrap(err, "failed to insert new transaction")
 	}
@@ -83,30 +98,16 @@ func (s *sqliteStore) TransactionCreate(ctx context.Context, req payd.Transactio
 	}
 
 	// If no invoice id was provided, end here as this is a change tx.
+	// The payment itself is recorded against the invoice by InvoiceUpdate once the tx is broadcast.
 	if req.InvoiceID == "" {
 		return errors.Wrapf(commit(ctx, tx),
 			"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
 	}
 
-	invUpdate := struct {
-		Timestamp time.Time   `db:"timestamp"`
-		InvoiceID string      `db:"invoice_id"`
-		RefundTo  null.String `db:"refundto"`
-	}{
-		Timestamp: timestamp,
-		InvoiceID: req.InvoiceID,
-		RefundTo:  req.RefundTo,
-	}
 	if err = handleNamedExec(tx, sqlTransactionInvoiceCreate, req); err != nil {
 		return errors.Wrapf(err, "failed to create invoice mapping for tx %s invoice %s", req.TxID, req.InvoiceID)
 	}
 
-	if err = handleNamedExec(tx, sqlInvoiceSetPaid, invUpdate); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return lathos.NewErrNotFound(errcodes.ErrInvoiceNotFound, fmt.Sprintf("invoiceID '%s' not found when updating payment received info", req.InvoiceID))
-		}
-	}
-
 	return errors.Wrapf(commit(ctx, tx),
 		"failed to commit transaction when adding tx and outputs for tx '%s'", req.TxID)
 }
@@ -154,7 +155,18 @@ func (s *sqliteStore) TransactionUpdateState(ctx context.Context, args payd.Tran
 	defer func() {
 		_ = rollback(ctx, tx)
 	}()
-	result, err := tx.Exec(sqlTransactionUpdateState, req.State, req.FailReason, time.Now().UTC(), args.TxID)
+	// a tx with a proof on the best chain only goes back to broadcast once its proofs are stale.
+	if req.State != payd.StateTxMined && req.State != payd.StateTxConfirmed {
+		var mined bool
+		if err := tx.Get(&mined, sqlTransactionProofExists, args.TxID); err != nil {
+			return errors.Wrapf(err, "failed to check proofs of transactionId '%s'", args.TxID)
+		}
+		if mined {
+			return lathos.NewErrUnprocessable(errcodes.ErrTxStateMined,
+				fmt.Sprintf("tx '%s' is mined and can't be set %s", args.TxID, req.State))
+		}
+	}
+	result, err := tx.Exec(sqlTransactionUpdateState, req.State, req.FailReason, req.State, req.BlockHeight, time.Now().UTC(), args.TxID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to update transactionId '%s' state to '%s'", args.TxID, req.State)
 	}
@@ -165,6 +177,19 @@ func (s *sqliteStore) TransactionUpdateState(ctx context.Context, args payd.Tran
 		"failed to commit transaction when updating transactionId '%s' state to '%s'", args.TxID, req.State)
 }
 
+// TransactionsConfirm will set mined transactions at or below the max block height to confirmed.
+func (s *sqliteStore) TransactionsConfirm(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error) {
+	result, err := s.db.ExecContext(ctx, sqlTransactionsConfirm, time.Now().UTC(), args.MaxBlockHeight)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to confirm transactions mined at or below height %d", args.MaxBlockHeight)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read confirmed transactions count")
+	}
+	return int(rows), nil
+}
+
 // Tx returns a tx from the internal store.
 func (s *sqliteStore) Tx(ctx context.Context, txID string) (*bt.Tx, error) {
 	var txhex struct {
@@ -179,3 +204,51 @@ func (s *sqliteStore) Tx(ctx context.Context, txID string) (*bt.Tx, error) {
 
 	return bt.NewTxFromString(txhex.TxHex)
 }
+
+// Transactions returns the txs matching args, ordered by creation date then id, with
+// the wallet txos they received and spent totalled.
+func (s *sqliteStore) Transactions(ctx context.Context, args payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+	query, params := transactionSearch(args)
+	resp := []payd.TransactionHistory{}
+	if err := s.db.SelectContext(ctx, &resp, query, params...); err != nil {
+		return nil, errors.Wrap(err, "failed to get transactions")
+	}
+	return resp, nil
+}
+
+// transactionSearch builds the query and params to find the txs matching args.
+func transactionSearch(args payd.TransactionSearchArgs) (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString(sqlTransactions)
+	var params []interface{}
+	where := func(clause string, vv ...interface{}) {
+		sb.WriteString(" AND " + clause)
+		params = append(params, vv...)
+	}
+	if args.State != "" {
+		where("tx.state = ?", string(args.State))
+	}
+	// tx created_at defaults to CURRENT_TIMESTAMP which is stored in a different text format
+	// to bound times, so both are normalised with datetime before they are compared.
+	if args.CreatedFrom.Valid {
+		where("datetime(tx.created_at) >= datetime(?)", args.CreatedFrom.Time.UTC())
+	}
+	if args.CreatedTo.Valid {
+		where("datetime(tx.created_at) < datetime(?)", args.CreatedTo.Time.UTC())
+	}
+	order, cmp := "ASC", ">"
+	if args.Descending() {
+		order, cmp = "DESC", "<"
+	}
+	if !args.Cursor.IsZero() {
+		createdAt := args.Cursor.CreatedAt.UTC()
+		where(fmt.Sprintf("(datetime(tx.created_at) %s datetime(?) OR (datetime(tx.created_at) = datetime(?) AND tx.tx_id %s ?))", cmp, cmp),
+			createdAt, createdAt, args.Cursor.TxID)
+	}
+	fmt.Fprintf(&sb, " ORDER BY tx.created_at %s, tx.tx_id %s", order, order)
+	if args.Limit > 0 {
+		sb.WriteString(" LIMIT ?")
+		params = append(params, args.Limit)
+	}
+	return sb.String(), params
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 
-	"github.com/libsv/go-bk/bip32"
 	"github.com/libsv/payd"
 	"github.com/pkg/errors"
 	lerrs "github.com/theflyingcodr/lathos/errs"
@@ -18,7 +17,7 @@ const (
 	`
 
 	sqlGetUserByID = `
-		SELECT u.user_id, u.name, u.avatar_url, u.email, u.phone_number, u.address, k.xprv 
+		SELECT u.user_id, u.name, u.avatar_url, u.email, u.phone_number, u.address 
 		FROM users u
 		JOIN keys k ON u.user_id = k.user_id
 		WHERE k.user_id = :user_id
@@ -92,7 +91,6 @@ func (s *sqliteStore) ReadUser(ctx context.Context, userID uint64) (*payd.User,
 		Avatar      string `db:"avatar_url"`
 		Address     string `db:"address"`
 		PhoneNumber string `db:"phone_number"`
-		Xprv        string `db:"xprv"`
 	}
 
 	if err := s.db.GetContext(ctx, &data, sqlGetUserByID, userID); err != nil {
@@ -102,11 +100,6 @@ func (s *sqliteStore) ReadUser(ctx context.Context, userID uint64) (*payd.User,
 		return nil, errors.Wrap(err, "failed to get <resource>")
 	}
 
-	xPriv, err := bip32.NewKeyFromString(data.Xprv)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse key from database xpriv")
-	}
-
 	meta := make([]struct {
 		Key   string `db:"key"`
 		Value string `db:"value"`
@@ -124,7 +117,6 @@ func (s *sqliteStore) ReadUser(ctx context.Context, userID uint64) (*payd.User,
 		Address:      data.Address,
 		PhoneNumber:  data.PhoneNumber,
 		ExtendedData: make(map[string]interface{}),
-		MasterKey:    xPriv,
 	}
 
 	for _, v := range meta {
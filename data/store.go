@@ -0,0 +1,31 @@
+package data
+
+import (
+	"github.com/libsv/go-bc/spv"
+
+	"github.com/libsv/payd"
+)
+
+// Store is the full set of data interfaces a payd data store must implement,
+// each supported database provides one.
+type Store interface {
+	payd.InvoiceReaderWriter
+	payd.DestinationsReaderWriter
+	payd.DerivationReader
+	payd.TxoReaderWriter
+	payd.TransactionReader
+	payd.TransactionWriter
+	payd.PeerChannelsStore
+	payd.PrivateKeyReaderWriter
+	payd.UserStore
+	payd.OwnerStore
+	payd.BalanceReader
+	payd.FeeQuoteReader
+	payd.FeeQuoteWriter
+	payd.ProofsReader
+	payd.ProofsWriter
+	payd.ProofCallbackReaderWriter
+	payd.WebhookReaderWriter
+	spv.TxStore
+	spv.MerkleProofStore
+}
@@ -0,0 +1,2031 @@
+// Package storetest contains a conformance suite that every payd data store
+// must pass, this ensures all supported databases behave identically.
+//
+// A store package runs the suite from its own tests by supplying a SetupFunc
+// that returns a new, empty and fully migrated store:
+//
+//	func TestStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T) (data.Store, payd.Transacter) {
+//			...
+//		})
+//	}
+package storetest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/chaincfg"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theflyingcodr/lathos"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/data"
+)
+
+// EnvRequired fails rather than skips the suite of a store whose database connection
+// string isn't set, CI sets it so a store suite can't pass without running.
+const EnvRequired = "PAYD_TEST_STORES_REQUIRED"
+
+// DSN returns the database connection string held in the env var, skipping the test
+// when it isn't set unless EnvRequired is set.
+func DSN(t *testing.T, env string) string {
+	dsn := os.Getenv(env)
+	if dsn != "" {
+		return dsn
+	}
+	if os.Getenv(EnvRequired) != "" {
+		t.Fatalf("%s not set but %s is, the store tests must run", env, EnvRequired)
+	}
+	t.Skipf("%s not set, skipping store tests", env)
+	return ""
+}
+
+// SetupFunc returns a new, empty and fully migrated store along with the
+// transacter used to manage its transactions.
+// It is called once per test so tests never share state.
+type SetupFunc func(t *testing.T) (data.Store, payd.Transacter)
+
+// Run will execute the conformance suite against stores returned from setup.
+func Run(t *testing.T, setup SetupFunc) {
+	tests := map[string]func(t *testing.T, s data.Store, tr payd.Transacter){
+		"invoices":        testInvoices,
+		"invoice search":  testInvoiceSearch,
+		"payments":        testPayments,
+		"refunds":         testRefunds,
+		"invoice expiry":  testInvoiceExpiry,
+		"destinations":    testDestinations,
+		"private keys":    testPrivateKeys,
+		"users":           testUsers,
+		"transactions":    testTransactions,
+		"confirmations":   testTransactionsConfirm,
+		"tx history":      testTransactionHistory,
+		"utxos":           testUTXOs,
+		"utxo search":     testUTXOSearch,
+		"balance":         testBalance,
+		"fee quotes":      testFeeQuotes,
+		"proofs":          testProofs,
+		"stale proofs":    testProofsStale,
+		"proof callbacks": testProofCallbacks,
+		"peer channels":   testPeerChannels,
+		"webhooks":        testWebhooks,
+		"transacter":      testTransacter,
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			s, tr := setup(t)
+			test(t, s, tr)
+		})
+	}
+}
+
+// randomHex returns a random hex string of n bytes.
+func randomHex(t *testing.T, n int) string {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return hex.EncodeToString(b)
+}
+
+// newScript returns a unique p2pkh locking script.
+func newScript(t *testing.T) *bscript.Script {
+	s, err := bscript.NewP2PKHFromPubKeyHashStr(randomHex(t, 20))
+	require.NoError(t, err)
+	return s
+}
+
+// newTx returns a tx paying the supplied outputs, the input is random so each
+// tx created has a unique id.
+func newTx(t *testing.T, outs ...payd.Output) *bt.Tx {
+	tx := bt.NewTx()
+	require.NoError(t, tx.From(randomHex(t, 32), 0, newScript(t).String(), 10000))
+	for _, o := range outs {
+		tx.AddOutput(&bt.Output{
+			Satoshis:      o.Satoshis,
+			LockingScript: o.LockingScript,
+		})
+	}
+	return tx
+}
+
+// newXprv returns a new random extended private key string.
+func newXprv(t *testing.T) string {
+	seed, err := bip32.GenerateSeed(bip32.RecommendedSeedLen)
+	require.NoError(t, err)
+	key, err := bip32.NewMaster(seed, &chaincfg.TestNet)
+	require.NoError(t, err)
+	return key.String()
+}
+
+// destinationsCreate stores a destination per satoshi amount, linking them to invoiceID if not empty.
+func destinationsCreate(t *testing.T, s data.Store, invoiceID string, satoshis ...uint64) []payd.Output {
+	req := make([]payd.DestinationCreate, 0, len(satoshis))
+	for i, sats := range satoshis {
+		req = append(req, payd.DestinationCreate{
+			Script:         newScript(t).String(),
+			DerivationPath: randomHex(t, 4) + "/" + string(rune('0'+i)),
+			Satoshis:       sats,
+			UserID:         1,
+			KeyName:        "masterkey",
+		})
+	}
+	args := payd.DestinationsCreateArgs{}
+	if invoiceID != "" {
+		args.InvoiceID = null.StringFrom(invoiceID)
+	}
+	oo, err := s.DestinationsCreate(context.Background(), args, req)
+	require.NoError(t, err)
+	require.Len(t, oo, len(satoshis))
+	return oo
+}
+
+// transactionCreate stores a tx paying outs, the outputs are written as txos.
+func transactionCreate(t *testing.T, s data.Store, invoiceID string, outs ...payd.Output) *bt.Tx {
+	tx := newTx(t, outs...)
+	txos := make([]*payd.TxoCreate, 0, len(outs))
+	for i, o := range outs {
+		txos = append(txos, &payd.TxoCreate{
+			Outpoint:      fmt.Sprintf("%s%d", tx.TxID(), i),
+			DestinationID: o.ID,
+			TxID:          tx.TxID(),
+			Vout:          uint64(i),
+			Satoshis:      o.Satoshis,
+		})
+	}
+	require.NoError(t, s.TransactionCreate(context.Background(), payd.TransactionCreate{
+		InvoiceID: invoiceID,
+		TxID:      tx.TxID(),
+		TxHex:     tx.String(),
+		Outputs:   txos,
+	}))
+	return tx
+}
+
+// invoicePay stores a tx paying sats to dest and records it as a payment against the invoice.
+func invoicePay(t *testing.T, s data.Store, invoiceID string, dest payd.Output, sats uint64, refundTo string) (*payd.Invoice, *bt.Tx) {
+	dest.Satoshis = sats
+	tx := transactionCreate(t, s, invoiceID, dest)
+	inv, err := s.InvoiceUpdate(context.Background(), payd.InvoiceUpdateArgs{InvoiceID: invoiceID}, payd.InvoiceUpdatePaid{
+		PaymentReceivedAt: time.Now().UTC(),
+		RefundTo:          refundTo,
+		TxID:              tx.TxID(),
+		Satoshis:          sats,
+	})
+	require.NoError(t, err)
+	return inv, tx
+}
+
+func invoiceCreate(t *testing.T, s data.Store, sats uint64) *payd.Invoice {
+	inv, err := s.InvoiceCreate(context.Background(), payd.InvoiceCreate{
+		InvoiceID:   randomHex(t, 8),
+		Satoshis:    sats,
+		Reference:   null.StringFrom("ref"),
+		Description: null.StringFrom("invoice for oranges"),
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   null.TimeFrom(time.Now().UTC().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	return inv
+}
+
+func testInvoices(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv1 := invoiceCreate(t, s, 1000)
+	assert.Equal(t, uint64(1000), inv1.Satoshis)
+	assert.Equal(t, payd.StateInvoicePending, inv1.State)
+	assert.Equal(t, "ref", inv1.Reference.ValueOrZero())
+	assert.Equal(t, "invoice for oranges", inv1.Description.ValueOrZero())
+	assert.False(t, inv1.PaymentReceivedAt.Valid)
+	inv2 := invoiceCreate(t, s, 2000)
+
+	_, err := s.InvoiceCreate(ctx, payd.InvoiceCreate{
+		InvoiceID: inv1.ID,
+		Satoshis:  1000,
+		CreatedAt: time.Now().UTC(),
+	})
+	assert.True(t, lathos.IsDuplicate(err), "expected duplicate error, got %v", err)
+
+	got, err := s.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv1.ID})
+	require.NoError(t, err)
+	assert.Equal(t, inv1.ID, got.ID)
+	assert.Equal(t, inv1.Satoshis, got.Satoshis)
+
+	_, err = s.Invoice(ctx, payd.InvoiceArgs{InvoiceID: "unknown"})
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+
+	ii, err := s.Invoices(ctx, payd.InvoiceSearchArgs{})
+	require.NoError(t, err)
+	assert.Len(t, ii, 2)
+
+	paid, _ := invoicePay(t, s, inv2.ID, destinationsCreate(t, s, inv2.ID, 2000)[0], 2000, "refund@example.com")
+	assert.Equal(t, payd.StateInvoicePaid, paid.State)
+	assert.Equal(t, uint64(2000), paid.SatoshisReceived)
+	assert.True(t, paid.PaymentReceivedAt.Valid)
+	assert.Equal(t, "refund@example.com", paid.RefundTo.ValueOrZero())
+
+	pending, err := s.InvoicesPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, inv1.ID, pending[0].ID)
+
+	require.NoError(t, s.InvoiceDelete(ctx, payd.InvoiceArgs{InvoiceID: inv1.ID}))
+	_, err = s.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv1.ID})
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+	ii, err = s.Invoices(ctx, payd.InvoiceSearchArgs{})
+	require.NoError(t, err)
+	assert.Len(t, ii, 1)
+
+	err = s.InvoiceDelete(ctx, payd.InvoiceArgs{InvoiceID: inv1.ID})
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+}
+
+func testPayments(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv := invoiceCreate(t, s, 1000)
+	dest := destinationsCreate(t, s, inv.ID, 1000)[0]
+	pp, err := s.InvoicePayments(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Empty(t, pp)
+
+	// a part payment leaves the invoice partially paid.
+	part, tx1 := invoicePay(t, s, inv.ID, dest, 400, "")
+	assert.Equal(t, payd.StateInvoicePartiallyPaid, part.State)
+	assert.Equal(t, uint64(400), part.SatoshisReceived)
+	assert.False(t, part.PaymentReceivedAt.Valid)
+	assert.False(t, part.RefundTo.Valid)
+
+	pending, err := s.InvoicesPending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, inv.ID, pending[0].ID)
+
+	ii, err := s.Invoices(ctx, payd.InvoiceSearchArgs{State: payd.StateInvoicePartiallyPaid})
+	require.NoError(t, err)
+	require.Len(t, ii, 1)
+	assert.Equal(t, inv.ID, ii[0].ID)
+
+	// the same tx can't be recorded twice.
+	_, err = s.InvoiceUpdate(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdatePaid{
+		TxID:     tx1.TxID(),
+		Satoshis: 400,
+	})
+	assert.True(t, lathos.IsDuplicate(err), "expected duplicate error, got %v", err)
+	got, err := s.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(400), got.SatoshisReceived)
+
+	// the invoice is paid once the payments cover it, overpayments are kept.
+	paid, tx2 := invoicePay(t, s, inv.ID, dest, 700, "refund@example.com")
+	assert.Equal(t, payd.StateInvoicePaid, paid.State)
+	assert.Equal(t, uint64(1100), paid.SatoshisReceived)
+	assert.True(t, paid.PaymentReceivedAt.Valid)
+	assert.Equal(t, "refund@example.com", paid.RefundTo.ValueOrZero())
+
+	pending, err = s.InvoicesPending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	pp, err = s.InvoicePayments(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	require.Len(t, pp, 2)
+	assert.Equal(t, inv.ID, pp[0].InvoiceID)
+	assert.Equal(t, tx1.TxID(), pp[0].TxID)
+	assert.Equal(t, uint64(400), pp[0].Satoshis)
+	assert.False(t, pp[0].RefundTo.Valid)
+	assert.Equal(t, tx2.TxID(), pp[1].TxID)
+	assert.Equal(t, uint64(700), pp[1].Satoshis)
+	assert.Equal(t, "refund@example.com", pp[1].RefundTo.ValueOrZero())
+
+	// paid invoices are returned unchanged and no payment is recorded.
+	dest.Satoshis = 100
+	tx3 := transactionCreate(t, s, inv.ID, dest)
+	got, err = s.InvoiceUpdate(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdatePaid{
+		TxID:     tx3.TxID(),
+		Satoshis: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1100), got.SatoshisReceived)
+	pp, err = s.InvoicePayments(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Len(t, pp, 2)
+
+	// txos hold the value paid to the destination, not the amount requested.
+	bal, err := s.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, payd.BalanceTotal{Satoshis: 1200, UTXOs: 3}, bal.Pending)
+}
+
+// refundTx stores a tx paying change back to the wallet, as a refund tx would.
+func refundTx(t *testing.T, s data.Store) *bt.Tx {
+	return transactionCreate(t, s, "", destinationsCreate(t, s, "", 100)...)
+}
+
+func testRefunds(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv := invoiceCreate(t, s, 1000)
+	dest := destinationsCreate(t, s, inv.ID, 1000)[0]
+	invoicePay(t, s, inv.ID, dest, 1000, "refund@example.com")
+	rr, err := s.InvoiceRefunds(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Empty(t, rr)
+
+	// invoices that haven't been paid are returned unchanged.
+	pending := invoiceCreate(t, s, 1000)
+	got, err := s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: pending.ID}, payd.InvoiceUpdateRefunded{
+		TxID:     refundTx(t, s).TxID(),
+		Satoshis: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePending, got.State)
+	assert.Zero(t, got.SatoshisRefunded)
+	rr, err = s.InvoiceRefunds(ctx, payd.InvoiceArgs{InvoiceID: pending.ID})
+	require.NoError(t, err)
+	assert.Empty(t, rr)
+
+	// a part refund leaves the invoice paid.
+	tx1 := refundTx(t, s)
+	part, err := s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		RefundTo: null.StringFrom("refund@example.com"),
+		TxID:     tx1.TxID(),
+		Satoshis: 400,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePaid, part.State)
+	assert.Equal(t, uint64(400), part.SatoshisRefunded)
+	assert.True(t, part.RefundedAt.Valid)
+
+	// the same tx can't be recorded twice.
+	_, err = s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		TxID:     tx1.TxID(),
+		Satoshis: 400,
+	})
+	assert.True(t, lathos.IsDuplicate(err), "expected duplicate error, got %v", err)
+
+	// refunds of more than is left to refund leave the invoice unchanged.
+	got, err = s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		TxID:     refundTx(t, s).TxID(),
+		Satoshis: 601,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePaid, got.State)
+	assert.Equal(t, uint64(400), got.SatoshisRefunded)
+
+	// the invoice is refunded once the refunds cover the satoshis received.
+	tx2 := refundTx(t, s)
+	refunded, err := s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		RefundTo: null.StringFrom("refund@example.com"),
+		TxID:     tx2.TxID(),
+		Satoshis: 600,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoiceRefunded, refunded.State)
+	assert.Equal(t, uint64(1000), refunded.SatoshisRefunded)
+	assert.Equal(t, uint64(1000), refunded.SatoshisReceived)
+
+	ii, err := s.Invoices(ctx, payd.InvoiceSearchArgs{State: payd.StateInvoiceRefunded})
+	require.NoError(t, err)
+	require.Len(t, ii, 1)
+	assert.Equal(t, inv.ID, ii[0].ID)
+
+	rr, err = s.InvoiceRefunds(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	require.Len(t, rr, 2)
+	assert.Equal(t, inv.ID, rr[0].InvoiceID)
+	assert.Equal(t, tx1.TxID(), rr[0].TxID)
+	assert.Equal(t, uint64(400), rr[0].Satoshis)
+	assert.Equal(t, "refund@example.com", rr[0].RefundTo)
+	assert.Equal(t, tx2.TxID(), rr[1].TxID)
+	assert.Equal(t, uint64(600), rr[1].Satoshis)
+
+	// refunded invoices are returned unchanged and no refund is recorded.
+	got, err = s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		TxID:     refundTx(t, s).TxID(),
+		Satoshis: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), got.SatoshisRefunded)
+	rr, err = s.InvoiceRefunds(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Len(t, rr, 2)
+}
+
+func testInvoiceExpiry(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	pending := invoiceCreate(t, s, 1000)
+	destinationsCreate(t, s, pending.ID, 500, 500)
+	partial := invoiceCreate(t, s, 1000)
+	partialDests := destinationsCreate(t, s, partial.ID, 500, 500)
+	invoicePay(t, s, partial.ID, partialDests[0], 400, "")
+	paid := invoiceCreate(t, s, 1000)
+	invoicePay(t, s, paid.ID, destinationsCreate(t, s, paid.ID, 1000)[0], 1000, "")
+
+	// the invoices expire in an hour, so none are overdue yet.
+	ii, err := s.InvoicesExpire(ctx, payd.InvoicesExpireArgs{ExpiresBefore: time.Now().UTC()})
+	require.NoError(t, err)
+	assert.Empty(t, ii)
+
+	// stores expiring invoices in batches expire every overdue invoice, oldest first.
+	ii, err = s.InvoicesExpire(ctx, payd.InvoicesExpireArgs{ExpiresBefore: time.Now().UTC().Add(2 * time.Hour), BatchSize: 1})
+	require.NoError(t, err)
+	require.Len(t, ii, 2)
+	assert.Equal(t, pending.ID, ii[0].ID)
+	assert.Equal(t, partial.ID, ii[1].ID)
+	for _, inv := range ii {
+		assert.Equal(t, payd.StateInvoiceExpired, inv.State)
+	}
+	assert.Equal(t, uint64(400), ii[1].SatoshisReceived)
+
+	got, err := s.Invoice(ctx, payd.InvoiceArgs{InvoiceID: paid.ID})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePaid, got.State)
+	pp, err := s.InvoicesPending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pp)
+	ii, err = s.Invoices(ctx, payd.InvoiceSearchArgs{State: payd.StateInvoiceExpired})
+	require.NoError(t, err)
+	assert.Len(t, ii, 2)
+
+	// destinations still waiting to be paid are released.
+	dd, err := s.Destinations(ctx, payd.DestinationsArgs{InvoiceID: pending.ID})
+	require.NoError(t, err)
+	for _, d := range dd {
+		assert.Equal(t, "deleted", d.State)
+	}
+	dd, err = s.Destinations(ctx, payd.DestinationsArgs{InvoiceID: partial.ID})
+	require.NoError(t, err)
+	require.Len(t, dd, 2)
+	for _, d := range dd {
+		if d.LockingScript.String() == partialDests[0].LockingScript.String() {
+			assert.Equal(t, "received", d.State)
+			continue
+		}
+		assert.Equal(t, "deleted", d.State)
+	}
+
+	// expired invoices are only expired once.
+	ii, err = s.InvoicesExpire(ctx, payd.InvoicesExpireArgs{ExpiresBefore: time.Now().UTC().Add(2 * time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, ii)
+
+	// expired invoices take no further payments.
+	tx := transactionCreate(t, s, "", destinationsCreate(t, s, "", 100)...)
+	got, err = s.InvoiceUpdate(ctx, payd.InvoiceUpdateArgs{InvoiceID: pending.ID}, payd.InvoiceUpdatePaid{
+		TxID:     tx.TxID(),
+		Satoshis: 100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoiceExpired, got.State)
+	assert.Zero(t, got.SatoshisReceived)
+
+	// what was received before expiry can be refunded.
+	got, err = s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: partial.ID}, payd.InvoiceUpdateRefunded{
+		TxID:     refundTx(t, s).TxID(),
+		Satoshis: 400,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoiceRefunded, got.State)
+	assert.Equal(t, uint64(400), got.SatoshisRefunded)
+}
+
+func testInvoiceSearch(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	base := time.Now().UTC().Truncate(time.Second)
+	// invoice ids are created in order so invoices sharing a created date sort by id.
+	ids := []string{"search1", "search2", "search3", "search4", "search5", "search6"}
+	for i, id := range ids {
+		createdAt := base.Add(time.Duration(i) * time.Minute)
+		if i == 5 {
+			// shares a created date with the previous invoice.
+			createdAt = base.Add(4 * time.Minute)
+		}
+		ref := "odd"
+		if i%2 == 0 {
+			ref = "even"
+		}
+		_, err := s.InvoiceCreate(ctx, payd.InvoiceCreate{
+			InvoiceID: id,
+			Satoshis:  uint64(i+1) * 1000,
+			Reference: null.StringFrom(ref),
+			CreatedAt: createdAt,
+			ExpiresAt: null.TimeFrom(createdAt.Add(time.Hour)),
+		})
+		require.NoError(t, err)
+	}
+	invoicePay(t, s, "search2", destinationsCreate(t, s, "search2", 2000)[0], 2000, "")
+	require.NoError(t, s.InvoiceDelete(ctx, payd.InvoiceArgs{InvoiceID: "search3"}))
+
+	search := func(args payd.InvoiceSearchArgs) []string {
+		ii, err := s.Invoices(ctx, args)
+		require.NoError(t, err)
+		resp := make([]string, 0, len(ii))
+		for _, inv := range ii {
+			resp = append(resp, inv.ID)
+		}
+		return resp
+	}
+	tests := map[string]struct {
+		args payd.InvoiceSearchArgs
+		exp  []string
+	}{
+		"no filters returns newest first": {
+			exp: []string{"search6", "search5", "search4", "search2", "search1"},
+		},
+		"asc returns oldest first": {
+			args: payd.InvoiceSearchArgs{Sort: payd.SortAsc},
+			exp:  []string{"search1", "search2", "search4", "search5", "search6"},
+		},
+		"state filter": {
+			args: payd.InvoiceSearchArgs{State: payd.StateInvoicePaid},
+			exp:  []string{"search2"},
+		},
+		"reference filter": {
+			args: payd.InvoiceSearchArgs{Reference: "even", Sort: payd.SortAsc},
+			exp:  []string{"search1", "search5"},
+		},
+		"satoshi range": {
+			args: payd.InvoiceSearchArgs{SatoshisMin: 2000, SatoshisMax: 4000, Sort: payd.SortAsc},
+			exp:  []string{"search2", "search4"},
+		},
+		"created range excludes the upper bound": {
+			args: payd.InvoiceSearchArgs{
+				CreatedFrom: null.TimeFrom(base.Add(time.Minute)),
+				CreatedTo:   null.TimeFrom(base.Add(4 * time.Minute)),
+				Sort:        payd.SortAsc,
+			},
+			exp: []string{"search2", "search4"},
+		},
+		"expiry range": {
+			args: payd.InvoiceSearchArgs{
+				ExpiresFrom: null.TimeFrom(base.Add(time.Hour + 3*time.Minute)),
+				Sort:        payd.SortAsc,
+			},
+			exp: []string{"search4", "search5", "search6"},
+		},
+		"limit": {
+			args: payd.InvoiceSearchArgs{Limit: 2},
+			exp:  []string{"search6", "search5"},
+		},
+		"cursor asc returns invoices after the cursor": {
+			args: payd.InvoiceSearchArgs{
+				Sort:   payd.SortAsc,
+				Cursor: payd.InvoiceCursor{CreatedAt: base.Add(4 * time.Minute), InvoiceID: "search5"},
+			},
+			exp: []string{"search6"},
+		},
+		"cursor desc returns invoices before the cursor": {
+			args: payd.InvoiceSearchArgs{
+				Cursor: payd.InvoiceCursor{CreatedAt: base.Add(4 * time.Minute), InvoiceID: "search6"},
+				Limit:  2,
+			},
+			exp: []string{"search5", "search4"},
+		},
+		"filters and cursor combine": {
+			args: payd.InvoiceSearchArgs{
+				State:  payd.StateInvoicePending,
+				Sort:   payd.SortAsc,
+				Cursor: payd.InvoiceCursor{CreatedAt: base, InvoiceID: "search1"},
+				Limit:  2,
+			},
+			exp: []string{"search4", "search5"},
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.exp, search(test.args))
+		})
+	}
+
+	// walking pages one invoice at a time returns every invoice once.
+	var walked []string
+	args := payd.InvoiceSearchArgs{Limit: 1}
+	for {
+		ii, err := s.Invoices(ctx, args)
+		require.NoError(t, err)
+		if len(ii) == 0 {
+			break
+		}
+		walked = append(walked, ii[0].ID)
+		args.Cursor = payd.InvoiceCursor{CreatedAt: ii[0].CreatedAt, InvoiceID: ii[0].ID}
+	}
+	assert.Equal(t, []string{"search6", "search5", "search4", "search2", "search1"}, walked)
+}
+
+func testDestinations(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv := invoiceCreate(t, s, 3000)
+	oo := destinationsCreate(t, s, inv.ID, 1000, 2000)
+	for _, o := range oo {
+		assert.NotZero(t, o.ID)
+		assert.Equal(t, "pending", o.State)
+	}
+
+	got, err := s.Destinations(ctx, payd.DestinationsArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, oo, got)
+
+	// destinations report what each has been paid, over every tx paying it.
+	invoicePay(t, s, inv.ID, oo[0], 400, "")
+	invoicePay(t, s, inv.ID, oo[0], 300, "")
+	got, err = s.Destinations(ctx, payd.DestinationsArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	for _, o := range got {
+		switch o.ID {
+		case oo[0].ID:
+			assert.Equal(t, uint64(700), o.SatoshisReceived)
+		default:
+			assert.Zero(t, o.SatoshisReceived)
+		}
+	}
+
+	_, err = s.Destinations(ctx, payd.DestinationsArgs{InvoiceID: "unknown"})
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+
+	// destinations don't need to be linked to an invoice.
+	destinationsCreate(t, s, "", 500)
+
+	exists, err := s.DerivationPathExists(ctx, payd.DerivationExistsArgs{
+		KeyName: "masterkey",
+		UserID:  1,
+		Path:    oo[0].DerivationPath,
+	})
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = s.DerivationPathExists(ctx, payd.DerivationExistsArgs{
+		KeyName: "masterkey",
+		UserID:  1,
+		Path:    "0/0/0",
+	})
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	paths, err := s.DerivationPaths(ctx, payd.KeyArgs{Name: "masterkey", UserID: 1})
+	require.NoError(t, err)
+	assert.Len(t, paths, 3)
+	assert.Contains(t, paths, payd.DerivationPath{Path: oo[0].DerivationPath, LockingScript: oo[0].LockingScript.String()})
+
+	paths, err = s.DerivationPaths(ctx, payd.KeyArgs{Name: "other", UserID: 1})
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+}
+
+func testPrivateKeys(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	key, err := s.PrivateKey(ctx, payd.KeyArgs{Name: "masterkey", UserID: 1})
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	master := newXprv(t)
+	key, err = s.PrivateKeyCreate(ctx, payd.PrivateKey{Name: "masterkey", UserID: 1, Xprv: master})
+	require.NoError(t, err)
+	assert.Equal(t, master, key.Xprv)
+	assert.False(t, key.CreatedAt.IsZero())
+
+	other := newXprv(t)
+	key, err = s.PrivateKeyCreate(ctx, payd.PrivateKey{Name: "other", UserID: 1, Xprv: other, Mnemonic: null.StringFrom("abandon ability")})
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom("abandon ability"), key.Mnemonic)
+
+	key, err = s.PrivateKey(ctx, payd.KeyArgs{Name: "masterkey", UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, master, key.Xprv)
+	key, err = s.PrivateKey(ctx, payd.KeyArgs{Name: "other", UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, other, key.Xprv)
+
+	keys, err := s.PrivateKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "masterkey", keys[0].Name)
+	assert.Equal(t, master, keys[0].Xprv)
+	assert.Equal(t, "other", keys[1].Name)
+	assert.Equal(t, other, keys[1].Xprv)
+
+	assert.False(t, keys[0].Mnemonic.Valid)
+	assert.Equal(t, null.StringFrom("abandon ability"), keys[1].Mnemonic)
+
+	keys[0].Xprv = "encrypted master"
+	keys[1].Xprv = "encrypted other"
+	keys[1].Mnemonic = null.String{}
+	require.NoError(t, s.PrivateKeysUpdate(ctx, keys))
+	key, err = s.PrivateKey(ctx, payd.KeyArgs{Name: "masterkey", UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted master", key.Xprv)
+	key, err = s.PrivateKey(ctx, payd.KeyArgs{Name: "other", UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted other", key.Xprv)
+	assert.False(t, key.Mnemonic.Valid)
+
+	err = s.PrivateKeysUpdate(ctx, []payd.PrivateKey{
+		{Name: "masterkey", UserID: 1, Xprv: master},
+		{Name: "missing", UserID: 1, Xprv: other},
+	})
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+	key, err = s.PrivateKey(ctx, payd.KeyArgs{Name: "masterkey", UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted master", key.Xprv)
+}
+
+// keyCreator creates user keys directly in the store under test.
+type keyCreator struct {
+	t *testing.T
+	s data.Store
+}
+
+func (k keyCreator) Create(ctx context.Context, keyName string, userID uint64) error {
+	_, err := k.s.PrivateKeyCreate(ctx, payd.PrivateKey{Name: keyName, UserID: userID, Xprv: newXprv(k.t)})
+	return err
+}
+
+func (k keyCreator) PrivateKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+	key, err := k.s.PrivateKey(ctx, payd.KeyArgs{Name: keyName, UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return bip32.NewKeyFromString(key.Xprv)
+}
+
+func (k keyCreator) PrivateKeyImport(ctx context.Context, args payd.KeyArgs, xprv *bip32.ExtendedKey) error {
+	return errors.New("not supported")
+}
+
+func (k keyCreator) MnemonicReveal(ctx context.Context, args payd.KeyArgs) (string, error) {
+	return "", errors.New("not supported")
+}
+
+func (k keyCreator) PublicKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+	key, err := k.PrivateKey(ctx, keyName, userID)
+	if err != nil {
+		return nil, err
+	}
+	return key.Neuter()
+}
+
+func testUsers(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	owner, err := s.Owner(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), owner.ID)
+	assert.Equal(t, map[string]interface{}{
+		"example key 1": "example value 1",
+		"example key 2": "example value 2",
+	}, owner.ExtendedData)
+
+	resp, err := s.CreateUser(ctx, payd.CreateUserArgs{
+		Name:         "Satoshi",
+		Email:        "satoshi@example.com",
+		Avatar:       "https://example.com/avatar.png",
+		Address:      "1 Street",
+		PhoneNumber:  "0123",
+		ExtendedData: map[string]interface{}{"colour": "orange"},
+	}, keyCreator{t: t, s: s})
+	require.NoError(t, err)
+	assert.Greater(t, resp.ID, uint64(1))
+
+	user, err := s.ReadUser(ctx, resp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, resp.ID, user.ID)
+	assert.Equal(t, "Satoshi", user.Name)
+	assert.Equal(t, "satoshi@example.com", user.Email)
+	assert.Equal(t, "https://example.com/avatar.png", user.Avatar)
+	assert.Equal(t, "1 Street", user.Address)
+	assert.Equal(t, "0123", user.PhoneNumber)
+	assert.Equal(t, map[string]interface{}{"colour": "orange"}, user.ExtendedData)
+
+	_, err = s.ReadUser(ctx, 999)
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+}
+
+func testTransactions(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv := invoiceCreate(t, s, 1000)
+	oo := destinationsCreate(t, s, inv.ID, 1000)
+	tx := transactionCreate(t, s, inv.ID, oo...)
+
+	got, err := s.Tx(ctx, tx.TxID())
+	require.NoError(t, err)
+	assert.Equal(t, tx.TxID(), got.TxID())
+
+	err = s.TransactionCreate(ctx, payd.TransactionCreate{
+		InvoiceID: inv.ID,
+		TxID:      tx.TxID(),
+		TxHex:     tx.String(),
+	})
+	assert.True(t, lathos.IsDuplicate(err), "expected duplicate error, got %v", err)
+
+	dd, err := s.Destinations(ctx, payd.DestinationsArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	require.Len(t, dd, 1)
+	assert.Equal(t, "received", dd[0].State)
+
+	require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+		State: payd.StateTxBroadcast,
+	}))
+
+	_, err = s.Tx(ctx, randomHex(t, 32))
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+
+	// change txs have no invoice.
+	change := transactionCreate(t, s, "", destinationsCreate(t, s, "", 500)...)
+	got, err = s.Tx(ctx, change.TxID())
+	require.NoError(t, err)
+	assert.Equal(t, change.TxID(), got.TxID())
+}
+
+func testTransactionsConfirm(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv := invoiceCreate(t, s, 1000)
+	_, tx := invoicePay(t, s, inv.ID, destinationsCreate(t, s, inv.ID, 1000)[0], 1000, "")
+	setState := func(state payd.TxState, height null.Int) {
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State:       state,
+			BlockHeight: height,
+		}))
+	}
+	blockHeight := func() null.Int {
+		got, err := s.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+		require.NoError(t, err)
+		ii, err := s.Invoices(ctx, payd.InvoiceSearchArgs{})
+		require.NoError(t, err)
+		require.Len(t, ii, 1)
+		assert.Equal(t, got.BlockHeight, ii[0].BlockHeight)
+		return got.BlockHeight
+	}
+	state := func() payd.TxState {
+		tt, err := s.Transactions(ctx, payd.TransactionSearchArgs{})
+		require.NoError(t, err)
+		require.Len(t, tt, 1)
+		return tt[0].State
+	}
+
+	setState(payd.StateTxBroadcast, null.Int{})
+	assert.False(t, blockHeight().Valid)
+
+	// an invoice has the height of the block its payments were mined in, it is kept by
+	// updates that don't set it.
+	setState(payd.StateTxMined, null.IntFrom(100))
+	assert.Equal(t, null.IntFrom(100), blockHeight())
+	setState(payd.StateTxMined, null.Int{})
+	assert.Equal(t, null.IntFrom(100), blockHeight())
+
+	n, err := s.TransactionsConfirm(ctx, payd.TransactionsConfirmArgs{MaxBlockHeight: 99})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, payd.StateTxMined, state())
+
+	n, err = s.TransactionsConfirm(ctx, payd.TransactionsConfirmArgs{MaxBlockHeight: 100})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, payd.StateTxConfirmed, state())
+	assert.Equal(t, null.IntFrom(100), blockHeight())
+
+	n, err = s.TransactionsConfirm(ctx, payd.TransactionsConfirmArgs{MaxBlockHeight: 100})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// the height is cleared when a tx leaves the mined and confirmed states.
+	setState(payd.StateTxBroadcast, null.Int{})
+	assert.False(t, blockHeight().Valid)
+}
+
+func testTransactionHistory(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	broadcast := func(tx *bt.Tx, state payd.TxState) {
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State: state,
+		}))
+	}
+
+	// an invoice is paid, the payment is confirmed then spent paying a url with change returned.
+	inv := invoiceCreate(t, s, 1000)
+	_, paid := invoicePay(t, s, inv.ID, destinationsCreate(t, s, inv.ID, 1000)[0], 1000, "refund@example.com")
+	broadcast(paid, payd.StateTxBroadcast)
+	require.NoError(t, s.ProofCreate(ctx, dpp.ProofWrapper{
+		CallbackPayload: &bc.MerkleProof{TxOrID: paid.TxID(), Target: randomHex(t, 32)},
+		BlockHash:       randomHex(t, 32),
+		CallbackTxID:    paid.TxID(),
+		CallbackReason:  "merkleProof",
+	}))
+	uu, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "https://example.com/pay/1", Satoshis: 500, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, uu, 1)
+	sent := transactionCreate(t, s, "", destinationsCreate(t, s, "", 300)...)
+	require.NoError(t, s.UTXOSpend(ctx, payd.UTXOSpend{SpendingTxID: sent.TxID(), Reservation: "https://example.com/pay/1"}))
+	broadcast(sent, payd.StateTxBroadcast)
+
+	// a refund of the invoice.
+	refund := refundTx(t, s)
+	_, err = s.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		RefundTo: null.StringFrom("refund@example.com"),
+		TxID:     refund.TxID(),
+		Satoshis: 100,
+	})
+	require.NoError(t, err)
+	failed := transactionCreate(t, s, "", destinationsCreate(t, s, "", 50)...)
+	require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: failed.TxID()}, payd.TransactionStateUpdate{
+		State:      payd.StateTxFailed,
+		FailReason: null.StringFrom("rejected"),
+	}))
+
+	txs := func(args payd.TransactionSearchArgs) map[string]payd.TransactionHistory {
+		tt, err := s.Transactions(ctx, args)
+		require.NoError(t, err)
+		m := make(map[string]payd.TransactionHistory, len(tt))
+		for _, tx := range tt {
+			m[tx.TxID] = tx
+		}
+		require.Len(t, m, len(tt))
+		return m
+	}
+
+	t.Run("txs have the wallet totals set", func(t *testing.T) {
+		tt := txs(payd.TransactionSearchArgs{})
+		require.Len(t, tt, 4)
+
+		got := tt[paid.TxID()]
+		assert.Equal(t, paid.String(), got.TxHex)
+		assert.Equal(t, uint64(1000), got.Received)
+		assert.Zero(t, got.Spent)
+		assert.Equal(t, payd.StateTxBroadcast, got.State)
+		assert.True(t, got.Confirmed)
+		assert.Equal(t, null.StringFrom(inv.ID), got.InvoiceID)
+		assert.False(t, got.PaidTo.Valid)
+		assert.False(t, got.CreatedAt.IsZero())
+
+		got = tt[sent.TxID()]
+		assert.Equal(t, uint64(300), got.Received)
+		assert.Equal(t, uint64(1000), got.Spent)
+		assert.False(t, got.Confirmed)
+		assert.False(t, got.InvoiceID.Valid)
+		assert.Equal(t, null.StringFrom("https://example.com/pay/1"), got.PaidTo)
+
+		got = tt[refund.TxID()]
+		assert.Equal(t, uint64(100), got.Received)
+		assert.Equal(t, payd.StateTxPending, got.State)
+		assert.Equal(t, null.StringFrom(inv.ID), got.InvoiceID)
+
+		got = tt[failed.TxID()]
+		assert.Equal(t, uint64(50), got.Received)
+		assert.Equal(t, payd.StateTxFailed, got.State)
+		assert.Equal(t, null.StringFrom("rejected"), got.FailReason)
+	})
+
+	t.Run("txs are filtered", func(t *testing.T) {
+		tt := txs(payd.TransactionSearchArgs{State: payd.StateTxBroadcast})
+		assert.Len(t, tt, 2)
+		assert.Contains(t, tt, paid.TxID())
+		assert.Contains(t, tt, sent.TxID())
+
+		tt = txs(payd.TransactionSearchArgs{State: payd.StateTxFailed})
+		assert.Len(t, tt, 1)
+		assert.Contains(t, tt, failed.TxID())
+
+		now := time.Now().UTC()
+		assert.Len(t, txs(payd.TransactionSearchArgs{CreatedFrom: null.TimeFrom(now.Add(-time.Hour))}), 4)
+		assert.Len(t, txs(payd.TransactionSearchArgs{CreatedTo: null.TimeFrom(now.Add(time.Hour))}), 4)
+		assert.Empty(t, txs(payd.TransactionSearchArgs{CreatedFrom: null.TimeFrom(now.Add(time.Hour))}))
+		assert.Empty(t, txs(payd.TransactionSearchArgs{CreatedTo: null.TimeFrom(now.Add(-time.Hour))}))
+	})
+
+	t.Run("pages are returned in order", func(t *testing.T) {
+		for _, order := range []payd.SortOrder{payd.SortAsc, payd.SortDesc} {
+			all, err := s.Transactions(ctx, payd.TransactionSearchArgs{Sort: order})
+			require.NoError(t, err)
+			require.Len(t, all, 4)
+			var paged []payd.TransactionHistory
+			args := payd.TransactionSearchArgs{Sort: order, Limit: 3}
+			for i := 0; i < 2; i++ {
+				tt, err := s.Transactions(ctx, args)
+				require.NoError(t, err)
+				paged = append(paged, tt...)
+				if len(tt) < args.Limit {
+					break
+				}
+				last := tt[len(tt)-1]
+				args.Cursor = payd.TransactionCursor{CreatedAt: last.CreatedAt, TxID: last.TxID}
+			}
+			require.Len(t, paged, 4)
+			for i := range all {
+				assert.Equal(t, all[i].TxID, paged[i].TxID)
+			}
+			for i := 1; i < len(all); i++ {
+				if order == payd.SortAsc {
+					assert.False(t, all[i].CreatedAt.Before(all[i-1].CreatedAt))
+				} else {
+					assert.False(t, all[i].CreatedAt.After(all[i-1].CreatedAt))
+				}
+			}
+		}
+	})
+}
+
+// selectorFunc allows a func to be used as a coin selector.
+type selectorFunc func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO
+
+func (f selectorFunc) SelectCoins(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	return f(args, utxos)
+}
+
+// firstFit selects utxos in the order given by the store until the amount is covered.
+var firstFit = selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	total := uint64(0)
+	for i, u := range utxos {
+		if total += u.Satoshis - args.InputFee; total > args.Satoshis {
+			return utxos[:i+1]
+		}
+	}
+	return nil
+})
+
+func testUTXOs(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	oo := destinationsCreate(t, s, "", 1000, 2000)
+	tx := transactionCreate(t, s, "", oo...)
+
+	// utxos are only spendable once their tx has been broadcast.
+	utxos, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay1", Satoshis: 500, Selector: firstFit})
+	require.NoError(t, err)
+	assert.Empty(t, utxos)
+	require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+		State: payd.StateTxBroadcast,
+	}))
+
+	// when confirmations are required only utxos of confirmed txs are reserved.
+	for _, state := range []payd.TxState{payd.StateTxBroadcast, payd.StateTxMined, payd.StateTxConfirmed} {
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State: state,
+		}))
+		confirmed, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay1", Satoshis: 500, Selector: firstFit, Confirmed: true})
+		require.NoError(t, err)
+		assert.Equal(t, state == payd.StateTxConfirmed, len(confirmed) == 1, "state %s", state)
+		require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay1"}))
+	}
+
+	bal, err := s.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3000), bal.Satoshis)
+
+	// only utxos paid to the spending key are reserved when it is given.
+	for _, k := range []payd.KeyArgs{{Name: "other", UserID: 1}, {Name: "masterkey", UserID: 2}} {
+		other, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "other", Satoshis: 500, Selector: firstFit,
+			KeyName: k.Name, UserID: k.UserID})
+		require.NoError(t, err)
+		assert.Empty(t, other)
+	}
+
+	pay1, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay1", Satoshis: 500, Selector: firstFit,
+		KeyName: "masterkey", UserID: 1})
+	require.NoError(t, err)
+	require.Len(t, pay1, 1)
+	assert.Equal(t, tx.TxID(), pay1[0].TxID)
+	pay2, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay2", Satoshis: 500, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay2, 1)
+	assert.NotEqual(t, pay1[0].Outpoint, pay2[0].Outpoint)
+
+	// everything is reserved.
+	pay3, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay3", Satoshis: 500, Selector: firstFit})
+	require.NoError(t, err)
+	assert.Empty(t, pay3)
+
+	require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay2"}))
+	pay3, err = s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay3", Satoshis: 500, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay3, 1)
+	assert.Equal(t, pay2[0].Outpoint, pay3[0].Outpoint)
+
+	spending := transactionCreate(t, s, "")
+	require.NoError(t, s.UTXOSpend(ctx, payd.UTXOSpend{
+		SpendingTxID: spending.TxID(),
+		Reservation:  "pay1",
+	}))
+	bal, err = s.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, 3000-pay1[0].Satoshis, bal.Satoshis)
+
+	// utxos spent by a tx that hasn't been broadcast are still reserved.
+	rr, err := s.UTXOReservations(ctx)
+	require.NoError(t, err)
+	require.Len(t, rr, 2)
+	assert.Equal(t, payd.UTXOReservation{ReservedFor: "pay1", UTXOs: 1, Satoshis: pay1[0].Satoshis}, rr[0])
+	assert.Equal(t, "pay3", rr[1].ReservedFor)
+
+	// spent utxos are not released once the spending tx is broadcast.
+	require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: spending.TxID()}, payd.TransactionStateUpdate{
+		State: payd.StateTxBroadcast,
+	}))
+	require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay1"}))
+	require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay3"}))
+
+	// a reservation that can't be met reserves nothing.
+	pay4, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay4", Satoshis: 10000, Selector: firstFit})
+	require.NoError(t, err)
+	assert.Empty(t, pay4)
+	pay5, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay5", Satoshis: 1, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay5, 1)
+	assert.Equal(t, pay3[0].Outpoint, pay5[0].Outpoint)
+
+	// the selector is given the spendable utxos and those it chooses are reserved.
+	oo = destinationsCreate(t, s, "", 300, 400)
+	tx = transactionCreate(t, s, "", oo...)
+	require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+		State: payd.StateTxBroadcast,
+	}))
+	var spendable []payd.UTXO
+	pay6, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay6", Satoshis: 350, InputFee: 10, ChangeFee: 5,
+		Selector: selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+			assert.Equal(t, payd.CoinSelectArgs{Satoshis: 350, InputFee: 10, ChangeFee: 5}, args)
+			spendable = utxos
+			for _, u := range utxos {
+				if u.Satoshis == 400 {
+					return []payd.UTXO{u}
+				}
+			}
+			return nil
+		})})
+	require.NoError(t, err)
+	require.Len(t, spendable, 2)
+	for _, u := range spendable {
+		assert.Equal(t, tx.TxID(), u.TxID)
+		assert.NotEmpty(t, u.LockingScript)
+		assert.NotEmpty(t, u.DerivationPath)
+		assert.False(t, u.CreatedAt.IsZero())
+	}
+	require.Len(t, pay6, 1)
+	assert.Equal(t, uint64(400), pay6[0].Satoshis)
+	pay7, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay7", Satoshis: 1, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay7, 1)
+	assert.Equal(t, uint64(300), pay7[0].Satoshis)
+
+	// reservations are listed with the expiry they were reserved until.
+	require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay7"}))
+	until := time.Now().UTC().Add(5 * time.Minute).Truncate(time.Second)
+	pay8, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay8", Satoshis: 1, ReservedUntil: until, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay8, 1)
+	rr, err = s.UTXOReservations(ctx)
+	require.NoError(t, err)
+	require.Len(t, rr, 3)
+	assert.Equal(t, payd.UTXOReservation{ReservedFor: "pay5", UTXOs: 1, Satoshis: pay5[0].Satoshis}, rr[0])
+	assert.Equal(t, payd.UTXOReservation{ReservedFor: "pay6", UTXOs: 1, Satoshis: 400}, rr[1])
+	assert.Equal(t, "pay8", rr[2].ReservedFor)
+	assert.Equal(t, uint64(300), rr[2].Satoshis)
+	assert.True(t, rr[2].ReservedUntil.Valid)
+	assert.True(t, until.Equal(rr[2].ReservedUntil.Time), "expected %s, got %s", until, rr[2].ReservedUntil.Time)
+
+	// released reservations are no longer listed.
+	require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay8"}))
+	require.NoError(t, s.UTXOSpend(ctx, payd.UTXOSpend{SpendingTxID: spending.TxID(), Reservation: "pay6"}))
+	rr, err = s.UTXOReservations(ctx)
+	require.NoError(t, err)
+	require.Len(t, rr, 1)
+	assert.Equal(t, "pay5", rr[0].ReservedFor)
+
+	// releasing utxos spent by a tx that was never broadcast unspends them.
+	pay9, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay9", Satoshis: 1, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay9, 1)
+	abandoned := transactionCreate(t, s, "")
+	require.NoError(t, s.UTXOSpend(ctx, payd.UTXOSpend{SpendingTxID: abandoned.TxID(), Reservation: "pay9"}))
+	require.NoError(t, s.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: "pay9"}))
+	rr, err = s.UTXOReservations(ctx)
+	require.NoError(t, err)
+	require.Len(t, rr, 1)
+	assert.Equal(t, "pay5", rr[0].ReservedFor)
+	pay10, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay10", Satoshis: 1, Selector: firstFit})
+	require.NoError(t, err)
+	require.Len(t, pay10, 1)
+	assert.Equal(t, pay9[0].Outpoint, pay10[0].Outpoint)
+}
+
+func testUTXOSearch(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	broadcast := func(tx *bt.Tx) {
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State: payd.StateTxBroadcast,
+		}))
+	}
+	destinations := func(paths map[string]uint64) []payd.Output {
+		req := make([]payd.DestinationCreate, 0, len(paths))
+		for path, sats := range paths {
+			req = append(req, payd.DestinationCreate{
+				Script:         newScript(t).String(),
+				DerivationPath: path,
+				Satoshis:       sats,
+				UserID:         1,
+				KeyName:        "masterkey",
+			})
+		}
+		oo, err := s.DestinationsCreate(ctx, payd.DestinationsCreateArgs{}, req)
+		require.NoError(t, err)
+		return oo
+	}
+	satoshis := func(uu []payd.UTXODetail) []uint64 {
+		ss := make([]uint64, 0, len(uu))
+		for _, u := range uu {
+			ss = append(ss, u.Satoshis)
+		}
+		sort.Slice(ss, func(i, j int) bool { return ss[i] < ss[j] })
+		return ss
+	}
+	outpoint := func(sats uint64) string {
+		uu, err := s.UTXOs(ctx, payd.UTXOSearchArgs{SatoshisMin: sats, SatoshisMax: sats})
+		require.NoError(t, err)
+		require.Len(t, uu, 1)
+		return uu[0].Outpoint
+	}
+
+	broadcast(transactionCreate(t, s, "", destinations(map[string]uint64{"1/0": 100, "1/1": 200, "2/0": 300})...))
+	transactionCreate(t, s, "", destinations(map[string]uint64{"3/0": 400})...)
+	confirmed := transactionCreate(t, s, "", destinations(map[string]uint64{"10/0": 500})...)
+	broadcast(confirmed)
+	require.NoError(t, s.ProofCreate(ctx, dpp.ProofWrapper{
+		CallbackPayload: &bc.MerkleProof{TxOrID: confirmed.TxID(), Target: randomHex(t, 32)},
+		BlockHash:       randomHex(t, 32),
+		CallbackTxID:    confirmed.TxID(),
+		CallbackReason:  "merkleProof",
+	}))
+	spending := transactionCreate(t, s, "")
+	_, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay1", Satoshis: 1,
+		Selector: selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+			for _, u := range utxos {
+				if u.Satoshis == 300 {
+					return []payd.UTXO{u}
+				}
+			}
+			return nil
+		})})
+	require.NoError(t, err)
+	require.NoError(t, s.UTXOSpend(ctx, payd.UTXOSpend{SpendingTxID: spending.TxID(), Reservation: "pay1"}))
+	_, err = s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay2", Satoshis: 1,
+		Selector: selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+			for _, u := range utxos {
+				if u.Satoshis == 200 {
+					return []payd.UTXO{u}
+				}
+			}
+			return nil
+		})})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		args payd.UTXOSearchArgs
+		exp  []uint64
+	}{
+		"all txos": {
+			exp: []uint64{100, 200, 300, 400, 500},
+		},
+		"unspent txos": {
+			args: payd.UTXOSearchArgs{Spent: null.BoolFrom(false)},
+			exp:  []uint64{100, 200, 400, 500},
+		},
+		"spent txos": {
+			args: payd.UTXOSearchArgs{Spent: null.BoolFrom(true)},
+			exp:  []uint64{300},
+		},
+		"reserved txos": {
+			args: payd.UTXOSearchArgs{Reserved: null.BoolFrom(true)},
+			exp:  []uint64{200},
+		},
+		"unreserved unspent txos": {
+			args: payd.UTXOSearchArgs{Reserved: null.BoolFrom(false), Spent: null.BoolFrom(false)},
+			exp:  []uint64{100, 400, 500},
+		},
+		"confirmed txos": {
+			args: payd.UTXOSearchArgs{Confirmed: null.BoolFrom(true)},
+			exp:  []uint64{500},
+		},
+		"unconfirmed txos": {
+			args: payd.UTXOSearchArgs{Confirmed: null.BoolFrom(false)},
+			exp:  []uint64{100, 200, 300, 400},
+		},
+		"satoshi range": {
+			args: payd.UTXOSearchArgs{SatoshisMin: 200, SatoshisMax: 400},
+			exp:  []uint64{200, 300, 400},
+		},
+		"derivation path and those beneath it": {
+			args: payd.UTXOSearchArgs{DerivationPath: "1"},
+			exp:  []uint64{100, 200},
+		},
+		"exact derivation path": {
+			args: payd.UTXOSearchArgs{DerivationPath: "1/1"},
+			exp:  []uint64{200},
+		},
+		"derivation path prefix isn't matched": {
+			args: payd.UTXOSearchArgs{DerivationPath: "10/0"},
+			exp:  []uint64{500},
+		},
+		"no matches": {
+			args: payd.UTXOSearchArgs{Frozen: null.BoolFrom(true)},
+			exp:  []uint64{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			uu, err := s.UTXOs(ctx, test.args)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, satoshis(uu))
+		})
+	}
+
+	t.Run("txo details are returned", func(t *testing.T) {
+		uu, err := s.UTXOs(ctx, payd.UTXOSearchArgs{SatoshisMin: 300, SatoshisMax: 300})
+		require.NoError(t, err)
+		require.Len(t, uu, 1)
+		u := uu[0]
+		assert.NotEmpty(t, u.Outpoint)
+		assert.NotEmpty(t, u.TxID)
+		assert.NotEmpty(t, u.LockingScript)
+		assert.Equal(t, "2/0", u.DerivationPath)
+		assert.Equal(t, "masterkey", u.KeyName)
+		assert.Equal(t, payd.StateTxBroadcast, u.TxState)
+		assert.Equal(t, "pay1", u.ReservedFor.String)
+		assert.True(t, u.SpentAt.Valid)
+		assert.Equal(t, spending.TxID(), u.SpendingTxID.String)
+		assert.False(t, u.CreatedAt.IsZero())
+	})
+
+	t.Run("pages are returned in order", func(t *testing.T) {
+		for _, order := range []payd.SortOrder{payd.SortAsc, payd.SortDesc} {
+			all, err := s.UTXOs(ctx, payd.UTXOSearchArgs{Sort: order})
+			require.NoError(t, err)
+			require.Len(t, all, 5)
+			var paged []payd.UTXODetail
+			args := payd.UTXOSearchArgs{Sort: order, Limit: 2}
+			for i := 0; i < 3; i++ {
+				uu, err := s.UTXOs(ctx, args)
+				require.NoError(t, err)
+				paged = append(paged, uu...)
+				if len(uu) < args.Limit {
+					break
+				}
+				last := uu[len(uu)-1]
+				args.Cursor = payd.UTXOCursor{CreatedAt: last.CreatedAt, Outpoint: last.Outpoint}
+			}
+			require.Len(t, paged, 5)
+			for i := range all {
+				assert.Equal(t, all[i].Outpoint, paged[i].Outpoint)
+			}
+			for i := 1; i < len(all); i++ {
+				if order == payd.SortAsc {
+					assert.False(t, all[i].CreatedAt.Before(all[i-1].CreatedAt))
+				} else {
+					assert.False(t, all[i].CreatedAt.After(all[i-1].CreatedAt))
+				}
+			}
+		}
+	})
+
+	t.Run("frozen txos aren't reserved", func(t *testing.T) {
+		frozen := outpoint(100)
+		require.NoError(t, s.UTXOFrozenUpdate(ctx, payd.UTXOFrozenUpdate{Outpoints: []string{frozen}, Frozen: true}))
+		uu, err := s.UTXOs(ctx, payd.UTXOSearchArgs{Frozen: null.BoolFrom(true)})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{100}, satoshis(uu))
+
+		var spendable []payd.UTXO
+		_, err = s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay3", Satoshis: 1,
+			Selector: selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+				spendable = utxos
+				return nil
+			})})
+		require.NoError(t, err)
+		require.Len(t, spendable, 1)
+		assert.Equal(t, uint64(500), spendable[0].Satoshis)
+
+		bal, err := s.Balance(ctx, payd.BalanceArgs{})
+		require.NoError(t, err)
+		assert.Equal(t, payd.BalanceTotal{Satoshis: 100, UTXOs: 1}, bal.Frozen)
+		assert.Equal(t, uint64(500), bal.Spendable)
+
+		require.NoError(t, s.UTXOFrozenUpdate(ctx, payd.UTXOFrozenUpdate{Outpoints: []string{frozen}}))
+		_, err = s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: "pay3", Satoshis: 1,
+			Selector: selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+				spendable = utxos
+				return nil
+			})})
+		require.NoError(t, err)
+		assert.Len(t, spendable, 2)
+	})
+
+	t.Run("only unspent txos can be frozen", func(t *testing.T) {
+		spent := outpoint(300)
+		unspent := outpoint(500)
+		for _, oo := range [][]string{{spent}, {"missing"}, {unspent, "missing"}} {
+			err := s.UTXOFrozenUpdate(ctx, payd.UTXOFrozenUpdate{Outpoints: oo, Frozen: true})
+			assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+		}
+		// nothing is frozen when an outpoint isn't found.
+		uu, err := s.UTXOs(ctx, payd.UTXOSearchArgs{Frozen: null.BoolFrom(true)})
+		require.NoError(t, err)
+		assert.Empty(t, uu)
+	})
+}
+
+func testBalance(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	broadcast := func(tx *bt.Tx, state payd.TxState) {
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State: state,
+		}))
+	}
+	mine := func(tx *bt.Tx, state payd.TxState, height int64) {
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State:       state,
+			BlockHeight: null.IntFrom(height),
+		}))
+	}
+	reserve := func(reservedFor string, sats uint64) {
+		uu, err := s.UTXOReserve(ctx, payd.UTXOReserve{ReservedFor: reservedFor, Satoshis: 1,
+			Selector: selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+				for _, u := range utxos {
+					if u.Satoshis == sats {
+						return []payd.UTXO{u}
+					}
+				}
+				return nil
+			})})
+		require.NoError(t, err)
+		require.Len(t, uu, 1)
+	}
+
+	transactionCreate(t, s, "", destinationsCreate(t, s, "", 100)...)
+	broadcast(transactionCreate(t, s, "", destinationsCreate(t, s, "", 200)...), payd.StateTxFailed)
+	// the block height of a tx reverted to broadcast by a reorg isn't counted.
+	mine(transactionCreate(t, s, "", destinationsCreate(t, s, "", 300, 400)...), payd.StateTxBroadcast, 90)
+	reserve("pay1", 400)
+	mine(transactionCreate(t, s, "", destinationsCreate(t, s, "", 500)...), payd.StateTxConfirmed, 100)
+	mine(transactionCreate(t, s, "", destinationsCreate(t, s, "", 600)...), payd.StateTxMined, 105)
+	mine(transactionCreate(t, s, "", destinationsCreate(t, s, "", 700)...), payd.StateTxMined, 103)
+	spent := transactionCreate(t, s, "", destinationsCreate(t, s, "", 1000)...)
+	broadcast(spent, payd.StateTxBroadcast)
+	reserve("pay2", 1000)
+	require.NoError(t, s.UTXOSpend(ctx, payd.UTXOSpend{SpendingTxID: transactionCreate(t, s, "").TxID(), Reservation: "pay2"}))
+
+	// txos paid to another user and key.
+	oo, err := s.DestinationsCreate(ctx, payd.DestinationsCreateArgs{}, []payd.DestinationCreate{{
+		Script:         newScript(t).String(),
+		DerivationPath: randomHex(t, 4) + "/0",
+		Satoshis:       50,
+		UserID:         0,
+		KeyName:        "other",
+	}})
+	require.NoError(t, err)
+	broadcast(transactionCreate(t, s, "", oo...), payd.StateTxBroadcast)
+
+	tests := map[string]struct {
+		args payd.BalanceArgs
+		exp  payd.Balance
+	}{
+		"all txos": {
+			exp: payd.Balance{
+				Satoshis:    2550,
+				UTXOs:       6,
+				Spendable:   2150,
+				Confirmed:   payd.BalanceTotal{Satoshis: 500, UTXOs: 1, LowBlockHeight: null.IntFrom(100), HighBlockHeight: null.IntFrom(100)},
+				Mined:       payd.BalanceTotal{Satoshis: 1300, UTXOs: 2, LowBlockHeight: null.IntFrom(103), HighBlockHeight: null.IntFrom(105)},
+				Unconfirmed: payd.BalanceTotal{Satoshis: 350, UTXOs: 2},
+				Reserved:    payd.BalanceTotal{Satoshis: 400, UTXOs: 1},
+				Pending:     payd.BalanceTotal{Satoshis: 100, UTXOs: 1},
+				Failed:      payd.BalanceTotal{Satoshis: 200, UTXOs: 1},
+			},
+		},
+		"scoped to a user": {
+			args: payd.BalanceArgs{UserID: null.IntFrom(1)},
+			exp: payd.Balance{
+				Satoshis:    2500,
+				UTXOs:       5,
+				Spendable:   2100,
+				Confirmed:   payd.BalanceTotal{Satoshis: 500, UTXOs: 1, LowBlockHeight: null.IntFrom(100), HighBlockHeight: null.IntFrom(100)},
+				Mined:       payd.BalanceTotal{Satoshis: 1300, UTXOs: 2, LowBlockHeight: null.IntFrom(103), HighBlockHeight: null.IntFrom(105)},
+				Unconfirmed: payd.BalanceTotal{Satoshis: 300, UTXOs: 1},
+				Reserved:    payd.BalanceTotal{Satoshis: 400, UTXOs: 1},
+				Pending:     payd.BalanceTotal{Satoshis: 100, UTXOs: 1},
+				Failed:      payd.BalanceTotal{Satoshis: 200, UTXOs: 1},
+			},
+		},
+		"scoped to a key": {
+			args: payd.BalanceArgs{KeyName: "other"},
+			exp: payd.Balance{
+				Satoshis:    50,
+				UTXOs:       1,
+				Spendable:   50,
+				Unconfirmed: payd.BalanceTotal{Satoshis: 50, UTXOs: 1},
+			},
+		},
+		"scoped to a user and key": {
+			args: payd.BalanceArgs{UserID: null.IntFrom(1), KeyName: "other"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bal, err := s.Balance(ctx, test.args)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, *bal)
+		})
+	}
+}
+
+func testFeeQuotes(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	inv := invoiceCreate(t, s, 1000)
+
+	_, err := s.FeeQuote(ctx, inv.ID)
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+
+	fq := bt.NewFeeQuote()
+	expiry := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	fq.UpdateExpiry(expiry)
+	require.NoError(t, s.FeeQuoteCreate(ctx, &payd.FeeQuoteCreateArgs{InvoiceID: inv.ID, FeeQuote: fq}))
+
+	got, err := s.FeeQuote(ctx, inv.ID)
+	require.NoError(t, err)
+	assert.True(t, expiry.Equal(got.Expiry()), "expected expiry %s got %s", expiry, got.Expiry())
+	std, err := got.Fee(bt.FeeTypeStandard)
+	require.NoError(t, err)
+	expStd, err := fq.Fee(bt.FeeTypeStandard)
+	require.NoError(t, err)
+	assert.Equal(t, expStd, std)
+
+	// a second quote for the same invoice replaces the first.
+	expiry = expiry.Add(time.Hour)
+	fq.UpdateExpiry(expiry)
+	require.NoError(t, s.FeeQuoteCreate(ctx, &payd.FeeQuoteCreateArgs{InvoiceID: inv.ID, FeeQuote: fq}))
+	got, err = s.FeeQuote(ctx, inv.ID)
+	require.NoError(t, err)
+	assert.True(t, expiry.Equal(got.Expiry()), "expected expiry %s got %s", expiry, got.Expiry())
+}
+
+func testProofs(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	tx := transactionCreate(t, s, "", destinationsCreate(t, s, "", 1000)...)
+
+	proof, err := s.MerkleProof(ctx, tx.TxID())
+	require.NoError(t, err)
+	assert.Nil(t, proof)
+
+	exp := &bc.MerkleProof{
+		Index:  1,
+		TxOrID: tx.TxID(),
+		Target: randomHex(t, 32),
+		Nodes:  []string{randomHex(t, 32), randomHex(t, 32)},
+	}
+	require.NoError(t, s.ProofCreate(ctx, dpp.ProofWrapper{
+		CallbackPayload: exp,
+		BlockHash:       exp.Target,
+		CallbackTxID:    tx.TxID(),
+		CallbackReason:  "merkleProof",
+	}))
+
+	proof, err = s.MerkleProof(ctx, tx.TxID())
+	require.NoError(t, err)
+	assert.Equal(t, exp, proof)
+}
+
+func testProofsStale(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	mine := func(tx *bt.Tx, blockHash string, height int64) *bc.MerkleProof {
+		mp := &bc.MerkleProof{TxOrID: tx.TxID(), Target: blockHash, Nodes: []string{randomHex(t, 32)}}
+		require.NoError(t, s.ProofCreate(ctx, dpp.ProofWrapper{
+			CallbackPayload: mp,
+			BlockHash:       blockHash,
+			BlockHeight:     uint32(height),
+			CallbackTxID:    tx.TxID(),
+			CallbackReason:  "merkleProof",
+		}))
+		require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID()}, payd.TransactionStateUpdate{
+			State:       payd.StateTxConfirmed,
+			BlockHeight: null.IntFrom(height),
+		}))
+		return mp
+	}
+	proofBlocks := func(min uint32) []payd.ProofBlock {
+		bb, err := s.ProofBlocks(ctx, payd.ProofBlocksArgs{MinBlockHeight: min})
+		require.NoError(t, err)
+		return bb
+	}
+	confirmed := func(tx *bt.Tx) bool {
+		hh, err := s.Transactions(ctx, payd.TransactionSearchArgs{})
+		require.NoError(t, err)
+		uu, err := s.UTXOs(ctx, payd.UTXOSearchArgs{})
+		require.NoError(t, err)
+		for _, u := range uu {
+			if u.TxID == tx.TxID() {
+				for _, h := range hh {
+					if h.TxID == tx.TxID() {
+						assert.Equal(t, u.Confirmed, h.Confirmed)
+					}
+				}
+				return u.Confirmed
+			}
+		}
+		t.Fatalf("tx %s has no txos", tx.TxID())
+		return false
+	}
+	parent := transactionCreate(t, s, "", destinationsCreate(t, s, "", 1000)...)
+	child := transactionCreate(t, s, "", destinationsCreate(t, s, "", 500)...)
+	old := transactionCreate(t, s, "", destinationsCreate(t, s, "", 300)...)
+	blockA, blockB, blockC, blockD := randomHex(t, 32), randomHex(t, 32), randomHex(t, 32), randomHex(t, 32)
+	parentProof := mine(parent, blockA, 100)
+	mine(child, blockB, 101)
+	mine(old, blockC, 90)
+
+	assert.Equal(t, []payd.ProofBlock{{BlockHash: blockB, BlockHeight: 101}, {BlockHash: blockA, BlockHeight: 100}}, proofBlocks(95))
+	assert.Len(t, proofBlocks(0), 3)
+
+	// the proofs of an orphaned block are marked stale, they no longer prove their tx is mined.
+	txIDs, err := s.ProofsStale(ctx, payd.ProofsStaleArgs{BlockHash: blockA, StaleAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, []string{parent.TxID()}, txIDs)
+	proof, err := s.MerkleProof(ctx, parent.TxID())
+	require.NoError(t, err)
+	assert.Nil(t, proof)
+	assert.False(t, confirmed(parent))
+	assert.True(t, confirmed(child))
+	assert.Equal(t, []payd.ProofBlock{{BlockHash: blockB, BlockHeight: 101}}, proofBlocks(95))
+	txIDs, err = s.ProofsStale(ctx, payd.ProofsStaleArgs{BlockHash: blockA, StaleAt: now})
+	require.NoError(t, err)
+	assert.Empty(t, txIDs)
+
+	// a tx with a proof on the best chain can't go back to broadcast, it can once its proof is stale.
+	assert.Error(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: child.TxID()}, payd.TransactionStateUpdate{
+		State: payd.StateTxBroadcast,
+	}))
+	assert.True(t, confirmed(child))
+	assert.Equal(t, []payd.ProofBlock{{BlockHash: blockB, BlockHeight: 101}}, proofBlocks(95))
+	txIDs, err = s.ProofsStale(ctx, payd.ProofsStaleArgs{BlockHash: blockB, StaleAt: now})
+	require.NoError(t, err)
+	assert.Equal(t, []string{child.TxID()}, txIDs)
+	require.NoError(t, s.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: child.TxID()}, payd.TransactionStateUpdate{
+		State: payd.StateTxBroadcast,
+	}))
+	assert.Empty(t, proofBlocks(95))
+
+	// a proof in a block of the new best chain is stored, then the orphaned block rejoins the best
+	// chain, its proof replaces the stale proof and the proof in the other block becomes stale.
+	newProof := mine(parent, blockD, 101)
+	proof, err = s.MerkleProof(ctx, parent.TxID())
+	require.NoError(t, err)
+	assert.Equal(t, newProof, proof)
+	assert.True(t, confirmed(parent))
+	assert.Equal(t, []payd.ProofBlock{{BlockHash: blockD, BlockHeight: 101}}, proofBlocks(95))
+	assert.Error(t, s.ProofCreate(ctx, dpp.ProofWrapper{
+		CallbackPayload: newProof,
+		BlockHash:       blockD,
+		CallbackTxID:    parent.TxID(),
+		CallbackReason:  "merkleProof",
+	}))
+
+	mine(parent, blockA, 100)
+	proof, err = s.MerkleProof(ctx, parent.TxID())
+	require.NoError(t, err)
+	assert.Equal(t, parentProof.TxOrID, proof.TxOrID)
+	assert.Equal(t, blockA, proof.Target)
+	assert.Equal(t, []payd.ProofBlock{{BlockHash: blockA, BlockHeight: 100}}, proofBlocks(95))
+}
+
+func testProofCallbacks(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	inv := invoiceCreate(t, s, 1000)
+	tx := transactionCreate(t, s, inv.ID, destinationsCreate(t, s, inv.ID, 1000)...)
+	other := transactionCreate(t, s, inv.ID, destinationsCreate(t, s, inv.ID, 500)...)
+	args := payd.ProofCallbackArgs{InvoiceID: inv.ID, TxID: tx.TxID()}
+
+	require.NoError(t, s.ProofCallBacksCreate(ctx, args, nil))
+	require.NoError(t, s.ProofCallBacksCreate(ctx, args, map[string]dpp.ProofCallback{
+		"https://example.com/proofs/1": {Token: "abc"},
+		"https://example.com/proofs/2": {},
+	}))
+	assert.Error(t, s.ProofCallBacksCreate(ctx, args, map[string]dpp.ProofCallback{
+		"https://example.com/proofs/1": {Token: "abc"},
+	}))
+	// the same url can be used for each payment of an invoice.
+	require.NoError(t, s.ProofCallBacksCreate(ctx, payd.ProofCallbackArgs{InvoiceID: inv.ID, TxID: other.TxID()}, map[string]dpp.ProofCallback{
+		"https://example.com/proofs/1": {Token: "def"},
+	}))
+
+	cc, err := s.ProofCallbacks(ctx, payd.ProofCallbackArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Len(t, cc, 3)
+	cc, err = s.ProofCallbacks(ctx, args)
+	require.NoError(t, err)
+	require.Len(t, cc, 2)
+	assert.Equal(t, "https://example.com/proofs/1", cc[0].URL)
+	assert.Equal(t, "abc", cc[0].Token)
+	assert.Equal(t, tx.TxID(), cc[0].TxID)
+	assert.Equal(t, payd.StateProofCallbackPending, cc[0].State)
+	assert.Empty(t, cc[0].Proof)
+	assert.False(t, cc[0].NextAttemptAt.Valid)
+
+	// callbacks aren't due until a proof is queued for them.
+	due, err := s.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{DueBefore: now.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	require.NoError(t, s.ProofCallbacksQueue(ctx, args, payd.ProofCallbacksQueue{
+		Proof:         []byte(`{"payload":"proof"}`),
+		NextAttemptAt: now,
+		UpdatedAt:     now,
+	}))
+	due, err = s.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{DueBefore: now.Add(-time.Second)})
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = s.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{DueBefore: now, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "https://example.com/proofs/1", due[0].URL)
+	assert.JSONEq(t, `{"payload":"proof"}`, string(due[0].Proof))
+	assert.True(t, now.Equal(due[0].NextAttemptAt.Time))
+	due, err = s.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{DueBefore: now, Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	require.NoError(t, s.ProofCallbackUpdate(ctx, payd.ProofCallbackSendArgs{TxID: tx.TxID(), URL: "https://example.com/proofs/1"}, payd.ProofCallbackUpdate{
+		State:     payd.StateProofCallbackSent,
+		Attempts:  1,
+		UpdatedAt: now,
+	}))
+	require.NoError(t, s.ProofCallbackUpdate(ctx, payd.ProofCallbackSendArgs{TxID: tx.TxID(), URL: "https://example.com/proofs/2"}, payd.ProofCallbackUpdate{
+		State:         payd.StateProofCallbackPending,
+		Attempts:      1,
+		NextAttemptAt: null.TimeFrom(now.Add(time.Minute)),
+		LastError:     null.StringFrom("connection refused"),
+		UpdatedAt:     now,
+	}))
+	err = s.ProofCallbackUpdate(ctx, payd.ProofCallbackSendArgs{TxID: tx.TxID(), URL: "https://example.com/missing"}, payd.ProofCallbackUpdate{
+		State:     payd.StateProofCallbackSent,
+		UpdatedAt: now,
+	})
+	assert.True(t, lathos.IsNotFound(err))
+
+	due, err = s.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{DueBefore: now.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "https://example.com/proofs/2", due[0].URL)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, null.StringFrom("connection refused"), due[0].LastError)
+
+	cc, err = s.ProofCallbacks(ctx, args)
+	require.NoError(t, err)
+	require.Len(t, cc, 2)
+	assert.Equal(t, payd.StateProofCallbackSent, cc[0].State)
+	assert.False(t, cc[0].NextAttemptAt.Valid)
+
+	// a proof received again is sent to every callback again.
+	require.NoError(t, s.ProofCallbacksQueue(ctx, args, payd.ProofCallbacksQueue{
+		Proof:         []byte(`{"payload":"proof2"}`),
+		NextAttemptAt: now,
+		UpdatedAt:     now,
+	}))
+	due, err = s.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{DueBefore: now})
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, 0, due[0].Attempts)
+	assert.False(t, due[1].LastError.Valid)
+}
+
+func testPeerChannels(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	acc, err := s.PeerChannelAccount(ctx, &payd.PeerChannelIDArgs{UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), acc.ID)
+	assert.Equal(t, "username", acc.Username)
+	assert.Equal(t, "password", acc.Password)
+
+	channelID := randomHex(t, 16)
+	require.NoError(t, s.PeerChannelCreate(ctx, &payd.PeerChannelCreateArgs{
+		PeerChannelAccountID: acc.ID,
+		ChannelType:          payd.PeerChannelHandlerTypeProof,
+		ChannelHost:          "peerchannels:25009",
+		ChannelPath:          "/api/v1",
+		ChannelID:            channelID,
+	}))
+	require.NoError(t, s.PeerChannelAPITokensCreate(ctx, &payd.PeerChannelAPITokenStoreArgs{
+		PeerChannelsChannelID: channelID,
+		Token:                 "read-tok",
+		Role:                  "notification",
+		CanRead:               true,
+	}))
+
+	opened, err := s.PeerChannelsOpened(ctx, payd.PeerChannelHandlerTypeProof)
+	require.NoError(t, err)
+	require.Len(t, opened, 1)
+	assert.Equal(t, channelID, opened[0].ID)
+	assert.Equal(t, "read-tok", opened[0].Token)
+	assert.Equal(t, "peerchannels:25009", opened[0].Host)
+	assert.Equal(t, "/api/v1", opened[0].Path)
+	assert.Equal(t, payd.PeerChannelHandlerTypeProof, opened[0].Type)
+	assert.False(t, opened[0].CreatedAt.IsZero())
+
+	require.NoError(t, s.PeerChannelCloseChannel(ctx, channelID))
+	opened, err = s.PeerChannelsOpened(ctx, payd.PeerChannelHandlerTypeProof)
+	require.NoError(t, err)
+	assert.Empty(t, opened)
+}
+
+func testWebhooks(t *testing.T, s data.Store, _ payd.Transacter) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	_, err := s.Webhook(ctx, payd.WebhookArgs{WebhookID: "missing"})
+	assert.True(t, lathos.IsNotFound(err))
+
+	wh, err := s.WebhookCreate(ctx, payd.WebhookCreate{
+		WebhookID: "wh1",
+		URL:       "https://merchant.com/hooks",
+		Secret:    "shh",
+		Events:    []payd.WebhookEvent{payd.WebhookEventTxBroadcast, payd.WebhookEventInvoicePaid},
+		CreatedAt: now,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []payd.WebhookEvent{payd.WebhookEventInvoicePaid, payd.WebhookEventTxBroadcast}, wh.Events)
+	_, err = s.WebhookCreate(ctx, payd.WebhookCreate{
+		WebhookID: "wh1",
+		URL:       "https://merchant.com/hooks",
+		Events:    []payd.WebhookEvent{payd.WebhookEventInvoicePaid},
+		CreatedAt: now,
+	})
+	assert.True(t, lathos.IsDuplicate(err))
+	_, err = s.WebhookCreate(ctx, payd.WebhookCreate{
+		WebhookID: "wh2",
+		URL:       "https://other.com/hooks",
+		Secret:    "shh2",
+		Events:    []payd.WebhookEvent{payd.WebhookEventInvoiceExpired},
+		CreatedAt: now.Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	got, err := s.Webhook(ctx, payd.WebhookArgs{WebhookID: "wh1"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://merchant.com/hooks", got.URL)
+	assert.Equal(t, "shh", got.Secret)
+	assert.Equal(t, wh.Events, got.Events)
+	ww, err := s.Webhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, ww, 2)
+	assert.Equal(t, "wh1", ww[0].ID)
+	assert.Equal(t, []payd.WebhookEvent{payd.WebhookEventInvoiceExpired}, ww[1].Events)
+
+	require.NoError(t, s.WebhookDeliveriesCreate(ctx, []payd.WebhookDeliveryCreate{{
+		DeliveryID:    "d1",
+		WebhookID:     "wh1",
+		Event:         payd.WebhookEventInvoicePaid,
+		Payload:       []byte(`{"id":"inv1"}`),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}, {
+		DeliveryID:    "d2",
+		WebhookID:     "wh1",
+		Event:         payd.WebhookEventTxBroadcast,
+		Payload:       []byte(`{"txid":"abc"}`),
+		NextAttemptAt: now.Add(time.Hour),
+		CreatedAt:     now.Add(time.Second),
+	}, {
+		DeliveryID:    "d3",
+		WebhookID:     "wh2",
+		Event:         payd.WebhookEventInvoiceExpired,
+		Payload:       []byte(`{"id":"inv2"}`),
+		NextAttemptAt: now.Add(-time.Minute),
+		CreatedAt:     now.Add(2 * time.Second),
+	}}))
+	assert.NoError(t, s.WebhookDeliveriesCreate(ctx, nil))
+
+	d, err := s.WebhookDelivery(ctx, payd.WebhookDeliveryArgs{DeliveryID: "d1"})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateWebhookDeliveryPending, d.State)
+	assert.Equal(t, 0, d.Attempts)
+	assert.JSONEq(t, `{"id":"inv1"}`, string(d.Payload))
+	assert.True(t, now.Equal(d.NextAttemptAt))
+	_, err = s.WebhookDelivery(ctx, payd.WebhookDeliveryArgs{DeliveryID: "missing"})
+	assert.True(t, lathos.IsNotFound(err))
+
+	// deliveries are due once their next attempt has passed, oldest attempt first.
+	dd, err := s.WebhookDeliveriesDue(ctx, payd.WebhookDeliveriesDueArgs{DueBefore: now, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, dd, 2)
+	assert.Equal(t, "d3", dd[0].ID)
+	assert.Equal(t, "d1", dd[1].ID)
+	dd, err = s.WebhookDeliveriesDue(ctx, payd.WebhookDeliveriesDueArgs{DueBefore: now, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, dd, 1)
+
+	require.NoError(t, s.WebhookDeliveryUpdate(ctx, payd.WebhookDeliveryArgs{DeliveryID: "d1"}, payd.WebhookDeliveryUpdate{
+		State:         payd.StateWebhookDeliveryDelivered,
+		Attempts:      1,
+		NextAttemptAt: now,
+		DeliveredAt:   null.TimeFrom(now),
+		UpdatedAt:     now,
+	}))
+	require.NoError(t, s.WebhookDeliveryUpdate(ctx, payd.WebhookDeliveryArgs{DeliveryID: "d3"}, payd.WebhookDeliveryUpdate{
+		State:         payd.StateWebhookDeliveryDead,
+		Attempts:      10,
+		NextAttemptAt: now,
+		LastError:     null.StringFrom("connection refused"),
+		UpdatedAt:     now,
+	}))
+	err = s.WebhookDeliveryUpdate(ctx, payd.WebhookDeliveryArgs{DeliveryID: "missing"}, payd.WebhookDeliveryUpdate{
+		State:     payd.StateWebhookDeliveryDead,
+		UpdatedAt: now,
+	})
+	assert.True(t, lathos.IsNotFound(err))
+	dd, err = s.WebhookDeliveriesDue(ctx, payd.WebhookDeliveriesDueArgs{DueBefore: now.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, dd, 1)
+	assert.Equal(t, "d2", dd[0].ID)
+
+	d, err = s.WebhookDelivery(ctx, payd.WebhookDeliveryArgs{DeliveryID: "d3"})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateWebhookDeliveryDead, d.State)
+	assert.Equal(t, 10, d.Attempts)
+	assert.Equal(t, null.StringFrom("connection refused"), d.LastError)
+	assert.False(t, d.DeliveredAt.Valid)
+
+	// deliveries are listed newest first.
+	dd, err = s.WebhookDeliveries(ctx, payd.WebhookDeliveriesArgs{})
+	require.NoError(t, err)
+	require.Len(t, dd, 3)
+	assert.Equal(t, "d3", dd[0].ID)
+	assert.Equal(t, "d1", dd[2].ID)
+	dd, err = s.WebhookDeliveries(ctx, payd.WebhookDeliveriesArgs{WebhookID: "wh1", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, dd, 1)
+	assert.Equal(t, "d2", dd[0].ID)
+	dd, err = s.WebhookDeliveries(ctx, payd.WebhookDeliveriesArgs{State: payd.StateWebhookDeliveryDelivered})
+	require.NoError(t, err)
+	require.Len(t, dd, 1)
+	assert.Equal(t, "d1", dd[0].ID)
+	assert.True(t, dd[0].DeliveredAt.Valid)
+
+	// deleted webhooks are hidden and their deliveries are no longer due, but are still listed.
+	require.NoError(t, s.WebhookDelete(ctx, payd.WebhookArgs{WebhookID: "wh1"}))
+	assert.True(t, lathos.IsNotFound(s.WebhookDelete(ctx, payd.WebhookArgs{WebhookID: "wh1"})))
+	_, err = s.Webhook(ctx, payd.WebhookArgs{WebhookID: "wh1"})
+	assert.True(t, lathos.IsNotFound(err))
+	ww, err = s.Webhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, ww, 1)
+	assert.Equal(t, "wh2", ww[0].ID)
+	dd, err = s.WebhookDeliveriesDue(ctx, payd.WebhookDeliveriesDueArgs{DueBefore: now.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, dd)
+	dd, err = s.WebhookDeliveries(ctx, payd.WebhookDeliveriesArgs{WebhookID: "wh1"})
+	require.NoError(t, err)
+	assert.Len(t, dd, 2)
+}
+
+func testTransacter(t *testing.T, s data.Store, tr payd.Transacter) {
+	ctx := tr.WithTx(context.Background())
+	inv := invoiceCreate(t, s, 1000)
+	destinationsCreate(t, s, inv.ID, 1000)
+	_, err := s.InvoiceCreate(ctx, payd.InvoiceCreate{
+		InvoiceID: "rolledback",
+		Satoshis:  1000,
+		CreatedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tr.Rollback(ctx))
+	_, err = s.Invoice(context.Background(), payd.InvoiceArgs{InvoiceID: "rolledback"})
+	assert.True(t, lathos.IsNotFound(err), "expected not found error, got %v", err)
+
+	ctx = tr.WithTx(context.Background())
+	_, err = s.InvoiceCreate(ctx, payd.InvoiceCreate{
+		InvoiceID: "committed",
+		Satoshis:  1000,
+		CreatedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	_, err = s.DestinationsCreate(ctx, payd.DestinationsCreateArgs{InvoiceID: null.StringFrom("committed")}, []payd.DestinationCreate{{
+		Script:         newScript(t).String(),
+		DerivationPath: "1/2/3",
+		Satoshis:       1000,
+		UserID:         1,
+		KeyName:        "masterkey",
+	}})
+	require.NoError(t, err)
+	require.NoError(t, tr.Commit(ctx))
+	got, err := s.Invoice(context.Background(), payd.InvoiceArgs{InvoiceID: "committed"})
+	require.NoError(t, err)
+	assert.Equal(t, "committed", got.ID)
+	dd, err := s.Destinations(context.Background(), payd.DestinationsArgs{InvoiceID: "committed"})
+	require.NoError(t, err)
+	assert.Len(t, dd, 1)
+}
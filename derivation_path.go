@@ -12,7 +12,15 @@ type DerivationExistsArgs struct {
 	Path    string `db:"derivation_path"`
 }
 
+// DerivationPath is a path that has been used to derive a destination from a key.
+type DerivationPath struct {
+	Path          string `db:"derivation_path"`
+	LockingScript string `db:"locking_script"`
+}
+
 // DerivationReader can be used to read derivation path data from a data store.
 type DerivationReader interface {
 	DerivationPathExists(ctx context.Context, args DerivationExistsArgs) (bool, error)
+	// DerivationPaths returns every path used to derive a destination from the key.
+	DerivationPaths(ctx context.Context, args KeyArgs) ([]DerivationPath, error)
 }
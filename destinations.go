@@ -9,19 +9,55 @@ import (
 	"gopkg.in/guregu/null.v3"
 )
 
+// OutputSplit is the strategy used to split the satoshis requested by an invoice
+// across multiple outputs.
+type OutputSplit string
+
+// Supported output splits.
+const (
+	// OutputSplitNone requests all satoshis in a single output.
+	OutputSplitNone OutputSplit = "none"
+	// OutputSplitDenomination splits the satoshis into outputs of a fixed denomination,
+	// the last output takes the remainder.
+	OutputSplitDenomination OutputSplit = "denomination"
+	// OutputSplitEven splits the satoshis evenly across the max outputs.
+	OutputSplitEven OutputSplit = "even"
+	// OutputSplitRandom splits the satoshis into a random number of outputs of random
+	// amounts, making the payment harder to identify on chain.
+	OutputSplitRandom OutputSplit = "random"
+)
+
+func (o OutputSplit) String() string {
+	return string(o)
+}
+
+// DestinationsMaxOutputs is the most outputs the satoshis of a destination can be split into.
+const DestinationsMaxOutputs = 100
+
 // DestinationsCreate will create new destinations.
 type DestinationsCreate struct {
-	InvoiceID     null.String
-	Satoshis      uint64
+	InvoiceID null.String
+	Satoshis  uint64
+	// Split is how the satoshis are split across outputs, if empty the wallet default is used.
+	Split OutputSplit
+	// Denominations is the value of each output when split by denomination.
 	Denominations uint64
-	UserID        uint64
+	// MaxOutputs is the most outputs the satoshis are split into.
+	MaxOutputs int
+	UserID     uint64
 }
 
 // Validate will ensure arguments for destinationsCreate are valid, otherwise an error is returned.
 func (d DestinationsCreate) Validate() error {
-	return validator.New().
+	v := validator.New().
 		Validate("satoshis", validator.MinUInt64(d.Satoshis, 136)).
-		Err()
+		Validate("split", validator.AnyString(string(d.Split), "", string(OutputSplitNone),
+			string(OutputSplitDenomination), string(OutputSplitEven), string(OutputSplitRandom))).
+		Validate("maxOutputs", validator.BetweenInt(d.MaxOutputs, 0, DestinationsMaxOutputs))
+	if d.Split == OutputSplitDenomination {
+		v = v.Validate("denominations", validator.MinUInt64(d.Denominations, DustLimit))
+	}
+	return v.Err()
 }
 
 // DestinationCreate can be used to create a single Output for storage.
@@ -56,6 +92,8 @@ type Output struct {
 	// State will indicate if this destination is still waiting on a tx to fulfil it (pending)
 	// has been paid to in a tx (received) or has been deleted.
 	State string `json:"-" db:"state"  enums:"pending,received,deleted"`
+	// SatoshisReceived is the total already paid to this destination by stored txs.
+	SatoshisReceived uint64 `json:"-" db:"satoshis_received"`
 }
 
 // DestinationsArgs are used to get a set of Denominations
@@ -30,7 +30,7 @@ var doc = `{
     "paths": {
         "/v1/balance": {
             "get": {
-                "description": "Returns current balance, which is a sum of unspent txos",
+                "description": "Returns current balance, the unspent txos totalled by whether they are confirmed, mined, unconfirmed,\nreserved for a payment, or belong to a pending or failed tx. Confirmed, mined and unconfirmed txos\nare spendable, unless confirmations are required when only confirmed txos are spendable.\nThe balance can be scoped to the txos paid to a user or key.",
                 "consumes": [
                     "application/json"
                 ],
@@ -43,14 +43,37 @@ var doc = `{
                 "summary": "Balance",
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.Balance"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the args are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
                     }
-                }
+                },
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only include txos paid to this user",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include txos paid to destinations derived from this key",
+                        "name": "keyName",
+                        "in": "query"
+                    }
+                ]
             }
         },
         "/v1/invoices": {
             "get": {
-                "description": "Returns all invoices currently stored",
+                "description": "Returns a page of invoices matching the search params, newest first by default. When there are more\ninvoices the nextCursor returned should be supplied as the cursor to get the next page.",
                 "consumes": [
                     "application/json"
                 ],
@@ -61,9 +84,97 @@ var doc = `{
                     "Invoices"
                 ],
                 "summary": "Invoices",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "partially_paid",
+                            "paid",
+                            "refunded",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Only return invoices in this state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return invoices with this payment reference",
+                        "name": "reference",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return invoices created at or after this RFC3339 date",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return invoices created before this RFC3339 date",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return invoices expiring at or after this RFC3339 date",
+                        "name": "expiresFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return invoices expiring before this RFC3339 date",
+                        "name": "expiresTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return invoices for at least this many satoshis",
+                        "name": "satoshisMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return invoices for at most this many satoshis",
+                        "name": "satoshisMax",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by creation date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, defaults to 50, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.InvoicePage"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the search params are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
                     }
                 }
             },
@@ -159,6 +270,64 @@ var doc = `{
                 }
             }
         },
+        "/v1/invoices/{invoiceID}/refund": {
+            "post": {
+                "description": "Refunds a paid invoice to the refundTo paymail or locking script supplied with the payment.\nIf satoshis isn't set everything received, less any previous refunds, is refunded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Refund invoice",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "invoiceID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Amount to refund",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/payd.RefundCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/payd.InvoiceRefund"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the amount is more than is left to refund",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if the invoice has not been found",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the invoice isn't paid or has no refund address",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/owner": {
             "get": {
                 "description": "Returns information about the wallet owner",
@@ -341,104 +510,1329 @@ var doc = `{
                 }
             }
         },
-        "/v1/user/:id": {
-            "get": {}
-        }
-    },
-    "definitions": {
-        "bt.FeeQuote": {
-            "type": "object"
-        },
-        "envelope.JSONEnvelope": {
-            "type": "object",
-            "properties": {
-                "encoding": {
-                    "type": "string"
-                },
-                "mimetype": {
-                    "type": "string"
-                },
-                "payload": {
-                    "type": "string"
-                },
-                "publicKey": {
-                    "type": "string"
-                },
-                "signature": {
-                    "type": "string"
+        "/v1/reservations": {
+            "get": {
+                "description": "Returns the utxos reserved to fund payments, grouped by the url being paid.\nReservations past their expiry are released by a background job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "UTXO reservations",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return expired reservations",
+                        "name": "expired",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payd.UTXOReservation"
+                            }
+                        }
+                    }
                 }
-            }
-        },
-        "payd.ClientError": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "string",
-                    "example": "N01"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "e97970bf-2a88-4bc8-90e6-2f597a80b93d"
-                },
-                "message": {
-                    "type": "string",
-                    "example": "unable to find foo when loading bar"
-                },
-                "title": {
-                    "type": "string",
-                    "example": "not found"
+            },
+            "delete": {
+                "description": "Releases the utxos reserved for a payment so they can be spent again, use this\nto free utxos held by a stuck payment without waiting for the reservation to expire.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Release UTXO reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The payment attempt the utxos are reserved for, the url being paid with the attempt as its fragment",
+                        "name": "reservedFor",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "returned if reservedFor is missing",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if nothing is reserved for the url",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
                 }
             }
         },
-        "payd.DPPDestination": {
-            "type": "object",
-            "properties": {
-                "outputs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/payd.DPPOutput"
+        "/v1/transactions": {
+            "get": {
+                "description": "Returns a page of the txs sent and received by the wallet, newest first by default. Each tx has the\nnet amount for the wallet, the fee the wallet paid and the invoice or url it paid. When there are more\ntxs the nextCursor returned should be supplied as the cursor to get the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Transactions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "broadcast",
+                            "mined",
+                            "confirmed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Only return txs in this state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return txs created at or after this RFC3339 date",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return txs created before this RFC3339 date",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by creation date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, defaults to 50, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.TransactionPage"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the search params are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
                     }
                 }
             }
         },
-        "payd.DPPOutput": {
-            "type": "object",
-            "properties": {
-                "amount": {
-                    "type": "integer"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "script": {
-                    "type": "string"
-                }
-            }
+        "/v1/user/:id": {
+            "get": {}
         },
-        "payd.InvoiceCreate": {
+        "/v1/users/{id}/keys/{name}/mnemonic": {
+            "post": {
+                "description": "Returns the BIP39 mnemonic a key was created from. The mnemonic is deleted once revealed,\nso it must be written down, along with the wallet mnemonic passphrase if one is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Reveal key mnemonic",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.KeyMnemonic"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if the mnemonic has already been revealed or the key was imported",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the wallet is locked",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/{id}/keys/{name}/rescan": {
+            "post": {
+                "description": "Checks the key derives each destination previously derived from it, as stored in the database, and returns the balance they hold. The chain isn't searched for other funds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Rescan key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.KeyRescan"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/{id}/keys/{name}/restore": {
+            "post": {
+                "description": "Replaces a key with one restored from a BIP39 mnemonic, and optional passphrase, or an xprv.\nThe restored key must derive every destination previously derived from the key, the\nresult of this rescan is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keys"
+                ],
+                "summary": "Restore key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The key backup",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payd.KeyRestore"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.KeyRescan"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the mnemonic or xprv is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the restored key doesn't derive the previous destinations or the wallet is locked",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/utxos": {
+            "get": {
+                "description": "Returns a page of the txos paid to the wallet matching the search params, newest first by default.\nWhen there are more txos the nextCursor returned should be supplied as the cursor to get the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UTXOs"
+                ],
+                "summary": "UTXOs",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return spent txos when true, or unspent txos when false",
+                        "name": "spent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return txos reserved for a payment when true, or those that aren't when false",
+                        "name": "reserved",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return txos of txs with a merkle proof when true, or those without when false",
+                        "name": "confirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return frozen txos when true, or those that aren't when false",
+                        "name": "frozen",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return txos worth at least this many satoshis",
+                        "name": "satoshisMin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only return txos worth at most this many satoshis",
+                        "name": "satoshisMax",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return txos paid to this derivation path, or any path beneath it",
+                        "name": "derivationPath",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort by creation date",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, defaults to 50, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOPage"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the search params are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/utxos/consolidate": {
+            "post": {
+                "description": "Sweeps the smallest utxos, worth satoshisMax or less, into a single utxo at the standard fee rate.\nZero values default to the wallet config, this also runs on a schedule when WALLET_CONSOLIDATE_INTERVAL_SECONDS is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UTXOs"
+                ],
+                "summary": "Consolidate UTXOs",
+                "parameters": [
+                    {
+                        "description": "The utxos to consolidate",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOsConsolidate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOMaintenanceTx"
+                        }
+                    },
+                    "204": {
+                        "description": "returned if there are fewer than minUtxos small utxos"
+                    },
+                    "400": {
+                        "description": "returned if the args are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the utxos can't pay the fee",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/utxos/fanout": {
+            "post": {
+                "description": "Splits the largest utxo, worth satoshisMin or more, into outputs utxos of equal value at the standard fee rate.\nZero values default to the wallet config, this also runs on a schedule when WALLET_FANOUT_INTERVAL_SECONDS is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UTXOs"
+                ],
+                "summary": "Fan out UTXOs",
+                "parameters": [
+                    {
+                        "description": "The utxo to fan out",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOsFanOut"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOMaintenanceTx"
+                        }
+                    },
+                    "204": {
+                        "description": "returned if no utxos are worth satoshisMin"
+                    },
+                    "400": {
+                        "description": "returned if the args are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the outputs would be below the dust limit",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/utxos/freeze": {
+            "post": {
+                "description": "Freezes unspent txos so they are never reserved to fund a payment, if any\nof the outpoints aren't unspent txos of the wallet none are frozen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UTXOs"
+                ],
+                "summary": "Freeze UTXOs",
+                "parameters": [
+                    {
+                        "description": "The outpoints to freeze",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOsFreeze"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "returned if no outpoints are supplied",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if an outpoint isn't an unspent txo",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/utxos/unfreeze": {
+            "post": {
+                "description": "Unfreezes txos so they can be reserved to fund payments again, if any\nof the outpoints aren't unspent txos of the wallet none are unfrozen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "UTXOs"
+                ],
+                "summary": "Unfreeze UTXOs",
+                "parameters": [
+                    {
+                        "description": "The outpoints to unfreeze",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payd.UTXOsFreeze"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "returned if no outpoints are supplied",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if an outpoint isn't an unspent txo",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet/encrypt": {
+            "post": {
+                "description": "Encrypts the private keys at rest with the passphrase, the wallet is locked once encrypted.\nThe passphrase can't be recovered, if it is lost so are the funds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Encrypt wallet",
+                "parameters": [
+                    {
+                        "description": "The wallet passphrase",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payd.WalletEncrypt"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "returned if the passphrase is too short",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the wallet is already encrypted",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet/lock": {
+            "get": {
+                "description": "Returns whether the wallet private keys are encrypted and, if so, whether they are locked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Wallet lock status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.WalletLockStatus"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Removes the decrypted private keys from memory, payments can't be signed until the wallet\nis unlocked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Lock wallet",
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "422": {
+                        "description": "returned if the wallet isn't encrypted",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/wallet/unlock": {
+            "post": {
+                "description": "Decrypts the private keys with the passphrase, holding them in memory for timeout seconds.\nA timeout of zero keeps the wallet unlocked until it is locked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallet"
+                ],
+                "summary": "Unlock wallet",
+                "parameters": [
+                    {
+                        "description": "The wallet passphrase",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payd.WalletUnlock"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": "returned if the args are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "403": {
+                        "description": "returned if the passphrase is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    },
+                    "422": {
+                        "description": "returned if the wallet isn't encrypted",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "description": "Returns all registered webhooks, their secrets are not returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payd.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a url to be sent the events it subscribes to. Each event is posted as json with a\nX-Payd-Signature header of \"sha256=<hex HMAC-SHA256 of the body keyed with the secret>\".\nIf a secret isn't supplied one is generated, this is the only time the secret is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "Url, secret and events to subscribe to",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payd.WebhookCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/payd.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the url or events are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/deliveries": {
+            "get": {
+                "description": "Returns webhook deliveries, newest first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return deliveries to this webhook",
+                        "name": "webhookId",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "delivered",
+                            "dead"
+                        ],
+                        "type": "string",
+                        "description": "Only return deliveries in this state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max deliveries to return, defaults to 50, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payd.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "returned if the params are invalid",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/deliveries/{deliveryID}/replay": {
+            "post": {
+                "description": "Queues a delivery to be sent again with a fresh set of attempts, used to retry dead deliveries.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Replay webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.WebhookDelivery"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if the delivery or its webhook has not been found",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookID}": {
+            "get": {
+                "description": "Returns a webhook by id, the secret is not returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payd.Webhook"
+                        }
+                    },
+                    "404": {
+                        "description": "returned if the webhook has not been found",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes a webhook, deliveries waiting to be sent to it are not sent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": "returned if the webhook has not been found",
+                        "schema": {
+                            "$ref": "#/definitions/payd.ClientError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "bt.FeeQuote": {
+            "type": "object"
+        },
+        "envelope.JSONEnvelope": {
+            "type": "object",
+            "properties": {
+                "encoding": {
+                    "type": "string"
+                },
+                "mimetype": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "publicKey": {
+                    "type": "string"
+                },
+                "signature": {
+                    "type": "string"
+                }
+            }
+        },
+        "payd.Balance": {
+            "type": "object",
+            "properties": {
+                "confirmed": {
+                    "description": "Confirmed are the txos of txs that have a merkle proof and at least MinConfirmations.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "failed": {
+                    "description": "Failed are the txos of txs that failed to broadcast, these can't be spent.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "frozen": {
+                    "description": "Frozen are the txos that have been frozen so they aren't spent.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "minConfirmations": {
+                    "description": "MinConfirmations are the confirmations a tx needs before its txos can be spent.",
+                    "type": "integer"
+                },
+                "mined": {
+                    "description": "Mined are the txos of txs that have a merkle proof but fewer than MinConfirmations.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "pending": {
+                    "description": "Pending are the txos of txs that haven't been broadcast, these can't be spent.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "reserved": {
+                    "description": "Reserved are the txos reserved to fund a payment that hasn't completed.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "satoshis": {
+                    "description": "Satoshis is the total of the unspent txos of broadcast txs, the confirmed,\nmined, unconfirmed, reserved and frozen totals.",
+                    "type": "integer"
+                },
+                "spendable": {
+                    "description": "Spendable is the satoshis that can be used to fund a payment, the confirmed satoshis\nwhen MinConfirmations is set, otherwise the confirmed, mined and unconfirmed satoshis.",
+                    "type": "integer"
+                },
+                "tipHeight": {
+                    "description": "TipHeight is the height of the best chain tip the confirmations of each total are counted from.",
+                    "type": "integer"
+                },
+                "unconfirmed": {
+                    "description": "Unconfirmed are the txos of broadcast txs still waiting on a merkle proof.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.BalanceTotal"
+                        }
+                    ]
+                },
+                "utxos": {
+                    "description": "UTXOs is the number of unspent txos making up Satoshis.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.BalanceTotal": {
+            "type": "object",
+            "properties": {
+                "maxConfirmations": {
+                    "description": "MaxConfirmations are the most confirmations of a mined tx of the txos, 0 when none have been mined.",
+                    "type": "integer"
+                },
+                "minConfirmations": {
+                    "description": "MinConfirmations are the fewest confirmations of a mined tx of the txos, 0 when none have been mined.",
+                    "type": "integer"
+                },
+                "satoshis": {
+                    "type": "integer"
+                },
+                "utxos": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.ClientError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "N01"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "e97970bf-2a88-4bc8-90e6-2f597a80b93d"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "unable to find foo when loading bar"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "not found"
+                }
+            }
+        },
+        "payd.DPPDestination": {
+            "type": "object",
+            "properties": {
+                "outputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payd.DPPOutput"
+                    }
+                }
+            }
+        },
+        "payd.DPPOutput": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "script": {
+                    "type": "string"
+                }
+            }
+        },
+        "payd.Invoice": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "description": "BlockHeight is the height of the block containing the last mined of the txs paying\nthe invoice, it is null until every tx paying the invoice has been mined.",
+                    "type": "integer"
+                },
+                "confirmations": {
+                    "description": "Confirmations are the confirmations of BlockHeight, counted from the tip of the best chain.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "CreatedAt is the UTC time the object was created.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "DeletedAt is the date the object was removed.",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description is an optional text field that can have some further info\nlike 'invoice for oranges'.",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt is an optional param that can be passed to set an expiration\ndate on an invoice, after which, payments will not be accepted.",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID is a unique identifier for an invoice and can be used\nto lookup a single invoice.",
+                    "type": "string"
+                },
+                "paymentReceivedAt": {
+                    "description": "PaymentReceivedAt will be set when this invoice has been paid in full and\nstates when the final payment was received in UTC time.",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Reference is an identifier that can be used to link the\nPayD invoice with an external system.",
+                    "type": "string"
+                },
+                "refundTo": {
+                    "description": "RefundTo is an optional paymail address that can be used to refund the\ncustomer if required.",
+                    "type": "string"
+                },
+                "refundedAt": {
+                    "description": "RefundedAt if this payment has been refunded, this date will be set\nto the UTC time of the latest refund.",
+                    "type": "string"
+                },
+                "satoshis": {
+                    "description": "Satoshis is the total amount this invoice is to pay.",
+                    "type": "integer"
+                },
+                "satoshisReceived": {
+                    "description": "SatoshisReceived is the running total of all payments made against\nthis invoice, it can be paid in many instalments.",
+                    "type": "integer"
+                },
+                "satoshisRefunded": {
+                    "description": "SatoshisRefunded is the running total of all refunds paid back to\nthe customer, the invoice is refunded once it covers the satoshis received.",
+                    "type": "integer"
+                },
+                "state": {
+                    "description": "State is the current status of the invoice.",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "partially_paid",
+                        "paid",
+                        "refunded",
+                        "expired",
+                        "deleted"
+                    ]
+                },
+                "updatedAt": {
+                    "description": "UpdatedAt is the UTC time the object was updated.",
+                    "type": "string"
+                }
+            }
+        },
+        "payd.InvoiceCreate": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "Description is an optional text field that can have some further info\nlike 'invoice for oranges'.\nMaxLength is 1024 characters.",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "ExpiresAt is an optional param that can be passed to set an expiration\ndate on an invoice, after which, payments will not be accepted.",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Reference is an identifier that can be used to link the\npayd invoice with an external system.\nMaxLength is 32 characters.",
+                    "type": "string"
+                },
+                "satoshis": {
+                    "description": "Satoshis is the total amount this invoice is to pay.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.InvoicePage": {
+            "type": "object",
+            "properties": {
+                "invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payd.Invoice"
+                    }
+                },
+                "nextCursor": {
+                    "description": "NextCursor is supplied as the cursor to get the next page, it is empty\nwhen there are no more invoices.",
+                    "type": "string"
+                }
+            }
+        },
+        "payd.InvoiceRefund": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "invoiceId": {
+                    "type": "string"
+                },
+                "refundTo": {
+                    "description": "RefundTo is the paymail or locking script the refund was paid to.",
+                    "type": "string"
+                },
+                "satoshis": {
+                    "description": "Satoshis is the amount this transaction refunded to the customer.",
+                    "type": "integer"
+                },
+                "txId": {
+                    "type": "string"
+                }
+            }
+        },
+        "payd.KeyMnemonic": {
             "type": "object",
             "properties": {
-                "description": {
-                    "description": "Description is an optional text field that can have some further info\nlike 'invoice for oranges'.\nMaxLength is 1024 characters.",
+                "mnemonic": {
                     "type": "string"
+                }
+            }
+        },
+        "payd.KeyRescan": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "description": "Balance is the balance of the destinations derived from the key.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/payd.Balance"
+                        }
+                    ]
                 },
-                "expiresAt": {
-                    "description": "ExpiresAt is an optional param that can be passed to set an expiration\ndate on an invoice, after which, payments will not be accepted.",
+                "matched": {
+                    "description": "Matched is the number of paths the key derives the stored destination from,\nfunds paid to these can be spent.",
+                    "type": "integer"
+                },
+                "mismatched": {
+                    "description": "Mismatched are the paths the key doesn't derive the stored destination from,\nfunds paid to these can't be spent with this key.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "paths": {
+                    "description": "Paths is the number of derivation paths previously used by the key.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.KeyRestore": {
+            "type": "object",
+            "properties": {
+                "mnemonic": {
+                    "description": "Mnemonic is the BIP39 mnemonic of the key.",
                     "type": "string"
                 },
-                "reference": {
-                    "description": "Reference is an identifier that can be used to link the\npayd invoice with an external system.\nMaxLength is 32 characters.",
+                "passphrase": {
+                    "description": "Passphrase is the optional BIP39 passphrase used with the mnemonic.",
                     "type": "string"
                 },
-                "satoshis": {
-                    "description": "Satoshis is the total amount this invoice is to pay.",
-                    "type": "integer"
+                "xprv": {
+                    "description": "Xprv is the extended private key, it can be supplied instead of a mnemonic.",
+                    "type": "string"
                 }
             }
         },
         "payd.PayRequest": {
             "type": "object",
             "properties": {
+                "coinSelection": {
+                    "description": "CoinSelection is the strategy used to choose the utxos funding the payment,\nif not supplied the wallet default is used.",
+                    "type": "string",
+                    "enum": [
+                        "largest",
+                        "smallest",
+                        "bnb",
+                        "oldest",
+                        "privacy"
+                    ]
+                },
                 "payToURL": {
                     "type": "string"
                 }
@@ -477,6 +1871,257 @@ var doc = `{
                 }
             }
         },
+        "payd.RefundCreate": {
+            "type": "object",
+            "properties": {
+                "satoshis": {
+                    "description": "Satoshis is the amount to refund, if not set everything received\nagainst the invoice, less any previous refunds, is refunded.",
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "payd.TransactionHistory": {
+            "type": "object",
+            "properties": {
+                "confirmed": {
+                    "description": "Confirmed is true once the tx has a merkle proof.",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "direction": {
+                    "description": "Direction is sent when the tx spends wallet txos, otherwise it is received.",
+                    "type": "string",
+                    "enum": [
+                        "received",
+                        "sent"
+                    ]
+                },
+                "failReason": {
+                    "description": "FailReason is set when the tx failed to broadcast.",
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "Fee is the mining fee paid by the wallet, it is 0 for received txs as the payer paid it.",
+                    "type": "integer"
+                },
+                "invoiceId": {
+                    "description": "InvoiceID is the invoice paid by a received tx, or refunded by a sent tx.",
+                    "type": "string"
+                },
+                "paidTo": {
+                    "description": "PaidTo is the url paid by a sent tx, this is what the spent txos were reserved for so it has the\npayment attempt as its fragment.",
+                    "type": "string"
+                },
+                "received": {
+                    "description": "Received is the total of the outputs paying the wallet, including change.",
+                    "type": "integer"
+                },
+                "satoshis": {
+                    "description": "Satoshis is the net amount for the wallet, received less spent, it is negative for sent txs.",
+                    "type": "integer"
+                },
+                "spent": {
+                    "description": "Spent is the total of the wallet txos spent by the tx.",
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "broadcast",
+                        "mined",
+                        "confirmed",
+                        "failed"
+                    ]
+                },
+                "txid": {
+                    "type": "string"
+                }
+            }
+        },
+        "payd.TransactionPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "NextCursor is supplied as the cursor to get the next page, it is empty\nwhen there are no more txs.",
+                    "type": "string"
+                },
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payd.TransactionHistory"
+                    }
+                }
+            }
+        },
+        "payd.UTXODetail": {
+            "type": "object",
+            "properties": {
+                "confirmed": {
+                    "description": "Confirmed is true once the tx paying the txo has a merkle proof.",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "derivationPath": {
+                    "type": "string"
+                },
+                "frozen": {
+                    "description": "Frozen txos are never reserved to fund a payment.",
+                    "type": "boolean"
+                },
+                "keyName": {
+                    "type": "string"
+                },
+                "lockingScript": {
+                    "type": "string"
+                },
+                "outpoint": {
+                    "type": "string"
+                },
+                "reservedFor": {
+                    "description": "ReservedFor is the payment the txo is reserved to fund.",
+                    "type": "string"
+                },
+                "reservedUntil": {
+                    "type": "string"
+                },
+                "satoshis": {
+                    "type": "integer"
+                },
+                "spendingTxId": {
+                    "type": "string"
+                },
+                "spentAt": {
+                    "type": "string"
+                },
+                "txState": {
+                    "description": "TxState is the state of the tx paying the txo, only txos of broadcast txs can be spent,\nand only those of confirmed txs when confirmations are required.",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "broadcast",
+                        "mined",
+                        "confirmed",
+                        "failed"
+                    ]
+                },
+                "txid": {
+                    "type": "string"
+                },
+                "vout": {
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.UTXOMaintenanceTx": {
+            "type": "object",
+            "properties": {
+                "fee": {
+                    "description": "Fee is the mining fee paid, the spent utxos less Satoshis.",
+                    "type": "integer"
+                },
+                "inputs": {
+                    "description": "Inputs is the number of utxos spent.",
+                    "type": "integer"
+                },
+                "outputs": {
+                    "description": "Outputs is the number of utxos created.",
+                    "type": "integer"
+                },
+                "satoshis": {
+                    "description": "Satoshis is the total of the utxos created.",
+                    "type": "integer"
+                },
+                "txid": {
+                    "type": "string"
+                }
+            }
+        },
+        "payd.UTXOPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "description": "NextCursor is supplied as the cursor to get the next page, it is empty\nwhen there are no more txos.",
+                    "type": "string"
+                },
+                "utxos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payd.UTXODetail"
+                    }
+                }
+            }
+        },
+        "payd.UTXOReservation": {
+            "type": "object",
+            "properties": {
+                "expired": {
+                    "description": "Expired is true once ReservedUntil has passed, the reservation is released on the next run.",
+                    "type": "boolean"
+                },
+                "reservedFor": {
+                    "description": "ReservedFor identifies the payment attempt, usually the url being paid with the attempt as its fragment.",
+                    "type": "string"
+                },
+                "reservedUntil": {
+                    "description": "ReservedUntil is when the reservation expires and the utxos are released.",
+                    "type": "string"
+                },
+                "satoshis": {
+                    "type": "integer"
+                },
+                "utxos": {
+                    "description": "UTXOs is the number of utxos reserved.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.UTXOsConsolidate": {
+            "type": "object",
+            "properties": {
+                "maxUtxos": {
+                    "description": "MaxUTXOs is the most utxos swept by the tx, the smallest are swept first.",
+                    "type": "integer"
+                },
+                "minUtxos": {
+                    "description": "MinUTXOs is the fewest small utxos worth consolidating, if there are fewer nothing is done.",
+                    "type": "integer"
+                },
+                "satoshisMax": {
+                    "description": "SatoshisMax is the value at or below which utxos are consolidated.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.UTXOsFanOut": {
+            "type": "object",
+            "properties": {
+                "outputs": {
+                    "description": "Outputs is the number of utxos the largest utxo is split into.",
+                    "type": "integer"
+                },
+                "satoshisMin": {
+                    "description": "SatoshisMin is the value at or above which a utxo is split.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.UTXOsFreeze": {
+            "type": "object",
+            "properties": {
+                "outpoints": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "payd.User": {
             "type": "object",
             "properties": {
@@ -503,6 +2148,158 @@ var doc = `{
                     "type": "string"
                 }
             }
+        },
+        "payd.WalletEncrypt": {
+            "type": "object",
+            "properties": {
+                "passphrase": {
+                    "type": "string"
+                }
+            }
+        },
+        "payd.WalletLockStatus": {
+            "type": "object",
+            "properties": {
+                "encrypted": {
+                    "description": "Encrypted is true once the wallet keys have been encrypted with a passphrase.",
+                    "type": "boolean"
+                },
+                "locked": {
+                    "description": "Locked is true if the wallet is encrypted and hasn't been unlocked, while locked\npayments can't be signed.",
+                    "type": "boolean"
+                },
+                "unlockedUntil": {
+                    "description": "UnlockedUntil is when the wallet will lock itself, it is empty if the wallet is\nlocked or won't lock itself.",
+                    "type": "string"
+                }
+            }
+        },
+        "payd.WalletUnlock": {
+            "type": "object",
+            "properties": {
+                "passphrase": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "description": "Timeout is the number of seconds until the wallet is locked again, if zero it\nstays unlocked until it is locked.",
+                    "type": "integer"
+                }
+            }
+        },
+        "payd.Webhook": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events are the events delivered to the webhook.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "invoice.created",
+                            "invoice.paid",
+                            "invoice.expired",
+                            "tx.broadcast",
+                            "tx.failed",
+                            "proof.received",
+                            "proof.orphaned"
+                        ]
+                    }
+                },
+                "id": {
+                    "description": "ID uniquely identifies the webhook.",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret is used to sign each delivery with a HMAC-SHA256 of the body, it is only\nreturned when the webhook is created.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the endpoint events are posted to.",
+                    "type": "string"
+                }
+            }
+        },
+        "payd.WebhookCreate": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "Events are the events to deliver to the webhook, at least one is required.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "invoice.created",
+                            "invoice.paid",
+                            "invoice.expired",
+                            "tx.broadcast",
+                            "tx.failed",
+                            "proof.received",
+                            "proof.orphaned"
+                        ]
+                    }
+                },
+                "secret": {
+                    "description": "Secret is used to sign deliveries, if not supplied one is generated.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the http or https endpoint events are posted to.",
+                    "type": "string",
+                    "example": "https://merchant.com/payd/events"
+                }
+            }
+        },
+        "payd.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts is the number of times delivery has been tried.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID uniquely identifies the delivery, it is sent in the X-Payd-Delivery header\nso receivers can ignore deliveries they have already handled.",
+                    "type": "string"
+                },
+                "lastError": {
+                    "description": "LastError is the reason the last attempt failed.",
+                    "type": "string"
+                },
+                "nextAttemptAt": {
+                    "description": "NextAttemptAt is when a pending delivery will next be tried.",
+                    "type": "string"
+                },
+                "payload": {
+                    "description": "Payload is the event data, sent as the data field of the message.",
+                    "type": "object"
+                },
+                "state": {
+                    "description": "State is pending until the delivery succeeds, or it is dead lettered\nafter running out of attempts.",
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "delivered",
+                        "dead"
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -11,6 +11,8 @@ import (
 // EnvelopeArgs identify where an envelope is being paid to.
 type EnvelopeArgs struct {
 	PayToURL string `json:"payToURL"`
+	// CoinSelection is the strategy used to fund the envelope tx, the wallet default is used if empty.
+	CoinSelection CoinSelection `json:"coinSelection"`
 }
 
 // Validate will ensure that the args supplied are valid.
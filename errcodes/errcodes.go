@@ -5,9 +5,34 @@ const (
 	ErrDuplicatePayment = "D1"
 	ErrExpiredPayment   = "E1"
 
-	ErrInvoiceNotFound          = "N0001"
-	ErrInvoicesNotFound         = "N0002"
-	ErrDestinationsNotFound     = "N0003"
-	ErrDestinationsFailedCreate = "N0004"
-	ErrTxNotFound               = "N0005"
+	ErrInvoiceNotFound           = "N0001"
+	ErrInvoicesNotFound          = "N0002"
+	ErrDestinationsNotFound      = "N0003"
+	ErrDestinationsFailedCreate  = "N0004"
+	ErrTxNotFound                = "N0005"
+	ErrPeerChannelNotFound       = "N0006"
+	ErrPaymailCapabilityNotFound = "N0007"
+	ErrWebhookNotFound           = "N0008"
+	ErrWebhookDeliveryNotFound   = "N0009"
+	ErrProofCallbackNotFound     = "N0010"
+	ErrUTXOReservationNotFound   = "N0011"
+	ErrUTXONotFound              = "N0012"
+	ErrPrivateKeyNotFound        = "N0013"
+	ErrMnemonicNotFound          = "N0014"
+
+	ErrKeyRestoreMismatch = "K0001"
+	ErrKeyWatchOnly       = "K0002"
+
+	ErrRefundInvoiceNotPaid = "R0001"
+	ErrRefundNoRefundTo     = "R0002"
+	ErrRefundExceeded       = "R0003"
+
+	ErrTxStateMined = "T0001"
+
+	ErrUTXOsDust = "U0001"
+
+	ErrWalletLocked       = "W0001"
+	ErrWalletPassphrase   = "W0002"
+	ErrWalletEncrypted    = "W0003"
+	ErrWalletNotEncrypted = "W0004"
 )
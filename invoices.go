@@ -2,6 +2,8 @@ package payd
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
 	"time"
 
 	"github.com/libsv/go-bt/v2"
@@ -14,10 +16,12 @@ type InvoiceState string
 
 // contains states that an invocie can have.
 const (
-	StateInvoicePending  InvoiceState = "pending"
-	StateInvoicePaid     InvoiceState = "paid"
-	StateInvoiceRefunded InvoiceState = "refunded"
-	StateInvoiceDeleted  InvoiceState = "deleted"
+	StateInvoicePending       InvoiceState = "pending"
+	StateInvoicePartiallyPaid InvoiceState = "partially_paid"
+	StateInvoicePaid          InvoiceState = "paid"
+	StateInvoiceRefunded      InvoiceState = "refunded"
+	StateInvoiceExpired       InvoiceState = "expired"
+	StateInvoiceDeleted       InvoiceState = "deleted"
 )
 
 func (i InvoiceState) String() string {
@@ -39,20 +43,31 @@ type Invoice struct {
 	Description null.String `json:"description" db:"description"`
 	// Satoshis is the total amount this invoice is to pay.
 	Satoshis uint64 `json:"satoshis" db:"satoshis"`
+	// SatoshisReceived is the running total of all payments made against
+	// this invoice, it can be paid in many instalments.
+	SatoshisReceived uint64 `json:"satoshisReceived" db:"satoshis_received"`
+	// SatoshisRefunded is the running total of all refunds paid back to
+	// the customer, the invoice is refunded once it covers the satoshis received.
+	SatoshisRefunded uint64 `json:"satoshisRefunded" db:"satoshis_refunded"`
 	// ExpiresAt is an optional param that can be passed to set an expiration
 	// date on an invoice, after which, payments will not be accepted.
 	ExpiresAt null.Time `json:"expiresAt" db:"expires_at"`
-	// PaymentReceivedAt will be set when this invoice has been paid and
-	// states when the payment was received in UTC time.
+	// PaymentReceivedAt will be set when this invoice has been paid in full and
+	// states when the final payment was received in UTC time.
 	PaymentReceivedAt null.Time `json:"paymentReceivedAt" db:"payment_received_at"`
 	// RefundTo is an optional paymail address that can be used to refund the
 	// customer if required.
 	RefundTo null.String `json:"refundTo" db:"refund_to"`
 	// RefundedAt if this payment has been refunded, this date will be set
-	// to the UTC time of the refund.
+	// to the UTC time of the latest refund.
 	RefundedAt null.Time `json:"refundedAt" db:"refunded_at"`
 	// State is the current status of the invoice.
-	State InvoiceState `json:"state" db:"state" enums:"pending,paid,refunded,deleted"`
+	State InvoiceState `json:"state" db:"state" enums:"pending,partially_paid,paid,refunded,expired,deleted"`
+	// BlockHeight is the height of the block containing the last mined of the txs paying
+	// the invoice, it is null until every tx paying the invoice has been mined.
+	BlockHeight null.Int `json:"blockHeight" db:"block_height" swaggertype:"primitive,integer"`
+	// Confirmations are the confirmations of BlockHeight, counted from the tip of the best chain.
+	Confirmations uint32 `json:"confirmations" db:"-"`
 	// SPVRequired if true will mean this invoice requires a valid spvenvelope otherwise a rawTX will suffice.
 	SPVRequired bool `json:"-" db:"spv_required"`
 	MetaData
@@ -90,19 +105,73 @@ func (i InvoiceCreate) Validate(svc TimestampService) error {
 		Err()
 }
 
-// InvoiceUpdatePaid can be used to update an invoice after it has been created.
+// InvoiceUpdatePaid records a payment against an invoice, the invoice is
+// partially paid until the payments received cover the invoice satoshis.
 type InvoiceUpdatePaid struct {
 	PaymentReceivedAt time.Time `db:"payment_received_at"`
 	RefundTo          string    `db:"refund_to"`
+	// TxID is the transaction making the payment.
+	TxID string `db:"tx_id"`
+	// Satoshis is the amount the transaction pays to the invoice destinations.
+	Satoshis uint64 `db:"satoshis"`
+}
+
+// InvoicePayment is a single payment made against an invoice, an invoice
+// can be paid by many transactions.
+type InvoicePayment struct {
+	InvoiceID string `json:"invoiceId" db:"invoice_id"`
+	TxID      string `json:"txId" db:"tx_id"`
+	// Satoshis is the amount this transaction paid towards the invoice.
+	Satoshis uint64 `json:"satoshis" db:"satoshis"`
+	// RefundTo is the optional refund address supplied with the payment.
+	RefundTo  null.String `json:"refundTo" db:"refund_to"`
+	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
 }
 
-// InvoiceUpdateRefunded can be used to update an invoice state to refunded.
+// InvoiceUpdateRefunded records a refund against an invoice, the invoice is
+// refunded once the refunds cover the satoshis received.
 type InvoiceUpdateRefunded struct {
-	// RefundTo will set an invoice as refunded.
+	// RefundTo is the paymail or locking script the refund was paid to.
 	RefundTo null.String `db:"refund_to"`
 	// RefundedAt if this payment has been refunded, this date will be set
 	// to the UTC time of the refund.
 	RefundedAt null.Time `json:"refundedAt" db:"refunded_at"`
+	// TxID is the transaction paying the refund.
+	TxID string `db:"tx_id"`
+	// Satoshis is the amount refunded to the customer.
+	Satoshis uint64 `db:"satoshis"`
+}
+
+// InvoicesExpireArgs identify the invoices to expire.
+type InvoicesExpireArgs struct {
+	// ExpiresBefore, pending or partially paid invoices that expire at or before
+	// this time are expired.
+	ExpiresBefore time.Time
+	// BatchSize is how many invoices stores expire per statement, zero uses InvoicesExpireBatchSize.
+	BatchSize int
+}
+
+// InvoicesExpireBatchSize is how many invoices are expired per statement by default.
+const InvoicesExpireBatchSize = 500
+
+// Batch returns the number of invoices to expire per statement.
+func (i InvoicesExpireArgs) Batch() int {
+	if i.BatchSize <= 0 {
+		return InvoicesExpireBatchSize
+	}
+	return i.BatchSize
+}
+
+// InvoiceRefund is a single refund paid back to the customer, an invoice
+// can be refunded in part many times.
+type InvoiceRefund struct {
+	InvoiceID string `json:"invoiceId" db:"invoice_id"`
+	TxID      string `json:"txId" db:"tx_id"`
+	// Satoshis is the amount this transaction refunded to the customer.
+	Satoshis uint64 `json:"satoshis" db:"satoshis"`
+	// RefundTo is the paymail or locking script the refund was paid to.
+	RefundTo  string    `json:"refundTo" db:"refund_to"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
 // InvoiceUpdateArgs are used to identify the invoice to update.
@@ -122,15 +191,186 @@ func (i *InvoiceArgs) Validate() error {
 		Err()
 }
 
+// Invoice search defaults and limits.
+const (
+	// InvoiceSearchDefaultLimit is the page size used when no limit is supplied.
+	InvoiceSearchDefaultLimit = 50
+	// InvoiceSearchMaxLimit is the largest page size that can be requested.
+	InvoiceSearchMaxLimit = 500
+)
+
+// SortOrder defines the order results are returned in.
+type SortOrder string
+
+// Supported sort orders.
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// InvoiceCursor marks the position of the last invoice in a page, invoices are
+// ordered by their creation date then id so the next page starts after this pair.
+//
+// It is sent to clients as an opaque string.
+type InvoiceCursor struct {
+	CreatedAt time.Time
+	InvoiceID string
+}
+
+// IsZero returns true if the cursor is empty, meaning the first page is requested.
+func (c InvoiceCursor) IsZero() bool {
+	return c.InvoiceID == ""
+}
+
+// MarshalText encodes the cursor to an opaque url safe string.
+func (c InvoiceCursor) MarshalText() ([]byte, error) {
+	if c.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(
+		[]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.InvoiceID))), nil
+}
+
+// UnmarshalText decodes a cursor previously encoded with MarshalText.
+func (c *InvoiceCursor) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = InvoiceCursor{}
+		return nil
+	}
+	bb, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	parts := strings.SplitN(string(bb), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	c.CreatedAt = createdAt
+	c.InvoiceID = parts[1]
+	return nil
+}
+
+// String returns the encoded cursor.
+func (c InvoiceCursor) String() string {
+	bb, _ := c.MarshalText()
+	return string(bb)
+}
+
+// InvoiceSearchArgs are used to filter and page through invoices, deleted
+// invoices are never returned. Zero values are ignored.
+type InvoiceSearchArgs struct {
+	// State will only return invoices in this state.
+	State InvoiceState `query:"state" enums:"pending,partially_paid,paid,refunded,expired"`
+	// Reference will only return invoices with this payment reference.
+	Reference string `query:"reference"`
+	// CreatedFrom will return invoices created at or after this time.
+	CreatedFrom null.Time `query:"createdFrom" swaggertype:"primitive,string"`
+	// CreatedTo will return invoices created before this time.
+	CreatedTo null.Time `query:"createdTo" swaggertype:"primitive,string"`
+	// ExpiresFrom will return invoices expiring at or after this time.
+	ExpiresFrom null.Time `query:"expiresFrom" swaggertype:"primitive,string"`
+	// ExpiresTo will return invoices expiring before this time.
+	ExpiresTo null.Time `query:"expiresTo" swaggertype:"primitive,string"`
+	// SatoshisMin will return invoices for at least this many satoshis.
+	SatoshisMin uint64 `query:"satoshisMin"`
+	// SatoshisMax will return invoices for at most this many satoshis.
+	SatoshisMax uint64 `query:"satoshisMax"`
+	// Sort orders invoices by creation date, defaults to desc, newest first.
+	Sort SortOrder `query:"sort" enums:"asc,desc"`
+	// Limit is the max number of invoices returned.
+	Limit int `query:"limit"`
+	// Cursor is the next cursor returned with the previous page, when
+	// supplied the invoices after it are returned. The other search args
+	// should match those used to get the previous page.
+	Cursor InvoiceCursor `query:"cursor" swaggertype:"primitive,string"`
+}
+
+// Validate will check that the search args match expectations.
+func (i InvoiceSearchArgs) Validate() error {
+	v := validator.New().
+		Validate("state", validator.AnyString(string(i.State), "", string(StateInvoicePending),
+			string(StateInvoicePartiallyPaid), string(StateInvoicePaid), string(StateInvoiceRefunded), string(StateInvoiceExpired))).
+		Validate("sort", validator.AnyString(string(i.Sort), "", string(SortAsc), string(SortDesc))).
+		Validate("limit", validator.BetweenInt(i.Limit, 0, InvoiceSearchMaxLimit)).
+		Validate("reference", validator.StrLength(i.Reference, 0, 32))
+	if i.SatoshisMax > 0 {
+		v = v.Validate("satoshisMax", validator.MinUInt64(i.SatoshisMax, i.SatoshisMin))
+	}
+	if i.CreatedFrom.Valid && i.CreatedTo.Valid {
+		v = v.Validate("createdTo", validator.DateAfter(i.CreatedTo.Time, i.CreatedFrom.Time))
+	}
+	if i.ExpiresFrom.Valid && i.ExpiresTo.Valid {
+		v = v.Validate("expiresTo", validator.DateAfter(i.ExpiresTo.Time, i.ExpiresFrom.Time))
+	}
+	return v.Err()
+}
+
+// Descending returns true if invoices should be returned newest first.
+func (i InvoiceSearchArgs) Descending() bool {
+	return i.Sort != SortAsc
+}
+
+// Matches returns true if inv matches the search filters and comes after the cursor
+// in the sort order, it is used by stores that filter invoices in process.
+func (i InvoiceSearchArgs) Matches(inv Invoice) bool {
+	switch {
+	case i.State != "" && inv.State != i.State,
+		i.Reference != "" && inv.Reference.ValueOrZero() != i.Reference,
+		i.CreatedFrom.Valid && inv.CreatedAt.Before(i.CreatedFrom.Time),
+		i.CreatedTo.Valid && !inv.CreatedAt.Before(i.CreatedTo.Time),
+		i.ExpiresFrom.Valid && (!inv.ExpiresAt.Valid || inv.ExpiresAt.Time.Before(i.ExpiresFrom.Time)),
+		i.ExpiresTo.Valid && (!inv.ExpiresAt.Valid || !inv.ExpiresAt.Time.Before(i.ExpiresTo.Time)),
+		i.SatoshisMin > 0 && inv.Satoshis < i.SatoshisMin,
+		i.SatoshisMax > 0 && inv.Satoshis > i.SatoshisMax:
+		return false
+	}
+	if i.Cursor.IsZero() {
+		return true
+	}
+	after := inv.CreatedAt.After(i.Cursor.CreatedAt) ||
+		inv.CreatedAt.Equal(i.Cursor.CreatedAt) && inv.ID > i.Cursor.InvoiceID
+	before := inv.CreatedAt.Before(i.Cursor.CreatedAt) ||
+		inv.CreatedAt.Equal(i.Cursor.CreatedAt) && inv.ID < i.Cursor.InvoiceID
+	if i.Descending() {
+		return before
+	}
+	return after
+}
+
+// InvoicePage is a single page of invoice search results.
+type InvoicePage struct {
+	Invoices []Invoice `json:"invoices"`
+	// NextCursor is supplied as the cursor to get the next page, it is empty
+	// when there are no more invoices.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
 // InvoiceService defines a service for managing invoices.
 type InvoiceService interface {
 	Invoice(ctx context.Context, args InvoiceArgs) (*Invoice, error)
-	Invoices(ctx context.Context) ([]Invoice, error)
+	Invoices(ctx context.Context, args InvoiceSearchArgs) (*InvoicePage, error)
 	InvoicesPending(ctx context.Context) ([]Invoice, error)
 	Create(ctx context.Context, req InvoiceCreate) (*Invoice, error)
 	Delete(ctx context.Context, args InvoiceArgs) error
 }
 
+// InvoiceExpiryService moves invoices that haven't been paid in full by their expiry date
+// to the expired state.
+type InvoiceExpiryService interface {
+	// InvoicesExpire will expire all overdue invoices and return them.
+	InvoicesExpire(ctx context.Context) ([]Invoice, error)
+}
+
+// InvoiceExpiryNotifier is told about each invoice as it expires, it is used to close
+// any channels joined to take payment for the invoice and to let others know it expired.
+type InvoiceExpiryNotifier interface {
+	InvoiceExpired(ctx context.Context, inv Invoice) error
+}
+
 // InvoiceReaderWriter can be implemented to support storing and retrieval of invoices.
 type InvoiceReaderWriter interface {
 	InvoiceWriter
@@ -141,8 +381,17 @@ type InvoiceReaderWriter interface {
 type InvoiceWriter interface {
 	// Create will persist a new Invoice in the data store.
 	InvoiceCreate(ctx context.Context, req InvoiceCreate) (*Invoice, error)
-	// Update will update an invoice matching the provided args with the requested changes.
+	// Update will record a payment against an invoice matching the provided args, adding it to the
+	// satoshis received and marking the invoice paid once it is covered or partially paid if not.
+	// Only pending or partially paid invoices are updated, any other invoice is returned unchanged.
 	InvoiceUpdate(ctx context.Context, args InvoiceUpdateArgs, req InvoiceUpdatePaid) (*Invoice, error)
+	// InvoiceRefund will record a refund against an invoice matching the provided args, adding it to the
+	// satoshis refunded and marking the invoice refunded once it covers the satoshis received.
+	// Only paid, partially paid or expired invoices are updated, any other invoice is returned unchanged.
+	InvoiceRefund(ctx context.Context, args InvoiceUpdateArgs, req InvoiceUpdateRefunded) (*Invoice, error)
+	// InvoicesExpire will move the pending or partially paid invoices matching args to the expired
+	// state, deleting any of their destinations still waiting to be paid, and return them.
+	InvoicesExpire(ctx context.Context, args InvoicesExpireArgs) ([]Invoice, error)
 	// Delete will remove an invoice from the data store, depending on implementation this could
 	// be a hard or soft delete.
 	InvoiceDelete(ctx context.Context, args InvoiceArgs) error
@@ -152,7 +401,14 @@ type InvoiceWriter interface {
 type InvoiceReader interface {
 	// Invoice will return an invoice that matches the provided args.
 	Invoice(ctx context.Context, args InvoiceArgs) (*Invoice, error)
-	// Invoices returns all currently stored invoices TODO: update to support search args
-	Invoices(ctx context.Context) ([]Invoice, error)
+	// Invoices returns the invoices matching args, ordered by creation date then id.
+	// At most args.Limit invoices are returned, a zero limit returns all matches.
+	Invoices(ctx context.Context, args InvoiceSearchArgs) ([]Invoice, error)
+	// InvoicesPending returns the invoices still waiting to be paid in full, these are
+	// pending or partially paid.
 	InvoicesPending(ctx context.Context) ([]Invoice, error)
+	// InvoicePayments returns the payments made against an invoice, oldest first.
+	InvoicePayments(ctx context.Context, args InvoiceArgs) ([]InvoicePayment, error)
+	// InvoiceRefunds returns the refunds paid against an invoice, oldest first.
+	InvoiceRefunds(ctx context.Context, args InvoiceArgs) ([]InvoiceRefund, error)
 }
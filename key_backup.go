@@ -0,0 +1,71 @@
+package payd
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// KeyMnemonic is the BIP39 mnemonic a key was created from.
+type KeyMnemonic struct {
+	Mnemonic string `json:"mnemonic"`
+}
+
+// KeyRestore is used to restore a key from a backup, either a BIP39 mnemonic or an xprv.
+type KeyRestore struct {
+	// Mnemonic is the BIP39 mnemonic of the key.
+	Mnemonic string `json:"mnemonic"`
+	// Passphrase is the optional BIP39 passphrase used with the mnemonic.
+	Passphrase string `json:"passphrase"`
+	// Xprv is the extended private key, it can be supplied instead of a mnemonic.
+	Xprv string `json:"xprv"`
+}
+
+// Validate will check that exactly one of a mnemonic or xprv is supplied.
+func (k KeyRestore) Validate() error {
+	return validator.New().
+		Validate("mnemonic", func() error {
+			if k.Mnemonic == "" && k.Xprv == "" {
+				return errors.New("a mnemonic or xprv is required")
+			}
+			return nil
+		}).
+		Validate("xprv", func() error {
+			if k.Mnemonic != "" && k.Xprv != "" {
+				return errors.New("an xprv can't be supplied with a mnemonic")
+			}
+			return nil
+		}).
+		Validate("passphrase", func() error {
+			if k.Passphrase != "" && k.Mnemonic == "" {
+				return errors.New("a passphrase can only be used with a mnemonic")
+			}
+			return nil
+		}).Err()
+}
+
+// KeyRescan is the result of checking the destinations previously derived from a key.
+type KeyRescan struct {
+	// Paths is the number of derivation paths previously used by the key.
+	Paths int `json:"paths"`
+	// Matched is the number of paths the key derives the stored destination from,
+	// funds paid to these can be spent.
+	Matched int `json:"matched"`
+	// Mismatched are the paths the key doesn't derive the stored destination from,
+	// funds paid to these can't be spent with this key.
+	Mismatched []string `json:"mismatched"`
+	// Balance is the balance of the destinations derived from the key.
+	Balance *Balance `json:"balance"`
+}
+
+// KeyBackupService is used to back up and restore private keys.
+type KeyBackupService interface {
+	// KeyMnemonicReveal returns the mnemonic a key was created from, it can only be revealed once.
+	KeyMnemonicReveal(ctx context.Context, args KeyArgs) (*KeyMnemonic, error)
+	// KeyRestore replaces a key with one restored from a mnemonic or xprv, then rescans it.
+	KeyRestore(ctx context.Context, args KeyArgs, req KeyRestore) (*KeyRescan, error)
+	// KeyRescan checks the key derives the destinations stored for it, returning the funds recorded
+	// against them. The chain isn't searched for funds paid to destinations that aren't stored.
+	KeyRescan(ctx context.Context, args KeyArgs) (*KeyRescan, error)
+}
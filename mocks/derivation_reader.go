@@ -23,6 +23,9 @@ var _ payd.DerivationReader = &DerivationReaderMock{}
 // 			DerivationPathExistsFunc: func(ctx context.Context, args payd.DerivationExistsArgs) (bool, error) {
 // 				panic("mock out the DerivationPathExists method")
 // 			},
+// 			DerivationPathsFunc: func(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+// 				panic("mock out the DerivationPaths method")
+// 			},
 // 		}
 //
 // 		// use mockedDerivationReader in code that requires payd.DerivationReader
@@ -33,6 +36,9 @@ type DerivationReaderMock struct {
 	// DerivationPathExistsFunc mocks the DerivationPathExists method.
 	DerivationPathExistsFunc func(ctx context.Context, args payd.DerivationExistsArgs) (bool, error)
 
+	// DerivationPathsFunc mocks the DerivationPaths method.
+	DerivationPathsFunc func(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// DerivationPathExists holds details about calls to the DerivationPathExists method.
@@ -42,8 +48,16 @@ type DerivationReaderMock struct {
 			// Args is the args argument value.
 			Args payd.DerivationExistsArgs
 		}
+		// DerivationPaths holds details about calls to the DerivationPaths method.
+		DerivationPaths []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.KeyArgs
+		}
 	}
 	lockDerivationPathExists sync.RWMutex
+	lockDerivationPaths      sync.RWMutex
 }
 
 // DerivationPathExists calls DerivationPathExistsFunc.
@@ -80,3 +94,38 @@ func (mock *DerivationReaderMock) DerivationPathExistsCalls() []struct {
 	mock.lockDerivationPathExists.RUnlock()
 	return calls
 }
+
+// DerivationPaths calls DerivationPathsFunc.
+func (mock *DerivationReaderMock) DerivationPaths(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+	if mock.DerivationPathsFunc == nil {
+		panic("DerivationReaderMock.DerivationPathsFunc: method is nil but DerivationReader.DerivationPaths was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.KeyArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockDerivationPaths.Lock()
+	mock.calls.DerivationPaths = append(mock.calls.DerivationPaths, callInfo)
+	mock.lockDerivationPaths.Unlock()
+	return mock.DerivationPathsFunc(ctx, args)
+}
+
+// DerivationPathsCalls gets all the calls that were made to DerivationPaths.
+// Check the length with:
+//     len(mockedDerivationReader.DerivationPathsCalls())
+func (mock *DerivationReaderMock) DerivationPathsCalls() []struct {
+	Ctx  context.Context
+	Args payd.KeyArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.KeyArgs
+	}
+	mock.lockDerivationPaths.RLock()
+	calls = mock.calls.DerivationPaths
+	mock.lockDerivationPaths.RUnlock()
+	return calls
+}
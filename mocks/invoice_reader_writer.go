@@ -29,12 +29,24 @@ var _ payd.InvoiceReaderWriter = &InvoiceReaderWriterMock{}
 // 			InvoiceDeleteFunc: func(ctx context.Context, args payd.InvoiceArgs) error {
 // 				panic("mock out the InvoiceDelete method")
 // 			},
+// 			InvoicePaymentsFunc: func(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoicePayment, error) {
+// 				panic("mock out the InvoicePayments method")
+// 			},
+// 			InvoiceRefundFunc: func(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error) {
+// 				panic("mock out the InvoiceRefund method")
+// 			},
+// 			InvoiceRefundsFunc: func(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoiceRefund, error) {
+// 				panic("mock out the InvoiceRefunds method")
+// 			},
 // 			InvoiceUpdateFunc: func(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
 // 				panic("mock out the InvoiceUpdate method")
 // 			},
-// 			InvoicesFunc: func(ctx context.Context) ([]payd.Invoice, error) {
+// 			InvoicesFunc: func(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
 // 				panic("mock out the Invoices method")
 // 			},
+// 			InvoicesExpireFunc: func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+// 				panic("mock out the InvoicesExpire method")
+// 			},
 // 			InvoicesPendingFunc: func(ctx context.Context) ([]payd.Invoice, error) {
 // 				panic("mock out the InvoicesPending method")
 // 			},
@@ -54,11 +66,23 @@ type InvoiceReaderWriterMock struct {
 	// InvoiceDeleteFunc mocks the InvoiceDelete method.
 	InvoiceDeleteFunc func(ctx context.Context, args payd.InvoiceArgs) error
 
+	// InvoicePaymentsFunc mocks the InvoicePayments method.
+	InvoicePaymentsFunc func(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoicePayment, error)
+
+	// InvoiceRefundFunc mocks the InvoiceRefund method.
+	InvoiceRefundFunc func(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error)
+
+	// InvoiceRefundsFunc mocks the InvoiceRefunds method.
+	InvoiceRefundsFunc func(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoiceRefund, error)
+
 	// InvoiceUpdateFunc mocks the InvoiceUpdate method.
 	InvoiceUpdateFunc func(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error)
 
 	// InvoicesFunc mocks the Invoices method.
-	InvoicesFunc func(ctx context.Context) ([]payd.Invoice, error)
+	InvoicesFunc func(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error)
+
+	// InvoicesExpireFunc mocks the InvoicesExpire method.
+	InvoicesExpireFunc func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error)
 
 	// InvoicesPendingFunc mocks the InvoicesPending method.
 	InvoicesPendingFunc func(ctx context.Context) ([]payd.Invoice, error)
@@ -86,6 +110,29 @@ type InvoiceReaderWriterMock struct {
 			// Args is the args argument value.
 			Args payd.InvoiceArgs
 		}
+		// InvoicePayments holds details about calls to the InvoicePayments method.
+		InvoicePayments []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.InvoiceArgs
+		}
+		// InvoiceRefund holds details about calls to the InvoiceRefund method.
+		InvoiceRefund []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.InvoiceUpdateArgs
+			// Req is the req argument value.
+			Req payd.InvoiceUpdateRefunded
+		}
+		// InvoiceRefunds holds details about calls to the InvoiceRefunds method.
+		InvoiceRefunds []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.InvoiceArgs
+		}
 		// InvoiceUpdate holds details about calls to the InvoiceUpdate method.
 		InvoiceUpdate []struct {
 			// Ctx is the ctx argument value.
@@ -99,6 +146,15 @@ type InvoiceReaderWriterMock struct {
 		Invoices []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.InvoiceSearchArgs
+		}
+		// InvoicesExpire holds details about calls to the InvoicesExpire method.
+		InvoicesExpire []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.InvoicesExpireArgs
 		}
 		// InvoicesPending holds details about calls to the InvoicesPending method.
 		InvoicesPending []struct {
@@ -109,8 +165,12 @@ type InvoiceReaderWriterMock struct {
 	lockInvoice         sync.RWMutex
 	lockInvoiceCreate   sync.RWMutex
 	lockInvoiceDelete   sync.RWMutex
+	lockInvoicePayments sync.RWMutex
+	lockInvoiceRefund   sync.RWMutex
+	lockInvoiceRefunds  sync.RWMutex
 	lockInvoiceUpdate   sync.RWMutex
 	lockInvoices        sync.RWMutex
+	lockInvoicesExpire  sync.RWMutex
 	lockInvoicesPending sync.RWMutex
 }
 
@@ -219,6 +279,115 @@ func (mock *InvoiceReaderWriterMock) InvoiceDeleteCalls() []struct {
 	return calls
 }
 
+// InvoicePayments calls InvoicePaymentsFunc.
+func (mock *InvoiceReaderWriterMock) InvoicePayments(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoicePayment, error) {
+	if mock.InvoicePaymentsFunc == nil {
+		panic("InvoiceReaderWriterMock.InvoicePaymentsFunc: method is nil but InvoiceReaderWriter.InvoicePayments was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.InvoiceArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockInvoicePayments.Lock()
+	mock.calls.InvoicePayments = append(mock.calls.InvoicePayments, callInfo)
+	mock.lockInvoicePayments.Unlock()
+	return mock.InvoicePaymentsFunc(ctx, args)
+}
+
+// InvoicePaymentsCalls gets all the calls that were made to InvoicePayments.
+// Check the length with:
+//     len(mockedInvoiceReaderWriter.InvoicePaymentsCalls())
+func (mock *InvoiceReaderWriterMock) InvoicePaymentsCalls() []struct {
+	Ctx  context.Context
+	Args payd.InvoiceArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.InvoiceArgs
+	}
+	mock.lockInvoicePayments.RLock()
+	calls = mock.calls.InvoicePayments
+	mock.lockInvoicePayments.RUnlock()
+	return calls
+}
+
+// InvoiceRefund calls InvoiceRefundFunc.
+func (mock *InvoiceReaderWriterMock) InvoiceRefund(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error) {
+	if mock.InvoiceRefundFunc == nil {
+		panic("InvoiceReaderWriterMock.InvoiceRefundFunc: method is nil but InvoiceReaderWriter.InvoiceRefund was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.InvoiceUpdateArgs
+		Req  payd.InvoiceUpdateRefunded
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockInvoiceRefund.Lock()
+	mock.calls.InvoiceRefund = append(mock.calls.InvoiceRefund, callInfo)
+	mock.lockInvoiceRefund.Unlock()
+	return mock.InvoiceRefundFunc(ctx, args, req)
+}
+
+// InvoiceRefundCalls gets all the calls that were made to InvoiceRefund.
+// Check the length with:
+//     len(mockedInvoiceReaderWriter.InvoiceRefundCalls())
+func (mock *InvoiceReaderWriterMock) InvoiceRefundCalls() []struct {
+	Ctx  context.Context
+	Args payd.InvoiceUpdateArgs
+	Req  payd.InvoiceUpdateRefunded
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.InvoiceUpdateArgs
+		Req  payd.InvoiceUpdateRefunded
+	}
+	mock.lockInvoiceRefund.RLock()
+	calls = mock.calls.InvoiceRefund
+	mock.lockInvoiceRefund.RUnlock()
+	return calls
+}
+
+// InvoiceRefunds calls InvoiceRefundsFunc.
+func (mock *InvoiceReaderWriterMock) InvoiceRefunds(ctx context.Context, args payd.InvoiceArgs) ([]payd.InvoiceRefund, error) {
+	if mock.InvoiceRefundsFunc == nil {
+		panic("InvoiceReaderWriterMock.InvoiceRefundsFunc: method is nil but InvoiceReaderWriter.InvoiceRefunds was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.InvoiceArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockInvoiceRefunds.Lock()
+	mock.calls.InvoiceRefunds = append(mock.calls.InvoiceRefunds, callInfo)
+	mock.lockInvoiceRefunds.Unlock()
+	return mock.InvoiceRefundsFunc(ctx, args)
+}
+
+// InvoiceRefundsCalls gets all the calls that were made to InvoiceRefunds.
+// Check the length with:
+//     len(mockedInvoiceReaderWriter.InvoiceRefundsCalls())
+func (mock *InvoiceReaderWriterMock) InvoiceRefundsCalls() []struct {
+	Ctx  context.Context
+	Args payd.InvoiceArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.InvoiceArgs
+	}
+	mock.lockInvoiceRefunds.RLock()
+	calls = mock.calls.InvoiceRefunds
+	mock.lockInvoiceRefunds.RUnlock()
+	return calls
+}
+
 // InvoiceUpdate calls InvoiceUpdateFunc.
 func (mock *InvoiceReaderWriterMock) InvoiceUpdate(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
 	if mock.InvoiceUpdateFunc == nil {
@@ -259,29 +428,33 @@ func (mock *InvoiceReaderWriterMock) InvoiceUpdateCalls() []struct {
 }
 
 // Invoices calls InvoicesFunc.
-func (mock *InvoiceReaderWriterMock) Invoices(ctx context.Context) ([]payd.Invoice, error) {
+func (mock *InvoiceReaderWriterMock) Invoices(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
 	if mock.InvoicesFunc == nil {
 		panic("InvoiceReaderWriterMock.InvoicesFunc: method is nil but InvoiceReaderWriter.Invoices was just called")
 	}
 	callInfo := struct {
-		Ctx context.Context
+		Ctx  context.Context
+		Args payd.InvoiceSearchArgs
 	}{
-		Ctx: ctx,
+		Ctx:  ctx,
+		Args: args,
 	}
 	mock.lockInvoices.Lock()
 	mock.calls.Invoices = append(mock.calls.Invoices, callInfo)
 	mock.lockInvoices.Unlock()
-	return mock.InvoicesFunc(ctx)
+	return mock.InvoicesFunc(ctx, args)
 }
 
 // InvoicesCalls gets all the calls that were made to Invoices.
 // Check the length with:
 //     len(mockedInvoiceReaderWriter.InvoicesCalls())
 func (mock *InvoiceReaderWriterMock) InvoicesCalls() []struct {
-	Ctx context.Context
+	Ctx  context.Context
+	Args payd.InvoiceSearchArgs
 } {
 	var calls []struct {
-		Ctx context.Context
+		Ctx  context.Context
+		Args payd.InvoiceSearchArgs
 	}
 	mock.lockInvoices.RLock()
 	calls = mock.calls.Invoices
@@ -289,6 +462,41 @@ func (mock *InvoiceReaderWriterMock) InvoicesCalls() []struct {
 	return calls
 }
 
+// InvoicesExpire calls InvoicesExpireFunc.
+func (mock *InvoiceReaderWriterMock) InvoicesExpire(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+	if mock.InvoicesExpireFunc == nil {
+		panic("InvoiceReaderWriterMock.InvoicesExpireFunc: method is nil but InvoiceReaderWriter.InvoicesExpire was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.InvoicesExpireArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockInvoicesExpire.Lock()
+	mock.calls.InvoicesExpire = append(mock.calls.InvoicesExpire, callInfo)
+	mock.lockInvoicesExpire.Unlock()
+	return mock.InvoicesExpireFunc(ctx, args)
+}
+
+// InvoicesExpireCalls gets all the calls that were made to InvoicesExpire.
+// Check the length with:
+//     len(mockedInvoiceReaderWriter.InvoicesExpireCalls())
+func (mock *InvoiceReaderWriterMock) InvoicesExpireCalls() []struct {
+	Ctx  context.Context
+	Args payd.InvoicesExpireArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.InvoicesExpireArgs
+	}
+	mock.lockInvoicesExpire.RLock()
+	calls = mock.calls.InvoicesExpire
+	mock.lockInvoicesExpire.RUnlock()
+	return calls
+}
+
 // InvoicesPending calls InvoicesPendingFunc.
 func (mock *InvoiceReaderWriterMock) InvoicesPending(ctx context.Context) ([]payd.Invoice, error) {
 	if mock.InvoicesPendingFunc == nil {
@@ -12,19 +12,36 @@ package mocks
 //go:generate moq -pkg mocks -out fee_quote_reader.go ../ FeeQuoteReader
 //go:generate moq -pkg mocks -out fee_quote_fetcher.go ../ FeeQuoteFetcher
 //go:generate moq -pkg mocks -out txo_writer.go ../ TxoWriter
+//go:generate moq -pkg mocks -out txo_reader_writer.go ../ TxoReaderWriter
 //go:generate moq -pkg mocks -out owner_store.go ../ OwnerStore
 //go:generate moq -pkg mocks -out proofs_writer.go ../ ProofsWriter
+//go:generate moq -pkg mocks -out proofs_reader.go ../ ProofsReader
+//go:generate moq -pkg mocks -out proofs_service.go ../ ProofsService
+//go:generate moq -pkg mocks -out transaction_reader.go ../ TransactionReader
 //go:generate moq -pkg mocks -out tx_writer.go ../ TransactionWriter
 //go:generate moq -pkg mocks -out broadcast_writer.go ../ BroadcastWriter
+//go:generate moq -pkg mocks -out broadcast_reader.go ../ BroadcastReader
+//go:generate moq -pkg mocks -out paymail_writer.go ../ PaymailWriter
 //go:generate moq -pkg mocks -out derivation_reader.go ../ DerivationReader
 //go:generate moq -pkg mocks -out peerchannels_store.go ../ PeerChannelsStore
 //go:generate moq -pkg mocks -out proof_callback_writer.go ../ ProofCallbackWriter
+//go:generate moq -pkg mocks -out proof_callback_reader_writer.go ../ ProofCallbackReaderWriter
+//go:generate moq -pkg mocks -out proof_callback_sender.go ../ ProofCallbackSender
 //go:generate moq -pkg mocks -out invoice_reader_writer.go ../ InvoiceReaderWriter
+//go:generate moq -pkg mocks -out invoice_expiry_notifier.go ../ InvoiceExpiryNotifier
 //go:generate moq -pkg mocks -out private_key_reader_writer.go ../ PrivateKeyReaderWriter
 //go:generate moq -pkg mocks -out destination_reader_writer.go ../ DestinationsReaderWriter
+//go:generate moq -pkg mocks -out balance_reader.go ../ BalanceReader
+//go:generate moq -pkg mocks -out user_store.go ../ UserStore
 //go:generate moq -pkg mocks -out dpp.go ../data/http DPP
+//go:generate moq -pkg mocks -out webhook_publisher.go ../ WebhookPublisher
+//go:generate moq -pkg mocks -out webhook_sender.go ../ WebhookSender
+//go:generate moq -pkg mocks -out webhook_reader_writer.go ../ WebhookReaderWriter
+//go:generate moq -pkg mocks -out chain_tip_reader.go ../ ChainTipReader
+//go:generate moq -pkg mocks -out header_chain.go ../ HeaderChain
 
 // third party
 
 //go:generate moq -pkg mocks -out payment_verifier.go ../vendor/github.com/libsv/go-bc/spv PaymentVerifier
 //go:generate moq -pkg mocks -out envelope_creator.go ../vendor/github.com/libsv/go-bc/spv EnvelopeCreator
+//go:generate moq -pkg mocks -out block_header_chain.go ../vendor/github.com/libsv/go-bc BlockHeaderChain
@@ -26,6 +26,12 @@ var _ payd.PrivateKeyReaderWriter = &PrivateKeyReaderWriterMock{}
 // 			PrivateKeyCreateFunc: func(ctx context.Context, req payd.PrivateKey) (*payd.PrivateKey, error) {
 // 				panic("mock out the PrivateKeyCreate method")
 // 			},
+// 			PrivateKeysFunc: func(ctx context.Context) ([]payd.PrivateKey, error) {
+// 				panic("mock out the PrivateKeys method")
+// 			},
+// 			PrivateKeysUpdateFunc: func(ctx context.Context, req []payd.PrivateKey) error {
+// 				panic("mock out the PrivateKeysUpdate method")
+// 			},
 // 		}
 //
 // 		// use mockedPrivateKeyReaderWriter in code that requires payd.PrivateKeyReaderWriter
@@ -39,6 +45,12 @@ type PrivateKeyReaderWriterMock struct {
 	// PrivateKeyCreateFunc mocks the PrivateKeyCreate method.
 	PrivateKeyCreateFunc func(ctx context.Context, req payd.PrivateKey) (*payd.PrivateKey, error)
 
+	// PrivateKeysFunc mocks the PrivateKeys method.
+	PrivateKeysFunc func(ctx context.Context) ([]payd.PrivateKey, error)
+
+	// PrivateKeysUpdateFunc mocks the PrivateKeysUpdate method.
+	PrivateKeysUpdateFunc func(ctx context.Context, req []payd.PrivateKey) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// PrivateKey holds details about calls to the PrivateKey method.
@@ -55,9 +67,23 @@ type PrivateKeyReaderWriterMock struct {
 			// Req is the req argument value.
 			Req payd.PrivateKey
 		}
+		// PrivateKeys holds details about calls to the PrivateKeys method.
+		PrivateKeys []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// PrivateKeysUpdate holds details about calls to the PrivateKeysUpdate method.
+		PrivateKeysUpdate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req []payd.PrivateKey
+		}
 	}
-	lockPrivateKey       sync.RWMutex
-	lockPrivateKeyCreate sync.RWMutex
+	lockPrivateKey        sync.RWMutex
+	lockPrivateKeyCreate  sync.RWMutex
+	lockPrivateKeys       sync.RWMutex
+	lockPrivateKeysUpdate sync.RWMutex
 }
 
 // PrivateKey calls PrivateKeyFunc.
@@ -129,3 +155,69 @@ func (mock *PrivateKeyReaderWriterMock) PrivateKeyCreateCalls() []struct {
 	mock.lockPrivateKeyCreate.RUnlock()
 	return calls
 }
+
+// PrivateKeys calls PrivateKeysFunc.
+func (mock *PrivateKeyReaderWriterMock) PrivateKeys(ctx context.Context) ([]payd.PrivateKey, error) {
+	if mock.PrivateKeysFunc == nil {
+		panic("PrivateKeyReaderWriterMock.PrivateKeysFunc: method is nil but PrivateKeyReaderWriter.PrivateKeys was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockPrivateKeys.Lock()
+	mock.calls.PrivateKeys = append(mock.calls.PrivateKeys, callInfo)
+	mock.lockPrivateKeys.Unlock()
+	return mock.PrivateKeysFunc(ctx)
+}
+
+// PrivateKeysCalls gets all the calls that were made to PrivateKeys.
+// Check the length with:
+//     len(mockedPrivateKeyReaderWriter.PrivateKeysCalls())
+func (mock *PrivateKeyReaderWriterMock) PrivateKeysCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockPrivateKeys.RLock()
+	calls = mock.calls.PrivateKeys
+	mock.lockPrivateKeys.RUnlock()
+	return calls
+}
+
+// PrivateKeysUpdate calls PrivateKeysUpdateFunc.
+func (mock *PrivateKeyReaderWriterMock) PrivateKeysUpdate(ctx context.Context, req []payd.PrivateKey) error {
+	if mock.PrivateKeysUpdateFunc == nil {
+		panic("PrivateKeyReaderWriterMock.PrivateKeysUpdateFunc: method is nil but PrivateKeyReaderWriter.PrivateKeysUpdate was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req []payd.PrivateKey
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockPrivateKeysUpdate.Lock()
+	mock.calls.PrivateKeysUpdate = append(mock.calls.PrivateKeysUpdate, callInfo)
+	mock.lockPrivateKeysUpdate.Unlock()
+	return mock.PrivateKeysUpdateFunc(ctx, req)
+}
+
+// PrivateKeysUpdateCalls gets all the calls that were made to PrivateKeysUpdate.
+// Check the length with:
+//     len(mockedPrivateKeyReaderWriter.PrivateKeysUpdateCalls())
+func (mock *PrivateKeyReaderWriterMock) PrivateKeysUpdateCalls() []struct {
+	Ctx context.Context
+	Req []payd.PrivateKey
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req []payd.PrivateKey
+	}
+	mock.lockPrivateKeysUpdate.RLock()
+	calls = mock.calls.PrivateKeysUpdate
+	mock.lockPrivateKeysUpdate.RUnlock()
+	return calls
+}
@@ -24,9 +24,18 @@ var _ payd.PrivateKeyService = &PrivateKeyServiceMock{}
 // 			CreateFunc: func(ctx context.Context, keyName string, userID uint64) error {
 // 				panic("mock out the Create method")
 // 			},
+// 			MnemonicRevealFunc: func(ctx context.Context, args payd.KeyArgs) (string, error) {
+// 				panic("mock out the MnemonicReveal method")
+// 			},
 // 			PrivateKeyFunc: func(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
 // 				panic("mock out the PrivateKey method")
 // 			},
+// 			PrivateKeyImportFunc: func(ctx context.Context, args payd.KeyArgs, xprv *bip32.ExtendedKey) error {
+// 				panic("mock out the PrivateKeyImport method")
+// 			},
+// 			PublicKeyFunc: func(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+// 				panic("mock out the PublicKey method")
+// 			},
 // 		}
 //
 // 		// use mockedPrivateKeyService in code that requires payd.PrivateKeyService
@@ -37,9 +46,18 @@ type PrivateKeyServiceMock struct {
 	// CreateFunc mocks the Create method.
 	CreateFunc func(ctx context.Context, keyName string, userID uint64) error
 
+	// MnemonicRevealFunc mocks the MnemonicReveal method.
+	MnemonicRevealFunc func(ctx context.Context, args payd.KeyArgs) (string, error)
+
 	// PrivateKeyFunc mocks the PrivateKey method.
 	PrivateKeyFunc func(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error)
 
+	// PrivateKeyImportFunc mocks the PrivateKeyImport method.
+	PrivateKeyImportFunc func(ctx context.Context, args payd.KeyArgs, xprv *bip32.ExtendedKey) error
+
+	// PublicKeyFunc mocks the PublicKey method.
+	PublicKeyFunc func(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// Create holds details about calls to the Create method.
@@ -51,6 +69,13 @@ type PrivateKeyServiceMock struct {
 			// UserID is the userID argument value.
 			UserID uint64
 		}
+		// MnemonicReveal holds details about calls to the MnemonicReveal method.
+		MnemonicReveal []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.KeyArgs
+		}
 		// PrivateKey holds details about calls to the PrivateKey method.
 		PrivateKey []struct {
 			// Ctx is the ctx argument value.
@@ -60,9 +85,30 @@ type PrivateKeyServiceMock struct {
 			// UserID is the userID argument value.
 			UserID uint64
 		}
+		// PrivateKeyImport holds details about calls to the PrivateKeyImport method.
+		PrivateKeyImport []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.KeyArgs
+			// Xprv is the xprv argument value.
+			Xprv *bip32.ExtendedKey
+		}
+		// PublicKey holds details about calls to the PublicKey method.
+		PublicKey []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// KeyName is the keyName argument value.
+			KeyName string
+			// UserID is the userID argument value.
+			UserID uint64
+		}
 	}
-	lockCreate     sync.RWMutex
-	lockPrivateKey sync.RWMutex
+	lockCreate           sync.RWMutex
+	lockMnemonicReveal   sync.RWMutex
+	lockPrivateKey       sync.RWMutex
+	lockPrivateKeyImport sync.RWMutex
+	lockPublicKey        sync.RWMutex
 }
 
 // Create calls CreateFunc.
@@ -104,6 +150,41 @@ func (mock *PrivateKeyServiceMock) CreateCalls() []struct {
 	return calls
 }
 
+// MnemonicReveal calls MnemonicRevealFunc.
+func (mock *PrivateKeyServiceMock) MnemonicReveal(ctx context.Context, args payd.KeyArgs) (string, error) {
+	if mock.MnemonicRevealFunc == nil {
+		panic("PrivateKeyServiceMock.MnemonicRevealFunc: method is nil but PrivateKeyService.MnemonicReveal was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.KeyArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockMnemonicReveal.Lock()
+	mock.calls.MnemonicReveal = append(mock.calls.MnemonicReveal, callInfo)
+	mock.lockMnemonicReveal.Unlock()
+	return mock.MnemonicRevealFunc(ctx, args)
+}
+
+// MnemonicRevealCalls gets all the calls that were made to MnemonicReveal.
+// Check the length with:
+//     len(mockedPrivateKeyService.MnemonicRevealCalls())
+func (mock *PrivateKeyServiceMock) MnemonicRevealCalls() []struct {
+	Ctx  context.Context
+	Args payd.KeyArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.KeyArgs
+	}
+	mock.lockMnemonicReveal.RLock()
+	calls = mock.calls.MnemonicReveal
+	mock.lockMnemonicReveal.RUnlock()
+	return calls
+}
+
 // PrivateKey calls PrivateKeyFunc.
 func (mock *PrivateKeyServiceMock) PrivateKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
 	if mock.PrivateKeyFunc == nil {
@@ -142,3 +223,81 @@ func (mock *PrivateKeyServiceMock) PrivateKeyCalls() []struct {
 	mock.lockPrivateKey.RUnlock()
 	return calls
 }
+
+// PrivateKeyImport calls PrivateKeyImportFunc.
+func (mock *PrivateKeyServiceMock) PrivateKeyImport(ctx context.Context, args payd.KeyArgs, xprv *bip32.ExtendedKey) error {
+	if mock.PrivateKeyImportFunc == nil {
+		panic("PrivateKeyServiceMock.PrivateKeyImportFunc: method is nil but PrivateKeyService.PrivateKeyImport was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.KeyArgs
+		Xprv *bip32.ExtendedKey
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Xprv: xprv,
+	}
+	mock.lockPrivateKeyImport.Lock()
+	mock.calls.PrivateKeyImport = append(mock.calls.PrivateKeyImport, callInfo)
+	mock.lockPrivateKeyImport.Unlock()
+	return mock.PrivateKeyImportFunc(ctx, args, xprv)
+}
+
+// PrivateKeyImportCalls gets all the calls that were made to PrivateKeyImport.
+// Check the length with:
+//     len(mockedPrivateKeyService.PrivateKeyImportCalls())
+func (mock *PrivateKeyServiceMock) PrivateKeyImportCalls() []struct {
+	Ctx  context.Context
+	Args payd.KeyArgs
+	Xprv *bip32.ExtendedKey
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.KeyArgs
+		Xprv *bip32.ExtendedKey
+	}
+	mock.lockPrivateKeyImport.RLock()
+	calls = mock.calls.PrivateKeyImport
+	mock.lockPrivateKeyImport.RUnlock()
+	return calls
+}
+
+// PublicKey calls PublicKeyFunc.
+func (mock *PrivateKeyServiceMock) PublicKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+	if mock.PublicKeyFunc == nil {
+		panic("PrivateKeyServiceMock.PublicKeyFunc: method is nil but PrivateKeyService.PublicKey was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		KeyName string
+		UserID  uint64
+	}{
+		Ctx:     ctx,
+		KeyName: keyName,
+		UserID:  userID,
+	}
+	mock.lockPublicKey.Lock()
+	mock.calls.PublicKey = append(mock.calls.PublicKey, callInfo)
+	mock.lockPublicKey.Unlock()
+	return mock.PublicKeyFunc(ctx, keyName, userID)
+}
+
+// PublicKeyCalls gets all the calls that were made to PublicKey.
+// Check the length with:
+//     len(mockedPrivateKeyService.PublicKeyCalls())
+func (mock *PrivateKeyServiceMock) PublicKeyCalls() []struct {
+	Ctx     context.Context
+	KeyName string
+	UserID  uint64
+} {
+	var calls []struct {
+		Ctx     context.Context
+		KeyName string
+		UserID  uint64
+	}
+	mock.lockPublicKey.RLock()
+	calls = mock.calls.PublicKey
+	mock.lockPublicKey.RUnlock()
+	return calls
+}
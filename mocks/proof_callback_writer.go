@@ -24,6 +24,12 @@ var _ payd.ProofCallbackWriter = &ProofCallbackWriterMock{}
 // 			ProofCallBacksCreateFunc: func(ctx context.Context, args payd.ProofCallbackArgs, callbacks map[string]dpp.ProofCallback) error {
 // 				panic("mock out the ProofCallBacksCreate method")
 // 			},
+// 			ProofCallbackUpdateFunc: func(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error {
+// 				panic("mock out the ProofCallbackUpdate method")
+// 			},
+// 			ProofCallbacksQueueFunc: func(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+// 				panic("mock out the ProofCallbacksQueue method")
+// 			},
 // 		}
 //
 // 		// use mockedProofCallbackWriter in code that requires payd.ProofCallbackWriter
@@ -34,6 +40,12 @@ type ProofCallbackWriterMock struct {
 	// ProofCallBacksCreateFunc mocks the ProofCallBacksCreate method.
 	ProofCallBacksCreateFunc func(ctx context.Context, args payd.ProofCallbackArgs, callbacks map[string]dpp.ProofCallback) error
 
+	// ProofCallbackUpdateFunc mocks the ProofCallbackUpdate method.
+	ProofCallbackUpdateFunc func(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error
+
+	// ProofCallbacksQueueFunc mocks the ProofCallbacksQueue method.
+	ProofCallbacksQueueFunc func(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// ProofCallBacksCreate holds details about calls to the ProofCallBacksCreate method.
@@ -45,8 +57,28 @@ type ProofCallbackWriterMock struct {
 			// Callbacks is the callbacks argument value.
 			Callbacks map[string]dpp.ProofCallback
 		}
+		// ProofCallbackUpdate holds details about calls to the ProofCallbackUpdate method.
+		ProofCallbackUpdate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.ProofCallbackSendArgs
+			// Req is the req argument value.
+			Req payd.ProofCallbackUpdate
+		}
+		// ProofCallbacksQueue holds details about calls to the ProofCallbacksQueue method.
+		ProofCallbacksQueue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.ProofCallbackArgs
+			// Req is the req argument value.
+			Req payd.ProofCallbacksQueue
+		}
 	}
 	lockProofCallBacksCreate sync.RWMutex
+	lockProofCallbackUpdate  sync.RWMutex
+	lockProofCallbacksQueue  sync.RWMutex
 }
 
 // ProofCallBacksCreate calls ProofCallBacksCreateFunc.
@@ -87,3 +119,81 @@ func (mock *ProofCallbackWriterMock) ProofCallBacksCreateCalls() []struct {
 	mock.lockProofCallBacksCreate.RUnlock()
 	return calls
 }
+
+// ProofCallbackUpdate calls ProofCallbackUpdateFunc.
+func (mock *ProofCallbackWriterMock) ProofCallbackUpdate(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error {
+	if mock.ProofCallbackUpdateFunc == nil {
+		panic("ProofCallbackWriterMock.ProofCallbackUpdateFunc: method is nil but ProofCallbackWriter.ProofCallbackUpdate was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.ProofCallbackSendArgs
+		Req  payd.ProofCallbackUpdate
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockProofCallbackUpdate.Lock()
+	mock.calls.ProofCallbackUpdate = append(mock.calls.ProofCallbackUpdate, callInfo)
+	mock.lockProofCallbackUpdate.Unlock()
+	return mock.ProofCallbackUpdateFunc(ctx, args, req)
+}
+
+// ProofCallbackUpdateCalls gets all the calls that were made to ProofCallbackUpdate.
+// Check the length with:
+//     len(mockedProofCallbackWriter.ProofCallbackUpdateCalls())
+func (mock *ProofCallbackWriterMock) ProofCallbackUpdateCalls() []struct {
+	Ctx  context.Context
+	Args payd.ProofCallbackSendArgs
+	Req  payd.ProofCallbackUpdate
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.ProofCallbackSendArgs
+		Req  payd.ProofCallbackUpdate
+	}
+	mock.lockProofCallbackUpdate.RLock()
+	calls = mock.calls.ProofCallbackUpdate
+	mock.lockProofCallbackUpdate.RUnlock()
+	return calls
+}
+
+// ProofCallbacksQueue calls ProofCallbacksQueueFunc.
+func (mock *ProofCallbackWriterMock) ProofCallbacksQueue(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+	if mock.ProofCallbacksQueueFunc == nil {
+		panic("ProofCallbackWriterMock.ProofCallbacksQueueFunc: method is nil but ProofCallbackWriter.ProofCallbacksQueue was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.ProofCallbackArgs
+		Req  payd.ProofCallbacksQueue
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockProofCallbacksQueue.Lock()
+	mock.calls.ProofCallbacksQueue = append(mock.calls.ProofCallbacksQueue, callInfo)
+	mock.lockProofCallbacksQueue.Unlock()
+	return mock.ProofCallbacksQueueFunc(ctx, args, req)
+}
+
+// ProofCallbacksQueueCalls gets all the calls that were made to ProofCallbacksQueue.
+// Check the length with:
+//     len(mockedProofCallbackWriter.ProofCallbacksQueueCalls())
+func (mock *ProofCallbackWriterMock) ProofCallbacksQueueCalls() []struct {
+	Ctx  context.Context
+	Args payd.ProofCallbackArgs
+	Req  payd.ProofCallbacksQueue
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.ProofCallbackArgs
+		Req  payd.ProofCallbacksQueue
+	}
+	mock.lockProofCallbacksQueue.RLock()
+	calls = mock.calls.ProofCallbacksQueue
+	mock.lockProofCallbacksQueue.RUnlock()
+	return calls
+}
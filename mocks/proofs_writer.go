@@ -24,6 +24,9 @@ var _ payd.ProofsWriter = &ProofsWriterMock{}
 // 			ProofCreateFunc: func(ctx context.Context, req dpp.ProofWrapper) error {
 // 				panic("mock out the ProofCreate method")
 // 			},
+// 			ProofsStaleFunc: func(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error) {
+// 				panic("mock out the ProofsStale method")
+// 			},
 // 		}
 //
 // 		// use mockedProofsWriter in code that requires payd.ProofsWriter
@@ -34,6 +37,9 @@ type ProofsWriterMock struct {
 	// ProofCreateFunc mocks the ProofCreate method.
 	ProofCreateFunc func(ctx context.Context, req dpp.ProofWrapper) error
 
+	// ProofsStaleFunc mocks the ProofsStale method.
+	ProofsStaleFunc func(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// ProofCreate holds details about calls to the ProofCreate method.
@@ -43,8 +49,16 @@ type ProofsWriterMock struct {
 			// Req is the req argument value.
 			Req dpp.ProofWrapper
 		}
+		// ProofsStale holds details about calls to the ProofsStale method.
+		ProofsStale []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.ProofsStaleArgs
+		}
 	}
 	lockProofCreate sync.RWMutex
+	lockProofsStale sync.RWMutex
 }
 
 // ProofCreate calls ProofCreateFunc.
@@ -81,3 +95,38 @@ func (mock *ProofsWriterMock) ProofCreateCalls() []struct {
 	mock.lockProofCreate.RUnlock()
 	return calls
 }
+
+// ProofsStale calls ProofsStaleFunc.
+func (mock *ProofsWriterMock) ProofsStale(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error) {
+	if mock.ProofsStaleFunc == nil {
+		panic("ProofsWriterMock.ProofsStaleFunc: method is nil but ProofsWriter.ProofsStale was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.ProofsStaleArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockProofsStale.Lock()
+	mock.calls.ProofsStale = append(mock.calls.ProofsStale, callInfo)
+	mock.lockProofsStale.Unlock()
+	return mock.ProofsStaleFunc(ctx, args)
+}
+
+// ProofsStaleCalls gets all the calls that were made to ProofsStale.
+// Check the length with:
+//     len(mockedProofsWriter.ProofsStaleCalls())
+func (mock *ProofsWriterMock) ProofsStaleCalls() []struct {
+	Ctx  context.Context
+	Args payd.ProofsStaleArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.ProofsStaleArgs
+	}
+	mock.lockProofsStale.RLock()
+	calls = mock.calls.ProofsStale
+	mock.lockProofsStale.RUnlock()
+	return calls
+}
@@ -26,6 +26,9 @@ var _ payd.TransactionWriter = &TransactionWriterMock{}
 // 			TransactionUpdateStateFunc: func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
 // 				panic("mock out the TransactionUpdateState method")
 // 			},
+// 			TransactionsConfirmFunc: func(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error) {
+// 				panic("mock out the TransactionsConfirm method")
+// 			},
 // 		}
 //
 // 		// use mockedTransactionWriter in code that requires payd.TransactionWriter
@@ -39,6 +42,9 @@ type TransactionWriterMock struct {
 	// TransactionUpdateStateFunc mocks the TransactionUpdateState method.
 	TransactionUpdateStateFunc func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error
 
+	// TransactionsConfirmFunc mocks the TransactionsConfirm method.
+	TransactionsConfirmFunc func(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
 		// TransactionCreate holds details about calls to the TransactionCreate method.
@@ -57,9 +63,17 @@ type TransactionWriterMock struct {
 			// Req is the req argument value.
 			Req payd.TransactionStateUpdate
 		}
+		// TransactionsConfirm holds details about calls to the TransactionsConfirm method.
+		TransactionsConfirm []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args payd.TransactionsConfirmArgs
+		}
 	}
 	lockTransactionCreate      sync.RWMutex
 	lockTransactionUpdateState sync.RWMutex
+	lockTransactionsConfirm    sync.RWMutex
 }
 
 // TransactionCreate calls TransactionCreateFunc.
@@ -135,3 +149,38 @@ func (mock *TransactionWriterMock) TransactionUpdateStateCalls() []struct {
 	mock.lockTransactionUpdateState.RUnlock()
 	return calls
 }
+
+// TransactionsConfirm calls TransactionsConfirmFunc.
+func (mock *TransactionWriterMock) TransactionsConfirm(ctx context.Context, args payd.TransactionsConfirmArgs) (int, error) {
+	if mock.TransactionsConfirmFunc == nil {
+		panic("TransactionWriterMock.TransactionsConfirmFunc: method is nil but TransactionWriter.TransactionsConfirm was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args payd.TransactionsConfirmArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockTransactionsConfirm.Lock()
+	mock.calls.TransactionsConfirm = append(mock.calls.TransactionsConfirm, callInfo)
+	mock.lockTransactionsConfirm.Unlock()
+	return mock.TransactionsConfirmFunc(ctx, args)
+}
+
+// TransactionsConfirmCalls gets all the calls that were made to TransactionsConfirm.
+// Check the length with:
+//     len(mockedTransactionWriter.TransactionsConfirmCalls())
+func (mock *TransactionWriterMock) TransactionsConfirmCalls() []struct {
+	Ctx  context.Context
+	Args payd.TransactionsConfirmArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args payd.TransactionsConfirmArgs
+	}
+	mock.lockTransactionsConfirm.RLock()
+	calls = mock.calls.TransactionsConfirm
+	mock.lockTransactionsConfirm.RUnlock()
+	return calls
+}
@@ -20,6 +20,9 @@ var _ payd.TxoWriter = &TxoWriterMock{}
 //
 // 		// make and configure a mocked payd.TxoWriter
 // 		mockedTxoWriter := &TxoWriterMock{
+// 			UTXOFrozenUpdateFunc: func(ctx context.Context, req payd.UTXOFrozenUpdate) error {
+// 				panic("mock out the UTXOFrozenUpdate method")
+// 			},
 // 			UTXOReserveFunc: func(ctx context.Context, req payd.UTXOReserve) ([]payd.UTXO, error) {
 // 				panic("mock out the UTXOReserve method")
 // 			},
@@ -36,6 +39,9 @@ var _ payd.TxoWriter = &TxoWriterMock{}
 //
 // 	}
 type TxoWriterMock struct {
+	// UTXOFrozenUpdateFunc mocks the UTXOFrozenUpdate method.
+	UTXOFrozenUpdateFunc func(ctx context.Context, req payd.UTXOFrozenUpdate) error
+
 	// UTXOReserveFunc mocks the UTXOReserve method.
 	UTXOReserveFunc func(ctx context.Context, req payd.UTXOReserve) ([]payd.UTXO, error)
 
@@ -47,6 +53,13 @@ type TxoWriterMock struct {
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// UTXOFrozenUpdate holds details about calls to the UTXOFrozenUpdate method.
+		UTXOFrozenUpdate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req payd.UTXOFrozenUpdate
+		}
 		// UTXOReserve holds details about calls to the UTXOReserve method.
 		UTXOReserve []struct {
 			// Ctx is the ctx argument value.
@@ -69,9 +82,45 @@ type TxoWriterMock struct {
 			Req payd.UTXOUnreserve
 		}
 	}
-	lockUTXOReserve   sync.RWMutex
-	lockUTXOSpend     sync.RWMutex
-	lockUTXOUnreserve sync.RWMutex
+	lockUTXOFrozenUpdate sync.RWMutex
+	lockUTXOReserve      sync.RWMutex
+	lockUTXOSpend        sync.RWMutex
+	lockUTXOUnreserve    sync.RWMutex
+}
+
+// UTXOFrozenUpdate calls UTXOFrozenUpdateFunc.
+func (mock *TxoWriterMock) UTXOFrozenUpdate(ctx context.Context, req payd.UTXOFrozenUpdate) error {
+	if mock.UTXOFrozenUpdateFunc == nil {
+		panic("TxoWriterMock.UTXOFrozenUpdateFunc: method is nil but TxoWriter.UTXOFrozenUpdate was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req payd.UTXOFrozenUpdate
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockUTXOFrozenUpdate.Lock()
+	mock.calls.UTXOFrozenUpdate = append(mock.calls.UTXOFrozenUpdate, callInfo)
+	mock.lockUTXOFrozenUpdate.Unlock()
+	return mock.UTXOFrozenUpdateFunc(ctx, req)
+}
+
+// UTXOFrozenUpdateCalls gets all the calls that were made to UTXOFrozenUpdate.
+// Check the length with:
+//     len(mockedTxoWriter.UTXOFrozenUpdateCalls())
+func (mock *TxoWriterMock) UTXOFrozenUpdateCalls() []struct {
+	Ctx context.Context
+	Req payd.UTXOFrozenUpdate
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req payd.UTXOFrozenUpdate
+	}
+	mock.lockUTXOFrozenUpdate.RLock()
+	calls = mock.calls.UTXOFrozenUpdate
+	mock.lockUTXOFrozenUpdate.RUnlock()
+	return calls
 }
 
 // UTXOReserve calls UTXOReserveFunc.
@@ -11,6 +11,9 @@ import (
 // PayRequest a request for making a payment.
 type PayRequest struct {
 	PayToURL string `json:"payToURL"`
+	// CoinSelection is the strategy used to choose the utxos funding the payment,
+	// if not supplied the wallet default is used.
+	CoinSelection CoinSelection `json:"coinSelection" enums:"largest,smallest,bnb,oldest,privacy"`
 }
 
 // Validate validates the request.
@@ -18,7 +21,9 @@ func (p PayRequest) Validate() error {
 	return validator.New().Validate("payToURL", func() error {
 		_, err := url.Parse(p.PayToURL)
 		return err
-	}).Err()
+	}).Validate("coinSelection", validator.AnyString(string(p.CoinSelection), "", string(CoinSelectionLargestFirst),
+		string(CoinSelectionSmallestFirst), string(CoinSelectionBranchAndBound), string(CoinSelectionOldestFirst),
+		string(CoinSelectionPrivacy))).Err()
 }
 
 // DPPOutput an output matching what a dpp server expects.
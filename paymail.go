@@ -6,7 +6,8 @@ import (
 	"github.com/libsv/go-bt/v2"
 )
 
-// P2PTransactionArgs is used to get a transaction.
+// P2PTransactionArgs is used to identify a transaction sent to a paymail, PaymentID is the
+// reference returned with the paymail's payment destination.
 type P2PTransactionArgs struct {
 	Alias     string
 	Domain    string
@@ -45,7 +46,14 @@ type P2POutputCreateArgs struct {
 
 // P2PPayment contains the amount of satoshis to send peer to peer.
 type P2PPayment struct {
-	Satoshis uint64
+	Satoshis uint64 `json:"satoshis"`
+}
+
+// P2PPaymentDestination is the outputs a paymail wants to be paid to, the reference identifies
+// the payment when its transaction is sent to the paymail.
+type P2PPaymentDestination struct {
+	Outputs   []*bt.Output
+	Reference string
 }
 
 // PaymailReader reads paymail information from a datastore.
@@ -55,7 +63,9 @@ type PaymailReader interface {
 
 // PaymailWriter writes to a paymail datastore.
 type PaymailWriter interface {
-	OutputsCreate(ctx context.Context, args P2POutputCreateArgs, req P2PPayment) ([]*bt.Output, error)
+	OutputsCreate(ctx context.Context, args P2POutputCreateArgs, req P2PPayment) (*P2PPaymentDestination, error)
+	// TransactionSubmit sends a transaction paying the outputs of a payment destination to the paymail.
+	TransactionSubmit(ctx context.Context, args P2PTransactionArgs, req P2PTransaction) error
 }
 
 // PaymailReaderWriter combines the reader and writer interfaces.
@@ -82,12 +82,6 @@ type PaymentCreateArgs struct {
 	InvoiceID string
 }
 
-// ProofCallback contains information relating to a merkleproof callback.
-type ProofCallback struct {
-	// Token to use for authentication when sending the proof to the destination. Optional.
-	Token string
-}
-
 // AckArgs are used to identify a payment we are acknowledging.
 type AckArgs struct {
 	InvoiceID   string
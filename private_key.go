@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/libsv/go-bk/bip32"
+	"gopkg.in/guregu/null.v3"
 )
 
 // PrivateKey describes a named private key.
@@ -12,8 +13,12 @@ type PrivateKey struct {
 	UserID uint64 `db:"user_id"`
 	// Name of the private key.
 	Name string `db:"name"`
-	// Xprv is the private key.
+	// Xprv is the private key, once the wallet is encrypted this is the encrypted key.
+	// Watch-only keys store an xpub, these are never encrypted.
 	Xprv string `db:"xprv"`
+	// Mnemonic is the BIP39 mnemonic the key was created from, it is removed once revealed
+	// and is encrypted along with the key.
+	Mnemonic null.String `db:"mnemonic"`
 	// CreatedAt is the date/time when the key was stored.
 	CreatedAt time.Time `db:"createdAt"`
 }
@@ -30,20 +35,33 @@ type KeyArgs struct {
 type PrivateKeyService interface {
 	// Create will create a new private key if it doesn't exist already.
 	Create(ctx context.Context, keyName string, userID uint64) error
-	// PrivateKey will return a private key.
+	// PrivateKey will return a private key, if the wallet is encrypted it must be unlocked.
+	// Watch-only keys have no private key so return an error.
 	PrivateKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error)
+	// PublicKey will return the extended public key of a private key, this doesn't need the wallet unlocked.
+	PublicKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error)
+	// PrivateKeyImport will store key as the named key, replacing any existing key.
+	// If key is an xpub the key is watch-only, it can derive destinations but can't sign.
+	PrivateKeyImport(ctx context.Context, args KeyArgs, key *bip32.ExtendedKey) error
+	// MnemonicReveal will return the mnemonic a key was created from, it can only be revealed once.
+	MnemonicReveal(ctx context.Context, args KeyArgs) (string, error)
 }
 
 // PrivateKeyReader reads private info from a data store.
 type PrivateKeyReader interface {
 	// PrivateKey can be used to return an existing private key.
 	PrivateKey(ctx context.Context, args KeyArgs) (*PrivateKey, error)
+	// PrivateKeys returns every private key, ordered by user then name.
+	PrivateKeys(ctx context.Context) ([]PrivateKey, error)
 }
 
 // PrivateKeyWriter will add private key to the datastore.
 type PrivateKeyWriter interface {
 	// PrivateKeyCreate will add a new private key to the data store.
 	PrivateKeyCreate(ctx context.Context, req PrivateKey) (*PrivateKey, error)
+	// PrivateKeysUpdate will replace the Xprv and Mnemonic of each key, matched by user and name,
+	// if any aren't found none are updated.
+	PrivateKeysUpdate(ctx context.Context, req []PrivateKey) error
 }
 
 // PrivateKeyReaderWriter describes a data store that can be implemented to get and store private keys.
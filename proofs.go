@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/libsv/go-bc"
 	"github.com/libsv/go-bk/envelope"
@@ -12,6 +13,7 @@ import (
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
 )
 
 var reTargetType = regexp.MustCompile(`^(header|hash|merkleRoot)$`)
@@ -81,9 +83,68 @@ func (p ProofWrapper) Validate(args ProofCreateArgs) error {
 	return vl.Err()
 }
 
-// ProofCallbackArgs are used to identify proofs for an invoice.
+// defines states a proof callback can have.
+const (
+	StateProofCallbackPending ProofCallbackState = "pending"
+	StateProofCallbackSent    ProofCallbackState = "sent"
+	StateProofCallbackFailed  ProofCallbackState = "failed"
+)
+
+// ProofCallbackState is the state of a proof callback, it is pending until the proof
+// is sent or sending it runs out of attempts.
+type ProofCallbackState string
+
+// ProofCallbackArgs are used to identify the proof callbacks supplied with a payment.
 type ProofCallbackArgs struct {
 	InvoiceID string `db:"invoice_id"`
+	TxID      string `db:"tx_id"`
+}
+
+// ProofCallbackSendArgs identify a single proof callback.
+type ProofCallbackSendArgs struct {
+	TxID string `db:"tx_id"`
+	URL  string `db:"url"`
+}
+
+// ProofCallback contains information relating to a merkleproof callback, a url the payer
+// asked for the merkle proof of their payment to be sent to.
+type ProofCallback struct {
+	InvoiceID string `db:"invoice_id"`
+	TxID      string `db:"tx_id"`
+	URL       string `db:"url"`
+	// Token to use for authentication when sending the proof to the destination. Optional.
+	Token    string             `db:"token"`
+	State    ProofCallbackState `db:"state"`
+	Attempts int                `db:"attempts"`
+	// Proof is the merkle proof envelope sent to the callback, it is empty until the proof is received.
+	Proof []byte `db:"proof"`
+	// NextAttemptAt is when the proof is next sent, it is null until the proof is received.
+	NextAttemptAt null.Time   `db:"next_attempt_at"`
+	LastError     null.String `db:"last_error"`
+	CreatedAt     time.Time   `db:"created_at"`
+	UpdatedAt     time.Time   `db:"updated_at"`
+}
+
+// ProofCallbacksQueue sets the proof to send to each callback for a transaction.
+type ProofCallbacksQueue struct {
+	Proof         []byte    `db:"proof"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+// ProofCallbacksDueArgs are used to get the proof callbacks due to be sent.
+type ProofCallbacksDueArgs struct {
+	DueBefore time.Time
+	Limit     int
+}
+
+// ProofCallbackUpdate records the outcome of an attempt to send a proof.
+type ProofCallbackUpdate struct {
+	State         ProofCallbackState `db:"state"`
+	Attempts      int                `db:"attempts"`
+	NextAttemptAt null.Time          `db:"next_attempt_at"`
+	LastError     null.String        `db:"last_error"`
+	UpdatedAt     time.Time          `db:"updated_at"`
 }
 
 // ProofsService enforces business rules and validation when handling merkle proofs.
@@ -94,14 +155,78 @@ type ProofsService interface {
 	Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error
 }
 
+// ProofBlock is a block that stored merkle proofs prove txs are in.
+type ProofBlock struct {
+	BlockHash   string `db:"blockhash"`
+	BlockHeight uint32 `db:"block_height"`
+}
+
+// ProofBlocksArgs are used to get the blocks of stored proofs.
+type ProofBlocksArgs struct {
+	// MinBlockHeight, only blocks at or above this height are returned.
+	MinBlockHeight uint32
+}
+
+// ProofsStaleArgs identify the proofs of a block that is no longer on the best chain.
+type ProofsStaleArgs struct {
+	BlockHash string
+	StaleAt   time.Time
+}
+
+// ProofsReader is used to read stored proofs.
+type ProofsReader interface {
+	// ProofBlocks returns the blocks of the proofs of mined and confirmed txs, highest first.
+	ProofBlocks(ctx context.Context, args ProofBlocksArgs) ([]ProofBlock, error)
+}
+
 // ProofsWriter is used to persist a proof to a data store.
 type ProofsWriter interface {
 	// ProofCreate can be used to persist a merkle proof in TSC format.
 	ProofCreate(ctx context.Context, req dpp.ProofWrapper) error
+	// ProofsStale marks the proofs of a block stale, they are no longer returned as a merkle proof
+	// of their tx. The txIDs of the proofs marked are returned.
+	ProofsStale(ctx context.Context, args ProofsStaleArgs) ([]string, error)
+}
+
+// ReorgService watches the best chain for reorganisations that orphan the blocks of stored proofs.
+type ReorgService interface {
+	// ProofsReorg will mark the proofs in orphaned blocks stale and revert their txs to broadcast,
+	// returning the number of txs reverted.
+	ProofsReorg(ctx context.Context) (int, error)
+}
+
+// ProofCallbackService sends received merkle proofs to the callbacks supplied with payments.
+type ProofCallbackService interface {
+	// ProofCallbacksSend will send each proof callback that is due, returning the number attempted.
+	ProofCallbacksSend(ctx context.Context) (int, error)
+}
+
+// ProofCallbackSender posts a merkle proof envelope to a proof callback url.
+type ProofCallbackSender interface {
+	// ProofCallbackSend will post the proof to the callback, authorised with its token.
+	ProofCallbackSend(ctx context.Context, callback ProofCallback) error
+}
+
+// ProofCallbackReaderWriter combines the reader and writer interfaces.
+type ProofCallbackReaderWriter interface {
+	ProofCallbackReader
+	ProofCallbackWriter
+}
+
+// ProofCallbackReader reads proof callbacks from a data store.
+type ProofCallbackReader interface {
+	// ProofCallbacks returns the callbacks for an invoice, optionally only those for a single transaction.
+	ProofCallbacks(ctx context.Context, args ProofCallbackArgs) ([]ProofCallback, error)
+	// ProofCallbacksDue returns the pending callbacks with a proof that are due to be sent, oldest first.
+	ProofCallbacksDue(ctx context.Context, args ProofCallbacksDueArgs) ([]ProofCallback, error)
 }
 
 // ProofCallbackWriter can be implemented to support writing proof callbacks.
 type ProofCallbackWriter interface {
-	// ProofCallBacksCreate can be implemented to store merkle proof callback urls for an invoice.
+	// ProofCallBacksCreate can be implemented to store merkle proof callback urls for a payment.
 	ProofCallBacksCreate(ctx context.Context, args ProofCallbackArgs, callbacks map[string]dpp.ProofCallback) error
+	// ProofCallbacksQueue will store the proof for each callback of a transaction and queue them to be sent.
+	ProofCallbacksQueue(ctx context.Context, args ProofCallbackArgs, req ProofCallbacksQueue) error
+	// ProofCallbackUpdate will record the outcome of sending a proof callback.
+	ProofCallbackUpdate(ctx context.Context, args ProofCallbackSendArgs, req ProofCallbackUpdate) error
 }
@@ -0,0 +1,20 @@
+package payd
+
+import (
+	"context"
+)
+
+// RefundCreate is used to refund a customer some or all of what they have paid
+// against an invoice.
+type RefundCreate struct {
+	// Satoshis is the amount to refund, if not set everything received
+	// against the invoice, less any previous refunds, is refunded.
+	Satoshis uint64 `json:"satoshis" example:"1000"`
+}
+
+// RefundService is used to pay refunds back to customers.
+type RefundService interface {
+	// Refund will pay a refund to the RefundTo paymail or locking script captured with the
+	// payment, the refund transaction is broadcast and recorded against the invoice.
+	Refund(ctx context.Context, args InvoiceArgs, req RefundCreate) (*InvoiceRefund, error)
+}
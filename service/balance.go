@@ -6,22 +6,35 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
 )
 
 type balance struct {
-	store payd.BalanceReader
+	store            payd.BalanceReader
+	tipRdr           payd.ChainTipReader
+	minConfirmations uint32
 }
 
 // NewBalance will setup and return the current balance of the wallet.
-func NewBalance(store payd.BalanceReader) *balance {
-	return &balance{store: store}
+func NewBalance(store payd.BalanceReader, tipRdr payd.ChainTipReader, cfg *config.Wallet) *balance {
+	return &balance{store: store, tipRdr: tipRdr, minConfirmations: cfg.MinConfirmations}
 }
 
-// Balance will return the current wallet balance.
-func (b *balance) Balance(ctx context.Context) (*payd.Balance, error) {
-	resp, err := b.store.Balance(ctx)
+// Balance will return the current wallet balance, scoped to a user or key when
+// these are supplied, with the confirmations of each total counted from the chain tip.
+func (b *balance) Balance(ctx context.Context, args payd.BalanceArgs) (*payd.Balance, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	resp, err := b.store.Balance(ctx, args)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to get balance")
 	}
+	resp.RequireConfirmations(b.minConfirmations)
+	tip, err := b.tipRdr.ChainTip(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get chain tip for balance confirmations")
+	}
+	resp.Confirmations(*tip)
 	return resp, nil
 }
@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestBalanceService_Balance(t *testing.T) {
+	tests := map[string]struct {
+		balanceFunc func(context.Context, payd.BalanceArgs) (*payd.Balance, error)
+		tipFunc     func(context.Context) (*payd.ChainTip, error)
+		args        payd.BalanceArgs
+		expBalance  *payd.Balance
+		expErr      error
+	}{
+		"confirmations are counted from the tip": {
+			balanceFunc: func(context.Context, payd.BalanceArgs) (*payd.Balance, error) {
+				var b payd.Balance
+				b.Add(payd.BalanceTotal{Satoshis: 500, UTXOs: 1, LowBlockHeight: null.IntFrom(100), HighBlockHeight: null.IntFrom(100)},
+					payd.StateTxConfirmed, false, false)
+				b.Add(payd.BalanceTotal{Satoshis: 600, UTXOs: 2, LowBlockHeight: null.IntFrom(101), HighBlockHeight: null.IntFrom(104)},
+					payd.StateTxConfirmed, false, false)
+				b.Add(payd.BalanceTotal{Satoshis: 300, UTXOs: 1}, payd.StateTxBroadcast, false, false)
+				return &b, nil
+			},
+			tipFunc: func(context.Context) (*payd.ChainTip, error) {
+				return &payd.ChainTip{Height: 105}, nil
+			},
+			expBalance: &payd.Balance{
+				Satoshis:  1400,
+				UTXOs:     4,
+				Spendable: 1400,
+				TipHeight: 105,
+				Confirmed: payd.BalanceTotal{
+					Satoshis:         1100,
+					UTXOs:            3,
+					MinConfirmations: 2,
+					MaxConfirmations: 6,
+					LowBlockHeight:   null.IntFrom(100),
+					HighBlockHeight:  null.IntFrom(104),
+				},
+				Unconfirmed: payd.BalanceTotal{Satoshis: 300, UTXOs: 1},
+			},
+		},
+		"chain tip error is reported": {
+			balanceFunc: func(context.Context, payd.BalanceArgs) (*payd.Balance, error) {
+				return &payd.Balance{}, nil
+			},
+			tipFunc: func(context.Context) (*payd.ChainTip, error) {
+				return nil, errors.New("whoopsie")
+			},
+			expErr: errors.New("failed to get chain tip for balance confirmations: whoopsie"),
+		},
+		"store error is reported": {
+			balanceFunc: func(context.Context, payd.BalanceArgs) (*payd.Balance, error) {
+				return nil, errors.New("whoopsie")
+			},
+			expErr: errors.New("failed to get balance: whoopsie"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewBalance(&mocks.BalanceReaderMock{
+				BalanceFunc: test.balanceFunc,
+			}, &mocks.ChainTipReaderMock{
+				ChainTipFunc: test.tipFunc,
+			}, &config.Wallet{})
+			bal, err := svc.Balance(context.TODO(), test.args)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expBalance, bal)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+)
+
+type broadcastStatus struct {
+	l           log.Logger
+	txRdr       payd.TransactionReader
+	txWtr       payd.TransactionWriter
+	statusRdr   payd.BroadcastReader
+	broadcaster payd.BroadcastWriter
+	proofSvc    payd.ProofsService
+	webhooks    payd.WebhookPublisher
+	transacter  payd.Transacter
+	timeSvc     payd.TimestampService
+	grace       time.Duration
+}
+
+// NewBroadcastStatus will setup and return a new broadcast status service, used to find broadcast
+// txs whose merkle proof never arrived at the callback.
+func NewBroadcastStatus(l log.Logger, cfg *config.Wallet, txRdr payd.TransactionReader, txWtr payd.TransactionWriter,
+	statusRdr payd.BroadcastReader, broadcaster payd.BroadcastWriter, proofSvc payd.ProofsService,
+	webhooks payd.WebhookPublisher, transacter payd.Transacter, timeSvc payd.TimestampService) *broadcastStatus {
+	return &broadcastStatus{
+		l:           l,
+		txRdr:       txRdr,
+		txWtr:       txWtr,
+		statusRdr:   statusRdr,
+		broadcaster: broadcaster,
+		proofSvc:    proofSvc,
+		webhooks:    webhooks,
+		transacter:  transacter,
+		timeSvc:     timeSvc,
+		grace:       cfg.PollGrace,
+	}
+}
+
+// BroadcastsPoll will query mapi for the status of the broadcast txs created more than PollGrace ago,
+// oldest first. Mined txs have their proof stored, txs unknown to the miner are broadcast again and
+// those rejected as double spends are failed.
+//
+// Txs are polled oldest first so the parent of a dropped tx is broadcast again before the tx itself.
+func (b *broadcastStatus) BroadcastsPoll(ctx context.Context) (int, error) {
+	txs, err := b.txRdr.Transactions(ctx, payd.TransactionSearchArgs{
+		State:     payd.StateTxBroadcast,
+		CreatedTo: null.TimeFrom(b.timeSvc.NowUTC().Add(-b.grace)),
+		Sort:      payd.SortAsc,
+	})
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get broadcast transactions")
+	}
+	var n int
+	for _, tx := range txs {
+		status, err := b.statusRdr.BroadcastStatus(ctx, tx.TxID)
+		if err != nil {
+			return n, errors.WithMessagef(err, "failed to query status of tx %s", tx.TxID)
+		}
+		switch {
+		case status.Proof != nil && status.BlockHash != "":
+			// the proof is verified when stored, a failure is retried next poll.
+			if err := storeStatusProof(ctx, b.proofSvc, status); err != nil {
+				b.l.Errorf(err, "failed to store proof of tx %s", tx.TxID)
+				continue
+			}
+		case !status.Known:
+			ok, err := b.rebroadcast(ctx, tx)
+			if err != nil {
+				return n, errors.WithMessagef(err, "failed to rebroadcast tx %s", tx.TxID)
+			}
+			if !ok {
+				continue
+			}
+		default:
+			b.l.Debugf("tx %s not yet mined: %s", tx.TxID, status.Description)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// rebroadcast submits a tx to mapi again, failing it if it is rejected as a double spend. Any
+// other rejection is only logged as the tx is broadcast again next poll. No callback is sent with
+// the tx, its proof is stored by a later poll once it is mined.
+func (b *broadcastStatus) rebroadcast(ctx context.Context, tx payd.TransactionHistory) (bool, error) {
+	t, err := bt.NewTxFromString(tx.TxHex)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to parse tx")
+	}
+	bErr := b.broadcaster.Broadcast(ctx, payd.BroadcastArgs{InvoiceID: tx.InvoiceID.String}, t)
+	if bErr == nil {
+		b.l.Infof("tx %s unknown to the miner was broadcast again", tx.TxID)
+		return true, nil
+	}
+	if !errors.Is(bErr, payd.ErrTxDoubleSpent) {
+		b.l.Errorf(bErr, "failed to broadcast tx %s again", tx.TxID)
+		return false, nil
+	}
+	ctx = b.transacter.WithTx(ctx)
+	defer func() {
+		_ = b.transacter.Rollback(ctx)
+	}()
+	if err := b.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: tx.TxID}, payd.TransactionStateUpdate{
+		State:      payd.StateTxFailed,
+		FailReason: null.StringFrom(bErr.Error()),
+	}); err != nil {
+		return false, errors.WithMessagef(err, "failed to set tx %s", payd.StateTxFailed)
+	}
+	if err := b.webhooks.Publish(ctx, payd.WebhookEventTxFailed, payd.WebhookTxEvent{
+		TxID:       tx.TxID,
+		InvoiceID:  tx.InvoiceID.String,
+		FailReason: bErr.Error(),
+	}); err != nil {
+		return false, errors.WithMessage(err, "failed to publish tx failed event")
+	}
+	b.l.Warnf("tx %s was double spent: %s", tx.TxID, bErr)
+	return true, errors.Wrap(b.transacter.Commit(ctx), "failed to commit failed tx")
+}
+
+// storeStatusProof stores the merkle proof returned with the status of a mined tx. The proof isn't
+// signed by the miner, it is verified against the block headers when stored.
+func storeStatusProof(ctx context.Context, proofSvc payd.ProofsService, status *payd.BroadcastStatus) error {
+	bb, err := json.Marshal(dpp.ProofWrapper{
+		CallbackPayload: status.Proof,
+		BlockHash:       status.BlockHash,
+		BlockHeight:     status.BlockHeight,
+		CallbackTxID:    status.TxID,
+		CallbackReason:  "merkleProof",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode proof")
+	}
+	return proofSvc.Create(ctx, dpp.ProofCreateArgs{TxID: status.TxID}, envelope.JSONEnvelope{
+		Payload:  string(bb),
+		Encoding: "UTF-8",
+		MimeType: "application/json",
+	})
+}
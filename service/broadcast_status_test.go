@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestBroadcastStatusService_BroadcastsPoll(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	tx := bt.NewTx()
+	require.NoError(t, tx.PayToAddress("mtdruWYVEV1wz5yL7GvpBj4MgifCB7yhPd", 1000))
+	txs := []payd.TransactionHistory{
+		{TxID: "mined", TxHex: tx.String()},
+		{TxID: "mempool", TxHex: tx.String()},
+		{TxID: "dropped", TxHex: tx.String(), InvoiceID: null.StringFrom("inv1")},
+	}
+	proof := &bc.MerkleProof{Index: 1, TxOrID: "mined", Target: "block", Nodes: []string{"abc"}}
+	doubleSpend := errors.Wrap(payd.ErrTxDoubleSpent, "failed to submit transaction txn-mempool-conflict")
+	tests := map[string]struct {
+		txsErr       error
+		statusErr    error
+		createErr    error
+		broadcastErr error
+		updateErr    error
+		expProofs    []string
+		expBroadcast []string
+		expUpdate    *payd.TransactionStateUpdate
+		expEvent     *payd.WebhookTxEvent
+		expN         int
+		expErr       error
+	}{
+		"mined txs get proofs and dropped txs are broadcast again": {
+			expProofs:    []string{"mined"},
+			expBroadcast: []string{"inv1"},
+			expN:         2,
+		},
+		"double spent txs are failed": {
+			broadcastErr: doubleSpend,
+			expProofs:    []string{"mined"},
+			expBroadcast: []string{"inv1"},
+			expUpdate: &payd.TransactionStateUpdate{
+				State:      payd.StateTxFailed,
+				FailReason: null.StringFrom(doubleSpend.Error()),
+			},
+			expEvent: &payd.WebhookTxEvent{TxID: "dropped", InvoiceID: "inv1", FailReason: doubleSpend.Error()},
+			expN:     2,
+		},
+		"rejected rebroadcasts are only logged": {
+			broadcastErr: errors.New("failed to submit transaction Missing inputs"),
+			expProofs:    []string{"mined"},
+			expBroadcast: []string{"inv1"},
+			expN:         1,
+		},
+		"proofs that fail to be stored are only logged": {
+			createErr:    errors.New("invalid proof"),
+			expProofs:    []string{"mined"},
+			expBroadcast: []string{"inv1"},
+			expN:         1,
+		},
+		"transactions error is reported": {
+			txsErr: errors.New("whoopsie"),
+			expErr: errors.New("failed to get broadcast transactions: whoopsie"),
+		},
+		"status error is reported": {
+			statusErr: errors.New("mapi is down"),
+			expErr:    errors.New("failed to query status of tx mined: mapi is down"),
+		},
+		"fail error is reported": {
+			broadcastErr: doubleSpend,
+			updateErr:    errors.New("whoopsie"),
+			expProofs:    []string{"mined"},
+			expBroadcast: []string{"inv1"},
+			expUpdate: &payd.TransactionStateUpdate{
+				State:      payd.StateTxFailed,
+				FailReason: null.StringFrom(doubleSpend.Error()),
+			},
+			expN:   1,
+			expErr: errors.New("failed to rebroadcast tx dropped: failed to set tx failed: whoopsie"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var proofs, broadcast []string
+			var update *payd.TransactionStateUpdate
+			var event *payd.WebhookTxEvent
+			svc := service.NewBroadcastStatus(log.Noop{}, &config.Wallet{PollGrace: time.Hour}, &mocks.TransactionReaderMock{
+				TransactionsFunc: func(ctx context.Context, args payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+					assert.Equal(t, payd.TransactionSearchArgs{
+						State:     payd.StateTxBroadcast,
+						CreatedTo: null.TimeFrom(now.Add(-time.Hour)),
+						Sort:      payd.SortAsc,
+					}, args)
+					return txs, test.txsErr
+				},
+			}, &mocks.TransactionWriterMock{
+				TransactionUpdateStateFunc: func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+					assert.Equal(t, "dropped", args.TxID)
+					update = &req
+					return test.updateErr
+				},
+			}, &mocks.BroadcastReaderMock{
+				BroadcastStatusFunc: func(ctx context.Context, txID string) (*payd.BroadcastStatus, error) {
+					if test.statusErr != nil {
+						return nil, test.statusErr
+					}
+					switch txID {
+					case "mined":
+						return &payd.BroadcastStatus{TxID: txID, Known: true, BlockHash: "block", BlockHeight: 100, Proof: proof}, nil
+					case "mempool":
+						return &payd.BroadcastStatus{TxID: txID, Known: true, Description: "Transaction in mempool but not yet in block"}, nil
+					}
+					return &payd.BroadcastStatus{TxID: txID, Description: "No such mempool or blockchain transaction"}, nil
+				},
+			}, &mocks.BroadcastWriterMock{
+				BroadcastFunc: func(ctx context.Context, args payd.BroadcastArgs, t *bt.Tx) error {
+					broadcast = append(broadcast, args.InvoiceID)
+					return test.broadcastErr
+				},
+			}, &mocks.ProofsServiceMock{
+				CreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+					proofs = append(proofs, args.TxID)
+					return test.createErr
+				},
+			}, &mocks.WebhookPublisherMock{
+				PublishFunc: func(ctx context.Context, e payd.WebhookEvent, data interface{}) error {
+					assert.Equal(t, payd.WebhookEventTxFailed, e)
+					ev := data.(payd.WebhookTxEvent)
+					event = &ev
+					return nil
+				},
+			}, &mocks.TransacterMock{
+				WithTxFunc: func(ctx context.Context) context.Context {
+					return ctx
+				},
+				RollbackFunc: func(context.Context) error {
+					return nil
+				},
+				CommitFunc: func(context.Context) error {
+					return nil
+				},
+			}, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+			n, err := svc.BroadcastsPoll(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expN, n)
+			assert.Equal(t, test.expProofs, proofs)
+			assert.Equal(t, test.expBroadcast, broadcast)
+			assert.Equal(t, test.expUpdate, update)
+			assert.Equal(t, test.expEvent, event)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/libsv/payd"
+)
+
+// bnbMaxTries is the most selections branch and bound will try before giving up on a match.
+const bnbMaxTries = 100000
+
+// NewCoinSelector returns the coin selector for a strategy.
+func NewCoinSelector(strategy payd.CoinSelection) (payd.CoinSelector, error) {
+	switch strategy {
+	case payd.CoinSelectionLargestFirst:
+		return largestFirst, nil
+	case payd.CoinSelectionSmallestFirst:
+		return smallestFirst, nil
+	case payd.CoinSelectionOldestFirst:
+		return oldestFirst, nil
+	case payd.CoinSelectionBranchAndBound:
+		return branchAndBound{}, nil
+	case payd.CoinSelectionPrivacy:
+		return privacy{}, nil
+	}
+	return nil, fmt.Errorf("coin selection %s is not supported", strategy)
+}
+
+var (
+	largestFirst = orderedSelector(func(a, b payd.UTXO) bool {
+		return a.Satoshis > b.Satoshis
+	})
+	smallestFirst = orderedSelector(func(a, b payd.UTXO) bool {
+		return a.Satoshis < b.Satoshis
+	})
+	oldestFirst = orderedSelector(func(a, b payd.UTXO) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+)
+
+// orderedSelector spends utxos in order, as sorted by the func, until the amount is covered.
+type orderedSelector func(a, b payd.UTXO) bool
+
+// SelectCoins will return the first utxos, in order, that cover the amount.
+func (o orderedSelector) SelectCoins(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	uu := spendable(args, utxos)
+	sort.SliceStable(uu, func(i, j int) bool {
+		return o(uu[i], uu[j])
+	})
+	return fund(args, uu)
+}
+
+// branchAndBound searches for utxos that cover the amount without needing a change output,
+// this saves the fee for the change output and doesn't create another utxo. If there is
+// no match the largest utxos are used.
+type branchAndBound struct{}
+
+// SelectCoins will return the utxos exceeding the amount by the least, as long as it is less
+// than the change fee, otherwise the largest utxos are returned.
+func (b branchAndBound) SelectCoins(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	uu := spendable(args, utxos)
+	sort.SliceStable(uu, func(i, j int) bool {
+		return uu[i].Satoshis > uu[j].Satoshis
+	})
+	// remaining[i] is the value left to spend from uu[i] onwards.
+	remaining := make([]uint64, len(uu)+1)
+	for i := len(uu) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + uu[i].Satoshis - args.InputFee
+	}
+	target, max := args.Satoshis, args.Satoshis+args.ChangeFee
+	var best []int
+	bestValue := uint64(0)
+	selected := make([]int, 0, len(uu))
+	tries := 0
+	var search func(i int, value uint64)
+	search = func(i int, value uint64) {
+		tries++
+		if tries > bnbMaxTries || value > max || (best != nil && value >= bestValue) {
+			return
+		}
+		if value > target {
+			best = append([]int{}, selected...)
+			bestValue = value
+			return
+		}
+		if i == len(uu) || value+remaining[i] <= target {
+			return
+		}
+		// include uu[i], then try without it.
+		selected = append(selected, i)
+		search(i+1, value+uu[i].Satoshis-args.InputFee)
+		selected = selected[:len(selected)-1]
+		search(i+1, value)
+	}
+	search(0, 0)
+	if best == nil {
+		return fund(args, uu)
+	}
+	resp := make([]payd.UTXO, 0, len(best))
+	for _, i := range best {
+		resp = append(resp, uu[i])
+	}
+	return resp
+}
+
+// privacy avoids linking utxos from different derivation branches by spending them
+// together. If no branch can cover the amount on its own, the fewest branches are used.
+type privacy struct{}
+
+// SelectCoins will return the utxos from the branch that covers the amount with the fewest
+// inputs, or if none can, the utxos from the largest branches.
+func (p privacy) SelectCoins(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	branches := map[string][]payd.UTXO{}
+	totals := map[string]uint64{}
+	var names []string
+	for _, u := range spendable(args, utxos) {
+		b := derivationBranch(u.DerivationPath)
+		if _, ok := branches[b]; !ok {
+			names = append(names, b)
+		}
+		branches[b] = append(branches[b], u)
+		totals[b] += u.Satoshis
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if totals[names[i]] == totals[names[j]] {
+			return names[i] < names[j]
+		}
+		return totals[names[i]] > totals[names[j]]
+	})
+	var best []payd.UTXO
+	for _, b := range names {
+		uu := largestFirst.SelectCoins(args, branches[b])
+		if len(uu) > 0 && (best == nil || len(uu) < len(best)) {
+			best = uu
+		}
+	}
+	if best != nil {
+		return best
+	}
+	// mixing branches can't be avoided, use as few as possible.
+	var uu []payd.UTXO
+	for _, b := range names {
+		bb := branches[b]
+		sort.SliceStable(bb, func(i, j int) bool {
+			return bb[i].Satoshis > bb[j].Satoshis
+		})
+		uu = append(uu, bb...)
+	}
+	return fund(args, uu)
+}
+
+// derivationBranch returns the branch of a derivation path, the path without its last index.
+func derivationBranch(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// spendable returns a copy of the utxos worth more than the fee to spend them.
+func spendable(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	uu := make([]payd.UTXO, 0, len(utxos))
+	for _, u := range utxos {
+		if u.Satoshis > args.InputFee {
+			uu = append(uu, u)
+		}
+	}
+	return uu
+}
+
+// fund returns the first utxos that cover the amount once their fees are paid,
+// or nothing if they can't.
+func fund(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	value := uint64(0)
+	for i, u := range utxos {
+		value += u.Satoshis - args.InputFee
+		if value > args.Satoshis {
+			return utxos[:i+1]
+		}
+	}
+	return nil
+}
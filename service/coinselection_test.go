@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/service"
+)
+
+func TestCoinSelector_SelectCoins(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	utxo := func(outpoint, path string, sats uint64, age time.Duration) payd.UTXO {
+		return payd.UTXO{Outpoint: outpoint, Satoshis: sats, DerivationPath: path, CreatedAt: now.Add(-age)}
+	}
+	utxos := []payd.UTXO{
+		utxo("a", "1/1/1", 500, time.Hour),
+		utxo("b", "1/1/2", 2000, 3*time.Hour),
+		utxo("c", "2/2/1", 300, 2*time.Hour),
+		utxo("d", "2/2/2", 1200, 4*time.Hour),
+		utxo("e", "3/3/1", 5, 5*time.Hour),
+	}
+	tests := map[string]struct {
+		strategy payd.CoinSelection
+		args     payd.CoinSelectArgs
+		utxos    []payd.UTXO
+		exp      []string
+	}{
+		"largest first uses the fewest utxos": {
+			strategy: payd.CoinSelectionLargestFirst,
+			args:     payd.CoinSelectArgs{Satoshis: 2500},
+			utxos:    utxos,
+			exp:      []string{"b", "d"},
+		},
+		"smallest first spends small utxos": {
+			strategy: payd.CoinSelectionSmallestFirst,
+			args:     payd.CoinSelectArgs{Satoshis: 700},
+			utxos:    utxos,
+			exp:      []string{"e", "c", "a"},
+		},
+		"utxos worth less than the input fee are not spent": {
+			strategy: payd.CoinSelectionSmallestFirst,
+			args:     payd.CoinSelectArgs{Satoshis: 700, InputFee: 10},
+			utxos:    utxos,
+			exp:      []string{"c", "a"},
+		},
+		"input fees are covered": {
+			strategy: payd.CoinSelectionLargestFirst,
+			args:     payd.CoinSelectArgs{Satoshis: 3185, InputFee: 10},
+			utxos:    utxos,
+			exp:      []string{"b", "d", "a"},
+		},
+		"oldest first spends the oldest utxos": {
+			strategy: payd.CoinSelectionOldestFirst,
+			args:     payd.CoinSelectArgs{Satoshis: 1500},
+			utxos:    utxos,
+			exp:      []string{"e", "d", "b"},
+		},
+		"branch and bound finds a match without change": {
+			strategy: payd.CoinSelectionBranchAndBound,
+			args:     payd.CoinSelectArgs{Satoshis: 1490, InputFee: 1, ChangeFee: 20},
+			utxos:    utxos,
+			exp:      []string{"d", "c"},
+		},
+		"branch and bound picks the closest match": {
+			strategy: payd.CoinSelectionBranchAndBound,
+			args:     payd.CoinSelectArgs{Satoshis: 799, ChangeFee: 500},
+			utxos:    utxos,
+			exp:      []string{"a", "c"},
+		},
+		"branch and bound falls back to largest first": {
+			strategy: payd.CoinSelectionBranchAndBound,
+			args:     payd.CoinSelectArgs{Satoshis: 2100, ChangeFee: 10},
+			utxos:    utxos,
+			exp:      []string{"b", "d"},
+		},
+		"privacy spends from a single branch": {
+			strategy: payd.CoinSelectionPrivacy,
+			args:     payd.CoinSelectArgs{Satoshis: 1400},
+			utxos:    utxos,
+			exp:      []string{"b"},
+		},
+		"privacy prefers the branch needing the fewest utxos": {
+			strategy: payd.CoinSelectionPrivacy,
+			args:     payd.CoinSelectArgs{Satoshis: 2300},
+			utxos:    utxos,
+			exp:      []string{"b", "a"},
+		},
+		"privacy mixes the fewest branches when it has to": {
+			strategy: payd.CoinSelectionPrivacy,
+			args:     payd.CoinSelectArgs{Satoshis: 3000},
+			utxos:    utxos,
+			exp:      []string{"b", "a", "d"},
+		},
+		"not enough funds selects nothing": {
+			strategy: payd.CoinSelectionLargestFirst,
+			args:     payd.CoinSelectArgs{Satoshis: 4005},
+			utxos:    utxos,
+		},
+		"branch and bound without enough funds selects nothing": {
+			strategy: payd.CoinSelectionBranchAndBound,
+			args:     payd.CoinSelectArgs{Satoshis: 4005},
+			utxos:    utxos,
+		},
+		"privacy without enough funds selects nothing": {
+			strategy: payd.CoinSelectionPrivacy,
+			args:     payd.CoinSelectArgs{Satoshis: 4005},
+			utxos:    utxos,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			selector, err := service.NewCoinSelector(test.strategy)
+			require.NoError(t, err)
+			var oo []string
+			for _, u := range selector.SelectCoins(test.args, test.utxos) {
+				oo = append(oo, u.Outpoint)
+			}
+			assert.Equal(t, test.exp, oo)
+		})
+	}
+}
+
+func TestCoinSelector_Unsupported(t *testing.T) {
+	_, err := service.NewCoinSelector("random")
+	assert.EqualError(t, err, "coin selection random is not supported")
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+)
+
+type confirmations struct {
+	tipRdr           payd.ChainTipReader
+	wtr              payd.TransactionWriter
+	minConfirmations uint32
+}
+
+// NewConfirmations will setup and return a new confirmation service, used to confirm mined
+// txs once they have the confirmations needed to spend their txos.
+func NewConfirmations(cfg *config.Wallet, tipRdr payd.ChainTipReader, wtr payd.TransactionWriter) *confirmations {
+	return &confirmations{
+		tipRdr:           tipRdr,
+		wtr:              wtr,
+		minConfirmations: cfg.MinConfirmations,
+	}
+}
+
+// TransactionsConfirm will confirm the mined txs that are at least the minimum confirmations
+// deep in the best chain, returning the number confirmed.
+func (c *confirmations) TransactionsConfirm(ctx context.Context) (int, error) {
+	tip, err := c.tipRdr.ChainTip(ctx)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get chain tip")
+	}
+	// a tx in the tip block has 1 confirmation.
+	maxHeight := tip.Height
+	if c.minConfirmations > 1 {
+		if tip.Height+1 < c.minConfirmations {
+			return 0, nil
+		}
+		maxHeight = tip.Height + 1 - c.minConfirmations
+	}
+	n, err := c.wtr.TransactionsConfirm(ctx, payd.TransactionsConfirmArgs{MaxBlockHeight: maxHeight})
+	if err != nil {
+		return 0, errors.WithMessagef(err, "failed to confirm transactions mined at or below height %d", maxHeight)
+	}
+	return n, nil
+}
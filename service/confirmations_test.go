@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestConfirmationsService_TransactionsConfirm(t *testing.T) {
+	tests := map[string]struct {
+		minConfs  uint32
+		tipErr    error
+		confirmFn func(context.Context, payd.TransactionsConfirmArgs) (int, error)
+		expArgs   *payd.TransactionsConfirmArgs
+		expN      int
+		expErr    error
+	}{
+		"txs at the tip are confirmed when 1 confirmation is needed": {
+			minConfs: 1,
+			expArgs:  &payd.TransactionsConfirmArgs{MaxBlockHeight: 100},
+			expN:     2,
+		},
+		"txs are confirmed once they are the minimum confirmations deep": {
+			minConfs: 6,
+			expArgs:  &payd.TransactionsConfirmArgs{MaxBlockHeight: 95},
+			expN:     2,
+		},
+		"nothing is confirmed when the chain is shorter than the minimum confirmations": {
+			minConfs: 102,
+		},
+		"chain tip error is reported": {
+			minConfs: 6,
+			tipErr:   errors.New("headers-sv is down"),
+			expErr:   errors.New("failed to get chain tip: headers-sv is down"),
+		},
+		"store error is reported": {
+			minConfs: 6,
+			confirmFn: func(context.Context, payd.TransactionsConfirmArgs) (int, error) {
+				return 0, errors.New("whoopsie")
+			},
+			expArgs: &payd.TransactionsConfirmArgs{MaxBlockHeight: 95},
+			expErr:  errors.New("failed to confirm transactions mined at or below height 95: whoopsie"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var args *payd.TransactionsConfirmArgs
+			svc := service.NewConfirmations(&config.Wallet{MinConfirmations: test.minConfs}, &mocks.ChainTipReaderMock{
+				ChainTipFunc: func(context.Context) (*payd.ChainTip, error) {
+					if test.tipErr != nil {
+						return nil, test.tipErr
+					}
+					return &payd.ChainTip{Height: 100}, nil
+				},
+			}, &mocks.TransactionWriterMock{
+				TransactionsConfirmFunc: func(ctx context.Context, a payd.TransactionsConfirmArgs) (int, error) {
+					args = &a
+					if test.confirmFn != nil {
+						return test.confirmFn(ctx, a)
+					}
+					return 2, nil
+				},
+			})
+			n, err := svc.TransactionsConfirm(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expN, n)
+			assert.Equal(t, test.expArgs, args)
+		})
+	}
+}
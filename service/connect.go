@@ -12,15 +12,15 @@ import (
 
 type connect struct {
 	wtr    payd.ConnectWriter
-	invRdr payd.InvoiceReader
+	invSvc payd.InvoiceService
 	dppCfg *config.DPP
 }
 
 // NewConnect will setup a new connect service used to connect this wallet to a dpp socket server.
-func NewConnect(wtr payd.ConnectWriter, invRdr payd.InvoiceReader, dppCfg *config.DPP) *connect {
+func NewConnect(wtr payd.ConnectWriter, invSvc payd.InvoiceService, dppCfg *config.DPP) *connect {
 	return &connect{
 		wtr:    wtr,
-		invRdr: invRdr,
+		invSvc: invSvc,
 		dppCfg: dppCfg,
 	}
 }
@@ -31,7 +31,7 @@ func (c *connect) Connect(ctx context.Context, args payd.ConnectArgs) error {
 		return err
 	}
 	// get the invoice if an error then it isn't here.
-	if _, err := c.invRdr.Invoice(ctx, payd.InvoiceArgs{InvoiceID: args.InvoiceID}); err != nil {
+	if _, err := c.invSvc.Invoice(ctx, payd.InvoiceArgs{InvoiceID: args.InvoiceID}); err != nil {
 		return errors.Wrapf(err, "failed to validate invoice %s when attempting to create connection", args.InvoiceID)
 	}
 	u, err := url.Parse(c.dppCfg.ServerHost)
@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/libsv/go-bk/bip32"
 	"github.com/libsv/go-bt/v2/bscript"
 	"github.com/libsv/payd/config"
 	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
 )
 
 type destinations struct {
@@ -33,67 +39,80 @@ func NewDestinationsService(deployCfg *config.Wallet, privKeySvc payd.PrivateKey
 	}
 }
 
-// Create will split satoshis into multiple denominations and store
+// DestinationsCreate will split satoshis into multiple denominations and store
 // as denominations waiting to be fulfilled in a tx.
 func (d *destinations) DestinationsCreate(ctx context.Context, req payd.DestinationsCreate) (*payd.Destination, error) {
+	if d.deployCfg != nil {
+		if req.Split == "" {
+			req.Split = payd.OutputSplit(d.deployCfg.OutputSplit)
+		}
+		if req.Denominations == 0 {
+			req.Denominations = d.deployCfg.OutputDenomination
+		}
+		if req.MaxOutputs == 0 {
+			req.MaxOutputs = d.deployCfg.OutputMax
+		}
+	}
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 	// Get the master key associated with the beneficiary of the invoice.
 	key := "masterkey"
-	priv, err := d.privKeySvc.PrivateKey(ctx, key, req.UserID)
+	xKey, derivePath, err := d.destinationKey(ctx, key, req.UserID)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, err
 	}
-	// TODO - split requested satsohis in some way
-	// 1 for now - we may decide to increase or split output in future so
-	// keeping the code here flexible
-	totOutputs := 1
-	destinations := make([]payd.DestinationCreate, 0, totOutputs)
-	for i := 0; i < totOutputs; i++ {
-		// TODO - run in a go routine when we start splitting
-		var path string
-		for { // attempt to create a unique derivation path
-			seed, err := d.seed.Uint64()
+	amounts, err := splitSatoshis(req.Split, req.Satoshis, req.Denominations, req.MaxOutputs, d.seed)
+	if err != nil {
+		return nil, err
+	}
+	destinations := make([]payd.DestinationCreate, len(amounts))
+	// keys are derived concurrently, the store is called by one at a time as it may be
+	// using a single db transaction, the claimed paths stop two outputs using the same path.
+	var mu sync.Mutex
+	claimed := make(map[string]struct{}, len(amounts))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, satoshis := range amounts {
+		i, satoshis := i, satoshis
+		g.Go(func() error {
+			var path string
+			for { // attempt to create a unique derivation path
+				seed, err := d.seed.Uint64()
+				if err != nil {
+					return errors.Wrap(err, "failed to create seed for derivation path")
+				}
+				path = derivePath(seed)
+				mu.Lock()
+				exists, err := d.pathClaimed(gctx, claimed, key, req.UserID, path)
+				mu.Unlock()
+				if err != nil {
+					return err
+				}
+				if !exists {
+					break
+				}
+			}
+			pubKey, err := xKey.DerivePublicKeyFromPath(path)
 			if err != nil {
-				return nil, errors.Wrap(err, "failed to create seed for derivation path")
+				return errors.Wrap(err, "failed to create new extended key when creating new payment request output")
 			}
-			path = bip32.DerivePath(seed)
-			exists, err := d.derivRdr.DerivationPathExists(ctx, payd.DerivationExistsArgs{
-				KeyName: key,
-				UserID:  req.UserID,
-				Path:    path,
-			})
+			s, err := bscript.NewP2PKHFromPubKeyBytes(pubKey)
 			if err != nil {
-				return nil, errors.Wrap(err, "failed to check derivation path exists when creating new destination")
+				return errors.WithMessage(err, "failed to derive key when creating output")
 			}
-			if !exists {
-				break
+			destinations[i] = payd.DestinationCreate{
+				UserID:         req.UserID,
+				DerivationPath: path,
+				Script:         s.String(),
+				Satoshis:       satoshis,
+				KeyName:        key,
 			}
-		}
-		pubKey, err := priv.DerivePublicKeyFromPath(path)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create new extended key when creating new payment request output")
-		}
-		s, err := bscript.NewP2PKHFromPubKeyBytes(pubKey)
-		if err != nil {
-			return nil, errors.WithMessage(err, "failed to derive key when creating output")
-		}
-		// use the below if we decide to split outputs
-		/*sats := args.Denomination * uint64(i+1)
-		if sats > args.Satoshis {
-			sats = sats - args.Satoshis
-		} else {
-			sats = args.Denomination
-		}*/
-		destinations = append(destinations, payd.DestinationCreate{
-			UserID:         req.UserID,
-			DerivationPath: path,
-			Script:         s.String(),
-			Satoshis:       req.Satoshis,
-			KeyName:        key,
+			return nil
 		})
 	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
 	oo, err := d.destRdrWtr.DestinationsCreate(ctx, payd.DestinationsCreateArgs{InvoiceID: req.InvoiceID}, destinations)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to store destinations")
@@ -103,6 +122,65 @@ func (d *destinations) DestinationsCreate(ctx context.Context, req payd.Destinat
 	}, nil
 }
 
+// destinationKey returns the key destinations are derived from and the func creating their paths.
+// Paths are hardened and derived from the private key, so a leaked child private key and the xpub
+// can't expose the master key, except for watch-only keys which only have an xpub.
+func (d *destinations) destinationKey(ctx context.Context, key string, userID uint64) (*bip32.ExtendedKey, func(uint64) string, error) {
+	xprv, err := d.privKeySvc.PrivateKey(ctx, key, userID)
+	if err == nil {
+		return xprv, bip32.DerivePath, nil
+	}
+	var clientErr lathos.ErrUnprocessable
+	if !errors.As(err, &clientErr) || clientErr.Code() != errcodes.ErrKeyWatchOnly {
+		return nil, nil, errors.WithStack(err)
+	}
+	xpub, err := d.privKeySvc.PublicKey(ctx, key, userID)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return xpub, derivePublicPath, nil
+}
+
+// derivePublicPath splits seed into a 3 level path like bip32.DerivePath, but without hardening
+// each level, so the path can be derived from an xpub.
+func derivePublicPath(seed uint64) string {
+	return fmt.Sprintf("%d/%d/%d", seed>>33, (seed<<31)>>33, seed&3)
+}
+
+// hardenedPath returns true if any level of path is hardened, these can only be derived
+// from a private key.
+func hardenedPath(path string) bool {
+	for _, level := range strings.Split(path, "/") {
+		if strings.HasSuffix(level, "'") {
+			return true
+		}
+		if i, err := strconv.ParseUint(level, 10, 32); err == nil && i >= bip32.HardenedKeyStart {
+			return true
+		}
+	}
+	return false
+}
+
+// pathClaimed returns true if the derivation path has been used, either by an existing
+// destination or another output being created, otherwise it is claimed.
+func (d *destinations) pathClaimed(ctx context.Context, claimed map[string]struct{}, key string, userID uint64, path string) (bool, error) {
+	if _, ok := claimed[path]; ok {
+		return true, nil
+	}
+	exists, err := d.derivRdr.DerivationPathExists(ctx, payd.DerivationExistsArgs{
+		KeyName: key,
+		UserID:  userID,
+		Path:    path,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check derivation path exists when creating new destination")
+	}
+	if !exists {
+		claimed[path] = struct{}{}
+	}
+	return exists, nil
+}
+
 // Destinations given the args, will return a set of Destinations.
 func (d *destinations) Destinations(ctx context.Context, args payd.DestinationsArgs) (*payd.Destination, error) {
 	if err := args.Validate(); err != nil {
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/libsv/payd/service"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	lathos "github.com/theflyingcodr/lathos/errs"
 	"gopkg.in/guregu/null.v3"
 )
 
@@ -85,6 +87,39 @@ func TestDestinationService_DestinationsCreate(t *testing.T) {
 			},
 			expDerivationChecks: 1,
 		},
+		"watch-only key derives unhardened paths from its xpub": {
+			req: payd.DestinationsCreate{
+				InvoiceID: null.StringFrom("abc123"),
+				Satoshis:  1000,
+				UserID:    1,
+			},
+			uint64Func: func() (uint64, error) {
+				return 0, nil
+			},
+			privateKeyFunc: func(context.Context, string) (*bip32.ExtendedKey, error) {
+				return nil, lathos.NewErrUnprocessable("K0002", "key masterkey is watch-only, it can't sign transactions")
+			},
+			derivationPathExistsFunc: func(context.Context, payd.DerivationExistsArgs) (bool, error) {
+				return false, nil
+			},
+			destinationsCreateFunc: destinationsToOutputs,
+			expDests: []payd.DestinationCreate{{
+				Satoshis:       1000,
+				Script:         "76a914d6656c85ad879e8c18c906c1e33f831cc77b130788ac",
+				DerivationPath: "0/0/0",
+				UserID:         1,
+				KeyName:        "masterkey",
+			}},
+			expDestination: &payd.Destination{
+				Outputs: []payd.Output{{
+					LockingScript:  internal.StringToScript("76a914d6656c85ad879e8c18c906c1e33f831cc77b130788ac"),
+					Satoshis:       1000,
+					DerivationPath: "0/0/0",
+					State:          "pending",
+				}},
+			},
+			expDerivationChecks: 1,
+		},
 		"success create after derivation path collision": {
 			req: payd.DestinationsCreate{
 				InvoiceID: null.StringFrom("abc123"),
@@ -231,6 +266,13 @@ func TestDestinationService_DestinationsCreate(t *testing.T) {
 						}
 						return bip32.NewKeyFromString("tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP")
 					},
+					PublicKeyFunc: func(ctx context.Context, name string, userID uint64) (*bip32.ExtendedKey, error) {
+						xprv, err := bip32.NewKeyFromString("tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP")
+						if err != nil {
+							return nil, err
+						}
+						return xprv.Neuter()
+					},
 				},
 				&mocks.DestinationsReaderWriterMock{
 					DestinationsCreateFunc: func(ctx context.Context, args payd.DestinationsCreateArgs, dests []payd.DestinationCreate) ([]payd.Output, error) {
@@ -536,3 +578,110 @@ func TestDestinationService_Destinations(t *testing.T) {
 		})
 	}
 }
+
+func TestDestinationService_DestinationsCreateSplit(t *testing.T) {
+	t.Parallel()
+	tests := map[string]struct {
+		cfg         *config.Wallet
+		req         payd.DestinationsCreate
+		expSatoshis []uint64
+		expRandom   bool
+		expErr      error
+	}{
+		"no split uses a single output": {
+			req:         payd.DestinationsCreate{Satoshis: 25000, Split: payd.OutputSplitNone},
+			expSatoshis: []uint64{25000},
+		},
+		"denomination split adds the remainder as an output": {
+			req:         payd.DestinationsCreate{Satoshis: 25000, Split: payd.OutputSplitDenomination, Denominations: 10000, MaxOutputs: 10},
+			expSatoshis: []uint64{10000, 10000, 5000},
+		},
+		"denomination split adds a dust remainder to the last output": {
+			req:         payd.DestinationsCreate{Satoshis: 20100, Split: payd.OutputSplitDenomination, Denominations: 10000, MaxOutputs: 10},
+			expSatoshis: []uint64{10000, 10100},
+		},
+		"denomination split is limited to max outputs": {
+			req:         payd.DestinationsCreate{Satoshis: 25000, Split: payd.OutputSplitDenomination, Denominations: 10000, MaxOutputs: 2},
+			expSatoshis: []uint64{10000, 15000},
+		},
+		"even split gives the remainder to the last output": {
+			req:         payd.DestinationsCreate{Satoshis: 1001, Split: payd.OutputSplitEven, MaxOutputs: 4},
+			expSatoshis: []uint64{250, 250, 250, 251},
+		},
+		"even split never creates dust outputs": {
+			req:         payd.DestinationsCreate{Satoshis: 300, Split: payd.OutputSplitEven, MaxOutputs: 10},
+			expSatoshis: []uint64{150, 150},
+		},
+		"random split creates outputs above the dust limit": {
+			req:       payd.DestinationsCreate{Satoshis: 100000, Split: payd.OutputSplitRandom, MaxOutputs: 5},
+			expRandom: true,
+		},
+		"wallet config is used by default": {
+			cfg:         &config.Wallet{OutputSplit: "even", OutputMax: 2},
+			req:         payd.DestinationsCreate{Satoshis: 1000},
+			expSatoshis: []uint64{500, 500},
+		},
+		"unknown split is rejected": {
+			req:    payd.DestinationsCreate{Satoshis: 1000, Split: "largest"},
+			expErr: errors.New("[split: value not found in allowed values]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var seed uint64
+			var mu sync.Mutex
+			var created []payd.DestinationCreate
+			svc := service.NewDestinationsService(
+				test.cfg,
+				&mocks.PrivateKeyServiceMock{
+					PrivateKeyFunc: func(ctx context.Context, name string, userID uint64) (*bip32.ExtendedKey, error) {
+						return bip32.NewKeyFromString("tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP")
+					},
+				},
+				&mocks.DestinationsReaderWriterMock{
+					DestinationsCreateFunc: func(ctx context.Context, args payd.DestinationsCreateArgs, dests []payd.DestinationCreate) ([]payd.Output, error) {
+						created = dests
+						return []payd.Output{}, nil
+					},
+				},
+				&mocks.DerivationReaderMock{
+					DerivationPathExistsFunc: func(ctx context.Context, args payd.DerivationExistsArgs) (bool, error) {
+						return false, nil
+					},
+				},
+				nil,
+				&mocks.SeedServiceMock{
+					Uint64Func: func() (uint64, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						seed += 7919
+						return seed, nil
+					},
+				},
+			)
+			_, err := svc.DestinationsCreate(context.TODO(), test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			paths := map[string]struct{}{}
+			satoshis := make([]uint64, 0, len(created))
+			total := uint64(0)
+			for _, d := range created {
+				paths[d.DerivationPath] = struct{}{}
+				satoshis = append(satoshis, d.Satoshis)
+				total += d.Satoshis
+				assert.GreaterOrEqual(t, d.Satoshis, uint64(payd.DustLimit))
+			}
+			assert.Len(t, paths, len(created), "derivation paths should be unique")
+			assert.Equal(t, test.req.Satoshis, total)
+			if test.expRandom {
+				assert.GreaterOrEqual(t, len(created), 2)
+				assert.LessOrEqual(t, len(created), test.req.MaxOutputs)
+				return
+			}
+			assert.Equal(t, test.expSatoshis, satoshis)
+		})
+	}
+}
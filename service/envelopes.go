@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"time"
 
 	"github.com/libsv/go-bc/spv"
 	"github.com/libsv/go-bk/bip32"
@@ -14,26 +15,35 @@ import (
 	"github.com/theflyingcodr/lathos/errs"
 
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+)
+
+// Sizes, in bytes, used to estimate the fees passed to the coin selector.
+const (
+	p2pkhInputSize  = 148
+	p2pkhOutputSize = 34
 )
 
 type envelopes struct {
-	pkSvc   payd.PrivateKeyService
-	destWtr payd.DestinationsWriter
-	txoWtr  payd.TxoWriter
-	txWtr   payd.TransactionWriter
-	seedSvc payd.SeedService
-	spvc    spv.EnvelopeCreator
+	pkSvc     payd.PrivateKeyService
+	destWtr   payd.DestinationsWriter
+	txoWtr    payd.TxoWriter
+	txWtr     payd.TransactionWriter
+	seedSvc   payd.SeedService
+	spvc      spv.EnvelopeCreator
+	walletCfg *config.Wallet
 }
 
 // NewEnvelopes will setup and return an Envelope service, used to create spv envelopes.
-func NewEnvelopes(pkSvc payd.PrivateKeyService, destWtr payd.DestinationsWriter, txWtr payd.TransactionWriter, txoWtr payd.TxoWriter, seedSvc payd.SeedService, spvc spv.EnvelopeCreator) *envelopes {
+func NewEnvelopes(pkSvc payd.PrivateKeyService, destWtr payd.DestinationsWriter, txWtr payd.TransactionWriter, txoWtr payd.TxoWriter, seedSvc payd.SeedService, spvc spv.EnvelopeCreator, walletCfg *config.Wallet) *envelopes {
 	return &envelopes{
-		pkSvc:   pkSvc,
-		destWtr: destWtr,
-		txoWtr:  txoWtr,
-		txWtr:   txWtr,
-		seedSvc: seedSvc,
-		spvc:    spvc,
+		pkSvc:     pkSvc,
+		destWtr:   destWtr,
+		txoWtr:    txoWtr,
+		txWtr:     txWtr,
+		seedSvc:   seedSvc,
+		spvc:      spvc,
+		walletCfg: walletCfg,
 	}
 }
 
@@ -48,6 +58,22 @@ func (e *envelopes) Envelope(ctx context.Context, args payd.EnvelopeArgs, req dp
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to retrieve private key")
 	}
+	strategy := args.CoinSelection
+	if strategy == "" {
+		strategy = payd.CoinSelection(e.walletCfg.CoinSelection)
+	}
+	if strategy == "" {
+		strategy = payd.CoinSelectionBranchAndBound
+	}
+	selector, err := NewCoinSelector(strategy)
+	if err != nil {
+		return nil, errs.NewErrUnprocessable("F002", err.Error())
+	}
+	inputFee, changeFee := uint64(0), uint64(0)
+	if fee, err := req.FeeRate.Fee(bt.FeeTypeStandard); err == nil && fee.MiningFee.Bytes > 0 {
+		inputFee = uint64(p2pkhInputSize * fee.MiningFee.Satoshis / fee.MiningFee.Bytes)
+		changeFee = uint64(p2pkhOutputSize * fee.MiningFee.Satoshis / fee.MiningFee.Bytes)
+	}
 
 	tx := bt.NewTx()
 	// Add funds to new tx.
@@ -62,10 +88,21 @@ func (e *envelopes) Envelope(ctx context.Context, args payd.EnvelopeArgs, req dp
 		pathMap:       make(map[*bscript.Script]string),
 		masterPrivKey: privKey,
 	}
+	var reservedUntil time.Time
+	if e.walletCfg.ReservationTTL > 0 {
+		reservedUntil = time.Now().UTC().Add(e.walletCfg.ReservationTTL)
+	}
 	if err = tx.Fund(ctx, req.FeeRate, func(ctx context.Context, deficit uint64) ([]*bt.UTXO, error) {
 		utxos, err := e.txoWtr.UTXOReserve(ctx, payd.UTXOReserve{
-			ReservedFor: args.PayToURL,
-			Satoshis:    deficit,
+			ReservedFor:   args.PayToURL,
+			Satoshis:      deficit,
+			ReservedUntil: reservedUntil,
+			InputFee:      inputFee,
+			ChangeFee:     changeFee,
+			Selector:      selector,
+			KeyName:       keyname,
+			UserID:        userID,
+			Confirmed:     e.walletCfg.MinConfirmations > 0,
 		})
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to reserve utxos")
@@ -102,7 +139,7 @@ func (e *envelopes) Envelope(ctx context.Context, args payd.EnvelopeArgs, req dp
 		return nil, errors.Wrapf(err, "failed to fund tx for payment %s", args.PayToURL)
 	}
 
-	changeOutput, err := e.changeScript(privKey)
+	changeOutput, err := derivedOutput(e.seedSvc, privKey)
 	if err != nil {
 		return nil, err
 	}
@@ -148,6 +185,7 @@ func (e *envelopes) Envelope(ctx context.Context, args payd.EnvelopeArgs, req dp
 			Outpoint:      fmt.Sprintf("%s%d", txCreate.TxID, tx.OutputCount()-1),
 			Vout:          uint64(tx.OutputCount() - 1),
 			DestinationID: oo[0].ID,
+			Satoshis:      tx.Outputs[tx.OutputCount()-1].Satoshis,
 		}}
 	}
 
@@ -167,9 +205,9 @@ func (e *envelopes) Envelope(ctx context.Context, args payd.EnvelopeArgs, req dp
 	return spvEnvelope, nil
 }
 
-// changeScript will create and return a change locking script.
-func (e *envelopes) changeScript(privKey *bip32.ExtendedKey) (*payd.Output, error) {
-	seed, err := e.seedSvc.Uint64()
+// derivedOutput will create and return a locking script paying a newly derived key.
+func derivedOutput(seedSvc payd.SeedService, privKey *bip32.ExtendedKey) (*payd.Output, error) {
+	seed, err := seedSvc.Uint64()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create seed for derivation path")
 	}
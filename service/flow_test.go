@@ -0,0 +1,598 @@
+package service_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bc/spv"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-spvchannels"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theflyingcodr/lathos"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/data"
+	"github.com/libsv/payd/data/memory"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+	"github.com/libsv/payd/session"
+)
+
+// flow wires the services used to take a payment to an in-memory store.
+type flow struct {
+	store    data.Store
+	invoices payd.InvoiceService
+	payments payd.PaymentsService
+	proofs   payd.ProofsService
+	balance  payd.BalanceService
+	webhooks payd.WebhookService
+	sent     []payd.WebhookMessage
+	// callbacks sends stored proofs, those sent are recorded in proofsSent.
+	callbacks  payd.ProofCallbackService
+	proofsSent []payd.ProofCallback
+	// headers is the best chain, blocks are added by mine.
+	headers map[string]*bc.BlockHeader
+	// heights are the heights of the blocks in headers.
+	heights map[string]uint32
+	// height is the height of the chain tip, incremented by mine.
+	height uint32
+}
+
+func newFlow(t *testing.T, broadcastFn func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error) *flow {
+	store := memory.NewMemoryStore()
+	transacter := &memory.Transacter{}
+	pkSvc := service.NewPrivateKeys(store, &config.Wallet{})
+	require.NoError(t, pkSvc.Create(context.Background(), "masterkey", 1))
+	walletCfg := &config.Wallet{Network: config.NetworkRegtest, PaymentExpiryHours: 24}
+	destSvc := service.NewDestinationsService(walletCfg, pkSvc, store, store, store, service.NewSeedService())
+	f := &flow{store: store, headers: map[string]*bc.BlockHeader{}, heights: map[string]uint32{}}
+	webhooks := service.NewWebhooks(log.Noop{}, &config.Webhooks{MaxAttempts: 3, Backoff: time.Second, BackoffMax: time.Minute}, store,
+		&mocks.WebhookSenderMock{
+			WebhookSendFunc: func(ctx context.Context, args payd.WebhookSendArgs, msg payd.WebhookMessage) error {
+				f.sent = append(f.sent, msg)
+				return nil
+			},
+		}, service.NewTimestampService())
+	callbacks := service.NewProofCallbacks(log.Noop{}, &config.ProofCallbacks{MaxAttempts: 3, Backoff: time.Second, BackoffMax: time.Minute}, store,
+		&mocks.ProofCallbackSenderMock{
+			ProofCallbackSendFunc: func(ctx context.Context, callback payd.ProofCallback) error {
+				f.proofsSent = append(f.proofsSent, callback)
+				return nil
+			},
+		}, service.NewTimestampService())
+	headers := &mocks.HeaderChainMock{
+		BlockHeaderFunc: func(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+			h, ok := f.headers[blockHash]
+			if !ok {
+				return nil, bc.ErrHeaderNotFound
+			}
+			return h, nil
+		},
+		BlockHeightFunc: func(ctx context.Context, blockHash string) (uint32, error) {
+			h, ok := f.heights[blockHash]
+			if !ok {
+				return 0, bc.ErrHeaderNotFound
+			}
+			return h, nil
+		},
+	}
+	verifier, err := spv.NewMerkleProofVerifier(headers)
+	require.NoError(t, err)
+	tipRdr := &mocks.ChainTipReaderMock{
+		ChainTipFunc: func(ctx context.Context) (*payd.ChainTip, error) {
+			return &payd.ChainTip{Height: f.height}, nil
+		},
+	}
+	*f = flow{
+		store:    store,
+		invoices: service.NewInvoice(&config.Server{Hostname: "payd"}, walletCfg, store, destSvc, transacter, service.NewTimestampService(), webhooks, tipRdr),
+		payments: service.NewPayments(log.Noop{}, &mocks.PaymentVerifierMock{}, store, store, store, transacter,
+			&mocks.BroadcastWriterMock{BroadcastFunc: broadcastFn}, store, store,
+			&mocks.PeerChannelsServiceMock{
+				PeerChannelCreateFunc: func(ctx context.Context, req spvchannels.ChannelCreateRequest) (*payd.PeerChannel, error) {
+					return &payd.PeerChannel{ID: "channel1", CreatedAt: time.Now()}, nil
+				},
+				PeerChannelAPITokensCreateFunc: func(ctx context.Context, reqs ...*payd.PeerChannelAPITokenCreateArgs) ([]*spvchannels.TokenCreateReply, error) {
+					return []*spvchannels.TokenCreateReply{{Token: "mapi"}, {Token: "notify"}, {Token: "payer"}}, nil
+				},
+			},
+			&mocks.PeerChannelsNotifyServiceMock{
+				SubscribeFunc: func(ctx context.Context, args *payd.PeerChannel) error {
+					return nil
+				},
+			}, webhooks, &config.PeerChannels{Host: "peerchannels:25009"}),
+		proofs: service.NewProofsService(store, store, store, webhooks, transacter, service.NewTimestampService(),
+			headers, verifier, walletCfg, log.Noop{}),
+		balance:   service.NewBalance(store, tipRdr, walletCfg),
+		webhooks:  webhooks,
+		callbacks: callbacks,
+		headers:   f.headers,
+		heights:   f.heights,
+	}
+	return f
+}
+
+// mine adds a block containing only tx to the best chain, returning the block hash.
+func (f *flow) mine(tx *bt.Tx) string {
+	header := &bc.BlockHeader{
+		Version:        1,
+		Time:           uint32(time.Now().Unix()),
+		HashPrevBlock:  make([]byte, 32),
+		HashMerkleRoot: tx.TxIDBytes(),
+		Bits:           []byte{0x20, 0x7f, 0xff, 0xff},
+	}
+	hash := hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(header.Bytes())))
+	f.headers[hash] = header
+	f.height++
+	f.heights[hash] = f.height
+	return hash
+}
+
+// events returns the events of the webhook messages sent so far.
+func (f *flow) events() []payd.WebhookEvent {
+	ee := make([]payd.WebhookEvent, 0, len(f.sent))
+	for _, msg := range f.sent {
+		ee = append(ee, msg.Event)
+	}
+	return ee
+}
+
+// pay creates an invoice for satoshis and a fee quote for it, returning the
+// invoice and a tx paying it.
+func (f *flow) pay(t *testing.T, ctx context.Context, satoshis uint64) (*payd.Invoice, *bt.Tx) {
+	inv, err := f.invoices.Create(ctx, payd.InvoiceCreate{Satoshis: satoshis})
+	require.NoError(t, err)
+	fq := bt.NewFeeQuote()
+	fq.UpdateExpiry(time.Now().Add(time.Hour))
+	require.NoError(t, f.store.FeeQuoteCreate(ctx, &payd.FeeQuoteCreateArgs{InvoiceID: inv.ID, FeeQuote: fq}))
+	oo, err := f.store.Destinations(ctx, payd.DestinationsArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+
+	tx := bt.NewTx()
+	require.NoError(t, tx.From("2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca", 0,
+		"76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac", satoshis+100))
+	for _, o := range oo {
+		tx.AddOutput(&bt.Output{LockingScript: o.LockingScript, Satoshis: o.Satoshis})
+	}
+	return inv, tx
+}
+
+func TestFlow_InvoicePaymentProofBalance(t *testing.T) {
+	f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+		return nil
+	})
+	ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+	inv, tx := f.pay(t, ctx, 1000)
+
+	rawTx := tx.String()
+	ack, err := f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+	require.NoError(t, err)
+	assert.Equal(t, tx.TxID(), ack.TxID)
+	assert.Equal(t, "payer", ack.PeerChannel.Token)
+
+	paid, err := f.invoices.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePaid, paid.State)
+	assert.True(t, paid.PaymentReceivedAt.Valid)
+
+	// a second payment for the same invoice is rejected.
+	_, err = f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+	assert.True(t, lathos.IsDuplicate(err))
+
+	blockHash := f.mine(tx)
+	proof := &bc.MerkleProof{
+		TxOrID:     tx.TxID(),
+		Target:     blockHash,
+		TargetType: "hash",
+	}
+	env, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
+		CallbackPayload: proof,
+		BlockHash:       blockHash,
+		BlockHeight:     f.height,
+		CallbackTxID:    tx.TxID(),
+		CallbackReason:  "merkleProof",
+	})
+	require.NoError(t, err)
+	require.NoError(t, f.proofs.Create(ctx, dpp.ProofCreateArgs{TxID: tx.TxID()}, *env))
+	stored, err := f.store.MerkleProof(ctx, tx.TxID())
+	require.NoError(t, err)
+	assert.Equal(t, proof, stored)
+
+	balance, err := f.balance.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), balance.Satoshis)
+	assert.Equal(t, uint64(1000), balance.Confirmed.Satoshis)
+	assert.Equal(t, f.height, balance.TipHeight)
+	assert.Equal(t, uint32(1), balance.Confirmed.MinConfirmations)
+
+	// the invoice and balance gain a confirmation with each block mined on top of the payment.
+	f.mine(bt.NewTx())
+	mined, err := f.invoices.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(f.height-1), mined.BlockHeight.Int64)
+	assert.Equal(t, uint32(2), mined.Confirmations)
+	balance, err = f.balance.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, f.height, balance.TipHeight)
+	assert.Equal(t, uint32(2), balance.Confirmed.MinConfirmations)
+	assert.Equal(t, uint32(2), balance.Confirmed.MaxConfirmations)
+}
+
+func TestFlow_FailedBroadcastRollsBack(t *testing.T) {
+	f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+		return errors.New("mapi is down")
+	})
+	ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+	inv, tx := f.pay(t, ctx, 1000)
+
+	rawTx := tx.String()
+	_, err := f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+	assert.EqualError(t, err, "failed to broadcast tx: mapi is down")
+
+	pending, err := f.invoices.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePending, pending.State)
+	_, err = f.store.Tx(ctx, tx.TxID())
+	assert.True(t, lathos.IsNotFound(err))
+
+	balance, err := f.balance.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), balance.Satoshis)
+}
+
+func TestFlow_InstalmentPayments(t *testing.T) {
+	f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+		return nil
+	})
+	ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+	inv, tx := f.pay(t, ctx, 1000)
+
+	// the invoice is paid in two instalments, each from a different wallet.
+	payInstalment := func(fundingTxID string, satoshis uint64) error {
+		part := bt.NewTx()
+		require.NoError(t, part.From(fundingTxID, 0, "76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac", satoshis+100))
+		part.AddOutput(&bt.Output{LockingScript: tx.Outputs[0].LockingScript, Satoshis: satoshis})
+		rawTx := part.String()
+		_, err := f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+		return err
+	}
+	require.NoError(t, payInstalment("2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca", 400))
+	part, err := f.invoices.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePartiallyPaid, part.State)
+	assert.Equal(t, uint64(400), part.SatoshisReceived)
+	assert.False(t, part.PaymentReceivedAt.Valid)
+
+	require.NoError(t, payInstalment("9c0ab5c4e8a8e5ff1ad17c2dbea4fb3b4dc6a0b2e77c3a3e0fd2ae1ab07d2c1e", 600))
+	paid, err := f.invoices.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Equal(t, payd.StateInvoicePaid, paid.State)
+	assert.Equal(t, uint64(1000), paid.SatoshisReceived)
+	assert.True(t, paid.PaymentReceivedAt.Valid)
+
+	pp, err := f.store.InvoicePayments(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	assert.Len(t, pp, 2)
+
+	// once paid in full no further payments are accepted.
+	err = payInstalment("0d4e1cba6e4d0e2f5c3b3ff81a2d93b4a1d1e7b5c0c3c0b16a9b0e4f2d6c7a8b", 100)
+	assert.True(t, lathos.IsDuplicate(err))
+
+	balance, err := f.balance.Balance(ctx, payd.BalanceArgs{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), balance.Satoshis)
+}
+
+func TestFlow_Webhooks(t *testing.T) {
+	f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+		return nil
+	})
+	ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+	_, err := f.webhooks.WebhookCreate(ctx, payd.WebhookCreate{
+		URL:    "https://merchant.com/hooks",
+		Events: []payd.WebhookEvent{payd.WebhookEventInvoicePaid, payd.WebhookEventTxBroadcast},
+	})
+	require.NoError(t, err)
+	inv, tx := f.pay(t, ctx, 1000)
+
+	rawTx := tx.String()
+	_, err = f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+	require.NoError(t, err)
+
+	n, err := f.webhooks.WebhooksDeliver(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.ElementsMatch(t, []payd.WebhookEvent{payd.WebhookEventTxBroadcast, payd.WebhookEventInvoicePaid}, f.events())
+
+	dd, err := f.webhooks.WebhookDeliveries(ctx, payd.WebhookDeliveriesArgs{State: payd.StateWebhookDeliveryDelivered})
+	require.NoError(t, err)
+	assert.Len(t, dd, 2)
+
+	// delivered events aren't sent again.
+	n, err = f.webhooks.WebhooksDeliver(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestFlow_WebhooksFailedBroadcast(t *testing.T) {
+	f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+		return errors.New("mapi is down")
+	})
+	ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+	_, err := f.webhooks.WebhookCreate(ctx, payd.WebhookCreate{
+		URL:    "https://merchant.com/hooks",
+		Events: []payd.WebhookEvent{payd.WebhookEventTxFailed, payd.WebhookEventTxBroadcast},
+	})
+	require.NoError(t, err)
+	inv, tx := f.pay(t, ctx, 1000)
+
+	rawTx := tx.String()
+	_, err = f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+	require.Error(t, err)
+
+	// the payment is rolled back, no tx is stored so there is nothing to send an event for.
+	tt, err := f.store.Transactions(ctx, payd.TransactionSearchArgs{})
+	require.NoError(t, err)
+	assert.Empty(t, tt)
+	_, err = f.webhooks.WebhooksDeliver(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, f.events())
+}
+
+func TestFlow_ProofCallbacks(t *testing.T) {
+	f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+		return nil
+	})
+	ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+	inv, tx := f.pay(t, ctx, 1000)
+
+	rawTx := tx.String()
+	_, err := f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{
+		RawTx: &rawTx,
+		ProofCallbacks: map[string]dpp.ProofCallback{
+			"https://payer.com/proofs": {Token: "abc"},
+		},
+	})
+	require.NoError(t, err)
+
+	// nothing is sent until the proof is received.
+	n, err := f.callbacks.ProofCallbacksSend(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	blockHash := f.mine(tx)
+	env, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
+		CallbackPayload: &bc.MerkleProof{
+			TxOrID:     tx.TxID(),
+			Target:     blockHash,
+			TargetType: "hash",
+		},
+		BlockHash:      blockHash,
+		CallbackTxID:   tx.TxID(),
+		CallbackReason: "merkleProof",
+	})
+	require.NoError(t, err)
+	require.NoError(t, f.proofs.Create(ctx, dpp.ProofCreateArgs{TxID: tx.TxID()}, *env))
+
+	n, err = f.callbacks.ProofCallbacksSend(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, f.proofsSent, 1)
+	assert.Equal(t, "https://payer.com/proofs", f.proofsSent[0].URL)
+	assert.Equal(t, "abc", f.proofsSent[0].Token)
+	// the envelope is sent on as received.
+	bb, err := json.Marshal(env)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(bb), string(f.proofsSent[0].Proof))
+
+	cc, err := f.store.ProofCallbacks(ctx, payd.ProofCallbackArgs{InvoiceID: inv.ID})
+	require.NoError(t, err)
+	require.Len(t, cc, 1)
+	assert.Equal(t, payd.StateProofCallbackSent, cc[0].State)
+	assert.Equal(t, 1, cc[0].Attempts)
+
+	// sent proofs aren't sent again.
+	n, err = f.callbacks.ProofCallbacksSend(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestFlow_AbandonedSocketPayment(t *testing.T) {
+	tests := map[string]struct {
+		acked      bool
+		expRelease int
+	}{
+		"utxos of a payment that is never acked are released once the reservation expires": {
+			expRelease: 1,
+		},
+		"utxos of an acked payment stay spent": {
+			acked: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := memory.NewMemoryStore()
+			transacter := &memory.Transacter{}
+			pkSvc := service.NewPrivateKeys(store, &config.Wallet{})
+			require.NoError(t, pkSvc.Create(ctx, "masterkey", 1))
+			key, err := pkSvc.PrivateKey(ctx, "masterkey", 1)
+			require.NoError(t, err)
+
+			// fund the wallet with a broadcast tx paying the master key.
+			pubKey, err := key.DerivePublicKeyFromPath("0/0")
+			require.NoError(t, err)
+			script, err := bscript.NewP2PKHFromPubKeyBytes(pubKey)
+			require.NoError(t, err)
+			oo, err := store.DestinationsCreate(ctx, payd.DestinationsCreateArgs{}, []payd.DestinationCreate{{
+				Script:         script.String(),
+				DerivationPath: "0/0",
+				UserID:         1,
+				Satoshis:       10000,
+				KeyName:        "masterkey",
+			}})
+			require.NoError(t, err)
+			funding := bt.NewTx()
+			require.NoError(t, funding.From("2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca", 0,
+				"76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac", 10100))
+			funding.AddOutput(&bt.Output{LockingScript: script, Satoshis: 10000})
+			require.NoError(t, store.TransactionCreate(ctx, payd.TransactionCreate{
+				TxID:  funding.TxID(),
+				TxHex: funding.String(),
+				Outputs: []*payd.TxoCreate{{
+					Outpoint:      funding.TxID() + "0",
+					DestinationID: oo[0].ID,
+					TxID:          funding.TxID(),
+					Satoshis:      10000,
+				}},
+			}))
+			require.NoError(t, store.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: funding.TxID()},
+				payd.TransactionStateUpdate{State: payd.StateTxBroadcast}))
+
+			walletCfg := &config.Wallet{ReservationTTL: time.Minute}
+			envelopes := service.NewEnvelopes(pkSvc, store, store, store, service.NewSeedService(), nil, walletCfg)
+			payee, err := bscript.NewP2PKHFromAddress("mtdruWYVEV1wz5yL7GvpBj4MgifCB7yhPd")
+			require.NoError(t, err)
+			req := dpp.PaymentRequest{
+				Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: payee}}},
+				FeeRate:      bt.NewFeeQuote(),
+			}
+
+			// the socket flow sends the envelope to the payee and commits, the ack arrives later, if at all.
+			txCtx := transacter.WithTx(ctx)
+			env, err := envelopes.Envelope(txCtx, payd.EnvelopeArgs{PayToURL: "channel1#attempt1"}, req)
+			require.NoError(t, err)
+			require.NoError(t, transacter.Commit(txCtx))
+			if test.acked {
+				require.NoError(t, store.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: env.TxID},
+					payd.TransactionStateUpdate{State: payd.StateTxBroadcast}))
+			}
+			reservations := service.NewUTXOReservations(log.Noop{}, store, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return time.Now().UTC().Add(2 * time.Minute)
+				},
+			})
+			n, err := reservations.UTXOReservationsExpire(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.expRelease, n)
+
+			uu, err := store.UTXOs(ctx, payd.UTXOSearchArgs{})
+			require.NoError(t, err)
+			var found bool
+			for _, u := range uu {
+				if u.Outpoint != funding.TxID()+"0" {
+					continue
+				}
+				found = true
+				assert.Equal(t, test.acked, u.SpendingTxID.Valid)
+				assert.Equal(t, test.acked, u.ReservedFor.Valid)
+			}
+			assert.True(t, found)
+		})
+	}
+}
+
+func TestFlow_SplitPayments(t *testing.T) {
+	splits := map[string]*config.Wallet{
+		"denomination": {OutputSplit: string(payd.OutputSplitDenomination), OutputDenomination: 1000, OutputMax: 3},
+		"even":         {OutputSplit: string(payd.OutputSplitEven), OutputMax: 3},
+		"random":       {OutputSplit: string(payd.OutputSplitRandom), OutputMax: 3},
+	}
+	tests := map[string]struct {
+		// amounts returns the satoshis paid to each destination.
+		amounts  func(oo []payd.Output) []uint64
+		expState payd.InvoiceState
+		expErr   string
+	}{
+		"paying every destination its amount pays the invoice": {
+			amounts: func(oo []payd.Output) []uint64 {
+				aa := make([]uint64, len(oo))
+				for i, o := range oo {
+					aa[i] = o.Satoshis
+				}
+				return aa
+			},
+			expState: payd.StateInvoicePaid,
+		},
+		"paying one destination in full is a part payment": {
+			amounts: func(oo []payd.Output) []uint64 {
+				return []uint64{oo[0].Satoshis}
+			},
+			expState: payd.StateInvoicePartiallyPaid,
+		},
+		"moving satoshis from one destination to another is rejected": {
+			amounts: func(oo []payd.Output) []uint64 {
+				aa := make([]uint64, len(oo))
+				for i, o := range oo {
+					aa[i] = o.Satoshis
+				}
+				aa[0] += 10
+				aa[len(aa)-1] -= 10
+				return aa
+			},
+			expErr: "satoshis outstanding",
+		},
+	}
+	for split, walletCfg := range splits {
+		for name, test := range tests {
+			t.Run(split+" "+name, func(t *testing.T) {
+				f := newFlow(t, func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+					return nil
+				})
+				ctx := session.WithUser(context.Background(), &payd.User{ID: 1})
+				walletCfg.Network = config.NetworkRegtest
+				walletCfg.PaymentExpiryHours = 24
+				pkSvc := service.NewPrivateKeys(f.store, &config.Wallet{})
+				destSvc := service.NewDestinationsService(walletCfg, pkSvc, f.store, f.store, f.store, service.NewSeedService())
+				invoices := service.NewInvoice(&config.Server{Hostname: "payd"}, walletCfg, f.store, destSvc, &memory.Transacter{},
+					service.NewTimestampService(), &mocks.WebhookPublisherMock{
+						PublishFunc: func(ctx context.Context, event payd.WebhookEvent, payload interface{}) error {
+							return nil
+						},
+					}, &mocks.ChainTipReaderMock{
+						ChainTipFunc: func(ctx context.Context) (*payd.ChainTip, error) {
+							return &payd.ChainTip{}, nil
+						},
+					})
+				inv, err := invoices.Create(ctx, payd.InvoiceCreate{Satoshis: 3000})
+				require.NoError(t, err)
+				fq := bt.NewFeeQuote()
+				fq.UpdateExpiry(time.Now().Add(time.Hour))
+				require.NoError(t, f.store.FeeQuoteCreate(ctx, &payd.FeeQuoteCreateArgs{InvoiceID: inv.ID, FeeQuote: fq}))
+				oo, err := f.store.Destinations(ctx, payd.DestinationsArgs{InvoiceID: inv.ID})
+				require.NoError(t, err)
+				require.Greater(t, len(oo), 1)
+
+				tx := bt.NewTx()
+				require.NoError(t, tx.From("2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca", 0,
+					"76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac", 3100))
+				for i, satoshis := range test.amounts(oo) {
+					tx.AddOutput(&bt.Output{LockingScript: oo[i].LockingScript, Satoshis: satoshis})
+				}
+				rawTx := tx.String()
+				_, err = f.payments.PaymentCreate(ctx, payd.PaymentCreateArgs{InvoiceID: inv.ID}, dpp.Payment{RawTx: &rawTx})
+				if test.expErr != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.expErr)
+					return
+				}
+				require.NoError(t, err)
+				paid, err := f.invoices.Invoice(ctx, payd.InvoiceArgs{InvoiceID: inv.ID})
+				require.NoError(t, err)
+				assert.Equal(t, test.expState, paid.State)
+			})
+		}
+	}
+}
@@ -36,10 +36,13 @@ type invoice struct {
 	cfg        *config.Server
 	wallCfg    *config.Wallet
 	transacter payd.Transacter
+	webhooks   payd.WebhookPublisher
+	tipRdr     payd.ChainTipReader
 }
 
 // NewInvoice will setup and return a new invoice service.
-func NewInvoice(cfg *config.Server, wallCfg *config.Wallet, store payd.InvoiceReaderWriter, destSvc destinationCreator, transacter payd.Transacter, timeSvc payd.TimestampService) *invoice {
+func NewInvoice(cfg *config.Server, wallCfg *config.Wallet, store payd.InvoiceReaderWriter, destSvc destinationCreator, transacter payd.Transacter,
+	timeSvc payd.TimestampService, webhooks payd.WebhookPublisher, tipRdr payd.ChainTipReader) *invoice {
 	return &invoice{
 		cfg:        cfg,
 		wallCfg:    wallCfg,
@@ -47,6 +50,8 @@ func NewInvoice(cfg *config.Server, wallCfg *config.Wallet, store payd.InvoiceRe
 		destSvc:    destSvc,
 		transacter: transacter,
 		timeSvc:    timeSvc,
+		webhooks:   webhooks,
+		tipRdr:     tipRdr,
 	}
 }
 
@@ -59,21 +64,65 @@ func (i *invoice) Invoice(ctx context.Context, args payd.InvoiceArgs) (*payd.Inv
 	if err != nil {
 		return nil, errors.WithMessagef(err, "failed to get invoice with id %s", args.InvoiceID)
 	}
-	return inv, err
+	ii := []payd.Invoice{*inv}
+	if err := i.confirmations(ctx, ii); err != nil {
+		return nil, err
+	}
+	return &ii[0], nil
 }
 
-// Invoices will return all currently stored invoices.
-func (i *invoice) Invoices(ctx context.Context) ([]payd.Invoice, error) {
-	ii, err := i.store.Invoices(ctx)
+// Invoices will return a page of invoices matching the search args.
+func (i *invoice) Invoices(ctx context.Context, args payd.InvoiceSearchArgs) (*payd.InvoicePage, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if args.Limit == 0 {
+		args.Limit = payd.InvoiceSearchDefaultLimit
+	}
+	limit := args.Limit
+	// fetch an extra invoice to find out if there is another page.
+	args.Limit++
+	ii, err := i.store.Invoices(ctx, args)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to get invoices")
 	}
-	return ii, nil
+	resp := &payd.InvoicePage{Invoices: ii}
+	if len(ii) > limit {
+		resp.Invoices = ii[:limit]
+		last := resp.Invoices[limit-1]
+		resp.NextCursor = payd.InvoiceCursor{CreatedAt: last.CreatedAt, InvoiceID: last.ID}.String()
+	}
+	if resp.Invoices == nil {
+		resp.Invoices = []payd.Invoice{}
+	}
+	if err := i.confirmations(ctx, resp.Invoices); err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-// InvoicesPending will return all currently stored invoices.
+// confirmations sets the confirmations of invoices whose payments have been mined, the
+// chain tip is only read when there are some.
+func (i *invoice) confirmations(ctx context.Context, ii []payd.Invoice) error {
+	var tip *payd.ChainTip
+	for n := range ii {
+		if !ii[n].BlockHeight.Valid {
+			continue
+		}
+		if tip == nil {
+			var err error
+			if tip, err = i.tipRdr.ChainTip(ctx); err != nil {
+				return errors.WithMessage(err, "failed to get chain tip for invoice confirmations")
+			}
+		}
+		ii[n].Confirmations = tip.Confirmations(uint32(ii[n].BlockHeight.Int64))
+	}
+	return nil
+}
+
+// InvoicesPending will return all invoices that are waiting to be paid.
 func (i *invoice) InvoicesPending(ctx context.Context) ([]payd.Invoice, error) {
-	ii, err := i.store.Invoices(ctx)
+	ii, err := i.store.InvoicesPending(ctx)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed to get invoices")
 	}
@@ -126,6 +175,9 @@ func (i *invoice) Create(ctx context.Context, req payd.InvoiceCreate) (*payd.Inv
 	}); err != nil {
 		return nil, errors.Wrapf(err, "failed to create payment destinations for invoice")
 	}
+	if err := i.webhooks.Publish(ctx, payd.WebhookEventInvoiceCreated, inv); err != nil {
+		return nil, errors.WithMessage(err, "failed to publish invoice created event")
+	}
 	if err := i.transacter.Commit(ctx); err != nil {
 		return nil, errors.WithStack(err)
 	}
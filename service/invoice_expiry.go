@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/log"
+)
+
+type invoiceExpiry struct {
+	l        log.Logger
+	store    payd.InvoiceWriter
+	notifier payd.InvoiceExpiryNotifier
+	webhooks payd.WebhookPublisher
+	timeSvc  payd.TimestampService
+}
+
+// NewInvoiceExpiry will setup and return a new invoice expiry service, used to sweep
+// invoices that haven't been paid by their expiry date.
+func NewInvoiceExpiry(l log.Logger, store payd.InvoiceWriter, notifier payd.InvoiceExpiryNotifier, webhooks payd.WebhookPublisher, timeSvc payd.TimestampService) *invoiceExpiry {
+	return &invoiceExpiry{
+		l:        l,
+		store:    store,
+		notifier: notifier,
+		webhooks: webhooks,
+		timeSvc:  timeSvc,
+	}
+}
+
+// InvoicesExpire will move all pending or partially paid invoices that are past their expiry
+// date to the expired state, releasing their destinations, and notify that each has expired.
+// An invoice.expired event is published for each invoice.
+func (i *invoiceExpiry) InvoicesExpire(ctx context.Context) ([]payd.Invoice, error) {
+	ii, err := i.store.InvoicesExpire(ctx, payd.InvoicesExpireArgs{ExpiresBefore: i.timeSvc.NowUTC()})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to expire invoices")
+	}
+	// the invoices are expired now, so a failed notification is logged and the rest still notified.
+	for _, inv := range ii {
+		i.l.Infof("invoice %s has expired", inv.ID)
+		if err := i.notifier.InvoiceExpired(ctx, inv); err != nil {
+			i.l.Errorf(err, "failed to notify expiry of invoice %s", inv.ID)
+		}
+		if err := i.webhooks.Publish(ctx, payd.WebhookEventInvoiceExpired, inv); err != nil {
+			i.l.Errorf(err, "failed to publish expiry of invoice %s", inv.ID)
+		}
+	}
+	return ii, nil
+}
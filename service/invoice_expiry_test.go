@@ -0,0 +1,102 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestInvoiceExpiryService_InvoicesExpire(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		expireFunc  func(context.Context, payd.InvoicesExpireArgs) ([]payd.Invoice, error)
+		notifyFunc  func(context.Context, payd.Invoice) error
+		expInvoices []payd.Invoice
+		expNotified []string
+		expErr      error
+	}{
+		"expired invoices are notified": {
+			expireFunc: func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+				return []payd.Invoice{
+					{ID: "abc123", State: payd.StateInvoiceExpired},
+					{ID: "def456", State: payd.StateInvoiceExpired},
+				}, nil
+			},
+			notifyFunc: func(context.Context, payd.Invoice) error {
+				return nil
+			},
+			expInvoices: []payd.Invoice{
+				{ID: "abc123", State: payd.StateInvoiceExpired},
+				{ID: "def456", State: payd.StateInvoiceExpired},
+			},
+			expNotified: []string{"abc123", "def456"},
+		},
+		"no overdue invoices notifies nothing": {
+			expireFunc: func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+				return nil, nil
+			},
+		},
+		"notify error doesn't stop the others being notified": {
+			expireFunc: func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+				return []payd.Invoice{{ID: "abc123"}, {ID: "def456"}}, nil
+			},
+			notifyFunc: func(ctx context.Context, inv payd.Invoice) error {
+				if inv.ID == "abc123" {
+					return errors.New("socket closed")
+				}
+				return nil
+			},
+			expInvoices: []payd.Invoice{{ID: "abc123"}, {ID: "def456"}},
+			expNotified: []string{"abc123", "def456"},
+		},
+		"store error is reported": {
+			expireFunc: func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+				return nil, errors.New("db locked")
+			},
+			expErr: errors.New("failed to expire invoices: db locked"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &mocks.InvoiceReaderWriterMock{
+				InvoicesExpireFunc: func(ctx context.Context, args payd.InvoicesExpireArgs) ([]payd.Invoice, error) {
+					assert.Equal(t, now, args.ExpiresBefore)
+					return test.expireFunc(ctx, args)
+				},
+			}
+			notifier := &mocks.InvoiceExpiryNotifierMock{InvoiceExpiredFunc: test.notifyFunc}
+			svc := service.NewInvoiceExpiry(log.Noop{}, store, notifier, &mocks.WebhookPublisherMock{
+				PublishFunc: func(context.Context, payd.WebhookEvent, interface{}) error {
+					return nil
+				},
+			}, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+
+			ii, err := svc.InvoicesExpire(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, notifier.InvoiceExpiredCalls())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expInvoices, ii)
+			var notified []string
+			for _, c := range notifier.InvoiceExpiredCalls() {
+				notified = append(notified, c.Inv.ID)
+			}
+			assert.Equal(t, test.expNotified, notified)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package service_test
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -19,16 +20,43 @@ import (
 func TestInvoiceService_Invoice(t *testing.T) {
 	tests := map[string]struct {
 		invoiceFunc func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error)
+		tipFunc     func(context.Context) (*payd.ChainTip, error)
 		args        payd.InvoiceArgs
+		expInvoice  *payd.Invoice
 		expErr      error
 	}{
 		"successful invoice get": {
 			invoiceFunc: func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error) {
-				return nil, nil
+				return &payd.Invoice{ID: "abc123"}, nil
+			},
+			args: payd.InvoiceArgs{
+				InvoiceID: "abc123",
+			},
+			expInvoice: &payd.Invoice{ID: "abc123"},
+		},
+		"mined invoice has its confirmations set": {
+			invoiceFunc: func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: "abc123", BlockHeight: null.IntFrom(100)}, nil
+			},
+			tipFunc: func(context.Context) (*payd.ChainTip, error) {
+				return &payd.ChainTip{Height: 105}, nil
 			},
 			args: payd.InvoiceArgs{
 				InvoiceID: "abc123",
 			},
+			expInvoice: &payd.Invoice{ID: "abc123", BlockHeight: null.IntFrom(100), Confirmations: 6},
+		},
+		"chain tip error is reported": {
+			invoiceFunc: func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: "abc123", BlockHeight: null.IntFrom(100)}, nil
+			},
+			tipFunc: func(context.Context) (*payd.ChainTip, error) {
+				return nil, errors.New("whoopsie")
+			},
+			args: payd.InvoiceArgs{
+				InvoiceID: "abc123",
+			},
+			expErr: errors.New("failed to get chain tip for invoice confirmations: whoopsie"),
 		},
 		"invalid invoice args rejected": {
 			invoiceFunc: func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error) {
@@ -51,8 +79,10 @@ func TestInvoiceService_Invoice(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			svc := service.NewInvoice(nil, nil, &mocks.InvoiceReaderWriterMock{
 				InvoiceFunc: test.invoiceFunc,
-			}, nil, nil, nil)
-			_, err := svc.Invoice(context.TODO(), test.args)
+			}, nil, nil, nil, nil, &mocks.ChainTipReaderMock{
+				ChainTipFunc: test.tipFunc,
+			})
+			inv, err := svc.Invoice(context.TODO(), test.args)
 			if test.expErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, test.expErr.Error())
@@ -60,34 +90,84 @@ func TestInvoiceService_Invoice(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
+			assert.Equal(t, test.expInvoice, inv)
 		})
 	}
 }
 
 func TestInvoiceService_Invoices(t *testing.T) {
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	invoices := func(n int) []payd.Invoice {
+		ii := make([]payd.Invoice, 0, n)
+		for i := 0; i < n; i++ {
+			ii = append(ii, payd.Invoice{
+				ID:       fmt.Sprintf("inv%d", i),
+				MetaData: payd.MetaData{CreatedAt: created.Add(time.Duration(i) * time.Minute)},
+			})
+		}
+		return ii
+	}
 	tests := map[string]struct {
-		invoicesFunc func(context.Context) ([]payd.Invoice, error)
+		invoicesFunc func(context.Context, payd.InvoiceSearchArgs) ([]payd.Invoice, error)
+		args         payd.InvoiceSearchArgs
+		expArgs      payd.InvoiceSearchArgs
+		expPage      *payd.InvoicePage
 		expErr       error
 	}{
-		"successful invoices get": {
-			invoicesFunc: func(context.Context) ([]payd.Invoice, error) {
+		"successful invoices get uses the default limit": {
+			invoicesFunc: func(context.Context, payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
 				return nil, nil
 			},
+			expArgs: payd.InvoiceSearchArgs{Limit: payd.InvoiceSearchDefaultLimit + 1},
+			expPage: &payd.InvoicePage{Invoices: []payd.Invoice{}},
+		},
+		"last page has no next cursor": {
+			invoicesFunc: func(context.Context, payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
+				return invoices(2), nil
+			},
+			args:    payd.InvoiceSearchArgs{Limit: 2, State: payd.StateInvoicePaid},
+			expArgs: payd.InvoiceSearchArgs{Limit: 3, State: payd.StateInvoicePaid},
+			expPage: &payd.InvoicePage{Invoices: invoices(2)},
+		},
+		"extra invoice is trimmed and returned as the next cursor": {
+			invoicesFunc: func(context.Context, payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
+				return invoices(3), nil
+			},
+			args:    payd.InvoiceSearchArgs{Limit: 2},
+			expArgs: payd.InvoiceSearchArgs{Limit: 3},
+			expPage: &payd.InvoicePage{
+				Invoices:   invoices(2),
+				NextCursor: payd.InvoiceCursor{CreatedAt: created.Add(time.Minute), InvoiceID: "inv1"}.String(),
+			},
+		},
+		"invalid args are rejected": {
+			args: payd.InvoiceSearchArgs{
+				State:       payd.StateInvoiceDeleted,
+				Sort:        "sideways",
+				Limit:       1000,
+				SatoshisMin: 2000,
+				SatoshisMax: 1000,
+			},
+			expErr: errors.New("[limit: value 1000 must be between 0 and 500], [satoshisMax: value 1000 is smaller than minimum 2000], [sort: value not found in allowed values], [state: value not found in allowed values]"),
 		},
 		"store error is reported": {
-			invoicesFunc: func(context.Context) ([]payd.Invoice, error) {
+			invoicesFunc: func(context.Context, payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
 				return nil, errors.New("whoopsie")
 			},
-			expErr: errors.New("failed to get invoices: whoopsie"),
+			expArgs: payd.InvoiceSearchArgs{Limit: payd.InvoiceSearchDefaultLimit + 1},
+			expErr:  errors.New("failed to get invoices: whoopsie"),
 		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			svc := service.NewInvoice(nil, nil, &mocks.InvoiceReaderWriterMock{
-				InvoicesFunc: test.invoicesFunc,
-			}, nil, nil, nil)
-			_, err := svc.Invoices(context.TODO())
+				InvoicesFunc: func(ctx context.Context, args payd.InvoiceSearchArgs) ([]payd.Invoice, error) {
+					assert.Equal(t, test.expArgs, args)
+					return test.invoicesFunc(ctx, args)
+				},
+			}, nil, nil, nil, nil, nil)
+			page, err := svc.Invoices(context.TODO(), test.args)
 			if test.expErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, test.expErr.Error())
@@ -95,6 +175,7 @@ func TestInvoiceService_Invoices(t *testing.T) {
 			}
 
 			assert.NoError(t, err)
+			assert.Equal(t, test.expPage, page)
 		})
 	}
 }
@@ -382,7 +463,12 @@ func TestInvoiceService_Create(t *testing.T) {
 				&mocks.TimestampServiceMock{
 					NanosecondFunc: test.nanosecondFunc,
 					NowUTCFunc:     test.nowUTCFunc,
-				})
+				},
+				&mocks.WebhookPublisherMock{
+					PublishFunc: func(context.Context, payd.WebhookEvent, interface{}) error {
+						return nil
+					},
+				}, nil)
 
 			ctx := session.WithUser(context.Background(), &payd.User{})
 			_, err := svc.Create(ctx, test.req)
@@ -431,7 +517,7 @@ func TestInvoiceService_Delete(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			svc := service.NewInvoice(nil, nil, &mocks.InvoiceReaderWriterMock{
 				InvoiceDeleteFunc: test.invoiceDeleteFunc,
-			}, nil, nil, nil)
+			}, nil, nil, nil, nil, nil)
 
 			if test.expErr != nil {
 				assert.EqualError(t, svc.Delete(context.TODO(), test.args), test.expErr.Error())
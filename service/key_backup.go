@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/bip39"
+	"github.com/libsv/go-bk/chaincfg"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/errcodes"
+)
+
+type keyBackup struct {
+	pks      payd.PrivateKeyService
+	derivRdr payd.DerivationReader
+	balRdr   payd.BalanceReader
+	chain    *chaincfg.Params
+	network  config.NetworkType
+}
+
+// NewKeyBackup will setup and return a new service for backing up and restoring private keys.
+func NewKeyBackup(pks payd.PrivateKeyService, derivRdr payd.DerivationReader, balRdr payd.BalanceReader, cfg *config.Wallet) *keyBackup {
+	chain := &chaincfg.TestNet
+	if cfg.Network == config.NetworkMainet {
+		chain = &chaincfg.MainNet
+	}
+	return &keyBackup{
+		pks:      pks,
+		derivRdr: derivRdr,
+		balRdr:   balRdr,
+		chain:    chain,
+		network:  cfg.Network,
+	}
+}
+
+// KeyMnemonicReveal returns the mnemonic a key was created from, it is deleted once revealed.
+func (k *keyBackup) KeyMnemonicReveal(ctx context.Context, args payd.KeyArgs) (*payd.KeyMnemonic, error) {
+	mnemonic, err := k.pks.MnemonicReveal(ctx, args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &payd.KeyMnemonic{Mnemonic: mnemonic}, nil
+}
+
+// KeyRestore replaces a key with one restored from a mnemonic or xprv.
+//
+// Destinations are derived from random paths, so rather than searching for funds the restored
+// key must derive every destination previously derived from the key it replaces. This stops a
+// mistyped mnemonic or passphrase replacing a key and stranding its funds.
+func (k *keyBackup) KeyRestore(ctx context.Context, args payd.KeyArgs, req payd.KeyRestore) (*payd.KeyRescan, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	var xprv *bip32.ExtendedKey
+	if req.Mnemonic != "" {
+		seed, err := mnemonicToSeed(req.Mnemonic, req.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if xprv, err = bip32.NewMaster(seed, k.chain); err != nil {
+			return nil, errors.Wrap(err, "failed to create master node for given seed and chain")
+		}
+	} else {
+		var err error
+		if xprv, err = bip32.NewKeyFromString(req.Xprv); err != nil || !xprv.IsPrivate() {
+			return nil, validator.NewSingleError("xprv", []string{"xprv is not a valid extended private key"})
+		}
+		if !xprv.IsForNet(k.chain) {
+			return nil, validator.NewSingleError("xprv", []string{fmt.Sprintf("xprv is not for %s", k.network)})
+		}
+	}
+	paths, err := k.paths(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	rescan, err := k.rescan(ctx, args, paths, xprv)
+	if err != nil {
+		return nil, err
+	}
+	if len(rescan.Mismatched) > 0 {
+		return nil, lathos.NewErrUnprocessable(errcodes.ErrKeyRestoreMismatch, fmt.Sprintf(
+			"restored key doesn't derive %d of the %d destinations previously derived from key %s, check the backup and passphrase",
+			len(rescan.Mismatched), rescan.Paths, args.Name))
+	}
+	if err := k.pks.PrivateKeyImport(ctx, args, xprv); err != nil {
+		return nil, errors.Wrapf(err, "failed to import restored key %s", args.Name)
+	}
+	return rescan, nil
+}
+
+// KeyRescan checks the key derives each destination previously derived from it and returns
+// the balance held by them. Hardened paths can only be checked with the private key, so the
+// wallet must be unlocked if the key has any.
+//
+// Only the derivation paths stored in the db are checked and the balance is that of the txos
+// already recorded against them, the chain isn't searched. Destinations are derived from random
+// paths so there is no gap limit to scan, a key restored without its db finds no funds.
+func (k *keyBackup) KeyRescan(ctx context.Context, args payd.KeyArgs) (*payd.KeyRescan, error) {
+	paths, err := k.paths(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	getKey := k.pks.PublicKey
+	for _, p := range paths {
+		if hardenedPath(p.Path) {
+			getKey = k.pks.PrivateKey
+			break
+		}
+	}
+	key, err := getKey(ctx, args.Name, args.UserID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return k.rescan(ctx, args, paths, key)
+}
+
+func (k *keyBackup) paths(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+	paths, err := k.derivRdr.DerivationPaths(ctx, args)
+	return paths, errors.Wrapf(err, "failed to get derivation paths for key %s", args.Name)
+}
+
+func (k *keyBackup) rescan(ctx context.Context, args payd.KeyArgs, paths []payd.DerivationPath, key *bip32.ExtendedKey) (*payd.KeyRescan, error) {
+	resp := &payd.KeyRescan{
+		Paths:      len(paths),
+		Mismatched: []string{},
+	}
+	for _, p := range paths {
+		pubKey, err := key.DerivePublicKeyFromPath(p.Path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to derive public key for path %s", p.Path)
+		}
+		s, err := bscript.NewP2PKHFromPubKeyBytes(pubKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create locking script for path %s", p.Path)
+		}
+		if s.String() != p.LockingScript {
+			resp.Mismatched = append(resp.Mismatched, p.Path)
+			continue
+		}
+		resp.Matched++
+	}
+	balance, err := k.balRdr.Balance(ctx, payd.BalanceArgs{
+		UserID:  null.IntFrom(int64(args.UserID)),
+		KeyName: args.Name,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get balance for key %s", args.Name)
+	}
+	resp.Balance = balance
+	return resp, nil
+}
+
+// mnemonicToSeed checks the mnemonic words and checksum before creating its seed, bip39.MnemonicToSeed
+// only checks the words are in the list.
+func mnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, validator.NewSingleError("mnemonic", []string{"mnemonic must have 12, 15, 18, 21 or 24 words"})
+	}
+	// each word encodes 11 bits, the last len(words)/3 bits are the checksum.
+	bits := make([]bool, 0, len(words)*11)
+	for _, w := range words {
+		idx := sort.SearchStrings(bip39.English, w)
+		if idx == len(bip39.English) || bip39.English[idx] != w {
+			return nil, validator.NewSingleError("mnemonic", []string{fmt.Sprintf("%s is not a BIP39 english word", w)})
+		}
+		for i := 10; i >= 0; i-- {
+			bits = append(bits, idx&(1<<i) != 0)
+		}
+	}
+	csLen := len(words) / 3
+	entropy := make([]byte, (len(bits)-csLen)/8)
+	for i := range entropy {
+		for j := 0; j < 8; j++ {
+			if bits[i*8+j] {
+				entropy[i] |= 1 << (7 - j)
+			}
+		}
+	}
+	checksum := sha256.Sum256(entropy)
+	for i := 0; i < csLen; i++ {
+		if bits[len(entropy)*8+i] != (checksum[0]&(1<<(7-i)) != 0) {
+			return nil, validator.NewSingleError("mnemonic", []string{"mnemonic checksum is invalid"})
+		}
+	}
+	seed, err := bip39.MnemonicToSeed(strings.Join(words, " "), passphrase)
+	return seed, errors.Wrap(err, "failed to create seed from mnemonic")
+}
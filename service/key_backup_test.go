@@ -0,0 +1,170 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/bip39"
+	"github.com/libsv/go-bk/chaincfg"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestKeyBackupService_KeyRestore(t *testing.T) {
+	const (
+		mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+		otherKey = "tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP"
+	)
+	seed, err := bip39.MnemonicToSeed(mnemonic, "")
+	assert.NoError(t, err)
+	master, err := bip32.NewMaster(seed, &chaincfg.TestNet)
+	assert.NoError(t, err)
+	script := func(path string) string {
+		pub, err := master.DerivePublicKeyFromPath(path)
+		assert.NoError(t, err)
+		s, err := bscript.NewP2PKHFromPubKeyBytes(pub)
+		assert.NoError(t, err)
+		return s.String()
+	}
+	paths := []payd.DerivationPath{
+		{Path: "0/1/2", LockingScript: script("0/1/2")},
+		{Path: "2147483647/5/9", LockingScript: script("2147483647/5/9")},
+	}
+	tests := map[string]struct {
+		req       payd.KeyRestore
+		paths     []payd.DerivationPath
+		expImport string
+		expRescan *payd.KeyRescan
+		expErr    error
+	}{
+		"mnemonic restoring the used paths is imported": {
+			req:       payd.KeyRestore{Mnemonic: "  Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon ABOUT "},
+			paths:     paths,
+			expImport: master.String(),
+			expRescan: &payd.KeyRescan{Paths: 2, Matched: 2, Mismatched: []string{}, Balance: &payd.Balance{Satoshis: 1000}},
+		},
+		"xprv restoring the used paths is imported": {
+			req:       payd.KeyRestore{Xprv: master.String()},
+			paths:     paths,
+			expImport: master.String(),
+			expRescan: &payd.KeyRescan{Paths: 2, Matched: 2, Mismatched: []string{}, Balance: &payd.Balance{Satoshis: 1000}},
+		},
+		"key with no used paths is imported": {
+			req:       payd.KeyRestore{Xprv: otherKey},
+			expImport: otherKey,
+			expRescan: &payd.KeyRescan{Mismatched: []string{}, Balance: &payd.Balance{Satoshis: 1000}},
+		},
+		"mnemonic with the wrong passphrase is rejected": {
+			req:    payd.KeyRestore{Mnemonic: mnemonic, Passphrase: "wrong"},
+			paths:  paths,
+			expErr: lathos.NewErrUnprocessable("K0001", "restored key doesn't derive 2 of the 2 destinations previously derived from key masterkey, check the backup and passphrase"),
+		},
+		"mnemonic with an invalid checksum is rejected": {
+			req:    payd.KeyRestore{Mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"},
+			expErr: errors.New("[mnemonic: mnemonic checksum is invalid]"),
+		},
+		"mnemonic with an unknown word is rejected": {
+			req:    payd.KeyRestore{Mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon zzz"},
+			expErr: errors.New("[mnemonic: zzz is not a BIP39 english word]"),
+		},
+		"mnemonic with too few words is rejected": {
+			req:    payd.KeyRestore{Mnemonic: "abandon about"},
+			expErr: errors.New("[mnemonic: mnemonic must have 12, 15, 18, 21 or 24 words]"),
+		},
+		"xpub is rejected": {
+			req:    payd.KeyRestore{Xprv: "tpubD6NzVbkrYhZ4WPxQEWLbnueqwj1kdLofAKjqutMjxprwNYThy49k6YJs5d4ZZ7Z7JMXaeCykmAqtUaEf9EQtMPchEQqYxcrtiJzaUiLgPSE"},
+			expErr: errors.New("[xprv: xprv is not a valid extended private key]"),
+		},
+		"mainnet xprv is rejected": {
+			req:    payd.KeyRestore{Xprv: "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+			expErr: errors.New("[xprv: xprv is not for testnet]"),
+		},
+		"mnemonic and xprv are rejected": {
+			req:    payd.KeyRestore{Mnemonic: mnemonic, Xprv: otherKey},
+			expErr: errors.New("[xprv: an xprv can't be supplied with a mnemonic]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var imported string
+			svc := service.NewKeyBackup(
+				&mocks.PrivateKeyServiceMock{
+					PrivateKeyImportFunc: func(ctx context.Context, args payd.KeyArgs, xprv *bip32.ExtendedKey) error {
+						imported = xprv.String()
+						return nil
+					},
+				},
+				&mocks.DerivationReaderMock{
+					DerivationPathsFunc: func(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+						return test.paths, nil
+					},
+				},
+				&mocks.BalanceReaderMock{
+					BalanceFunc: func(ctx context.Context, args payd.BalanceArgs) (*payd.Balance, error) {
+						assert.Equal(t, "masterkey", args.KeyName)
+						assert.Equal(t, int64(1), args.UserID.Int64)
+						return &payd.Balance{Satoshis: 1000}, nil
+					},
+				},
+				&config.Wallet{Network: config.NetworkTestnet},
+			)
+			rescan, err := svc.KeyRestore(context.Background(), payd.KeyArgs{Name: "masterkey", UserID: 1}, test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expRescan, rescan)
+			assert.Equal(t, test.expImport, imported)
+		})
+	}
+}
+
+func TestKeyBackupService_KeyRescan(t *testing.T) {
+	xprv, err := bip32.NewKeyFromString("tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP")
+	assert.NoError(t, err)
+	xpub, err := xprv.Neuter()
+	assert.NoError(t, err)
+	pub, err := xpub.DerivePublicKeyFromPath("0/1/2")
+	assert.NoError(t, err)
+	s, err := bscript.NewP2PKHFromPubKeyBytes(pub)
+	assert.NoError(t, err)
+
+	svc := service.NewKeyBackup(
+		&mocks.PrivateKeyServiceMock{
+			PublicKeyFunc: func(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+				return xpub, nil
+			},
+		},
+		&mocks.DerivationReaderMock{
+			DerivationPathsFunc: func(ctx context.Context, args payd.KeyArgs) ([]payd.DerivationPath, error) {
+				return []payd.DerivationPath{
+					{Path: "0/1/2", LockingScript: s.String()},
+					{Path: "0/1/3", LockingScript: s.String()},
+				}, nil
+			},
+		},
+		&mocks.BalanceReaderMock{
+			BalanceFunc: func(ctx context.Context, args payd.BalanceArgs) (*payd.Balance, error) {
+				return &payd.Balance{Satoshis: 500}, nil
+			},
+		},
+		&config.Wallet{},
+	)
+	rescan, err := svc.KeyRescan(context.Background(), payd.KeyArgs{Name: "masterkey", UserID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, &payd.KeyRescan{
+		Paths:      2,
+		Matched:    1,
+		Mismatched: []string{"0/1/3"},
+		Balance:    &payd.Balance{Satoshis: 500},
+	}, rescan)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+// splitSatoshis returns the output amounts satoshis is split into, every amount is at
+// least the dust limit and together they total satoshis. Random amounts are drawn from
+// the seed service.
+func splitSatoshis(split payd.OutputSplit, satoshis, denomination uint64, maxOutputs int, seed payd.SeedService) ([]uint64, error) {
+	// never split into outputs below the dust limit.
+	if n := satoshis / payd.DustLimit; uint64(maxOutputs) > n {
+		maxOutputs = int(n)
+	}
+	if maxOutputs <= 1 {
+		return []uint64{satoshis}, nil
+	}
+	switch split {
+	case payd.OutputSplitDenomination:
+		return splitDenomination(satoshis, denomination, maxOutputs), nil
+	case payd.OutputSplitEven:
+		return splitEven(satoshis, maxOutputs), nil
+	case payd.OutputSplitRandom:
+		return splitRandom(satoshis, maxOutputs, seed)
+	}
+	return []uint64{satoshis}, nil
+}
+
+// splitDenomination returns outputs of denomination, the remainder is added as another
+// output or, if it is dust, to the last output. Outputs beyond maxOutputs are merged
+// into the last output.
+func splitDenomination(satoshis, denomination uint64, maxOutputs int) []uint64 {
+	if denomination == 0 || satoshis <= denomination {
+		return []uint64{satoshis}
+	}
+	n := int(satoshis / denomination)
+	if n > maxOutputs {
+		n = maxOutputs
+	}
+	amounts := make([]uint64, n)
+	for i := range amounts {
+		amounts[i] = denomination
+	}
+	remainder := satoshis - denomination*uint64(n)
+	if remainder >= payd.DustLimit && n < maxOutputs {
+		return append(amounts, remainder)
+	}
+	amounts[n-1] += remainder
+	return amounts
+}
+
+// splitEven returns maxOutputs outputs of equal value, the last output takes the remainder.
+func splitEven(satoshis uint64, maxOutputs int) []uint64 {
+	amounts := make([]uint64, maxOutputs)
+	each := satoshis / uint64(maxOutputs)
+	for i := range amounts {
+		amounts[i] = each
+	}
+	amounts[maxOutputs-1] += satoshis - each*uint64(maxOutputs)
+	return amounts
+}
+
+// splitRandom returns between 2 and maxOutputs outputs of random amounts. Each output is
+// given the dust limit and what is left is split at random points.
+func splitRandom(satoshis uint64, maxOutputs int, seed payd.SeedService) ([]uint64, error) {
+	r, err := seed.Uint64()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create seed for output count")
+	}
+	n := 2 + int(r%uint64(maxOutputs-1))
+	spare := satoshis - payd.DustLimit*uint64(n)
+	cuts := make([]uint64, 0, n+1)
+	cuts = append(cuts, 0, spare)
+	for i := 0; i < n-1; i++ {
+		r, err := seed.Uint64()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create seed for output amount")
+		}
+		cuts = append(cuts, r%(spare+1))
+	}
+	sort.Slice(cuts, func(i, j int) bool {
+		return cuts[i] < cuts[j]
+	})
+	amounts := make([]uint64, n)
+	for i := range amounts {
+		amounts[i] = payd.DustLimit + cuts[i+1] - cuts[i]
+	}
+	return amounts, nil
+}
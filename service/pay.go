@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/libsv/go-bk/bip32"
 	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/go-bt/v2/bscript"
@@ -21,6 +22,7 @@ import (
 type pay struct {
 	storeTx    payd.Transacter
 	txWtr      payd.TransactionWriter
+	txoWtr     payd.TxoWriter
 	dpp        http.DPP
 	spvc       payd.EnvelopeService
 	pcStr      payd.PeerChannelsStore
@@ -30,10 +32,11 @@ type pay struct {
 }
 
 // NewPayService returns a pay service.
-func NewPayService(storeTx payd.Transacter, dpp http.DPP, spvc payd.EnvelopeService, svrCfg *config.Server, pcNotifSvc payd.PeerChannelsNotifyService, pcStr payd.PeerChannelsStore, txWtr payd.TransactionWriter, walletCfg *config.Wallet) payd.PayService {
+func NewPayService(storeTx payd.Transacter, dpp http.DPP, spvc payd.EnvelopeService, svrCfg *config.Server, pcNotifSvc payd.PeerChannelsNotifyService, pcStr payd.PeerChannelsStore, txWtr payd.TransactionWriter, txoWtr payd.TxoWriter, walletCfg *config.Wallet) payd.PayService {
 	return &pay{
 		storeTx:    storeTx,
 		txWtr:      txWtr,
+		txoWtr:     txoWtr,
 		dpp:        dpp,
 		spvc:       spvc,
 		svrCfg:     svrCfg,
@@ -95,12 +98,30 @@ func (p *pay) Pay(ctx context.Context, req payd.PayRequest) (*dpp.PaymentACK, er
 				fmt.Sprintf("amount requested %d satoshis is larger than our max payout of %d satoshis", s, p.walletCfg.PayoutLimitSatoshis))
 		}
 	}
+	// each attempt reserves its utxos under its own key, the url with the attempt as its fragment,
+	// so a failed attempt never releases or spends the utxos of another attempt to pay the url.
+	reservedFor := req.PayToURL + "#" + uuid.NewString()
+	ack, err := p.pay(ctx, req, reservedFor, payReq)
+	if err != nil {
+		// the tx is rolled back by now, releasing any utxos reserved within it, this also releases
+		// those of this attempt reserved outside of it rather than holding them until the reservation expires.
+		if e := p.txoWtr.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: reservedFor}); e != nil {
+			log.Error().Err(e).Msgf("failed to release utxos reserved for failed payment %s", reservedFor)
+		}
+		return nil, err
+	}
+	return ack, nil
+}
+
+// pay funds and sends the payment requested, storing the tx and any peer channel
+// for its proof in a single db transaction. The utxos funding it are reserved for reservedFor.
+func (p *pay) pay(ctx context.Context, req payd.PayRequest, reservedFor string, payReq *dpp.PaymentRequest) (*dpp.PaymentACK, error) {
 	// begin a transaction that can be picked up by other services etc for rollbacks on failure.
 	ctx = p.storeTx.WithTx(ctx)
 	defer func() {
 		_ = p.storeTx.Rollback(ctx)
 	}()
-	env, err := p.spvc.Envelope(ctx, payd.EnvelopeArgs{PayToURL: req.PayToURL}, *payReq)
+	env, err := p.spvc.Envelope(ctx, payd.EnvelopeArgs{PayToURL: reservedFor, CoinSelection: req.CoinSelection}, *payReq)
 	if err != nil {
 		return nil, errors.Wrapf(err, "envelope creation failed for '%s'", req.PayToURL)
 	}
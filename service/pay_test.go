@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -373,6 +374,8 @@ func TestPayService_Pay(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			var unreserved bool
+			var reservedFor string
 			svc := service.NewPayService(
 				&mocks.TransacterMock{
 					WithTxFunc: func(ctx context.Context) context.Context {
@@ -393,7 +396,13 @@ func TestPayService_Pay(t *testing.T) {
 						return test.paymentSendFunc(ctx, req, args)
 					},
 				},
-				&mocks.EnvelopeServiceMock{EnvelopeFunc: test.envelopeFunc},
+				&mocks.EnvelopeServiceMock{
+					EnvelopeFunc: func(ctx context.Context, args payd.EnvelopeArgs, req dpp.PaymentRequest) (*spv.Envelope, error) {
+						assert.True(t, strings.HasPrefix(args.PayToURL, test.req.PayToURL+"#"))
+						reservedFor = args.PayToURL
+						return test.envelopeFunc(ctx, args, req)
+					},
+				},
 				&config.Server{Hostname: "myserver"},
 				&mocks.PeerChannelsNotifyServiceMock{
 					SubscribeFunc: func(ctx context.Context, args *payd.PeerChannel) error {
@@ -413,6 +422,14 @@ func TestPayService_Pay(t *testing.T) {
 						return nil
 					},
 				},
+				&mocks.TxoWriterMock{
+					UTXOUnreserveFunc: func(ctx context.Context, req payd.UTXOUnreserve) error {
+						// only the utxos reserved by this attempt are released.
+						assert.Equal(t, reservedFor, req.ReservedFor)
+						unreserved = true
+						return nil
+					},
+				},
 				test.walletConfig,
 			)
 
@@ -423,6 +440,7 @@ func TestPayService_Pay(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 			}
+			assert.Equal(t, test.expUTXOUnreserve, unreserved)
 		})
 	}
 }
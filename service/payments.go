@@ -35,11 +35,12 @@ type payments struct {
 	pcStr         payd.PeerChannelsStore
 	pcNotif       payd.PeerChannelsNotifyService
 	feeRdr        payd.FeeQuoteReader
+	webhooks      payd.WebhookPublisher
 	pCfg          *config.PeerChannels
 }
 
 // NewPayments will setup and return a payments service.
-func NewPayments(l log.Logger, paymentVerify spv.PaymentVerifier, txWtr payd.TransactionWriter, invRdr payd.InvoiceReaderWriter, destRdr payd.DestinationsReader, transacter payd.Transacter, broadcaster payd.BroadcastWriter, feeRdr payd.FeeQuoteReader, callbackWtr payd.ProofCallbackWriter, pcSvc payd.PeerChannelsService, pcNotif payd.PeerChannelsNotifyService, pCfg *config.PeerChannels) payd.PaymentsService {
+func NewPayments(l log.Logger, paymentVerify spv.PaymentVerifier, txWtr payd.TransactionWriter, invRdr payd.InvoiceReaderWriter, destRdr payd.DestinationsReader, transacter payd.Transacter, broadcaster payd.BroadcastWriter, feeRdr payd.FeeQuoteReader, callbackWtr payd.ProofCallbackWriter, pcSvc payd.PeerChannelsService, pcNotif payd.PeerChannelsNotifyService, webhooks payd.WebhookPublisher, pCfg *config.PeerChannels) payd.PaymentsService {
 	svc := &payments{
 		l:             l,
 		paymentVerify: paymentVerify,
@@ -52,6 +53,7 @@ func NewPayments(l log.Logger, paymentVerify spv.PaymentVerifier, txWtr payd.Tra
 		callbackWtr:   callbackWtr,
 		pcSvc:         pcSvc,
 		pcNotif:       pcNotif,
+		webhooks:      webhooks,
 		pCfg:          pCfg,
 	}
 	return svc
@@ -69,16 +71,17 @@ func (p *payments) PaymentCreate(ctx context.Context, args payd.PaymentCreateArg
 		_ = p.transacter.Rollback(ctx)
 	}()
 	p.l.Debugf("checking invoice for payment %s", args.InvoiceID)
-	// Check tx pays enough to cover invoice and that invoice hasn't been paid already
+	// Check the invoice hasn't been paid in full already, partially paid invoices can take further payments.
 	inv, err := p.invRdr.Invoice(ctx, payd.InvoiceArgs{InvoiceID: args.InvoiceID})
 	if err != nil || inv.State == "" {
 		return nil, errors.Wrapf(err, "failed to get invoice with ID '%s'", args.InvoiceID)
 	}
-	if inv.State != payd.StateInvoicePending {
+	if inv.State != payd.StateInvoicePending && inv.State != payd.StateInvoicePartiallyPaid && inv.State != payd.StateInvoiceExpired {
 		p.l.Debugf("invoice for payment %s is a duplicate", args.InvoiceID)
 		return nil, errs.NewErrDuplicate("D001", fmt.Sprintf("payment already received for invoice ID '%s'", args.InvoiceID))
 	}
-	if !inv.ExpiresAt.ValueOrZero().IsZero() && inv.ExpiresAt.Time.Before(time.Now().UTC()) {
+	if inv.State == payd.StateInvoiceExpired ||
+		!inv.ExpiresAt.ValueOrZero().IsZero() && inv.ExpiresAt.Time.Before(time.Now().UTC()) {
 		p.l.Debugf("invoice for payment %s has expired", args.InvoiceID)
 		return nil, errs.NewErrUnprocessable("E001", "invoice you are attempting to pay has expired")
 	}
@@ -140,44 +143,51 @@ func (p *payments) PaymentCreate(ctx context.Context, args payd.PaymentCreateArg
 		outputs[o.LockingScript.String()] = o
 	}
 	txos := make([]*payd.TxoCreate, 0)
-	// get total of outputs that we know about
+	// get total of outputs that we know about, an invoice can be paid in instalments
+	// so outputs can pay less than the destination amount and not every destination
+	// has to be paid by a single tx, but no destination can be paid more than is outstanding.
 	txID := tx.TxID()
-	// TODO: simple dust limit check
-	p.l.Debugf("dust limit check for payment %s", args.InvoiceID)
+	paying := map[string]uint64{}
+	// invoice satoshis can be split across several destinations, every output paying
+	// one is checked against the amount split to it.
 	for i, o := range tx.Outputs {
 		if output, ok := outputs[o.LockingScript.String()]; ok {
-			if o.Satoshis != output.Satoshis {
+			if o.Satoshis < payd.DustLimit {
+				return nil, validator.ErrValidation{
+					"transaction": {
+						fmt.Sprintf("output %d pays %d satoshis to an invoice destination, below the dust limit of %d", i, o.Satoshis, payd.DustLimit),
+					},
+				}
+			}
+			received := output.SatoshisReceived + paying[o.LockingScript.String()]
+			if received+o.Satoshis > output.Satoshis {
+				var outstanding uint64
+				if received < output.Satoshis {
+					outstanding = output.Satoshis - received
+				}
 				return nil, validator.ErrValidation{
 					"tx.outputs": {
-						"output satoshis do not match requested amount",
+						fmt.Sprintf("output %d pays %d satoshis to a destination with %d satoshis outstanding", i, o.Satoshis, outstanding),
 					},
 				}
 			}
-
-			total += output.Satoshis
+			paying[o.LockingScript.String()] += o.Satoshis
+			total += o.Satoshis
 			txos = append(txos, &payd.TxoCreate{
 				Outpoint:      fmt.Sprintf("%s%d", txID, i),
 				DestinationID: output.ID,
 				TxID:          txID,
 				Vout:          uint64(i),
+				Satoshis:      o.Satoshis,
 			})
-			delete(outputs, o.LockingScript.String())
 		}
 	}
-	// fail if tx doesn't pay invoice in full
-	if total < inv.Satoshis {
-		p.l.Debugf("tx doesn't pay invoice in full for payment %s", args.InvoiceID)
+	// fail if tx doesn't pay anything towards the invoice
+	if total == 0 {
+		p.l.Debugf("tx doesn't pay invoice destinations for payment %s", args.InvoiceID)
 		return nil, validator.ErrValidation{
 			"transaction": {
-				"tx does not pay enough to cover invoice, ensure all outputs are included, the correct destinations are used and try again",
-			},
-		}
-	}
-	if len(outputs) > 0 {
-		p.l.Debugf("not all destinations supplied for payment %s", args.InvoiceID)
-		return nil, validator.ErrValidation{
-			"tx.outputs": {
-				fmt.Sprintf("expected '%d' outputs, received '%d', ensure all destinations are supplied", len(oo), len(tx.Outputs)),
+				"tx does not pay any of the invoice destinations, ensure the correct destinations are used and try again",
 			},
 		}
 	}
@@ -248,7 +258,7 @@ func (p *payments) PaymentCreate(ctx context.Context, args payd.PaymentCreateArg
 	// Store callbacks if we have any
 	if len(req.ProofCallbacks) > 0 {
 		p.l.Debugf("creating proof callbacks for payment %s", args.InvoiceID)
-		if err := p.callbackWtr.ProofCallBacksCreate(ctx, payd.ProofCallbackArgs{InvoiceID: args.InvoiceID}, req.ProofCallbacks); err != nil {
+		if err := p.callbackWtr.ProofCallBacksCreate(ctx, payd.ProofCallbackArgs{InvoiceID: args.InvoiceID, TxID: txID}, req.ProofCallbacks); err != nil {
 			return nil, errors.Wrapf(err, "failed to store proof callbacks for invoiceID '%s'", args.InvoiceID)
 		}
 	}
@@ -269,10 +279,8 @@ func (p *payments) PaymentCreate(ctx context.Context, args payd.PaymentCreateArg
 		CallbackURL: callbackURL.String(),
 		Token:       "Bearer " + tokens[0].Token,
 	}, tx); err != nil {
-		// set as failed
-		if err := p.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: txID}, payd.TransactionStateUpdate{State: payd.StateTxFailed}); err != nil {
-			p.l.Error(err, "failed to update tx after failed broadcast")
-		}
+		// the payment is rolled back so the tx is never stored, the payer is told by the error
+		// and there is no tx to send a tx.failed event for.
 		return nil, errors.Wrap(err, "failed to broadcast tx")
 	}
 
@@ -292,8 +300,11 @@ func (p *payments) PaymentCreate(ctx context.Context, args payd.PaymentCreateArg
 	if err := p.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: txID}, payd.TransactionStateUpdate{State: payd.StateTxBroadcast}); err != nil {
 		p.l.Error(err, "failed to update tx to broadcast state")
 	}
-	// set invoice as paid
-	if _, err := p.invRdr.InvoiceUpdate(ctx, payd.InvoiceUpdateArgs{InvoiceID: args.InvoiceID}, payd.InvoiceUpdatePaid{
+	if err := p.webhooks.Publish(ctx, payd.WebhookEventTxBroadcast, payd.WebhookTxEvent{TxID: txID, InvoiceID: inv.ID}); err != nil {
+		p.l.Error(err, "failed to publish tx broadcast event")
+	}
+	// record the payment, the invoice is set as paid once the payments cover it.
+	paid, err := p.invRdr.InvoiceUpdate(ctx, payd.InvoiceUpdateArgs{InvoiceID: args.InvoiceID}, payd.InvoiceUpdatePaid{
 		PaymentReceivedAt: time.Now().UTC(),
 		RefundTo: func() string {
 			if req.RefundTo == nil {
@@ -301,8 +312,16 @@ func (p *payments) PaymentCreate(ctx context.Context, args payd.PaymentCreateArg
 			}
 			return *req.RefundTo
 		}(),
-	}); err != nil {
-		p.l.Error(err, "failed to update invoice to paid")
+		TxID:     txID,
+		Satoshis: total,
+	})
+	switch {
+	case err != nil:
+		p.l.Error(err, "failed to record payment against invoice")
+	case paid.State == payd.StateInvoicePaid:
+		if err := p.webhooks.Publish(ctx, payd.WebhookEventInvoicePaid, paid); err != nil {
+			p.l.Error(err, "failed to publish invoice paid event")
+		}
 	}
 
 	if err := p.transacter.Commit(ctx); err != nil {
@@ -344,7 +363,13 @@ func (p *payments) Ack(ctx context.Context, args payd.AckArgs, req payd.Ack) err
 		return errors.Wrap(err, "failed to update transaction state after payment ack")
 	}
 	if req.Failed {
-		return nil
+		if err := p.webhooks.Publish(ctx, payd.WebhookEventTxFailed, payd.WebhookTxEvent{TxID: args.TxID, FailReason: req.Reason}); err != nil {
+			return errors.WithMessage(err, "failed to publish tx failed event")
+		}
+		return errors.Wrap(p.transacter.Commit(ctx), "failed to commit data store when acking")
+	}
+	if err := p.webhooks.Publish(ctx, payd.WebhookEventTxBroadcast, payd.WebhookTxEvent{TxID: args.TxID}); err != nil {
+		return errors.WithMessage(err, "failed to publish tx broadcast event")
 	}
 
 	if err := p.pcStr.PeerChannelCreate(ctx, &payd.PeerChannelCreateArgs{
@@ -39,6 +39,7 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 		expVerifyOpts           []spv.VerifyOpt
 		expRawTx                string
 		expTxState              payd.TxState
+		expPaidSatoshis         uint64
 		expErr                  error
 	}{
 		"successful create": {
@@ -84,9 +85,10 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					return &s
 				}(),
 			},
-			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
-			expRawTx:      "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
-			expTxState:    payd.StateTxBroadcast,
+			expVerifyOpts:   []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expRawTx:        "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
+			expTxState:      payd.StateTxBroadcast,
+			expPaidSatoshis: 1000,
 		},
 		"successful create with spv verification": {
 			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
@@ -131,9 +133,10 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					return &s
 				}(),
 			},
-			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.VerifySPV()},
-			expRawTx:      "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
-			expTxState:    payd.StateTxBroadcast,
+			expVerifyOpts:   []spv.VerifyOpt{spv.VerifyFees(fq), spv.VerifySPV()},
+			expRawTx:        "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
+			expTxState:      payd.StateTxBroadcast,
+			expPaidSatoshis: 1000,
 		},
 		"invalid request is rejected": {
 			req:    dpp.Payment{},
@@ -243,9 +246,9 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
 			expErr:        errors.New("failed to get destinations with ID 'abc123': destinations unknown"),
 		},
-		"mismatch in satoshis tx output/destination satoshis is rejected": {
+		"output paying more than its destination is rejected": {
 			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
-				return &payd.Invoice{ID: args.InvoiceID, State: payd.StateInvoicePending}, nil
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 1000, State: payd.StateInvoicePending}, nil
 			},
 			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
 				return fq, nil
@@ -259,6 +262,102 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 						s, _ := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
 						return s
 					}(),
+					DerivationPath:   "2147483648/2147483648/2147483648",
+					Satoshis:         1000,
+					SatoshisReceived: 0,
+					State:            "pending",
+				}}, nil
+			},
+			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
+			req: dpp.Payment{
+				RawTx: func() *string {
+					s := "010000000001e9030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+					return &s
+				}(),
+			},
+			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expErr:        errors.New("[tx.outputs: output 0 pays 1001 satoshis to a destination with 1000 satoshis outstanding]"),
+		},
+		"same destination cannot be paid to twice": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 1000, State: payd.StateInvoicePending}, nil
+			},
+			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+				return fq, nil
+			},
+			verifyPaymentFunc: func(context.Context, *bt.Tx, []byte, ...spv.VerifyOpt) (*bt.Tx, error) {
+				return bt.NewTxFromString("010000000002e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ace8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000")
+			},
+			destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
+				return []payd.Output{{
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
+						return s
+					}(),
+					DerivationPath:   "2147483648/2147483648/2147483648",
+					Satoshis:         1000,
+					SatoshisReceived: 0,
+					State:            "pending",
+				}}, nil
+			},
+			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
+			req: dpp.Payment{
+				RawTx: func() *string {
+					s := "010000000002e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ace8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+					return &s
+				}(),
+			},
+			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expErr:        errors.New("[tx.outputs: output 1 pays 1000 satoshis to a destination with 0 satoshis outstanding]"),
+		},
+		"output paying more than is outstanding after an earlier payment is rejected": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 1000, State: payd.StateInvoicePending}, nil
+			},
+			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+				return fq, nil
+			},
+			verifyPaymentFunc: func(context.Context, *bt.Tx, []byte, ...spv.VerifyOpt) (*bt.Tx, error) {
+				return bt.NewTxFromString("010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000")
+			},
+			destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
+				return []payd.Output{{
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
+						return s
+					}(),
+					DerivationPath:   "2147483648/2147483648/2147483648",
+					Satoshis:         1000,
+					SatoshisReceived: 600,
+					State:            "pending",
+				}}, nil
+			},
+			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
+			req: dpp.Payment{
+				RawTx: func() *string {
+					s := "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+					return &s
+				}(),
+			},
+			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expErr:        errors.New("[tx.outputs: output 0 pays 1000 satoshis to a destination with 400 satoshis outstanding]"),
+		},
+		"tx that doesn't pay any destination is rejected": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 1001, State: payd.StateInvoicePending, SPVRequired: true}, nil
+			},
+			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+				return fq, nil
+			},
+			verifyPaymentFunc: func(context.Context, *bt.Tx, []byte, ...spv.VerifyOpt) (*bt.Tx, error) {
+				return bt.NewTxFromString("010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000")
+			},
+			destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
+				return []payd.Output{{
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac")
+						return s
+					}(),
 					DerivationPath: "2147483648/2147483648/2147483648",
 					Satoshis:       1000,
 					State:          "pending",
@@ -267,38 +366,16 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
 			req: dpp.Payment{
 				RawTx: func() *string {
-					s := "010000000001e9030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+					s := "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
 					return &s
 				}(),
 			},
 			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
-			expErr:        errors.New("[tx.outputs: output satoshis do not match requested amount]"),
+			expErr:        errors.New("[transaction: tx does not pay any of the invoice destinations, ensure the correct destinations are used and try again]"),
 		},
-		// TODO: fix
-		//"same destination cannot be paid to twice": {
-		//	invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
-		//		return &payd.Invoice{ID: args.InvoiceID, State: payd.StateInvoicePending}, nil
-		//	},
-		//	feesFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
-		//		return fq, nil
-		//	},
-		//	verifyPaymentFunc: func(context.Context, *spv.Envelope, ...spv.VerifyOpt) (*bt.Tx, error) {
-		//		return bt.NewTxFromString("010000000002e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ace8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000")
-		//	},
-		//	destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
-		//		return []payd.Output{{
-		//			LockingScript:  "76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac",
-		//			DerivationPath: "2147483648/2147483648/2147483648",
-		//			Satoshis:       1000,
-		//			State:          "pending",
-		//		}}, nil
-		//	},
-		//	req:    dpp.Payment{InvoiceID: "abc123"},
-		//	expErr: errors.New("[tx.outputs: ]"),
-		//},
-		"tx with insufficient outputs is rejected": {
+		"tx paying some of the destinations is accepted as a part payment": {
 			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
-				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 1001, State: payd.StateInvoicePending, SPVRequired: true}, nil
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 2000, State: payd.StateInvoicePending}, nil
 			},
 			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
 				return fq, nil
@@ -315,8 +392,31 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					DerivationPath: "2147483648/2147483648/2147483648",
 					Satoshis:       1000,
 					State:          "pending",
+				}, {
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac")
+						return s
+					}(),
+					DerivationPath: "2147483648/2147483648/2147483650",
+					Satoshis:       1000,
+					State:          "pending",
 				}}, nil
 			},
+			txCreateFunc: func(context.Context, payd.TransactionCreate) error {
+				return nil
+			},
+			proofCallbackCreateFunc: func(context.Context, payd.ProofCallbackArgs, map[string]dpp.ProofCallback) error {
+				return nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			txUpdateStateFunc: func(context.Context, payd.TransactionArgs, payd.TransactionStateUpdate) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
 			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
 			req: dpp.Payment{
 				RawTx: func() *string {
@@ -324,12 +424,109 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					return &s
 				}(),
 			},
+			expVerifyOpts:   []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expRawTx:        "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
+			expTxState:      payd.StateTxBroadcast,
+			expPaidSatoshis: 1000,
+		},
+		"tx paying every split destination is accepted": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 2000, State: payd.StateInvoicePending}, nil
+			},
+			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+				return fq, nil
+			},
+			verifyPaymentFunc: func(context.Context, *bt.Tx, []byte, ...spv.VerifyOpt) (*bt.Tx, error) {
+				return bt.NewTxFromString("010000000002e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ace8030000000000001976a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac00000000")
+			},
+			destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
+				return []payd.Output{{
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
+						return s
+					}(),
+					DerivationPath: "2147483648/2147483648/2147483648",
+					Satoshis:       1000,
+					State:          "pending",
+				}, {
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac")
+						return s
+					}(),
+					DerivationPath: "2147483648/2147483648/2147483650",
+					Satoshis:       1000,
+					State:          "pending",
+				}}, nil
+			},
+			txCreateFunc: func(context.Context, payd.TransactionCreate) error {
+				return nil
+			},
+			proofCallbackCreateFunc: func(context.Context, payd.ProofCallbackArgs, map[string]dpp.ProofCallback) error {
+				return nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			txUpdateStateFunc: func(context.Context, payd.TransactionArgs, payd.TransactionStateUpdate) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
+			req: dpp.Payment{
+				RawTx: func() *string {
+					s := "010000000002e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ace8030000000000001976a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac00000000"
+					return &s
+				}(),
+			},
+			expVerifyOpts:   []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expRawTx:        "010000000002e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ace8030000000000001976a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac00000000",
+			expTxState:      payd.StateTxBroadcast,
+			expPaidSatoshis: 2000,
+		},
+		"output paying a destination below the dust limit is rejected": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 2000, State: payd.StateInvoicePending}, nil
+			},
+			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+				return fq, nil
+			},
+			verifyPaymentFunc: func(context.Context, *bt.Tx, []byte, ...spv.VerifyOpt) (*bt.Tx, error) {
+				return bt.NewTxFromString("01000000000164000000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000")
+			},
+			destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
+				return []payd.Output{{
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
+						return s
+					}(),
+					DerivationPath: "2147483648/2147483648/2147483648",
+					Satoshis:       1000,
+					State:          "pending",
+				}, {
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a9141a4cc80bc3ee6567cb37f9c5121841a5f8e0b87d88ac")
+						return s
+					}(),
+					DerivationPath: "2147483648/2147483648/2147483650",
+					Satoshis:       1000,
+					State:          "pending",
+				}}, nil
+			},
+			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
+			req: dpp.Payment{
+				RawTx: func() *string {
+					s := "01000000000164000000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+					return &s
+				}(),
+			},
 			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
-			expErr:        errors.New("[transaction: tx does not pay enough to cover invoice, ensure all outputs are included, the correct destinations are used and try again]"),
+			expErr:        errors.New("[transaction: output 0 pays 100 satoshis to an invoice destination, below the dust limit of 136]"),
 		},
-		"tx that doesn't use all destinations is rejected": {
+		"partially paid invoice accepts a further payment": {
 			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
-				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 1000, State: payd.StateInvoicePending}, nil
+				return &payd.Invoice{ID: args.InvoiceID, Satoshis: 2000, SatoshisReceived: 1000, State: payd.StateInvoicePartiallyPaid}, nil
 			},
 			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
 				return fq, nil
@@ -356,6 +553,21 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					State:          "pending",
 				}}, nil
 			},
+			txCreateFunc: func(context.Context, payd.TransactionCreate) error {
+				return nil
+			},
+			proofCallbackCreateFunc: func(context.Context, payd.ProofCallbackArgs, map[string]dpp.ProofCallback) error {
+				return nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			txUpdateStateFunc: func(context.Context, payd.TransactionArgs, payd.TransactionStateUpdate) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
 			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
 			req: dpp.Payment{
 				RawTx: func() *string {
@@ -363,8 +575,10 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					return &s
 				}(),
 			},
-			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
-			expErr:        errors.New("[tx.outputs: expected '2' outputs, received '1', ensure all destinations are supplied]"),
+			expVerifyOpts:   []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expRawTx:        "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
+			expTxState:      payd.StateTxBroadcast,
+			expPaidSatoshis: 1000,
 		},
 		"error on tx create is reported": {
 			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
@@ -475,9 +689,6 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
 				return errors.New("broadcast error")
 			},
-			txUpdateStateFunc: func(context.Context, payd.TransactionArgs, payd.TransactionStateUpdate) error {
-				return nil
-			},
 			commitFunc: func(context.Context) error {
 				return nil
 			},
@@ -489,7 +700,6 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 				}(),
 			},
 			expRawTx:      "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
-			expTxState:    payd.StateTxFailed,
 			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
 			expErr:        errors.New("failed to broadcast tx: broadcast error"),
 		},
@@ -536,10 +746,11 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 					return &s
 				}(),
 			},
-			expRawTx:      "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
-			expTxState:    payd.StateTxBroadcast,
-			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
-			expErr:        errors.New("oh no"),
+			expRawTx:        "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
+			expTxState:      payd.StateTxBroadcast,
+			expVerifyOpts:   []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expPaidSatoshis: 1000,
+			expErr:          errors.New("oh no"),
 		},
 		"expired invoice": {
 			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
@@ -590,6 +801,54 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 			expTxState:    payd.StateTxBroadcast,
 			expErr:        lathos.NewErrUnprocessable("E001", "invoice you are attempting to pay has expired"),
 		},
+		"invoice in the expired state": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, State: payd.StateInvoiceExpired}, nil
+			},
+			feeQuoteFunc: func(ctx context.Context, invoiceID string) (*bt.FeeQuote, error) {
+				return fq, nil
+			},
+			verifyPaymentFunc: func(context.Context, *bt.Tx, []byte, ...spv.VerifyOpt) (*bt.Tx, error) {
+				return bt.NewTxFromString("010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000")
+			},
+			destinationsFunc: func(context.Context, payd.DestinationsArgs) ([]payd.Output, error) {
+				return []payd.Output{{
+					LockingScript: func() *bscript.Script {
+						s, _ := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
+						return s
+					}(),
+					DerivationPath: "2147483648/2147483648/2147483648",
+					Satoshis:       1000,
+					State:          "pending",
+				}}, nil
+			},
+			txCreateFunc: func(context.Context, payd.TransactionCreate) error {
+				return nil
+			},
+			proofCallbackCreateFunc: func(context.Context, payd.ProofCallbackArgs, map[string]dpp.ProofCallback) error {
+				return nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			txUpdateStateFunc: func(context.Context, payd.TransactionArgs, payd.TransactionStateUpdate) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			args: payd.PaymentCreateArgs{InvoiceID: "abc123"},
+			req: dpp.Payment{
+				RawTx: func() *string {
+					s := "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+					return &s
+				}(),
+			},
+			expVerifyOpts: []spv.VerifyOpt{spv.VerifyFees(fq), spv.NoVerifySPV()},
+			expRawTx:      "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000",
+			expTxState:    payd.StateTxBroadcast,
+			expErr:        lathos.NewErrUnprocessable("E001", "invoice you are attempting to pay has expired"),
+		},
 	}
 
 	for name, test := range tests {
@@ -615,7 +874,9 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 				&mocks.InvoiceReaderWriterMock{
 					InvoiceFunc: test.invoiceFunc,
 					InvoiceUpdateFunc: func(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdatePaid) (*payd.Invoice, error) {
-						return nil, nil
+						assert.Equal(t, test.expPaidSatoshis, req.Satoshis)
+						assert.NotEmpty(t, req.TxID)
+						return &payd.Invoice{}, nil
 					},
 				},
 				&mocks.DestinationsReaderWriterMock{
@@ -652,6 +913,11 @@ func TestPaymentsService_PaymentCreate(t *testing.T) {
 						return nil
 					},
 				},
+				&mocks.WebhookPublisherMock{
+					PublishFunc: func(context.Context, payd.WebhookEvent, interface{}) error {
+						return nil
+					},
+				},
 				&config.PeerChannels{
 					Host: "peerchannels",
 					TTL:  1 * time.Minute,
@@ -2,31 +2,69 @@ package service
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/bip39"
 	"github.com/libsv/go-bk/chaincfg"
 	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/guregu/null.v3"
 
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/errcodes"
+)
+
+// encrypted keys are stored as the prefix, the xpub, then the base64 encoded salt,
+// nonce and AES-GCM sealed xprv, separated by colons. The xpub is kept in the clear,
+// as bitcoind does with public keys, so users can be read while the wallet is locked.
+// Encrypted mnemonics are stored as the prefix and the sealed mnemonic.
+const (
+	encryptedKeyPrefix = "scrypt"
+	scryptN            = 1 << 15
+	scryptR            = 8
+	scryptP            = 1
+	scryptKeyLen       = 32
+	scryptSaltLen      = 16
 )
 
 type privateKey struct {
-	store           payd.PrivateKeyReaderWriter
-	useMainNet      bool
-	numericPlusTick *regexp.Regexp
+	store              payd.PrivateKeyReaderWriter
+	useMainNet         bool
+	mnemonicPassphrase string
+	numericPlusTick    *regexp.Regexp
+
+	mu sync.RWMutex
+	// passphrase and keys are only set while the wallet is unlocked.
+	passphrase    []byte
+	keys          map[payd.KeyArgs]*bip32.ExtendedKey
+	unlockedUntil null.Time
+	lockTimer     *time.Timer
 }
 
 // NewPrivateKeys will setup and return a new PrivateKey service.
-func NewPrivateKeys(store payd.PrivateKeyReaderWriter, useMainNet bool) *privateKey {
+func NewPrivateKeys(store payd.PrivateKeyReaderWriter, cfg *config.Wallet) *privateKey {
 	return &privateKey{
-		store:           store,
-		useMainNet:      useMainNet,
-		numericPlusTick: regexp.MustCompile(`^[0-9]+'{0,1}$`),
+		store:              store,
+		useMainNet:         cfg.Network == config.NetworkMainet,
+		mnemonicPassphrase: cfg.MnemonicPassphrase,
+		numericPlusTick:    regexp.MustCompile(`^[0-9]+'{0,1}$`),
 	}
 }
 
-// Create creates an extended private key for a keyName.
+// Create creates an extended private key for a keyName from a new BIP39 mnemonic, the mnemonic
+// is stored until it is revealed. If the wallet is encrypted the key and mnemonic are encrypted
+// too, so the wallet must be unlocked.
 func (svc *privateKey) Create(ctx context.Context, keyName string, userID uint64) error { // get keyname from settings in caller
 	key, err := svc.store.PrivateKey(ctx, payd.KeyArgs{Name: keyName, UserID: userID})
 	if err != nil {
@@ -36,30 +74,313 @@ func (svc *privateKey) Create(ctx context.Context, keyName string, userID uint64
 		// This is unhelpful because when we try to create a new key and it already exists, there is no error.
 		return nil
 	}
-	seed, err := bip32.GenerateSeed(bip32.RecommendedSeedLen)
+	encrypted, err := svc.encrypted(ctx)
 	if err != nil {
-		return errors.Wrap(err, "failed to generate seed")
+		return err
 	}
-	chain := &chaincfg.TestNet
-	if svc.useMainNet {
-		chain = &chaincfg.MainNet
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if encrypted && svc.keys == nil {
+		return errWalletLocked()
 	}
-	xprv, err := bip32.NewMaster(seed, chain)
+	entropy, err := bip39.GenerateEntropy(bip39.EntWords24)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate entropy")
+	}
+	mnemonic, seed, err := bip39.Mnemonic(entropy, svc.mnemonicPassphrase)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate mnemonic")
+	}
+	xprv, err := bip32.NewMaster(seed, svc.chain())
 	if err != nil {
 		return errors.Wrap(err, "failed to create master node for given seed and chain")
 	}
+	storedKey, storedMnemonic := xprv.String(), mnemonic
+	if encrypted {
+		if storedKey, err = encryptKey(xprv, svc.passphrase); err != nil {
+			return errors.Wrapf(err, "failed to encrypt key %s", keyName)
+		}
+		if storedMnemonic, err = encryptMnemonic(mnemonic, svc.passphrase); err != nil {
+			return errors.Wrapf(err, "failed to encrypt key %s mnemonic", keyName)
+		}
+	}
 	if _, err := svc.store.PrivateKeyCreate(ctx, payd.PrivateKey{
-		UserID: userID,
-		Name:   keyName,
-		Xprv:   xprv.String(),
+		UserID:   userID,
+		Name:     keyName,
+		Xprv:     storedKey,
+		Mnemonic: null.StringFrom(storedMnemonic),
 	}); err != nil {
 		return errors.Wrap(err, "failed to create private key")
 	}
+	if encrypted {
+		svc.keys[payd.KeyArgs{Name: keyName, UserID: userID}] = xprv
+	}
 	return nil
 }
 
 // PrivateKey returns the extended private key for a keyname.
 func (svc *privateKey) PrivateKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+	key, err := svc.key(ctx, keyName, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isEncrypted(key.Xprv) {
+		xKey, err := bip32.NewKeyFromString(key.Xprv)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get extended key from xpriv")
+		}
+		if !xKey.IsPrivate() {
+			return nil, lathos.NewErrUnprocessable(errcodes.ErrKeyWatchOnly,
+				fmt.Sprintf("key %s is watch-only, it can't sign transactions", keyName))
+		}
+		return xKey, nil
+	}
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	if svc.keys == nil {
+		return nil, errWalletLocked()
+	}
+	if xKey, ok := svc.keys[payd.KeyArgs{Name: keyName, UserID: userID}]; ok {
+		return xKey, nil
+	}
+	// the key was encrypted after the wallet was unlocked, by another instance.
+	return decryptKey(key.Xprv, svc.passphrase)
+}
+
+// PrivateKeyImport stores xKey as the key for args, replacing any existing key and its mnemonic.
+// If the wallet is encrypted a private key is encrypted too, so the wallet must be unlocked.
+// An xpub is stored in the clear as a watch-only key.
+func (svc *privateKey) PrivateKeyImport(ctx context.Context, args payd.KeyArgs, xKey *bip32.ExtendedKey) error {
+	encrypted, err := svc.encrypted(ctx)
+	if err != nil {
+		return err
+	}
+	encrypted = encrypted && xKey.IsPrivate()
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if encrypted && svc.keys == nil {
+		return errWalletLocked()
+	}
+	stored := xKey.String()
+	if encrypted {
+		if stored, err = encryptKey(xKey, svc.passphrase); err != nil {
+			return errors.Wrapf(err, "failed to encrypt key %s", args.Name)
+		}
+	}
+	key, err := svc.store.PrivateKey(ctx, args)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get key %s by name", args.Name)
+	}
+	if key == nil {
+		if _, err := svc.store.PrivateKeyCreate(ctx, payd.PrivateKey{
+			UserID: args.UserID,
+			Name:   args.Name,
+			Xprv:   stored,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create private key")
+		}
+	} else {
+		key.Xprv, key.Mnemonic = stored, null.String{}
+		if err := svc.store.PrivateKeysUpdate(ctx, []payd.PrivateKey{*key}); err != nil {
+			return errors.Wrap(err, "failed to replace private key")
+		}
+	}
+	if encrypted {
+		svc.keys[args] = xKey
+	} else if svc.keys != nil {
+		// the key may have replaced an encrypted key cached by the unlock.
+		delete(svc.keys, args)
+	}
+	return nil
+}
+
+// MnemonicReveal returns the mnemonic a key was created from then deletes it, so it can
+// only be revealed once.
+func (svc *privateKey) MnemonicReveal(ctx context.Context, args payd.KeyArgs) (string, error) {
+	// held throughout so concurrent reveals can't both read the mnemonic.
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	key, err := svc.key(ctx, args.Name, args.UserID)
+	if err != nil {
+		return "", err
+	}
+	if !key.Mnemonic.Valid {
+		return "", lathos.NewErrNotFound(errcodes.ErrMnemonicNotFound,
+			fmt.Sprintf("key %s has no mnemonic, it has already been revealed or the key was imported", args.Name))
+	}
+	mnemonic := key.Mnemonic.String
+	if isEncrypted(mnemonic) {
+		if svc.keys == nil {
+			return "", errWalletLocked()
+		}
+		if mnemonic, err = decryptMnemonic(mnemonic, svc.passphrase); err != nil {
+			return "", err
+		}
+	}
+	key.Mnemonic = null.String{}
+	if err := svc.store.PrivateKeysUpdate(ctx, []payd.PrivateKey{*key}); err != nil {
+		return "", errors.Wrap(err, "failed to delete revealed mnemonic")
+	}
+	return mnemonic, nil
+}
+
+// PublicKey returns the extended public key for a keyname, it can be used while the wallet is locked.
+func (svc *privateKey) PublicKey(ctx context.Context, keyName string, userID uint64) (*bip32.ExtendedKey, error) {
+	key, err := svc.key(ctx, keyName, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isEncrypted(key.Xprv) {
+		return encryptedKeyXpub(key.Xprv)
+	}
+	xKey, err := bip32.NewKeyFromString(key.Xprv)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get extended key from xpriv")
+	}
+	xPub, err := xKey.Neuter()
+	return xPub, errors.Wrap(err, "failed to get extended public key from xpriv")
+}
+
+// WalletEncrypt encrypts every private key with the passphrase then locks the wallet.
+func (svc *privateKey) WalletEncrypt(ctx context.Context, req payd.WalletEncrypt) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	keys, err := svc.store.PrivateKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get private keys to encrypt")
+	}
+	for i, k := range keys {
+		if isEncrypted(k.Xprv) {
+			return lathos.NewErrUnprocessable(errcodes.ErrWalletEncrypted, "wallet is already encrypted")
+		}
+		xKey, err := bip32.NewKeyFromString(k.Xprv)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get extended key %s from xpriv", k.Name)
+		}
+		if !xKey.IsPrivate() {
+			// watch-only keys have nothing to protect.
+			continue
+		}
+		if keys[i].Xprv, err = encryptKey(xKey, []byte(req.Passphrase)); err != nil {
+			return errors.Wrapf(err, "failed to encrypt key %s", k.Name)
+		}
+		if k.Mnemonic.Valid {
+			if keys[i].Mnemonic.String, err = encryptMnemonic(k.Mnemonic.String, []byte(req.Passphrase)); err != nil {
+				return errors.Wrapf(err, "failed to encrypt key %s mnemonic", k.Name)
+			}
+		}
+	}
+	if err := svc.store.PrivateKeysUpdate(ctx, keys); err != nil {
+		return errors.Wrap(err, "failed to store encrypted private keys")
+	}
+	svc.lock()
+	return nil
+}
+
+// WalletUnlock decrypts every private key, keeping them in memory until the timeout passes.
+// Unlocking an unlocked wallet replaces its timeout.
+func (svc *privateKey) WalletUnlock(ctx context.Context, req payd.WalletUnlock) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	keys, err := svc.store.PrivateKeys(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get private keys to unlock")
+	}
+	passphrase := []byte(req.Passphrase)
+	decrypted := make(map[payd.KeyArgs]*bip32.ExtendedKey, len(keys))
+	for _, k := range keys {
+		if !isEncrypted(k.Xprv) {
+			continue
+		}
+		xKey, err := decryptKey(k.Xprv, passphrase)
+		if err != nil {
+			return err
+		}
+		decrypted[payd.KeyArgs{Name: k.Name, UserID: k.UserID}] = xKey
+	}
+	if len(decrypted) == 0 {
+		return errWalletNotEncrypted()
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.lock()
+	svc.passphrase = passphrase
+	svc.keys = decrypted
+	if req.Timeout > 0 {
+		timeout := time.Duration(req.Timeout) * time.Second
+		svc.unlockedUntil = null.TimeFrom(time.Now().UTC().Add(timeout))
+		var timer *time.Timer
+		timer = time.AfterFunc(timeout, func() {
+			svc.mu.Lock()
+			defer svc.mu.Unlock()
+			// the wallet may have been locked, then unlocked again, while this waited.
+			if svc.lockTimer == timer {
+				svc.lock()
+			}
+		})
+		svc.lockTimer = timer
+	}
+	return nil
+}
+
+// WalletLock removes the decrypted private keys from memory.
+func (svc *privateKey) WalletLock(ctx context.Context) error {
+	encrypted, err := svc.encrypted(ctx)
+	if err != nil {
+		return err
+	}
+	if !encrypted {
+		return errWalletNotEncrypted()
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.lock()
+	return nil
+}
+
+// WalletLockStatus returns whether the wallet is encrypted and locked.
+func (svc *privateKey) WalletLockStatus(ctx context.Context) (*payd.WalletLockStatus, error) {
+	encrypted, err := svc.encrypted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	svc.mu.RLock()
+	defer svc.mu.RUnlock()
+	return &payd.WalletLockStatus{
+		Encrypted:     encrypted,
+		Locked:        encrypted && svc.keys == nil,
+		UnlockedUntil: svc.unlockedUntil,
+	}, nil
+}
+
+func (svc *privateKey) chain() *chaincfg.Params {
+	if svc.useMainNet {
+		return &chaincfg.MainNet
+	}
+	return &chaincfg.TestNet
+}
+
+// lock clears the decrypted keys, svc.mu must be held. The keys themselves aren't
+// zeroed as they may still be in use signing a tx.
+func (svc *privateKey) lock() {
+	if svc.lockTimer != nil {
+		svc.lockTimer.Stop()
+		svc.lockTimer = nil
+	}
+	for i := range svc.passphrase {
+		svc.passphrase[i] = 0
+	}
+	svc.passphrase = nil
+	svc.keys = nil
+	svc.unlockedUntil = null.Time{}
+}
+
+// key returns the stored key, erroring if it doesn't exist.
+func (svc *privateKey) key(ctx context.Context, keyName string, userID uint64) (*payd.PrivateKey, error) {
 	key, err := svc.store.PrivateKey(ctx, payd.KeyArgs{Name: keyName, UserID: userID})
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get key %s by name", keyName)
@@ -67,10 +388,142 @@ func (svc *privateKey) PrivateKey(ctx context.Context, keyName string, userID ui
 	if key == nil {
 		return nil, errors.New("key not found")
 	}
+	return key, nil
+}
 
-	xKey, err := bip32.NewKeyFromString(key.Xprv)
+// encrypted returns true if the stored keys are encrypted.
+func (svc *privateKey) encrypted(ctx context.Context) (bool, error) {
+	keys, err := svc.store.PrivateKeys(ctx)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get extended key from xpriv")
+		return false, errors.Wrap(err, "failed to get private keys")
+	}
+	for _, k := range keys {
+		if isEncrypted(k.Xprv) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isEncrypted(xprv string) bool {
+	return strings.HasPrefix(xprv, encryptedKeyPrefix+":")
+}
+
+// encryptKey seals xprv with a key derived from the passphrase, the xpub is kept in the clear.
+func encryptKey(xprv *bip32.ExtendedKey, passphrase []byte) (string, error) {
+	xpub, err := xprv.Neuter()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get extended public key")
+	}
+	sealed, err := seal([]byte(xprv.String()), passphrase)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s:%s", encryptedKeyPrefix, xpub.String(), sealed), nil
+}
+
+// decryptKey opens a key sealed by encryptKey.
+func decryptKey(stored string, passphrase []byte) (*bip32.ExtendedKey, error) {
+	parts := strings.Split(stored, ":")
+	if len(parts) != 3 {
+		return nil, errors.New("encrypted key is malformed")
+	}
+	xprv, err := open(parts[2], passphrase)
+	if err != nil {
+		return nil, err
+	}
+	xKey, err := bip32.NewKeyFromString(string(xprv))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get extended key from decrypted xpriv")
 	}
 	return xKey, nil
 }
+
+// encryptedKeyXpub returns the xpub of a key encrypted by encryptKey.
+func encryptedKeyXpub(stored string) (*bip32.ExtendedKey, error) {
+	parts := strings.Split(stored, ":")
+	if len(parts) != 3 {
+		return nil, errors.New("encrypted key is malformed")
+	}
+	xpub, err := bip32.NewKeyFromString(parts[1])
+	return xpub, errors.Wrap(err, "failed to get extended key from encrypted key xpub")
+}
+
+// encryptMnemonic seals a mnemonic with a key derived from the passphrase.
+func encryptMnemonic(mnemonic string, passphrase []byte) (string, error) {
+	sealed, err := seal([]byte(mnemonic), passphrase)
+	if err != nil {
+		return "", err
+	}
+	return encryptedKeyPrefix + ":" + sealed, nil
+}
+
+// decryptMnemonic opens a mnemonic sealed by encryptMnemonic.
+func decryptMnemonic(stored string, passphrase []byte) (string, error) {
+	mnemonic, err := open(strings.TrimPrefix(stored, encryptedKeyPrefix+":"), passphrase)
+	return string(mnemonic), err
+}
+
+// seal encrypts plaintext with a key derived from the passphrase and a random salt, returning
+// the base64 encoded salt, nonce and ciphertext.
+func seal(plaintext, passphrase []byte) (string, error) {
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "failed to generate salt")
+	}
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "failed to generate nonce")
+	}
+	sealed := append(append(salt, nonce...), aead.Seal(nil, nonce, plaintext, nil)...)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a value encrypted by seal, a wrong passphrase fails authentication.
+func open(encoded string, passphrase []byte) ([]byte, error) {
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode encrypted value")
+	}
+	if len(sealed) < scryptSaltLen {
+		return nil, errors.New("encrypted value is too short")
+	}
+	aead, err := newAEAD(passphrase, sealed[:scryptSaltLen])
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[scryptSaltLen:]
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, lathos.NewErrNotAuthorised(errcodes.ErrWalletPassphrase, "the wallet passphrase is incorrect")
+	}
+	return plaintext, nil
+}
+
+func newAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive key from passphrase")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Wrap(err, "failed to create gcm cipher")
+}
+
+func errWalletLocked() error {
+	return lathos.NewErrUnprocessable(errcodes.ErrWalletLocked, "wallet is locked, unlock it with the wallet passphrase")
+}
+
+func errWalletNotEncrypted() error {
+	return lathos.NewErrUnprocessable(errcodes.ErrWalletNotEncrypted, "wallet is not encrypted")
+}
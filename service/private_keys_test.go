@@ -2,14 +2,20 @@ package service_test
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/bip39"
+	"github.com/libsv/go-bk/chaincfg"
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
 	"github.com/libsv/payd/mocks"
 	"github.com/libsv/payd/service"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	lathos "github.com/theflyingcodr/lathos/errs"
 )
 
 func TestPrivateKeyService_Create(t *testing.T) {
@@ -70,12 +76,15 @@ func TestPrivateKeyService_Create(t *testing.T) {
 			var createPrivateKeyCalled bool
 			svc := service.NewPrivateKeys(&mocks.PrivateKeyReaderWriterMock{
 				PrivateKeyFunc: test.privateKeyFunc,
+				PrivateKeysFunc: func(ctx context.Context) ([]payd.PrivateKey, error) {
+					return nil, nil
+				},
 				PrivateKeyCreateFunc: func(ctx context.Context, args payd.PrivateKey) (*payd.PrivateKey, error) {
 					createPrivateKeyCalled = true
 					assert.Equal(t, test.keyname, args.Name)
 					return test.privateKeyCreateFunc(ctx, args)
 				},
-			}, false)
+			}, &config.Wallet{})
 
 			err := svc.Create(context.Background(), test.keyname, 1)
 			if test.expErr != nil {
@@ -126,7 +135,7 @@ func TestPrivateKeyService_PrivateKey(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			svc := service.NewPrivateKeys(&mocks.PrivateKeyReaderWriterMock{
 				PrivateKeyFunc: test.privateKeyFunc,
-			}, false)
+			}, &config.Wallet{})
 
 			key, err := svc.PrivateKey(context.Background(), test.keyname, 1)
 			if test.expErr != nil {
@@ -144,3 +153,220 @@ func TestPrivateKeyService_PrivateKey(t *testing.T) {
 		})
 	}
 }
+
+func TestPrivateKeyService_WalletLock(t *testing.T) {
+	const (
+		xprv       = "tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP"
+		passphrase = "correct horse"
+	)
+	setup := func() (payd.PrivateKeyService, payd.WalletLockService, map[string]payd.PrivateKey) {
+		keys := map[string]payd.PrivateKey{
+			"masterkey": {UserID: 1, Name: "masterkey", Xprv: xprv},
+		}
+		store := mapKeyStore(keys)
+		svc := service.NewPrivateKeys(store, &config.Wallet{})
+		return svc, svc, keys
+	}
+	ctx := context.Background()
+
+	t.Run("encrypted wallet is locked until unlocked", func(t *testing.T) {
+		pks, wls, keys := setup()
+		status, err := wls.WalletLockStatus(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, &payd.WalletLockStatus{}, status)
+
+		assert.NoError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.NotContains(t, keys["masterkey"].Xprv, xprv)
+		status, err = wls.WalletLockStatus(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, &payd.WalletLockStatus{Encrypted: true, Locked: true}, status)
+
+		_, err = pks.PrivateKey(ctx, "masterkey", 1)
+		assert.EqualError(t, err, lathos.NewErrUnprocessable("W0001", "wallet is locked, unlock it with the wallet passphrase").Error())
+		assert.EqualError(t, pks.Create(ctx, "other", 1), lathos.NewErrUnprocessable("W0001", "wallet is locked, unlock it with the wallet passphrase").Error())
+
+		xpub, err := pks.PublicKey(ctx, "masterkey", 1)
+		assert.NoError(t, err)
+		assert.False(t, xpub.IsPrivate())
+
+		assert.NoError(t, wls.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: passphrase}))
+		key, err := pks.PrivateKey(ctx, "masterkey", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, xprv, key.String())
+		status, err = wls.WalletLockStatus(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, &payd.WalletLockStatus{Encrypted: true}, status)
+
+		assert.NoError(t, wls.WalletLock(ctx))
+		_, err = pks.PrivateKey(ctx, "masterkey", 1)
+		assert.EqualError(t, err, lathos.NewErrUnprocessable("W0001", "wallet is locked, unlock it with the wallet passphrase").Error())
+	})
+
+	t.Run("keys created while unlocked are encrypted", func(t *testing.T) {
+		pks, wls, keys := setup()
+		assert.NoError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.NoError(t, wls.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: passphrase}))
+		assert.NoError(t, pks.Create(ctx, "other", 1))
+		key, err := pks.PrivateKey(ctx, "other", 1)
+		assert.NoError(t, err)
+		assert.NotContains(t, keys["other"].Xprv, key.String())
+
+		assert.NoError(t, wls.WalletLock(ctx))
+		assert.NoError(t, wls.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: passphrase}))
+		unlocked, err := pks.PrivateKey(ctx, "other", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, key.String(), unlocked.String())
+	})
+
+	t.Run("wallet locks after the timeout", func(t *testing.T) {
+		pks, wls, _ := setup()
+		assert.NoError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.NoError(t, wls.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: passphrase, Timeout: 1}))
+		status, err := wls.WalletLockStatus(ctx)
+		assert.NoError(t, err)
+		assert.True(t, status.UnlockedUntil.Valid)
+		assert.Eventually(t, func() bool {
+			_, err := pks.PrivateKey(ctx, "masterkey", 1)
+			return err != nil
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("wrong passphrase is rejected", func(t *testing.T) {
+		_, wls, _ := setup()
+		assert.NoError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.EqualError(t, wls.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: "wrong horse"}),
+			lathos.NewErrNotAuthorised("W0002", "the wallet passphrase is incorrect").Error())
+		status, err := wls.WalletLockStatus(ctx)
+		assert.NoError(t, err)
+		assert.True(t, status.Locked)
+	})
+
+	t.Run("wallet can't be encrypted twice", func(t *testing.T) {
+		_, wls, _ := setup()
+		assert.NoError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.EqualError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}),
+			lathos.NewErrUnprocessable("W0003", "wallet is already encrypted").Error())
+	})
+
+	t.Run("unencrypted wallet can't be unlocked or locked", func(t *testing.T) {
+		_, wls, _ := setup()
+		assert.EqualError(t, wls.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: passphrase}),
+			lathos.NewErrUnprocessable("W0004", "wallet is not encrypted").Error())
+		assert.EqualError(t, wls.WalletLock(ctx), lathos.NewErrUnprocessable("W0004", "wallet is not encrypted").Error())
+	})
+
+	t.Run("short passphrase is rejected", func(t *testing.T) {
+		_, wls, _ := setup()
+		assert.EqualError(t, wls.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: "short"}),
+			"[passphrase: value must be between 8 and 1024 characters]")
+	})
+}
+
+func TestPrivateKeyService_Mnemonic(t *testing.T) {
+	const passphrase = "correct horse"
+	ctx := context.Background()
+	args := payd.KeyArgs{Name: "masterkey", UserID: 1}
+
+	t.Run("created key can be restored from its mnemonic once", func(t *testing.T) {
+		keys := map[string]payd.PrivateKey{}
+		svc := service.NewPrivateKeys(mapKeyStore(keys), &config.Wallet{MnemonicPassphrase: "salt"})
+		assert.NoError(t, svc.Create(ctx, args.Name, args.UserID))
+		key, err := svc.PrivateKey(ctx, args.Name, args.UserID)
+		assert.NoError(t, err)
+
+		mnemonic, err := svc.MnemonicReveal(ctx, args)
+		assert.NoError(t, err)
+		assert.Len(t, strings.Fields(mnemonic), 24)
+		seed, err := bip39.MnemonicToSeed(mnemonic, "salt")
+		assert.NoError(t, err)
+		restored, err := bip32.NewMaster(seed, &chaincfg.TestNet)
+		assert.NoError(t, err)
+		assert.Equal(t, key.String(), restored.String())
+		assert.False(t, keys[args.Name].Mnemonic.Valid)
+
+		_, err = svc.MnemonicReveal(ctx, args)
+		assert.EqualError(t, err, lathos.NewErrNotFound("N0014",
+			"key masterkey has no mnemonic, it has already been revealed or the key was imported").Error())
+	})
+
+	t.Run("mnemonic is encrypted with the wallet", func(t *testing.T) {
+		keys := map[string]payd.PrivateKey{}
+		svc := service.NewPrivateKeys(mapKeyStore(keys), &config.Wallet{})
+		assert.NoError(t, svc.Create(ctx, args.Name, args.UserID))
+		mnemonic := keys[args.Name].Mnemonic.String
+		assert.NoError(t, svc.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.NotContains(t, keys[args.Name].Mnemonic.String, mnemonic)
+
+		_, err := svc.MnemonicReveal(ctx, args)
+		assert.EqualError(t, err, lathos.NewErrUnprocessable("W0001", "wallet is locked, unlock it with the wallet passphrase").Error())
+		assert.NoError(t, svc.WalletUnlock(ctx, payd.WalletUnlock{Passphrase: passphrase}))
+		revealed, err := svc.MnemonicReveal(ctx, args)
+		assert.NoError(t, err)
+		assert.Equal(t, mnemonic, revealed)
+	})
+
+	t.Run("imported key replaces the key and its mnemonic", func(t *testing.T) {
+		const xprv = "tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP"
+		keys := map[string]payd.PrivateKey{}
+		svc := service.NewPrivateKeys(mapKeyStore(keys), &config.Wallet{})
+		assert.NoError(t, svc.Create(ctx, args.Name, args.UserID))
+		xKey, err := bip32.NewKeyFromString(xprv)
+		assert.NoError(t, err)
+
+		assert.NoError(t, svc.PrivateKeyImport(ctx, args, xKey))
+		key, err := svc.PrivateKey(ctx, args.Name, args.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, xprv, key.String())
+		assert.False(t, keys[args.Name].Mnemonic.Valid)
+
+		assert.NoError(t, svc.PrivateKeyImport(ctx, payd.KeyArgs{Name: "other", UserID: 1}, xKey))
+		assert.Equal(t, xprv, keys["other"].Xprv)
+	})
+
+	t.Run("imported xpub is watch-only and left unencrypted", func(t *testing.T) {
+		const xpub = "tpubD6NzVbkrYhZ4WPxQEWLbnueqwj1kdLofAKjqutMjxprwNYThy49k6YJs5d4ZZ7Z7JMXaeCykmAqtUaEf9EQtMPchEQqYxcrtiJzaUiLgPSE"
+		keys := map[string]payd.PrivateKey{}
+		svc := service.NewPrivateKeys(mapKeyStore(keys), &config.Wallet{})
+		xKey, err := bip32.NewKeyFromString(xpub)
+		assert.NoError(t, err)
+		assert.NoError(t, svc.PrivateKeyImport(ctx, args, xKey))
+
+		_, err = svc.PrivateKey(ctx, args.Name, args.UserID)
+		assert.EqualError(t, err, lathos.NewErrUnprocessable("K0002", "key masterkey is watch-only, it can't sign transactions").Error())
+		assert.NoError(t, svc.WalletEncrypt(ctx, payd.WalletEncrypt{Passphrase: passphrase}))
+		assert.Equal(t, xpub, keys[args.Name].Xprv)
+		pub, err := svc.PublicKey(ctx, args.Name, args.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, xpub, pub.String())
+	})
+}
+
+// mapKeyStore returns a private key store backed by keys, keyed by name.
+func mapKeyStore(keys map[string]payd.PrivateKey) *mocks.PrivateKeyReaderWriterMock {
+	return &mocks.PrivateKeyReaderWriterMock{
+		PrivateKeyFunc: func(ctx context.Context, args payd.KeyArgs) (*payd.PrivateKey, error) {
+			k, ok := keys[args.Name]
+			if !ok {
+				return nil, nil
+			}
+			return &k, nil
+		},
+		PrivateKeysFunc: func(ctx context.Context) ([]payd.PrivateKey, error) {
+			kk := make([]payd.PrivateKey, 0, len(keys))
+			for _, k := range keys {
+				kk = append(kk, k)
+			}
+			return kk, nil
+		},
+		PrivateKeyCreateFunc: func(ctx context.Context, req payd.PrivateKey) (*payd.PrivateKey, error) {
+			keys[req.Name] = req
+			return &req, nil
+		},
+		PrivateKeysUpdateFunc: func(ctx context.Context, req []payd.PrivateKey) error {
+			for _, k := range req {
+				keys[k.Name] = k
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+)
+
+// proofCallbacksBatch is the most proof callbacks sent each time due callbacks are sent.
+const proofCallbacksBatch = 100
+
+type proofCallbacks struct {
+	l       log.Logger
+	cfg     *config.ProofCallbacks
+	store   payd.ProofCallbackReaderWriter
+	sender  payd.ProofCallbackSender
+	timeSvc payd.TimestampService
+}
+
+// NewProofCallbacks will setup and return a new proof callback service, used to send
+// received merkle proofs to the callbacks supplied by payers.
+func NewProofCallbacks(l log.Logger, cfg *config.ProofCallbacks, store payd.ProofCallbackReaderWriter, sender payd.ProofCallbackSender, timeSvc payd.TimestampService) *proofCallbacks {
+	return &proofCallbacks{
+		l:       l,
+		cfg:     cfg,
+		store:   store,
+		sender:  sender,
+		timeSvc: timeSvc,
+	}
+}
+
+// ProofCallbacksSend will send each proof callback that is due. Failed callbacks are retried with
+// an exponential backoff until they run out of attempts, when they are failed.
+func (p *proofCallbacks) ProofCallbacksSend(ctx context.Context) (int, error) {
+	cc, err := p.store.ProofCallbacksDue(ctx, payd.ProofCallbacksDueArgs{
+		DueBefore: p.timeSvc.NowUTC(),
+		Limit:     proofCallbacksBatch,
+	})
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get due proof callbacks")
+	}
+	for _, c := range cc {
+		p.send(ctx, c)
+	}
+	return len(cc), nil
+}
+
+// send posts a single proof and records the outcome, errors are logged so
+// one failure doesn't hold up the other callbacks.
+func (p *proofCallbacks) send(ctx context.Context, c payd.ProofCallback) {
+	err := p.sender.ProofCallbackSend(ctx, c)
+	now := p.timeSvc.NowUTC()
+	req := payd.ProofCallbackUpdate{
+		State:     payd.StateProofCallbackSent,
+		Attempts:  c.Attempts + 1,
+		UpdatedAt: now,
+	}
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > lastErrorLen {
+			msg = msg[:lastErrorLen]
+		}
+		req.LastError = null.StringFrom(msg)
+		req.State = payd.StateProofCallbackPending
+		req.NextAttemptAt = null.TimeFrom(now.Add(retryBackoff(req.Attempts, p.cfg.Backoff, p.cfg.BackoffMax)))
+		if req.Attempts >= p.cfg.MaxAttempts {
+			req.State = payd.StateProofCallbackFailed
+			req.NextAttemptAt = null.Time{}
+			p.l.Warnf("proof callback %s for tx %s failed after %d attempts: %s", c.URL, c.TxID, req.Attempts, msg)
+		} else {
+			p.l.Debugf("proof callback %s for tx %s failed, retrying at %s: %s", c.URL, c.TxID, req.NextAttemptAt.Time, msg)
+		}
+	}
+	if err := p.store.ProofCallbackUpdate(ctx, payd.ProofCallbackSendArgs{TxID: c.TxID, URL: c.URL}, req); err != nil {
+		p.l.Errorf(err, "failed to record outcome of proof callback %s for tx %s", c.URL, c.TxID)
+	}
+}
@@ -0,0 +1,124 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestProofCallbackService_ProofCallbacksSend(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	cfg := &config.ProofCallbacks{MaxAttempts: 3, Backoff: 30 * time.Second, BackoffMax: time.Hour}
+	callback := payd.ProofCallback{
+		InvoiceID:     "inv1",
+		TxID:          "abc123",
+		URL:           "https://payer.com/proofs",
+		Token:         "token",
+		Proof:         []byte(`{"payload":"proof"}`),
+		NextAttemptAt: null.TimeFrom(now),
+	}
+	tests := map[string]struct {
+		callback  payd.ProofCallback
+		sendErr   error
+		dueErr    error
+		expN      int
+		expUpdate *payd.ProofCallbackUpdate
+		expErr    error
+	}{
+		"successful send is sent": {
+			callback: callback,
+			expN:     1,
+			expUpdate: &payd.ProofCallbackUpdate{
+				State:     payd.StateProofCallbackSent,
+				Attempts:  1,
+				UpdatedAt: now,
+			},
+		},
+		"failed send is retried after the backoff": {
+			callback: func() payd.ProofCallback {
+				c := callback
+				c.Attempts = 1
+				return c
+			}(),
+			sendErr: errors.New("connection refused"),
+			expN:    1,
+			expUpdate: &payd.ProofCallbackUpdate{
+				State:         payd.StateProofCallbackPending,
+				Attempts:      2,
+				NextAttemptAt: null.TimeFrom(now.Add(time.Minute)),
+				LastError:     null.StringFrom("connection refused"),
+				UpdatedAt:     now,
+			},
+		},
+		"callback is failed when out of attempts": {
+			callback: func() payd.ProofCallback {
+				c := callback
+				c.Attempts = 2
+				return c
+			}(),
+			sendErr: errors.New("unexpected status code 500"),
+			expN:    1,
+			expUpdate: &payd.ProofCallbackUpdate{
+				State:     payd.StateProofCallbackFailed,
+				Attempts:  3,
+				LastError: null.StringFrom("unexpected status code 500"),
+				UpdatedAt: now,
+			},
+		},
+		"error getting due callbacks is returned": {
+			dueErr: errors.New("db down"),
+			expErr: errors.New("failed to get due proof callbacks: db down"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var update *payd.ProofCallbackUpdate
+			store := &mocks.ProofCallbackReaderWriterMock{
+				ProofCallbacksDueFunc: func(ctx context.Context, args payd.ProofCallbacksDueArgs) ([]payd.ProofCallback, error) {
+					assert.Equal(t, now, args.DueBefore)
+					if test.dueErr != nil {
+						return nil, test.dueErr
+					}
+					return []payd.ProofCallback{test.callback}, nil
+				},
+				ProofCallbackUpdateFunc: func(ctx context.Context, args payd.ProofCallbackSendArgs, req payd.ProofCallbackUpdate) error {
+					assert.Equal(t, payd.ProofCallbackSendArgs{TxID: test.callback.TxID, URL: test.callback.URL}, args)
+					update = &req
+					return nil
+				},
+			}
+			sender := &mocks.ProofCallbackSenderMock{
+				ProofCallbackSendFunc: func(ctx context.Context, c payd.ProofCallback) error {
+					assert.Equal(t, test.callback, c)
+					return test.sendErr
+				},
+			}
+			svc := service.NewProofCallbacks(log.Noop{}, cfg, store, sender, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+
+			n, err := svc.ProofCallbacksSend(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expN, n)
+			assert.Equal(t, test.expUpdate, update)
+		})
+	}
+}
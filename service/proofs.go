@@ -3,33 +3,59 @@ package service
 import (
 	"context"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"strings"
 
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bc/spv"
+	"github.com/libsv/go-bk/crypto"
 	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/go-dpp"
 	"github.com/libsv/go-spvchannels"
 	"github.com/libsv/payd/log"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
 
 	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
 )
 
 type proofs struct {
-	wtr payd.ProofsWriter
-	l   log.Logger
+	wtr              payd.ProofsWriter
+	txWtr            payd.TransactionWriter
+	callbackWtr      payd.ProofCallbackWriter
+	webhooks         payd.WebhookPublisher
+	transacter       payd.Transacter
+	timeSvc          payd.TimestampService
+	headers          payd.HeaderChain
+	verifier         spv.MerkleProofVerifier
+	minConfirmations uint32
+	l                log.Logger
 }
 
 // NewProofsService will setup and return a new merkle proof service.
-func NewProofsService(wtr payd.ProofsWriter, l log.Logger) *proofs {
+func NewProofsService(wtr payd.ProofsWriter, txWtr payd.TransactionWriter, callbackWtr payd.ProofCallbackWriter, webhooks payd.WebhookPublisher,
+	transacter payd.Transacter, timeSvc payd.TimestampService, headers payd.HeaderChain, verifier spv.MerkleProofVerifier, cfg *config.Wallet, l log.Logger) *proofs {
 	return &proofs{
-		wtr: wtr,
-		l:   l,
+		wtr:              wtr,
+		txWtr:            txWtr,
+		callbackWtr:      callbackWtr,
+		webhooks:         webhooks,
+		transacter:       transacter,
+		timeSvc:          timeSvc,
+		headers:          headers,
+		verifier:         verifier,
+		minConfirmations: cfg.MinConfirmations,
+		l:                l,
 	}
 }
 
 // Create will add a merkle proof to a data store for persistent storage once it has
-// been validated.
+// been validated and verified against the block header chain.
 func (p *proofs) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
 	var proof dpp.ProofWrapper
 	if err := json.Unmarshal([]byte(req.Payload), &proof); err != nil {
@@ -46,10 +72,96 @@ func (p *proofs) Create(ctx context.Context, args dpp.ProofCreateArgs, req envel
 	if err := proof.Validate(args); err != nil {
 		return err
 	}
+	height, err := p.verify(ctx, proof)
+	if err != nil {
+		return err
+	}
+	// the height in the payload isn't covered by the proof, the height of the verified block is used instead.
+	proof.BlockHeight = height
+	ctx = p.transacter.WithTx(ctx)
+	defer func() {
+		_ = p.transacter.Rollback(ctx)
+	}()
 	if err := p.wtr.ProofCreate(ctx, proof); err != nil {
 		return errors.Wrap(err, "failed to save proof")
 	}
-	return nil
+	// a block on the best chain has 1 confirmation, txs needing more stay mined until
+	// the confirmation job sees enough blocks on top of it.
+	state := payd.StateTxConfirmed
+	if p.minConfirmations > 1 {
+		state = payd.StateTxMined
+	}
+	if err := p.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: proof.CallbackTxID}, payd.TransactionStateUpdate{
+		State:       state,
+		BlockHeight: null.IntFrom(int64(proof.BlockHeight)),
+	}); err != nil {
+		return errors.WithMessagef(err, "failed to set tx %s %s", proof.CallbackTxID, state)
+	}
+	// the envelope is sent as received so payers can check it is signed by the miner.
+	bb, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode proof envelope")
+	}
+	now := p.timeSvc.NowUTC()
+	if err := p.callbackWtr.ProofCallbacksQueue(ctx, payd.ProofCallbackArgs{TxID: proof.CallbackTxID}, payd.ProofCallbacksQueue{
+		Proof:         bb,
+		NextAttemptAt: now,
+		UpdatedAt:     now,
+	}); err != nil {
+		return errors.WithMessage(err, "failed to queue proof callbacks")
+	}
+	if err := p.webhooks.Publish(ctx, payd.WebhookEventProofReceived, payd.WebhookProofEvent{
+		TxID:        proof.CallbackTxID,
+		BlockHash:   proof.BlockHash,
+		BlockHeight: proof.BlockHeight,
+	}); err != nil {
+		return errors.WithMessage(err, "failed to publish proof received event")
+	}
+	return errors.Wrap(p.transacter.Commit(ctx), "failed to commit proof")
+}
+
+// verify checks the proof block is on the best chain and that the proof target identifies that
+// block, as a header or merkle root target is trusted by the merkle proof verifier, before verifying
+// the proof against it, returning the height of the block.
+func (p *proofs) verify(ctx context.Context, proof dpp.ProofWrapper) (uint32, error) {
+	header, err := p.headers.BlockHeader(ctx, proof.BlockHash)
+	if err != nil {
+		if errors.Is(err, bc.ErrHeaderNotFound) || errors.Is(err, bc.ErrNotOnLongestChain) {
+			return 0, validator.NewSingleError("blockhash", []string{fmt.Sprintf("block %s is not on the best chain", proof.BlockHash)})
+		}
+		return 0, errors.Wrapf(err, "failed to get header for block %s", proof.BlockHash)
+	}
+	if hash := hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(header.Bytes()))); hash != proof.BlockHash {
+		return 0, errors.Errorf("header for block %s has hash %s", proof.BlockHash, hash)
+	}
+	mp := proof.CallbackPayload
+	var target string
+	switch mp.TargetType {
+	case "header":
+		target = header.String()
+	case "merkleRoot":
+		target = header.HashMerkleRootStr()
+	default:
+		target = proof.BlockHash
+	}
+	if !strings.EqualFold(mp.Target, target) {
+		return 0, validator.NewSingleError("callbackPayload.target", []string{fmt.Sprintf("target is not the %s of block %s", mp.TargetType, proof.BlockHash)})
+	}
+	valid, _, err := p.verifier.VerifyMerkleProofJSON(ctx, mp)
+	if err != nil {
+		return 0, validator.NewSingleError("callbackPayload", []string{err.Error()})
+	}
+	if !valid {
+		return 0, validator.NewSingleError("callbackPayload", []string{fmt.Sprintf("merkle root doesn't match the merkle root of block %s", proof.BlockHash)})
+	}
+	height, err := p.headers.BlockHeight(ctx, proof.BlockHash)
+	if err != nil {
+		if errors.Is(err, bc.ErrHeaderNotFound) || errors.Is(err, bc.ErrNotOnLongestChain) {
+			return 0, validator.NewSingleError("blockhash", []string{fmt.Sprintf("block %s is not on the best chain", proof.BlockHash)})
+		}
+		return 0, errors.Wrapf(err, "failed to get height of block %s", proof.BlockHash)
+	}
+	return height, nil
 }
 
 func (p *proofs) HandlePeerChannelsMessage(ctx context.Context, msgs spvchannels.MessagesReply) (bool, error) {
@@ -4,42 +4,75 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bc/spv"
 	"github.com/libsv/go-bk/envelope"
 	"github.com/libsv/go-dpp"
 	"github.com/libsv/payd/log"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
 
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
 	"github.com/libsv/payd/mocks"
 )
 
 func Test_Proofs_create(t *testing.T) {
 	t.Parallel()
+	// block 170 of mainnet, holding the coinbase and the first tx paying between two people.
+	const (
+		txID       = "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16"
+		coinbaseID = "b1fea52486ce0c62bb442b530a3f0132b826c74e473d1f2c220bfa78111c5082"
+		coinbaseTx = "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff0704ffff001d0102ffffffff0100f2052a01000000434104d46c4968bde02899d2aa0963367c7a6ce34eec332b32e42e5f3407e052d64ac625da6f0718e7b302140434bd725706957c092db53805b821a85b23a7ac61725bac00000000"
+		root       = "7dac2c5666815c17a3b36427de37bb9d2e2c5ccec3f8633eb91a4205cb4c10ff"
+		blockHash  = "00000000d1145790a8694403d4063f323d499e655c83426834d4ce2f8dd4a2ee"
+		headerHex  = "0100000055bd840a78798ad0da853f68974f3d183e2bd1db6a842c1feecf222a00000000ff104ccb05421ab93e63f8c3ce5c2c2e9dbb37de2764b3a3175c8166562cac7d51b96a49ffff001d283e9e70"
+	)
+	header, err := bc.NewBlockHeaderFromStr(headerHex)
+	assert.NoError(t, err)
+	proof := func(mp bc.MerkleProof) envelope.JSONEnvelope {
+		e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
+			CallbackPayload: &mp,
+			BlockHash:       blockHash,
+			BlockHeight:     101,
+			CallbackTxID:    txID,
+			CallbackReason:  "merkleProof",
+		})
+		assert.NoError(t, err)
+		return *e
+	}
 	tests := map[string]struct {
 		args           dpp.ProofCreateArgs
 		req            envelope.JSONEnvelope
+		headerFn       func(ctx context.Context, blockHash string) (*bc.BlockHeader, error)
+		heightErr      error
 		proofsCreateFn func(ctx context.Context, req dpp.ProofWrapper) error
+		queueFn        func(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error
+		txUpdateErr    error
+		minConfs       uint32
+		expState       payd.TxState
 		err            error
 	}{
 		"successful run should return no errors": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+				TxID: txID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
-						Index:      0,
-						TxOrID:     "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
-						Target:     "abc123",
-						Nodes:      nil,
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
 						TargetType: "header",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    0,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+					CallbackTxID:   txID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -52,22 +85,22 @@ func Test_Proofs_create(t *testing.T) {
 			err: nil,
 		}, "mismatch txid should return error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+				TxID: coinbaseID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
-						Index:      0,
-						TxOrID:     "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
-						Target:     "abc123",
-						Nodes:      nil,
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
 						TargetType: "header",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    123,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+					CallbackTxID:   txID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -77,25 +110,25 @@ func Test_Proofs_create(t *testing.T) {
 			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
 				return nil
 			},
-			err: errors.New("[callbackPayload.txOrId: txId provided in callbackPayload doesn't match expected txID 2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb], [callbackTxID: proof txid does not match expected txid 2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb]"),
+			err: errors.New("[callbackPayload.txOrId: txId provided in callbackPayload doesn't match expected txID " + coinbaseID + "], [callbackTxID: proof txid does not match expected txid " + coinbaseID + "]"),
 		}, "mismatch txid in proof only should return single error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+				TxID: coinbaseID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
-						Index:      0,
-						TxOrID:     "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
-						Target:     "abc123",
-						Nodes:      nil,
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
 						TargetType: "header",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    123,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+					CallbackTxID:   coinbaseID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -105,16 +138,16 @@ func Test_Proofs_create(t *testing.T) {
 			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
 				return nil
 			},
-			err: errors.New("[callbackPayload.txOrId: txId provided in callbackPayload doesn't match expected txID 2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb]"),
+			err: errors.New("[callbackPayload.txOrId: txId provided in callbackPayload doesn't match expected txID " + coinbaseID + "]"),
 		}, "empty payload should return error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+				TxID: coinbaseID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    0,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+					CallbackTxID:   coinbaseID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -127,13 +160,13 @@ func Test_Proofs_create(t *testing.T) {
 			err: errors.New("[callbackPayload: value cannot be empty]"),
 		}, "invalid envelope sig should return error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+				TxID: coinbaseID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    1,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70cb",
+					CallbackTxID:   coinbaseID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -147,22 +180,22 @@ func Test_Proofs_create(t *testing.T) {
 			err: errors.New("[jsonEnvelope: invalid merkleproof envelope: failed to parse json envelope signature malformed signature: too short]"),
 		}, "invalid callback reason should error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+				TxID: txID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
-						Index:      0,
-						TxOrID:     "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
-						Target:     "abc123",
-						Nodes:      nil,
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
 						TargetType: "header",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    2,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+					CallbackTxID:   txID,
 					CallbackReason: "mine",
 				})
 				assert.NoError(t, err)
@@ -175,22 +208,22 @@ func Test_Proofs_create(t *testing.T) {
 			err: errors.New("[callbackReason: invalid callback received, should be of type merkleProof]"),
 		}, "txhex with correct txid should validate with no error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+				TxID: coinbaseID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
 						Index:      0,
-						TxOrID:     "02000000010000000000000000000000000000000000000000000000000000000000000000ffffffff0502a5000101ffffffff0100f9029500000000232103e59d8dfdd42499ceaf97362c64b997abaafc872efacc7a2011df98ee41fe84b7ac00000000",
-						Target:     "abc123",
-						Nodes:      nil,
+						TxOrID:     coinbaseTx,
+						Target:     header.String(),
+						Nodes:      []string{txID},
 						TargetType: "header",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    7,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+					CallbackTxID:   coinbaseID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -202,22 +235,22 @@ func Test_Proofs_create(t *testing.T) {
 			},
 		}, "invalid targetType should error": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+				TxID: txID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
-						Index:      0,
-						TxOrID:     "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
-						Target:     "abc123",
-						Nodes:      nil,
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
 						TargetType: "mine",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    0,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+					CallbackTxID:   txID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -230,22 +263,22 @@ func Test_Proofs_create(t *testing.T) {
 			err: errors.New("[callbackPayload.targetType: value not found in allowed values]"),
 		}, "error from proof create should be echoed back": {
 			args: dpp.ProofCreateArgs{
-				TxID: "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+				TxID: txID,
 			},
 			req: func() envelope.JSONEnvelope {
 				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
 					CallbackPayload: &bc.MerkleProof{
-						Index:      0,
-						TxOrID:     "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
-						Target:     "abc123",
-						Nodes:      nil,
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
 						TargetType: "header",
 						ProofType:  "",
 						Composite:  false,
 					},
-					BlockHash:      "abc123",
+					BlockHash:      blockHash,
 					BlockHeight:    0,
-					CallbackTxID:   "2f8d0ac044aa2fd8fc7675809f5d17acac4e9bf63dd0ea4eb58f43b66ccc70ca",
+					CallbackTxID:   txID,
 					CallbackReason: "merkleProof",
 				})
 				assert.NoError(t, err)
@@ -256,12 +289,173 @@ func Test_Proofs_create(t *testing.T) {
 				return errors.New("I failed")
 			},
 			err: errors.New("failed to save proof: I failed"),
+		}, "error from queueing proof callbacks should be echoed back": {
+			args: dpp.ProofCreateArgs{
+				TxID: txID,
+			},
+			req: func() envelope.JSONEnvelope {
+				e, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
+					CallbackPayload: &bc.MerkleProof{
+						Index:      1,
+						TxOrID:     txID,
+						Target:     header.String(),
+						Nodes:      []string{coinbaseID},
+						TargetType: "header",
+						ProofType:  "",
+						Composite:  false,
+					},
+					BlockHash:      blockHash,
+					BlockHeight:    0,
+					CallbackTxID:   txID,
+					CallbackReason: "merkleProof",
+				})
+				assert.NoError(t, err)
+				assert.NotEmpty(t, e)
+				return *e
+			}(),
+			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
+				return nil
+			},
+			queueFn: func(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+				return errors.New("I failed")
+			},
+			err: errors.New("failed to queue proof callbacks: I failed"),
+		}, "proof with a block hash target should validate with no error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
+				return nil
+			},
+		}, "proof with a merkle root target should validate with no error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: root, TargetType: "merkleRoot", Nodes: []string{coinbaseID}}),
+			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
+				return nil
+			},
+		}, "proof not matching the block merkle root should error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 0, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			err:  errors.New("[callbackPayload: merkle root doesn't match the merkle root of block " + blockHash + "]"),
+		}, "proof with a duplicate left node should error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{"*"}}),
+			err:  errors.New("[callbackPayload: invalid nodes]"),
+		}, "composite proof should error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}, Composite: true}),
+			err:  errors.New("[callbackPayload: only single proof supported in this version]"),
+		}, "target that isn't the proof block should error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: coinbaseID, TargetType: "merkleRoot", Nodes: []string{coinbaseID}}),
+			err:  errors.New("[callbackPayload.target: target is not the merkleRoot of block " + blockHash + "]"),
+		}, "block not on the best chain should error": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			headerFn: func(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+				return nil, bc.ErrNotOnLongestChain
+			},
+			err: errors.New("[blockhash: block " + blockHash + " is not on the best chain]"),
+		}, "error from header chain should be echoed back": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			headerFn: func(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+				return nil, errors.New("I failed")
+			},
+			err: errors.New("failed to get header for block " + blockHash + ": I failed"),
+		}, "block dropped from the best chain after the header lookup should error": {
+			args:      dpp.ProofCreateArgs{TxID: txID},
+			req:       proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			heightErr: bc.ErrNotOnLongestChain,
+			err:       errors.New("[blockhash: block " + blockHash + " is not on the best chain]"),
+		}, "error from block height lookup should be echoed back": {
+			args:      dpp.ProofCreateArgs{TxID: txID},
+			req:       proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			heightErr: errors.New("I failed"),
+			err:       errors.New("failed to get height of block " + blockHash + ": I failed"),
+		}, "proof should set the tx confirmed at the height of the block, not the payload height": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
+				return nil
+			},
+			minConfs: 1,
+			expState: payd.StateTxConfirmed,
+		}, "proof needing more confirmations should set the tx mined": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
+				return nil
+			},
+			minConfs: 6,
+			expState: payd.StateTxMined,
+		}, "error from updating tx state should be echoed back": {
+			args: dpp.ProofCreateArgs{TxID: txID},
+			req:  proof(bc.MerkleProof{Index: 1, TxOrID: txID, Target: blockHash, TargetType: "hash", Nodes: []string{coinbaseID}}),
+			proofsCreateFn: func(ctx context.Context, req dpp.ProofWrapper) error {
+				return nil
+			},
+			txUpdateErr: errors.New("I failed"),
+			err:         errors.New("failed to set tx " + txID + " confirmed: I failed"),
 		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			headerFn := test.headerFn
+			if headerFn == nil {
+				headerFn = func(ctx context.Context, hash string) (*bc.BlockHeader, error) {
+					assert.Equal(t, blockHash, hash)
+					return header, nil
+				}
+			}
+			headers := &mocks.HeaderChainMock{
+				BlockHeaderFunc: headerFn,
+				BlockHeightFunc: func(ctx context.Context, hash string) (uint32, error) {
+					assert.Equal(t, blockHash, hash)
+					return 120, test.heightErr
+				},
+			}
+			verifier, err := spv.NewMerkleProofVerifier(headers)
+			assert.NoError(t, err)
 			mockProofWrtr := &mocks.ProofsWriterMock{ProofCreateFunc: test.proofsCreateFn}
-			err := NewProofsService(mockProofWrtr, log.Noop{}).Create(context.Background(), test.args, test.req)
+			mockCallbackWrtr := &mocks.ProofCallbackWriterMock{
+				ProofCallbacksQueueFunc: func(ctx context.Context, args payd.ProofCallbackArgs, req payd.ProofCallbacksQueue) error {
+					assert.Equal(t, test.args.TxID, args.TxID)
+					assert.NotEmpty(t, req.Proof)
+					if test.queueFn != nil {
+						return test.queueFn(ctx, args, req)
+					}
+					return nil
+				},
+			}
+			mockTxWrtr := &mocks.TransactionWriterMock{
+				TransactionUpdateStateFunc: func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+					assert.Equal(t, test.args.TxID, args.TxID)
+					if test.expState != "" {
+						assert.Equal(t, test.expState, req.State)
+						assert.Equal(t, null.IntFrom(120), req.BlockHeight)
+					}
+					return test.txUpdateErr
+				},
+			}
+			err = NewProofsService(mockProofWrtr, mockTxWrtr, mockCallbackWrtr, &mocks.WebhookPublisherMock{
+				PublishFunc: func(context.Context, payd.WebhookEvent, interface{}) error {
+					return nil
+				},
+			}, &mocks.TransacterMock{
+				WithTxFunc: func(ctx context.Context) context.Context {
+					return ctx
+				},
+				RollbackFunc: func(context.Context) error {
+					return nil
+				},
+				CommitFunc: func(context.Context) error {
+					return nil
+				},
+			}, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+				},
+			}, headers, verifier, &config.Wallet{MinConfirmations: test.minConfs}, log.Noop{}).Create(context.Background(), test.args, test.req)
 			if test.err != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, test.err.Error())
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+	"github.com/libsv/payd/log"
+)
+
+type refunds struct {
+	l           log.Logger
+	store       payd.InvoiceReaderWriter
+	envSvc      payd.EnvelopeService
+	txWtr       payd.TransactionWriter
+	feeFetcher  payd.FeeQuoteFetcher
+	broadcaster payd.BroadcastWriter
+	paymailWtr  payd.PaymailWriter
+	transacter  payd.Transacter
+}
+
+// NewRefunds will setup and return a refund service, used to pay customers back
+// from the wallet.
+func NewRefunds(l log.Logger, store payd.InvoiceReaderWriter, envSvc payd.EnvelopeService, txWtr payd.TransactionWriter, feeFetcher payd.FeeQuoteFetcher, broadcaster payd.BroadcastWriter, paymailWtr payd.PaymailWriter, transacter payd.Transacter) *refunds {
+	return &refunds{
+		l:           l,
+		store:       store,
+		envSvc:      envSvc,
+		txWtr:       txWtr,
+		feeFetcher:  feeFetcher,
+		broadcaster: broadcaster,
+		paymailWtr:  paymailWtr,
+		transacter:  transacter,
+	}
+}
+
+// Refund will fund and sign a tx from the wallet paying the invoice RefundTo address, this
+// can be a paymail or a locking script. The tx is broadcast and recorded against the invoice,
+// which is set as refunded once everything received has been refunded, then sent to a paymail.
+func (r *refunds) Refund(ctx context.Context, args payd.InvoiceArgs, req payd.RefundCreate) (*payd.InvoiceRefund, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	ctx = r.transacter.WithTx(ctx)
+	defer func() {
+		_ = r.transacter.Rollback(ctx)
+	}()
+	inv, err := r.store.Invoice(ctx, args)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get invoice with id %s", args.InvoiceID)
+	}
+	// expired invoices may have been partially paid before they expired.
+	refundable := inv.State == payd.StateInvoicePaid || inv.State == payd.StateInvoicePartiallyPaid || inv.State == payd.StateInvoiceExpired
+	if !refundable || inv.SatoshisReceived == 0 {
+		return nil, lathos.NewErrUnprocessable(errcodes.ErrRefundInvoiceNotPaid,
+			fmt.Sprintf("invoice %s is %s, only invoices that have received payment can be refunded", inv.ID, inv.State))
+	}
+	if !inv.RefundTo.Valid {
+		return nil, lathos.NewErrUnprocessable(errcodes.ErrRefundNoRefundTo,
+			fmt.Sprintf("invoice %s has no refund address, the customer didn't supply one with their payment", inv.ID))
+	}
+	remaining := inv.SatoshisReceived - inv.SatoshisRefunded
+	if req.Satoshis == 0 {
+		req.Satoshis = remaining
+	}
+	if err := validator.New().
+		Validate("satoshis", validator.MaxUInt64(req.Satoshis, remaining)).Err(); err != nil {
+		return nil, err
+	}
+	oo, reference, err := r.outputs(ctx, inv.RefundTo.String, req.Satoshis)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := r.feeFetcher.FeeQuote(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fees for refund")
+	}
+	// the envelope service reserves, signs and spends the wallet utxos used to fund the refund, each
+	// refund has its own reservation so spending them doesn't touch the utxos of earlier refunds.
+	env, err := r.envSvc.Envelope(ctx, payd.EnvelopeArgs{PayToURL: "refund/" + inv.ID + "/" + uuid.NewString()}, dpp.PaymentRequest{
+		Destinations: dpp.PaymentDestinations{Outputs: oo},
+		FeeRate:      fees,
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to create refund tx for invoice %s", inv.ID)
+	}
+	tx, err := bt.NewTxFromString(env.RawTx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse refund tx for invoice %s", inv.ID)
+	}
+	// the refund is recorded before it is broadcast, so a refund that can't be recorded is never paid.
+	refund := &payd.InvoiceRefund{
+		InvoiceID: inv.ID,
+		TxID:      env.TxID,
+		Satoshis:  req.Satoshis,
+		RefundTo:  inv.RefundTo.String,
+		CreatedAt: time.Now().UTC(),
+	}
+	refunded, err := r.store.InvoiceRefund(ctx, payd.InvoiceUpdateArgs{InvoiceID: inv.ID}, payd.InvoiceUpdateRefunded{
+		RefundTo:   inv.RefundTo,
+		RefundedAt: null.TimeFrom(refund.CreatedAt),
+		TxID:       refund.TxID,
+		Satoshis:   refund.Satoshis,
+	})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to record refund against invoice %s", inv.ID)
+	}
+	// the store leaves the invoice unchanged if a concurrent refund means this one is more than is left.
+	if refunded.SatoshisRefunded != inv.SatoshisRefunded+req.Satoshis {
+		return nil, lathos.NewErrUnprocessable(errcodes.ErrRefundExceeded,
+			fmt.Sprintf("invoice %s has been refunded since this refund was requested, refund %d satoshis exceeds what is left to refund",
+				inv.ID, req.Satoshis))
+	}
+	// nothing is committed if the broadcast fails, releasing the utxos and the refund.
+	if err := r.broadcaster.Broadcast(ctx, payd.BroadcastArgs{InvoiceID: inv.ID}, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to broadcast refund tx for invoice %s", inv.ID)
+	}
+	// Just logging errors from here as the refund is broadcast, the spent utxos must be committed.
+	if err := r.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: env.TxID}, payd.TransactionStateUpdate{State: payd.StateTxBroadcast}); err != nil {
+		r.l.Error(err, "failed to update refund tx to broadcast state")
+	}
+	if err := r.transacter.Commit(ctx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit refund for invoice %s", inv.ID)
+	}
+	// a paymail is sent the tx with the reference of its outputs so it can match the refund, the tx
+	// is already broadcast so failures are only logged.
+	if alias, domain, ok := strings.Cut(inv.RefundTo.String, "@"); ok {
+		if err := r.paymailWtr.TransactionSubmit(ctx, payd.P2PTransactionArgs{
+			Alias:     alias,
+			Domain:    domain,
+			PaymentID: reference,
+			TxHex:     env.RawTx,
+		}, payd.P2PTransaction{
+			TxHex:    env.RawTx,
+			Metadata: payd.P2PTransactionMetadata{Note: fmt.Sprintf("refund of invoice %s", inv.ID)},
+		}); err != nil {
+			r.l.Error(err, "failed to send refund tx to paymail")
+		}
+	}
+	return refund, nil
+}
+
+// outputs returns the outputs paying satoshis to refundTo, a paymail is asked for
+// its outputs and the reference to send with the tx, otherwise refundTo must be a locking script.
+func (r *refunds) outputs(ctx context.Context, refundTo string, satoshis uint64) ([]dpp.Output, string, error) {
+	if alias, domain, ok := strings.Cut(refundTo, "@"); ok {
+		dest, err := r.paymailWtr.OutputsCreate(ctx, payd.P2POutputCreateArgs{Alias: alias, Domain: domain}, payd.P2PPayment{Satoshis: satoshis})
+		if err != nil {
+			return nil, "", errors.WithMessagef(err, "failed to get refund outputs for paymail %s", refundTo)
+		}
+		resp := make([]dpp.Output, 0, len(dest.Outputs))
+		for _, o := range dest.Outputs {
+			resp = append(resp, dpp.Output{Amount: o.Satoshis, LockingScript: o.LockingScript})
+		}
+		return resp, dest.Reference, nil
+	}
+	s, err := bscript.NewFromHexString(refundTo)
+	if err != nil || len(*s) == 0 {
+		return nil, "", lathos.NewErrUnprocessable(errcodes.ErrRefundNoRefundTo,
+			fmt.Sprintf("refund address %s is not a paymail or locking script", refundTo))
+	}
+	return []dpp.Output{{Amount: satoshis, LockingScript: s}}, "", nil
+}
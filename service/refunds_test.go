@@ -0,0 +1,307 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/libsv/go-bc/spv"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lathos "github.com/theflyingcodr/lathos/errs"
+	"gopkg.in/guregu/null.v3"
+)
+
+func TestRefundService_Refund(t *testing.T) {
+	const refundTx = "010000000001e8030000000000001976a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac00000000"
+	refundScript, err := bscript.NewFromHexString("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac")
+	assert.NoError(t, err)
+	paidInvoice := func(refundTo string, refunded uint64) func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error) {
+		return func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+			return &payd.Invoice{
+				ID:               args.InvoiceID,
+				Satoshis:         1000,
+				SatoshisReceived: 1000,
+				SatoshisRefunded: refunded,
+				RefundTo:         null.NewString(refundTo, refundTo != ""),
+				State:            payd.StateInvoicePaid,
+			}, nil
+		}
+	}
+	tests := map[string]struct {
+		invoiceFunc    func(context.Context, payd.InvoiceArgs) (*payd.Invoice, error)
+		outputsFunc    func(context.Context, payd.P2POutputCreateArgs, payd.P2PPayment) (*payd.P2PPaymentDestination, error)
+		submitErr      error
+		broadcastFunc  func(context.Context, payd.BroadcastArgs, *bt.Tx) error
+		commitFunc     func(context.Context) error
+		refundErr      error
+		refundRaced    bool
+		args           payd.InvoiceArgs
+		req            payd.RefundCreate
+		expOutputs     []dpp.Output
+		expPaymailArgs *payd.P2POutputCreateArgs
+		expSatoshis    uint64
+		expErr         error
+	}{
+		"full refund to a paymail": {
+			invoiceFunc: paidInvoice("me@paymail.com", 0),
+			outputsFunc: func(ctx context.Context, args payd.P2POutputCreateArgs, req payd.P2PPayment) (*payd.P2PPaymentDestination, error) {
+				return &payd.P2PPaymentDestination{
+					Outputs:   []*bt.Output{{LockingScript: refundScript, Satoshis: req.Satoshis}},
+					Reference: "ref1",
+				}, nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			args:           payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:     []dpp.Output{{Amount: 1000, LockingScript: refundScript}},
+			expPaymailArgs: &payd.P2POutputCreateArgs{Alias: "me", Domain: "paymail.com"},
+			expSatoshis:    1000,
+		},
+		"paymail submit error is only logged": {
+			invoiceFunc: paidInvoice("me@paymail.com", 0),
+			outputsFunc: func(ctx context.Context, args payd.P2POutputCreateArgs, req payd.P2PPayment) (*payd.P2PPaymentDestination, error) {
+				return &payd.P2PPaymentDestination{
+					Outputs:   []*bt.Output{{LockingScript: refundScript, Satoshis: req.Satoshis}},
+					Reference: "ref1",
+				}, nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			submitErr:      errors.New("paymail is down"),
+			args:           payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:     []dpp.Output{{Amount: 1000, LockingScript: refundScript}},
+			expPaymailArgs: &payd.P2POutputCreateArgs{Alias: "me", Domain: "paymail.com"},
+			expSatoshis:    1000,
+		},
+		"part refund to a locking script": {
+			invoiceFunc: paidInvoice("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac", 0),
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			req:         payd.RefundCreate{Satoshis: 400},
+			expOutputs:  []dpp.Output{{Amount: 400, LockingScript: refundScript}},
+			expSatoshis: 400,
+		},
+		"refund without an amount refunds what is left": {
+			invoiceFunc: paidInvoice("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac", 600),
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:  []dpp.Output{{Amount: 400, LockingScript: refundScript}},
+			expSatoshis: 400,
+		},
+		"refund of more than is left is rejected": {
+			invoiceFunc: paidInvoice("me@paymail.com", 600),
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			req:         payd.RefundCreate{Satoshis: 500},
+			expErr:      errors.New("[satoshis: value 500 is larger than maximum 400]"),
+		},
+		"pending invoice is rejected": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, RefundTo: null.StringFrom("me@paymail.com"), State: payd.StateInvoicePending}, nil
+			},
+			args:   payd.InvoiceArgs{InvoiceID: "abc123"},
+			expErr: lathos.NewErrUnprocessable("R0001", "invoice abc123 is pending, only invoices that have received payment can be refunded"),
+		},
+		"expired invoice is refunded what it received": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{
+					ID:               args.InvoiceID,
+					Satoshis:         1000,
+					SatoshisReceived: 300,
+					RefundTo:         null.StringFrom("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac"),
+					State:            payd.StateInvoiceExpired,
+				}, nil
+			},
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return nil
+			},
+			commitFunc: func(context.Context) error {
+				return nil
+			},
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:  []dpp.Output{{Amount: 300, LockingScript: refundScript}},
+			expSatoshis: 300,
+		},
+		"expired invoice without payments is rejected": {
+			invoiceFunc: func(ctx context.Context, args payd.InvoiceArgs) (*payd.Invoice, error) {
+				return &payd.Invoice{ID: args.InvoiceID, RefundTo: null.StringFrom("me@paymail.com"), State: payd.StateInvoiceExpired}, nil
+			},
+			args:   payd.InvoiceArgs{InvoiceID: "abc123"},
+			expErr: lathos.NewErrUnprocessable("R0001", "invoice abc123 is expired, only invoices that have received payment can be refunded"),
+		},
+		"invoice without a refund address is rejected": {
+			invoiceFunc: paidInvoice("", 0),
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expErr:      lathos.NewErrUnprocessable("R0002", "invoice abc123 has no refund address, the customer didn't supply one with their payment"),
+		},
+		"invalid refund address is rejected": {
+			invoiceFunc: paidInvoice("not a script", 0),
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expErr:      lathos.NewErrUnprocessable("R0002", "refund address not a script is not a paymail or locking script"),
+		},
+		"paymail error is reported": {
+			invoiceFunc: paidInvoice("me@paymail.com", 0),
+			outputsFunc: func(context.Context, payd.P2POutputCreateArgs, payd.P2PPayment) (*payd.P2PPaymentDestination, error) {
+				return nil, errors.New("no capability")
+			},
+			args:   payd.InvoiceArgs{InvoiceID: "abc123"},
+			expErr: errors.New("failed to get refund outputs for paymail me@paymail.com: no capability"),
+		},
+		"refund error is reported and nothing broadcast": {
+			invoiceFunc: paidInvoice("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac", 0),
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				t.Error("refund was broadcast")
+				return nil
+			},
+			refundErr:   errors.New("whoopsie"),
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:  []dpp.Output{{Amount: 1000, LockingScript: refundScript}},
+			expSatoshis: 1000,
+			expErr:      errors.New("failed to record refund against invoice abc123: whoopsie"),
+		},
+		"refund exceeding what is left after a concurrent refund is rejected": {
+			invoiceFunc: paidInvoice("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac", 0),
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				t.Error("refund was broadcast")
+				return nil
+			},
+			refundRaced: true,
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:  []dpp.Output{{Amount: 1000, LockingScript: refundScript}},
+			expSatoshis: 1000,
+			expErr: lathos.NewErrUnprocessable("R0003",
+				"invoice abc123 has been refunded since this refund was requested, refund 1000 satoshis exceeds what is left to refund"),
+		},
+		"broadcast error is reported and nothing committed": {
+			invoiceFunc: paidInvoice("76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac", 0),
+			broadcastFunc: func(context.Context, payd.BroadcastArgs, *bt.Tx) error {
+				return errors.New("rejected")
+			},
+			args:        payd.InvoiceArgs{InvoiceID: "abc123"},
+			expOutputs:  []dpp.Output{{Amount: 1000, LockingScript: refundScript}},
+			expSatoshis: 1000,
+			expErr:      errors.New("failed to broadcast refund tx for invoice abc123: rejected"),
+		},
+		"invalid invoice args are rejected": {
+			expErr: errors.New("[invoiceID: value must be between 1 and 30 characters]"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &mocks.InvoiceReaderWriterMock{
+				InvoiceFunc: test.invoiceFunc,
+				InvoiceRefundFunc: func(ctx context.Context, args payd.InvoiceUpdateArgs, req payd.InvoiceUpdateRefunded) (*payd.Invoice, error) {
+					assert.Equal(t, test.args.InvoiceID, args.InvoiceID)
+					assert.Equal(t, test.expSatoshis, req.Satoshis)
+					assert.NotEmpty(t, req.TxID)
+					assert.True(t, req.RefundedAt.Valid)
+					if test.refundErr != nil {
+						return nil, test.refundErr
+					}
+					inv, err := test.invoiceFunc(ctx, payd.InvoiceArgs{InvoiceID: args.InvoiceID})
+					assert.NoError(t, err)
+					// a refund racing this one leaves the invoice unchanged.
+					if !test.refundRaced {
+						inv.SatoshisRefunded += req.Satoshis
+					}
+					return inv, nil
+				},
+			}
+			paymail := &mocks.PaymailWriterMock{
+				OutputsCreateFunc: test.outputsFunc,
+				TransactionSubmitFunc: func(context.Context, payd.P2PTransactionArgs, payd.P2PTransaction) error {
+					return test.submitErr
+				},
+			}
+			envSvc := &mocks.EnvelopeServiceMock{
+				EnvelopeFunc: func(ctx context.Context, args payd.EnvelopeArgs, req dpp.PaymentRequest) (*spv.Envelope, error) {
+					assert.True(t, strings.HasPrefix(args.PayToURL, "refund/"+test.args.InvoiceID+"/"))
+					assert.Equal(t, test.expOutputs, req.Destinations.Outputs)
+					assert.NotNil(t, req.FeeRate)
+					tx, err := bt.NewTxFromString(refundTx)
+					assert.NoError(t, err)
+					return &spv.Envelope{TxID: tx.TxID(), RawTx: refundTx}, nil
+				},
+			}
+			txWtr := &mocks.TransactionWriterMock{
+				TransactionUpdateStateFunc: func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+					assert.Equal(t, payd.StateTxBroadcast, req.State)
+					return nil
+				},
+			}
+			transacter := &mocks.TransacterMock{
+				WithTxFunc: func(ctx context.Context) context.Context {
+					return ctx
+				},
+				RollbackFunc: func(context.Context) error {
+					return nil
+				},
+				CommitFunc: test.commitFunc,
+			}
+			svc := service.NewRefunds(log.Noop{}, store, envSvc, txWtr,
+				&mocks.FeeQuoteFetcherMock{
+					FeeQuoteFunc: func(context.Context) (*bt.FeeQuote, error) {
+						return bt.NewFeeQuote(), nil
+					},
+				},
+				&mocks.BroadcastWriterMock{BroadcastFunc: test.broadcastFunc},
+				paymail, transacter)
+
+			refund, err := svc.Refund(context.Background(), test.args, test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, transacter.CommitCalls())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expSatoshis, refund.Satoshis)
+			assert.Equal(t, test.args.InvoiceID, refund.InvoiceID)
+			assert.NotEmpty(t, refund.TxID)
+			assert.Len(t, store.InvoiceRefundCalls(), 1)
+			assert.Len(t, transacter.CommitCalls(), 1)
+			if test.expPaymailArgs != nil {
+				assert.Len(t, paymail.OutputsCreateCalls(), 1)
+				assert.Equal(t, *test.expPaymailArgs, paymail.OutputsCreateCalls()[0].Args)
+				assert.Equal(t, test.expSatoshis, paymail.OutputsCreateCalls()[0].Req.Satoshis)
+				// the paymail is sent the refund tx with the reference of its outputs.
+				require.Len(t, paymail.TransactionSubmitCalls(), 1)
+				assert.Equal(t, payd.P2PTransactionArgs{
+					Alias:     test.expPaymailArgs.Alias,
+					Domain:    test.expPaymailArgs.Domain,
+					PaymentID: "ref1",
+					TxHex:     refundTx,
+				}, paymail.TransactionSubmitCalls()[0].Args)
+				assert.Equal(t, refundTx, paymail.TransactionSubmitCalls()[0].Req.TxHex)
+			} else {
+				assert.Empty(t, paymail.TransactionSubmitCalls())
+			}
+		})
+	}
+}
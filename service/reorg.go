@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/libsv/go-bc"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+)
+
+type reorg struct {
+	l          log.Logger
+	tipRdr     payd.ChainTipReader
+	headers    bc.BlockHeaderChain
+	proofRdr   payd.ProofsReader
+	proofWtr   payd.ProofsWriter
+	txWtr      payd.TransactionWriter
+	statusRdr  payd.BroadcastReader
+	proofSvc   payd.ProofsService
+	webhooks   payd.WebhookPublisher
+	transacter payd.Transacter
+	timeSvc    payd.TimestampService
+	depth      uint32
+
+	mu      sync.Mutex
+	lastTip string
+}
+
+// NewReorg will setup and return a new reorg service, used to find stored merkle proofs
+// whose block has been orphaned by a chain reorganisation.
+func NewReorg(l log.Logger, cfg *config.Wallet, tipRdr payd.ChainTipReader, headers bc.BlockHeaderChain, proofRdr payd.ProofsReader,
+	proofWtr payd.ProofsWriter, txWtr payd.TransactionWriter, statusRdr payd.BroadcastReader, proofSvc payd.ProofsService,
+	webhooks payd.WebhookPublisher, transacter payd.Transacter, timeSvc payd.TimestampService) *reorg {
+	return &reorg{
+		l:          l,
+		tipRdr:     tipRdr,
+		headers:    headers,
+		proofRdr:   proofRdr,
+		proofWtr:   proofWtr,
+		txWtr:      txWtr,
+		statusRdr:  statusRdr,
+		proofSvc:   proofSvc,
+		webhooks:   webhooks,
+		transacter: transacter,
+		timeSvc:    timeSvc,
+		depth:      cfg.ReorgDepth,
+	}
+}
+
+// ProofsReorg will check the blocks of the proofs stored for the last ReorgDepth blocks are still
+// on the best chain, whenever the chain tip changes. The proofs in an orphaned block are marked stale
+// and their txs are reverted to broadcast, then a new proof is requested from mapi.
+//
+// A tx spending the change of a reverted tx can only be mined in the same block or a block built
+// on top of it, so it is reverted along with its parent when that block is found to be orphaned too.
+func (r *reorg) ProofsReorg(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tip, err := r.tipRdr.ChainTip(ctx)
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get chain tip")
+	}
+	if tip.Hash == r.lastTip {
+		return 0, nil
+	}
+	var minHeight uint32
+	if tip.Height > r.depth {
+		minHeight = tip.Height - r.depth
+	}
+	blocks, err := r.proofRdr.ProofBlocks(ctx, payd.ProofBlocksArgs{MinBlockHeight: minHeight})
+	if err != nil {
+		return 0, errors.WithMessagef(err, "failed to get proof blocks at or above height %d", minHeight)
+	}
+	var reverted []string
+	for _, b := range blocks {
+		if _, err := r.headers.BlockHeader(ctx, b.BlockHash); err == nil {
+			continue
+		} else if !errors.Is(err, bc.ErrHeaderNotFound) && !errors.Is(err, bc.ErrNotOnLongestChain) {
+			return len(reverted), errors.Wrapf(err, "failed to get header for block %s", b.BlockHash)
+		}
+		txIDs, err := r.orphan(ctx, b)
+		if err != nil {
+			return len(reverted), errors.WithMessagef(err, "failed to revert txs in orphaned block %s", b.BlockHash)
+		}
+		r.l.Warnf("block %s at height %d was orphaned, %d txs reverted to broadcast", b.BlockHash, b.BlockHeight, len(txIDs))
+		reverted = append(reverted, txIDs...)
+	}
+	// proofs are requested once every orphaned block is reverted, as a tx in a block that
+	// is still to be reverted would otherwise fail its proof.
+	for _, txID := range reverted {
+		r.reproof(ctx, txID)
+	}
+	r.lastTip = tip.Hash
+	return len(reverted), nil
+}
+
+// orphan marks the proofs in a block stale and reverts their txs to broadcast.
+func (r *reorg) orphan(ctx context.Context, b payd.ProofBlock) ([]string, error) {
+	ctx = r.transacter.WithTx(ctx)
+	defer func() {
+		_ = r.transacter.Rollback(ctx)
+	}()
+	txIDs, err := r.proofWtr.ProofsStale(ctx, payd.ProofsStaleArgs{
+		BlockHash: b.BlockHash,
+		StaleAt:   r.timeSvc.NowUTC(),
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to mark proofs stale")
+	}
+	for _, txID := range txIDs {
+		if err := r.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: txID}, payd.TransactionStateUpdate{
+			State: payd.StateTxBroadcast,
+		}); err != nil {
+			return nil, errors.WithMessagef(err, "failed to set tx %s %s", txID, payd.StateTxBroadcast)
+		}
+		if err := r.webhooks.Publish(ctx, payd.WebhookEventProofOrphaned, payd.WebhookProofEvent{
+			TxID:        txID,
+			BlockHash:   b.BlockHash,
+			BlockHeight: b.BlockHeight,
+		}); err != nil {
+			return nil, errors.WithMessage(err, "failed to publish proof orphaned event")
+		}
+	}
+	return txIDs, errors.Wrap(r.transacter.Commit(ctx), "failed to commit reverted txs")
+}
+
+// reproof asks mapi for the new proof of a reverted tx. Failures are only logged as the
+// tx stays broadcast until mapi sends the proof to its callback.
+func (r *reorg) reproof(ctx context.Context, txID string) {
+	status, err := r.statusRdr.BroadcastStatus(ctx, txID)
+	if err != nil {
+		r.l.Errorf(err, "failed to query status of orphaned tx %s", txID)
+		return
+	}
+	if status.Proof == nil || status.BlockHash == "" {
+		r.l.Infof("orphaned tx %s has not been mined again yet", txID)
+		return
+	}
+	if err := storeStatusProof(ctx, r.proofSvc, status); err != nil {
+		r.l.Errorf(err, "failed to store new proof of orphaned tx %s", txID)
+	}
+}
@@ -0,0 +1,255 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestReorgService_ProofsReorg(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	blocks := []payd.ProofBlock{
+		{BlockHash: "orphan2", BlockHeight: 101},
+		{BlockHash: "orphan1", BlockHeight: 100},
+		{BlockHash: "best", BlockHeight: 99},
+	}
+	proofTxs := map[string][]string{
+		"orphan2": {"child"},
+		"orphan1": {"parent", "sibling"},
+	}
+	newProof := &bc.MerkleProof{Index: 1, TxOrID: "parent", Target: "newblock", Nodes: []string{"abc"}}
+	tests := map[string]struct {
+		depth      uint32
+		tipErr     error
+		blocksErr  error
+		headerErr  error
+		staleErr   error
+		updateErr  error
+		statusErr  error
+		createErr  error
+		expMin     uint32
+		expStale   []string
+		expUpdated []string
+		expEvents  []payd.WebhookProofEvent
+		expCreated []string
+		expN       int
+		expErr     error
+	}{
+		"txs in orphaned blocks are reverted and their new proofs stored": {
+			depth:      10,
+			expMin:     95,
+			expStale:   []string{"orphan2", "orphan1"},
+			expUpdated: []string{"child", "parent", "sibling"},
+			expEvents: []payd.WebhookProofEvent{
+				{TxID: "child", BlockHash: "orphan2", BlockHeight: 101},
+				{TxID: "parent", BlockHash: "orphan1", BlockHeight: 100},
+				{TxID: "sibling", BlockHash: "orphan1", BlockHeight: 100},
+			},
+			expCreated: []string{"parent"},
+			expN:       3,
+		},
+		"every proof block is checked when the chain is shorter than the depth": {
+			depth:      200,
+			expStale:   []string{"orphan2", "orphan1"},
+			expUpdated: []string{"child", "parent", "sibling"},
+			expEvents: []payd.WebhookProofEvent{
+				{TxID: "child", BlockHash: "orphan2", BlockHeight: 101},
+				{TxID: "parent", BlockHash: "orphan1", BlockHeight: 100},
+				{TxID: "sibling", BlockHash: "orphan1", BlockHeight: 100},
+			},
+			expCreated: []string{"parent"},
+			expN:       3,
+		},
+		"proofs that can't be fetched again are only logged": {
+			depth:      10,
+			statusErr:  errors.New("mapi is down"),
+			expMin:     95,
+			expStale:   []string{"orphan2", "orphan1"},
+			expUpdated: []string{"child", "parent", "sibling"},
+			expEvents: []payd.WebhookProofEvent{
+				{TxID: "child", BlockHash: "orphan2", BlockHeight: 101},
+				{TxID: "parent", BlockHash: "orphan1", BlockHeight: 100},
+				{TxID: "sibling", BlockHash: "orphan1", BlockHeight: 100},
+			},
+			expN: 3,
+		},
+		"proofs that fail to be stored are only logged": {
+			depth:      10,
+			createErr:  errors.New("invalid proof"),
+			expMin:     95,
+			expStale:   []string{"orphan2", "orphan1"},
+			expUpdated: []string{"child", "parent", "sibling"},
+			expEvents: []payd.WebhookProofEvent{
+				{TxID: "child", BlockHash: "orphan2", BlockHeight: 101},
+				{TxID: "parent", BlockHash: "orphan1", BlockHeight: 100},
+				{TxID: "sibling", BlockHash: "orphan1", BlockHeight: 100},
+			},
+			expCreated: []string{"parent"},
+			expN:       3,
+		},
+		"chain tip error is reported": {
+			depth:  10,
+			tipErr: errors.New("headers-sv is down"),
+			expErr: errors.New("failed to get chain tip: headers-sv is down"),
+		},
+		"proof blocks error is reported": {
+			depth:     10,
+			blocksErr: errors.New("whoopsie"),
+			expMin:    95,
+			expErr:    errors.New("failed to get proof blocks at or above height 95: whoopsie"),
+		},
+		"header error is reported": {
+			depth:     10,
+			headerErr: errors.New("timeout"),
+			expMin:    95,
+			expErr:    errors.New("failed to get header for block orphan2: timeout"),
+		},
+		"stale error is reported": {
+			depth:    10,
+			staleErr: errors.New("whoopsie"),
+			expMin:   95,
+			expStale: []string{"orphan2"},
+			expErr:   errors.New("failed to revert txs in orphaned block orphan2: failed to mark proofs stale: whoopsie"),
+		},
+		"tx update error is reported": {
+			depth:      10,
+			updateErr:  errors.New("whoopsie"),
+			expMin:     95,
+			expStale:   []string{"orphan2"},
+			expUpdated: []string{"child"},
+			expErr:     errors.New("failed to revert txs in orphaned block orphan2: failed to set tx child broadcast: whoopsie"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var minHeight *uint32
+			var stale, updated, created []string
+			var events []payd.WebhookProofEvent
+			svc := service.NewReorg(log.Noop{}, &config.Wallet{ReorgDepth: test.depth}, &mocks.ChainTipReaderMock{
+				ChainTipFunc: func(context.Context) (*payd.ChainTip, error) {
+					if test.tipErr != nil {
+						return nil, test.tipErr
+					}
+					return &payd.ChainTip{Hash: "tip", Height: 105}, nil
+				},
+			}, &mocks.BlockHeaderChainMock{
+				BlockHeaderFunc: func(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+					if test.headerErr != nil {
+						return nil, test.headerErr
+					}
+					switch blockHash {
+					case "orphan2":
+						return nil, bc.ErrNotOnLongestChain
+					case "orphan1":
+						return nil, bc.ErrHeaderNotFound
+					}
+					return &bc.BlockHeader{}, nil
+				},
+			}, &mocks.ProofsReaderMock{
+				ProofBlocksFunc: func(ctx context.Context, args payd.ProofBlocksArgs) ([]payd.ProofBlock, error) {
+					minHeight = &args.MinBlockHeight
+					if test.blocksErr != nil {
+						return nil, test.blocksErr
+					}
+					return blocks, nil
+				},
+			}, &mocks.ProofsWriterMock{
+				ProofsStaleFunc: func(ctx context.Context, args payd.ProofsStaleArgs) ([]string, error) {
+					assert.Equal(t, now, args.StaleAt)
+					stale = append(stale, args.BlockHash)
+					if test.staleErr != nil {
+						return nil, test.staleErr
+					}
+					return proofTxs[args.BlockHash], nil
+				},
+			}, &mocks.TransactionWriterMock{
+				TransactionUpdateStateFunc: func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+					assert.Equal(t, payd.TransactionStateUpdate{State: payd.StateTxBroadcast}, req)
+					updated = append(updated, args.TxID)
+					return test.updateErr
+				},
+			}, &mocks.BroadcastReaderMock{
+				BroadcastStatusFunc: func(ctx context.Context, txID string) (*payd.BroadcastStatus, error) {
+					if test.statusErr != nil {
+						return nil, test.statusErr
+					}
+					if txID != "parent" {
+						return &payd.BroadcastStatus{TxID: txID}, nil
+					}
+					return &payd.BroadcastStatus{TxID: txID, BlockHash: "newblock", BlockHeight: 101, Proof: newProof}, nil
+				},
+			}, &mocks.ProofsServiceMock{
+				CreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+					var proof dpp.ProofWrapper
+					assert.NoError(t, json.Unmarshal([]byte(req.Payload), &proof))
+					assert.Equal(t, dpp.ProofWrapper{
+						CallbackPayload: newProof,
+						BlockHash:       "newblock",
+						BlockHeight:     101,
+						CallbackTxID:    args.TxID,
+						CallbackReason:  "merkleProof",
+					}, proof)
+					created = append(created, args.TxID)
+					return test.createErr
+				},
+			}, &mocks.WebhookPublisherMock{
+				PublishFunc: func(ctx context.Context, event payd.WebhookEvent, data interface{}) error {
+					assert.Equal(t, payd.WebhookEventProofOrphaned, event)
+					events = append(events, data.(payd.WebhookProofEvent))
+					return nil
+				},
+			}, &mocks.TransacterMock{
+				WithTxFunc: func(ctx context.Context) context.Context {
+					return ctx
+				},
+				RollbackFunc: func(context.Context) error {
+					return nil
+				},
+				CommitFunc: func(context.Context) error {
+					return nil
+				},
+			}, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+			n, err := svc.ProofsReorg(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expN, n)
+			if test.tipErr == nil {
+				assert.Equal(t, &test.expMin, minHeight)
+			}
+			assert.Equal(t, test.expStale, stale)
+			assert.Equal(t, test.expUpdated, updated)
+			assert.Equal(t, test.expEvents, events)
+			assert.Equal(t, test.expCreated, created)
+			if test.expErr != nil {
+				return
+			}
+
+			// nothing is checked again until the chain tip changes.
+			minHeight = nil
+			n, err = svc.ProofsReorg(context.Background())
+			assert.NoError(t, err)
+			assert.Zero(t, n)
+			assert.Nil(t, minHeight)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type transactionHistory struct {
+	store payd.TransactionReader
+}
+
+// NewTransactionHistory will setup and return a new transaction history service, used
+// to list the txs sent and received by the wallet.
+func NewTransactionHistory(store payd.TransactionReader) *transactionHistory {
+	return &transactionHistory{store: store}
+}
+
+// Transactions will return a page of the txs matching args with the net amount and fee
+// paid by the wallet set.
+func (t *transactionHistory) Transactions(ctx context.Context, args payd.TransactionSearchArgs) (*payd.TransactionPage, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if args.Limit == 0 {
+		args.Limit = payd.TransactionSearchDefaultLimit
+	}
+	limit := args.Limit
+	// fetch an extra tx to find out if there is another page.
+	args.Limit++
+	tt, err := t.store.Transactions(ctx, args)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get transactions")
+	}
+	resp := &payd.TransactionPage{Transactions: tt}
+	if len(tt) > limit {
+		resp.Transactions = tt[:limit]
+		last := resp.Transactions[limit-1]
+		resp.NextCursor = payd.TransactionCursor{CreatedAt: last.CreatedAt, TxID: last.TxID}.String()
+	}
+	if resp.Transactions == nil {
+		resp.Transactions = []payd.TransactionHistory{}
+	}
+	for i := range resp.Transactions {
+		if err := summarise(&resp.Transactions[i]); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// summarise sets the direction, net amount and fee of a tx from the wallet txos it
+// received and spent.
+func summarise(h *payd.TransactionHistory) error {
+	h.Satoshis = int64(h.Received) - int64(h.Spent)
+	h.Direction = payd.TxDirectionReceived
+	if h.Spent == 0 {
+		return nil
+	}
+	h.Direction = payd.TxDirectionSent
+	// the wallet funds every input of the txs it sends, so the fee is what
+	// was spent less the value of every output.
+	tx, err := bt.NewTxFromString(h.TxHex)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse tx %s", h.TxID)
+	}
+	if out := tx.TotalOutputSatoshis(); h.Spent > out {
+		h.Fee = h.Spent - out
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestTransactionHistoryService_Transactions(t *testing.T) {
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	received := func(n int) []payd.TransactionHistory {
+		tt := make([]payd.TransactionHistory, 0, n)
+		for i := 0; i < n; i++ {
+			tt = append(tt, payd.TransactionHistory{
+				TxID:      fmt.Sprintf("tx%d", i),
+				Received:  1000,
+				State:     payd.StateTxBroadcast,
+				CreatedAt: created.Add(-time.Duration(i) * time.Minute),
+			})
+		}
+		return tt
+	}
+	summarised := func(tt []payd.TransactionHistory) []payd.TransactionHistory {
+		for i := range tt {
+			tt[i].Direction = payd.TxDirectionReceived
+			tt[i].Satoshis = int64(tt[i].Received)
+		}
+		return tt
+	}
+	script, err := bscript.NewP2PKHFromPubKeyHashStr("8fe80c75c9560e8b56ed64ea3c26e18d2c52211b")
+	require.NoError(t, err)
+	sent := bt.NewTx()
+	require.NoError(t, sent.From("b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576", 0, script.String(), 1500))
+	sent.AddOutput(&bt.Output{Satoshis: 600, LockingScript: script})
+	sent.AddOutput(&bt.Output{Satoshis: 800, LockingScript: script})
+
+	tests := map[string]struct {
+		transactionsFunc func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error)
+		args             payd.TransactionSearchArgs
+		expArgs          payd.TransactionSearchArgs
+		expPage          *payd.TransactionPage
+		expErr           error
+	}{
+		"successful transactions get uses the default limit": {
+			transactionsFunc: func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+				return nil, nil
+			},
+			expArgs: payd.TransactionSearchArgs{Limit: payd.TransactionSearchDefaultLimit + 1},
+			expPage: &payd.TransactionPage{Transactions: []payd.TransactionHistory{}},
+		},
+		"last page has no next cursor": {
+			transactionsFunc: func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+				return received(2), nil
+			},
+			args:    payd.TransactionSearchArgs{Limit: 2, State: payd.StateTxBroadcast},
+			expArgs: payd.TransactionSearchArgs{Limit: 3, State: payd.StateTxBroadcast},
+			expPage: &payd.TransactionPage{Transactions: summarised(received(2))},
+		},
+		"extra transaction is trimmed and returned as the next cursor": {
+			transactionsFunc: func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+				return received(3), nil
+			},
+			args:    payd.TransactionSearchArgs{Limit: 2},
+			expArgs: payd.TransactionSearchArgs{Limit: 3},
+			expPage: &payd.TransactionPage{
+				Transactions: summarised(received(2)),
+				NextCursor:   payd.TransactionCursor{CreatedAt: created.Add(-time.Minute), TxID: "tx1"}.String(),
+			},
+		},
+		"sent transaction has net amount and fee set": {
+			transactionsFunc: func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+				return []payd.TransactionHistory{{
+					TxID:      sent.TxID(),
+					TxHex:     sent.String(),
+					Received:  800,
+					Spent:     1500,
+					CreatedAt: created,
+				}}, nil
+			},
+			expArgs: payd.TransactionSearchArgs{Limit: payd.TransactionSearchDefaultLimit + 1},
+			expPage: &payd.TransactionPage{Transactions: []payd.TransactionHistory{{
+				TxID:      sent.TxID(),
+				TxHex:     sent.String(),
+				Direction: payd.TxDirectionSent,
+				Satoshis:  -700,
+				Received:  800,
+				Spent:     1500,
+				Fee:       100,
+				CreatedAt: created,
+			}}},
+		},
+		"invalid sent transaction is reported": {
+			transactionsFunc: func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+				return []payd.TransactionHistory{{TxID: "abc", TxHex: "nope", Spent: 1000}}, nil
+			},
+			expArgs: payd.TransactionSearchArgs{Limit: payd.TransactionSearchDefaultLimit + 1},
+			expErr:  errors.New("failed to parse tx abc: encoding/hex: invalid byte: U+006E 'n'"),
+		},
+		"invalid args are rejected": {
+			args: payd.TransactionSearchArgs{
+				State: "deleted",
+				Sort:  "sideways",
+				Limit: 1000,
+			},
+			expErr: errors.New("[limit: value 1000 must be between 0 and 500], [sort: value not found in allowed values], [state: value not found in allowed values]"),
+		},
+		"store error is reported": {
+			transactionsFunc: func(context.Context, payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+				return nil, errors.New("whoopsie")
+			},
+			expArgs: payd.TransactionSearchArgs{Limit: payd.TransactionSearchDefaultLimit + 1},
+			expErr:  errors.New("failed to get transactions: whoopsie"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewTransactionHistory(&mocks.TransactionReaderMock{
+				TransactionsFunc: func(ctx context.Context, args payd.TransactionSearchArgs) ([]payd.TransactionHistory, error) {
+					assert.Equal(t, test.expArgs, args)
+					return test.transactionsFunc(ctx, args)
+				},
+			})
+			page, err := svc.Transactions(context.TODO(), test.args)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expPage, page)
+		})
+	}
+}
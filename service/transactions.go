@@ -58,15 +58,19 @@ func (t *transactions) Submit(ctx context.Context, args payd.TransactionSubmitAr
 		destLookup[out.LockingScript.String()] = uint64(i)
 	}
 
+	var total uint64
 	txos := make([]*payd.TxoCreate, len(destinations))
 	for i := 0; i < len(destinations); i++ {
 		outpoint := destLookup[destinations[i].LockingScript.String()]
+		satoshis := tx.Outputs[outpoint].Satoshis
 		txos[i] = &payd.TxoCreate{
 			Outpoint:      fmt.Sprintf("%s%d", txID, outpoint),
 			DestinationID: destinations[i].ID,
 			TxID:          txID,
 			Vout:          outpoint,
+			Satoshis:      satoshis,
 		}
+		total += satoshis
 	}
 	if err := t.tWtr.TransactionCreate(ctx, payd.TransactionCreate{
 		InvoiceID: inv.ID,
@@ -84,6 +88,8 @@ func (t *transactions) Submit(ctx context.Context, args payd.TransactionSubmitAr
 	if _, err := t.invRdr.InvoiceUpdate(ctx, payd.InvoiceUpdateArgs{InvoiceID: args.InvoiceID}, payd.InvoiceUpdatePaid{
 		PaymentReceivedAt: time.Now().UTC(),
 		RefundTo:          "",
+		TxID:              txID,
+		Satoshis:          total,
 	}); err != nil {
 		return err
 	}
@@ -3,30 +3,57 @@ package service
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 
-	"github.com/libsv/payd"
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/chaincfg"
 	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
 )
 
 type users struct {
-	str payd.UserStore
-	pks payd.PrivateKeyService
+	str       payd.UserStore
+	pks       payd.PrivateKeyService
+	walletCfg *config.Wallet
 }
 
 // NewUsersService returns a new owner service.
-func NewUsersService(str payd.UserStore, pks payd.PrivateKeyService) payd.UserService {
+func NewUsersService(str payd.UserStore, pks payd.PrivateKeyService, walletCfg *config.Wallet) payd.UserService {
 	return &users{
-		str: str,
-		pks: pks,
+		str:       str,
+		pks:       pks,
+		walletCfg: walletCfg,
 	}
 }
 
+// watchOnlyKeys creates the user's master key from an xpub rather than a new private key.
+type watchOnlyKeys struct {
+	payd.PrivateKeyService
+	xpub *bip32.ExtendedKey
+}
+
+// Create imports the xpub as the watch-only key.
+func (w watchOnlyKeys) Create(ctx context.Context, keyName string, userID uint64) error {
+	return w.PrivateKeyImport(ctx, payd.KeyArgs{Name: keyName, UserID: userID}, w.xpub)
+}
+
 // CreateUser allows you to add user data to the payd instance, and it will return the same data plus a user_id to confirm acceptance.
 func (u *users) CreateUser(ctx context.Context, user payd.CreateUserArgs) (*payd.User, error) {
 	if user.Name == "" || user.Email == "" {
 		return nil, errors.New("Please specify a name and email address for the user")
 	}
-	resp, err := u.str.CreateUser(ctx, user, u.pks)
+	pks := u.pks
+	if user.Xpub != "" {
+		xpub, err := u.xpub(user.Xpub)
+		if err != nil {
+			return nil, err
+		}
+		pks = watchOnlyKeys{PrivateKeyService: u.pks, xpub: xpub}
+	}
+	resp, err := u.str.CreateUser(ctx, user, pks)
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +75,14 @@ func (u *users) ReadUser(ctx context.Context, userID uint64) (*payd.User, error)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to read user")
 	}
-	pki, err := user.MasterKey.DerivePublicKeyFromPath("0/0/0")
+	// the public key is used so users can be read while the wallet is locked.
+	xpub, err := u.pks.PublicKey(ctx, "masterkey", userID)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse key from database xpriv")
+		return nil, errors.Wrap(err, "failed to get user master key")
+	}
+	pki, err := xpub.DerivePublicKeyFromPath("0/0/0")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive user public key")
 	}
 	user.ExtendedData["pki"] = hex.EncodeToString(pki)
 	return user, nil
@@ -65,3 +97,23 @@ func (u *users) UpdateUser(ctx context.Context, userID uint64, d payd.User) (*pa
 func (u *users) DeleteUser(ctx context.Context, userID uint64) error {
 	return u.str.DeleteUser(ctx, userID)
 }
+
+// xpub parses the extended public key of a watch-only user, private keys are rejected
+// so they aren't stored by mistake.
+func (u *users) xpub(s string) (*bip32.ExtendedKey, error) {
+	xpub, err := bip32.NewKeyFromString(s)
+	if err != nil {
+		return nil, validator.NewSingleError("xpub", []string{"xpub is not a valid extended public key"})
+	}
+	if xpub.IsPrivate() {
+		return nil, validator.NewSingleError("xpub", []string{"xpub must be an extended public key, not a private key"})
+	}
+	chain := &chaincfg.TestNet
+	if u.walletCfg.Network == config.NetworkMainet {
+		chain = &chaincfg.MainNet
+	}
+	if !xpub.IsForNet(chain) {
+		return nil, validator.NewSingleError("xpub", []string{fmt.Sprintf("xpub is not for %s", u.walletCfg.Network)})
+	}
+	return xpub, nil
+}
@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestUserService_CreateUser(t *testing.T) {
+	const (
+		xprv = "tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP"
+		xpub = "tpubD6NzVbkrYhZ4WPxQEWLbnueqwj1kdLofAKjqutMjxprwNYThy49k6YJs5d4ZZ7Z7JMXaeCykmAqtUaEf9EQtMPchEQqYxcrtiJzaUiLgPSE"
+	)
+	tests := map[string]struct {
+		req       payd.CreateUserArgs
+		expCreate bool
+		expImport string
+		expErr    error
+	}{
+		"user is created with a new private key": {
+			req:       payd.CreateUserArgs{Name: "merchant", Email: "merchant@example.com"},
+			expCreate: true,
+		},
+		"user with an xpub is watch-only": {
+			req:       payd.CreateUserArgs{Name: "merchant", Email: "merchant@example.com", Xpub: xpub},
+			expImport: xpub,
+		},
+		"xprv is rejected": {
+			req:    payd.CreateUserArgs{Name: "merchant", Email: "merchant@example.com", Xpub: xprv},
+			expErr: errors.New("[xpub: xpub must be an extended public key, not a private key]"),
+		},
+		"invalid xpub is rejected": {
+			req:    payd.CreateUserArgs{Name: "merchant", Email: "merchant@example.com", Xpub: "xpub"},
+			expErr: errors.New("[xpub: xpub is not a valid extended public key]"),
+		},
+		"mainnet xpub is rejected": {
+			req: payd.CreateUserArgs{Name: "merchant", Email: "merchant@example.com",
+				Xpub: "xpub661MyMwAqRbcGEWNpqdEMsTqzE8wG3q5Qp8i3nzZSKZEyVpM32P5my1BH5gaUhKHxYGPTLUc7R4PN63NiXdSwYQ1aZr9QRJkyQzGXtZS8SU"},
+			expErr: errors.New("[xpub: xpub is not for testnet]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var created bool
+			var imported string
+			svc := service.NewUsersService(&mocks.UserStoreMock{
+				CreateUserFunc: func(ctx context.Context, req payd.CreateUserArgs, pks payd.PrivateKeyService) (*payd.CreateUserResponse, error) {
+					if err := pks.Create(ctx, "masterkey", 2); err != nil {
+						return nil, err
+					}
+					return &payd.CreateUserResponse{ID: 2}, nil
+				},
+			}, &mocks.PrivateKeyServiceMock{
+				CreateFunc: func(ctx context.Context, keyName string, userID uint64) error {
+					created = true
+					return nil
+				},
+				PrivateKeyImportFunc: func(ctx context.Context, args payd.KeyArgs, key *bip32.ExtendedKey) error {
+					assert.Equal(t, payd.KeyArgs{Name: "masterkey", UserID: 2}, args)
+					imported = key.String()
+					return nil
+				},
+			}, &config.Wallet{Network: config.NetworkTestnet})
+
+			user, err := svc.CreateUser(context.Background(), test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(2), user.ID)
+			}
+			assert.Equal(t, test.expCreate, created)
+			assert.Equal(t, test.expImport, imported)
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/errcodes"
+	"github.com/libsv/payd/log"
+)
+
+// txOverheadSize is the size, in bytes, of a tx without its inputs and outputs.
+const txOverheadSize = 10
+
+type utxoMaintenance struct {
+	l           log.Logger
+	cfg         *config.Wallet
+	pkSvc       payd.PrivateKeyService
+	destWtr     payd.DestinationsWriter
+	txWtr       payd.TransactionWriter
+	txoWtr      payd.TxoWriter
+	seedSvc     payd.SeedService
+	feeFetcher  payd.FeeQuoteFetcher
+	broadcaster payd.BroadcastWriter
+	transacter  payd.Transacter
+}
+
+// NewUTXOMaintenance will setup and return a utxo maintenance service, used to consolidate
+// small utxos and fan out large ones.
+func NewUTXOMaintenance(l log.Logger, cfg *config.Wallet, pkSvc payd.PrivateKeyService, destWtr payd.DestinationsWriter, txWtr payd.TransactionWriter, txoWtr payd.TxoWriter, seedSvc payd.SeedService, feeFetcher payd.FeeQuoteFetcher, broadcaster payd.BroadcastWriter, transacter payd.Transacter) *utxoMaintenance {
+	return &utxoMaintenance{
+		l:           l,
+		cfg:         cfg,
+		pkSvc:       pkSvc,
+		destWtr:     destWtr,
+		txWtr:       txWtr,
+		txoWtr:      txoWtr,
+		seedSvc:     seedSvc,
+		feeFetcher:  feeFetcher,
+		broadcaster: broadcaster,
+		transacter:  transacter,
+	}
+}
+
+// UTXOsConsolidate will sweep the utxos worth SatoshisMax or less into a single utxo, smallest
+// first. Nothing is done if there are fewer than MinUTXOs.
+func (u *utxoMaintenance) UTXOsConsolidate(ctx context.Context, req payd.UTXOsConsolidate) (*payd.UTXOMaintenanceTx, error) {
+	if req.SatoshisMax == 0 {
+		req.SatoshisMax = u.cfg.ConsolidateSatoshis
+	}
+	if req.MinUTXOs == 0 {
+		req.MinUTXOs = u.cfg.ConsolidateMinUTXOs
+	}
+	if req.MaxUTXOs == 0 {
+		req.MaxUTXOs = u.cfg.ConsolidateMaxUTXOs
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return u.sweep(ctx, "consolidate", consolidateSelector(req), func(int) int {
+		return 1
+	})
+}
+
+// UTXOsFanOut will split the largest utxo worth SatoshisMin or more into Outputs utxos of
+// equal value. Nothing is done if there are no utxos this large.
+func (u *utxoMaintenance) UTXOsFanOut(ctx context.Context, req payd.UTXOsFanOut) (*payd.UTXOMaintenanceTx, error) {
+	if req.SatoshisMin == 0 {
+		req.SatoshisMin = u.cfg.FanOutSatoshis
+	}
+	if req.Outputs == 0 {
+		req.Outputs = u.cfg.FanOutOutputs
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return u.sweep(ctx, "fanout", fanOutSelector(req), func(int) int {
+		return req.Outputs
+	})
+}
+
+// sweep reserves the utxos chosen by the selector and spends them to outputs of equal value,
+// paying newly derived wallet keys. The tx is signed using the derivation paths of the spent
+// utxos, in the same way as payments, then broadcast.
+func (u *utxoMaintenance) sweep(ctx context.Context, op string, selector payd.CoinSelector, outputs func(inputs int) int) (*payd.UTXOMaintenanceTx, error) {
+	keyname := "masterkey"
+	userID := uint64(1)
+	fees, err := u.feeFetcher.FeeQuote(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get fees for %s", op)
+	}
+	// the standard rate is used as these txs aren't urgent.
+	fee, err := fees.Fee(bt.FeeTypeStandard)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get standard fee for %s", op)
+	}
+	feeFor := func(bytes int) uint64 {
+		if fee.MiningFee.Bytes == 0 {
+			return 0
+		}
+		return uint64(bytes * fee.MiningFee.Satoshis / fee.MiningFee.Bytes)
+	}
+	privKey, err := u.pkSvc.PrivateKey(ctx, keyname, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve private key")
+	}
+	ctx = u.transacter.WithTx(ctx)
+	defer func() {
+		_ = u.transacter.Rollback(ctx)
+	}()
+	reservedFor := op + "/" + uuid.NewString()
+	var reservedUntil time.Time
+	if u.cfg.ReservationTTL > 0 {
+		reservedUntil = time.Now().UTC().Add(u.cfg.ReservationTTL)
+	}
+	utxos, err := u.txoWtr.UTXOReserve(ctx, payd.UTXOReserve{
+		ReservedFor:   reservedFor,
+		ReservedUntil: reservedUntil,
+		InputFee:      feeFor(p2pkhInputSize),
+		ChangeFee:     feeFor(p2pkhOutputSize),
+		Selector:      selector,
+		KeyName:       keyname,
+		UserID:        userID,
+		Confirmed:     u.cfg.MinConfirmations > 0,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to reserve utxos for %s", op)
+	}
+	if len(utxos) == 0 {
+		return nil, nil
+	}
+
+	tx := bt.NewTx()
+	signer := &derivationSigner{
+		pathMap:       make(map[*bscript.Script]string),
+		masterPrivKey: privKey,
+	}
+	total := uint64(0)
+	for _, utxo := range utxos {
+		txid, err := hex.DecodeString(utxo.TxID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode txid %s for utxo", utxo.TxID)
+		}
+		lockingScript, err := bscript.NewFromHexString(utxo.LockingScript)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse locking script %s for utxo", utxo.LockingScript)
+		}
+		if err := tx.FromUTXOs(&bt.UTXO{
+			TxID:          txid,
+			Vout:          utxo.Vout,
+			Satoshis:      utxo.Satoshis,
+			LockingScript: lockingScript,
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to add utxo %s to tx", utxo.Outpoint)
+		}
+		signer.pathMap[lockingScript] = utxo.DerivationPath
+		total += utxo.Satoshis
+	}
+	n := outputs(len(utxos))
+	txFee := feeFor(txOverheadSize + len(utxos)*p2pkhInputSize + n*p2pkhOutputSize)
+	if total < txFee || (total-txFee)/uint64(n) < payd.DustLimit {
+		return nil, lathos.NewErrUnprocessable(errcodes.ErrUTXOsDust,
+			fmt.Sprintf("%d utxos worth %d satoshis can't pay a fee of %d and %d outputs above the dust limit", len(utxos), total, txFee, n))
+	}
+	satoshis := (total - txFee) / uint64(n)
+	dd := make([]payd.DestinationCreate, 0, n)
+	for i := 0; i < n; i++ {
+		out, err := derivedOutput(u.seedSvc, privKey)
+		if err != nil {
+			return nil, err
+		}
+		// the last output takes the remainder.
+		if i == n-1 {
+			satoshis = total - txFee - satoshis*uint64(n-1)
+		}
+		if err := tx.AddP2PKHOutputFromScript(out.LockingScript, satoshis); err != nil {
+			return nil, errors.Wrapf(err, "failed to add output to %s tx", op)
+		}
+		dd = append(dd, payd.DestinationCreate{
+			Script:         out.LockingScript.String(),
+			DerivationPath: out.DerivationPath,
+			UserID:         userID,
+			Satoshis:       satoshis,
+			KeyName:        keyname,
+		})
+	}
+	if err := tx.UnlockAll(ctx, signer); err != nil {
+		return nil, errors.Wrapf(err, "failed to sign %s tx", op)
+	}
+	oo, err := u.destWtr.DestinationsCreate(ctx, payd.DestinationsCreateArgs{}, dd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create destinations for %s outputs", op)
+	}
+	txCreate := payd.TransactionCreate{
+		TxID:  tx.TxID(),
+		TxHex: tx.String(),
+	}
+	vouts := make(map[string]int, n)
+	for i, o := range tx.Outputs {
+		vouts[o.LockingScript.String()] = i
+	}
+	for _, o := range oo {
+		vout, ok := vouts[o.LockingScript.String()]
+		if !ok {
+			continue
+		}
+		txCreate.Outputs = append(txCreate.Outputs, &payd.TxoCreate{
+			TxID:          txCreate.TxID,
+			Outpoint:      fmt.Sprintf("%s%d", txCreate.TxID, vout),
+			Vout:          uint64(vout),
+			DestinationID: o.ID,
+			Satoshis:      tx.Outputs[vout].Satoshis,
+		})
+	}
+	sort.Slice(txCreate.Outputs, func(i, j int) bool {
+		return txCreate.Outputs[i].Vout < txCreate.Outputs[j].Vout
+	})
+	if err := u.txWtr.TransactionCreate(ctx, txCreate); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s tx", op)
+	}
+	if err := u.txoWtr.UTXOSpend(ctx, payd.UTXOSpend{
+		SpendingTxID: txCreate.TxID,
+		Reservation:  reservedFor,
+	}); err != nil {
+		return nil, errors.Wrap(err, "failed to mark utxos as spent")
+	}
+	// nothing is committed if the broadcast fails, releasing the utxos.
+	if err := u.broadcaster.Broadcast(ctx, payd.BroadcastArgs{}, tx); err != nil {
+		return nil, errors.Wrapf(err, "failed to broadcast %s tx", op)
+	}
+	// Just logging errors from here as the tx is broadcast, the spent utxos must be committed.
+	if err := u.txWtr.TransactionUpdateState(ctx, payd.TransactionArgs{TxID: txCreate.TxID}, payd.TransactionStateUpdate{State: payd.StateTxBroadcast}); err != nil {
+		u.l.Error(err, fmt.Sprintf("failed to update %s tx to broadcast state", op))
+	}
+	if err := u.transacter.Commit(ctx); err != nil {
+		return nil, errors.Wrapf(err, "failed to commit %s tx %s", op, txCreate.TxID)
+	}
+	return &payd.UTXOMaintenanceTx{
+		TxID:     txCreate.TxID,
+		Inputs:   len(utxos),
+		Outputs:  n,
+		Satoshis: total - txFee,
+		Fee:      txFee,
+	}, nil
+}
+
+// consolidateSelector chooses up to MaxUTXOs of the smallest utxos worth SatoshisMax or less,
+// nothing is chosen if there are fewer than MinUTXOs.
+func consolidateSelector(req payd.UTXOsConsolidate) payd.CoinSelector {
+	return selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+		uu := make([]payd.UTXO, 0, len(utxos))
+		for _, u := range spendable(args, utxos) {
+			if u.Satoshis <= req.SatoshisMax {
+				uu = append(uu, u)
+			}
+		}
+		if len(uu) < req.MinUTXOs {
+			return nil
+		}
+		sort.SliceStable(uu, func(i, j int) bool {
+			return uu[i].Satoshis < uu[j].Satoshis
+		})
+		if req.MaxUTXOs > 0 && len(uu) > req.MaxUTXOs {
+			uu = uu[:req.MaxUTXOs]
+		}
+		return uu
+	})
+}
+
+// fanOutSelector chooses the largest utxo worth SatoshisMin or more.
+func fanOutSelector(req payd.UTXOsFanOut) payd.CoinSelector {
+	return selectorFunc(func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+		var largest *payd.UTXO
+		for i, u := range utxos {
+			if u.Satoshis >= req.SatoshisMin && (largest == nil || u.Satoshis > largest.Satoshis) {
+				largest = &utxos[i]
+			}
+		}
+		if largest == nil {
+			return nil
+		}
+		return []payd.UTXO{*largest}
+	})
+}
+
+// selectorFunc allows a func to be used as a payd.CoinSelector.
+type selectorFunc func(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO
+
+// SelectCoins will call the func.
+func (s selectorFunc) SelectCoins(args payd.CoinSelectArgs, utxos []payd.UTXO) []payd.UTXO {
+	return s(args, utxos)
+}
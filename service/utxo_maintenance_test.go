@@ -0,0 +1,213 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestUTXOMaintenanceService(t *testing.T) {
+	const script = "76a91474b0424726ca510399c1eb5c8374f974c68b2fa388ac"
+	utxo := func(i int, satoshis uint64) payd.UTXO {
+		txid := fmt.Sprintf("%064d", i)
+		return payd.UTXO{
+			Outpoint:       fmt.Sprintf("%s0", txid),
+			TxID:           txid,
+			Satoshis:       satoshis,
+			LockingScript:  script,
+			DerivationPath: fmt.Sprintf("0/%d", i),
+		}
+	}
+	cfg := &config.Wallet{
+		ConsolidateSatoshis: 1000,
+		ConsolidateMinUTXOs: 2,
+		ConsolidateMaxUTXOs: 2,
+		FanOutSatoshis:      10000,
+		FanOutOutputs:       4,
+	}
+	tests := map[string]struct {
+		utxos         []payd.UTXO
+		consolidate   *payd.UTXOsConsolidate
+		fanOut        *payd.UTXOsFanOut
+		broadcastErr  error
+		expReserved   []string
+		expSatoshis   []uint64
+		expTx         *payd.UTXOMaintenanceTx
+		expBroadcasts int
+		expCommits    int
+		expErr        error
+	}{
+		"consolidate sweeps the smallest utxos": {
+			utxos:       []payd.UTXO{utxo(1, 700), utxo(2, 5000), utxo(3, 500), utxo(4, 600)},
+			consolidate: &payd.UTXOsConsolidate{},
+			expReserved: []string{utxo(3, 0).Outpoint, utxo(4, 0).Outpoint},
+			expSatoshis: []uint64{930},
+			expTx: &payd.UTXOMaintenanceTx{
+				Inputs:   2,
+				Outputs:  1,
+				Satoshis: 930,
+				Fee:      170,
+			},
+			expBroadcasts: 1,
+			expCommits:    1,
+		},
+		"consolidate with too few small utxos does nothing": {
+			utxos:       []payd.UTXO{utxo(1, 700), utxo(2, 5000)},
+			consolidate: &payd.UTXOsConsolidate{},
+		},
+		"consolidate with invalid args is rejected": {
+			consolidate: &payd.UTXOsConsolidate{MinUTXOs: 5, MaxUTXOs: 3},
+			expErr:      errors.New("[maxUtxos: value 3 is smaller than minimum 5]"),
+		},
+		"fan out splits the largest utxo": {
+			utxos:       []payd.UTXO{utxo(1, 10000), utxo(2, 500), utxo(3, 20000)},
+			fanOut:      &payd.UTXOsFanOut{},
+			expReserved: []string{utxo(3, 0).Outpoint},
+			expSatoshis: []uint64{4963, 4963, 4963, 4964},
+			expTx: &payd.UTXOMaintenanceTx{
+				Inputs:   1,
+				Outputs:  4,
+				Satoshis: 19853,
+				Fee:      147,
+			},
+			expBroadcasts: 1,
+			expCommits:    1,
+		},
+		"fan out with no large utxos does nothing": {
+			utxos:  []payd.UTXO{utxo(1, 9999)},
+			fanOut: &payd.UTXOsFanOut{},
+		},
+		"fan out into dust is rejected": {
+			utxos:       []payd.UTXO{utxo(1, 600)},
+			fanOut:      &payd.UTXOsFanOut{SatoshisMin: 600},
+			expReserved: []string{utxo(1, 0).Outpoint},
+			expErr:      lathos.NewErrUnprocessable("U0001", "1 utxos worth 600 satoshis can't pay a fee of 147 and 4 outputs above the dust limit"),
+		},
+		"fan out with invalid args is rejected": {
+			fanOut: &payd.UTXOsFanOut{Outputs: 1},
+			expErr: errors.New("[outputs: value 1 must be between 2 and 1000]"),
+		},
+		"failed broadcast isn't committed": {
+			utxos:         []payd.UTXO{utxo(1, 20000)},
+			fanOut:        &payd.UTXOsFanOut{},
+			broadcastErr:  errors.New("rejected"),
+			expReserved:   []string{utxo(1, 0).Outpoint},
+			expSatoshis:   []uint64{4963, 4963, 4963, 4964},
+			expBroadcasts: 1,
+			expErr:        errors.New("failed to broadcast fanout tx: rejected"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var reserved []string
+			var satoshis []uint64
+			var broadcasts, commits int
+			var seed uint64
+			svc := service.NewUTXOMaintenance(
+				log.Noop{},
+				cfg,
+				&mocks.PrivateKeyServiceMock{
+					PrivateKeyFunc: func(ctx context.Context, name string, userID uint64) (*bip32.ExtendedKey, error) {
+						return bip32.NewKeyFromString("tprv8ZgxMBicQKsPcvvcLrg1PVzjNhVpU1ckb294dNKSYZ4YY4CwLfL9v3gzuW5WY96Cg7Wu58t7bukEezWFKzKapc4gJriYwgSYcHaN2VrTRKP")
+					},
+				},
+				&mocks.DestinationsReaderWriterMock{
+					DestinationsCreateFunc: func(ctx context.Context, args payd.DestinationsCreateArgs, req []payd.DestinationCreate) ([]payd.Output, error) {
+						oo := make([]payd.Output, 0, len(req))
+						for i, d := range req {
+							satoshis = append(satoshis, d.Satoshis)
+							s, _ := bscript.NewFromHexString(d.Script)
+							oo = append(oo, payd.Output{ID: uint64(i + 1), LockingScript: s, Satoshis: d.Satoshis})
+						}
+						return oo, nil
+					},
+				},
+				&mocks.TransactionWriterMock{
+					TransactionCreateFunc: func(ctx context.Context, req payd.TransactionCreate) error {
+						assert.Len(t, req.Outputs, len(satoshis))
+						return nil
+					},
+					TransactionUpdateStateFunc: func(ctx context.Context, args payd.TransactionArgs, req payd.TransactionStateUpdate) error {
+						assert.Equal(t, payd.StateTxBroadcast, req.State)
+						return nil
+					},
+				},
+				&mocks.TxoWriterMock{
+					UTXOReserveFunc: func(ctx context.Context, req payd.UTXOReserve) ([]payd.UTXO, error) {
+						uu := req.Selector.SelectCoins(payd.CoinSelectArgs{InputFee: req.InputFee, ChangeFee: req.ChangeFee}, test.utxos)
+						for _, u := range uu {
+							reserved = append(reserved, u.Outpoint)
+						}
+						return uu, nil
+					},
+					UTXOSpendFunc: func(ctx context.Context, req payd.UTXOSpend) error {
+						return nil
+					},
+				},
+				&mocks.SeedServiceMock{
+					Uint64Func: func() (uint64, error) {
+						seed++
+						return seed, nil
+					},
+				},
+				&mocks.FeeQuoteFetcherMock{
+					FeeQuoteFunc: func(ctx context.Context) (*bt.FeeQuote, error) {
+						return bt.NewFeeQuote(), nil
+					},
+				},
+				&mocks.BroadcastWriterMock{
+					BroadcastFunc: func(ctx context.Context, args payd.BroadcastArgs, tx *bt.Tx) error {
+						broadcasts++
+						return test.broadcastErr
+					},
+				},
+				&mocks.TransacterMock{
+					WithTxFunc: func(ctx context.Context) context.Context {
+						return ctx
+					},
+					CommitFunc: func(ctx context.Context) error {
+						commits++
+						return nil
+					},
+					RollbackFunc: func(ctx context.Context) error {
+						return nil
+					},
+				},
+			)
+			var tx *payd.UTXOMaintenanceTx
+			var err error
+			if test.consolidate != nil {
+				tx, err = svc.UTXOsConsolidate(context.Background(), *test.consolidate)
+			} else {
+				tx, err = svc.UTXOsFanOut(context.Background(), *test.fanOut)
+			}
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			if test.expTx != nil && tx != nil {
+				assert.NotEmpty(t, tx.TxID)
+				tx.TxID = ""
+			}
+			assert.Equal(t, test.expTx, tx)
+			assert.Equal(t, test.expReserved, reserved)
+			assert.Equal(t, test.expSatoshis, satoshis)
+			assert.Equal(t, test.expBroadcasts, broadcasts)
+			assert.Equal(t, test.expCommits, commits)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	lathos "github.com/theflyingcodr/lathos/errs"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/errcodes"
+	"github.com/libsv/payd/log"
+)
+
+type utxoReservations struct {
+	l       log.Logger
+	store   payd.TxoReaderWriter
+	timeSvc payd.TimestampService
+}
+
+// NewUTXOReservations will setup and return a new utxo reservation service, used to
+// release utxos reserved by payments that were never completed.
+func NewUTXOReservations(l log.Logger, store payd.TxoReaderWriter, timeSvc payd.TimestampService) *utxoReservations {
+	return &utxoReservations{
+		l:       l,
+		store:   store,
+		timeSvc: timeSvc,
+	}
+}
+
+// UTXOReservations returns the current reservations, if args.Expired is set only those
+// past their expiry are returned.
+func (u *utxoReservations) UTXOReservations(ctx context.Context, args payd.UTXOReservationsArgs) ([]payd.UTXOReservation, error) {
+	rr, err := u.store.UTXOReservations(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get utxo reservations")
+	}
+	now := u.timeSvc.NowUTC()
+	resp := make([]payd.UTXOReservation, 0, len(rr))
+	for _, r := range rr {
+		r.Expired = r.ReservedUntil.Valid && !r.ReservedUntil.Time.After(now)
+		if args.Expired && !r.Expired {
+			continue
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
+
+// UTXOReservationRelease will release the utxos of a reservation so they can be spent again. Each
+// payment attempt has its own reservation, so only the utxos of that attempt are released.
+func (u *utxoReservations) UTXOReservationRelease(ctx context.Context, args payd.UTXOReservationArgs) error {
+	if err := args.Validate(); err != nil {
+		return err
+	}
+	rr, err := u.store.UTXOReservations(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get utxo reservations")
+	}
+	for _, r := range rr {
+		if r.ReservedFor != args.ReservedFor {
+			continue
+		}
+		if err := u.store.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: r.ReservedFor}); err != nil {
+			return errors.WithMessagef(err, "failed to release utxos reserved for %s", r.ReservedFor)
+		}
+		u.l.Infof("released %d utxos reserved for %s", r.UTXOs, r.ReservedFor)
+		return nil
+	}
+	return lathos.NewErrNotFound(errcodes.ErrUTXOReservationNotFound, fmt.Sprintf("no utxos are reserved for %s", args.ReservedFor))
+}
+
+// UTXOReservationsExpire will release the utxos of every reservation past its expiry,
+// returning the number of reservations released.
+func (u *utxoReservations) UTXOReservationsExpire(ctx context.Context) (int, error) {
+	rr, err := u.UTXOReservations(ctx, payd.UTXOReservationsArgs{Expired: true})
+	if err != nil {
+		return 0, err
+	}
+	// a reservation failing to release is retried on the next run, so the rest are still released.
+	n := 0
+	for _, r := range rr {
+		if err := u.store.UTXOUnreserve(ctx, payd.UTXOUnreserve{ReservedFor: r.ReservedFor}); err != nil {
+			u.l.Errorf(err, "failed to release expired reservation %s", r.ReservedFor)
+			continue
+		}
+		u.l.Infof("released %d utxos from expired reservation %s", r.UTXOs, r.ReservedFor)
+		n++
+	}
+	return n, nil
+}
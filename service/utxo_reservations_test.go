@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestUTXOReservationService_UTXOReservations(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	reservations := []payd.UTXOReservation{{
+		ReservedFor:   "http://dpp/api/v1/payment/abc",
+		UTXOs:         2,
+		Satoshis:      3000,
+		ReservedUntil: null.TimeFrom(now.Add(-time.Minute)),
+	}, {
+		ReservedFor:   "http://dpp/api/v1/payment/def",
+		UTXOs:         1,
+		Satoshis:      500,
+		ReservedUntil: null.TimeFrom(now),
+	}, {
+		ReservedFor:   "http://dpp/api/v1/payment/ghi",
+		UTXOs:         1,
+		Satoshis:      800,
+		ReservedUntil: null.TimeFrom(now.Add(time.Minute)),
+	}, {
+		ReservedFor: "http://dpp/api/v1/payment/jkl",
+		UTXOs:       1,
+		Satoshis:    100,
+	}}
+	tests := map[string]struct {
+		args   payd.UTXOReservationsArgs
+		err    error
+		exp    []string
+		expErr error
+	}{
+		"all reservations are returned": {
+			exp: []string{
+				"http://dpp/api/v1/payment/abc",
+				"http://dpp/api/v1/payment/def",
+				"http://dpp/api/v1/payment/ghi",
+				"http://dpp/api/v1/payment/jkl",
+			},
+		},
+		"only expired reservations are returned when requested": {
+			args: payd.UTXOReservationsArgs{Expired: true},
+			exp: []string{
+				"http://dpp/api/v1/payment/abc",
+				"http://dpp/api/v1/payment/def",
+			},
+		},
+		"store error is returned": {
+			err:    errors.New("db down"),
+			expErr: errors.New("failed to get utxo reservations: db down"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewUTXOReservations(log.Noop{}, &mocks.TxoReaderWriterMock{
+				UTXOReservationsFunc: func(ctx context.Context) ([]payd.UTXOReservation, error) {
+					return reservations, test.err
+				},
+			}, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+			rr, err := svc.UTXOReservations(context.Background(), test.args)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			var ids []string
+			for _, r := range rr {
+				ids = append(ids, r.ReservedFor)
+				assert.Equal(t, r.ReservedFor <= "http://dpp/api/v1/payment/def", r.Expired, r.ReservedFor)
+			}
+			assert.Equal(t, test.exp, ids)
+		})
+	}
+}
+
+func TestUTXOReservationService_UTXOReservationRelease(t *testing.T) {
+	tests := map[string]struct {
+		args         payd.UTXOReservationArgs
+		unreserveErr error
+		expUnreserve bool
+		expErr       error
+	}{
+		"reservation is released": {
+			args:         payd.UTXOReservationArgs{ReservedFor: "http://dpp/api/v1/payment/abc"},
+			expUnreserve: true,
+		},
+		"missing reservation is not found": {
+			args:   payd.UTXOReservationArgs{ReservedFor: "http://dpp/api/v1/payment/xyz"},
+			expErr: errors.New("Not found: no utxos are reserved for http://dpp/api/v1/payment/xyz"),
+		},
+		"reservedFor is required": {
+			expErr: errors.New("[reservedFor: value cannot be empty]"),
+		},
+		"unreserve error is returned": {
+			args:         payd.UTXOReservationArgs{ReservedFor: "http://dpp/api/v1/payment/abc"},
+			unreserveErr: errors.New("db down"),
+			expUnreserve: true,
+			expErr:       errors.New("failed to release utxos reserved for http://dpp/api/v1/payment/abc: db down"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var unreserved bool
+			svc := service.NewUTXOReservations(log.Noop{}, &mocks.TxoReaderWriterMock{
+				UTXOReservationsFunc: func(ctx context.Context) ([]payd.UTXOReservation, error) {
+					return []payd.UTXOReservation{{ReservedFor: "http://dpp/api/v1/payment/abc", UTXOs: 1, Satoshis: 1000}}, nil
+				},
+				UTXOUnreserveFunc: func(ctx context.Context, req payd.UTXOUnreserve) error {
+					assert.Equal(t, test.args.ReservedFor, req.ReservedFor)
+					unreserved = true
+					return test.unreserveErr
+				},
+			}, &mocks.TimestampServiceMock{
+				NowUTCFunc: time.Now,
+			})
+			err := svc.UTXOReservationRelease(context.Background(), test.args)
+			assert.Equal(t, test.expUnreserve, unreserved)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUTXOReservationService_UTXOReservationsExpire(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	var released []string
+	svc := service.NewUTXOReservations(log.Noop{}, &mocks.TxoReaderWriterMock{
+		UTXOReservationsFunc: func(ctx context.Context) ([]payd.UTXOReservation, error) {
+			return []payd.UTXOReservation{
+				{ReservedFor: "abc", ReservedUntil: null.TimeFrom(now.Add(-time.Hour))},
+				{ReservedFor: "def", ReservedUntil: null.TimeFrom(now.Add(-time.Minute))},
+				{ReservedFor: "ghi", ReservedUntil: null.TimeFrom(now.Add(-time.Second))},
+				{ReservedFor: "jkl", ReservedUntil: null.TimeFrom(now.Add(time.Minute))},
+			}, nil
+		},
+		UTXOUnreserveFunc: func(ctx context.Context, req payd.UTXOUnreserve) error {
+			released = append(released, req.ReservedFor)
+			if req.ReservedFor == "def" {
+				return errors.New("db locked")
+			}
+			return nil
+		},
+	}, &mocks.TimestampServiceMock{
+		NowUTCFunc: func() time.Time {
+			return now
+		},
+	})
+	n, err := svc.UTXOReservationsExpire(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"abc", "def", "ghi"}, released)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type utxos struct {
+	store payd.TxoReaderWriter
+}
+
+// NewUTXOs will setup and return a new utxo service, used to list the txos
+// paid to the wallet and to freeze those that shouldn't be spent.
+func NewUTXOs(store payd.TxoReaderWriter) *utxos {
+	return &utxos{store: store}
+}
+
+// UTXOs will return a page of the txos matching args.
+func (u *utxos) UTXOs(ctx context.Context, args payd.UTXOSearchArgs) (*payd.UTXOPage, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if args.Limit == 0 {
+		args.Limit = payd.UTXOSearchDefaultLimit
+	}
+	limit := args.Limit
+	// fetch an extra txo to find out if there is another page.
+	args.Limit++
+	uu, err := u.store.UTXOs(ctx, args)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get utxos")
+	}
+	resp := &payd.UTXOPage{UTXOs: uu}
+	if len(uu) > limit {
+		resp.UTXOs = uu[:limit]
+		last := resp.UTXOs[limit-1]
+		resp.NextCursor = payd.UTXOCursor{CreatedAt: last.CreatedAt, Outpoint: last.Outpoint}.String()
+	}
+	if resp.UTXOs == nil {
+		resp.UTXOs = []payd.UTXODetail{}
+	}
+	return resp, nil
+}
+
+// UTXOsFreeze will freeze the unspent txos so they aren't reserved to fund payments.
+func (u *utxos) UTXOsFreeze(ctx context.Context, req payd.UTXOsFreeze) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	return errors.WithMessage(u.store.UTXOFrozenUpdate(ctx, payd.UTXOFrozenUpdate{
+		Outpoints: req.Outpoints,
+		Frozen:    true,
+	}), "failed to freeze utxos")
+}
+
+// UTXOsUnfreeze will unfreeze the txos so they can be reserved to fund payments again.
+func (u *utxos) UTXOsUnfreeze(ctx context.Context, req payd.UTXOsFreeze) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	return errors.WithMessage(u.store.UTXOFrozenUpdate(ctx, payd.UTXOFrozenUpdate{
+		Outpoints: req.Outpoints,
+		Frozen:    false,
+	}), "failed to unfreeze utxos")
+}
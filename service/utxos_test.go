@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestUTXOService_UTXOs(t *testing.T) {
+	created := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	utxos := func(n int) []payd.UTXODetail {
+		uu := make([]payd.UTXODetail, 0, n)
+		for i := 0; i < n; i++ {
+			uu = append(uu, payd.UTXODetail{
+				Outpoint:  fmt.Sprintf("abc%d", i),
+				Satoshis:  1000,
+				CreatedAt: created.Add(-time.Duration(i) * time.Minute),
+			})
+		}
+		return uu
+	}
+	tests := map[string]struct {
+		utxosFunc func(context.Context, payd.UTXOSearchArgs) ([]payd.UTXODetail, error)
+		args      payd.UTXOSearchArgs
+		expArgs   payd.UTXOSearchArgs
+		expPage   *payd.UTXOPage
+		expErr    error
+	}{
+		"successful utxos get uses the default limit": {
+			utxosFunc: func(context.Context, payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+				return nil, nil
+			},
+			expArgs: payd.UTXOSearchArgs{Limit: payd.UTXOSearchDefaultLimit + 1},
+			expPage: &payd.UTXOPage{UTXOs: []payd.UTXODetail{}},
+		},
+		"last page has no next cursor": {
+			utxosFunc: func(context.Context, payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+				return utxos(2), nil
+			},
+			args:    payd.UTXOSearchArgs{Limit: 2, Spent: null.BoolFrom(false), DerivationPath: "0/1"},
+			expArgs: payd.UTXOSearchArgs{Limit: 3, Spent: null.BoolFrom(false), DerivationPath: "0/1"},
+			expPage: &payd.UTXOPage{UTXOs: utxos(2)},
+		},
+		"extra utxo is trimmed and returned as the next cursor": {
+			utxosFunc: func(context.Context, payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+				return utxos(3), nil
+			},
+			args:    payd.UTXOSearchArgs{Limit: 2},
+			expArgs: payd.UTXOSearchArgs{Limit: 3},
+			expPage: &payd.UTXOPage{
+				UTXOs:      utxos(2),
+				NextCursor: payd.UTXOCursor{CreatedAt: created.Add(-time.Minute), Outpoint: "abc1"}.String(),
+			},
+		},
+		"invalid args are rejected": {
+			args: payd.UTXOSearchArgs{
+				Sort:           "sideways",
+				Limit:          1000,
+				SatoshisMin:    2000,
+				SatoshisMax:    1000,
+				DerivationPath: "m/0/1",
+			},
+			expErr: errors.New("[derivationPath: value m/0/1 failed to meet requirements], [limit: value 1000 must be between 0 and 500], [satoshisMax: value 1000 is smaller than minimum 2000], [sort: value not found in allowed values]"),
+		},
+		"store error is reported": {
+			utxosFunc: func(context.Context, payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+				return nil, errors.New("whoopsie")
+			},
+			expArgs: payd.UTXOSearchArgs{Limit: payd.UTXOSearchDefaultLimit + 1},
+			expErr:  errors.New("failed to get utxos: whoopsie"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewUTXOs(&mocks.TxoReaderWriterMock{
+				UTXOsFunc: func(ctx context.Context, args payd.UTXOSearchArgs) ([]payd.UTXODetail, error) {
+					assert.Equal(t, test.expArgs, args)
+					return test.utxosFunc(ctx, args)
+				},
+			})
+			page, err := svc.UTXOs(context.TODO(), test.args)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expPage, page)
+		})
+	}
+}
+
+func TestUTXOService_UTXOsFreeze(t *testing.T) {
+	tests := map[string]struct {
+		frozenUpdateFunc func(context.Context, payd.UTXOFrozenUpdate) error
+		req              payd.UTXOsFreeze
+		unfreeze         bool
+		expUpdate        *payd.UTXOFrozenUpdate
+		expErr           error
+	}{
+		"successful freeze": {
+			frozenUpdateFunc: func(context.Context, payd.UTXOFrozenUpdate) error {
+				return nil
+			},
+			req:       payd.UTXOsFreeze{Outpoints: []string{"abc0", "abc1"}},
+			expUpdate: &payd.UTXOFrozenUpdate{Outpoints: []string{"abc0", "abc1"}, Frozen: true},
+		},
+		"successful unfreeze": {
+			frozenUpdateFunc: func(context.Context, payd.UTXOFrozenUpdate) error {
+				return nil
+			},
+			req:       payd.UTXOsFreeze{Outpoints: []string{"abc0"}},
+			unfreeze:  true,
+			expUpdate: &payd.UTXOFrozenUpdate{Outpoints: []string{"abc0"}},
+		},
+		"no outpoints are rejected": {
+			expErr: errors.New("[outpoints: at least one outpoint is required]"),
+		},
+		"empty outpoints are rejected": {
+			req:    payd.UTXOsFreeze{Outpoints: []string{"abc0", ""}},
+			expErr: errors.New("[outpoints: outpoints cannot be empty]"),
+		},
+		"store error on freeze is reported": {
+			frozenUpdateFunc: func(context.Context, payd.UTXOFrozenUpdate) error {
+				return errors.New("whoopsie")
+			},
+			req:       payd.UTXOsFreeze{Outpoints: []string{"abc0"}},
+			expUpdate: &payd.UTXOFrozenUpdate{Outpoints: []string{"abc0"}, Frozen: true},
+			expErr:    errors.New("failed to freeze utxos: whoopsie"),
+		},
+		"store error on unfreeze is reported": {
+			frozenUpdateFunc: func(context.Context, payd.UTXOFrozenUpdate) error {
+				return errors.New("whoopsie")
+			},
+			req:       payd.UTXOsFreeze{Outpoints: []string{"abc0"}},
+			unfreeze:  true,
+			expUpdate: &payd.UTXOFrozenUpdate{Outpoints: []string{"abc0"}},
+			expErr:    errors.New("failed to unfreeze utxos: whoopsie"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var update *payd.UTXOFrozenUpdate
+			svc := service.NewUTXOs(&mocks.TxoReaderWriterMock{
+				UTXOFrozenUpdateFunc: func(ctx context.Context, req payd.UTXOFrozenUpdate) error {
+					update = &req
+					return test.frozenUpdateFunc(ctx, req)
+				},
+			})
+			var err error
+			if test.unfreeze {
+				err = svc.UTXOsUnfreeze(context.TODO(), test.req)
+			} else {
+				err = svc.UTXOsFreeze(context.TODO(), test.req)
+			}
+			assert.Equal(t, test.expUpdate, update)
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+)
+
+const (
+	// webhookDeliveriesBatch is the most deliveries attempted each time due deliveries are sent.
+	webhookDeliveriesBatch = 100
+	// lastErrorLen is the longest error stored against a failed delivery.
+	lastErrorLen = 1024
+)
+
+type webhooks struct {
+	l       log.Logger
+	cfg     *config.Webhooks
+	store   payd.WebhookReaderWriter
+	sender  payd.WebhookSender
+	timeSvc payd.TimestampService
+}
+
+// NewWebhooks will setup and return a new webhook service, it is used to manage webhooks,
+// publish events to them and deliver those events.
+func NewWebhooks(l log.Logger, cfg *config.Webhooks, store payd.WebhookReaderWriter, sender payd.WebhookSender, timeSvc payd.TimestampService) *webhooks {
+	return &webhooks{
+		l:       l,
+		cfg:     cfg,
+		store:   store,
+		sender:  sender,
+		timeSvc: timeSvc,
+	}
+}
+
+// Webhook will return a single webhook, the secret isn't returned.
+func (w *webhooks) Webhook(ctx context.Context, args payd.WebhookArgs) (*payd.Webhook, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	wh, err := w.store.Webhook(ctx, args)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get webhook with id %s", args.WebhookID)
+	}
+	wh.Secret = ""
+	return wh, nil
+}
+
+// Webhooks will return all registered webhooks, their secrets aren't returned.
+func (w *webhooks) Webhooks(ctx context.Context) ([]payd.Webhook, error) {
+	ww, err := w.store.Webhooks(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get webhooks")
+	}
+	resp := make([]payd.Webhook, 0, len(ww))
+	for _, wh := range ww {
+		wh.Secret = ""
+		resp = append(resp, wh)
+	}
+	return resp, nil
+}
+
+// WebhookCreate will register a new webhook, if a secret isn't supplied one is generated.
+// This is the only time the secret is returned.
+func (w *webhooks) WebhookCreate(ctx context.Context, req payd.WebhookCreate) (*payd.Webhook, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Secret == "" {
+		bb := make([]byte, 32)
+		if _, err := rand.Read(bb); err != nil {
+			return nil, errors.Wrap(err, "failed to generate webhook secret")
+		}
+		req.Secret = hex.EncodeToString(bb)
+	}
+	req.WebhookID = uuid.NewString()
+	req.CreatedAt = w.timeSvc.NowUTC()
+	wh, err := w.store.WebhookCreate(ctx, req)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create webhook")
+	}
+	return wh, nil
+}
+
+// WebhookDelete will remove a webhook, deliveries still waiting to be sent to it are not sent.
+func (w *webhooks) WebhookDelete(ctx context.Context, args payd.WebhookArgs) error {
+	if err := args.Validate(); err != nil {
+		return err
+	}
+	return errors.WithMessagef(w.store.WebhookDelete(ctx, args), "failed to delete webhook with id %s", args.WebhookID)
+}
+
+// WebhookDeliveries will return the deliveries matching args, newest first.
+func (w *webhooks) WebhookDeliveries(ctx context.Context, args payd.WebhookDeliveriesArgs) ([]payd.WebhookDelivery, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if args.Limit == 0 {
+		args.Limit = payd.WebhookDeliveriesDefaultLimit
+	}
+	dd, err := w.store.WebhookDeliveries(ctx, args)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to get webhook deliveries")
+	}
+	if dd == nil {
+		dd = []payd.WebhookDelivery{}
+	}
+	return dd, nil
+}
+
+// WebhookDeliveryReplay will queue a delivery to be sent on the next run, it is given
+// a fresh set of attempts so dead lettered deliveries can be retried once the endpoint is fixed.
+func (w *webhooks) WebhookDeliveryReplay(ctx context.Context, args payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	d, err := w.store.WebhookDelivery(ctx, args)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to get webhook delivery with id %s", args.DeliveryID)
+	}
+	if _, err := w.store.Webhook(ctx, payd.WebhookArgs{WebhookID: d.WebhookID}); err != nil {
+		return nil, errors.WithMessagef(err, "failed to replay webhook delivery with id %s", args.DeliveryID)
+	}
+	now := w.timeSvc.NowUTC()
+	req := payd.WebhookDeliveryUpdate{
+		State:         payd.StateWebhookDeliveryPending,
+		NextAttemptAt: now,
+		LastError:     d.LastError,
+		UpdatedAt:     now,
+	}
+	if err := w.store.WebhookDeliveryUpdate(ctx, args, req); err != nil {
+		return nil, errors.WithMessagef(err, "failed to replay webhook delivery with id %s", args.DeliveryID)
+	}
+	d.State = req.State
+	d.Attempts = req.Attempts
+	d.NextAttemptAt = req.NextAttemptAt
+	d.DeliveredAt = req.DeliveredAt
+	d.UpdatedAt = req.UpdatedAt
+	return d, nil
+}
+
+// Publish will queue a delivery of the event, with data as its payload, to each webhook
+// subscribed to it. The deliveries are written in the context transaction if there is one.
+func (w *webhooks) Publish(ctx context.Context, event payd.WebhookEvent, data interface{}) error {
+	ww, err := w.store.Webhooks(ctx)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get webhooks to publish %s event", event)
+	}
+	var payload []byte
+	now := w.timeSvc.NowUTC()
+	dd := make([]payd.WebhookDeliveryCreate, 0, len(ww))
+	for _, wh := range ww {
+		if !wh.Subscribed(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(data); err != nil {
+				return errors.Wrapf(err, "failed to encode %s event", event)
+			}
+		}
+		dd = append(dd, payd.WebhookDeliveryCreate{
+			DeliveryID:    uuid.NewString(),
+			WebhookID:     wh.ID,
+			Event:         event,
+			Payload:       payload,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	return errors.WithMessagef(w.store.WebhookDeliveriesCreate(ctx, dd), "failed to queue %s event deliveries", event)
+}
+
+// WebhooksDeliver will attempt each delivery that is due. Failed deliveries are retried with
+// an exponential backoff until they run out of attempts, when they are dead lettered.
+func (w *webhooks) WebhooksDeliver(ctx context.Context) (int, error) {
+	dd, err := w.store.WebhookDeliveriesDue(ctx, payd.WebhookDeliveriesDueArgs{
+		DueBefore: w.timeSvc.NowUTC(),
+		Limit:     webhookDeliveriesBatch,
+	})
+	if err != nil {
+		return 0, errors.WithMessage(err, "failed to get due webhook deliveries")
+	}
+	ww := map[string]*payd.Webhook{}
+	for _, d := range dd {
+		wh, ok := ww[d.WebhookID]
+		if !ok {
+			if wh, err = w.store.Webhook(ctx, payd.WebhookArgs{WebhookID: d.WebhookID}); err != nil {
+				return 0, errors.WithMessagef(err, "failed to get webhook for delivery %s", d.ID)
+			}
+			ww[d.WebhookID] = wh
+		}
+		w.deliver(ctx, *wh, d)
+	}
+	return len(dd), nil
+}
+
+// deliver sends a single delivery and records the outcome, errors are logged so
+// one failure doesn't hold up the other deliveries.
+func (w *webhooks) deliver(ctx context.Context, wh payd.Webhook, d payd.WebhookDelivery) {
+	err := w.sender.WebhookSend(ctx, payd.WebhookSendArgs{URL: wh.URL, Secret: wh.Secret}, payd.WebhookMessage{
+		ID:        d.ID,
+		Event:     d.Event,
+		CreatedAt: d.CreatedAt,
+		Data:      d.Payload,
+	})
+	now := w.timeSvc.NowUTC()
+	req := payd.WebhookDeliveryUpdate{
+		State:         payd.StateWebhookDeliveryDelivered,
+		Attempts:      d.Attempts + 1,
+		NextAttemptAt: d.NextAttemptAt,
+		DeliveredAt:   null.TimeFrom(now),
+		UpdatedAt:     now,
+	}
+	if err != nil {
+		msg := err.Error()
+		if len(msg) > lastErrorLen {
+			msg = msg[:lastErrorLen]
+		}
+		req.LastError = null.StringFrom(msg)
+		req.DeliveredAt = null.Time{}
+		req.State = payd.StateWebhookDeliveryPending
+		req.NextAttemptAt = now.Add(retryBackoff(req.Attempts, w.cfg.Backoff, w.cfg.BackoffMax))
+		if req.Attempts >= w.cfg.MaxAttempts {
+			req.State = payd.StateWebhookDeliveryDead
+			w.l.Warnf("webhook delivery %s of %s event to %s is dead lettered after %d attempts: %s",
+				d.ID, d.Event, wh.URL, req.Attempts, msg)
+		} else {
+			w.l.Debugf("webhook delivery %s of %s event to %s failed, retrying at %s: %s",
+				d.ID, d.Event, wh.URL, req.NextAttemptAt, msg)
+		}
+	}
+	if err := w.store.WebhookDeliveryUpdate(ctx, payd.WebhookDeliveryArgs{DeliveryID: d.ID}, req); err != nil {
+		w.l.Errorf(err, "failed to record outcome of webhook delivery %s", d.ID)
+	}
+}
+
+// retryBackoff returns the wait before the next attempt, it starts at backoff
+// and doubles with each attempt up to max.
+func retryBackoff(attempts int, backoff, max time.Duration) time.Duration {
+	wait := backoff
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	return wait
+}
@@ -0,0 +1,336 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/libsv/payd"
+	"github.com/libsv/payd/config"
+	"github.com/libsv/payd/log"
+	"github.com/libsv/payd/mocks"
+	"github.com/libsv/payd/service"
+)
+
+func TestWebhookService_WebhookCreate(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		req       payd.WebhookCreate
+		expSecret bool
+		expErr    error
+	}{
+		"webhook with secret is created": {
+			req: payd.WebhookCreate{
+				URL:    "https://merchant.com/hooks",
+				Secret: "shh",
+				Events: []payd.WebhookEvent{payd.WebhookEventInvoicePaid},
+			},
+			expSecret: true,
+		},
+		"secret is generated when not supplied": {
+			req: payd.WebhookCreate{
+				URL:    "https://merchant.com/hooks",
+				Events: []payd.WebhookEvent{payd.WebhookEventInvoicePaid},
+			},
+			expSecret: true,
+		},
+		"invalid url and events are rejected": {
+			req: payd.WebhookCreate{
+				URL:    "ftp://merchant.com",
+				Events: []payd.WebhookEvent{"invoice.lost"},
+			},
+			expErr: errors.New("[events: value not found in allowed values], [url: url must be a valid http or https url]"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &mocks.WebhookReaderWriterMock{
+				WebhookCreateFunc: func(ctx context.Context, req payd.WebhookCreate) (*payd.Webhook, error) {
+					return &payd.Webhook{
+						ID:        req.WebhookID,
+						URL:       req.URL,
+						Secret:    req.Secret,
+						Events:    req.Events,
+						CreatedAt: req.CreatedAt,
+					}, nil
+				},
+			}
+			svc := service.NewWebhooks(log.Noop{}, &config.Webhooks{}, store, nil, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+
+			wh, err := svc.WebhookCreate(context.Background(), test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, store.WebhookCreateCalls())
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, wh.ID)
+			assert.Equal(t, now, wh.CreatedAt)
+			assert.Equal(t, test.expSecret, wh.Secret != "")
+			if test.req.Secret != "" {
+				assert.Equal(t, test.req.Secret, wh.Secret)
+			}
+		})
+	}
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		webhooksFunc  func(context.Context) ([]payd.Webhook, error)
+		createErr     error
+		expWebhookIDs []string
+		expErr        error
+	}{
+		"only subscribed webhooks are sent the event": {
+			webhooksFunc: func(context.Context) ([]payd.Webhook, error) {
+				return []payd.Webhook{
+					{ID: "wh1", Events: []payd.WebhookEvent{payd.WebhookEventInvoicePaid}},
+					{ID: "wh2", Events: []payd.WebhookEvent{payd.WebhookEventTxFailed}},
+					{ID: "wh3", Events: []payd.WebhookEvent{payd.WebhookEventTxFailed, payd.WebhookEventInvoicePaid}},
+				}, nil
+			},
+			expWebhookIDs: []string{"wh1", "wh3"},
+		},
+		"no webhooks queues nothing": {
+			webhooksFunc: func(context.Context) ([]payd.Webhook, error) {
+				return nil, nil
+			},
+			expWebhookIDs: []string{},
+		},
+		"webhooks store error is reported": {
+			webhooksFunc: func(context.Context) ([]payd.Webhook, error) {
+				return nil, errors.New("db locked")
+			},
+			expErr: errors.New("failed to get webhooks to publish invoice.paid event: db locked"),
+		},
+		"deliveries store error is reported": {
+			webhooksFunc: func(context.Context) ([]payd.Webhook, error) {
+				return []payd.Webhook{{ID: "wh1", Events: []payd.WebhookEvent{payd.WebhookEventInvoicePaid}}}, nil
+			},
+			createErr: errors.New("db locked"),
+			expErr:    errors.New("failed to queue invoice.paid event deliveries: db locked"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var created []payd.WebhookDeliveryCreate
+			store := &mocks.WebhookReaderWriterMock{
+				WebhooksFunc: test.webhooksFunc,
+				WebhookDeliveriesCreateFunc: func(ctx context.Context, req []payd.WebhookDeliveryCreate) error {
+					created = req
+					return test.createErr
+				},
+			}
+			svc := service.NewWebhooks(log.Noop{}, &config.Webhooks{}, store, nil, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+
+			err := svc.Publish(context.Background(), payd.WebhookEventInvoicePaid, payd.Invoice{ID: "abc123"})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			ids := make([]string, 0, len(created))
+			for _, d := range created {
+				ids = append(ids, d.WebhookID)
+				assert.NotEmpty(t, d.DeliveryID)
+				assert.Equal(t, payd.WebhookEventInvoicePaid, d.Event)
+				assert.Equal(t, now, d.NextAttemptAt)
+				var inv payd.Invoice
+				require.NoError(t, json.Unmarshal(d.Payload, &inv))
+				assert.Equal(t, "abc123", inv.ID)
+			}
+			assert.Equal(t, test.expWebhookIDs, ids)
+		})
+	}
+}
+
+func TestWebhookService_WebhooksDeliver(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	cfg := &config.Webhooks{MaxAttempts: 5, Backoff: 30 * time.Second, BackoffMax: 90 * time.Second}
+	tests := map[string]struct {
+		delivery  payd.WebhookDelivery
+		sendErr   error
+		expUpdate payd.WebhookDeliveryUpdate
+	}{
+		"successful send is delivered": {
+			delivery: payd.WebhookDelivery{ID: "d1", WebhookID: "wh1", NextAttemptAt: now},
+			expUpdate: payd.WebhookDeliveryUpdate{
+				State:         payd.StateWebhookDeliveryDelivered,
+				Attempts:      1,
+				NextAttemptAt: now,
+				DeliveredAt:   null.TimeFrom(now),
+				UpdatedAt:     now,
+			},
+		},
+		"first failure is retried after the backoff": {
+			delivery: payd.WebhookDelivery{ID: "d1", WebhookID: "wh1", NextAttemptAt: now},
+			sendErr:  errors.New("connection refused"),
+			expUpdate: payd.WebhookDeliveryUpdate{
+				State:         payd.StateWebhookDeliveryPending,
+				Attempts:      1,
+				NextAttemptAt: now.Add(30 * time.Second),
+				LastError:     null.StringFrom("connection refused"),
+				UpdatedAt:     now,
+			},
+		},
+		"backoff doubles with each attempt": {
+			delivery: payd.WebhookDelivery{ID: "d1", WebhookID: "wh1", Attempts: 1, NextAttemptAt: now},
+			sendErr:  errors.New("connection refused"),
+			expUpdate: payd.WebhookDeliveryUpdate{
+				State:         payd.StateWebhookDeliveryPending,
+				Attempts:      2,
+				NextAttemptAt: now.Add(60 * time.Second),
+				LastError:     null.StringFrom("connection refused"),
+				UpdatedAt:     now,
+			},
+		},
+		"backoff is capped": {
+			delivery: payd.WebhookDelivery{ID: "d1", WebhookID: "wh1", Attempts: 3, NextAttemptAt: now},
+			sendErr:  errors.New("connection refused"),
+			expUpdate: payd.WebhookDeliveryUpdate{
+				State:         payd.StateWebhookDeliveryPending,
+				Attempts:      4,
+				NextAttemptAt: now.Add(90 * time.Second),
+				LastError:     null.StringFrom("connection refused"),
+				UpdatedAt:     now,
+			},
+		},
+		"delivery is dead lettered when out of attempts": {
+			delivery: payd.WebhookDelivery{ID: "d1", WebhookID: "wh1", Attempts: 4, NextAttemptAt: now},
+			sendErr:  errors.New("unexpected status code 500"),
+			expUpdate: payd.WebhookDeliveryUpdate{
+				State:         payd.StateWebhookDeliveryDead,
+				Attempts:      5,
+				NextAttemptAt: now.Add(90 * time.Second),
+				LastError:     null.StringFrom("unexpected status code 500"),
+				UpdatedAt:     now,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var update payd.WebhookDeliveryUpdate
+			store := &mocks.WebhookReaderWriterMock{
+				WebhookDeliveriesDueFunc: func(ctx context.Context, args payd.WebhookDeliveriesDueArgs) ([]payd.WebhookDelivery, error) {
+					assert.Equal(t, now, args.DueBefore)
+					return []payd.WebhookDelivery{test.delivery}, nil
+				},
+				WebhookFunc: func(ctx context.Context, args payd.WebhookArgs) (*payd.Webhook, error) {
+					return &payd.Webhook{ID: args.WebhookID, URL: "https://merchant.com/hooks", Secret: "shh"}, nil
+				},
+				WebhookDeliveryUpdateFunc: func(ctx context.Context, args payd.WebhookDeliveryArgs, req payd.WebhookDeliveryUpdate) error {
+					assert.Equal(t, test.delivery.ID, args.DeliveryID)
+					update = req
+					return nil
+				},
+			}
+			sender := &mocks.WebhookSenderMock{
+				WebhookSendFunc: func(ctx context.Context, args payd.WebhookSendArgs, msg payd.WebhookMessage) error {
+					assert.Equal(t, payd.WebhookSendArgs{URL: "https://merchant.com/hooks", Secret: "shh"}, args)
+					assert.Equal(t, test.delivery.ID, msg.ID)
+					return test.sendErr
+				},
+			}
+			svc := service.NewWebhooks(log.Noop{}, cfg, store, sender, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+
+			n, err := svc.WebhooksDeliver(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+			assert.Len(t, sender.WebhookSendCalls(), 1)
+			assert.Equal(t, test.expUpdate, update)
+		})
+	}
+}
+
+func TestWebhookService_WebhookDeliveryReplay(t *testing.T) {
+	now := time.Date(2022, 8, 3, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		deliveryFunc func(context.Context, payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error)
+		webhookErr   error
+		args         payd.WebhookDeliveryArgs
+		expDelivery  *payd.WebhookDelivery
+		expErr       error
+	}{
+		"dead delivery is queued again": {
+			deliveryFunc: func(context.Context, payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error) {
+				return &payd.WebhookDelivery{
+					ID:        "d1",
+					WebhookID: "wh1",
+					State:     payd.StateWebhookDeliveryDead,
+					Attempts:  10,
+					LastError: null.StringFrom("connection refused"),
+				}, nil
+			},
+			args: payd.WebhookDeliveryArgs{DeliveryID: "d1"},
+			expDelivery: &payd.WebhookDelivery{
+				ID:            "d1",
+				WebhookID:     "wh1",
+				State:         payd.StateWebhookDeliveryPending,
+				NextAttemptAt: now,
+				LastError:     null.StringFrom("connection refused"),
+				UpdatedAt:     now,
+			},
+		},
+		"delivery to deleted webhook is not replayed": {
+			deliveryFunc: func(context.Context, payd.WebhookDeliveryArgs) (*payd.WebhookDelivery, error) {
+				return &payd.WebhookDelivery{ID: "d1", WebhookID: "wh1"}, nil
+			},
+			webhookErr: errors.New("not found"),
+			args:       payd.WebhookDeliveryArgs{DeliveryID: "d1"},
+			expErr:     errors.New("failed to replay webhook delivery with id d1: not found"),
+		},
+		"invalid args are rejected": {
+			expErr: errors.New("[deliveryID: value must be between 1 and 36 characters]"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &mocks.WebhookReaderWriterMock{
+				WebhookDeliveryFunc: test.deliveryFunc,
+				WebhookFunc: func(ctx context.Context, args payd.WebhookArgs) (*payd.Webhook, error) {
+					return &payd.Webhook{ID: args.WebhookID}, test.webhookErr
+				},
+				WebhookDeliveryUpdateFunc: func(ctx context.Context, args payd.WebhookDeliveryArgs, req payd.WebhookDeliveryUpdate) error {
+					return nil
+				},
+			}
+			svc := service.NewWebhooks(log.Noop{}, &config.Webhooks{}, store, nil, &mocks.TimestampServiceMock{
+				NowUTCFunc: func() time.Time {
+					return now
+				},
+			})
+
+			d, err := svc.WebhookDeliveryReplay(context.Background(), test.args)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, store.WebhookDeliveryUpdateCalls())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expDelivery, d)
+		})
+	}
+}
@@ -12,11 +12,20 @@ const (
 	StateTxBroadcast TxState = "broadcast"
 	StateTxFailed    TxState = "failed"
 	StateTxPending   TxState = "pending"
+	// StateTxMined is a tx with a merkle proof that doesn't yet have the confirmations needed to spend its txos.
+	StateTxMined TxState = "mined"
+	// StateTxConfirmed is a tx with a merkle proof and the confirmations needed to spend its txos.
+	StateTxConfirmed TxState = "confirmed"
 )
 
 // TxState defines states a transaction can have.
 type TxState string
 
+// Broadcast returns true if the tx has been broadcast, whether or not it has since been mined.
+func (s TxState) Broadcast() bool {
+	return s == StateTxBroadcast || s == StateTxMined || s == StateTxConfirmed
+}
+
 // Transaction defines a single transaction.
 type Transaction struct {
 	PaymentID string    `db:"paymentid"`
@@ -24,7 +33,7 @@ type Transaction struct {
 	TxHex     string    `db:"tx_hex"`
 	CreatedAt time.Time `db:"created_at"`
 	Outputs   []Txo     `db:"-"`
-	State     string    `enums:"pending,broadcast,failed,deleted"`
+	State     string    `enums:"pending,broadcast,mined,confirmed,failed,deleted"`
 }
 
 // Txo defines a single txo and can be returned from the data store.
@@ -78,13 +87,32 @@ type TransactionArgs struct {
 type TransactionStateUpdate struct {
 	State      TxState     `db:"state"`
 	FailReason null.String `db:"fail_reason"`
+	// BlockHeight is the height of the block a mined or confirmed tx is in, it is cleared for other states.
+	BlockHeight null.Int `db:"block_height"`
+}
+
+// TransactionsConfirmArgs identify the mined txs that now have the confirmations they need.
+type TransactionsConfirmArgs struct {
+	// MaxBlockHeight, mined txs in blocks at or below this height are confirmed.
+	MaxBlockHeight uint32
 }
 
 // TransactionWriter will add and update transaction data.
 type TransactionWriter interface {
 	TransactionCreate(ctx context.Context, req TransactionCreate) error
-	// TransactionUpdateState can be used to change a tx state (failed, broadcast).
+	// TransactionUpdateState can be used to change a tx state (failed, broadcast, mined, confirmed).
+	// A tx with a proof on the best chain can only be set mined or confirmed, it is reverted once its
+	// proofs are stale. The block height is kept unless set and is cleared when the tx isn't mined.
 	TransactionUpdateState(ctx context.Context, args TransactionArgs, req TransactionStateUpdate) error
+	// TransactionsConfirm moves mined txs to confirmed, returning the number confirmed.
+	TransactionsConfirm(ctx context.Context, args TransactionsConfirmArgs) (int, error)
+}
+
+// ConfirmationService is used to track the confirmations of mined txs.
+type ConfirmationService interface {
+	// TransactionsConfirm confirms the mined txs that now have the confirmations needed
+	// to spend their txos, returning the number confirmed.
+	TransactionsConfirm(ctx context.Context) (int, error)
 }
 
 // TransactionSubmitArgs are used to identify a tx.
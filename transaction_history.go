@@ -0,0 +1,188 @@
+package payd
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
+)
+
+// defines the directions of a tx relative to the wallet.
+const (
+	TxDirectionReceived TxDirection = "received"
+	TxDirectionSent     TxDirection = "sent"
+)
+
+// TxDirection is whether a tx paid the wallet or was paid for by the wallet.
+type TxDirection string
+
+const (
+	// TransactionSearchDefaultLimit is the page size used when no limit is supplied.
+	TransactionSearchDefaultLimit = 50
+	// TransactionSearchMaxLimit is the largest page size that can be requested.
+	TransactionSearchMaxLimit = 500
+)
+
+// TransactionHistory is a tx sent or received by the wallet along with its
+// effect on the wallet balance.
+type TransactionHistory struct {
+	TxID string `json:"txid" db:"tx_id"`
+	// Direction is sent when the tx spends wallet txos, otherwise it is received.
+	Direction TxDirection `json:"direction" db:"-" enums:"received,sent"`
+	// Satoshis is the net amount for the wallet, received less spent, it is negative for sent txs.
+	Satoshis int64 `json:"satoshis" db:"-"`
+	// Received is the total of the outputs paying the wallet, including change.
+	Received uint64 `json:"received" db:"received"`
+	// Spent is the total of the wallet txos spent by the tx.
+	Spent uint64 `json:"spent" db:"spent"`
+	// Fee is the mining fee paid by the wallet, it is 0 for received txs as the payer paid it.
+	Fee   uint64  `json:"fee" db:"-"`
+	State TxState `json:"state" db:"state" enums:"pending,broadcast,mined,confirmed,failed"`
+	// FailReason is set when the tx failed to broadcast.
+	FailReason null.String `json:"failReason" db:"fail_reason" swaggertype:"primitive,string"`
+	// Confirmed is true once the tx has a merkle proof.
+	Confirmed bool `json:"confirmed" db:"confirmed"`
+	// InvoiceID is the invoice paid by a received tx, or refunded by a sent tx.
+	InvoiceID null.String `json:"invoiceId" db:"invoice_id" swaggertype:"primitive,string"`
+	// PaidTo is the url paid by a sent tx, this is what the spent txos were reserved for so it has the
+	// payment attempt as its fragment.
+	PaidTo    null.String `json:"paidTo" db:"paid_to" swaggertype:"primitive,string"`
+	TxHex     string      `json:"-" db:"tx_hex"`
+	CreatedAt time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// TransactionCursor marks the position of the last tx in a page, txs are ordered
+// by their creation date then id so the next page starts after this pair.
+//
+// It is sent to clients as an opaque string.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	TxID      string
+}
+
+// IsZero returns true if the cursor is empty, meaning the first page is requested.
+func (c TransactionCursor) IsZero() bool {
+	return c.TxID == ""
+}
+
+// MarshalText encodes the cursor to an opaque url safe string.
+func (c TransactionCursor) MarshalText() ([]byte, error) {
+	if c.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(
+		[]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.TxID))), nil
+}
+
+// UnmarshalText decodes a cursor previously encoded with MarshalText.
+func (c *TransactionCursor) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = TransactionCursor{}
+		return nil
+	}
+	bb, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	parts := strings.SplitN(string(bb), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	c.CreatedAt = createdAt
+	c.TxID = parts[1]
+	return nil
+}
+
+// String returns the encoded cursor.
+func (c TransactionCursor) String() string {
+	bb, _ := c.MarshalText()
+	return string(bb)
+}
+
+// TransactionSearchArgs are used to filter and page through the wallet txs.
+// Zero values are ignored.
+type TransactionSearchArgs struct {
+	// State will only return txs in this state.
+	State TxState `query:"state" enums:"pending,broadcast,failed"`
+	// CreatedFrom will return txs created at or after this time.
+	CreatedFrom null.Time `query:"createdFrom" swaggertype:"primitive,string"`
+	// CreatedTo will return txs created before this time.
+	CreatedTo null.Time `query:"createdTo" swaggertype:"primitive,string"`
+	// Sort orders txs by creation date, defaults to desc, newest first.
+	Sort SortOrder `query:"sort" enums:"asc,desc"`
+	// Limit is the max number of txs returned.
+	Limit int `query:"limit"`
+	// Cursor is the next cursor returned with the previous page, when supplied
+	// the txs after it are returned. The other search args should match those
+	// used to get the previous page.
+	Cursor TransactionCursor `query:"cursor" swaggertype:"primitive,string"`
+}
+
+// Validate will check that the search args match expectations.
+func (t TransactionSearchArgs) Validate() error {
+	v := validator.New().
+		Validate("state", validator.AnyString(string(t.State), "",
+			string(StateTxPending), string(StateTxBroadcast), string(StateTxMined), string(StateTxConfirmed),
+			string(StateTxFailed))).
+		Validate("sort", validator.AnyString(string(t.Sort), "", string(SortAsc), string(SortDesc))).
+		Validate("limit", validator.BetweenInt(t.Limit, 0, TransactionSearchMaxLimit))
+	if t.CreatedFrom.Valid && t.CreatedTo.Valid {
+		v = v.Validate("createdTo", validator.DateAfter(t.CreatedTo.Time, t.CreatedFrom.Time))
+	}
+	return v.Err()
+}
+
+// Descending returns true if txs should be returned newest first.
+func (t TransactionSearchArgs) Descending() bool {
+	return t.Sort != SortAsc
+}
+
+// Matches returns true if tx matches the search filters and comes after the cursor
+// in the sort order, it is used by stores that filter txs in process.
+func (t TransactionSearchArgs) Matches(tx TransactionHistory) bool {
+	switch {
+	case t.State != "" && tx.State != t.State,
+		t.CreatedFrom.Valid && tx.CreatedAt.Before(t.CreatedFrom.Time),
+		t.CreatedTo.Valid && !tx.CreatedAt.Before(t.CreatedTo.Time):
+		return false
+	}
+	if t.Cursor.IsZero() {
+		return true
+	}
+	after := tx.CreatedAt.After(t.Cursor.CreatedAt) ||
+		tx.CreatedAt.Equal(t.Cursor.CreatedAt) && tx.TxID > t.Cursor.TxID
+	before := tx.CreatedAt.Before(t.Cursor.CreatedAt) ||
+		tx.CreatedAt.Equal(t.Cursor.CreatedAt) && tx.TxID < t.Cursor.TxID
+	if t.Descending() {
+		return before
+	}
+	return after
+}
+
+// TransactionPage is a single page of tx search results.
+type TransactionPage struct {
+	Transactions []TransactionHistory `json:"transactions"`
+	// NextCursor is supplied as the cursor to get the next page, it is empty
+	// when there are no more txs.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// TransactionHistoryService is used to list the txs sent and received by the wallet.
+type TransactionHistoryService interface {
+	// Transactions returns a page of the txs matching args.
+	Transactions(ctx context.Context, args TransactionSearchArgs) (*TransactionPage, error)
+}
+
+// TransactionReader is used to read txs from a data store.
+type TransactionReader interface {
+	// Transactions returns the txs matching args, ordered by creation date then id, with
+	// the wallet txos they received and spent totalled.
+	Transactions(ctx context.Context, args TransactionSearchArgs) ([]TransactionHistory, error)
+}
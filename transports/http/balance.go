@@ -25,14 +25,24 @@ func (b *balance) RegisterRoutes(g *echo.Group) {
 
 // balance godoc
 // @Summary Balance
-// @Description Returns current balance, which is a sum of unspent txos
+// @Description Returns current balance, the unspent txos totalled by whether they are confirmed, mined, unconfirmed,
+// @Description reserved for a payment, or belong to a pending or failed tx. Confirmed, mined and unconfirmed txos
+// @Description are spendable, unless confirmations are required when only confirmed txos are spendable.
+// @Description The balance can be scoped to the txos paid to a user or key.
 // @Tags Balance
 // @Accept json
 // @Produce json
-// @Success 200
+// @Param userId query int false "Only include txos paid to this user"
+// @Param keyName query string false "Only include txos paid to destinations derived from this key"
+// @Success 200 {object} payd.Balance
+// @Failure 400 {object} payd.ClientError "returned if the args are invalid"
 // @Router /v1/balance [GET].
 func (b *balance) balance(e echo.Context) error {
-	resp, err := b.svc.Balance(e.Request().Context())
+	var args payd.BalanceArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse balance args")
+	}
+	resp, err := b.svc.Balance(e.Request().Context(), args)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -28,18 +28,35 @@ func (i *invoices) RegisterRoutes(g *echo.Group) {
 
 // invoices godoc
 // @Summary Invoices
-// @Description Returns all invoices currently stored
+// @Description Returns a page of invoices matching the search params, newest first by default. When there are more
+// @Description invoices the nextCursor returned should be supplied as the cursor to get the next page.
 // @Tags Invoices
 // @Accept json
 // @Produce json
-// @Success 200
+// @Param state query string false "Only return invoices in this state" Enums(pending, partially_paid, paid, refunded, expired)
+// @Param reference query string false "Only return invoices with this payment reference"
+// @Param createdFrom query string false "Only return invoices created at or after this RFC3339 date"
+// @Param createdTo query string false "Only return invoices created before this RFC3339 date"
+// @Param expiresFrom query string false "Only return invoices expiring at or after this RFC3339 date"
+// @Param expiresTo query string false "Only return invoices expiring before this RFC3339 date"
+// @Param satoshisMin query int false "Only return invoices for at least this many satoshis"
+// @Param satoshisMax query int false "Only return invoices for at most this many satoshis"
+// @Param sort query string false "Sort by creation date" Enums(asc, desc)
+// @Param limit query int false "Page size, defaults to 50, max 500"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Success 200 {object} payd.InvoicePage
+// @Failure 400 {object} payd.ClientError "returned if the search params are invalid"
 // @Router /v1/invoices [GET].
 func (i *invoices) invoices(e echo.Context) error {
-	ii, err := i.svc.Invoices(e.Request().Context())
+	var args payd.InvoiceSearchArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse invoice search args")
+	}
+	page, err := i.svc.Invoices(e.Request().Context(), args)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	return e.JSON(http.StatusOK, ii)
+	return e.JSON(http.StatusOK, page)
 }
 
 // invoices godoc
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/payd"
+)
+
+type keyBackup struct {
+	svc payd.KeyBackupService
+}
+
+// NewKeyBackup will setup and return a new key backup handler.
+func NewKeyBackup(svc payd.KeyBackupService) *keyBackup {
+	return &keyBackup{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (k *keyBackup) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1KeyMnemonic, k.mnemonic)
+	g.POST(RouteV1KeyRestore, k.restore)
+	g.POST(RouteV1KeyRescan, k.rescan)
+}
+
+// mnemonic godoc
+// @Summary Reveal key mnemonic
+// @Description Returns the BIP39 mnemonic a key was created from. The mnemonic is deleted once revealed,
+// @Description so it must be written down, along with the wallet mnemonic passphrase if one is set.
+// @Tags Keys
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param name path string true "Key name"
+// @Success 200 {object} payd.KeyMnemonic
+// @Failure 404 {object} payd.ClientError "returned if the mnemonic has already been revealed or the key was imported"
+// @Failure 422 {object} payd.ClientError "returned if the wallet is locked"
+// @Router /v1/users/{id}/keys/{name}/mnemonic [POST].
+func (k *keyBackup) mnemonic(e echo.Context) error {
+	args, err := keyArgs(e)
+	if err != nil {
+		return err
+	}
+	resp, err := k.svc.KeyMnemonicReveal(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// restore godoc
+// @Summary Restore key
+// @Description Replaces a key with one restored from a BIP39 mnemonic, and optional passphrase, or an xprv.
+// @Description The restored key must derive every destination previously derived from the key, the
+// @Description result of this rescan is returned.
+// @Tags Keys
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param name path string true "Key name"
+// @Param body body payd.KeyRestore true "The key backup"
+// @Success 200 {object} payd.KeyRescan
+// @Failure 400 {object} payd.ClientError "returned if the mnemonic or xprv is invalid"
+// @Failure 422 {object} payd.ClientError "returned if the restored key doesn't derive the previous destinations or the wallet is locked"
+// @Router /v1/users/{id}/keys/{name}/restore [POST].
+func (k *keyBackup) restore(e echo.Context) error {
+	args, err := keyArgs(e)
+	if err != nil {
+		return err
+	}
+	var req payd.KeyRestore
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse key restore request")
+	}
+	resp, err := k.svc.KeyRestore(e.Request().Context(), args, req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// rescan godoc
+// @Summary Rescan key
+// @Description Checks the key derives each destination previously derived from it, as stored in the database, and returns the balance they hold.
+// @Description The chain isn't searched for other funds.
+// @Tags Keys
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param name path string true "Key name"
+// @Success 200 {object} payd.KeyRescan
+// @Router /v1/users/{id}/keys/{name}/rescan [POST].
+func (k *keyBackup) rescan(e echo.Context) error {
+	args, err := keyArgs(e)
+	if err != nil {
+		return err
+	}
+	resp, err := k.svc.KeyRescan(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+func keyArgs(e echo.Context) (payd.KeyArgs, error) {
+	userID, err := strconv.ParseUint(e.Param("id"), 10, 64)
+	if err != nil {
+		return payd.KeyArgs{}, validator.NewSingleError("id", []string{"user id is not a valid number"})
+	}
+	return payd.KeyArgs{Name: e.Param("name"), UserID: userID}, nil
+}
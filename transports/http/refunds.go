@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type refunds struct {
+	svc payd.RefundService
+}
+
+// NewRefunds will setup and return a new refunds handler.
+func NewRefunds(svc payd.RefundService) *refunds {
+	return &refunds{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (r *refunds) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1InvoiceRefund, r.create)
+}
+
+// create godoc
+// @Summary Refund invoice
+// @Description Refunds a paid invoice to the refundTo paymail or locking script supplied with the payment.
+// @Description If satoshis isn't set everything received, less any previous refunds, is refunded.
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param invoiceID path string true "Invoice ID"
+// @Param body body payd.RefundCreate false "Amount to refund"
+// @Success 201 {object} payd.InvoiceRefund
+// @Failure 400 {object} payd.ClientError "returned if the amount is more than is left to refund"
+// @Failure 404 {object} payd.ClientError "returned if the invoice has not been found"
+// @Failure 422 {object} payd.ClientError "returned if the invoice isn't paid or has no refund address"
+// @Router /v1/invoices/{invoiceID}/refund [POST].
+func (r *refunds) create(e echo.Context) error {
+	var req payd.RefundCreate
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse refund req")
+	}
+	resp, err := r.svc.Refund(e.Request().Context(), payd.InvoiceArgs{InvoiceID: e.Param("invoiceID")}, req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusCreated, resp)
+}
@@ -3,12 +3,14 @@ package http
 // Routes used in the http handlers.
 const (
 	// Receive payment endpoints.
-	RouteV1Invoice     = "api/v1/invoices/:invoiceID"
-	RouteV1Invoices    = "api/v1/invoices"
-	RouteV1Payment     = "api/v1/payments/:invoiceID"
-	RouteV1Proofs      = "api/v1/proofs/:txid"
-	RouteV1Connect     = "api/v1/socket/connect/:invoiceID"
-	RouteV1Transaction = "api/v1/transactions/:invoiceID"
+	RouteV1Invoice       = "api/v1/invoices/:invoiceID"
+	RouteV1Invoices      = "api/v1/invoices"
+	RouteV1InvoiceRefund = "api/v1/invoices/:invoiceID/refund"
+	RouteV1Payment       = "api/v1/payments/:invoiceID"
+	RouteV1Proofs        = "api/v1/proofs/:txid"
+	RouteV1Connect       = "api/v1/socket/connect/:invoiceID"
+	RouteV1Transaction   = "api/v1/transactions/:invoiceID"
+	RouteV1Transactions  = "api/v1/transactions"
 
 	// User management.
 	RouteV1Balance = "api/v1/balance"
@@ -22,5 +24,31 @@ const (
 	// TODO - fix this endpoint def.
 	RouteV1Submit = "api/v1/submit"
 
+	// Webhooks.
+	RouteV1Webhooks              = "api/v1/webhooks"
+	RouteV1Webhook               = "api/v1/webhooks/:webhookID"
+	RouteV1WebhookDeliveries     = "api/v1/webhooks/deliveries"
+	RouteV1WebhookDeliveryReplay = "api/v1/webhooks/deliveries/:deliveryID/replay"
+
+	// UTXO reservations.
+	RouteV1Reservations = "api/v1/reservations"
+
+	// UTXO management.
+	RouteV1UTXOs            = "api/v1/utxos"
+	RouteV1UTXOsFreeze      = "api/v1/utxos/freeze"
+	RouteV1UTXOsUnfreeze    = "api/v1/utxos/unfreeze"
+	RouteV1UTXOsConsolidate = "api/v1/utxos/consolidate"
+	RouteV1UTXOsFanOut      = "api/v1/utxos/fanout"
+
+	// Wallet encryption.
+	RouteV1WalletLock    = "api/v1/wallet/lock"
+	RouteV1WalletUnlock  = "api/v1/wallet/unlock"
+	RouteV1WalletEncrypt = "api/v1/wallet/encrypt"
+
+	// Key backup and restore.
+	RouteV1KeyMnemonic = "api/v1/users/:id/keys/:name/mnemonic"
+	RouteV1KeyRestore  = "api/v1/users/:id/keys/:name/restore"
+	RouteV1KeyRescan   = "api/v1/users/:id/keys/:name/rescan"
+
 	RouteV1Health = "api/v1/health"
 )
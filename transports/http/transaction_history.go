@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type transactionHistory struct {
+	svc payd.TransactionHistoryService
+}
+
+// NewTransactionHistory will setup and return a new transaction history handler.
+func NewTransactionHistory(svc payd.TransactionHistoryService) *transactionHistory {
+	return &transactionHistory{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (t *transactionHistory) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1Transactions, t.transactions)
+}
+
+// transactions godoc
+// @Summary Transactions
+// @Description Returns a page of the txs sent and received by the wallet, newest first by default. Each tx has the
+// @Description net amount for the wallet, the fee the wallet paid and the invoice or url it paid. When there are more
+// @Description txs the nextCursor returned should be supplied as the cursor to get the next page.
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param state query string false "Only return txs in this state" Enums(pending, broadcast, mined, confirmed, failed)
+// @Param createdFrom query string false "Only return txs created at or after this RFC3339 date"
+// @Param createdTo query string false "Only return txs created before this RFC3339 date"
+// @Param sort query string false "Sort by creation date" Enums(asc, desc)
+// @Param limit query int false "Page size, defaults to 50, max 500"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Success 200 {object} payd.TransactionPage
+// @Failure 400 {object} payd.ClientError "returned if the search params are invalid"
+// @Router /v1/transactions [GET].
+func (t *transactionHistory) transactions(e echo.Context) error {
+	var args payd.TransactionSearchArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse transaction search args")
+	}
+	page, err := t.svc.Transactions(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type utxoMaintenance struct {
+	svc payd.UTXOMaintenanceService
+}
+
+// NewUTXOMaintenance will setup and return a new utxo maintenance handler.
+func NewUTXOMaintenance(svc payd.UTXOMaintenanceService) *utxoMaintenance {
+	return &utxoMaintenance{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (u *utxoMaintenance) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1UTXOsConsolidate, u.consolidate)
+	g.POST(RouteV1UTXOsFanOut, u.fanOut)
+}
+
+// consolidate godoc
+// @Summary Consolidate UTXOs
+// @Description Sweeps the smallest utxos, worth satoshisMax or less, into a single utxo at the standard fee rate.
+// @Description Zero values default to the wallet config, this also runs on a schedule when WALLET_CONSOLIDATE_INTERVAL_SECONDS is set.
+// @Tags UTXOs
+// @Accept json
+// @Produce json
+// @Param body body payd.UTXOsConsolidate false "The utxos to consolidate"
+// @Success 200 {object} payd.UTXOMaintenanceTx
+// @Success 204 "returned if there are fewer than minUtxos small utxos"
+// @Failure 400 {object} payd.ClientError "returned if the args are invalid"
+// @Failure 422 {object} payd.ClientError "returned if the utxos can't pay the fee"
+// @Router /v1/utxos/consolidate [POST].
+func (u *utxoMaintenance) consolidate(e echo.Context) error {
+	var req payd.UTXOsConsolidate
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse utxos to consolidate")
+	}
+	tx, err := u.svc.UTXOsConsolidate(e.Request().Context(), req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if tx == nil {
+		return e.NoContent(http.StatusNoContent)
+	}
+	return e.JSON(http.StatusOK, tx)
+}
+
+// fanOut godoc
+// @Summary Fan out UTXOs
+// @Description Splits the largest utxo, worth satoshisMin or more, into outputs utxos of equal value at the standard fee rate.
+// @Description Zero values default to the wallet config, this also runs on a schedule when WALLET_FANOUT_INTERVAL_SECONDS is set.
+// @Tags UTXOs
+// @Accept json
+// @Produce json
+// @Param body body payd.UTXOsFanOut false "The utxo to fan out"
+// @Success 200 {object} payd.UTXOMaintenanceTx
+// @Success 204 "returned if no utxos are worth satoshisMin"
+// @Failure 400 {object} payd.ClientError "returned if the args are invalid"
+// @Failure 422 {object} payd.ClientError "returned if the outputs would be below the dust limit"
+// @Router /v1/utxos/fanout [POST].
+func (u *utxoMaintenance) fanOut(e echo.Context) error {
+	var req payd.UTXOsFanOut
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse utxos to fan out")
+	}
+	tx, err := u.svc.UTXOsFanOut(e.Request().Context(), req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if tx == nil {
+		return e.NoContent(http.StatusNoContent)
+	}
+	return e.JSON(http.StatusOK, tx)
+}
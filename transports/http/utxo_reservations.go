@@ -0,0 +1,70 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type utxoReservations struct {
+	svc payd.UTXOReservationService
+}
+
+// NewUTXOReservations will setup and return a new utxo reservations handler.
+func NewUTXOReservations(svc payd.UTXOReservationService) *utxoReservations {
+	return &utxoReservations{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (u *utxoReservations) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1Reservations, u.reservations)
+	g.DELETE(RouteV1Reservations, u.release)
+}
+
+// reservations godoc
+// @Summary UTXO reservations
+// @Description Returns the utxos reserved to fund payments, grouped by the url being paid.
+// @Description Reservations past their expiry are released by a background job.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param expired query bool false "Only return expired reservations"
+// @Success 200 {object} []payd.UTXOReservation
+// @Router /v1/reservations [GET].
+func (u *utxoReservations) reservations(e echo.Context) error {
+	var args payd.UTXOReservationsArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse reservations args")
+	}
+	resp, err := u.svc.UTXOReservations(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// release godoc
+// @Summary Release UTXO reservation
+// @Description Releases the utxos reserved for a payment so they can be spent again, use this
+// @Description to free utxos held by a stuck payment without waiting for the reservation to expire.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param reservedFor query string true "The payment attempt the utxos are reserved for, the url being paid with the attempt as its fragment"
+// @Success 204
+// @Failure 400 {object} payd.ClientError "returned if reservedFor is missing"
+// @Failure 404 {object} payd.ClientError "returned if nothing is reserved for the url"
+// @Router /v1/reservations [DELETE].
+func (u *utxoReservations) release(e echo.Context) error {
+	var args payd.UTXOReservationArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse reservation args")
+	}
+	if err := u.svc.UTXOReservationRelease(e.Request().Context(), args); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type utxos struct {
+	svc payd.UTXOService
+}
+
+// NewUTXOs will setup and return a new utxos handler.
+func NewUTXOs(svc payd.UTXOService) *utxos {
+	return &utxos{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (u *utxos) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1UTXOs, u.utxos)
+	g.POST(RouteV1UTXOsFreeze, u.freeze)
+	g.POST(RouteV1UTXOsUnfreeze, u.unfreeze)
+}
+
+// utxos godoc
+// @Summary UTXOs
+// @Description Returns a page of the txos paid to the wallet matching the search params, newest first by default.
+// @Description When there are more txos the nextCursor returned should be supplied as the cursor to get the next page.
+// @Tags UTXOs
+// @Accept json
+// @Produce json
+// @Param spent query bool false "Only return spent txos when true, or unspent txos when false"
+// @Param reserved query bool false "Only return txos reserved for a payment when true, or those that aren't when false"
+// @Param confirmed query bool false "Only return txos of txs with a merkle proof when true, or those without when false"
+// @Param frozen query bool false "Only return frozen txos when true, or those that aren't when false"
+// @Param satoshisMin query int false "Only return txos worth at least this many satoshis"
+// @Param satoshisMax query int false "Only return txos worth at most this many satoshis"
+// @Param derivationPath query string false "Only return txos paid to this derivation path, or any path beneath it"
+// @Param sort query string false "Sort by creation date" Enums(asc, desc)
+// @Param limit query int false "Page size, defaults to 50, max 500"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Success 200 {object} payd.UTXOPage
+// @Failure 400 {object} payd.ClientError "returned if the search params are invalid"
+// @Router /v1/utxos [GET].
+func (u *utxos) utxos(e echo.Context) error {
+	var args payd.UTXOSearchArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse utxo search args")
+	}
+	page, err := u.svc.UTXOs(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, page)
+}
+
+// freeze godoc
+// @Summary Freeze UTXOs
+// @Description Freezes unspent txos so they are never reserved to fund a payment, if any
+// @Description of the outpoints aren't unspent txos of the wallet none are frozen.
+// @Tags UTXOs
+// @Accept json
+// @Produce json
+// @Param body body payd.UTXOsFreeze true "The outpoints to freeze"
+// @Success 204
+// @Failure 400 {object} payd.ClientError "returned if no outpoints are supplied"
+// @Failure 404 {object} payd.ClientError "returned if an outpoint isn't an unspent txo"
+// @Router /v1/utxos/freeze [POST].
+func (u *utxos) freeze(e echo.Context) error {
+	var req payd.UTXOsFreeze
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse utxos to freeze")
+	}
+	if err := u.svc.UTXOsFreeze(e.Request().Context(), req); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
+
+// unfreeze godoc
+// @Summary Unfreeze UTXOs
+// @Description Unfreezes txos so they can be reserved to fund payments again, if any
+// @Description of the outpoints aren't unspent txos of the wallet none are unfrozen.
+// @Tags UTXOs
+// @Accept json
+// @Produce json
+// @Param body body payd.UTXOsFreeze true "The outpoints to unfreeze"
+// @Success 204
+// @Failure 400 {object} payd.ClientError "returned if no outpoints are supplied"
+// @Failure 404 {object} payd.ClientError "returned if an outpoint isn't an unspent txo"
+// @Router /v1/utxos/unfreeze [POST].
+func (u *utxos) unfreeze(e echo.Context) error {
+	var req payd.UTXOsFreeze
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse utxos to unfreeze")
+	}
+	if err := u.svc.UTXOsUnfreeze(e.Request().Context(), req); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type walletLock struct {
+	svc payd.WalletLockService
+}
+
+// NewWalletLock will setup and return a new wallet lock handler.
+func NewWalletLock(svc payd.WalletLockService) *walletLock {
+	return &walletLock{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (w *walletLock) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1WalletLock, w.status)
+	g.POST(RouteV1WalletLock, w.lock)
+	g.POST(RouteV1WalletUnlock, w.unlock)
+	g.POST(RouteV1WalletEncrypt, w.encrypt)
+}
+
+// status godoc
+// @Summary Wallet lock status
+// @Description Returns whether the wallet private keys are encrypted and, if so, whether they are locked.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Success 200 {object} payd.WalletLockStatus
+// @Router /v1/wallet/lock [GET].
+func (w *walletLock) status(e echo.Context) error {
+	resp, err := w.svc.WalletLockStatus(e.Request().Context())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// lock godoc
+// @Summary Lock wallet
+// @Description Removes the decrypted private keys from memory, payments can't be signed until the wallet
+// @Description is unlocked.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Success 204
+// @Failure 422 {object} payd.ClientError "returned if the wallet isn't encrypted"
+// @Router /v1/wallet/lock [POST].
+func (w *walletLock) lock(e echo.Context) error {
+	if err := w.svc.WalletLock(e.Request().Context()); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
+
+// unlock godoc
+// @Summary Unlock wallet
+// @Description Decrypts the private keys with the passphrase, holding them in memory for timeout seconds.
+// @Description A timeout of zero keeps the wallet unlocked until it is locked.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param body body payd.WalletUnlock true "The wallet passphrase"
+// @Success 204
+// @Failure 400 {object} payd.ClientError "returned if the args are invalid"
+// @Failure 403 {object} payd.ClientError "returned if the passphrase is incorrect"
+// @Failure 422 {object} payd.ClientError "returned if the wallet isn't encrypted"
+// @Router /v1/wallet/unlock [POST].
+func (w *walletLock) unlock(e echo.Context) error {
+	var req payd.WalletUnlock
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse wallet unlock request")
+	}
+	if err := w.svc.WalletUnlock(e.Request().Context(), req); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
+
+// encrypt godoc
+// @Summary Encrypt wallet
+// @Description Encrypts the private keys at rest with the passphrase, the wallet is locked once encrypted.
+// @Description The passphrase can't be recovered, if it is lost so are the funds.
+// @Tags Wallet
+// @Accept json
+// @Produce json
+// @Param body body payd.WalletEncrypt true "The wallet passphrase"
+// @Success 204
+// @Failure 400 {object} payd.ClientError "returned if the passphrase is too short"
+// @Failure 422 {object} payd.ClientError "returned if the wallet is already encrypted"
+// @Router /v1/wallet/encrypt [POST].
+func (w *walletLock) encrypt(e echo.Context) error {
+	var req payd.WalletEncrypt
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse wallet encrypt request")
+	}
+	if err := w.svc.WalletEncrypt(e.Request().Context(), req); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
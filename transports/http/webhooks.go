@@ -0,0 +1,158 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/payd"
+)
+
+type webhooks struct {
+	svc payd.WebhookService
+}
+
+// NewWebhooks will setup and return a new webhooks handler.
+func NewWebhooks(svc payd.WebhookService) *webhooks {
+	return &webhooks{svc: svc}
+}
+
+// RegisterRoutes will hook up the routes to the echo group.
+func (w *webhooks) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1Webhooks, w.webhooks)
+	g.POST(RouteV1Webhooks, w.create)
+	g.GET(RouteV1WebhookDeliveries, w.deliveries)
+	g.POST(RouteV1WebhookDeliveryReplay, w.replay)
+	g.GET(RouteV1Webhook, w.webhook)
+	g.DELETE(RouteV1Webhook, w.delete)
+}
+
+// webhooks godoc
+// @Summary Webhooks
+// @Description Returns all registered webhooks, their secrets are not returned.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} []payd.Webhook
+// @Router /v1/webhooks [GET].
+func (w *webhooks) webhooks(e echo.Context) error {
+	resp, err := w.svc.Webhooks(e.Request().Context())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// webhook godoc
+// @Summary Webhook
+// @Description Returns a webhook by id, the secret is not returned.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhookID path string true "Webhook ID"
+// @Success 200 {object} payd.Webhook
+// @Failure 404 {object} payd.ClientError "returned if the webhook has not been found"
+// @Router /v1/webhooks/{webhookID} [GET].
+func (w *webhooks) webhook(e echo.Context) error {
+	var args payd.WebhookArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse webhook args")
+	}
+	resp, err := w.svc.Webhook(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// create godoc
+// @Summary Create webhook
+// @Description Registers a url to be sent the events it subscribes to. Each event is posted as json with a
+// @Description X-Payd-Signature header of "sha256=<hex HMAC-SHA256 of the body keyed with the secret>".
+// @Description If a secret isn't supplied one is generated, this is the only time the secret is returned.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param body body payd.WebhookCreate true "Url, secret and events to subscribe to"
+// @Success 201 {object} payd.Webhook
+// @Failure 400 {object} payd.ClientError "returned if the url or events are invalid"
+// @Router /v1/webhooks [POST].
+func (w *webhooks) create(e echo.Context) error {
+	var req payd.WebhookCreate
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to parse webhook create req")
+	}
+	resp, err := w.svc.WebhookCreate(e.Request().Context(), req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusCreated, resp)
+}
+
+// delete godoc
+// @Summary Delete webhook
+// @Description Removes a webhook, deliveries waiting to be sent to it are not sent.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhookID path string true "Webhook ID"
+// @Success 204
+// @Failure 404 {object} payd.ClientError "returned if the webhook has not been found"
+// @Router /v1/webhooks/{webhookID} [DELETE].
+func (w *webhooks) delete(e echo.Context) error {
+	var args payd.WebhookArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse webhook delete args")
+	}
+	if err := w.svc.WebhookDelete(e.Request().Context(), args); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
+
+// deliveries godoc
+// @Summary Webhook deliveries
+// @Description Returns webhook deliveries, newest first.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param webhookId query string false "Only return deliveries to this webhook"
+// @Param state query string false "Only return deliveries in this state" Enums(pending, delivered, dead)
+// @Param limit query int false "Max deliveries to return, defaults to 50, max 500"
+// @Success 200 {object} []payd.WebhookDelivery
+// @Failure 400 {object} payd.ClientError "returned if the params are invalid"
+// @Router /v1/webhooks/deliveries [GET].
+func (w *webhooks) deliveries(e echo.Context) error {
+	var args payd.WebhookDeliveriesArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse webhook deliveries args")
+	}
+	resp, err := w.svc.WebhookDeliveries(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// replay godoc
+// @Summary Replay webhook delivery
+// @Description Queues a delivery to be sent again with a fresh set of attempts, used to retry dead deliveries.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param deliveryID path string true "Delivery ID"
+// @Success 200 {object} payd.WebhookDelivery
+// @Failure 404 {object} payd.ClientError "returned if the delivery or its webhook has not been found"
+// @Router /v1/webhooks/deliveries/{deliveryID}/replay [POST].
+func (w *webhooks) replay(e echo.Context) error {
+	var args payd.WebhookDeliveryArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to parse webhook delivery args")
+	}
+	resp, err := w.svc.WebhookDeliveryReplay(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/theflyingcodr/lathos"
@@ -95,7 +96,9 @@ func (p *paymentRequest) response(ctx context.Context, msg *sockets.Message) (*s
 	defer func() {
 		_ = p.transacter.Rollback(ctx)
 	}()
-	env, err := p.envSvc.Envelope(ctx, payd.EnvelopeArgs{PayToURL: msg.ChannelID()}, req)
+	// each attempt reserves its utxos under its own key, the channel with the attempt as its fragment, so
+	// an attempt that is never acked can be released without touching another attempt on the channel.
+	env, err := p.envSvc.Envelope(ctx, payd.EnvelopeArgs{PayToURL: msg.ChannelID() + "#" + uuid.NewString()}, req)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,9 @@ package payd
 import (
 	"context"
 	"time"
+
+	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
 )
 
 // TxoCreate will add utxos to our data store linked by a destinationId.
@@ -12,22 +15,56 @@ type TxoCreate struct {
 	DestinationID uint64 `db:"destination_id"`
 	TxID          string `db:"tx_id"`
 	Vout          uint64 `db:"vout"`
+	// Satoshis is the value of the output, this can differ from the destination
+	// satoshis when an invoice is paid in instalments.
+	Satoshis uint64 `db:"satoshis"`
 }
 
 // UTXO an internal utxo.
 type UTXO struct {
-	Outpoint       string `db:"outpoint"`
-	TxID           string `db:"tx_id"`
-	Vout           uint32 `db:"vout"`
-	Satoshis       uint64 `db:"satoshis"`
-	LockingScript  string `db:"locking_script"`
-	DerivationPath string `db:"derivation_path"`
+	Outpoint       string    `db:"outpoint"`
+	TxID           string    `db:"tx_id"`
+	Vout           uint32    `db:"vout"`
+	Satoshis       uint64    `db:"satoshis"`
+	LockingScript  string    `db:"locking_script"`
+	DerivationPath string    `db:"derivation_path"`
+	CreatedAt      time.Time `db:"created_at"`
 }
 
 // UTXOReserve takes args for marking a utxo in the db as reserved.
 type UTXOReserve struct {
 	ReservedFor string
 	Satoshis    uint64
+	// ReservedUntil is when the reservation expires and the utxos are released,
+	// if zero the reservation doesn't expire.
+	ReservedUntil time.Time
+	// InputFee and ChangeFee are passed to the Selector, see CoinSelectArgs.
+	InputFee  uint64
+	ChangeFee uint64
+	// Selector chooses the utxos to reserve from those that are spendable.
+	Selector CoinSelector
+	// KeyName and UserID, when KeyName is set, only reserve utxos paid to destinations derived
+	// from this key, the key signing the spend. Other keys, such as watch-only keys, are skipped.
+	KeyName string
+	UserID  uint64
+	// Confirmed will only reserve utxos of confirmed txs, it is set when txs need
+	// confirmations before their txos can be spent.
+	Confirmed bool
+}
+
+// MatchesKey returns true if utxos paid to destinations of the key can be reserved, it is used
+// by stores that filter utxos in process.
+func (u UTXOReserve) MatchesKey(userID uint64, keyName string) bool {
+	return u.KeyName == "" || u.KeyName == keyName && u.UserID == userID
+}
+
+// Spendable returns true if utxos of a tx in this state can be reserved, it is used
+// by stores that filter utxos in process.
+func (u UTXOReserve) Spendable(state TxState) bool {
+	if u.Confirmed {
+		return state == StateTxConfirmed
+	}
+	return state.Broadcast()
 }
 
 // UTXOUnreserve takes args for unreserving reserved utxos in the db.
@@ -42,6 +79,62 @@ type UTXOSpend struct {
 	Reservation  string    `db:"reserved_for"`
 }
 
+// UTXOReservation is a set of unspent utxos reserved to fund a payment.
+type UTXOReservation struct {
+	// ReservedFor identifies the payment attempt, usually the url being paid with the attempt as its fragment.
+	ReservedFor string `json:"reservedFor" db:"reserved_for"`
+	// UTXOs is the number of utxos reserved.
+	UTXOs    uint64 `json:"utxos" db:"utxos"`
+	Satoshis uint64 `json:"satoshis" db:"satoshis"`
+	// ReservedUntil is when the reservation expires and the utxos are released.
+	ReservedUntil null.Time `json:"reservedUntil" db:"reserved_until" swaggertype:"primitive,string"`
+	// Expired is true once ReservedUntil has passed, the reservation is released on the next run.
+	Expired bool `json:"expired" db:"-"`
+}
+
+// UTXOReservationsArgs are used to filter reservations.
+type UTXOReservationsArgs struct {
+	// Expired only returns reservations that have expired.
+	Expired bool `query:"expired"`
+}
+
+// UTXOReservationArgs identify a single reservation.
+type UTXOReservationArgs struct {
+	ReservedFor string `query:"reservedFor"`
+}
+
+// Validate will ensure the args are valid.
+func (u UTXOReservationArgs) Validate() error {
+	return validator.New().
+		Validate("reservedFor", validator.NotEmpty(u.ReservedFor)).Err()
+}
+
+// UTXOReservationService is used to manage utxo reservations, expired reservations are released
+// so utxos from abandoned payments can be spent again.
+type UTXOReservationService interface {
+	// UTXOReservations returns the current reservations.
+	UTXOReservations(ctx context.Context, args UTXOReservationsArgs) ([]UTXOReservation, error)
+	// UTXOReservationRelease will release the utxos of a reservation.
+	UTXOReservationRelease(ctx context.Context, args UTXOReservationArgs) error
+	// UTXOReservationsExpire will release expired reservations, returning the number released.
+	UTXOReservationsExpire(ctx context.Context) (int, error)
+}
+
+// TxoReaderWriter combines the reader and writer interfaces.
+type TxoReaderWriter interface {
+	TxoReader
+	TxoWriter
+}
+
+// TxoReader is used to read utxo information from a data store.
+type TxoReader interface {
+	// UTXOReservations returns each reservation of unspent utxos, or of utxos spent by a tx that
+	// was never broadcast, ordered by ReservedFor.
+	UTXOReservations(ctx context.Context) ([]UTXOReservation, error)
+	// UTXOs returns the txos matching args, ordered by creation date then outpoint.
+	UTXOs(ctx context.Context, args UTXOSearchArgs) ([]UTXODetail, error)
+}
+
 // TxoWriter is used to add transaction information to a data store.
 type TxoWriter interface {
 	// TxosCreate will add an array of txos to a data store.
@@ -49,4 +142,6 @@ type TxoWriter interface {
 	UTXOReserve(ctx context.Context, req UTXOReserve) ([]UTXO, error)
 	UTXOUnreserve(ctx context.Context, req UTXOUnreserve) error
 	UTXOSpend(ctx context.Context, req UTXOSpend) error
+	// UTXOFrozenUpdate will freeze or unfreeze unspent txos, if any aren't found none are updated.
+	UTXOFrozenUpdate(ctx context.Context, req UTXOFrozenUpdate) error
 }
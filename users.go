@@ -2,8 +2,6 @@ package payd
 
 import (
 	"context"
-
-	"github.com/libsv/go-bk/bip32"
 )
 
 // User information on wallet users.
@@ -15,7 +13,6 @@ type User struct {
 	Address      string                 `json:"address" db:"address"`
 	PhoneNumber  string                 `json:"phoneNumber" db:"phone_number"`
 	ExtendedData map[string]interface{} `json:"extendedData,omitempty"`
-	MasterKey    *bip32.ExtendedKey     `json:"-"`
 }
 
 // OwnerService interfaces with owners.
@@ -52,6 +49,10 @@ type CreateUserArgs struct {
 	Address      string                 `json:"address"`
 	PhoneNumber  string                 `json:"phoneNumber"`
 	ExtendedData map[string]interface{} `json:"extendedData"`
+	// Xpub, if set, is used as the user's master key instead of creating a private key.
+	// The user is watch-only, invoices can be created and paid to them but payd can't
+	// spend what they receive.
+	Xpub string `json:"xpub"`
 }
 
 // CreateUserResponse is what we expect to receive once a new user is created.
@@ -0,0 +1,289 @@
+package payd
+
+import (
+	"context"
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// UTXOSearchDefaultLimit is the page size used when no limit is supplied.
+	UTXOSearchDefaultLimit = 50
+	// UTXOSearchMaxLimit is the largest page size that can be requested.
+	UTXOSearchMaxLimit = 500
+)
+
+var reDerivationPath = regexp.MustCompile(`^[0-9]+(/[0-9]+)*$`)
+
+// UTXODetail is a txo paid to the wallet along with its current state.
+type UTXODetail struct {
+	Outpoint       string `json:"outpoint" db:"outpoint"`
+	TxID           string `json:"txid" db:"tx_id"`
+	Vout           uint32 `json:"vout" db:"vout"`
+	Satoshis       uint64 `json:"satoshis" db:"satoshis"`
+	LockingScript  string `json:"lockingScript" db:"locking_script"`
+	DerivationPath string `json:"derivationPath" db:"derivation_path"`
+	KeyName        string `json:"keyName" db:"key_name"`
+	// TxState is the state of the tx paying the txo, only txos of broadcast txs can be spent,
+	// and only those of confirmed txs when confirmations are required.
+	TxState TxState `json:"txState" db:"tx_state" enums:"pending,broadcast,mined,confirmed,failed"`
+	// Confirmed is true once the tx paying the txo has a merkle proof.
+	Confirmed bool `json:"confirmed" db:"confirmed"`
+	// ReservedFor is the payment the txo is reserved to fund.
+	ReservedFor   null.String `json:"reservedFor" db:"reserved_for" swaggertype:"primitive,string"`
+	ReservedUntil null.Time   `json:"reservedUntil" db:"reserved_until" swaggertype:"primitive,string"`
+	// Frozen txos are never reserved to fund a payment.
+	Frozen       bool        `json:"frozen" db:"frozen"`
+	SpentAt      null.Time   `json:"spentAt" db:"spent_at" swaggertype:"primitive,string"`
+	SpendingTxID null.String `json:"spendingTxId" db:"spending_txid" swaggertype:"primitive,string"`
+	CreatedAt    time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// Spent returns true if the txo has been spent.
+func (u UTXODetail) Spent() bool {
+	return u.SpentAt.Valid || u.SpendingTxID.Valid
+}
+
+// Reserved returns true if the txo is reserved to fund a payment that hasn't completed.
+func (u UTXODetail) Reserved() bool {
+	return u.ReservedFor.Valid && !u.Spent()
+}
+
+// UTXOCursor marks the position of the last txo in a page, txos are ordered by
+// their creation date then outpoint so the next page starts after this pair.
+//
+// It is sent to clients as an opaque string.
+type UTXOCursor struct {
+	CreatedAt time.Time
+	Outpoint  string
+}
+
+// IsZero returns true if the cursor is empty, meaning the first page is requested.
+func (c UTXOCursor) IsZero() bool {
+	return c.Outpoint == ""
+}
+
+// MarshalText encodes the cursor to an opaque url safe string.
+func (c UTXOCursor) MarshalText() ([]byte, error) {
+	if c.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(base64.RawURLEncoding.EncodeToString(
+		[]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.Outpoint))), nil
+}
+
+// UnmarshalText decodes a cursor previously encoded with MarshalText.
+func (c *UTXOCursor) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = UTXOCursor{}
+		return nil
+	}
+	bb, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	parts := strings.SplitN(string(bb), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return validator.NewSingleError("cursor", []string{"cursor is invalid"})
+	}
+	c.CreatedAt = createdAt
+	c.Outpoint = parts[1]
+	return nil
+}
+
+// String returns the encoded cursor.
+func (c UTXOCursor) String() string {
+	bb, _ := c.MarshalText()
+	return string(bb)
+}
+
+// UTXOSearchArgs are used to filter and page through the txos paid to the wallet.
+// Zero values are ignored.
+type UTXOSearchArgs struct {
+	// Spent will only return spent txos when true, or unspent txos when false.
+	Spent null.Bool `query:"spent" swaggertype:"primitive,boolean"`
+	// Reserved will only return txos reserved for a payment when true, or those that aren't when false.
+	Reserved null.Bool `query:"reserved" swaggertype:"primitive,boolean"`
+	// Confirmed will only return txos of txs with a merkle proof when true, or those without when false.
+	Confirmed null.Bool `query:"confirmed" swaggertype:"primitive,boolean"`
+	// Frozen will only return frozen txos when true, or those that aren't when false.
+	Frozen null.Bool `query:"frozen" swaggertype:"primitive,boolean"`
+	// SatoshisMin will return txos worth at least this many satoshis.
+	SatoshisMin uint64 `query:"satoshisMin"`
+	// SatoshisMax will return txos worth at most this many satoshis.
+	SatoshisMax uint64 `query:"satoshisMax"`
+	// DerivationPath will return txos paid to this path, or any path beneath it.
+	DerivationPath string `query:"derivationPath"`
+	// Sort orders txos by creation date, defaults to desc, newest first.
+	Sort SortOrder `query:"sort" enums:"asc,desc"`
+	// Limit is the max number of txos returned.
+	Limit int `query:"limit"`
+	// Cursor is the next cursor returned with the previous page, when supplied
+	// the txos after it are returned. The other search args should match those
+	// used to get the previous page.
+	Cursor UTXOCursor `query:"cursor" swaggertype:"primitive,string"`
+}
+
+// Validate will check that the search args match expectations.
+func (u UTXOSearchArgs) Validate() error {
+	v := validator.New().
+		Validate("sort", validator.AnyString(string(u.Sort), "", string(SortAsc), string(SortDesc))).
+		Validate("limit", validator.BetweenInt(u.Limit, 0, UTXOSearchMaxLimit))
+	if u.SatoshisMax > 0 {
+		v = v.Validate("satoshisMax", validator.MinUInt64(u.SatoshisMax, u.SatoshisMin))
+	}
+	if u.DerivationPath != "" {
+		v = v.Validate("derivationPath", validator.MatchString(u.DerivationPath, reDerivationPath))
+	}
+	return v.Err()
+}
+
+// Descending returns true if txos should be returned newest first.
+func (u UTXOSearchArgs) Descending() bool {
+	return u.Sort != SortAsc
+}
+
+// Matches returns true if utxo matches the search filters and comes after the cursor
+// in the sort order, it is used by stores that filter txos in process.
+func (u UTXOSearchArgs) Matches(utxo UTXODetail) bool {
+	switch {
+	case u.Spent.Valid && utxo.Spent() != u.Spent.Bool,
+		u.Reserved.Valid && utxo.Reserved() != u.Reserved.Bool,
+		u.Confirmed.Valid && utxo.Confirmed != u.Confirmed.Bool,
+		u.Frozen.Valid && utxo.Frozen != u.Frozen.Bool,
+		u.SatoshisMin > 0 && utxo.Satoshis < u.SatoshisMin,
+		u.SatoshisMax > 0 && utxo.Satoshis > u.SatoshisMax,
+		u.DerivationPath != "" && utxo.DerivationPath != u.DerivationPath &&
+			!strings.HasPrefix(utxo.DerivationPath, u.DerivationPath+"/"):
+		return false
+	}
+	if u.Cursor.IsZero() {
+		return true
+	}
+	after := utxo.CreatedAt.After(u.Cursor.CreatedAt) ||
+		utxo.CreatedAt.Equal(u.Cursor.CreatedAt) && utxo.Outpoint > u.Cursor.Outpoint
+	before := utxo.CreatedAt.Before(u.Cursor.CreatedAt) ||
+		utxo.CreatedAt.Equal(u.Cursor.CreatedAt) && utxo.Outpoint < u.Cursor.Outpoint
+	if u.Descending() {
+		return before
+	}
+	return after
+}
+
+// UTXOPage is a single page of txo search results.
+type UTXOPage struct {
+	UTXOs []UTXODetail `json:"utxos"`
+	// NextCursor is supplied as the cursor to get the next page, it is empty
+	// when there are no more txos.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// UTXOsFreeze lists the outpoints to freeze or unfreeze.
+type UTXOsFreeze struct {
+	Outpoints []string `json:"outpoints"`
+}
+
+// Validate will check the outpoints are supplied.
+func (u UTXOsFreeze) Validate() error {
+	return validator.New().
+		Validate("outpoints", func() error {
+			if len(u.Outpoints) == 0 {
+				return errors.New("at least one outpoint is required")
+			}
+			for _, o := range u.Outpoints {
+				if o == "" {
+					return errors.New("outpoints cannot be empty")
+				}
+			}
+			return nil
+		}).Err()
+}
+
+// UTXOFrozenUpdate is used to freeze or unfreeze unspent txos in a data store.
+type UTXOFrozenUpdate struct {
+	Outpoints []string
+	Frozen    bool
+}
+
+// UTXOService is used to list the txos paid to the wallet and to freeze those
+// that shouldn't be spent.
+type UTXOService interface {
+	// UTXOs returns a page of the txos matching args.
+	UTXOs(ctx context.Context, args UTXOSearchArgs) (*UTXOPage, error)
+	// UTXOsFreeze will stop the unspent txos being reserved to fund payments.
+	UTXOsFreeze(ctx context.Context, req UTXOsFreeze) error
+	// UTXOsUnfreeze will allow frozen txos to be reserved to fund payments again.
+	UTXOsUnfreeze(ctx context.Context, req UTXOsFreeze) error
+}
+
+// UTXOFanOutMaxOutputs is the most outputs a utxo can be split into.
+const UTXOFanOutMaxOutputs = 1000
+
+// UTXOsConsolidate is used to sweep small utxos into a single utxo, zero values
+// default to the wallet config.
+type UTXOsConsolidate struct {
+	// SatoshisMax is the value at or below which utxos are consolidated.
+	SatoshisMax uint64 `json:"satoshisMax"`
+	// MinUTXOs is the fewest small utxos worth consolidating, if there are fewer nothing is done.
+	MinUTXOs int `json:"minUtxos"`
+	// MaxUTXOs is the most utxos swept by the tx, the smallest are swept first.
+	MaxUTXOs int `json:"maxUtxos"`
+}
+
+// Validate will check the consolidation args are valid.
+func (u UTXOsConsolidate) Validate() error {
+	v := validator.New().
+		Validate("minUtxos", validator.MinInt(u.MinUTXOs, 2))
+	if u.MaxUTXOs > 0 {
+		v = v.Validate("maxUtxos", validator.MinInt(u.MaxUTXOs, u.MinUTXOs))
+	}
+	return v.Err()
+}
+
+// UTXOsFanOut is used to split a large utxo into smaller utxos of equal value, zero
+// values default to the wallet config.
+type UTXOsFanOut struct {
+	// SatoshisMin is the value at or above which a utxo is split.
+	SatoshisMin uint64 `json:"satoshisMin"`
+	// Outputs is the number of utxos the largest utxo is split into.
+	Outputs int `json:"outputs"`
+}
+
+// Validate will check the fan out args are valid.
+func (u UTXOsFanOut) Validate() error {
+	return validator.New().
+		Validate("outputs", validator.BetweenInt(u.Outputs, 2, UTXOFanOutMaxOutputs)).
+		Validate("satoshisMin", validator.MinUInt64(u.SatoshisMin, uint64(u.Outputs)*DustLimit)).Err()
+}
+
+// UTXOMaintenanceTx is a tx created by the wallet to consolidate or fan out its own utxos.
+type UTXOMaintenanceTx struct {
+	TxID string `json:"txid"`
+	// Inputs is the number of utxos spent.
+	Inputs int `json:"inputs"`
+	// Outputs is the number of utxos created.
+	Outputs int `json:"outputs"`
+	// Satoshis is the total of the utxos created.
+	Satoshis uint64 `json:"satoshis"`
+	// Fee is the mining fee paid, the spent utxos less Satoshis.
+	Fee uint64 `json:"fee"`
+}
+
+// UTXOMaintenanceService is used to reshape the wallet utxos, by sweeping small utxos
+// together or splitting large ones, so payments can be funded efficiently.
+type UTXOMaintenanceService interface {
+	// UTXOsConsolidate sweeps the small utxos into one, nil is returned if there aren't enough to sweep.
+	UTXOsConsolidate(ctx context.Context, req UTXOsConsolidate) (*UTXOMaintenanceTx, error)
+	// UTXOsFanOut splits the largest utxo, nil is returned if none are large enough to split.
+	UTXOsFanOut(ctx context.Context, req UTXOsFanOut) (*UTXOMaintenanceTx, error)
+}
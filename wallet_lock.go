@@ -0,0 +1,64 @@
+package payd
+
+import (
+	"context"
+
+	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
+)
+
+// WalletPassphraseMinLength is the shortest passphrase the wallet can be encrypted with.
+const WalletPassphraseMinLength = 8
+
+// WalletEncrypt is used to encrypt the wallet private keys with a passphrase.
+type WalletEncrypt struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// Validate will check the passphrase is long enough.
+func (w WalletEncrypt) Validate() error {
+	return validator.New().
+		Validate("passphrase", validator.StrLength(w.Passphrase, WalletPassphraseMinLength, 1024)).
+		Err()
+}
+
+// WalletUnlock is used to unlock an encrypted wallet so its keys can be used.
+type WalletUnlock struct {
+	Passphrase string `json:"passphrase"`
+	// Timeout is the number of seconds until the wallet is locked again, if zero it
+	// stays unlocked until it is locked.
+	Timeout int64 `json:"timeout"`
+}
+
+// Validate will check the unlock args are valid.
+func (w WalletUnlock) Validate() error {
+	return validator.New().
+		Validate("passphrase", validator.NotEmpty(w.Passphrase)).
+		Validate("timeout", validator.MinInt64(w.Timeout, 0)).
+		Err()
+}
+
+// WalletLockStatus describes whether the wallet keys are encrypted and can be used.
+type WalletLockStatus struct {
+	// Encrypted is true once the wallet keys have been encrypted with a passphrase.
+	Encrypted bool `json:"encrypted"`
+	// Locked is true if the wallet is encrypted and hasn't been unlocked, while locked
+	// payments can't be signed.
+	Locked bool `json:"locked"`
+	// UnlockedUntil is when the wallet will lock itself, it is empty if the wallet is
+	// locked or won't lock itself.
+	UnlockedUntil null.Time `json:"unlockedUntil" swaggertype:"primitive,string"`
+}
+
+// WalletLockService is used to encrypt the wallet private keys at rest and to unlock
+// them for use, in the same way as bitcoind's encryptwallet and walletpassphrase.
+type WalletLockService interface {
+	// WalletEncrypt encrypts every private key with the passphrase, the wallet is locked once encrypted.
+	WalletEncrypt(ctx context.Context, req WalletEncrypt) error
+	// WalletUnlock decrypts the private keys, holding them in memory until the timeout passes.
+	WalletUnlock(ctx context.Context, req WalletUnlock) error
+	// WalletLock removes the decrypted private keys from memory.
+	WalletLock(ctx context.Context) error
+	// WalletLockStatus returns whether the wallet is encrypted and locked.
+	WalletLockStatus(ctx context.Context) (*WalletLockStatus, error)
+}
@@ -0,0 +1,325 @@
+package payd
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	"gopkg.in/guregu/null.v3"
+)
+
+// WebhookEvent is a lifecycle event that webhooks can subscribe to.
+type WebhookEvent string
+
+// events that are delivered to webhooks.
+const (
+	WebhookEventInvoiceCreated WebhookEvent = "invoice.created"
+	WebhookEventInvoicePaid    WebhookEvent = "invoice.paid"
+	WebhookEventInvoiceExpired WebhookEvent = "invoice.expired"
+	WebhookEventTxBroadcast    WebhookEvent = "tx.broadcast"
+	WebhookEventTxFailed       WebhookEvent = "tx.failed"
+	WebhookEventProofReceived  WebhookEvent = "proof.received"
+	WebhookEventProofOrphaned  WebhookEvent = "proof.orphaned"
+)
+
+// WebhookEvents are all the events a webhook can subscribe to.
+// nolint:gochecknoglobals // read only list of supported events
+var WebhookEvents = []WebhookEvent{
+	WebhookEventInvoiceCreated,
+	WebhookEventInvoicePaid,
+	WebhookEventInvoiceExpired,
+	WebhookEventTxBroadcast,
+	WebhookEventTxFailed,
+	WebhookEventProofReceived,
+	WebhookEventProofOrphaned,
+}
+
+func (w WebhookEvent) String() string {
+	return string(w)
+}
+
+// WebhookDeliveryState defines the states a webhook delivery can have.
+type WebhookDeliveryState string
+
+// contains states that a webhook delivery can have.
+const (
+	StateWebhookDeliveryPending   WebhookDeliveryState = "pending"
+	StateWebhookDeliveryDelivered WebhookDeliveryState = "delivered"
+	StateWebhookDeliveryDead      WebhookDeliveryState = "dead"
+)
+
+// Webhook is an endpoint registered by a merchant to be told about invoice and
+// transaction lifecycle events.
+type Webhook struct {
+	// ID uniquely identifies the webhook.
+	ID string `json:"id" db:"webhook_id"`
+	// URL is the endpoint events are posted to.
+	URL string `json:"url" db:"url"`
+	// Secret is used to sign each delivery with a HMAC-SHA256 of the body, it is only
+	// returned when the webhook is created.
+	Secret string `json:"secret,omitempty" db:"secret"`
+	// Events are the events delivered to the webhook.
+	Events    []WebhookEvent `json:"events" db:"-" enums:"invoice.created,invoice.paid,invoice.expired,tx.broadcast,tx.failed,proof.received,proof.orphaned"`
+	CreatedAt time.Time      `json:"createdAt" db:"created_at"`
+}
+
+// Subscribed returns true if the webhook should be sent the event.
+func (w Webhook) Subscribed(event WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookCreate is used to register a new webhook.
+type WebhookCreate struct {
+	WebhookID string `json:"-" db:"webhook_id"`
+	// URL is the http or https endpoint events are posted to.
+	URL string `json:"url" db:"url" example:"https://merchant.com/payd/events"`
+	// Secret is used to sign deliveries, if not supplied one is generated.
+	Secret string `json:"secret" db:"secret"`
+	// Events are the events to deliver to the webhook, at least one is required.
+	Events    []WebhookEvent `json:"events" db:"-" enums:"invoice.created,invoice.paid,invoice.expired,tx.broadcast,tx.failed,proof.received,proof.orphaned"`
+	CreatedAt time.Time      `json:"-" db:"created_at"`
+}
+
+// Validate will check that WebhookCreate params match expectations.
+func (w WebhookCreate) Validate() error {
+	return validator.New().
+		Validate("url", func() error {
+			u, err := url.Parse(w.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return errors.New("url must be a valid http or https url")
+			}
+			return nil
+		}).
+		Validate("secret", validator.StrLength(w.Secret, 0, 256)).
+		Validate("events", func() error {
+			if len(w.Events) == 0 {
+				return errors.New("at least one event is required")
+			}
+			for _, e := range w.Events {
+				if err := validator.AnyString(string(e), webhookEventStrings()...)(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Err()
+}
+
+func webhookEventStrings() []string {
+	ss := make([]string, 0, len(WebhookEvents))
+	for _, e := range WebhookEvents {
+		ss = append(ss, string(e))
+	}
+	return ss
+}
+
+// WebhookArgs are used to identify a single webhook.
+type WebhookArgs struct {
+	WebhookID string `param:"webhookID" db:"webhook_id"`
+}
+
+// Validate will check that webhook arguments match expectations.
+func (w WebhookArgs) Validate() error {
+	return validator.New().
+		Validate("webhookID", validator.StrLength(w.WebhookID, 1, 36)).
+		Err()
+}
+
+// WebhookDelivery is an event waiting to be, or that has been, posted to a webhook.
+// Deliveries are written to an outbox in the same data store transaction as the change
+// that raised the event, then posted by a background worker.
+type WebhookDelivery struct {
+	// ID uniquely identifies the delivery, it is sent in the X-Payd-Delivery header
+	// so receivers can ignore deliveries they have already handled.
+	ID        string       `json:"id" db:"delivery_id"`
+	WebhookID string       `json:"webhookId" db:"webhook_id"`
+	Event     WebhookEvent `json:"event" db:"event"`
+	// Payload is the event data, sent as the data field of the message.
+	Payload json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	// State is pending until the delivery succeeds, or it is dead lettered
+	// after running out of attempts.
+	State WebhookDeliveryState `json:"state" db:"state" enums:"pending,delivered,dead"`
+	// Attempts is the number of times delivery has been tried.
+	Attempts int `json:"attempts" db:"attempts"`
+	// NextAttemptAt is when a pending delivery will next be tried.
+	NextAttemptAt time.Time `json:"nextAttemptAt" db:"next_attempt_at"`
+	// LastError is the reason the last attempt failed.
+	LastError   null.String `json:"lastError" db:"last_error" swaggertype:"primitive,string"`
+	DeliveredAt null.Time   `json:"deliveredAt" db:"delivered_at" swaggertype:"primitive,string"`
+	CreatedAt   time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updatedAt" db:"updated_at"`
+}
+
+// WebhookDeliveryCreate is used to add a delivery to the outbox.
+type WebhookDeliveryCreate struct {
+	DeliveryID string          `db:"delivery_id"`
+	WebhookID  string          `db:"webhook_id"`
+	Event      WebhookEvent    `db:"event"`
+	Payload    json.RawMessage `db:"payload"`
+	// NextAttemptAt is when the first attempt is due.
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// WebhookDeliveryArgs are used to identify a single delivery.
+type WebhookDeliveryArgs struct {
+	DeliveryID string `param:"deliveryID" db:"delivery_id"`
+}
+
+// Validate will check that delivery arguments match expectations.
+func (w WebhookDeliveryArgs) Validate() error {
+	return validator.New().
+		Validate("deliveryID", validator.StrLength(w.DeliveryID, 1, 36)).
+		Err()
+}
+
+// WebhookDeliveriesArgs are used to filter deliveries, zero values are ignored.
+type WebhookDeliveriesArgs struct {
+	// WebhookID will only return deliveries to this webhook.
+	WebhookID string `query:"webhookId"`
+	// State will only return deliveries in this state.
+	State WebhookDeliveryState `query:"state" enums:"pending,delivered,dead"`
+	// Limit is the max number of deliveries returned, newest first.
+	Limit int `query:"limit"`
+}
+
+// Webhook delivery listing defaults and limits.
+const (
+	// WebhookDeliveriesDefaultLimit is the number of deliveries returned when no limit is supplied.
+	WebhookDeliveriesDefaultLimit = 50
+	// WebhookDeliveriesMaxLimit is the most deliveries that can be requested.
+	WebhookDeliveriesMaxLimit = 500
+)
+
+// Validate will check that the delivery filters match expectations.
+func (w WebhookDeliveriesArgs) Validate() error {
+	return validator.New().
+		Validate("webhookId", validator.StrLength(w.WebhookID, 0, 36)).
+		Validate("state", validator.AnyString(string(w.State), "", string(StateWebhookDeliveryPending),
+			string(StateWebhookDeliveryDelivered), string(StateWebhookDeliveryDead))).
+		Validate("limit", validator.BetweenInt(w.Limit, 0, WebhookDeliveriesMaxLimit)).
+		Err()
+}
+
+// WebhookDeliveriesDueArgs identify the pending deliveries ready to be attempted.
+type WebhookDeliveriesDueArgs struct {
+	// DueBefore, pending deliveries with a next attempt at or before this time are due.
+	DueBefore time.Time
+	// Limit is the max number of deliveries returned, the longest waiting first.
+	Limit int
+}
+
+// WebhookDeliveryUpdate records the outcome of a delivery attempt.
+type WebhookDeliveryUpdate struct {
+	State         WebhookDeliveryState `db:"state"`
+	Attempts      int                  `db:"attempts"`
+	NextAttemptAt time.Time            `db:"next_attempt_at"`
+	LastError     null.String          `db:"last_error"`
+	DeliveredAt   null.Time            `db:"delivered_at"`
+	UpdatedAt     time.Time            `db:"updated_at"`
+}
+
+// WebhookMessage is the body posted to a webhook endpoint.
+type WebhookMessage struct {
+	// ID is the delivery id, retries of a delivery have the same id.
+	ID        string          `json:"id"`
+	Event     WebhookEvent    `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookSendArgs identify the endpoint a message is posted to.
+type WebhookSendArgs struct {
+	URL    string
+	Secret string
+}
+
+// WebhookTxEvent is the data sent with tx.broadcast and tx.failed events.
+type WebhookTxEvent struct {
+	TxID      string `json:"txId"`
+	InvoiceID string `json:"invoiceId,omitempty"`
+	// FailReason is the reason a transaction failed, if known.
+	FailReason string `json:"failReason,omitempty"`
+}
+
+// WebhookProofEvent is the data sent with proof.received and proof.orphaned events.
+type WebhookProofEvent struct {
+	TxID        string `json:"txId"`
+	BlockHash   string `json:"blockHash"`
+	BlockHeight uint32 `json:"blockHeight"`
+}
+
+// WebhookService is used to manage webhooks and deliver events to them.
+type WebhookService interface {
+	// Webhook will return a single webhook.
+	Webhook(ctx context.Context, args WebhookArgs) (*Webhook, error)
+	// Webhooks will return all registered webhooks.
+	Webhooks(ctx context.Context) ([]Webhook, error)
+	// WebhookCreate will register a new webhook.
+	WebhookCreate(ctx context.Context, req WebhookCreate) (*Webhook, error)
+	// WebhookDelete will remove a webhook, no further events are delivered to it.
+	WebhookDelete(ctx context.Context, args WebhookArgs) error
+	// WebhookDeliveries will return the deliveries matching args, newest first.
+	WebhookDeliveries(ctx context.Context, args WebhookDeliveriesArgs) ([]WebhookDelivery, error)
+	// WebhookDeliveryReplay will queue a delivery to be sent again, whatever state it is in.
+	WebhookDeliveryReplay(ctx context.Context, args WebhookDeliveryArgs) (*WebhookDelivery, error)
+	// WebhooksDeliver will attempt all deliveries that are due, returning the number attempted.
+	WebhooksDeliver(ctx context.Context) (int, error)
+}
+
+// WebhookPublisher raises lifecycle events, a delivery is queued for each webhook
+// subscribed to the event.
+//
+// Deliveries are written using the context transaction, if there is one, so they are
+// only sent if the change raising the event is committed.
+type WebhookPublisher interface {
+	Publish(ctx context.Context, event WebhookEvent, data interface{}) error
+}
+
+// WebhookSender posts a signed message to a webhook endpoint, an error is returned
+// if the endpoint doesn't respond with a 2xx status code.
+type WebhookSender interface {
+	WebhookSend(ctx context.Context, args WebhookSendArgs, msg WebhookMessage) error
+}
+
+// WebhookReaderWriter can be implemented to support storing and retrieval of webhooks
+// and their deliveries.
+type WebhookReaderWriter interface {
+	WebhookReader
+	WebhookWriter
+}
+
+// WebhookReader defines a data store used to read webhooks and deliveries.
+type WebhookReader interface {
+	// Webhook will return a webhook matching args, deleted webhooks are not returned.
+	Webhook(ctx context.Context, args WebhookArgs) (*Webhook, error)
+	// Webhooks will return all webhooks that haven't been deleted, oldest first.
+	Webhooks(ctx context.Context) ([]Webhook, error)
+	// WebhookDelivery will return a delivery matching args.
+	WebhookDelivery(ctx context.Context, args WebhookDeliveryArgs) (*WebhookDelivery, error)
+	// WebhookDeliveries returns the deliveries matching args, newest first.
+	// At most args.Limit deliveries are returned, a zero limit returns all matches.
+	WebhookDeliveries(ctx context.Context, args WebhookDeliveriesArgs) ([]WebhookDelivery, error)
+	// WebhookDeliveriesDue returns the pending deliveries due to be attempted, for
+	// webhooks that haven't been deleted, ordered by their next attempt.
+	WebhookDeliveriesDue(ctx context.Context, args WebhookDeliveriesDueArgs) ([]WebhookDelivery, error)
+}
+
+// WebhookWriter defines a data store used to write webhooks and deliveries.
+type WebhookWriter interface {
+	// WebhookCreate will persist a new webhook along with the events it subscribes to.
+	WebhookCreate(ctx context.Context, req WebhookCreate) (*Webhook, error)
+	// WebhookDelete will soft delete a webhook, its deliveries are kept.
+	WebhookDelete(ctx context.Context, args WebhookArgs) error
+	// WebhookDeliveriesCreate will add deliveries to the outbox in the pending state.
+	WebhookDeliveriesCreate(ctx context.Context, req []WebhookDeliveryCreate) error
+	// WebhookDeliveryUpdate will update a delivery with the outcome of an attempt.
+	WebhookDeliveryUpdate(ctx context.Context, args WebhookDeliveryArgs, req WebhookDeliveryUpdate) error
+}